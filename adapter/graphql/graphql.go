@@ -0,0 +1,316 @@
+// Package graphql mounts a GraphQL server on a GoSPA app and lets Load
+// functions and remote actions run queries against it in-process.
+//
+// Any net/http GraphQL handler works, including gqlgen's handler.Server, so
+// this package does not depend on gqlgen:
+//
+//	srv := handler.NewDefaultServer(generated.NewExecutableSchema(cfg))
+//	gql := graphql.Mount(app, "/graphql", srv, graphql.Options{})
+//
+//	// In a Load function:
+//	var out struct{ Post struct{ Title string } }
+//	err := gql.Query(c, `query($id: ID!) { post(id: $id) { title } }`,
+//		map[string]interface{}{"id": c.Param("id")}, &out)
+//
+// Resolvers read the caller's session and user with FromContext. Queries and
+// Loader batches are cached for the lifetime of one page request, so several
+// components asking for the same data run it once.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/aydenstechdungeon/gospa/routing"
+	json "github.com/goccy/go-json"
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+)
+
+// scopeLocal is the Fiber local holding the request scope.
+const scopeLocal = "gospa.graphql"
+
+// Options configures Mount.
+type Options struct {
+	// Context adds application values (tenant, permissions, ...) to the
+	// context passed to resolvers for HTTP requests.
+	Context func(c gofiber.Ctx, ctx context.Context) context.Context
+}
+
+// Handler serves a GraphQL http.Handler and executes queries against it.
+type Handler struct {
+	handler http.Handler
+	path    string
+	opts    Options
+}
+
+// Mount serves h at path for GET and POST and installs the middleware that
+// gives each request its query and Loader cache. Call it before the app
+// starts. With CSRF enabled, browser POSTs must send the X-CSRF-Token header.
+// Subscriptions over streaming transports are not supported; use GoSPA's
+// WebSocket state sync instead.
+func Mount(app *gospa.App, path string, h http.Handler, opts Options) *Handler {
+	g := New(h, opts)
+	g.path = path
+	app.Fiber.Use(func(c gofiber.Ctx) error {
+		c.Locals(scopeLocal, &scope{})
+		return c.Next()
+	})
+	app.Fiber.Get(path, g.serve)
+	app.Fiber.Post(path, g.serve)
+	return g
+}
+
+// New wraps h without mounting it, for executing queries only.
+func New(h http.Handler, opts Options) *Handler {
+	return &Handler{handler: h, path: "/graphql", opts: opts}
+}
+
+// Info is the caller identity shared with resolvers.
+type Info struct {
+	// SessionID is the GoSPA session token, if the request has one.
+	SessionID string
+	// User is the value plugin/auth stores in the "user" local, if any.
+	User interface{}
+
+	local func(key string) interface{}
+}
+
+// Local returns a request local set by middleware. It is only valid while
+// the request is being handled.
+func (i *Info) Local(key string) interface{} {
+	if i == nil || i.local == nil {
+		return nil
+	}
+	return i.local(key)
+}
+
+type infoKey struct{}
+
+type scopeKey struct{}
+
+// FromContext returns the caller identity of a request served by Mount or
+// a query run with Query or Exec. It returns nil for other contexts.
+func FromContext(ctx context.Context) *Info {
+	info, _ := ctx.Value(infoKey{}).(*Info)
+	return info
+}
+
+// WithRequestScope returns a context with a fresh query and Loader cache,
+// for work outside a page request such as a job.
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{})
+}
+
+func infoFromLocals(local func(key string) interface{}) *Info {
+	info := &Info{User: local("user"), local: local}
+	info.SessionID, _ = local("gospa.session").(string)
+	return info
+}
+
+func (g *Handler) serve(c gofiber.Ctx) error {
+	req, err := adaptor.ConvertRequest(c, true)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(c.Context(), infoKey{}, infoFromLocals(func(key string) interface{} { return c.Locals(key) }))
+	if s, ok := c.Locals(scopeLocal).(*scope); ok {
+		ctx = context.WithValue(ctx, scopeKey{}, s)
+	}
+	if g.opts.Context != nil {
+		ctx = g.opts.Context(c, ctx)
+	}
+
+	w := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	g.handler.ServeHTTP(w, req.WithContext(ctx))
+	for k, values := range w.header {
+		for _, v := range values {
+			c.Response().Header.Add(k, v)
+		}
+	}
+	return c.Status(w.status).Send(w.body.Bytes())
+}
+
+// Error is one entry of a GraphQL response's "errors" list.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Errors is returned when a response carries GraphQL errors. Partial data is
+// still decoded into the output value.
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// Query runs query from a Load function as the page's caller, decoding the
+// response data into out. Identical queries in the same page request run
+// once.
+func (g *Handler) Query(c routing.LoadContext, query string, vars map[string]interface{}, out interface{}) error {
+	ctx := context.WithValue(context.Background(), infoKey{}, infoFromLocals(c.Local))
+	if s, ok := c.Local(scopeLocal).(*scope); ok {
+		ctx = context.WithValue(ctx, scopeKey{}, s)
+	}
+	return g.Exec(ctx, query, vars, out)
+}
+
+// Exec runs query with ctx passed through to resolvers, decoding the response
+// data into out (which may be nil). Queries are cached when ctx carries a
+// request scope; mutations never are.
+func (g *Handler) Exec(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+
+	var data json.RawMessage
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	if s != nil && !isMutation(query) {
+		data, err = s.result(hashBody(body), func() (json.RawMessage, error) {
+			return g.execute(ctx, body)
+		})
+	} else {
+		data, err = g.execute(ctx, body)
+	}
+	if out != nil && len(data) > 0 && string(data) != "null" {
+		if uerr := json.Unmarshal(data, out); uerr != nil && err == nil {
+			err = uerr
+		}
+	}
+	return err
+}
+
+func (g *Handler) execute(ctx context.Context, body []byte) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	w := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	g.handler.ServeHTTP(w, req)
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors Errors          `json:"errors"`
+	}
+	if err := json.Unmarshal(w.body.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("graphql: invalid response (status %d)", w.status)
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Data, resp.Errors
+	}
+	return resp.Data, nil
+}
+
+// RemoteAction returns a remote action that runs query with the action's
+// input (a JSON object) as variables, so client code can call it through
+// the remote action endpoint:
+//
+//	routing.RegisterRemoteAction("posts", gql.RemoteAction(`query { posts { id title } }`))
+func (g *Handler) RemoteAction(query string) routing.RemoteActionFunc {
+	return func(ctx context.Context, rc routing.RemoteContext, input interface{}) (interface{}, error) {
+		vars, _ := input.(map[string]interface{})
+		ctx = context.WithValue(ctx, infoKey{}, &Info{SessionID: rc.SessionID})
+		var out json.RawMessage
+		if err := g.Exec(ctx, query, vars, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// scope holds the caches of one request.
+type scope struct {
+	mu      sync.Mutex
+	results map[string]*cachedResult
+	loaders map[interface{}]interface{}
+}
+
+type cachedResult struct {
+	done chan struct{}
+	data json.RawMessage
+	err  error
+}
+
+// result returns the cached result for key, running fn once if there is
+// none. Concurrent callers wait for the first one.
+func (s *scope) result(key string, fn func() (json.RawMessage, error)) (json.RawMessage, error) {
+	s.mu.Lock()
+	if r, ok := s.results[key]; ok {
+		s.mu.Unlock()
+		<-r.done
+		return r.data, r.err
+	}
+	if s.results == nil {
+		s.results = make(map[string]*cachedResult)
+	}
+	r := &cachedResult{done: make(chan struct{})}
+	s.results[key] = r
+	s.mu.Unlock()
+
+	r.data, r.err = fn()
+	close(r.done)
+	return r.data, r.err
+}
+
+// loader returns the per-request state for owner, creating it with create.
+func (s *scope) loader(owner interface{}, create func() interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.loaders[owner]; ok {
+		return st
+	}
+	if s.loaders == nil {
+		s.loaders = make(map[interface{}]interface{})
+	}
+	st := create()
+	s.loaders[owner] = st
+	return st
+}
+
+// bufferedResponse collects a handler's response so it can be copied to
+// Fiber or decoded.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (w *bufferedResponse) Header() http.Header { return w.header }
+
+func (w *bufferedResponse) WriteHeader(status int) {
+	if !w.wrote {
+		w.status = status
+		w.wrote = true
+	}
+}
+
+func (w *bufferedResponse) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.body.Write(p)
+}
@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa"
+	json "github.com/goccy/go-json"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// fakeServer answers every request with the caller's user and the query it
+// received, counting executions.
+type fakeServer struct {
+	calls atomic.Int32
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.calls.Add(1)
+	var req struct {
+		Query string `json:"query"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	if strings.Contains(req.Query, "fail") {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":null,"errors":[{"message":"boom"}]}`)
+		return
+	}
+	var user interface{}
+	if info := FromContext(r.Context()); info != nil {
+		user = info.User
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{"user": user, "query": req.Query},
+	})
+}
+
+func TestMountSharesRequestLocals(t *testing.T) {
+	cfg := gospa.DefaultConfig()
+	cfg.RoutesDir = t.TempDir()
+	cfg.DisableCSRF = true
+	app := gospa.New(cfg)
+	t.Cleanup(func() { _ = app.Fiber.Shutdown() })
+	app.Fiber.Use(func(c gofiber.Ctx) error {
+		c.Locals("user", "ada")
+		return c.Next()
+	})
+	Mount(app, "/graphql", &fakeServer{}, Options{})
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ me }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"user":"ada"`) {
+		t.Fatalf("status %d body %s", resp.StatusCode, body)
+	}
+}
+
+func TestExecCachesQueriesPerScope(t *testing.T) {
+	srv := &fakeServer{}
+	g := New(srv, Options{})
+	ctx := WithRequestScope(context.Background())
+
+	var out struct{ Query string }
+	for i := 0; i < 3; i++ {
+		if err := g.Exec(ctx, "{ posts }", nil, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.Query != "{ posts }" || srv.calls.Load() != 1 {
+		t.Fatalf("out %+v, calls %d; want one execution", out, srv.calls.Load())
+	}
+
+	for i := 0; i < 2; i++ {
+		_ = g.Exec(ctx, "mutation { like }", nil, nil)
+	}
+	_ = g.Exec(context.Background(), "{ posts }", nil, nil)
+	if srv.calls.Load() != 4 {
+		t.Fatalf("calls = %d, want mutations and unscoped queries uncached", srv.calls.Load())
+	}
+
+	err := g.Exec(ctx, "{ fail }", nil, &out)
+	if gqlErr, ok := err.(Errors); !ok || gqlErr[0].Message != "boom" {
+		t.Fatalf("err = %v, want Errors{boom}", err)
+	}
+}
+
+func TestLoaderBatchesWithinScope(t *testing.T) {
+	var batches atomic.Int32
+	loader := NewLoader(func(_ context.Context, keys []int) (map[int]int, error) {
+		batches.Add(1)
+		out := make(map[int]int, len(keys))
+		for _, k := range keys {
+			out[k] = k * 10
+		}
+		return out, nil
+	})
+	loader.Wait = 50 * time.Millisecond
+	ctx := WithRequestScope(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			if v, err := loader.Load(ctx, k%5); err != nil || v != (k%5)*10 {
+				t.Errorf("Load(%d) = %d, %v", k%5, v, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := batches.Load(); n != 1 {
+		t.Fatalf("batches = %d, want 1", n)
+	}
+	if v, _ := loader.Load(ctx, 3); v != 30 || batches.Load() != 1 {
+		t.Fatal("cached key was loaded again")
+	}
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads values for keys in one call. Keys missing from the result
+// load as the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches lookups made by resolvers during one request, so
+// a list of 50 posts fetches its authors with one query instead of 50.
+// Declare loaders once, at package level:
+//
+//	var authors = graphql.NewLoader(func(ctx context.Context, ids []string) (map[string]*User, error) {
+//		return db.UsersByID(ctx, ids)
+//	})
+//
+//	func (r *postResolver) Author(ctx context.Context, p *Post) (*User, error) {
+//		return authors.Load(ctx, p.AuthorID)
+//	}
+//
+// Outside a request scope every Load calls the batch function directly.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+	// Wait is how long the first Load of a batch waits for more keys
+	// (default 1ms).
+	Wait time.Duration
+}
+
+// NewLoader returns a Loader backed by batch.
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch}
+}
+
+// Load returns the value for key, batching it with other keys requested
+// within Wait and reusing values already loaded in this request.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	if s == nil {
+		values, err := l.batch(ctx, []K{key})
+		return values[key], err
+	}
+	st := s.loader(l, func() interface{} {
+		return &loaderState[K, V]{cache: make(map[K]*loaderBatch[K, V])}
+	}).(*loaderState[K, V])
+
+	st.mu.Lock()
+	b, ok := st.cache[key]
+	if !ok {
+		if st.pending == nil {
+			st.pending = &loaderBatch[K, V]{done: make(chan struct{})}
+			wait := l.Wait
+			if wait <= 0 {
+				wait = time.Millisecond
+			}
+			time.AfterFunc(wait, func() { st.dispatch(ctx, l.batch) })
+		}
+		b = st.pending
+		b.keys = append(b.keys, key)
+		st.cache[key] = b
+	}
+	st.mu.Unlock()
+
+	<-b.done
+	return b.values[key], b.err
+}
+
+// loaderState is one Loader's cache within a request scope.
+type loaderState[K comparable, V any] struct {
+	mu      sync.Mutex
+	cache   map[K]*loaderBatch[K, V]
+	pending *loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func (st *loaderState[K, V]) dispatch(ctx context.Context, batch BatchFunc[K, V]) {
+	st.mu.Lock()
+	b := st.pending
+	st.pending = nil
+	st.mu.Unlock()
+	if b == nil {
+		return
+	}
+	b.values, b.err = batch(ctx, b.keys)
+	close(b.done)
+}
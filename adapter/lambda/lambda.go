@@ -0,0 +1,244 @@
+// Package lambda serves a GoSPA app from AWS Lambda.
+//
+// It converts API Gateway (REST and HTTP API), Lambda function URL, and ALB
+// events to Fiber requests without opening a listener. The handler's
+// signature matches what github.com/aws/aws-lambda-go/lambda.Start expects, so
+// this package does not depend on the AWS SDK:
+//
+//	cfg := lambda.Config(gospa.ProductionConfig())
+//	cfg.RealtimeURL = "wss://realtime.example.com/_gospa/ws" // optional
+//	app := gospa.New(cfg)
+//	awslambda.Start(lambda.Handler(app))
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/valyala/fasthttp"
+)
+
+// Config returns base adjusted for Lambda. RequestMode disables WebSocket and
+// prefork and limits rendering to SSR and SSG; set RealtimeURL on the result
+// to keep live state sync through an external realtime node.
+func Config(base gospa.Config) gospa.Config {
+	base.RequestMode = true
+	return base
+}
+
+// Request is the union of the API Gateway REST (payload 1.0), HTTP API and
+// function URL (payload 2.0), and ALB event shapes.
+type Request struct {
+	Version string `json:"version,omitempty"`
+
+	// Payload 1.0 and ALB.
+	HTTPMethod                      string              `json:"httpMethod,omitempty"`
+	Path                            string              `json:"path,omitempty"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters,omitempty"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters,omitempty"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders,omitempty"`
+
+	// Payload 2.0.
+	RawPath        string   `json:"rawPath,omitempty"`
+	RawQueryString string   `json:"rawQueryString,omitempty"`
+	Cookies        []string `json:"cookies,omitempty"`
+
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"isBase64Encoded,omitempty"`
+	RequestContext  RequestContext    `json:"requestContext"`
+}
+
+// RequestContext holds the parts of the event request context the adapter uses.
+type RequestContext struct {
+	Identity struct {
+		SourceIP string `json:"sourceIp,omitempty"`
+	} `json:"identity"`
+	HTTP struct {
+		Method   string `json:"method,omitempty"`
+		SourceIP string `json:"sourceIp,omitempty"`
+	} `json:"http"`
+	ELB *struct {
+		TargetGroupArn string `json:"targetGroupArn,omitempty"`
+	} `json:"elb,omitempty"`
+}
+
+// Response is accepted by API Gateway (both payload versions), function URLs,
+// and ALB.
+type Response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// Handler prepares app's routes and returns a Lambda handler serving them.
+// If preparation fails, every invocation returns that error.
+func Handler(app *gospa.App) func(context.Context, Request) (Response, error) {
+	if err := app.Prepare(); err != nil {
+		return func(context.Context, Request) (Response, error) {
+			return Response{}, err
+		}
+	}
+	serve := app.Fiber.Handler()
+	return func(_ context.Context, event Request) (Response, error) {
+		var fctx fasthttp.RequestCtx
+		req, remote, err := event.toFastHTTP()
+		if err != nil {
+			return Response{}, err
+		}
+		fctx.Init(req, remote, nil)
+		fasthttp.ReleaseRequest(req)
+		serve(&fctx)
+		return event.response(&fctx.Response), nil
+	}
+}
+
+// isV2 reports whether the event uses payload format 2.0.
+func (e *Request) isV2() bool {
+	return e.Version == "2.0"
+}
+
+func (e *Request) toFastHTTP() (*fasthttp.Request, net.Addr, error) {
+	req := fasthttp.AcquireRequest()
+
+	method, path, query := e.HTTPMethod, e.Path, ""
+	if e.isV2() {
+		method, path, query = e.RequestContext.HTTP.Method, e.RawPath, e.RawQueryString
+	} else {
+		query = e.v1Query()
+	}
+	if path == "" {
+		path = "/"
+	}
+	uri := path
+	if query != "" {
+		uri += "?" + query
+	}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+
+	if len(e.MultiValueHeaders) > 0 {
+		for name, values := range e.MultiValueHeaders {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+	} else {
+		for name, value := range e.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+	if len(e.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(e.Cookies, "; "))
+	}
+	if host := req.Header.Peek("Host"); len(host) > 0 {
+		req.SetHost(string(host))
+	}
+
+	if e.Body != "" {
+		body := []byte(e.Body)
+		if e.IsBase64Encoded {
+			decoded, err := base64.StdEncoding.DecodeString(e.Body)
+			if err != nil {
+				fasthttp.ReleaseRequest(req)
+				return nil, nil, fmt.Errorf("lambda: decode request body: %w", err)
+			}
+			body = decoded
+		}
+		req.SetBody(body)
+	}
+
+	ip := net.ParseIP(e.RequestContext.HTTP.SourceIP)
+	if ip == nil {
+		ip = net.ParseIP(e.RequestContext.Identity.SourceIP)
+	}
+	if ip == nil {
+		return req, nil, nil
+	}
+	return req, &net.TCPAddr{IP: ip}, nil
+}
+
+// v1Query rebuilds the query string of a payload 1.0 or ALB event.
+func (e *Request) v1Query() string {
+	values := url.Values{}
+	if len(e.MultiValueQueryStringParameters) > 0 {
+		for key, vals := range e.MultiValueQueryStringParameters {
+			values[key] = vals
+		}
+	} else {
+		for key, val := range e.QueryStringParameters {
+			values.Set(key, val)
+		}
+	}
+	return values.Encode()
+}
+
+func (e *Request) response(resp *fasthttp.Response) Response {
+	out := Response{StatusCode: resp.StatusCode()}
+	if e.RequestContext.ELB != nil {
+		out.StatusDescription = fmt.Sprintf("%d %s", out.StatusCode, fasthttp.StatusMessage(out.StatusCode))
+	}
+
+	multi := make(map[string][]string)
+	for key, value := range resp.Header.All() {
+		name := string(key)
+		switch {
+		case strings.EqualFold(name, "Connection"):
+			// Hop-by-hop; the gateway manages the client connection.
+		case e.isV2() && strings.EqualFold(name, "Set-Cookie"):
+			out.Cookies = append(out.Cookies, string(value))
+		default:
+			multi[name] = append(multi[name], string(value))
+		}
+	}
+	if len(e.MultiValueHeaders) > 0 {
+		out.MultiValueHeaders = multi
+	} else {
+		// Single-value events cannot repeat a header; join the rest as RFC 9110
+		// allows, except Set-Cookie, where only the last value survives.
+		out.Headers = make(map[string]string, len(multi))
+		for name, values := range multi {
+			if strings.EqualFold(name, "Set-Cookie") {
+				out.Headers[name] = values[len(values)-1]
+				continue
+			}
+			out.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	body := resp.Body()
+	if isTextual(string(resp.Header.ContentType()), string(resp.Header.ContentEncoding())) {
+		out.Body = string(body)
+	} else {
+		out.Body = base64.StdEncoding.EncodeToString(body)
+		out.IsBase64Encoded = true
+	}
+	return out
+}
+
+// isTextual reports whether a body can be returned as a plain string.
+func isTextual(contentType, contentEncoding string) bool {
+	if contentEncoding != "" && contentEncoding != "identity" {
+		return false
+	}
+	contentType = strings.ToLower(contentType)
+	switch {
+	case contentType == "",
+		strings.HasPrefix(contentType, "text/"),
+		strings.Contains(contentType, "json"),
+		strings.Contains(contentType, "javascript"),
+		strings.Contains(contentType, "xml"),
+		strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+func newTestApp(t *testing.T) *gospa.App {
+	t.Helper()
+	cfg := Config(gospa.DefaultConfig())
+	cfg.RoutesDir = t.TempDir()
+	cfg.PublicOrigin = "https://example.com"
+	cfg.DisableCSRF = true
+	app := gospa.New(cfg)
+	app.Fiber.Post("/echo", func(c gofiber.Ctx) error {
+		c.Cookie(&gofiber.Cookie{Name: "a", Value: "1"})
+		c.Cookie(&gofiber.Cookie{Name: "b", Value: "2"})
+		return c.JSON(gofiber.Map{
+			"q":      c.Query("q"),
+			"cookie": c.Cookies("sid"),
+			"body":   string(c.Body()),
+			"ip":     c.IP(),
+		})
+	})
+	app.Fiber.Get("/bin", func(c gofiber.Ctx) error {
+		c.Set("Content-Type", "image/png")
+		return c.Send([]byte{0x89, 'P', 'N', 'G'})
+	})
+	t.Cleanup(func() { _ = app.Fiber.Shutdown() })
+	return app
+}
+
+func TestHandlerPayloadV2(t *testing.T) {
+	handle := Handler(newTestApp(t))
+	resp, err := handle(context.Background(), Request{
+		Version:         "2.0",
+		RawPath:         "/echo",
+		RawQueryString:  "q=hello",
+		Cookies:         []string{"sid=abc"},
+		Headers:         map[string]string{"host": "example.com", "content-type": "text/plain"},
+		Body:            base64.StdEncoding.EncodeToString([]byte("payload")),
+		IsBase64Encoded: true,
+		RequestContext: RequestContext{HTTP: struct {
+			Method   string `json:"method,omitempty"`
+			SourceIP string `json:"sourceIp,omitempty"`
+		}{Method: "POST", SourceIP: "203.0.113.9"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || resp.IsBase64Encoded {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	want := `{"body":"payload","cookie":"abc","ip":"203.0.113.9","q":"hello"}`
+	if resp.Body != want {
+		t.Fatalf("body = %s, want %s", resp.Body, want)
+	}
+	if len(resp.Cookies) != 2 {
+		t.Fatalf("expected both cookies in the cookies field, got %v", resp.Cookies)
+	}
+	if _, ok := resp.Headers["Set-Cookie"]; ok {
+		t.Fatal("payload 2.0 responses must return cookies outside the headers")
+	}
+}
+
+func TestHandlerPayloadV1(t *testing.T) {
+	handle := Handler(newTestApp(t))
+	resp, err := handle(context.Background(), Request{
+		HTTPMethod:                      "POST",
+		Path:                            "/echo",
+		MultiValueQueryStringParameters: map[string][]string{"q": {"a b"}},
+		MultiValueHeaders:               map[string][]string{"Host": {"example.com"}, "Cookie": {"sid=v1"}},
+		Body:                            "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.MultiValueHeaders["Set-Cookie"]; len(got) != 2 {
+		t.Fatalf("expected two Set-Cookie values, got %v", resp.MultiValueHeaders)
+	}
+	if resp.Body != `{"body":"x","cookie":"v1","ip":"0.0.0.0","q":"a b"}` {
+		t.Fatalf("unexpected body %s", resp.Body)
+	}
+
+	resp, err = handle(context.Background(), Request{HTTPMethod: "GET", Path: "/bin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsBase64Encoded || resp.Body != base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G'}) {
+		t.Fatalf("binary body not base64 encoded: %+v", resp)
+	}
+}
+
+func TestConfigDisablesWebSocket(t *testing.T) {
+	app := newTestApp(t)
+	if app.Config.EnableWebSocket || app.Hub != nil {
+		t.Fatal("request mode must not start a WebSocket hub")
+	}
+}
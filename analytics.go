@@ -0,0 +1,74 @@
+package gospa
+
+import (
+	"context"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/analytics"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// newAnalytics builds App.Analytics from Config.Analytics. Counts go to
+// Storage unless the analytics config names its own.
+func newAnalytics(config *Config) *analytics.Collector {
+	if config.Analytics == nil {
+		return nil
+	}
+	cfg := *config.Analytics
+	if cfg.Storage == nil {
+		cfg.Storage = config.Storage
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = config.Logger
+	}
+	return analytics.New(cfg)
+}
+
+// recordPageView feeds OnNavigate events to App.Analytics. Prefetches and
+// error responses are not page views.
+func (a *App) recordPageView(ev NavEvent) {
+	if ev.Prefetch || ev.Status >= fiberpkg.StatusBadRequest {
+		return
+	}
+	a.Analytics.PageView(ev.Time, ev.RoutePath, ev.SPA, ev.Referrer, ev.Host)
+	if a.Config.RequestMode {
+		// No flush loop runs in RequestMode.
+		if err := a.Analytics.Flush(context.Background()); err != nil {
+			a.Logger().Warn("analytics flush failed", "err", err)
+		}
+	}
+}
+
+// recordWSConnect counts WebSocket connections.
+func (a *App) recordWSConnect(*fiber.WSClient) {
+	a.Analytics.Event(time.Now(), "ws.connect")
+}
+
+// recordWSMessage counts WebSocket messages by type before handling them
+// as usual.
+func (a *App) recordWSMessage(client *fiber.WSClient, msg fiber.WSMessage) {
+	a.Analytics.Event(time.Now(), "ws."+msg.Type)
+	fiber.DefaultMessageHandler(client, msg)
+}
+
+// setupAnalyticsRoutes mounts the analytics dashboard: always at
+// /_gospa/dev/analytics in DevMode, and at Analytics.DashboardPath when a
+// DashboardMiddleware guards it.
+func (a *App) setupAnalyticsRoutes() {
+	if a.Analytics == nil {
+		return
+	}
+	cfg := a.Analytics.Config()
+	if a.Config.DevMode {
+		a.Fiber.Get("/_gospa/dev/analytics", a.Analytics.Handler())
+	}
+	if cfg.DashboardPath == "" {
+		return
+	}
+	if cfg.DashboardMiddleware == nil {
+		a.Logger().Warn("analytics dashboard not mounted: DashboardPath requires DashboardMiddleware", "path", cfg.DashboardPath)
+		return
+	}
+	a.Fiber.Get(cfg.DashboardPath, cfg.DashboardMiddleware, a.Analytics.Handler())
+}
@@ -0,0 +1,380 @@
+// Package analytics counts page views and realtime events for GoSPA
+// applications without cookies, client scripts or visitor fingerprints.
+//
+// Only aggregate daily counts are kept: views per route pattern (not per
+// concrete URL, which can carry IDs), SPA versus full page loads, the host of
+// external referrers, and named events such as WebSocket message types.
+// Nothing identifies a visitor, so there are no unique-visitor numbers.
+//
+// Counts are buffered in memory and written to a store.Storage periodically.
+// Backends implementing store.CounterStorage and store.SetStorage (the memory
+// and Redis stores) are updated atomically and can be shared by instances;
+// others are updated with a read-modify-write per day.
+//
+//	app := gospa.New(gospa.Config{
+//		Analytics: &analytics.Config{
+//			DashboardPath:       "/admin/analytics",
+//			DashboardMiddleware: requireAdmin,
+//		},
+//	})
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Count kinds, used in storage keys.
+const (
+	kindView  = "view"
+	kindNav   = "nav"
+	kindRef   = "ref"
+	kindEvent = "event"
+)
+
+// Navigation types counted under kindNav.
+const (
+	navSPA  = "spa"
+	navFull = "full"
+)
+
+// OtherName collects the names counted after Config.MaxKeys distinct names
+// of one kind were seen in a day.
+const OtherName = "(other)"
+
+const (
+	keyPrefix   = "gospa:analytics:"
+	dayLayout   = "20060102"
+	maxNameSize = 200
+)
+
+// Config configures a Collector.
+type Config struct {
+	// Storage keeps the daily counts. gospa.New defaults it to Config.Storage;
+	// New defaults it to a new store.MemoryStorage.
+	Storage store.Storage
+	// Retention is how long daily counts are kept (default 90 days).
+	Retention time.Duration
+	// FlushInterval is how often buffered counts are written to Storage
+	// (default 10s).
+	FlushInterval time.Duration
+	// MaxKeys bounds the distinct pages, referrers and events one process
+	// counts per day (default 1000). Further names are counted as OtherName,
+	// so crafted paths or event names cannot grow Storage without bound.
+	MaxKeys int
+	// DashboardPath serves the dashboard outside DevMode, e.g.
+	// "/admin/analytics". It is only mounted together with
+	// DashboardMiddleware. In DevMode the dashboard is always available at
+	// /_gospa/dev/analytics.
+	DashboardPath string
+	// DashboardMiddleware authorizes dashboard requests, for example by
+	// checking an admin session.
+	DashboardMiddleware fiberpkg.Handler
+	// Logger reports failed flushes (default slog.Default()).
+	Logger *slog.Logger
+}
+
+// countKey identifies one buffered counter.
+type countKey struct {
+	day, kind, name string
+}
+
+// Collector buffers counts and writes them to Storage.
+type Collector struct {
+	cfg Config
+
+	mu      sync.Mutex
+	pending map[countKey]int64
+	// seenDay and seen track the names counted today per kind, for MaxKeys.
+	seenDay string
+	seen    map[string]map[string]struct{}
+
+	// flushMu serializes flushes, so read-modify-write updates of backends
+	// without counters do not race within the process.
+	flushMu sync.Mutex
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// New creates a Collector. Call Start to flush periodically, or Flush after
+// recording when no background work may run (e.g. in RequestMode).
+func New(cfg Config) *Collector {
+	if cfg.Storage == nil {
+		cfg.Storage = store.NewMemoryStorage()
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 90 * 24 * time.Hour
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = 1000
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Collector{
+		cfg:     cfg,
+		pending: make(map[countKey]int64),
+		seen:    make(map[string]map[string]struct{}),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Config returns the collector's configuration with defaults applied.
+func (c *Collector) Config() Config {
+	return c.cfg
+}
+
+// PageView counts a view of routePath at t. spa reports a client-side
+// navigation. Only the host of referrer is kept, and only when it differs
+// from host, the site's own host.
+func (c *Collector) PageView(t time.Time, routePath string, spa bool, referrer, host string) {
+	day := t.UTC().Format(dayLayout)
+	nav := navFull
+	if spa {
+		nav = navSPA
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(day, kindView, routePath)
+	c.addLocked(day, kindNav, nav)
+	if ref := referrerHost(referrer); ref != "" && !strings.EqualFold(ref, host) {
+		c.addLocked(day, kindRef, ref)
+	}
+}
+
+// Event counts one occurrence of the named event at t.
+func (c *Collector) Event(t time.Time, name string) {
+	day := t.UTC().Format(dayLayout)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(day, kindEvent, name)
+}
+
+// addLocked buffers one count; c.mu must be held.
+func (c *Collector) addLocked(day, kind, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	if len(name) > maxNameSize {
+		name = name[:maxNameSize]
+	}
+	if day != c.seenDay {
+		c.seenDay = day
+		c.seen = make(map[string]map[string]struct{})
+	}
+	names := c.seen[kind]
+	if names == nil {
+		names = make(map[string]struct{})
+		c.seen[kind] = names
+	}
+	if _, ok := names[name]; !ok {
+		if len(names) >= c.cfg.MaxKeys {
+			name = OtherName
+		}
+		names[name] = struct{}{}
+	}
+	c.pending[countKey{day, kind, name}]++
+}
+
+// referrerHost returns the lowercased host of a Referer header value.
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// Start flushes buffered counts every FlushInterval until ctx is done or
+// Close is called.
+func (c *Collector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.cfg.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				if err := c.Flush(ctx); err != nil {
+					c.cfg.Logger.Warn("analytics flush failed", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the flush loop started by Start and writes what is buffered.
+func (c *Collector) Close(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	return c.Flush(ctx)
+}
+
+// Flush writes the buffered counts to Storage. Counts that could not be
+// written stay buffered for the next flush.
+func (c *Collector) Flush(ctx context.Context) error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[countKey]int64)
+	c.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	failed, err := c.write(ctx, batch)
+	if len(failed) > 0 {
+		c.mu.Lock()
+		for k, n := range failed {
+			c.pending[k] += n
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// write stores batch and returns the counts it could not store.
+func (c *Collector) write(ctx context.Context, batch map[countKey]int64) (map[countKey]int64, error) {
+	counters, okCounters := c.cfg.Storage.(store.CounterStorage)
+	sets, okSets := c.cfg.Storage.(store.SetStorage)
+	if okCounters && okSets {
+		failed := make(map[countKey]int64)
+		var errs []error
+		for k, n := range batch {
+			if _, err := counters.IncrBy(ctx, counterKey(k), n, c.cfg.Retention); err != nil {
+				failed[k] = n
+				errs = append(errs, err)
+				continue
+			}
+			if err := sets.SAdd(ctx, indexKey(k.day, k.kind), k.name, c.cfg.Retention); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return failed, errors.Join(errs...)
+	}
+
+	byDay := make(map[string]map[countKey]int64)
+	for k, n := range batch {
+		if byDay[k.day] == nil {
+			byDay[k.day] = make(map[countKey]int64)
+		}
+		byDay[k.day][k] = n
+	}
+	failed := make(map[countKey]int64)
+	var errs []error
+	for day, counts := range byDay {
+		doc, err := c.readDay(ctx, day)
+		if err == nil {
+			for k, n := range counts {
+				if doc[k.kind] == nil {
+					doc[k.kind] = make(map[string]int64)
+				}
+				doc[k.kind][k.name] += n
+			}
+			err = c.writeDay(ctx, day, doc)
+		}
+		if err != nil {
+			for k, n := range counts {
+				failed[k] = n
+			}
+			errs = append(errs, err)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// dayDoc holds one day's counts by kind and name, for backends without
+// counters.
+type dayDoc map[string]map[string]int64
+
+func (c *Collector) readDay(ctx context.Context, day string) (dayDoc, error) {
+	doc := make(dayDoc)
+	raw, err := c.cfg.Storage.Get(ctx, dayKey(day))
+	if errors.Is(err, store.ErrNotFound) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("analytics: decode %s: %w", day, err)
+	}
+	return doc, nil
+}
+
+func (c *Collector) writeDay(ctx context.Context, day string, doc dayDoc) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return c.cfg.Storage.Set(ctx, dayKey(day), raw, c.cfg.Retention)
+}
+
+// loadDay reads one day's counts from Storage.
+func (c *Collector) loadDay(ctx context.Context, day string) (dayDoc, error) {
+	_, okCounters := c.cfg.Storage.(store.CounterStorage)
+	sets, okSets := c.cfg.Storage.(store.SetStorage)
+	if !okCounters || !okSets {
+		return c.readDay(ctx, day)
+	}
+	doc := make(dayDoc)
+	for _, kind := range []string{kindView, kindNav, kindRef, kindEvent} {
+		names, err := sets.SMembers(ctx, indexKey(day, kind))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			raw, err := c.cfg.Storage.Get(ctx, counterKey(countKey{day, kind, name}))
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.ParseInt(string(raw), 10, 64)
+			if err != nil {
+				continue
+			}
+			if doc[kind] == nil {
+				doc[kind] = make(map[string]int64)
+			}
+			doc[kind][name] = n
+		}
+	}
+	return doc, nil
+}
+
+func counterKey(k countKey) string {
+	return keyPrefix + k.day + ":" + k.kind + ":" + k.name
+}
+
+func indexKey(day, kind string) string {
+	return keyPrefix + "index:" + day + ":" + kind
+}
+
+func dayKey(day string) string {
+	return keyPrefix + "day:" + day
+}
@@ -0,0 +1,110 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// plainStorage hides the counter and set capabilities of MemoryStorage.
+type plainStorage struct {
+	s *store.MemoryStorage
+}
+
+func (p plainStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return p.s.Get(ctx, key)
+}
+
+func (p plainStorage) Set(ctx context.Context, key string, val []byte, exp time.Duration) error {
+	return p.s.Set(ctx, key, val, exp)
+}
+
+func (p plainStorage) Delete(ctx context.Context, key string) error {
+	return p.s.Delete(ctx, key)
+}
+
+func TestCollectorReport(t *testing.T) {
+	for name, storage := range map[string]store.Storage{
+		"counters": store.NewMemoryStorage(),
+		"plain":    plainStorage{store.NewMemoryStorage()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := New(Config{Storage: storage, MaxKeys: 2})
+			ctx := context.Background()
+			now := time.Now()
+
+			c.PageView(now, "/blog/:slug", false, "https://news.example.org/item?id=1", "example.com")
+			c.PageView(now, "/blog/:slug", true, "https://example.com/", "example.com")
+			if err := c.Flush(ctx); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			c.PageView(now, "/", true, "", "example.com")
+			c.PageView(now, "/about", false, "", "example.com")
+			c.Event(now, "ws.connect")
+			c.PageView(now.AddDate(0, 0, -1), "/", false, "", "example.com")
+
+			r, err := c.Report(ctx, 2)
+			if err != nil {
+				t.Fatalf("Report: %v", err)
+			}
+			if r.Views != 5 || r.SPAViews != 2 || len(r.Days) != 2 || r.Days[1].Views != 4 {
+				t.Fatalf("unexpected totals: %+v", r)
+			}
+			want := []Count{{"/blog/:slug", 2}, {OtherName, 1}, {"/", 2}}
+			got := map[string]int64{}
+			for _, p := range r.Pages {
+				got[p.Name] = p.Count
+			}
+			for _, w := range want {
+				if got[w.Name] != w.Count {
+					t.Errorf("pages[%q] = %d, want %d (%+v)", w.Name, got[w.Name], w.Count, r.Pages)
+				}
+			}
+			if len(r.Referrers) != 1 || r.Referrers[0] != (Count{"news.example.org", 1}) {
+				t.Errorf("referrers = %+v", r.Referrers)
+			}
+			if len(r.Events) != 1 || r.Events[0] != (Count{"ws.connect", 1}) {
+				t.Errorf("events = %+v", r.Events)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := New(Config{})
+	c.PageView(time.Now(), "/<script>", false, "", "")
+
+	app := fiberpkg.New()
+	app.Get("/analytics", c.Handler())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/analytics?format=json&days=1", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var r Report
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	_ = resp.Body.Close()
+	if r.Views != 1 || len(r.Days) != 1 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/analytics", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "&lt;script&gt;") || strings.Contains(string(body), "<script>") {
+		t.Fatalf("dashboard must escape names: %s", body)
+	}
+}
@@ -0,0 +1,182 @@
+package analytics
+
+import (
+	"context"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Count is the number of times a page, referrer or event was counted.
+type Count struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// DayStats are the page views of one day.
+type DayStats struct {
+	// Day is the UTC date, formatted as 2006-01-02.
+	Day      string `json:"day"`
+	Views    int64  `json:"views"`
+	SPAViews int64  `json:"spaViews"`
+}
+
+// Report aggregates the counts of a range of days.
+type Report struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Views     int64      `json:"views"`
+	SPAViews  int64      `json:"spaViews"`
+	Days      []DayStats `json:"days"`
+	Pages     []Count    `json:"pages"`
+	Referrers []Count    `json:"referrers"`
+	Events    []Count    `json:"events"`
+}
+
+// Report flushes buffered counts and returns the counts of the last days
+// days, up to and including today (UTC).
+func (c *Collector) Report(ctx context.Context, days int) (*Report, error) {
+	if days <= 0 {
+		days = 1
+	}
+	if err := c.Flush(ctx); err != nil {
+		c.cfg.Logger.Warn("analytics flush failed", "err", err)
+	}
+
+	today := time.Now().UTC()
+	pages := make(map[string]int64)
+	refs := make(map[string]int64)
+	events := make(map[string]int64)
+	r := &Report{
+		From: today.AddDate(0, 0, -(days - 1)).Format("2006-01-02"),
+		To:   today.Format("2006-01-02"),
+		Days: make([]DayStats, 0, days),
+	}
+	for i := days - 1; i >= 0; i-- {
+		t := today.AddDate(0, 0, -i)
+		doc, err := c.loadDay(ctx, t.Format(dayLayout))
+		if err != nil {
+			return nil, err
+		}
+		day := DayStats{
+			Day:      t.Format("2006-01-02"),
+			Views:    doc[kindNav][navSPA] + doc[kindNav][navFull],
+			SPAViews: doc[kindNav][navSPA],
+		}
+		r.Days = append(r.Days, day)
+		r.Views += day.Views
+		r.SPAViews += day.SPAViews
+		addCounts(pages, doc[kindView])
+		addCounts(refs, doc[kindRef])
+		addCounts(events, doc[kindEvent])
+	}
+	r.Pages = sortedCounts(pages)
+	r.Referrers = sortedCounts(refs)
+	r.Events = sortedCounts(events)
+	return r, nil
+}
+
+func addCounts(dst, src map[string]int64) {
+	for name, n := range src {
+		dst[name] += n
+	}
+}
+
+// sortedCounts orders counts by count, highest first, then by name.
+func sortedCounts(m map[string]int64) []Count {
+	out := make([]Count, 0, len(m))
+	for name, n := range m {
+		out = append(out, Count{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Handler serves the dashboard: an HTML page, or the Report as JSON when the
+// request asks for application/json or sets ?format=json. ?days= selects the
+// range (default 30, capped at the retention period).
+func (c *Collector) Handler() fiberpkg.Handler {
+	maxDays := int(c.cfg.Retention / (24 * time.Hour))
+	if maxDays < 1 {
+		maxDays = 1
+	}
+	return func(ctx fiberpkg.Ctx) error {
+		days, err := strconv.Atoi(ctx.Query("days", "30"))
+		if err != nil || days < 1 {
+			days = 30
+		}
+		if days > maxDays {
+			days = maxDays
+		}
+		report, err := c.Report(ctx.Context(), days)
+		if err != nil {
+			return fiberpkg.NewError(fiberpkg.StatusInternalServerError, "failed to read analytics")
+		}
+		ctx.Set("Cache-Control", "no-store")
+		if ctx.Query("format") == "json" || strings.Contains(ctx.Get(fiberpkg.HeaderAccept), fiberpkg.MIMEApplicationJSON) {
+			return ctx.JSON(report)
+		}
+		var sb strings.Builder
+		if err := dashboardTemplate.Execute(&sb, report); err != nil {
+			return err
+		}
+		ctx.Set(fiberpkg.HeaderContentType, fiberpkg.MIMETextHTMLCharsetUTF8)
+		return ctx.SendString(sb.String())
+	}
+}
+
+var dashboardTemplate = template.Must(template.New("analytics").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Analytics</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2937; }
+h1 { font-size: 1.4rem; }
+h2 { font-size: 1.1rem; margin-top: 2rem; }
+table { border-collapse: collapse; min-width: 24rem; }
+th, td { text-align: left; padding: 0.25rem 1rem 0.25rem 0; border-bottom: 1px solid #e5e7eb; }
+td.n { text-align: right; font-variant-numeric: tabular-nums; }
+.summary { color: #4b5563; }
+</style>
+</head>
+<body>
+<h1>Analytics</h1>
+<p class="summary">{{.From}} to {{.To}} (UTC): {{.Views}} page views, {{.SPAViews}} of them SPA navigations.</p>
+<h2>Daily views</h2>
+<table>
+<tr><th>Day</th><th>Views</th><th>SPA</th></tr>
+{{range .Days}}<tr><td>{{.Day}}</td><td class="n">{{.Views}}</td><td class="n">{{.SPAViews}}</td></tr>
+{{end}}</table>
+<h2>Pages</h2>
+<table>
+<tr><th>Route</th><th>Views</th></tr>
+{{range .Pages}}<tr><td>{{.Name}}</td><td class="n">{{.Count}}</td></tr>
+{{else}}<tr><td colspan="2">No views yet.</td></tr>
+{{end}}</table>
+<h2>Referrers</h2>
+<table>
+<tr><th>Host</th><th>Views</th></tr>
+{{range .Referrers}}<tr><td>{{.Name}}</td><td class="n">{{.Count}}</td></tr>
+{{else}}<tr><td colspan="2">No external referrers yet.</td></tr>
+{{end}}</table>
+<h2>Events</h2>
+<table>
+<tr><th>Event</th><th>Count</th></tr>
+{{range .Events}}<tr><td>{{.Name}}</td><td class="n">{{.Count}}</td></tr>
+{{else}}<tr><td colspan="2">No events yet.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,63 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/analytics"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestAnalyticsCountsPageViews(t *testing.T) {
+	app := New(Config{Analytics: &analytics.Config{}})
+	defer func() { _ = app.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-analytics-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>ok</p>")
+			return err
+		})
+	})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	for _, headers := range []map[string]string{
+		nil,
+		{"X-Requested-With": "GoSPA-Navigate", "Referer": "https://search.example.net/?q=gospa"},
+		{"X-Requested-With": "GoSPA-Navigate", "Purpose": "prefetch"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, routePath, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	r, err := app.Analytics.Report(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if r.Views != 2 || r.SPAViews != 1 {
+		t.Fatalf("views = %d (spa %d), want 2 (1); prefetches must not count", r.Views, r.SPAViews)
+	}
+	if len(r.Pages) != 1 || r.Pages[0].Name != routePath {
+		t.Fatalf("pages = %+v", r.Pages)
+	}
+	if len(r.Referrers) != 1 || r.Referrers[0].Name != "search.example.net" {
+		t.Fatalf("referrers = %+v", r.Referrers)
+	}
+}
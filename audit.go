@@ -0,0 +1,110 @@
+package gospa
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/audit"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	json "github.com/goccy/go-json"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// newAuditor builds App.Audit from Config.Audit.
+func newAuditor(config *Config) *audit.Auditor {
+	if config.Audit == nil {
+		return nil
+	}
+	cfg := *config.Audit
+	if cfg.Logger == nil {
+		cfg.Logger = config.Logger
+	}
+	return audit.New(cfg)
+}
+
+// auditRemoteAction records remote action requests, including those the
+// rate limiter or RemoteActionMiddleware rejects. It runs right after the
+// session middleware.
+func (a *App) auditRemoteAction(c fiberpkg.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	cfg := a.Audit.Config()
+	e := audit.Entry{
+		Time:      start,
+		Kind:      audit.KindRemote,
+		Action:    strings.Clone(c.Params("name")),
+		SessionID: fiber.SessionClientID(c),
+		RequestID: fiber.GetRequestID(c),
+		IP:        strings.Clone(c.IP()),
+		InputHash: audit.HashInput(c.Body()),
+		Status:    c.Response().StatusCode(),
+		Duration:  time.Since(start),
+	}
+	if cfg.Actor != nil {
+		e.Actor = cfg.Actor(c)
+	} else {
+		e.Actor = e.SessionID
+	}
+	if err != nil {
+		ae := Classify(err)
+		e.Code, e.Status = ae.Code, ae.HTTPStatus
+	} else {
+		var body struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(c.Response().Body(), &body)
+		switch {
+		case body.Code != "":
+			e.Code = body.Code
+		case e.Status < fiberpkg.StatusBadRequest:
+			e.Code = audit.CodeSuccess
+		default:
+			e.Code = string(fiber.StatusErrorCode(e.Status))
+		}
+	}
+	a.Audit.Record(e)
+	return err
+}
+
+// auditWSAction records WebSocket "action" messages.
+func (a *App) auditWSAction(client *fiber.WSClient, ev fiber.ActionEvent) {
+	cfg := a.Audit.Config()
+	e := audit.Entry{
+		Time:      time.Now().Add(-ev.Duration),
+		Kind:      audit.KindWS,
+		Action:    ev.Action,
+		SessionID: client.SessionID,
+		RequestID: client.RequestID,
+		InputHash: audit.HashInput(wsPayloadBytes(ev.Payload)),
+		Code:      string(ev.Code),
+		Duration:  ev.Duration,
+	}
+	if e.Code == "" {
+		e.Code = audit.CodeSuccess
+	}
+	if client.Conn != nil {
+		e.IP = client.Conn.IP()
+	}
+	if cfg.WSActor != nil {
+		e.Actor = cfg.WSActor(client)
+	} else {
+		e.Actor = e.SessionID
+	}
+	a.Audit.Record(e)
+}
+
+// wsPayloadBytes returns the bytes an action payload arrived as, or its JSON
+// encoding when the message was decoded eagerly.
+func wsPayloadBytes(payload interface{}) []byte {
+	switch p := payload.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return p
+	case json.RawMessage:
+		return p
+	}
+	raw, _ := json.Marshal(payload)
+	return raw
+}
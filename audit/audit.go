@@ -0,0 +1,211 @@
+// Package audit records who invoked which remote action or WebSocket action,
+// with a hash of the input, the result code and the duration, for
+// applications that must keep an audit trail of administrative changes.
+//
+// Entries go to one or more Sinks: the application log (NewLoggerSink), a
+// store.Storage (NewStorageSink) or an HTTP endpoint (NewWebhookSink). Inputs
+// are never recorded, only their SHA-256 hash, so entries can be compared
+// against a known input without copying personal data into the trail.
+//
+//	app := gospa.New(gospa.Config{
+//		Audit: &audit.Config{
+//			Sinks: []audit.Sink{audit.NewStorageSink(storage, 365*24*time.Hour)},
+//			Actor: func(c fiber.Ctx) string { return currentUserID(c) },
+//		},
+//	})
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Entry kinds.
+const (
+	// KindRemote is a remote action called over HTTP.
+	KindRemote = "remote"
+	// KindWS is an "action" message on a WebSocket connection.
+	KindWS = "ws"
+)
+
+// CodeSuccess is the Code of entries whose action completed.
+const CodeSuccess = "SUCCESS"
+
+// Entry is one audited action invocation.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Action string    `json:"action"`
+	// Actor is who invoked the action, see Config.Actor.
+	Actor     string `json:"actor,omitempty"`
+	SessionID string `json:"sessionId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	// InputHash is the hex SHA-256 of the request body or message payload,
+	// empty when there was none.
+	InputHash string `json:"inputHash,omitempty"`
+	// Code is CodeSuccess or the error code sent to the client.
+	Code string `json:"code"`
+	// Status is the HTTP status of remote actions, 0 for WebSocket actions.
+	Status   int           `json:"status,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink stores or forwards entries.
+type Sink interface {
+	Write(ctx context.Context, e Entry) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(ctx context.Context, e Entry) error
+
+// Write calls f.
+func (f SinkFunc) Write(ctx context.Context, e Entry) error {
+	return f(ctx, e)
+}
+
+// Config configures an Auditor.
+type Config struct {
+	// Sinks receive every entry, in order. The default is a LoggerSink on
+	// Logger.
+	Sinks []Sink
+	// Actor names who made a remote action request, e.g. the user ID your
+	// RemoteActionMiddleware stored in Locals. The default is the session
+	// client ID.
+	Actor func(c fiberpkg.Ctx) string
+	// WSActor names who is behind a WebSocket connection; the upgrade
+	// request's Locals are available through client.Conn.Locals. The default
+	// is the client's SessionID.
+	WSActor func(client *fiber.WSClient) string
+	// QueueSize bounds the entries waiting for the sinks once Start was
+	// called (default 1024). When the queue is full, Record writes the entry
+	// itself rather than dropping it.
+	QueueSize int
+	// Logger reports sink errors and is the default sink (default
+	// slog.Default()).
+	Logger *slog.Logger
+}
+
+// Auditor hands entries to the configured sinks.
+type Auditor struct {
+	cfg Config
+
+	mu      sync.RWMutex
+	queue   chan Entry
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+// New creates an Auditor. Without Start, Record writes to the sinks before
+// it returns.
+func New(cfg Config) *Auditor {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if len(cfg.Sinks) == 0 {
+		cfg.Sinks = []Sink{NewLoggerSink(cfg.Logger)}
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	return &Auditor{
+		cfg:   cfg,
+		queue: make(chan Entry, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Config returns the auditor's configuration with defaults applied.
+func (a *Auditor) Config() Config {
+	return a.cfg
+}
+
+// Start writes queued entries in the background until Close is called. The
+// sinks get ctx without its cancellation, so entries queued when the
+// application shuts down are still written.
+func (a *Auditor) Start(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started || a.closed {
+		return
+	}
+	a.started = true
+	go func() {
+		defer close(a.done)
+		for e := range a.queue {
+			a.write(context.WithoutCancel(ctx), e)
+		}
+	}()
+}
+
+// Record audits e. Once Start was called it queues e; otherwise, or when the
+// queue is full, it writes e to the sinks before returning.
+func (a *Auditor) Record(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	a.mu.RLock()
+	if a.started && !a.closed {
+		select {
+		case a.queue <- e:
+			a.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	a.mu.RUnlock()
+	a.write(context.Background(), e)
+}
+
+// Close stops accepting queued entries and waits until the queued ones were
+// written or ctx is done. Later entries are written synchronously.
+func (a *Auditor) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	started := a.started
+	close(a.queue)
+	a.mu.Unlock()
+	if !started {
+		return nil
+	}
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *Auditor) write(ctx context.Context, e Entry) {
+	var errs []error
+	for _, s := range a.cfg.Sinks {
+		if err := s.Write(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		a.cfg.Logger.Error("audit sink failed", "kind", e.Kind, "action", e.Action, "request_id", e.RequestID, "err", err)
+	}
+}
+
+// HashInput returns the hex SHA-256 of input, or "" when it is empty.
+func HashInput(input []byte) string {
+	if len(input) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(input)
+	return hex.EncodeToString(sum[:])
+}
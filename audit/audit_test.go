@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+// plainStorage hides the set methods of MemoryStorage.
+type plainStorage struct{ store.Storage }
+
+func TestStorageSinkEntries(t *testing.T) {
+	for name, s := range map[string]store.Storage{
+		"sets":  store.NewMemoryStorage(),
+		"plain": plainStorage{store.NewMemoryStorage()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			sink := NewStorageSink(s, 0)
+			now := time.Now()
+			for i, action := range []string{"deleteUser", "setRole"} {
+				e := Entry{Time: now.Add(time.Duration(i) * time.Millisecond), Kind: KindRemote, Action: action, Actor: "admin", Code: CodeSuccess}
+				if err := sink.Write(ctx, e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			entries, err := sink.Entries(ctx, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 2 || entries[0].Action != "deleteUser" || entries[1].Action != "setRole" || entries[1].Actor != "admin" {
+				t.Fatalf("entries = %+v", entries)
+			}
+			if entries, _ := sink.Entries(ctx, now.AddDate(0, 0, -1)); len(entries) != 0 {
+				t.Fatalf("yesterday = %+v", entries)
+			}
+		})
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Entry
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &got)
+		if got.Action == "fail" {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, http.Header{"Authorization": {"Bearer t"}})
+	if err := sink.Write(context.Background(), Entry{Kind: KindWS, Action: "move", Code: "ACTION_NOT_FOUND"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Action != "move" || got.Code != "ACTION_NOT_FOUND" || auth != "Bearer t" {
+		t.Fatalf("webhook got %+v, auth %q", got, auth)
+	}
+	if err := sink.Write(context.Background(), Entry{Action: "fail"}); err == nil {
+		t.Fatal("expected an error for a 502 reply")
+	}
+}
+
+func TestAuditorQueuesUntilClose(t *testing.T) {
+	var mu sync.Mutex
+	var actions []string
+	a := New(Config{Sinks: []Sink{SinkFunc(func(_ context.Context, e Entry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		actions = append(actions, e.Action)
+		return nil
+	})}})
+
+	a.Record(Entry{Action: "sync"})
+	mu.Lock()
+	if len(actions) != 1 {
+		t.Fatalf("Record before Start did not write synchronously: %v", actions)
+	}
+	mu.Unlock()
+
+	a.Start(context.Background())
+	for _, action := range []string{"a", "b", "c"} {
+		a.Record(Entry{Action: action})
+	}
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	a.Record(Entry{Action: "after"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(actions) != 5 || actions[4] != "after" {
+		t.Fatalf("actions = %v", actions)
+	}
+}
+
+func TestHashInput(t *testing.T) {
+	if HashInput(nil) != "" {
+		t.Fatal("empty input hashed")
+	}
+	if got := HashInput([]byte("abc")); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("HashInput(abc) = %s", got)
+	}
+}
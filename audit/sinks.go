@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+// NewLoggerSink writes entries to l at level Info.
+func NewLoggerSink(l *slog.Logger) Sink {
+	return SinkFunc(func(ctx context.Context, e Entry) error {
+		l.LogAttrs(ctx, slog.LevelInfo, "audit",
+			slog.String("kind", e.Kind),
+			slog.String("action", e.Action),
+			slog.String("actor", e.Actor),
+			slog.String("session_id", e.SessionID),
+			slog.String("request_id", e.RequestID),
+			slog.String("ip", e.IP),
+			slog.String("input_sha256", e.InputHash),
+			slog.String("code", e.Code),
+			slog.Int("status", e.Status),
+			slog.Duration("duration", e.Duration),
+		)
+		return nil
+	})
+}
+
+const (
+	keyPrefix = "gospa:audit:"
+	dayLayout = "20060102"
+)
+
+// StorageSink keeps entries in a store.Storage for Retention, grouped by UTC
+// day. Backends implementing store.SetStorage (the memory and Redis stores)
+// store each entry under its own key and can be shared by instances; others
+// keep one JSON document per day, rewritten for each entry.
+type StorageSink struct {
+	storage   store.Storage
+	retention time.Duration
+	seq       atomic.Uint64
+	// mu serializes read-modify-write updates of day documents.
+	mu sync.Mutex
+}
+
+// NewStorageSink creates a StorageSink. A retention of 0 keeps entries for
+// 365 days.
+func NewStorageSink(s store.Storage, retention time.Duration) *StorageSink {
+	if retention <= 0 {
+		retention = 365 * 24 * time.Hour
+	}
+	return &StorageSink{storage: s, retention: retention}
+}
+
+// Write implements Sink.
+func (s *StorageSink) Write(ctx context.Context, e Entry) error {
+	day := e.Time.UTC().Format(dayLayout)
+	sets, ok := s.storage.(store.SetStorage)
+	if !ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		entries, err := s.readDay(ctx, day)
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(append(entries, e))
+		if err != nil {
+			return err
+		}
+		return s.storage.Set(ctx, dayKey(day), raw, s.retention)
+	}
+
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	id := strconv.FormatInt(e.Time.UnixNano(), 10) + "-" + strconv.FormatUint(s.seq.Add(1), 10)
+	key := keyPrefix + day + ":" + id
+	if err := s.storage.Set(ctx, key, raw, s.retention); err != nil {
+		return err
+	}
+	return sets.SAdd(ctx, indexKey(day), key, s.retention)
+}
+
+// Entries returns the entries of the UTC day containing day, oldest first.
+func (s *StorageSink) Entries(ctx context.Context, day time.Time) ([]Entry, error) {
+	d := day.UTC().Format(dayLayout)
+	sets, ok := s.storage.(store.SetStorage)
+	if !ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.readDay(ctx, d)
+	}
+	keys, err := sets.SMembers(ctx, indexKey(d))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		raw, err := s.storage.Get(ctx, key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("audit: decode %s: %w", key, err)
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// readDay reads a day document; s.mu must be held.
+func (s *StorageSink) readDay(ctx context.Context, day string) ([]Entry, error) {
+	raw, err := s.storage.Get(ctx, dayKey(day))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("audit: decode %s: %w", day, err)
+	}
+	return entries, nil
+}
+
+func indexKey(day string) string {
+	return keyPrefix + "index:" + day
+}
+
+func dayKey(day string) string {
+	return keyPrefix + "day:" + day
+}
+
+// Webhook posts each entry as JSON to URL.
+type Webhook struct {
+	URL string
+	// Header is added to every request, e.g. an Authorization header.
+	Header http.Header
+	// Client defaults to an http.Client with a 5s timeout.
+	Client *http.Client
+}
+
+// NewWebhookSink returns a Webhook posting to url.
+func NewWebhookSink(url string, header http.Header) *Webhook {
+	return &Webhook{URL: url, Header: header}
+}
+
+var defaultWebhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// Write implements Sink. A non-2xx reply is an error.
+func (w *Webhook) Write(ctx context.Context, e Entry) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	for name, values := range w.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit: webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit: webhook: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package gospa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/audit"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestAuditRecordsRemoteActions(t *testing.T) {
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	routing.RegisterRemoteAction(name, func(_ context.Context, _ routing.RemoteContext, input interface{}) (interface{}, error) {
+		if m, _ := input.(map[string]interface{}); m["fail"] == true {
+			return nil, NewError(CodeForbidden, fiberpkg.StatusForbidden, "no")
+		}
+		return "ok", nil
+	})
+
+	var mu sync.Mutex
+	var entries []audit.Entry
+	app := New(Config{
+		DevMode:      true,
+		PublicOrigin: "http://localhost",
+		Audit: &audit.Config{
+			Sinks: []audit.Sink{audit.SinkFunc(func(_ context.Context, e audit.Entry) error {
+				mu.Lock()
+				defer mu.Unlock()
+				entries = append(entries, e)
+				return nil
+			})},
+			Actor: func(c fiberpkg.Ctx) string { return c.Get("X-User") },
+		},
+	})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	for _, body := range []string{`{"id":1}`, `{"fail":true}`} {
+		req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User", "admin")
+		addValidCSRF(req)
+		res, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = res.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	ok, failed := entries[0], entries[1]
+	if ok.Kind != audit.KindRemote || ok.Action != name || ok.Actor != "admin" || ok.Code != audit.CodeSuccess || ok.Status != fiberpkg.StatusOK {
+		t.Fatalf("success entry = %+v", ok)
+	}
+	if ok.InputHash != audit.HashInput([]byte(`{"id":1}`)) {
+		t.Fatalf("input hash = %q", ok.InputHash)
+	}
+	if failed.Code != CodeForbidden || failed.Status != fiberpkg.StatusForbidden {
+		t.Fatalf("failure entry = %+v", failed)
+	}
+}
+
+func TestAuditRecordsWSActions(t *testing.T) {
+	var got []audit.Entry
+	app := &App{Audit: audit.New(audit.Config{
+		Sinks: []audit.Sink{audit.SinkFunc(func(_ context.Context, e audit.Entry) error {
+			got = append(got, e)
+			return nil
+		})},
+	})}
+	client := fiber.NewWSClient("c1", nil, fiber.WebSocketConfig{})
+	client.SessionID = "s1"
+
+	app.auditWSAction(client, fiber.ActionEvent{Action: "move", Payload: map[string]interface{}{"x": 1}})
+	app.auditWSAction(client, fiber.ActionEvent{Action: "nope", Code: fiber.ErrorCodeActionNotFound})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if got[0].Kind != audit.KindWS || got[0].Actor != "s1" || got[0].Code != audit.CodeSuccess || got[0].InputHash != audit.HashInput([]byte(`{"x":1}`)) {
+		t.Fatalf("entry = %+v", got[0])
+	}
+	if got[1].Code != string(fiber.ErrorCodeActionNotFound) || got[1].InputHash != "" {
+		t.Fatalf("entry = %+v", got[1])
+	}
+}
@@ -0,0 +1,112 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	fiberpkg "github.com/gofiber/fiber/v3"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	// autoTLSAddr and autoTLSRedirectAddr are the ports Let's Encrypt
+	// validates against; both must be reachable from the internet.
+	autoTLSAddr         = ":443"
+	autoTLSRedirectAddr = ":80"
+	// defaultAutoTLSCacheDir keeps certificates across restarts when Storage
+	// is in-memory, so restarts do not run into Let's Encrypt rate limits.
+	defaultAutoTLSCacheDir = "./.gospa/certs"
+	autoTLSKeyPrefix       = "gospa:autocert:"
+)
+
+// RunAutoTLS serves the app over HTTPS on :443 with certificates for domains
+// obtained from Let's Encrypt and renewed automatically. Certificates are
+// cached in Config.Storage, so every instance sharing a Redis Storage reuses
+// them. Port :80 answers ACME challenges and redirects everything else to
+// HTTPS.
+func (a *App) RunAutoTLS(domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("gospa: RunAutoTLS requires at least one domain")
+	}
+	if err := a.Prepare(); err != nil {
+		return err
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      a.autoTLSCache(),
+		Email:      a.Config.AutoTLSEmail,
+	}
+	a.autoTLSHosts = make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		a.autoTLSHosts[strings.ToLower(d)] = struct{}{}
+	}
+
+	redirect := a.newHTTPServer(autoTLSRedirectAddr, manager.HTTPHandler(nil))
+	errc := make(chan error, 1)
+	go func() { errc <- ignoreServerClosed(redirect.ListenAndServe()) }()
+
+	a.Logger().Info("starting GoSPA (auto TLS)", "version", Version, "domains", domains)
+	a.printStartupSummary()
+	err := a.Fiber.Listen(autoTLSAddr, fiberpkg.ListenConfig{AutoCertManager: manager})
+	_ = redirect.Close()
+	if redirectErr := <-errc; err == nil {
+		err = redirectErr
+	}
+	return err
+}
+
+// autoTLSCache picks where certificates are cached: AutoTLSCacheDir if set,
+// else shared Storage, else (in-memory Storage) a local directory.
+func (a *App) autoTLSCache() autocert.Cache {
+	if a.Config.AutoTLSCacheDir != "" {
+		return autocert.DirCache(a.Config.AutoTLSCacheDir)
+	}
+	if a.Config.Storage == nil || isInMemoryStorage(a.Config.Storage) {
+		a.Logger().Warn("auto TLS with in-memory Storage: caching certificates on disk", "dir", defaultAutoTLSCacheDir)
+		return autocert.DirCache(defaultAutoTLSCacheDir)
+	}
+	return storageCertCache{storage: a.Config.Storage}
+}
+
+// autoTLSHost returns the request host if it is one of the RunAutoTLS domains.
+func (a *App) autoTLSHost(c fiberpkg.Ctx) (string, bool) {
+	if len(a.autoTLSHosts) == 0 {
+		return "", false
+	}
+	host := strings.ToLower(strings.TrimSpace(string(c.Request().Host())))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	_, ok := a.autoTLSHosts[host]
+	return host, ok
+}
+
+// storageCertCache stores autocert data in a store.Storage.
+type storageCertCache struct {
+	storage store.Storage
+}
+
+func (s storageCertCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.storage.Get(ctx, autoTLSKeyPrefix+key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, err
+}
+
+func (s storageCertCache) Put(ctx context.Context, key string, data []byte) error {
+	return s.storage.Set(ctx, autoTLSKeyPrefix+key, data, 0)
+}
+
+func (s storageCertCache) Delete(ctx context.Context, key string) error {
+	err := s.storage.Delete(ctx, autoTLSKeyPrefix+key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestStorageCertCache(t *testing.T) {
+	ctx := context.Background()
+	storage := store.NewMemoryStorage()
+	cache := storageCertCache{storage: storage}
+
+	if _, err := cache.Get(ctx, "example.com"); !errors.Is(err, autocert.ErrCacheMiss) {
+		t.Fatalf("missing key error = %v, want ErrCacheMiss", err)
+	}
+	if err := cache.Put(ctx, "example.com", []byte("cert")); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := storage.Get(ctx, autoTLSKeyPrefix+"example.com"); string(raw) != "cert" {
+		t.Fatalf("certificate not stored under the autocert prefix: %q", raw)
+	}
+	if got, err := cache.Get(ctx, "example.com"); err != nil || string(got) != "cert" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if err := cache.Delete(ctx, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Delete(ctx, "example.com"); err != nil {
+		t.Fatalf("deleting a missing key failed: %v", err)
+	}
+}
+
+func TestAutoTLSCacheSelection(t *testing.T) {
+	app := New(Config{DevMode: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	if _, ok := app.autoTLSCache().(autocert.DirCache); !ok {
+		t.Fatal("in-memory Storage should fall back to a directory cache")
+	}
+	app.Config.Storage = sharedStorage{store.NewMemoryStorage()}
+	if _, ok := app.autoTLSCache().(storageCertCache); !ok {
+		t.Fatal("shared Storage should back the certificate cache")
+	}
+	app.Config.AutoTLSCacheDir = t.TempDir()
+	if _, ok := app.autoTLSCache().(autocert.DirCache); !ok {
+		t.Fatal("AutoTLSCacheDir should take precedence")
+	}
+}
+
+func TestGetWSUrl_AutoTLS(t *testing.T) {
+	app := New(Config{WebSocketPath: "/wsx", AllowPortsWithInsecureWS: []int{}})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.autoTLSHosts = map[string]struct{}{"example.com": {}}
+
+	f := gofiber.New()
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetHost("Example.com")
+	c := f.AcquireCtx(reqCtx)
+	if ws := app.getWSUrl(c); ws != "wss://example.com/wsx" {
+		t.Fatalf("expected wss://example.com/wsx, got %s", ws)
+	}
+
+	reqCtx.Request.SetHost("evil.test")
+	if ws := app.getWSUrl(c); ws == "wss://evil.test/wsx" {
+		t.Fatal("reflected a host that is not an auto TLS domain")
+	}
+}
+
+// sharedStorage hides the MemoryStorage type so it passes for a shared backend.
+type sharedStorage struct{ store.Storage }
@@ -0,0 +1,95 @@
+package gospa
+
+import (
+	"net/url"
+	"strings"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Config.TrailingSlash policies.
+const (
+	// TrailingSlashPreserve serves /docs and /docs/ as they are requested.
+	TrailingSlashPreserve = "preserve"
+	// TrailingSlashAlways redirects /docs to /docs/.
+	TrailingSlashAlways = "always"
+	// TrailingSlashNever redirects /docs/ to /docs.
+	TrailingSlashNever = "never"
+)
+
+// canonicalMiddleware redirects GET and HEAD requests for page URLs to their
+// canonical form, per TrailingSlash and CanonicalRedirect, in one 301.
+func (a *App) canonicalMiddleware() fiberpkg.Handler {
+	var origin *url.URL
+	if a.Config.CanonicalRedirect {
+		origin, _ = url.Parse(strings.TrimSpace(a.Config.PublicOrigin))
+		if origin != nil && origin.Host == "" {
+			// Reported by Validate.
+			origin = nil
+		}
+	}
+	return func(c fiberpkg.Ctx) error {
+		if c.Method() != fiberpkg.MethodGet && c.Method() != fiberpkg.MethodHead {
+			return c.Next()
+		}
+		path := c.Path()
+		if a.isInternalPath(path) {
+			return c.Next()
+		}
+		target := a.canonicalPath(localPath(path))
+		moved := target != path
+		prefix := ""
+		if origin != nil && (!strings.EqualFold(c.Protocol(), origin.Scheme) || !strings.EqualFold(c.Host(), origin.Host)) {
+			prefix = origin.Scheme + "://" + origin.Host
+			moved = true
+		}
+		if !moved {
+			return c.Next()
+		}
+		location := prefix + target
+		if query := string(c.Request().URI().QueryString()); query != "" {
+			location += "?" + query
+		}
+		return c.Redirect().Status(fiberpkg.StatusMovedPermanently).To(location)
+	}
+}
+
+// localPath collapses the leading slashes and backslashes of path into a
+// single slash, so a redirect to it cannot be read by browsers as a
+// protocol-relative URL such as //evil.example/.
+func localPath(path string) string {
+	trimmed := strings.TrimLeft(path, `/\`)
+	if len(trimmed) == len(path)-1 && path[0] == '/' {
+		return path
+	}
+	return "/" + trimmed
+}
+
+// canonicalPath applies the TrailingSlash policy to a page path. The root,
+// file-like paths such as /robots.txt and static files keep their form.
+func (a *App) canonicalPath(path string) string {
+	if path == "/" || path == "" || strings.HasPrefix(path, a.Config.StaticPrefix+"/") {
+		return path
+	}
+	if last := path[strings.LastIndexByte(path, '/')+1:]; strings.Contains(last, ".") {
+		return path
+	}
+	switch a.Config.TrailingSlash {
+	case TrailingSlashAlways:
+		if !strings.HasSuffix(path, "/") {
+			return path + "/"
+		}
+	case TrailingSlashNever:
+		if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+			return trimmed
+		}
+	}
+	return path
+}
+
+// isInternalPath reports whether path is served by the framework rather than
+// a page, such as the WebSocket, remote action and health endpoints.
+func (a *App) isInternalPath(path string) bool {
+	return strings.HasPrefix(path, "/_gospa") || strings.HasPrefix(path, "/__gospa") ||
+		strings.HasPrefix(path, a.Config.RemotePrefix) || path == a.Config.WebSocketPath
+}
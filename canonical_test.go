@@ -0,0 +1,75 @@
+package gospa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestCanonicalMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		method   string
+		target   string
+		host     string
+		location string
+	}{
+		{"never strips", Config{TrailingSlash: TrailingSlashNever}, http.MethodGet, "/docs/?page=2", "", "/docs?page=2"},
+		{"never keeps root", Config{TrailingSlash: TrailingSlashNever}, http.MethodGet, "/", "", ""},
+		{"always adds", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "/docs", "", "/docs/"},
+		{"always skips files", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "/robots.txt", "", ""},
+		{"always skips static", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "/static/app", "", ""},
+		{"always skips internal", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "/_gospa/healthz", "", ""},
+		{"only GET and HEAD", Config{TrailingSlash: TrailingSlashNever}, http.MethodPost, "/docs/", "", ""},
+		{"preserve", Config{}, http.MethodGet, "/docs/", "", ""},
+		{"canonical host", Config{CanonicalRedirect: true, PublicOrigin: "https://example.com"}, http.MethodGet, "/docs?x=1", "www.example.com", "https://example.com/docs?x=1"},
+		{"canonical scheme and slash", Config{CanonicalRedirect: true, PublicOrigin: "https://example.com", TrailingSlash: TrailingSlashNever}, http.MethodGet, "/docs/", "example.com", "https://example.com/docs"},
+		{"protocol-relative path", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "//evil.example/foo", "", "/evil.example/foo/"},
+		{"backslash path", Config{TrailingSlash: TrailingSlashAlways}, http.MethodGet, "/\\evil.example/foo", "", "/evil.example/foo/"},
+		{"protocol-relative path stripped", Config{TrailingSlash: TrailingSlashNever}, http.MethodGet, "///evil.example/foo/", "", "/evil.example/foo"},
+		{"canonical health probe", Config{CanonicalRedirect: true, PublicOrigin: "https://example.com"}, http.MethodGet, "/_gospa/healthz", "10.0.0.1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.RoutesDir = t.TempDir()
+			app := New(tt.config)
+			defer func() { _ = app.Fiber.Shutdown() }()
+			app.Fiber.All("/*", func(c fiberpkg.Ctx) error { return c.SendString("ok") })
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			resp, err := app.Fiber.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+			if tt.location == "" {
+				if resp.StatusCode == fiberpkg.StatusMovedPermanently {
+					t.Fatalf("redirected to %q", resp.Header.Get("Location"))
+				}
+				return
+			}
+			if resp.StatusCode != fiberpkg.StatusMovedPermanently || resp.Header.Get("Location") != tt.location {
+				t.Fatalf("got %d to %q, want 301 to %q", resp.StatusCode, resp.Header.Get("Location"), tt.location)
+			}
+		})
+	}
+}
+
+func TestValidateCanonicalSettings(t *testing.T) {
+	for _, c := range []Config{
+		{TrailingSlash: "sometimes"},
+		{CanonicalRedirect: true},
+		{CanonicalRedirect: true, PublicOrigin: "example.com"},
+	} {
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "TrailingSlash") && !strings.Contains(err.Error(), "CanonicalRedirect") {
+			t.Errorf("Validate(%+v) = %v", c, err)
+		}
+	}
+}
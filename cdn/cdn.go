@@ -0,0 +1,117 @@
+// Package cdn purges edge caches when GoSPA invalidates cached routes.
+//
+// Set a Purger in Config and Invalidate, InvalidateTag, InvalidateKey and
+// InvalidateAll clear the CDN as well as GoSPA's own cache:
+//
+//	app := gospa.New(gospa.Config{
+//		PublicOrigin: "https://example.com",
+//		CDNPurger: &cdn.Cloudflare{
+//			ZoneID:   os.Getenv("CF_ZONE_ID"),
+//			APIToken: os.Getenv("CF_API_TOKEN"),
+//		},
+//	})
+//
+// Purgers that address pages by URL join paths to Config.PublicOrigin.
+package cdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNoOrigin is returned when a purger needs absolute URLs but the purge has
+// no Origin. Set Config.PublicOrigin.
+var ErrNoOrigin = errors.New("cdn: purging paths needs an origin (set Config.PublicOrigin)")
+
+// Purge describes the pages an invalidation removed.
+type Purge struct {
+	// Origin is the site's scheme and host, e.g. "https://example.com".
+	Origin string
+	// Paths are invalidated request paths, with the query string when a page
+	// was cached per query, e.g. "/blog" or "/search?q=go".
+	Paths []string
+	// Tags are route cache tags such as "route:/blog", the names sent in the
+	// Surrogate-Key header.
+	Tags []string
+	// All asks for everything to be purged.
+	All bool
+}
+
+// URLs returns Paths joined to Origin.
+func (p Purge) URLs() ([]string, error) {
+	if len(p.Paths) == 0 {
+		return nil, nil
+	}
+	origin := strings.TrimRight(p.Origin, "/")
+	if origin == "" {
+		return nil, ErrNoOrigin
+	}
+	urls := make([]string, 0, len(p.Paths))
+	for _, path := range p.Paths {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		urls = append(urls, origin+path)
+	}
+	return urls, nil
+}
+
+// Purger clears pages from an edge cache.
+type Purger interface {
+	Purge(ctx context.Context, p Purge) error
+}
+
+// PurgerFunc adapts a function to Purger.
+type PurgerFunc func(ctx context.Context, p Purge) error
+
+// Purge calls f.
+func (f PurgerFunc) Purge(ctx context.Context, p Purge) error {
+	return f(ctx, p)
+}
+
+// Multi returns a Purger that runs each purger in turn and joins their errors.
+func Multi(purgers ...Purger) Purger {
+	return PurgerFunc(func(ctx context.Context, p Purge) error {
+		var errs []error
+		for _, purger := range purgers {
+			if err := purger.Purge(ctx, p); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// send performs req and turns a non-2xx reply into an error naming provider.
+func send(client *http.Client, req *http.Request, provider string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cdn: %s purge: %w", provider, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return body, fmt.Errorf("cdn: %s purge: %s: %s", provider, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// chunks splits items into slices of at most size.
+func chunks(items []string, size int) [][]string {
+	var out [][]string
+	for len(items) > size {
+		out = append(out, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		out = append(out, items)
+	}
+	return out
+}
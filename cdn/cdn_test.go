@@ -0,0 +1,171 @@
+package cdn
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorded struct {
+	method, path string
+	header       http.Header
+	body         string
+}
+
+func recorder(t *testing.T, reply string) (*httptest.Server, func() []recorded) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []recorded
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, recorded{r.Method, r.URL.RequestURI(), r.Header.Clone(), string(body)})
+		mu.Unlock()
+		_, _ = io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []recorded {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recorded(nil), got...)
+	}
+}
+
+func TestPurgeURLs(t *testing.T) {
+	urls, err := Purge{Origin: "https://example.com/", Paths: []string{"/a", "b?q=1"}}.URLs()
+	if err != nil || strings.Join(urls, " ") != "https://example.com/a https://example.com/b?q=1" {
+		t.Fatalf("URLs() = %v, %v", urls, err)
+	}
+	if _, err := (Purge{Paths: []string{"/a"}}).URLs(); err != ErrNoOrigin {
+		t.Fatalf("URLs() without origin: err = %v", err)
+	}
+}
+
+func TestCloudflare(t *testing.T) {
+	srv, requests := recorder(t, `{"success":true,"errors":[]}`)
+	cf := &Cloudflare{ZoneID: "zone", APIToken: "tok", Endpoint: srv.URL}
+
+	err := cf.Purge(context.Background(), Purge{Origin: "https://example.com", Paths: []string{"/blog"}, Tags: []string{"route:/blog"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cf.Purge(context.Background(), Purge{All: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := requests()
+	want := []string{`{"files":["https://example.com/blog"]}`, `{"tags":["route:/blog"]}`, `{"purge_everything":true}`}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(got), len(want))
+	}
+	for i, r := range got {
+		if r.method != http.MethodPost || r.path != "/zones/zone/purge_cache" || r.header.Get("Authorization") != "Bearer tok" {
+			t.Fatalf("request %d: %s %s auth=%q", i, r.method, r.path, r.header.Get("Authorization"))
+		}
+		if r.body != want[i] {
+			t.Fatalf("request %d body = %s, want %s", i, r.body, want[i])
+		}
+	}
+}
+
+func TestCloudflareReportsAPIErrors(t *testing.T) {
+	srv, _ := recorder(t, `{"success":false,"errors":[{"code":1134,"message":"bad token"}]}`)
+	cf := &Cloudflare{ZoneID: "zone", Endpoint: srv.URL}
+	err := cf.Purge(context.Background(), Purge{All: true})
+	if err == nil || !strings.Contains(err.Error(), "1134 bad token") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestFastly(t *testing.T) {
+	srv, requests := recorder(t, `{"status":"ok"}`)
+	f := &Fastly{ServiceID: "svc", APIToken: "tok", Soft: true, Endpoint: srv.URL}
+
+	err := f.Purge(context.Background(), Purge{Origin: "https://example.com", Paths: []string{"/blog?page=2"}, Tags: []string{"route:/blog", "posts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Purge(context.Background(), Purge{All: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := requests()
+	if len(got) != 3 {
+		t.Fatalf("got %d requests, want 3", len(got))
+	}
+	if got[0].path != "/purge/example.com/blog%3Fpage=2" || got[0].header.Get("Fastly-Soft-Purge") != "1" {
+		t.Fatalf("url purge: %s soft=%q", got[0].path, got[0].header.Get("Fastly-Soft-Purge"))
+	}
+	if got[1].path != "/service/svc/purge" || got[1].header.Get("Surrogate-Key") != "route:/blog posts" {
+		t.Fatalf("key purge: %s keys=%q", got[1].path, got[1].header.Get("Surrogate-Key"))
+	}
+	if got[2].path != "/service/svc/purge_all" || got[2].header.Get("Fastly-Soft-Purge") != "" {
+		t.Fatalf("purge all: %s soft=%q", got[2].path, got[2].header.Get("Fastly-Soft-Purge"))
+	}
+	for _, r := range got {
+		if r.header.Get("Fastly-Key") != "tok" {
+			t.Fatalf("%s: Fastly-Key = %q", r.path, r.header.Get("Fastly-Key"))
+		}
+	}
+}
+
+func TestCloudFront(t *testing.T) {
+	srv, requests := recorder(t, "")
+	cf := &CloudFront{DistributionID: "DIST", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: srv.URL}
+
+	err := cf.Purge(context.Background(), Purge{Paths: []string{"/blog?page=2", "/blog", "/about"}, Tags: []string{"route:/blog"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := requests()
+	if len(got) != 1 || got[0].path != "/2020-05-31/distribution/DIST/invalidation" {
+		t.Fatalf("requests = %+v", got)
+	}
+	if auth := got[0].header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(auth, "/us-east-1/cloudfront/aws4_request") {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	var batch invalidationBatch
+	if err := xml.Unmarshal([]byte(got[0].body), &batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Quantity != 2 || strings.Join(batch.Items, " ") != "/blog /about" || batch.CallerReference == "" {
+		t.Fatalf("batch = %+v", batch)
+	}
+}
+
+// TestSignV4 checks the signer against the get-vanilla case of the AWS
+// Signature Version 4 test suite.
+func TestSignV4(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signV4(req, nil, awsCredentials{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var calls []string
+	ok := PurgerFunc(func(_ context.Context, p Purge) error {
+		calls = append(calls, strings.Join(p.Paths, ","))
+		return nil
+	})
+	fail := PurgerFunc(func(context.Context, Purge) error { return io.ErrUnexpectedEOF })
+
+	err := Multi(fail, ok).Purge(context.Background(), Purge{Paths: []string{"/a"}})
+	if err == nil || len(calls) != 1 || calls[0] != "/a" {
+		t.Fatalf("err = %v, calls = %v", err, calls)
+	}
+}
@@ -0,0 +1,90 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const cloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+
+// cloudflareBatch is the most files or tags Cloudflare accepts per request on
+// every plan.
+const cloudflareBatch = 30
+
+// Cloudflare purges a zone through the Cloudflare API. Paths are purged by
+// URL and tags by cache tag. Cloudflare matches tags against the Cache-Tag
+// response header, not Surrogate-Key, so tag purges only reach pages whose
+// middleware copies the tags into Cache-Tag.
+type Cloudflare struct {
+	ZoneID string
+	// APIToken needs the Zone.Cache Purge permission.
+	APIToken string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Endpoint overrides the API base URL, e.g. for tests.
+	Endpoint string
+}
+
+// Purge implements Purger.
+func (cf *Cloudflare) Purge(ctx context.Context, p Purge) error {
+	if p.All {
+		return cf.post(ctx, map[string]any{"purge_everything": true})
+	}
+	urls, err := p.URLs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, batch := range chunks(urls, cloudflareBatch) {
+		errs = append(errs, cf.post(ctx, map[string]any{"files": batch}))
+	}
+	for _, batch := range chunks(p.Tags, cloudflareBatch) {
+		errs = append(errs, cf.post(ctx, map[string]any{"tags": batch}))
+	}
+	return errors.Join(errs...)
+}
+
+func (cf *Cloudflare) post(ctx context.Context, payload map[string]any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	endpoint := cf.Endpoint
+	if endpoint == "" {
+		endpoint = cloudflareEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(endpoint, "/")+"/zones/"+cf.ZoneID+"/purge_cache", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cf.APIToken)
+	req.Header.Set("Content-Type", "application/json")
+	reply, err := send(cf.Client, req, "cloudflare")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(reply, &result); err != nil {
+		return fmt.Errorf("cdn: cloudflare purge: decode reply: %w", err)
+	}
+	if !result.Success {
+		msgs := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("cdn: cloudflare purge failed: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const cloudfrontEndpoint = "https://cloudfront.amazonaws.com"
+
+// cloudfrontBatch is the most paths CloudFront accepts per invalidation.
+const cloudfrontBatch = 3000
+
+// CloudFront creates invalidations for a distribution. CloudFront has no tag
+// purge, so Tags are ignored; GoSPA resolves tags it has cached to Paths.
+// Query strings are dropped because invalidations match every query variant
+// of a path.
+type CloudFront struct {
+	DistributionID string
+	// AccessKeyID, SecretAccessKey and SessionToken default to the
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+	// environment variables. The key needs cloudfront:CreateInvalidation.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Endpoint overrides the API base URL, e.g. for tests.
+	Endpoint string
+
+	now func() time.Time
+}
+
+type invalidationBatch struct {
+	XMLName         xml.Name `xml:"http://cloudfront.amazonaws.com/doc/2020-05-31/ InvalidationBatch"`
+	Quantity        int      `xml:"Paths>Quantity"`
+	Items           []string `xml:"Paths>Items>Path"`
+	CallerReference string   `xml:"CallerReference"`
+}
+
+// Purge implements Purger.
+func (cf *CloudFront) Purge(ctx context.Context, p Purge) error {
+	var paths []string
+	if p.All {
+		paths = []string{"/*"}
+	} else {
+		seen := make(map[string]struct{}, len(p.Paths))
+		for _, path := range p.Paths {
+			path, _, _ = strings.Cut(path, "?")
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+			if _, dup := seen[path]; dup {
+				continue
+			}
+			seen[path] = struct{}{}
+			paths = append(paths, path)
+		}
+	}
+	var errs []error
+	for _, batch := range chunks(paths, cloudfrontBatch) {
+		errs = append(errs, cf.invalidate(ctx, batch))
+	}
+	return errors.Join(errs...)
+}
+
+func (cf *CloudFront) invalidate(ctx context.Context, paths []string) error {
+	ref := make([]byte, 8)
+	_, _ = rand.Read(ref)
+	body, err := xml.Marshal(invalidationBatch{
+		Quantity:        len(paths),
+		Items:           paths,
+		CallerReference: "gospa-" + hex.EncodeToString(ref),
+	})
+	if err != nil {
+		return err
+	}
+	body = append([]byte(xml.Header), body...)
+
+	endpoint := cf.Endpoint
+	if endpoint == "" {
+		endpoint = cloudfrontEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(endpoint, "/")+"/2020-05-31/distribution/"+cf.DistributionID+"/invalidation", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+
+	creds := awsCredentials{
+		accessKeyID:     firstNonEmpty(cf.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretAccessKey: firstNonEmpty(cf.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken:    firstNonEmpty(cf.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+	}
+	if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return errors.New("cdn: cloudfront purge: no AWS credentials")
+	}
+	now := time.Now
+	if cf.now != nil {
+		now = cf.now
+	}
+	signV4(req, body, creds, "us-east-1", "cloudfront", now())
+	_, err = send(cf.Client, req, "cloudfront")
+	return err
+}
+
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. It signs the Host
+// header, Content-Type when set, and every X-Amz-* header.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	day := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(vs, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const fastlyEndpoint = "https://api.fastly.com"
+
+// fastlyKeyBatch is the most surrogate keys Fastly purges per request.
+const fastlyKeyBatch = 256
+
+// Fastly purges a service through the Fastly API. Paths are purged by URL
+// and tags by surrogate key, which matches the Surrogate-Key header set by
+// RouteOptions.SurrogateKeys and SurrogateControl.
+type Fastly struct {
+	ServiceID string
+	// APIToken needs the purge_select scope, and purge_all for InvalidateAll.
+	APIToken string
+	// Soft marks content stale instead of removing it, so Fastly can keep
+	// serving it while the origin is unreachable. Purge-all is always hard.
+	Soft bool
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Endpoint overrides the API base URL, e.g. for tests.
+	Endpoint string
+}
+
+// Purge implements Purger.
+func (f *Fastly) Purge(ctx context.Context, p Purge) error {
+	if p.All {
+		return f.post(ctx, "/service/"+f.ServiceID+"/purge_all", nil)
+	}
+	urls, err := p.URLs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		target := u.Host + u.EscapedPath()
+		if u.RawQuery != "" {
+			target += "%3F" + u.RawQuery
+		}
+		errs = append(errs, f.post(ctx, "/purge/"+target, nil))
+	}
+	for _, batch := range chunks(p.Tags, fastlyKeyBatch) {
+		errs = append(errs, f.post(ctx, "/service/"+f.ServiceID+"/purge", http.Header{
+			"Surrogate-Key": {strings.Join(batch, " ")},
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Fastly) post(ctx context.Context, path string, header http.Header) error {
+	endpoint := f.Endpoint
+	if endpoint == "" {
+		endpoint = fastlyEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Fastly-Key", f.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if f.Soft && !strings.HasSuffix(path, "/purge_all") {
+		req.Header.Set("Fastly-Soft-Purge", "1")
+	}
+	_, err = send(f.Client, req, "fastly")
+	return err
+}
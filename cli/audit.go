@@ -0,0 +1,619 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AuditConfig controls the security audit.
+type AuditConfig struct {
+	RootDir    string // Project root to scan
+	JSONOutput bool   // JSON output
+	FailOn     string // Lowest severity that fails the audit: high, medium, low or none
+}
+
+// Audit severities, most severe first.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+var severityRank = map[string]int{SeverityHigh: 3, SeverityMedium: 2, SeverityLow: 1}
+
+// AuditFinding is a single issue reported by gospa audit.
+type AuditFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+const (
+	gospaTemplPath = "github.com/aydenstechdungeon/gospa/templ"
+	aTemplPath     = "github.com/a-h/templ"
+	fiberCORSPath  = "github.com/gofiber/fiber/v3/middleware/cors"
+)
+
+// rawHTMLFuncs lists, per import path, the functions that emit their argument
+// without escaping.
+var rawHTMLFuncs = map[string]map[string]bool{
+	gospaTemplPath: {"Raw": true, "HTMLContent": true, "UnsafeHTML": true, "UnsafeAttr": true},
+	aTemplPath:     {"Raw": true},
+}
+
+// htmlEscapers are calls whose result is safe to interpolate into HTML.
+var htmlEscapers = map[string]bool{
+	"html.EscapeString":         true,
+	"template.HTMLEscapeString": true,
+	"template.HTMLEscaper":      true,
+	"template.JSEscapeString":   true,
+	"templ.EscapeString":        true,
+	"url.QueryEscape":           true,
+	"url.PathEscape":            true,
+	"strconv.Itoa":              true,
+	"strconv.FormatInt":         true,
+	"strconv.FormatFloat":       true,
+	"strconv.FormatBool":        true,
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[A-Za-z/!]`)
+
+// Audit scans the project for unsafe HTML output and risky CSRF/CORS
+// configuration and exits non-zero when a finding reaches FailOn.
+func Audit(config *AuditConfig) {
+	printer := NewColorPrinter()
+
+	if config == nil {
+		config = &AuditConfig{}
+	}
+	if config.RootDir == "" {
+		config.RootDir = "."
+	}
+	if config.FailOn == "" {
+		config.FailOn = SeverityHigh
+	}
+	if _, ok := severityRank[config.FailOn]; !ok && config.FailOn != "none" {
+		fmt.Fprintf(os.Stderr, "Error: invalid --fail-on %q (want high, medium, low or none)\n", config.FailOn)
+		os.Exit(1)
+	}
+
+	findings, err := runAudit(config.RootDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: audit failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if config.JSONOutput {
+		if findings == nil {
+			findings = []AuditFinding{}
+		}
+		data, _ := json.MarshalIndent(findings, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printer.Title("GoSPA Security Audit")
+		printer.Subtitle("Scanning %s for raw HTML, unescaped HTML writes, CSRF and CORS configuration...", config.RootDir)
+		for _, f := range findings {
+			msg := fmt.Sprintf("[%s] %s:%d %s (%s)", strings.ToUpper(f.Severity), f.File, f.Line, f.Message, f.Rule)
+			if f.Severity == SeverityHigh {
+				printer.Error("%s", msg)
+			} else {
+				printer.Warning("%s", msg)
+			}
+		}
+		if len(findings) == 0 {
+			printer.Success("No issues found")
+		} else {
+			fmt.Printf("\n%d issue(s) found\n", len(findings))
+		}
+	}
+
+	if auditFails(findings, config.FailOn) {
+		os.Exit(1)
+	}
+}
+
+// auditFails reports whether any finding is at or above the failOn severity.
+func auditFails(findings []AuditFinding, failOn string) bool {
+	threshold, ok := severityRank[failOn]
+	if !ok {
+		return false
+	}
+	for _, f := range findings {
+		if severityRank[f.Severity] >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// auditScan accumulates findings and the project-wide facts that need every
+// file before they can be judged.
+type auditScan struct {
+	root          string
+	fset          *token.FileSet
+	findings      []AuditFinding
+	remoteActions []token.Position // RegisterRemoteAction call sites
+	csrfDisabled  []AuditFinding   // DisableCSRF: true sites
+}
+
+// runAudit walks root and returns its findings sorted by file and line.
+func runAudit(root string) ([]AuditFinding, error) {
+	s := &auditScan{root: root, fset: token.NewFileSet()}
+
+	var goFiles, templFiles []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(path, "_test.go"):
+		case strings.HasSuffix(path, ".go"):
+			goFiles = append(goFiles, path)
+		case strings.HasSuffix(path, ".templ"):
+			templFiles = append(templFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range goFiles {
+		// Generated templ code is audited through its .templ source, which
+		// has the lines users edit.
+		if strings.HasSuffix(path, "_templ.go") {
+			if _, err := os.Stat(strings.TrimSuffix(path, "_templ.go") + ".templ"); err == nil {
+				continue
+			}
+		}
+		s.auditGoFile(path)
+	}
+	for _, path := range templFiles {
+		if err := s.auditTemplFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.remoteActions) > 0 {
+		first := s.remoteActions[0]
+		for _, site := range s.csrfDisabled {
+			site.Message = fmt.Sprintf("CSRF protection is disabled but %d remote action(s) are registered (first at %s:%d)",
+				len(s.remoteActions), s.relPath(first.Filename), first.Line)
+			s.findings = append(s.findings, site)
+		}
+	}
+
+	sort.SliceStable(s.findings, func(i, j int) bool {
+		a, b := s.findings[i], s.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return s.findings, nil
+}
+
+// finding builds a finding at pos with a root-relative file name.
+func (s *auditScan) finding(rule, severity string, pos token.Pos, format string, args ...interface{}) AuditFinding {
+	p := s.fset.Position(pos)
+	return AuditFinding{
+		Rule:     rule,
+		Severity: severity,
+		File:     s.relPath(p.Filename),
+		Line:     p.Line,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (s *auditScan) relPath(path string) string {
+	if rel, err := filepath.Rel(s.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// fileImports maps the local names of a file's imports to their paths.
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string, len(f.Imports))
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func (s *auditScan) auditGoFile(path string) {
+	f, err := parser.ParseFile(s.fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		// Files that do not parse are the compiler's problem, not the audit's.
+		return
+	}
+	imports := fileImports(f)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			s.checkRawCall(n, imports)
+			s.checkFprint(n)
+			if name := calleeName(n); strings.HasPrefix(name, "RegisterRemoteAction") {
+				s.remoteActions = append(s.remoteActions, s.fset.Position(n.Pos()))
+			}
+		case *ast.CompositeLit:
+			s.checkCORSConfig(n, imports)
+			s.checkGospaConfig(n)
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "DisableCSRF" && i < len(n.Rhs) && isTrue(n.Rhs[i]) {
+					s.csrfDisabled = append(s.csrfDisabled, s.finding("csrf-disabled", SeverityHigh, n.Pos(), ""))
+				}
+			}
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				s.checkCORSHeaders(n.Body)
+			}
+		case *ast.FuncLit:
+			s.checkCORSHeaders(n.Body)
+		}
+		return true
+	})
+}
+
+// calleeName returns the function or method name of a call, ignoring any
+// package qualifier and type arguments.
+func calleeName(call *ast.CallExpr) string {
+	fun := call.Fun
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
+
+// qualifiedName returns "pkg.Func" for a package-qualified call.
+func qualifiedName(call *ast.CallExpr) (pkg, name string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", ""
+	}
+	return id.Name, sel.Sel.Name
+}
+
+func (s *auditScan) checkRawCall(call *ast.CallExpr, imports map[string]string) {
+	pkg, name := qualifiedName(call)
+	if pkg == "" || !rawHTMLFuncs[imports[pkg]][name] || len(call.Args) == 0 {
+		return
+	}
+	if isStringConstant(call.Args[0]) {
+		return
+	}
+	s.findings = append(s.findings, s.finding("raw-html", SeverityHigh, call.Pos(),
+		"%s.%s renders a non-constant value without escaping; escape it or make sure it is trusted HTML", pkg, name))
+}
+
+// isStringConstant reports whether expr is a string literal or a
+// concatenation of string literals.
+func isStringConstant(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING
+	case *ast.ParenExpr:
+		return isStringConstant(e.X)
+	case *ast.BinaryExpr:
+		return e.Op == token.ADD && isStringConstant(e.X) && isStringConstant(e.Y)
+	}
+	return false
+}
+
+// isSafeHTMLArg reports whether expr can be written into HTML unescaped: a
+// literal or the result of a known escaping function.
+func isSafeHTMLArg(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isSafeHTMLArg(e.X)
+	case *ast.CallExpr:
+		pkg, name := qualifiedName(e)
+		return htmlEscapers[pkg+"."+name]
+	}
+	return false
+}
+
+// printfVerbs returns the verbs of a printf format, one per consumed argument.
+func printfVerbs(format string) []byte {
+	var verbs []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] != '%' {
+			verbs = append(verbs, format[i])
+		}
+	}
+	return verbs
+}
+
+// checkFprint flags fmt.Fprintf/Fprint/Fprintln calls that write HTML markup
+// together with unescaped string values.
+func (s *auditScan) checkFprint(call *ast.CallExpr) {
+	pkg, name := qualifiedName(call)
+	if pkg != "fmt" || len(call.Args) < 2 {
+		return
+	}
+	args := call.Args[1:]
+	var unsafe bool
+	switch name {
+	case "Fprintf":
+		lit, ok := args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		format, err := strconv.Unquote(lit.Value)
+		if err != nil || !htmlTagPattern.MatchString(format) {
+			return
+		}
+		for i, verb := range printfVerbs(format) {
+			if i+1 >= len(args) {
+				break
+			}
+			if strings.IndexByte("svqx", verb) >= 0 && !isSafeHTMLArg(args[i+1]) {
+				unsafe = true
+			}
+		}
+	case "Fprint", "Fprintln":
+		markup := false
+		for _, arg := range args {
+			if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING && htmlTagPattern.MatchString(lit.Value) {
+				markup = true
+			} else if !isSafeHTMLArg(arg) {
+				unsafe = true
+			}
+		}
+		unsafe = unsafe && markup
+	default:
+		return
+	}
+	if unsafe {
+		s.findings = append(s.findings, s.finding("unescaped-html-write", SeverityMedium, call.Pos(),
+			"fmt.%s writes HTML with unescaped values; wrap them in html.EscapeString or render through templ", name))
+	}
+}
+
+func isTrue(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "true"
+}
+
+// hasWildcard reports whether expr is "*" or a slice literal containing "*".
+func hasWildcard(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		v, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return false
+		}
+		for _, o := range strings.Split(v, ",") {
+			if strings.TrimSpace(o) == "*" {
+				return true
+			}
+		}
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if hasWildcard(elt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyValues indexes the keyed fields of a composite literal.
+func keyValues(lit *ast.CompositeLit) map[string]*ast.KeyValueExpr {
+	fields := make(map[string]*ast.KeyValueExpr)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				fields[key.Name] = kv
+			}
+		}
+	}
+	return fields
+}
+
+// checkCORSConfig flags Fiber cors.Config literals that allow credentials from
+// any origin.
+func (s *auditScan) checkCORSConfig(lit *ast.CompositeLit, imports map[string]string) {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Config" {
+		return
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || imports[id.Name] != fiberCORSPath {
+		return
+	}
+	fields := keyValues(lit)
+	creds, ok := fields["AllowCredentials"]
+	if !ok || !isTrue(creds.Value) {
+		return
+	}
+	if origins, ok := fields["AllowOrigins"]; ok && hasWildcard(origins.Value) {
+		s.findings = append(s.findings, s.finding("cors-wildcard-credentials", SeverityHigh, origins.Pos(),
+			"CORS allows credentials from any origin; list the trusted origins instead of \"*\""))
+	}
+	if fn, ok := fields["AllowOriginsFunc"]; ok && returnsTrue(fn.Value) {
+		s.findings = append(s.findings, s.finding("cors-wildcard-credentials", SeverityHigh, fn.Pos(),
+			"AllowOriginsFunc accepts every origin while AllowCredentials is set"))
+	}
+}
+
+// returnsTrue reports whether expr is a function literal whose only statement
+// is "return true".
+func returnsTrue(expr ast.Expr) bool {
+	fn, ok := expr.(*ast.FuncLit)
+	if !ok || len(fn.Body.List) != 1 {
+		return false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	return ok && len(ret.Results) == 1 && isTrue(ret.Results[0])
+}
+
+// checkGospaConfig inspects literals that carry gospa.Config fields.
+func (s *auditScan) checkGospaConfig(lit *ast.CompositeLit) {
+	fields := keyValues(lit)
+	if kv, ok := fields["DisableCSRF"]; ok && isTrue(kv.Value) {
+		s.csrfDisabled = append(s.csrfDisabled, s.finding("csrf-disabled", SeverityHigh, kv.Pos(), ""))
+	}
+	if kv, ok := fields["AllowedOrigins"]; ok && hasWildcard(kv.Value) {
+		s.findings = append(s.findings, s.finding("cors-wildcard", SeverityLow, kv.Pos(),
+			"AllowedOrigins contains \"*\"; any site can read responses (credentials are only sent to exact origins)"))
+	}
+}
+
+// checkCORSHeaders flags handlers that set Access-Control-Allow-Credentials
+// alongside a wildcard or reflected Access-Control-Allow-Origin. Nested
+// function literals are checked on their own.
+func (s *auditScan) checkCORSHeaders(body *ast.BlockStmt) {
+	var origin *ast.CallExpr
+	credentials := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		if name := calleeName(call); name != "Set" && name != "Add" {
+			return true
+		}
+		header, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || header.Kind != token.STRING {
+			return true
+		}
+		key, _ := strconv.Unquote(header.Value)
+		switch strings.ToLower(key) {
+		case "access-control-allow-origin":
+			if !isStringConstant(call.Args[1]) || hasWildcard(call.Args[1]) {
+				origin = call
+			}
+		case "access-control-allow-credentials":
+			if lit, ok := call.Args[1].(*ast.BasicLit); ok && strings.EqualFold(strings.Trim(lit.Value, "\"`"), "true") {
+				credentials = true
+			}
+		}
+		return true
+	})
+	if origin != nil && credentials {
+		s.findings = append(s.findings, s.finding("cors-wildcard-credentials", SeverityHigh, origin.Pos(),
+			"Access-Control-Allow-Credentials is sent with a wildcard or reflected Access-Control-Allow-Origin"))
+	}
+}
+
+var (
+	templImportPattern = regexp.MustCompile(`(?m)^\s*(?:import\s+)?(?:([A-Za-z_]\w*)\s+)?"([^"]+)"`)
+	templRawPattern    = regexp.MustCompile(`\b([A-Za-z_]\w*)\.(Raw|HTMLContent|UnsafeHTML|UnsafeAttr)\(\s*`)
+)
+
+// auditTemplFile checks a .templ source for raw HTML calls. templ files are
+// not valid Go, so the check is textual.
+func (s *auditScan) auditTemplFile(path string) error {
+	// #nosec //nolint:gosec // path comes from walking RootDir
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text := string(content)
+
+	imports := map[string]string{}
+	for _, m := range templImportPattern.FindAllStringSubmatch(text, -1) {
+		if rawHTMLFuncs[m[2]] == nil {
+			continue
+		}
+		name := m[1]
+		if name == "" {
+			name = m[2][strings.LastIndex(m[2], "/")+1:]
+		}
+		imports[name] = m[2]
+	}
+	// Components can call templ.Raw without importing a-h/templ.
+	if _, ok := imports["templ"]; !ok {
+		imports["templ"] = aTemplPath
+	}
+
+	for _, loc := range templRawPattern.FindAllStringSubmatchIndex(text, -1) {
+		pkg, name := text[loc[2]:loc[3]], text[loc[4]:loc[5]]
+		if !rawHTMLFuncs[imports[pkg]][name] {
+			continue
+		}
+		if rest := text[loc[1]:]; strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "`") {
+			if end := closingLiteral(rest); end > 0 && strings.HasPrefix(strings.TrimSpace(rest[end:]), ")") {
+				continue
+			}
+		}
+		s.findings = append(s.findings, AuditFinding{
+			Rule:     "raw-html",
+			Severity: SeverityHigh,
+			File:     s.relPath(path),
+			Line:     strings.Count(text[:loc[0]], "\n") + 1,
+			Message:  fmt.Sprintf("%s.%s renders a non-constant value without escaping; escape it or make sure it is trusted HTML", pkg, name),
+		})
+	}
+	return nil
+}
+
+// closingLiteral returns the index just past the string literal that starts
+// s, or -1.
+func closingLiteral(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			return i + 1
+		case quote == '"' && s[i] == '\n':
+			return -1
+		}
+	}
+	return -1
+}
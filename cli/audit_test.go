@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeAuditProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestRunAudit(t *testing.T) {
+	root := writeAuditProject(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/aydenstechdungeon/gospa/routing"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
+	"github.com/gofiber/fiber/v3/middleware/cors"
+)
+
+func main() {
+	routing.RegisterRemoteAction("save", nil)
+	_ = gospa.Config{
+		DisableCSRF:    true,
+		AllowedOrigins: []string{"*"},
+	}
+	_ = cors.Config{
+		AllowOrigins:     []string{"*"},
+		AllowCredentials: true,
+	}
+}
+
+func page(w http.ResponseWriter, name string, n int) {
+	_ = templpkg.Raw(name)
+	_ = templpkg.Raw("<br>")
+	fmt.Fprintf(w, "<h1>%s</h1>", name)
+	fmt.Fprintf(w, "<h1>%s %d</h1>", html.EscapeString(name), n)
+	fmt.Fprintf(w, "plain %s", name)
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+	w.Header().Set("Access-Control-Allow-Credentials", "true")
+}
+`,
+		"routes/page.templ": `package routes
+
+templ Page(body string) {
+	<div>
+		@templ.Raw(body)
+		@templ.Raw("<hr>")
+	</div>
+}
+`,
+		"routes/page_templ.go": `package routes
+
+import "github.com/a-h/templ"
+
+var _ = templ.Raw(body)
+`,
+		"main_test.go": `package main
+
+import templpkg "github.com/aydenstechdungeon/gospa/templ"
+
+var _ = templpkg.Raw(x)
+`,
+	})
+
+	findings, err := runAudit(root)
+	if err != nil {
+		t.Fatalf("runAudit: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, f := range findings {
+		got[fmt.Sprintf("%s %s:%d", f.Rule, f.File, f.Line)] = true
+	}
+	want := []string{
+		"csrf-disabled main.go:17",
+		"cors-wildcard main.go:18",
+		"cors-wildcard-credentials main.go:21",
+		"raw-html main.go:27",
+		"unescaped-html-write main.go:29",
+		"cors-wildcard-credentials main.go:35",
+		"raw-html routes/page.templ:5",
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("missing finding %q", w)
+		}
+	}
+	if len(findings) != len(want) {
+		t.Errorf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	if !auditFails(findings, SeverityHigh) {
+		t.Error("auditFails(high) = false, want true")
+	}
+}
+
+func TestRunAudit_CSRFDisabledWithoutRemoteActions(t *testing.T) {
+	root := writeAuditProject(t, map[string]string{
+		"main.go": `package main
+
+import "github.com/aydenstechdungeon/gospa"
+
+var cfg = gospa.Config{DisableCSRF: true}
+`,
+	})
+	findings, err := runAudit(root)
+	if err != nil {
+		t.Fatalf("runAudit: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("findings = %+v, want none", findings)
+	}
+	if auditFails(findings, "none") {
+		t.Error("auditFails(none) = true")
+	}
+}
@@ -0,0 +1,548 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// BenchConfig controls the `gospa bench` load generator.
+type BenchConfig struct {
+	URL          string        // Target URL (http(s):// for HTTP, ws(s):// or http(s):// with WS for WebSocket)
+	Concurrency  int           // Number of concurrent workers (connections in WS mode)
+	Duration     time.Duration // How long to generate load
+	WS           bool          // Measure WebSocket ping/pong round-trips instead of HTTP GETs
+	Timeout      time.Duration // Per-request timeout
+	Output       string        // Export results to this file
+	Format       string        // Export format: json or csv (default: from Output extension)
+	Baseline     string        // Compare against a result saved with SaveBaseline
+	SaveBaseline string        // Save this run as a baseline JSON file
+	Threshold    float64       // Allowed regression against the baseline, in percent
+	JSONOutput   bool          // Print the result as JSON instead of a report
+}
+
+// BenchBucket is one latency histogram bucket. UpperMs is the inclusive upper
+// bound in milliseconds; the last bucket has UpperMs 0 and counts everything above.
+type BenchBucket struct {
+	UpperMs float64 `json:"upperMs"`
+	Count   int     `json:"count"`
+}
+
+// BenchResult is the outcome of a benchmark run and the format of exported
+// JSON results and saved baselines.
+type BenchResult struct {
+	Mode        string         `json:"mode"`
+	URL         string         `json:"url"`
+	Concurrency int            `json:"concurrency"`
+	DurationSec float64        `json:"durationSec"`
+	Requests    int            `json:"requests"`
+	Errors      int            `json:"errors"`
+	RPS         float64        `json:"rps"`
+	MinMs       float64        `json:"minMs"`
+	AvgMs       float64        `json:"avgMs"`
+	P50Ms       float64        `json:"p50Ms"`
+	P95Ms       float64        `json:"p95Ms"`
+	P99Ms       float64        `json:"p99Ms"`
+	MaxMs       float64        `json:"maxMs"`
+	StatusCodes map[string]int `json:"statusCodes,omitempty"`
+	Histogram   []BenchBucket  `json:"histogram"`
+	Timestamp   time.Time      `json:"timestamp"`
+	// StateSync is set for `gospa bench ws` runs, where Requests counts
+	// broadcast deliveries and the latency fields measure fan-out.
+	StateSync *BenchStateSync `json:"stateSync,omitempty"`
+}
+
+// BenchRegression is a metric that got worse than the baseline by more than
+// the allowed threshold.
+type BenchRegression struct {
+	Metric    string  `json:"metric"`
+	Baseline  float64 `json:"baseline"`
+	Current   float64 `json:"current"`
+	ChangePct float64 `json:"changePct"`
+}
+
+// benchBucketBounds are the histogram upper bounds in milliseconds.
+var benchBucketBounds = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+// benchSample is what a single worker collects.
+type benchSample struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+}
+
+// Bench runs a load test against a running GoSPA server and reports latency,
+// throughput, and regressions against a saved baseline.
+func Bench(config *BenchConfig) {
+	printer := NewColorPrinter()
+	if config == nil {
+		config = &BenchConfig{}
+	}
+	applyBenchDefaults(config)
+
+	if !config.JSONOutput {
+		mode := "HTTP"
+		if config.WS {
+			mode = "WebSocket"
+		}
+		printer.Title("GoSPA Bench")
+		printer.Subtitle("%s load against %s: %d workers for %s", mode, config.URL, config.Concurrency, config.Duration)
+	}
+
+	result, err := RunBench(context.Background(), config)
+	if err != nil {
+		printer.Error("Benchmark failed: %v", err)
+		os.Exit(1)
+	}
+
+	reportBench(printer, config, result)
+}
+
+// reportBench prints result and handles export, baseline saving, and
+// baseline comparison. It exits with status 1 on failure or regression.
+func reportBench(printer *ColorPrinter, config *BenchConfig, result *BenchResult) {
+	if config.JSONOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printBenchResult(printer, result)
+	}
+
+	if config.Output != "" {
+		if err := WriteBenchResult(config.Output, config.Format, result); err != nil {
+			printer.Error("Failed to export results: %v", err)
+			os.Exit(1)
+		}
+		if !config.JSONOutput {
+			printer.Success("Results written to %s", config.Output)
+		}
+	}
+	if config.SaveBaseline != "" {
+		if err := WriteBenchResult(config.SaveBaseline, "json", result); err != nil {
+			printer.Error("Failed to save baseline: %v", err)
+			os.Exit(1)
+		}
+		if !config.JSONOutput {
+			printer.Success("Baseline saved to %s", config.SaveBaseline)
+		}
+	}
+
+	if config.Baseline != "" {
+		baseline, err := LoadBenchBaseline(config.Baseline)
+		if err != nil {
+			printer.Error("Failed to load baseline: %v", err)
+			os.Exit(1)
+		}
+		regressions := CompareBench(baseline, result, config.Threshold)
+		if len(regressions) > 0 {
+			for _, r := range regressions {
+				printer.Error("%s regressed %.1f%% (baseline %.2f, now %.2f)", r.Metric, r.ChangePct, r.Baseline, r.Current)
+			}
+			os.Exit(1)
+		}
+		if !config.JSONOutput {
+			printer.Success("No regressions beyond %.1f%% against %s", config.Threshold, config.Baseline)
+		}
+	}
+}
+
+func applyBenchDefaults(config *BenchConfig) {
+	if config.URL == "" {
+		config.URL = "http://localhost:3000/"
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 10
+	}
+	if config.Duration <= 0 {
+		config.Duration = 10 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = 10
+	}
+}
+
+// RunBench generates load for config.Duration and returns the aggregated result.
+func RunBench(ctx context.Context, config *BenchConfig) (*BenchResult, error) {
+	applyBenchDefaults(config)
+
+	target := config.URL
+	mode := "http"
+	if config.WS {
+		mode = "ws"
+		wsURL, err := benchWSURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		target = wsURL
+	} else if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid HTTP URL %q", target)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	client := &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        config.Concurrency,
+			MaxIdleConnsPerHost: config.Concurrency,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	samples := make([]benchSample, config.Concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range samples {
+		wg.Add(1)
+		go func(s *benchSample) {
+			defer wg.Done()
+			s.statuses = make(map[int]int)
+			if config.WS {
+				benchWSWorker(ctx, target, config.Timeout, s)
+			} else {
+				benchHTTPWorker(ctx, client, target, s)
+			}
+		}(&samples[i])
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := summarizeBench(samples, elapsed)
+	result.Mode = mode
+	result.URL = target
+	result.Concurrency = config.Concurrency
+	if result.Requests == 0 && result.Errors > 0 {
+		return result, fmt.Errorf("all %d requests failed", result.Errors)
+	}
+	return result, nil
+}
+
+func benchHTTPWorker(ctx context.Context, client *http.Client, target string, s *benchSample) {
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			s.errors++
+			return
+		}
+		begin := time.Now()
+		resp, err := client.Do(req) // #nosec //nolint:gosec // intentional: user-provided benchmark target
+		if err != nil {
+			if ctx.Err() == nil {
+				s.errors++
+			}
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		s.statuses[resp.StatusCode]++
+		if resp.StatusCode >= http.StatusBadRequest {
+			s.errors++
+			continue
+		}
+		s.latencies = append(s.latencies, time.Since(begin))
+	}
+}
+
+// benchWSWorker opens one connection, performs the init handshake, and
+// measures ping/pong round-trips until ctx is done, reconnecting on errors.
+func benchWSWorker(ctx context.Context, target string, timeout time.Duration, s *benchSample) {
+	dialer := websocket.Dialer{HandshakeTimeout: timeout}
+	seq := 0
+	for ctx.Err() == nil {
+		conn, _, err := dialer.DialContext(ctx, target, nil)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.errors++
+				time.Sleep(50 * time.Millisecond)
+			}
+			continue
+		}
+		if err := conn.WriteJSON(map[string]any{"type": "init"}); err != nil {
+			s.errors++
+			_ = conn.Close()
+			continue
+		}
+		for ctx.Err() == nil {
+			seq++
+			id := strconv.Itoa(seq)
+			begin := time.Now()
+			_ = conn.SetWriteDeadline(begin.Add(timeout))
+			if err := conn.WriteJSON(map[string]any{"type": "ping", "data": map[string]any{"_requestId": id}}); err != nil {
+				s.errors++
+				break
+			}
+			if err := awaitBenchPong(conn, id, begin.Add(timeout)); err != nil {
+				if ctx.Err() == nil {
+					s.errors++
+				}
+				break
+			}
+			s.latencies = append(s.latencies, time.Since(begin))
+		}
+		_ = conn.Close()
+	}
+}
+
+// awaitBenchPong reads until the pong answering id arrives, skipping state
+// sync and other server-initiated messages.
+func awaitBenchPong(conn *websocket.Conn, id string, deadline time.Time) error {
+	_ = conn.SetReadDeadline(deadline)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var msg struct {
+			Type string         `json:"type"`
+			Data map[string]any `json:"data"`
+		}
+		if json.Unmarshal(data, &msg) != nil || msg.Type != "pong" {
+			continue
+		}
+		if respID, _ := msg.Data["_responseId"].(string); respID == id {
+			return nil
+		}
+	}
+}
+
+// benchWSURL converts an http(s) URL to ws(s) and defaults the path to the
+// GoSPA WebSocket endpoint.
+func benchWSURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid WebSocket URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("invalid WebSocket URL %q", raw)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/_gospa/ws"
+	}
+	return u.String(), nil
+}
+
+func summarizeBench(samples []benchSample, elapsed time.Duration) *BenchResult {
+	result := &BenchResult{
+		DurationSec: elapsed.Seconds(),
+		Timestamp:   time.Now().UTC(),
+		Histogram:   make([]BenchBucket, len(benchBucketBounds)+1),
+	}
+	for i, bound := range benchBucketBounds {
+		result.Histogram[i].UpperMs = bound
+	}
+
+	var latencies []time.Duration
+	for _, s := range samples {
+		latencies = append(latencies, s.latencies...)
+		result.Errors += s.errors
+		for code, n := range s.statuses {
+			if result.StatusCodes == nil {
+				result.StatusCodes = make(map[string]int)
+			}
+			result.StatusCodes[strconv.Itoa(code)] += n
+		}
+	}
+	result.Requests = len(latencies)
+	if elapsed > 0 {
+		result.RPS = float64(result.Requests) / elapsed.Seconds()
+	}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, d := range latencies {
+		total += d
+		ms := durationMs(d)
+		idx := sort.SearchFloat64s(benchBucketBounds, ms)
+		result.Histogram[idx].Count++
+	}
+	result.MinMs = durationMs(latencies[0])
+	result.MaxMs = durationMs(latencies[len(latencies)-1])
+	result.AvgMs = durationMs(total / time.Duration(len(latencies)))
+	result.P50Ms = durationMs(percentile(latencies, 0.50))
+	result.P95Ms = durationMs(percentile(latencies, 0.95))
+	result.P99Ms = durationMs(percentile(latencies, 0.99))
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// CompareBench returns the metrics of current that are worse than baseline by
+// more than thresholdPct: lower RPS, or higher p50/p95/p99 latency or error rate.
+func CompareBench(baseline, current *BenchResult, thresholdPct float64) []BenchRegression {
+	var regressions []BenchRegression
+	check := func(metric string, base, cur float64, higherIsWorse bool) {
+		if base <= 0 {
+			return
+		}
+		change := (cur - base) / base * 100
+		if !higherIsWorse {
+			change = -change
+		}
+		if change > thresholdPct {
+			regressions = append(regressions, BenchRegression{Metric: metric, Baseline: base, Current: cur, ChangePct: change})
+		}
+	}
+	check("rps", baseline.RPS, current.RPS, false)
+	check("p50", baseline.P50Ms, current.P50Ms, true)
+	check("p95", baseline.P95Ms, current.P95Ms, true)
+	check("p99", baseline.P99Ms, current.P99Ms, true)
+	if current.Errors > 0 && baseline.Errors == 0 {
+		regressions = append(regressions, BenchRegression{Metric: "errors", Current: float64(current.Errors), ChangePct: 100})
+	} else {
+		check("errors", float64(baseline.Errors), float64(current.Errors), true)
+	}
+	if baseline.StateSync != nil && current.StateSync != nil {
+		if current.StateSync.Dropped > 0 && baseline.StateSync.Dropped == 0 {
+			regressions = append(regressions, BenchRegression{Metric: "dropped", Current: float64(current.StateSync.Dropped), ChangePct: 100})
+		} else {
+			check("dropped", float64(baseline.StateSync.Dropped), float64(current.StateSync.Dropped), true)
+		}
+	}
+	return regressions
+}
+
+// LoadBenchBaseline reads a result previously written as JSON.
+func LoadBenchBaseline(path string) (*BenchResult, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var result BenchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// WriteBenchResult exports result as JSON or CSV. An empty format is inferred
+// from the file extension and defaults to JSON.
+func WriteBenchResult(path, format string, result *BenchResult) error {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	var data []byte
+	switch format {
+	case "", "json":
+		var err error
+		data, err = json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	case "csv":
+		var b strings.Builder
+		if err := writeBenchCSV(&b, result); err != nil {
+			return err
+		}
+		data = []byte(b.String())
+	default:
+		return fmt.Errorf("unsupported format %q (use json or csv)", format)
+	}
+	return os.WriteFile(filepath.Clean(path), data, 0o600)
+}
+
+func writeBenchCSV(w io.Writer, r *BenchResult) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	records := [][]string{
+		{"timestamp", "mode", "url", "concurrency", "duration_s", "requests", "errors", "rps", "min_ms", "avg_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms"},
+		{r.Timestamp.Format(time.RFC3339), r.Mode, r.URL, strconv.Itoa(r.Concurrency), f(r.DurationSec), strconv.Itoa(r.Requests), strconv.Itoa(r.Errors), f(r.RPS), f(r.MinMs), f(r.AvgMs), f(r.P50Ms), f(r.P95Ms), f(r.P99Ms), f(r.MaxMs)},
+	}
+	if ss := r.StateSync; ss != nil {
+		records[0] = append(records[0], "connected", "handshake_p50_ms", "handshake_p95_ms", "sent", "delivered", "fan_out", "coalesced", "dropped")
+		records[1] = append(records[1], strconv.Itoa(ss.Connected), f(ss.HandshakeP50Ms), f(ss.HandshakeP95Ms), strconv.Itoa(ss.Sent), strconv.Itoa(ss.Delivered), f(ss.FanOut), strconv.Itoa(ss.Coalesced), strconv.Itoa(ss.Dropped))
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func printBenchResult(printer *ColorPrinter, r *BenchResult) {
+	fmt.Println()
+	if ss := r.StateSync; ss != nil {
+		fmt.Printf("  %s %d/%d connected, %d failed (p50 %.2fms  p95 %.2fms)\n",
+			printer.Bold("Clients:"), ss.Connected, ss.Clients, ss.HandshakeFailures, ss.HandshakeP50Ms, ss.HandshakeP95Ms)
+		dropped := fmt.Sprintf("%d dropped", ss.Dropped)
+		if ss.Dropped > 0 {
+			dropped = printer.Red(dropped)
+		}
+		fmt.Printf("  %s %d sent, %d delivered (fan-out ×%.1f), %d coalesced, %s\n",
+			printer.Bold("Updates:"), ss.Sent, ss.Delivered, ss.FanOut, ss.Coalesced, dropped)
+		fmt.Printf("  %s %d in %.1fs (%s msg/s), %d errors\n", printer.Bold("Deliveries:"), r.Requests, r.DurationSec, printer.Green(fmt.Sprintf("%.1f", r.RPS)), r.Errors)
+		fmt.Printf("  %s min %.2fms  avg %.2fms  p50 %.2fms  p95 %.2fms  p99 %.2fms  max %.2fms\n",
+			printer.Bold("Fan-out latency:"), r.MinMs, r.AvgMs, r.P50Ms, r.P95Ms, r.P99Ms, r.MaxMs)
+	} else {
+		fmt.Printf("  %s %d in %.1fs (%s req/s), %d errors\n", printer.Bold("Requests:"), r.Requests, r.DurationSec, printer.Green(fmt.Sprintf("%.1f", r.RPS)), r.Errors)
+		fmt.Printf("  %s min %.2fms  avg %.2fms  p50 %.2fms  p95 %.2fms  p99 %.2fms  max %.2fms\n",
+			printer.Bold("Latency:"), r.MinMs, r.AvgMs, r.P50Ms, r.P95Ms, r.P99Ms, r.MaxMs)
+	}
+	if len(r.StatusCodes) > 0 {
+		codes := make([]string, 0, len(r.StatusCodes))
+		for code, n := range r.StatusCodes {
+			codes = append(codes, fmt.Sprintf("%s×%d", code, n))
+		}
+		sort.Strings(codes)
+		fmt.Printf("  %s %s\n", printer.Bold("Status:"), strings.Join(codes, "  "))
+	}
+
+	fmt.Println()
+	maxCount := 0
+	for _, b := range r.Histogram {
+		maxCount = max(maxCount, b.Count)
+	}
+	const barWidth = 40
+	for _, b := range r.Histogram {
+		label := fmt.Sprintf("≤ %gms", b.UpperMs)
+		if b.UpperMs == 0 {
+			label = fmt.Sprintf("> %gms", benchBucketBounds[len(benchBucketBounds)-1])
+		}
+		width := 0
+		if maxCount > 0 {
+			width = b.Count * barWidth / maxCount
+		}
+		fmt.Printf("  %10s │ %s %d\n", label, printer.Cyan(strings.Repeat("█", width)), b.Count)
+	}
+	fmt.Println()
+}
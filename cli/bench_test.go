@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBenchHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	result, err := RunBench(context.Background(), &BenchConfig{URL: srv.URL, Concurrency: 2, Duration: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("RunBench: %v", err)
+	}
+	if result.Mode != "http" || result.Requests == 0 || result.Errors != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.StatusCodes["200"] != result.Requests {
+		t.Fatalf("expected all requests to be 200, got %+v", result.StatusCodes)
+	}
+	histogramTotal := 0
+	for _, b := range result.Histogram {
+		histogramTotal += b.Count
+	}
+	if histogramTotal != result.Requests {
+		t.Fatalf("histogram counts %d, want %d", histogramTotal, result.Requests)
+	}
+}
+
+func TestBenchWSURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:3000":          "ws://localhost:3000/_gospa/ws",
+		"https://example.com/":           "wss://example.com/_gospa/ws",
+		"ws://localhost:3000/custom/ws":  "ws://localhost:3000/custom/ws",
+		"http://localhost:3000/other/ws": "ws://localhost:3000/other/ws",
+	}
+	for in, want := range tests {
+		got, err := benchWSURL(in)
+		if err != nil || got != want {
+			t.Fatalf("benchWSURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := benchWSURL("ftp://example.com"); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+}
+
+func TestCompareBench(t *testing.T) {
+	baseline := &BenchResult{RPS: 1000, P50Ms: 2, P95Ms: 5, P99Ms: 10}
+
+	if got := CompareBench(baseline, &BenchResult{RPS: 950, P50Ms: 2.1, P95Ms: 5.2, P99Ms: 10.5}, 10); len(got) != 0 {
+		t.Fatalf("expected no regressions within threshold, got %+v", got)
+	}
+
+	got := CompareBench(baseline, &BenchResult{RPS: 800, P50Ms: 2, P95Ms: 7, P99Ms: 10, Errors: 3}, 10)
+	metrics := make([]string, 0, len(got))
+	for _, r := range got {
+		metrics = append(metrics, r.Metric)
+	}
+	if strings.Join(metrics, ",") != "rps,p95,errors" {
+		t.Fatalf("unexpected regressions: %+v", got)
+	}
+}
+
+func TestWriteBenchResultRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	result := &BenchResult{Mode: "http", URL: "http://localhost", Concurrency: 4, Requests: 10, RPS: 123.4, P95Ms: 5}
+
+	jsonPath := filepath.Join(dir, "baseline.json")
+	if err := WriteBenchResult(jsonPath, "", result); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	loaded, err := LoadBenchBaseline(jsonPath)
+	if err != nil {
+		t.Fatalf("load baseline: %v", err)
+	}
+	if loaded.RPS != result.RPS || loaded.P95Ms != result.P95Ms {
+		t.Fatalf("round-trip mismatch: %+v", loaded)
+	}
+
+	csvPath := filepath.Join(dir, "results.csv")
+	if err := WriteBenchResult(csvPath, "", result); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "timestamp,mode,url") || !strings.Contains(lines[1], ",http,") {
+		t.Fatalf("unexpected csv: %q", data)
+	}
+
+	if err := WriteBenchResult(filepath.Join(dir, "out.xml"), "", result); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,452 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// benchSyncKeyPrefix prefixes the state key each simulated client updates.
+const benchSyncKeyPrefix = "bench_"
+
+// BenchWSConfig controls the `gospa bench ws` state sync load test. URL is the
+// page used for the session handshake; Concurrency is the number of simulated
+// clients. BenchConfig.WS is ignored.
+type BenchWSConfig struct {
+	BenchConfig
+	Sessions int           // Number of sessions the clients are spread across (clients in a session share broadcasts)
+	Senders  int           // Number of clients sending updates (default: all)
+	Rate     float64       // Updates per second sent by each sender
+	RampUp   time.Duration // Spread connection setup over this period
+	Drain    time.Duration // Wait for in-flight broadcasts after sending stops
+}
+
+// BenchStateSync is the state sync part of a `gospa bench ws` result. Latency
+// fields of the enclosing BenchResult measure fan-out: the time from a client
+// sending an update to each client receiving its broadcast.
+type BenchStateSync struct {
+	Clients           int     `json:"clients"`
+	Sessions          int     `json:"sessions"`
+	Connected         int     `json:"connected"`
+	HandshakeFailures int     `json:"handshakeFailures"`
+	HandshakeP50Ms    float64 `json:"handshakeP50Ms"`
+	HandshakeP95Ms    float64 `json:"handshakeP95Ms"`
+	RatePerSender     float64 `json:"ratePerSender"`
+	Senders           int     `json:"senders"`
+	Sent              int     `json:"sent"`
+	Delivered         int     `json:"delivered"`
+	FanOut            float64 `json:"fanOut"`
+	Coalesced         int     `json:"coalesced"`
+	Dropped           int     `json:"dropped"`
+}
+
+// benchSyncClient is one simulated browser tab.
+type benchSyncClient struct {
+	key  string
+	conn *websocket.Conn
+	sent int
+	// handshake is the time from the WebSocket dial to the init reply.
+	handshake time.Duration
+	errors    int
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	received  map[string]int // deliveries per sender key
+	coalesced int            // updates merged away by server-side coalescing
+}
+
+// benchSyncValue is the value each update carries: a per-sender sequence
+// number and the send time in microseconds since the epoch.
+type benchSyncValue struct {
+	Seq int   `json:"seq"`
+	TS  int64 `json:"ts"`
+}
+
+// BenchWS runs the state sync load test against a running GoSPA server and
+// reports broadcast fan-out latency and dropped messages.
+func BenchWS(config *BenchWSConfig) {
+	printer := NewColorPrinter()
+	if config == nil {
+		config = &BenchWSConfig{}
+	}
+	applyBenchWSDefaults(config)
+
+	if !config.JSONOutput {
+		printer.Title("GoSPA Bench")
+		printer.Subtitle("State sync load against %s: %d clients in %d sessions, %d senders at %g updates/s for %s",
+			config.URL, config.Concurrency, config.Sessions, config.Senders, config.Rate, config.Duration)
+	}
+
+	result, err := RunBenchWS(context.Background(), config)
+	if err != nil {
+		printer.Error("Benchmark failed: %v", err)
+		os.Exit(1)
+	}
+
+	reportBench(printer, &config.BenchConfig, result)
+}
+
+func applyBenchWSDefaults(config *BenchWSConfig) {
+	applyBenchDefaults(&config.BenchConfig)
+	if config.Sessions <= 0 {
+		config.Sessions = 1
+	}
+	config.Sessions = min(config.Sessions, config.Concurrency)
+	if config.Senders <= 0 || config.Senders > config.Concurrency {
+		config.Senders = config.Concurrency
+	}
+	if config.Rate <= 0 {
+		config.Rate = 1
+	}
+	if config.Drain <= 0 {
+		config.Drain = time.Second
+	}
+}
+
+// RunBenchWS connects the simulated clients, sends updates for
+// config.Duration, waits config.Drain for outstanding broadcasts, and returns
+// the aggregated result.
+func RunBenchWS(ctx context.Context, config *BenchWSConfig) (*BenchResult, error) {
+	applyBenchWSDefaults(config)
+
+	pageURL, err := benchPageURL(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	wsURL := config.URL
+	if strings.HasPrefix(wsURL, "http") {
+		// URL names the handshake page; connect to the default endpoint.
+		u, _ := url.Parse(wsURL)
+		u.Path, u.RawQuery = "", ""
+		wsURL = u.String()
+	}
+	if wsURL, err = benchWSURL(wsURL); err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Timeout: config.Timeout}
+	defer httpClient.CloseIdleConnections()
+
+	// Session handshake: one page load per session yields the cookie that
+	// the session's clients present when dialing.
+	cookies := make([]string, config.Sessions)
+	for i := range cookies {
+		cookie, err := benchSessionCookie(ctx, httpClient, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		cookies[i] = cookie
+	}
+
+	clients := make([]*benchSyncClient, config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range clients {
+		c := &benchSyncClient{key: benchSyncKeyPrefix + strconv.Itoa(i), received: make(map[string]int)}
+		clients[i] = c
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if config.RampUp > 0 {
+				time.Sleep(config.RampUp * time.Duration(i) / time.Duration(len(clients)))
+			}
+			if err := c.connect(ctx, wsURL, cookies[i%len(cookies)], config.Timeout); err != nil {
+				c.errors++
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var readers sync.WaitGroup
+	for _, c := range clients {
+		if c.conn == nil {
+			continue
+		}
+		readers.Add(1)
+		go func(c *benchSyncClient) {
+			defer readers.Done()
+			c.readLoop()
+		}(c)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	interval := time.Duration(float64(time.Second) / config.Rate)
+	start := time.Now()
+	var senders sync.WaitGroup
+	for i, c := range clients[:config.Senders] {
+		if c.conn == nil {
+			continue
+		}
+		senders.Add(1)
+		go func(c *benchSyncClient, offset time.Duration) {
+			defer senders.Done()
+			c.sendLoop(sendCtx, interval, offset, config.Timeout)
+		}(c, interval*time.Duration(i)/time.Duration(config.Senders))
+	}
+	senders.Wait()
+	elapsed := time.Since(start)
+	cancel()
+
+	select {
+	case <-time.After(config.Drain):
+	case <-ctx.Done():
+	}
+	for _, c := range clients {
+		if c.conn != nil {
+			_ = c.conn.Close()
+		}
+	}
+	readers.Wait()
+
+	result := summarizeBenchWS(clients, config, elapsed)
+	result.URL = wsURL
+	if result.StateSync.Connected == 0 {
+		return result, fmt.Errorf("all %d clients failed the handshake", config.Concurrency)
+	}
+	return result, nil
+}
+
+// connect dials the WebSocket endpoint with the session cookie and waits for
+// the server's init reply.
+func (c *benchSyncClient) connect(ctx context.Context, wsURL, cookie string, timeout time.Duration) error {
+	header := http.Header{}
+	if cookie != "" {
+		header.Set("Cookie", "gospa_session="+cookie)
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: timeout}
+	begin := time.Now()
+	conn, _, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return err
+	}
+	if err := conn.WriteJSON(map[string]any{"type": "init"}); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	_ = conn.SetReadDeadline(begin.Add(timeout))
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		var msg struct {
+			Type  string `json:"type"`
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		if msg.Type == "error" {
+			_ = conn.Close()
+			return fmt.Errorf("init rejected: %s", msg.Error)
+		}
+		if msg.Type == "init" {
+			break
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	c.conn = conn
+	c.handshake = time.Since(begin)
+	return nil
+}
+
+// sendLoop sends an update every interval, starting after offset so senders
+// don't fire in lockstep.
+func (c *benchSyncClient) sendLoop(ctx context.Context, interval, offset, timeout time.Duration) {
+	select {
+	case <-time.After(offset):
+	case <-ctx.Done():
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		_ = c.conn.SetWriteDeadline(now.Add(timeout))
+		err := c.conn.WriteJSON(map[string]any{
+			"type": "update",
+			"payload": map[string]any{
+				"key":   c.key,
+				"value": benchSyncValue{Seq: c.sent + 1, TS: now.UnixMicro()},
+			},
+		})
+		if err != nil {
+			c.errors++
+			return
+		}
+		c.sent++
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readLoop records broadcast deliveries until the connection is closed.
+// Direct acknowledgements of the client's own updates carry "success" and
+// are not broadcasts, so they are skipped.
+func (c *benchSyncClient) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		received := time.Now()
+		var msg struct {
+			Type      string                     `json:"type"`
+			Key       string                     `json:"key"`
+			Value     json.RawMessage            `json:"value"`
+			Success   *bool                      `json:"success"`
+			Patch     map[string]json.RawMessage `json:"patch"`
+			Coalesced int                        `json:"coalesced"`
+		}
+		if json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		switch {
+		case msg.Type == "sync" && msg.Success == nil:
+			c.record(msg.Key, msg.Value, received)
+		case msg.Type == "patch":
+			delivered := 0
+			for key, value := range msg.Patch {
+				if c.record(key, value, received) {
+					delivered++
+				}
+			}
+			if msg.Coalesced > delivered {
+				c.mu.Lock()
+				c.coalesced += msg.Coalesced - delivered
+				c.mu.Unlock()
+			}
+		}
+	}
+}
+
+// record counts one delivery of a bench update and its fan-out latency. It
+// reports whether key/value was a bench update.
+func (c *benchSyncClient) record(key string, value json.RawMessage, received time.Time) bool {
+	if !strings.HasPrefix(key, benchSyncKeyPrefix) {
+		return false
+	}
+	var v benchSyncValue
+	if json.Unmarshal(value, &v) != nil || v.Seq == 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.received[key]++
+	c.latencies = append(c.latencies, received.Sub(time.UnixMicro(v.TS)))
+	return true
+}
+
+// summarizeBenchWS aggregates client results. A client is expected to receive
+// every update from each sender it heard from at least once, and always its
+// own; anything missing that coalescing doesn't account for is dropped.
+func summarizeBenchWS(clients []*benchSyncClient, config *BenchWSConfig, elapsed time.Duration) *BenchResult {
+	sent := make(map[string]int, len(clients))
+	for _, c := range clients {
+		if c.sent > 0 {
+			sent[c.key] = c.sent
+		}
+	}
+
+	stats := &BenchStateSync{
+		Clients:       len(clients),
+		Sessions:      config.Sessions,
+		Senders:       config.Senders,
+		RatePerSender: config.Rate,
+	}
+	samples := make([]benchSample, 0, len(clients))
+	var handshakes []time.Duration
+	for _, c := range clients {
+		stats.Sent += c.sent
+		samples = append(samples, benchSample{latencies: c.latencies, errors: c.errors})
+		if c.conn == nil {
+			stats.HandshakeFailures++
+			continue
+		}
+		stats.Connected++
+		handshakes = append(handshakes, c.handshake)
+		stats.Coalesced += c.coalesced
+
+		expected, delivered := 0, 0
+		for key, n := range c.received {
+			expected += sent[key]
+			delivered += n
+		}
+		if _, ok := c.received[c.key]; !ok {
+			expected += c.sent
+		}
+		stats.Delivered += delivered
+		stats.Dropped += max(expected-delivered-c.coalesced, 0)
+	}
+	if stats.Sent > 0 {
+		stats.FanOut = float64(stats.Delivered+stats.Coalesced) / float64(stats.Sent)
+	}
+	if len(handshakes) > 0 {
+		sort.Slice(handshakes, func(i, j int) bool { return handshakes[i] < handshakes[j] })
+		stats.HandshakeP50Ms = durationMs(percentile(handshakes, 0.50))
+		stats.HandshakeP95Ms = durationMs(percentile(handshakes, 0.95))
+	}
+
+	result := summarizeBench(samples, elapsed)
+	result.Mode = "ws-sync"
+	result.Concurrency = len(clients)
+	result.StateSync = stats
+	return result
+}
+
+// benchSessionCookie loads pageURL and returns the gospa_session cookie the
+// server issued, or "" if it didn't set one.
+func benchSessionCookie(ctx context.Context, client *http.Client, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req) // #nosec //nolint:gosec // intentional: user-provided benchmark target
+	if err != nil {
+		return "", fmt.Errorf("session handshake failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "gospa_session" {
+			return cookie.Value, nil
+		}
+	}
+	return "", nil
+}
+
+// benchPageURL converts a ws(s) URL to the http(s) root page used for the
+// session handshake; http(s) URLs are used as-is.
+func benchPageURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.String(), nil
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return "", fmt.Errorf("invalid URL %q", raw)
+	}
+	u.Path = "/"
+	u.RawQuery = ""
+	return u.String(), nil
+}
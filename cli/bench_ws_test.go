@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+func TestSummarizeBenchWSDrops(t *testing.T) {
+	conn := &websocket.Conn{}
+	clients := []*benchSyncClient{
+		{key: "bench_0", conn: conn, sent: 10, received: map[string]int{"bench_0": 10, "bench_1": 10}},
+		// Two of bench_1's updates were merged into a patch, one was lost.
+		{key: "bench_1", conn: conn, sent: 10, received: map[string]int{"bench_0": 10, "bench_1": 7}, coalesced: 2},
+		// Receive-only client that never heard from bench_0.
+		{key: "bench_2", conn: conn, received: map[string]int{"bench_1": 5}},
+		// Failed handshake.
+		{key: "bench_3", received: map[string]int{}, errors: 1},
+	}
+	clients[0].latencies = []time.Duration{time.Millisecond, 3 * time.Millisecond}
+
+	result := summarizeBenchWS(clients, &BenchWSConfig{Sessions: 1, Senders: 2, Rate: 5}, time.Second)
+	ss := result.StateSync
+	if ss == nil {
+		t.Fatal("expected state sync stats")
+	}
+	if ss.Connected != 3 || ss.HandshakeFailures != 1 {
+		t.Fatalf("connected/failures = %d/%d, want 3/1", ss.Connected, ss.HandshakeFailures)
+	}
+	if ss.Sent != 20 || ss.Delivered != 42 || ss.Coalesced != 2 {
+		t.Fatalf("sent/delivered/coalesced = %d/%d/%d, want 20/42/2", ss.Sent, ss.Delivered, ss.Coalesced)
+	}
+	if ss.Dropped != 6 {
+		t.Fatalf("dropped = %d, want 6", ss.Dropped)
+	}
+	if ss.FanOut != 2.2 {
+		t.Fatalf("fan-out = %v, want 2.2", ss.FanOut)
+	}
+	if result.Mode != "ws-sync" || result.Requests != 2 || result.Errors != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestSummarizeBenchWSOwnUpdatesNeverSeen(t *testing.T) {
+	clients := []*benchSyncClient{
+		{key: "bench_0", conn: &websocket.Conn{}, sent: 4, received: map[string]int{}},
+	}
+	result := summarizeBenchWS(clients, &BenchWSConfig{Sessions: 1, Senders: 1, Rate: 1}, time.Second)
+	if result.StateSync.Dropped != 4 {
+		t.Fatalf("dropped = %d, want 4", result.StateSync.Dropped)
+	}
+}
+
+func TestBenchPageURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:3000/blog":    "http://localhost:3000/blog",
+		"ws://localhost:3000/_gospa/ws": "http://localhost:3000/",
+		"wss://example.com/_gospa/ws":   "https://example.com/",
+	}
+	for in, want := range tests {
+		got, err := benchPageURL(in)
+		if err != nil || got != want {
+			t.Fatalf("benchPageURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := benchPageURL("ftp://example.com"); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+}
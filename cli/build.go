@@ -41,6 +41,10 @@ type BuildConfig struct {
 	Watch        bool   // Watch mode after build
 	NoStatic     bool   // Skip static asset copying
 	NoCompress   bool   // Skip compression
+	PruneState   string // "warn" or "fail" on DefaultState keys no component uses; "" skips the check
+
+	// Deploy, when set, also writes Docker/Kubernetes artifacts for the build.
+	Deploy *DeployConfig
 }
 
 // BuildSummary captures the important outputs from a production build.
@@ -51,6 +55,7 @@ type BuildSummary struct {
 	GoBinaryPath       string
 	StaticFilesCopied  int
 	CompressedFiles    int
+	DeployFiles        []string
 }
 
 // BuildAllConfig holds configuration for multi-platform builds.
@@ -176,6 +181,14 @@ func BuildWithConfig(config *BuildConfig) (*BuildSummary, error) {
 		fmt.Println("Skipping go mod tidy (set GOSPA_RUN_MOD_TIDY=1 to enable)")
 	}
 
+	// Step 3.6: Drop DefaultState keys no component references
+	if config.PruneState != "" {
+		fmt.Println("Checking state key usage...")
+		if err := pruneStateForBuild(config, "."); err != nil {
+			return nil, err
+		}
+	}
+
 	// Step 4: Build Go binary
 	fmt.Println("Building Go binary...")
 	binaryPath, err := buildGoBinary(config)
@@ -212,6 +225,16 @@ func BuildWithConfig(config *BuildConfig) (*BuildSummary, error) {
 		}
 	}
 
+	// Step 8: Generate deployment artifacts if requested
+	if config.Deploy != nil {
+		fmt.Println("Generating deployment artifacts...")
+		files, err := GenerateDeploy(config, config.Deploy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate deployment artifacts: %w", err)
+		}
+		summary.DeployFiles = files
+	}
+
 	return summary, nil
 }
 
@@ -848,6 +871,9 @@ func printBuildSummary(printer *ColorPrinter, summary *BuildSummary) {
 	}
 	printer.Info("Static files copied: %d", summary.StaticFilesCopied)
 	printer.Info("Static files compressed: %d", summary.CompressedFiles)
+	for _, path := range summary.DeployFiles {
+		printer.Info("Deploy: %s", path)
+	}
 }
 
 func displayOrFallback(value, fallback string) string {
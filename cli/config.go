@@ -25,6 +25,7 @@ type GoSPAConfig struct {
 	Serve    ServeSection    `yaml:"serve"`
 	Plugins  []PluginConfig  `yaml:"plugins"`
 	BuildAll BuildAllSection `yaml:"build-all"`
+	Deploy   DeploySection   `yaml:"deploy"`
 }
 
 // ProjectSection holds project-level configuration.
@@ -58,6 +59,8 @@ type BuildSection struct {
 	LDFlags   string   `yaml:"ldflags"`
 	Tags      string   `yaml:"tags"`
 	Targets   []Target `yaml:"targets"`
+	// PruneState is "warn" or "fail", see gospa build --prune-state.
+	PruneState string `yaml:"prune_state"`
 }
 
 // Target represents a build target platform/arch combination.
@@ -91,6 +94,53 @@ type BuildAllSection struct {
 	Parallel  int      `yaml:"parallel"`
 }
 
+// DeploySection holds the parameters for `gospa build --deploy` artifacts.
+type DeploySection struct {
+	Dir         string            `yaml:"dir"`
+	Image       string            `yaml:"image"`
+	Port        int               `yaml:"port"`
+	Kubernetes  bool              `yaml:"kubernetes"`
+	Namespace   string            `yaml:"namespace"`
+	Replicas    int               `yaml:"replicas"`
+	MinReplicas int               `yaml:"min_replicas"`
+	MaxReplicas int               `yaml:"max_replicas"`
+	CPUTarget   int               `yaml:"cpu_target"`
+	Env         map[string]string `yaml:"env"`
+	Domain      string            `yaml:"domain"`
+	InstallDir  string            `yaml:"install_dir"`
+	User        string            `yaml:"user"`
+}
+
+// DeployConfig returns the deploy section as a DeployConfig for GenerateDeploy.
+func (c *GoSPAConfig) DeployConfig() *DeployConfig {
+	return &DeployConfig{
+		Dir:         c.Deploy.Dir,
+		Name:        c.Project.Name,
+		Image:       c.Deploy.Image,
+		Port:        c.Deploy.Port,
+		Kubernetes:  c.Deploy.Kubernetes,
+		Namespace:   c.Deploy.Namespace,
+		Replicas:    c.Deploy.Replicas,
+		MinReplicas: c.Deploy.MinReplicas,
+		MaxReplicas: c.Deploy.MaxReplicas,
+		CPUTarget:   c.Deploy.CPUTarget,
+		Env:         c.Deploy.Env,
+	}
+}
+
+// ScaffoldConfig returns the deploy section as a ScaffoldConfig for target.
+func (c *GoSPAConfig) ScaffoldConfig(target string) *ScaffoldConfig {
+	return &ScaffoldConfig{
+		Target:     target,
+		OutputDir:  c.Deploy.Dir,
+		Name:       c.Project.Name,
+		Domain:     c.Deploy.Domain,
+		Port:       c.Deploy.Port,
+		InstallDir: c.Deploy.InstallDir,
+		User:       c.Deploy.User,
+	}
+}
+
 // PluginConfig holds plugin configuration.
 type PluginConfig struct {
 	Name    string `yaml:"name"`
@@ -223,6 +273,13 @@ func DefaultConfig() *GoSPAConfig {
 			Manifest:  true,
 			Parallel:  4,
 		},
+		Deploy: DeploySection{
+			Dir:         "deploy",
+			Port:        3000,
+			Replicas:    2,
+			MaxReplicas: 10,
+			CPUTarget:   70,
+		},
 	}
 }
 
@@ -302,6 +359,7 @@ func (c *GoSPAConfig) ToBuildConfig() *BuildConfig {
 		AssetsDir:  c.Build.AssetsDir,
 		LDFlags:    c.Build.LDFlags,
 		Tags:       c.Build.Tags,
+		PruneState: c.Build.PruneState,
 		NoManifest: false,
 		NoStatic:   false,
 		NoCompress: false,
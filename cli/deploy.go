@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DeployConfig controls the deployment artifacts written by `gospa build --deploy`.
+type DeployConfig struct {
+	Dir         string            // Output directory for the artifacts (default "deploy")
+	Name        string            // App name used for images, containers, and Kubernetes objects
+	Image       string            // Container image (default "<name>:latest")
+	Port        int               // Port the server listens on (default 3000)
+	Kubernetes  bool              // Also write Kubernetes manifests
+	Namespace   string            // Kubernetes namespace (default: none, use the current context)
+	Replicas    int               // Initial Deployment replicas (default 2)
+	MinReplicas int               // HPA minimum (default Replicas)
+	MaxReplicas int               // HPA maximum (default 10)
+	CPUTarget   int               // HPA target CPU utilization percent (default 70)
+	Env         map[string]string // Extra environment variables for the app container
+}
+
+// Health endpoints served by every GoSPA app, used for container probes.
+const (
+	deployLivenessPath  = "/_gospa/healthz"
+	deployReadinessPath = "/_gospa/readyz"
+)
+
+// deployTemplateData is the view passed to the artifact templates.
+type deployTemplateData struct {
+	*DeployConfig
+	GoVersion   string
+	OutputDir   string
+	LDFlags     string
+	Tags        string
+	CGO         bool
+	BuildEnv    string
+	HasStatic   bool
+	HasManifest bool
+	EnvKeys     []string
+	Liveness    string
+	Readiness   string
+}
+
+// GenerateDeploy writes a Dockerfile, a docker-compose file with Redis, and
+// optionally Kubernetes manifests for the build described by build. It
+// returns the paths written. Existing files in the deploy directory are
+// overwritten.
+func GenerateDeploy(build *BuildConfig, deploy *DeployConfig) ([]string, error) {
+	d := *deploy
+	applyDeployDefaults(&d)
+
+	outputDir, err := projectRelative(build.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	data := deployTemplateData{
+		DeployConfig: &d,
+		GoVersion:    projectGoVersion(),
+		OutputDir:    outputDir,
+		LDFlags:      build.LDFlags,
+		Tags:         build.Tags,
+		CGO:          build.CGO,
+		BuildEnv:     displayOrFallback(build.Env, "production"),
+		HasStatic:    dirExists(filepath.Join(build.OutputDir, "static")),
+		HasManifest:  fileExists(filepath.Join(build.OutputDir, "manifest.json")),
+		Liveness:     deployLivenessPath,
+		Readiness:    deployReadinessPath,
+	}
+	for key := range d.Env {
+		data.EnvKeys = append(data.EnvKeys, key)
+	}
+	sort.Strings(data.EnvKeys)
+
+	type artifact struct {
+		path string
+		tmpl *template.Template
+	}
+	files := []artifact{
+		{filepath.Join(d.Dir, "Dockerfile"), dockerfileTemplate},
+		{filepath.Join(d.Dir, "docker-compose.yml"), composeTemplate},
+	}
+	if d.Kubernetes {
+		files = append(files,
+			artifact{filepath.Join(d.Dir, "k8s", "deployment.yaml"), k8sDeploymentTemplate},
+			artifact{filepath.Join(d.Dir, "k8s", "service.yaml"), k8sServiceTemplate},
+			artifact{filepath.Join(d.Dir, "k8s", "hpa.yaml"), k8sHPATemplate},
+		)
+	}
+
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0750); err != nil {
+			return written, fmt.Errorf("failed to create %s: %w", filepath.Dir(f.path), err)
+		}
+		var b strings.Builder
+		if err := f.tmpl.Execute(&b, data); err != nil {
+			return written, fmt.Errorf("failed to render %s: %w", f.path, err)
+		}
+		if err := os.WriteFile(f.path, []byte(b.String()), 0600); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
+
+func applyDeployDefaults(d *DeployConfig) {
+	if d.Dir == "" {
+		d.Dir = "deploy"
+	}
+	d.Name = dnsLabel(d.Name)
+	if d.Name == "" {
+		d.Name = dnsLabel(filepath.Base(getCurrentDir()))
+	}
+	if d.Name == "" {
+		d.Name = "gospa-app"
+	}
+	if d.Image == "" {
+		d.Image = d.Name + ":latest"
+	}
+	if d.Port <= 0 {
+		d.Port = 3000
+	}
+	if d.Replicas <= 0 {
+		d.Replicas = 2
+	}
+	if d.MinReplicas <= 0 {
+		d.MinReplicas = d.Replicas
+	}
+	if d.MaxReplicas < d.MinReplicas {
+		d.MaxReplicas = max(10, d.MinReplicas)
+	}
+	if d.CPUTarget <= 0 || d.CPUTarget > 100 {
+		d.CPUTarget = 70
+	}
+}
+
+// dnsLabel lowercases name and replaces anything Kubernetes object names
+// do not allow with dashes.
+func dnsLabel(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	label := strings.Trim(b.String(), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// projectRelative returns dir relative to the project root, slash-separated,
+// as Docker COPY expects.
+func projectRelative(dir string) (string, error) {
+	if dir == "" {
+		dir = "dist"
+	}
+	if filepath.IsAbs(dir) {
+		rel, err := filepath.Rel(getCurrentDir(), dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", fmt.Errorf("build output %s must be inside the project for --deploy", dir)
+		}
+		dir = rel
+	}
+	return filepath.ToSlash(filepath.Clean(dir)), nil
+}
+
+// projectGoVersion reads the go directive from go.mod, falling back to the
+// toolchain running the CLI.
+func projectGoVersion() string {
+	if f, err := os.Open("go.mod"); err == nil {
+		defer func() { _ = f.Close() }()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if version, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "go "); ok {
+				return strings.TrimSpace(version)
+			}
+		}
+	}
+	version := strings.TrimPrefix(runtime.Version(), "go")
+	if parts := strings.SplitN(version, ".", 3); len(parts) >= 2 {
+		return parts[0] + "." + parts[1]
+	}
+	return "1"
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+var deployFuncs = template.FuncMap{
+	// quote renders a double-quoted scalar, valid in YAML and Dockerfiles.
+	"quote": strconv.Quote,
+}
+
+const deployHeader = "# Generated by `gospa build --deploy`. Changes are overwritten on the next run;\n# edit the deploy section of gospa.config.yaml instead.\n"
+
+var dockerfileTemplate = template.Must(template.New("Dockerfile").Funcs(deployFuncs).Parse(deployHeader + `# Build from the project root: docker build -f {{.Dir}}/Dockerfile .
+
+FROM golang:{{.GoVersion}}{{if not .CGO}}-alpine{{end}} AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED={{if .CGO}}1{{else}}0{{end}} GOOS=linux go build -trimpath{{if .LDFlags}} -ldflags {{quote .LDFlags}}{{end}}{{if .Tags}} -tags {{quote .Tags}}{{end}} -o /out/server .
+
+FROM gcr.io/distroless/{{if .CGO}}base{{else}}static{{end}}-debian12:nonroot
+WORKDIR /app
+COPY --from=build /out/server ./server
+{{- if .HasStatic}}
+# Client runtime, islands, and precompressed assets from gospa build
+COPY {{.OutputDir}}/static ./static
+{{- end}}
+{{- if .HasManifest}}
+COPY {{.OutputDir}}/manifest.json ./manifest.json
+{{- end}}
+ENV PORT={{.Port}} GOSPA_ENV={{.BuildEnv}}
+EXPOSE {{.Port}}
+USER nonroot:nonroot
+ENTRYPOINT ["/app/server"]
+`))
+
+var composeTemplate = template.Must(template.New("docker-compose.yml").Funcs(deployFuncs).Parse(deployHeader + `# Redis backs Storage and PubSub so sessions, caches, and broadcasts are shared
+# across replicas; read REDIS_URL in main.go and pass store/redis to gospa.Config.
+services:
+  app:
+    build:
+      context: ..
+      dockerfile: {{.Dir}}/Dockerfile
+    image: {{quote .Image}}
+    ports:
+      - "{{.Port}}:{{.Port}}"
+    environment:
+      PORT: "{{.Port}}"
+      REDIS_URL: "redis://redis:6379/0"
+{{- range .EnvKeys}}
+      {{.}}: {{quote (index $.Env .)}}
+{{- end}}
+    depends_on:
+      redis:
+        condition: service_healthy
+    restart: unless-stopped
+
+  redis:
+    image: redis:7-alpine
+    command: ["redis-server", "--appendonly", "yes"]
+    healthcheck:
+      test: ["CMD", "redis-cli", "ping"]
+      interval: 5s
+      timeout: 3s
+      retries: 5
+    volumes:
+      - redis-data:/data
+    restart: unless-stopped
+
+volumes:
+  redis-data:
+`))
+
+var k8sDeploymentTemplate = template.Must(template.New("deployment.yaml").Funcs(deployFuncs).Parse(deployHeader + `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+{{- if .Namespace}}
+  namespace: {{.Namespace}}
+{{- end}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{.Name}}
+    spec:
+      terminationGracePeriodSeconds: 30
+      containers:
+        - name: {{.Name}}
+          image: {{quote .Image}}
+          ports:
+            - name: http
+              containerPort: {{.Port}}
+          env:
+            - name: PORT
+              value: "{{.Port}}"
+{{- range .EnvKeys}}
+            - name: {{.}}
+              value: {{quote (index $.Env .)}}
+{{- end}}
+          readinessProbe:
+            httpGet:
+              path: {{.Readiness}}
+              port: http
+            periodSeconds: 5
+            failureThreshold: 3
+          livenessProbe:
+            httpGet:
+              path: {{.Liveness}}
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+            failureThreshold: 3
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              memory: 512Mi
+`))
+
+var k8sServiceTemplate = template.Must(template.New("service.yaml").Funcs(deployFuncs).Parse(deployHeader + `apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+{{- if .Namespace}}
+  namespace: {{.Namespace}}
+{{- end}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  selector:
+    app.kubernetes.io/name: {{.Name}}
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`))
+
+var k8sHPATemplate = template.Must(template.New("hpa.yaml").Funcs(deployFuncs).Parse(deployHeader + `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{.Name}}
+{{- if .Namespace}}
+  namespace: {{.Namespace}}
+{{- end}}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{.Name}}
+  minReplicas: {{.MinReplicas}}
+  maxReplicas: {{.MaxReplicas}}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{.CPUTarget}}
+`))
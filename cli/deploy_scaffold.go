@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// ScaffoldConfig holds configuration for `gospa deploy scaffold`.
+type ScaffoldConfig struct {
+	Target       string // systemd, caddy, or nginx
+	OutputDir    string // Directory the file is written to (default "deploy")
+	Stdout       bool   // Print the config instead of writing it
+	Name         string // App name, used for the unit and upstream names
+	Domain       string // Public host name served by the proxy (default "example.com")
+	Port         int    // Port the app listens on (default 3000)
+	InstallDir   string // Directory holding the built server and static/ on the host (default /opt/<name>)
+	User         string // Service user for systemd (default <name>)
+	WSPath       string // WebSocket endpoint (default "/_gospa/ws")
+	StaticPrefix string // URL prefix for static files (default "/static")
+}
+
+// ScaffoldTargets lists the targets accepted by `gospa deploy scaffold`.
+var ScaffoldTargets = []string{"systemd", "caddy", "nginx"}
+
+// DeployScaffold writes (or prints) a systemd unit or reverse-proxy config
+// for the built app.
+func DeployScaffold(config *ScaffoldConfig) {
+	printer := NewColorPrinter()
+
+	name, content, err := RenderScaffold(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if config.Stdout {
+		fmt.Print(content)
+		return
+	}
+
+	dir := displayOrFallback(config.OutputDir, "deploy")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to create %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	printer.Success("Wrote %s", path)
+}
+
+// RenderScaffold renders the config for config.Target and returns its file
+// name and contents.
+func RenderScaffold(config *ScaffoldConfig) (string, string, error) {
+	c := *config
+	c.Name = dnsLabel(c.Name)
+	if c.Name == "" {
+		c.Name = dnsLabel(filepath.Base(getCurrentDir()))
+	}
+	if c.Name == "" {
+		c.Name = "gospa-app"
+	}
+	if c.Domain == "" {
+		c.Domain = "example.com"
+	}
+	if c.Port <= 0 {
+		c.Port = 3000
+	}
+	if c.InstallDir == "" {
+		c.InstallDir = "/opt/" + c.Name
+	}
+	c.InstallDir = strings.TrimRight(c.InstallDir, "/")
+	if c.User == "" {
+		c.User = c.Name
+	}
+	if c.WSPath == "" {
+		c.WSPath = "/_gospa/ws"
+	}
+	c.StaticPrefix = "/" + strings.Trim(c.StaticPrefix, "/")
+	if c.StaticPrefix == "/" {
+		c.StaticPrefix = "/static"
+	}
+
+	var name string
+	var tmpl *template.Template
+	switch strings.ToLower(c.Target) {
+	case "systemd":
+		name, tmpl = c.Name+".service", systemdTemplate
+	case "caddy":
+		name, tmpl = "Caddyfile", caddyTemplate
+	case "nginx":
+		name, tmpl = c.Name+".nginx.conf", nginxTemplate
+	default:
+		return "", "", fmt.Errorf("unknown target %q (want one of %s)", c.Target, strings.Join(ScaffoldTargets, ", "))
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, struct {
+		ScaffoldConfig
+		Var string // Name as an nginx variable suffix
+	}{c, strings.ReplaceAll(c.Name, "-", "_")}); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return name, b.String(), nil
+}
+
+var systemdTemplate = template.Must(template.New("systemd").Parse(`# Generated by ` + "`gospa deploy scaffold --target systemd`" + `.
+# Install: copy dist/ to {{.InstallDir}}, then
+#   sudo useradd --system --home {{.InstallDir}} {{.User}}
+#   sudo cp {{.Name}}.service /etc/systemd/system/
+#   sudo systemctl daemon-reload && sudo systemctl enable --now {{.Name}}
+
+[Unit]
+Description={{.Name}} (GoSPA)
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.User}}
+WorkingDirectory={{.InstallDir}}
+ExecStart={{.InstallDir}}/server
+Environment=PORT={{.Port}}
+Environment=GOSPA_ENV=production
+# Optional overrides (secrets, REDIS_URL, ...), one KEY=value per line.
+EnvironmentFile=-/etc/{{.Name}}/env
+Restart=on-failure
+RestartSec=2
+# Give open WebSocket and SSE connections time to drain on stop.
+KillSignal=SIGTERM
+TimeoutStopSec=30
+# Each WebSocket client holds a file descriptor.
+LimitNOFILE=65536
+
+NoNewPrivileges=true
+ProtectSystem=strict
+ProtectHome=true
+PrivateTmp=true
+ReadWritePaths={{.InstallDir}}
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+var caddyTemplate = template.Must(template.New("caddy").Parse(`# Generated by ` + "`gospa deploy scaffold --target caddy`" + `.
+# Caddy provisions TLS for {{.Domain}} and proxies WebSocket upgrades on
+# {{.WSPath}} without extra configuration. Set PublicOrigin to
+# https://{{.Domain}} in the app config.
+
+{{.Domain}} {
+	encode zstd gzip
+
+	# Serve built assets directly, preferring the .br/.gz files from gospa build.
+	handle_path {{.StaticPrefix}}/* {
+		root * {{.InstallDir}}/static
+		@hashed path *.js *.css *.woff2
+		header @hashed Cache-Control "public, max-age=31536000, immutable"
+		header ?Cache-Control "public, max-age=86400"
+		file_server {
+			precompressed br gzip
+		}
+	}
+
+	handle {
+		reverse_proxy 127.0.0.1:{{.Port}} {
+			# Stream SSR and SSE responses without buffering.
+			flush_interval -1
+		}
+	}
+}
+`))
+
+var nginxTemplate = template.Must(template.New("nginx").Parse(`# Generated by ` + "`gospa deploy scaffold --target nginx`" + `.
+# Install as /etc/nginx/conf.d/{{.Name}}.conf (inside the http block), add TLS
+# (e.g. certbot --nginx -d {{.Domain}}), and set PublicOrigin to
+# https://{{.Domain}} in the app config.
+
+upstream gospa_{{.Var}} {
+	server 127.0.0.1:{{.Port}};
+	keepalive 32;
+}
+
+map $http_upgrade $gospa_{{.Var}}_connection_upgrade {
+	default upgrade;
+	''      close;
+}
+
+map $uri $gospa_{{.Var}}_static_cache {
+	~*\.(?:js|css|woff2)$ "public, max-age=31536000, immutable";
+	default               "public, max-age=86400";
+}
+
+server {
+	listen 80;
+	listen [::]:80;
+	server_name {{.Domain}};
+
+	client_max_body_size 10m;
+
+	gzip on;
+	gzip_vary on;
+	gzip_proxied any;
+	gzip_comp_level 5;
+	gzip_types text/plain text/css application/javascript application/json image/svg+xml;
+
+	# Serve built assets directly, preferring the .gz files from gospa build.
+	location {{.StaticPrefix}}/ {
+		alias {{.InstallDir}}/static/;
+		gzip_static on;
+		add_header Cache-Control $gospa_{{.Var}}_static_cache;
+		access_log off;
+	}
+
+	# WebSocket state sync: forward the upgrade and keep idle connections open
+	# well past the server heartbeat.
+	location = {{.WSPath}} {
+		proxy_pass http://gospa_{{.Var}};
+		proxy_http_version 1.1;
+		proxy_set_header Upgrade $http_upgrade;
+		proxy_set_header Connection $gospa_{{.Var}}_connection_upgrade;
+		proxy_set_header Host $host;
+		proxy_set_header X-Real-IP $remote_addr;
+		proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+		proxy_set_header X-Forwarded-Proto $scheme;
+		proxy_read_timeout 1h;
+		proxy_send_timeout 1h;
+		proxy_buffering off;
+	}
+
+	location / {
+		proxy_pass http://gospa_{{.Var}};
+		proxy_http_version 1.1;
+		proxy_set_header Connection "";
+		proxy_set_header Host $host;
+		proxy_set_header X-Real-IP $remote_addr;
+		proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+		proxy_set_header X-Forwarded-Proto $scheme;
+		# Stream SSR and SSE responses without buffering.
+		proxy_buffering off;
+		proxy_read_timeout 1h;
+	}
+}
+`))
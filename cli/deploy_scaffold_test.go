@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderScaffold(t *testing.T) {
+	base := ScaffoldConfig{Name: "My App", Domain: "app.example.com", Port: 8080}
+
+	cases := map[string]struct {
+		file string
+		want []string
+	}{
+		"systemd": {"my-app.service", []string{
+			"ExecStart=/opt/my-app/server",
+			"Environment=PORT=8080",
+			"User=my-app",
+		}},
+		"caddy": {"Caddyfile", []string{
+			"app.example.com {",
+			"handle_path /static/*",
+			"reverse_proxy 127.0.0.1:8080",
+			"precompressed br gzip",
+		}},
+		"nginx": {"my-app.nginx.conf", []string{
+			"server 127.0.0.1:8080;",
+			"location = /_gospa/ws {",
+			"proxy_set_header Upgrade $http_upgrade;",
+			"proxy_set_header Connection $gospa_my_app_connection_upgrade;",
+			"alias /opt/my-app/static/;",
+			"add_header Cache-Control $gospa_my_app_static_cache;",
+		}},
+	}
+	for target, tc := range cases {
+		cfg := base
+		cfg.Target = target
+		name, content, err := RenderScaffold(&cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if name != tc.file {
+			t.Fatalf("%s: file name = %q, want %q", target, name, tc.file)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(content, want) {
+				t.Fatalf("%s: output missing %q:\n%s", target, want, content)
+			}
+		}
+	}
+
+	if _, _, err := RenderScaffold(&ScaffoldConfig{Target: "apache"}); err == nil {
+		t.Fatal("expected an error for an unknown target")
+	}
+}
+
+func TestRenderScaffoldCustomPaths(t *testing.T) {
+	_, content, err := RenderScaffold(&ScaffoldConfig{
+		Target:       "nginx",
+		Name:         "app",
+		InstallDir:   "/srv/app/",
+		WSPath:       "/live",
+		StaticPrefix: "assets/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"location = /live {", "location /assets/ {", "alias /srv/app/static/;", "server_name example.com;"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("output missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDeploy(t *testing.T) {
+	tmp := t.TempDir()
+	oldWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(oldWD)
+	}()
+
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.26\n"), 0600); err != nil {
+		t.Fatalf("write go.mod failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join("dist", "static"), 0750); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+
+	build := &BuildConfig{OutputDir: "dist", LDFlags: "-s -w", Tags: "prod"}
+	files, err := GenerateDeploy(build, &DeployConfig{Name: "My App", Env: map[string]string{"LOG_LEVEL": "info"}})
+	if err != nil {
+		t.Fatalf("GenerateDeploy failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected Dockerfile and compose only, got %v", files)
+	}
+
+	dockerfile := readTestFile(t, filepath.Join("deploy", "Dockerfile"))
+	for _, want := range []string{
+		"FROM golang:1.26-alpine AS build",
+		`-ldflags "-s -w" -tags "prod"`,
+		"COPY dist/static ./static",
+		"distroless/static-debian12",
+	} {
+		if !strings.Contains(dockerfile, want) {
+			t.Fatalf("Dockerfile missing %q:\n%s", want, dockerfile)
+		}
+	}
+	if strings.Contains(dockerfile, "manifest.json") {
+		t.Fatal("Dockerfile copies a manifest that was not built")
+	}
+
+	compose := readTestFile(t, filepath.Join("deploy", "docker-compose.yml"))
+	for _, want := range []string{`image: "my-app:latest"`, "redis:7-alpine", `LOG_LEVEL: "info"`} {
+		if !strings.Contains(compose, want) {
+			t.Fatalf("compose missing %q:\n%s", want, compose)
+		}
+	}
+	if _, err := os.Stat(filepath.Join("deploy", "k8s")); !os.IsNotExist(err) {
+		t.Fatal("Kubernetes manifests written without being requested")
+	}
+
+	files, err = GenerateDeploy(build, &DeployConfig{Name: "app", Kubernetes: true, Namespace: "web", MaxReplicas: 4})
+	if err != nil {
+		t.Fatalf("GenerateDeploy with Kubernetes failed: %v", err)
+	}
+	if len(files) != 5 {
+		t.Fatalf("expected 5 artifacts, got %v", files)
+	}
+	deployment := readTestFile(t, filepath.Join("deploy", "k8s", "deployment.yaml"))
+	for _, want := range []string{"namespace: web", "path: /_gospa/readyz", "path: /_gospa/healthz"} {
+		if !strings.Contains(deployment, want) {
+			t.Fatalf("deployment missing %q:\n%s", want, deployment)
+		}
+	}
+	hpa := readTestFile(t, filepath.Join("deploy", "k8s", "hpa.yaml"))
+	if !strings.Contains(hpa, "minReplicas: 2") || !strings.Contains(hpa, "maxReplicas: 4") {
+		t.Fatalf("unexpected HPA bounds:\n%s", hpa)
+	}
+}
+
+func TestDNSLabel(t *testing.T) {
+	for in, want := range map[string]string{
+		"My App":   "my-app",
+		"--api_v2": "api-v2",
+		"":         "",
+	} {
+		if got := dnsLabel(in); got != want {
+			t.Fatalf("dnsLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s failed: %v", path, err)
+	}
+	return string(data)
+}
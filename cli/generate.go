@@ -59,7 +59,7 @@ func Generate(config *GenerateConfig) {
 		routesDir = config.InputDir
 	}
 
-	if err := routing_generator.Generate(routesDir); err != nil {
+	if err := routing_generator.GenerateWithOptions(routesDir, routing_generator.Options{BasePath: config.BasePath}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error generating Go routes: %v\n", err)
 		// Non-fatal when called from a hot-reload goroutine; just return.
 		return
@@ -115,6 +115,7 @@ type GenerateConfig struct {
 	RoutesOnly    bool     // Only generate routes
 	Strict        bool     // Strict type checking
 	NoTempl       bool     // Skip templ generate
+	BasePath      string   // URL prefix of the routes, for apps mounted with App.Mount
 }
 
 // GenerateWithConfig generates code with custom configuration.
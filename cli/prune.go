@@ -4,8 +4,13 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aydenstechdungeon/gospa/state"
 )
@@ -219,6 +224,11 @@ func printSummaryReport(report *state.PruningReport, dryRun bool) {
 		fmt.Printf("\n%s files: %d\n", action, len(report.PrunedFiles))
 	}
 
+	if len(report.StateKeys) > 0 {
+		fmt.Printf("\nDefaultState keys:        %d\n", len(report.StateKeys))
+		fmt.Printf("Unused DefaultState keys: %d\n", len(report.UnusedStateKeys))
+	}
+
 	if len(report.Errors) > 0 {
 		fmt.Printf("\nErrors: %d\n", len(report.Errors))
 	}
@@ -271,6 +281,24 @@ func printVerboseReport(report *state.PruningReport) {
 		fmt.Println()
 	}
 
+	if len(report.StateKeys) > 0 {
+		names := make([]string, 0, len(report.StateKeys))
+		for name := range report.StateKeys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("DefaultState keys (%d):\n", len(names))
+		for _, name := range names {
+			key := report.StateKeys[name]
+			marker := "✓"
+			if !key.IsUsed {
+				marker = "✗"
+			}
+			fmt.Printf("  %s %s (%s:%d)\n", marker, name, filepath.Base(key.File), key.Line)
+		}
+		fmt.Println()
+	}
+
 	if len(report.PrunedFiles) > 0 {
 		fmt.Printf("Modified files (%d):\n", len(report.PrunedFiles))
 		for _, file := range report.PrunedFiles {
@@ -307,3 +335,112 @@ func printStateTree(tree *state.StateTree, depth int) {
 		}
 	}
 }
+
+// prunedStateFile is written beside each DefaultState literal by gospa build
+// --prune-state and compiled in with the prunedStateTag build tag.
+const (
+	prunedStateFile = "gospa_pruned_state.go"
+	prunedStateTag  = "gospa_prune_state"
+)
+
+// pruneStateForBuild checks which DefaultState keys under rootDir no
+// component references. In "fail" mode any such key fails the build. In
+// "warn" mode they are reported, and a generated file registers them with
+// state.RegisterPrunedKeys so the binary leaves them out of the initial
+// state.
+func pruneStateForBuild(config *BuildConfig, rootDir string) error {
+	if config.PruneState != "warn" && config.PruneState != "fail" {
+		return fmt.Errorf("invalid --prune-state mode %q (want warn or fail)", config.PruneState)
+	}
+
+	pruningConfig := state.DefaultPruningConfig()
+	pruningConfig.RootDir = rootDir
+	report, err := state.NewStatePruner(pruningConfig).Analyze()
+	if err != nil {
+		return fmt.Errorf("failed to analyze state: %w", err)
+	}
+	if len(report.StateKeys) == 0 {
+		fmt.Println("No DefaultState literal found; skipping state pruning")
+		return nil
+	}
+
+	// Group unused keys by the directory of their DefaultState literal.
+	unusedByDir := make(map[string][]string)
+	dirs := make(map[string]string) // dir -> a file declaring keys there
+	for _, key := range report.StateKeys {
+		dir := filepath.Dir(key.File)
+		dirs[dir] = key.File
+	}
+	for _, name := range report.UnusedStateKeys {
+		key := report.StateKeys[name]
+		fmt.Fprintf(os.Stderr, "Warning: DefaultState key %q (%s:%d) is not referenced by any component\n", name, key.File, key.Line)
+		dir := filepath.Dir(key.File)
+		unusedByDir[dir] = append(unusedByDir[dir], name)
+	}
+	if config.PruneState == "fail" && len(report.UnusedStateKeys) > 0 {
+		return fmt.Errorf("%d unused DefaultState key(s): %s", len(report.UnusedStateKeys), strings.Join(report.UnusedStateKeys, ", "))
+	}
+
+	for dir, file := range dirs {
+		out := filepath.Join(dir, prunedStateFile)
+		keys := unusedByDir[dir]
+		if len(keys) == 0 {
+			// A file left by an earlier build would drop keys now in use.
+			if err := os.Remove(out); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", out, err)
+			}
+			continue
+		}
+		pkg, err := packageName(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, []byte(prunedStateSource(pkg, keys)), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out, err)
+		}
+	}
+	if len(report.UnusedStateKeys) > 0 {
+		config.Tags = appendBuildTag(config.Tags, prunedStateTag)
+		fmt.Printf("Pruned %d DefaultState key(s) from the initial state\n", len(report.UnusedStateKeys))
+	}
+	return nil
+}
+
+// prunedStateSource returns the Go file registering keys as pruned.
+func prunedStateSource(pkg string, keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = strconv.Quote(k)
+	}
+	var sb strings.Builder
+	sb.WriteString("// Code generated by gospa build --prune-state. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "//go:build %s\n\n", prunedStateTag)
+	fmt.Fprintf(&sb, "package %s\n\n", pkg)
+	sb.WriteString("import \"github.com/aydenstechdungeon/gospa/state\"\n\n")
+	sb.WriteString("func init() {\n")
+	fmt.Fprintf(&sb, "\tstate.RegisterPrunedKeys(%s)\n", strings.Join(quoted, ", "))
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// packageName returns the package clause of a Go file.
+func packageName(file string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to read package of %s: %w", file, err)
+	}
+	return f.Name.Name, nil
+}
+
+// appendBuildTag adds tag to a comma-separated tag list.
+func appendBuildTag(tags, tag string) string {
+	for _, t := range strings.Split(tags, ",") {
+		if strings.TrimSpace(t) == tag {
+			return tags
+		}
+	}
+	if strings.TrimSpace(tags) == "" {
+		return tag
+	}
+	return tags + "," + tag
+}
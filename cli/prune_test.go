@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePruneProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go": `package main
+
+var cfg = Config{DefaultState: map[string]interface{}{"count": 0, "legacy": 1}}
+`,
+		"routes/page.templ": `<span data-bind="text:count"></span>`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestPruneStateForBuild_Warn(t *testing.T) {
+	root := writePruneProject(t)
+	cfg := &BuildConfig{PruneState: "warn", Tags: "netgo"}
+	if err := pruneStateForBuild(cfg, root); err != nil {
+		t.Fatalf("pruneStateForBuild: %v", err)
+	}
+	if cfg.Tags != "netgo,"+prunedStateTag {
+		t.Fatalf("Tags = %q", cfg.Tags)
+	}
+
+	out := filepath.Join(root, prunedStateFile)
+	//nolint:gosec // out is under t.TempDir().
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"//go:build " + prunedStateTag,
+		"package main",
+		`state.RegisterPrunedKeys("legacy")`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s missing %q:\n%s", prunedStateFile, want, content)
+		}
+	}
+
+	// Once the key is used, the next build removes the stale file.
+	if err := os.WriteFile(filepath.Join(root, "routes", "legacy.templ"), []byte(`{ templ.Text("legacy") }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg = &BuildConfig{PruneState: "warn"}
+	if err := pruneStateForBuild(cfg, root); err != nil {
+		t.Fatalf("pruneStateForBuild: %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, stat err = %v", out, err)
+	}
+	if cfg.Tags != "" {
+		t.Fatalf("Tags = %q, want none", cfg.Tags)
+	}
+}
+
+func TestPruneStateForBuild_Fail(t *testing.T) {
+	root := writePruneProject(t)
+	err := pruneStateForBuild(&BuildConfig{PruneState: "fail"}, root)
+	if err == nil || !strings.Contains(err.Error(), "legacy") {
+		t.Fatalf("expected an error naming legacy, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, prunedStateFile)); !os.IsNotExist(err) {
+		t.Fatal("fail mode should not write the pruned state file")
+	}
+}
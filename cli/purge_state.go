@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// PurgeStateConfig controls `gospa purge-state`, which asks a running server
+// to purge the state and sessions of clients not seen within OlderThan.
+type PurgeStateConfig struct {
+	URL        string        // Server base URL
+	OlderThan  time.Duration // Purge clients not seen for this long (0: the server's StateRetention)
+	Token      string        // Sent as a Bearer token for RemoteActionMiddleware
+	Timeout    time.Duration // Request timeout
+	JSONOutput bool          // Print the result as JSON instead of a report
+}
+
+// PurgeStateResult is the server's reply to a purge.
+type PurgeStateResult struct {
+	Scanned  int `json:"scanned"`
+	Purged   int `json:"purged"`
+	Sessions int `json:"sessions"`
+}
+
+// PurgeState runs `gospa purge-state` and exits with status 1 on failure.
+func PurgeState(config *PurgeStateConfig) {
+	printer := NewColorPrinter()
+	if config == nil {
+		config = &PurgeStateConfig{}
+	}
+	result, err := RunPurgeState(context.Background(), config)
+	if err != nil {
+		printer.Error("State purge failed: %v", err)
+		os.Exit(1)
+	}
+	if config.JSONOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	printer.Success("Purged %d of %d tracked clients (%d sessions revoked)", result.Purged, result.Scanned, result.Sessions)
+}
+
+// RunPurgeState posts a purge request to the server's /_gospa/state/purge
+// endpoint, which exists when the app sets Config.StateRetention.
+func RunPurgeState(ctx context.Context, config *PurgeStateConfig) (*PurgeStateResult, error) {
+	base := config.URL
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+	endpoint, err := url.JoinPath(base, "/_gospa/state/purge")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if config.OlderThan > 0 {
+		endpoint += "?olderThan=" + url.QueryEscape(config.OlderThan.String())
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The endpoint sits behind the CSRF middleware, which a non-browser
+	// client satisfies by sending the same token as cookie and header.
+	csrf := make([]byte, 32)
+	if _, err := rand.Read(csrf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(csrf)
+	req.AddCookie(&http.Cookie{Name: "csrf_token", Value: token})
+	req.Header.Set("X-CSRF-Token", token)
+	if config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+config.Token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		var envelope struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		if json.Unmarshal(body, &envelope) == nil && envelope.Error != "" {
+			return nil, fmt.Errorf("%s: %s (%s)", res.Status, envelope.Error, envelope.Code)
+		}
+		if res.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%s: is Config.StateRetention set?", res.Status)
+		}
+		return nil, fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	var result PurgeStateResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunPurgeState(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("csrf_token")
+		switch {
+		case r.URL.Path != "/_gospa/state/purge" || r.Method != http.MethodPost:
+			http.NotFound(w, r)
+		case err != nil || cookie.Value != r.Header.Get("X-CSRF-Token"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"no","code":"STATE_PURGE_AUTH_REQUIRED"}`))
+		case r.URL.Query().Get("olderThan") != "720h0m0s":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"scanned":3,"purged":2,"sessions":4}`))
+		}
+	}))
+	defer srv.Close()
+
+	result, err := RunPurgeState(context.Background(), &PurgeStateConfig{URL: srv.URL, OlderThan: 720 * time.Hour, Token: "secret"})
+	if err != nil {
+		t.Fatalf("RunPurgeState: %v", err)
+	}
+	if *result != (PurgeStateResult{Scanned: 3, Purged: 2, Sessions: 4}) {
+		t.Fatalf("result = %+v", result)
+	}
+
+	_, err = RunPurgeState(context.Background(), &PurgeStateConfig{URL: srv.URL, OlderThan: 720 * time.Hour})
+	if err == nil || !strings.Contains(err.Error(), "STATE_PURGE_AUTH_REQUIRED") {
+		t.Fatalf("unauthenticated purge error = %v", err)
+	}
+}
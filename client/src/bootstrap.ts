@@ -0,0 +1,47 @@
+// GoSPA Bootstrap - inlined into pages when Config.InlineRuntimeBootstrap is set
+// Keeps the runtime off the critical path: the page paints from server-rendered
+// HTML with __GOSPA_STATE__ already attached, and the runtime (and with it the
+// WebSocket connection) loads on the first interaction or once the browser is
+// idle after load. Written as a classic script so it can be inlined verbatim.
+
+interface BootConfig {
+  runtime: string;
+  init: Record<string, unknown>;
+  modules?: string[];
+  timeout?: number;
+}
+
+(() => {
+  const w = window as Window & {
+    __GOSPA_BOOT__?: BootConfig;
+    __GOSPA_RUNTIME_ESM__?: unknown;
+  };
+  const boot = w.__GOSPA_BOOT__;
+  if (!boot) return;
+
+  const events = ["pointerdown", "keydown", "touchstart", "focusin"];
+  let started = false;
+  const start = (): void => {
+    if (started) return;
+    started = true;
+    for (const e of events) removeEventListener(e, start, true);
+    import(boot.runtime).then((runtime) => {
+      w.__GOSPA_RUNTIME_ESM__ = runtime;
+      runtime.init(boot.init);
+      for (const m of boot.modules || []) import(m);
+    });
+  };
+  for (const e of events) {
+    addEventListener(e, start, { capture: true, passive: true });
+  }
+
+  const idle = (): void => {
+    if ("requestIdleCallback" in w) {
+      requestIdleCallback(start, { timeout: boot.timeout || 3000 });
+    } else {
+      setTimeout(start, 1);
+    }
+  };
+  if (document.readyState === "complete") idle();
+  else addEventListener("load", idle, { once: true });
+})();
@@ -0,0 +1,282 @@
+// Client replicas for server-shared CRDT keys (state.NewCRDTMap / state.NewCRDTList).
+// Ops are idempotent and commutative, so local edits apply immediately and the
+// server echo is a no-op.
+
+import { Rune } from "./state.ts";
+import { getWebSocketClient, type WSClient } from "./websocket.ts";
+
+export interface OpID {
+  c: number;
+  r: string;
+}
+
+export interface CRDTOp {
+  op: "set" | "ins" | "del";
+  id: OpID;
+  key?: string;
+  ref?: OpID;
+  value?: unknown;
+}
+
+const HEAD = "0:";
+
+function idKey(id: OpID | undefined): string {
+  return id ? `${id.c}:${id.r}` : HEAD;
+}
+
+function idLess(a: OpID, b: OpID): boolean {
+  return a.c !== b.c ? a.c < b.c : a.r < b.r;
+}
+
+function randomReplica(): string {
+  const bytes = new Uint8Array(8);
+  crypto.getRandomValues(bytes);
+  return Array.from(bytes, (b) => b.toString(16).padStart(2, "0")).join("");
+}
+
+abstract class Replica {
+  protected readonly replica = randomReplica();
+  protected clock = 0;
+
+  protected nextID(): OpID {
+    return { c: ++this.clock, r: this.replica };
+  }
+
+  protected observe(id: OpID): void {
+    if (id && id.c > this.clock) this.clock = id.c;
+  }
+
+  apply(ops: CRDTOp[]): boolean {
+    let changed = false;
+    for (const op of ops) {
+      this.observe(op.id);
+      if (this.applyOp(op)) changed = true;
+    }
+    return changed;
+  }
+
+  protected abstract applyOp(op: CRDTOp): boolean;
+}
+
+interface MapEntry {
+  id: OpID;
+  value: unknown;
+  deleted: boolean;
+}
+
+/** Last-writer-wins map replica. */
+export class CRDTMapReplica extends Replica {
+  private entries = new Map<string, MapEntry>();
+
+  set(key: string, value: unknown): CRDTOp[] {
+    const op: CRDTOp = { op: "set", id: this.nextID(), key, value };
+    this.applyOp(op);
+    return [op];
+  }
+
+  delete(key: string): CRDTOp[] {
+    const entry = this.entries.get(key);
+    if (!entry || entry.deleted) return [];
+    const op: CRDTOp = { op: "del", id: this.nextID(), key };
+    this.applyOp(op);
+    return [op];
+  }
+
+  value(): Record<string, unknown> {
+    const out: Record<string, unknown> = {};
+    for (const [key, entry] of this.entries) {
+      if (!entry.deleted) out[key] = entry.value;
+    }
+    return out;
+  }
+
+  protected applyOp(op: CRDTOp): boolean {
+    if ((op.op !== "set" && op.op !== "del") || op.key === undefined) {
+      return false;
+    }
+    const current = this.entries.get(op.key);
+    if (current && !idLess(current.id, op.id)) return false;
+    this.entries.set(op.key, {
+      id: op.id,
+      value: op.value,
+      deleted: op.op === "del",
+    });
+    return true;
+  }
+}
+
+interface ListElem {
+  id: OpID;
+  value: unknown;
+  deleted: boolean;
+}
+
+/** Replicated growable array (RGA) replica. */
+export class CRDTListReplica extends Replica {
+  private elems = new Map<string, ListElem>();
+  private children = new Map<string, OpID[]>();
+  private pending: CRDTOp[] = [];
+  private pendingDel = new Set<string>();
+  private order: string[] = [];
+
+  insert(index: number, value: unknown): CRDTOp[] {
+    const i = Math.max(0, Math.min(index, this.order.length));
+    const refKey = i > 0 ? this.order[i - 1] : HEAD;
+    const ref =
+      refKey === HEAD ? { c: 0, r: "" } : this.elems.get(refKey)!.id;
+    const op: CRDTOp = { op: "ins", id: this.nextID(), ref, value };
+    this.apply([op]);
+    return [op];
+  }
+
+  push(value: unknown): CRDTOp[] {
+    return this.insert(this.order.length, value);
+  }
+
+  delete(index: number): CRDTOp[] {
+    if (index < 0 || index >= this.order.length) return [];
+    const op: CRDTOp = {
+      op: "del",
+      id: this.nextID(),
+      ref: this.elems.get(this.order[index])!.id,
+    };
+    this.apply([op]);
+    return [op];
+  }
+
+  value(): unknown[] {
+    return this.order.map((key) => this.elems.get(key)!.value);
+  }
+
+  override apply(ops: CRDTOp[]): boolean {
+    const changed = super.apply(ops);
+    if (changed) this.reorder();
+    return changed;
+  }
+
+  protected applyOp(op: CRDTOp): boolean {
+    if (!op.ref) return false;
+    if (op.op === "del") {
+      const elem = this.elems.get(idKey(op.ref));
+      if (!elem) {
+        this.pendingDel.add(idKey(op.ref));
+        return false;
+      }
+      if (elem.deleted) return false;
+      elem.deleted = true;
+      return true;
+    }
+    if (op.op !== "ins" || !this.place(op)) return false;
+    for (let progress = true; progress && this.pending.length > 0; ) {
+      progress = false;
+      this.pending = this.pending.filter((p) => {
+        if (!this.elems.has(idKey(p.ref))) return true;
+        this.place(p);
+        progress = true;
+        return false;
+      });
+    }
+    return true;
+  }
+
+  private place(op: CRDTOp): boolean {
+    const key = idKey(op.id);
+    if (this.elems.has(key)) return false;
+    const refKey = op.ref && op.ref.c !== 0 ? idKey(op.ref) : HEAD;
+    if (refKey !== HEAD && !this.elems.has(refKey)) {
+      this.pending.push(op);
+      return false;
+    }
+    this.elems.set(key, {
+      id: op.id,
+      value: op.value,
+      deleted: this.pendingDel.delete(key),
+    });
+    // Siblings are kept newest-first, matching the server ordering.
+    const siblings = this.children.get(refKey) ?? [];
+    let i = 0;
+    while (i < siblings.length && !idLess(siblings[i], op.id)) i++;
+    siblings.splice(i, 0, op.id);
+    this.children.set(refKey, siblings);
+    return true;
+  }
+
+  private reorder(): void {
+    const order: string[] = [];
+    const stack: string[] = [];
+    const push = (parent: string) => {
+      const kids = this.children.get(parent) ?? [];
+      for (let i = kids.length - 1; i >= 0; i--) stack.push(idKey(kids[i]));
+    };
+    push(HEAD);
+    while (stack.length > 0) {
+      const key = stack.pop()!;
+      if (!this.elems.get(key)!.deleted) order.push(key);
+      push(key);
+    }
+    this.order = order;
+  }
+}
+
+export interface SharedCRDT<R extends Replica, V> {
+  readonly rune: Rune<V>;
+  readonly replica: R;
+  /** Stop receiving ops for this key. */
+  dispose(): void;
+}
+
+function share<R extends Replica, V>(
+  key: string,
+  replica: R,
+  read: () => V,
+  ws: WSClient | null,
+): SharedCRDT<R, V> & { commit(ops: CRDTOp[]): void } {
+  const rune = new Rune<V>(read());
+  const unsubscribe = ws?.subscribeCRDT(key, (message) => {
+    if (replica.apply((message.ops ?? []) as CRDTOp[])) rune.set(read());
+  });
+  return {
+    rune,
+    replica,
+    commit(ops: CRDTOp[]) {
+      if (ops.length === 0) return;
+      rune.set(read());
+      ws?.send({ type: "crdt", payload: { key, ops } });
+    },
+    dispose() {
+      unsubscribe?.();
+    },
+  };
+}
+
+/** Bind to a server map shared with app.ShareCRDT(key, state.NewCRDTMap()). */
+export function crdtMap(
+  key: string,
+  ws: WSClient | null = getWebSocketClient(),
+) {
+  const replica = new CRDTMapReplica();
+  const shared = share(key, replica, () => replica.value(), ws);
+  return {
+    rune: shared.rune,
+    dispose: shared.dispose,
+    set: (k: string, value: unknown) => shared.commit(replica.set(k, value)),
+    delete: (k: string) => shared.commit(replica.delete(k)),
+  };
+}
+
+/** Bind to a server list shared with app.ShareCRDT(key, state.NewCRDTList()). */
+export function crdtList(
+  key: string,
+  ws: WSClient | null = getWebSocketClient(),
+) {
+  const replica = new CRDTListReplica();
+  const shared = share(key, replica, () => replica.value(), ws);
+  return {
+    rune: shared.rune,
+    dispose: shared.dispose,
+    insert: (index: number, value: unknown) =>
+      shared.commit(replica.insert(index, value)),
+    push: (value: unknown) => shared.commit(replica.push(value)),
+    delete: (index: number) => shared.commit(replica.delete(index)),
+  };
+}
@@ -85,7 +85,12 @@ function clearFieldErrors(form: HTMLFormElement): void {
     });
 }
 
-function applyFieldErrors(
+/**
+ * Mark the fields of form named in validation.fieldErrors with aria-invalid
+ * and a data-gospa-error message, clearing earlier marks. Works with the
+ * validation of enhanced form actions and of remote() results.
+ */
+export function applyFieldErrors(
   form: HTMLFormElement,
   validation: ActionValidationError,
 ): void {
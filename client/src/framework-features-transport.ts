@@ -1,2 +1,4 @@
 export * from "./transport.ts";
 export * from "./websocket.ts";
+export * from "./crdt.ts";
+export * from "./list.ts";
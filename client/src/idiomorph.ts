@@ -354,10 +354,17 @@ export const Idiomorph = (function () {
     return (
       oldNode.nodeType === newNode.nodeType &&
       oldNode.tagName === newNode.tagName &&
-      (!oldNode.id || oldNode.id === newNode.id)
+      (!oldNode.id || oldNode.id === newNode.id) &&
+      keyOf(oldNode) === keyOf(newNode)
     );
   }
 
+  // Keyed list items (templ.Keyed) only match the item with the same key, so
+  // reordered lists move nodes instead of morphing one item into another.
+  function keyOf(node: any): string | null {
+    return node.nodeType === 1 ? node.getAttribute("data-key") : null;
+  }
+
   function isIdSetMatch(ctx: any, oldNode: any, newNode: any) {
     const oldSet = ctx.idMap.get(oldNode);
     const newSet = ctx.idMap.get(newNode);
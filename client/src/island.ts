@@ -15,7 +15,8 @@ export type IslandHydrationMode =
   | "visible"
   | "idle"
   | "interaction"
-  | "lazy";
+  | "lazy"
+  | "never";
 
 // Island priority levels
 export type IslandPriority =
@@ -217,9 +218,13 @@ export class IslandManager {
     const name = element.getAttribute("data-gospa-island");
     if (!name) return null;
 
-    const mode =
-      (element.getAttribute("data-gospa-mode") as IslandHydrationMode) ||
-      "immediate";
+    // An island without its own mode follows the enclosing templ.Hydrate
+    // boundary, if any.
+    const mode = (element.getAttribute("data-gospa-mode") ||
+      element
+        .closest("[data-gospa-hydrate]")
+        ?.getAttribute("data-gospa-hydrate") ||
+      "immediate") as IslandHydrationMode;
     const priority =
       (element.getAttribute("data-gospa-priority") as IslandPriority) ||
       "normal";
@@ -318,6 +323,10 @@ export class IslandManager {
         case "lazy":
           // Lazy islands are hydrated on demand
           break;
+
+        case "never":
+          // Server-rendered HTML only
+          break;
       }
     }
 
@@ -0,0 +1,171 @@
+// Client side of server list sources (app.RegisterListSource). Items load a
+// page at a time and later changes arrive as per-item events, so a large
+// collection never travels as one state value.
+
+import { Rune } from "./state.ts";
+import {
+  getWebSocketClient,
+  type StateMessage,
+  type WSClient,
+} from "./websocket.ts";
+
+// Mirrors fiber.maxListPageSize.
+const MAX_PAGE_SIZE = 500;
+
+export interface ListItem<T = unknown> {
+  id: string;
+  data?: T;
+}
+
+export interface ListEvent<T = unknown> {
+  op: "upsert" | "remove" | "reset";
+  item?: ListItem<T>;
+  before?: string;
+}
+
+interface ListPageData<T> {
+  items?: ListItem<T>[];
+  next?: string;
+  total?: number;
+}
+
+export interface LiveListOptions {
+  /** Items per page (default 50, at most 500). */
+  pageSize?: number;
+}
+
+export interface LiveList<T> {
+  /** The loaded items, in order. */
+  readonly rune: Rune<ListItem<T>[]>;
+  /** The size of the whole list, when the source reports it. */
+  readonly total: Rune<number>;
+  /** Whether more pages can be loaded. */
+  hasMore(): boolean;
+  /** Load the next page. Resolves to false when there was none. */
+  loadMore(): Promise<boolean>;
+  /** Load the list again, as many items as are loaded now. */
+  reload(): Promise<void>;
+  /** Stop receiving events for the list. */
+  dispose(): void;
+}
+
+/**
+ * Bind to a list served with app.RegisterListSource(name, src). The first
+ * page loads right away; call loadMore, e.g. when a table scrolls to its
+ * end, for the following ones. Events published with PublishListEvents
+ * update the loaded items in place.
+ */
+export function liveList<T = unknown>(
+  name: string,
+  options: LiveListOptions = {},
+  ws: WSClient | null = getWebSocketClient(),
+): LiveList<T> {
+  const pageSize = Math.min(Math.max(1, options.pageSize ?? 50), MAX_PAGE_SIZE);
+  const rune = new Rune<ListItem<T>[]>([]);
+  const total = new Rune<number>(0);
+  let items: ListItem<T>[] = [];
+  let cursor = "";
+  let done = false;
+  let disposed = false;
+  let loading: Promise<boolean> | null = null;
+  // Bumped by reload, so pages requested before it are dropped.
+  let generation = 0;
+
+  const load = (limit: number): Promise<boolean> => {
+    if (!ws || done || disposed) return Promise.resolve(false);
+    if (loading) return loading;
+    const gen = generation;
+    const request = ws
+      .fetchList(name, cursor, limit)
+      .then((data) => {
+        if (gen !== generation || disposed) return false;
+        const page = (data ?? {}) as ListPageData<T>;
+        const index = new Map(items.map((item, i) => [item.id, i]));
+        for (const item of page.items ?? []) {
+          const i = index.get(item.id);
+          if (i !== undefined) {
+            items[i] = item;
+          } else {
+            index.set(item.id, items.length);
+            items.push(item);
+          }
+        }
+        cursor = page.next ?? "";
+        done = cursor === "";
+        total.set(page.total ?? 0);
+        rune.set([...items]);
+        return true;
+      })
+      .finally(() => {
+        if (loading === request) loading = null;
+      });
+    loading = request;
+    return request;
+  };
+
+  const reload = async (): Promise<void> => {
+    const limit = Math.min(Math.max(items.length, pageSize), MAX_PAGE_SIZE);
+    generation++;
+    loading = null;
+    items = [];
+    cursor = "";
+    done = false;
+    await load(limit);
+  };
+
+  const apply = (event: ListEvent<T>): void => {
+    const item = event.item;
+    switch (event.op) {
+      case "reset":
+        void reload().catch(() => {});
+        return;
+      case "remove":
+        if (!item) return;
+        items = items.filter((existing) => existing.id !== item.id);
+        break;
+      case "upsert": {
+        if (!item) return;
+        const i = items.findIndex((existing) => existing.id === item.id);
+        const before = event.before
+          ? items.findIndex((existing) => existing.id === event.before)
+          : -1;
+        if (i >= 0) {
+          items[i] = item;
+        } else if (before >= 0) {
+          items.splice(before, 0, item);
+        } else if (done) {
+          items.push(item);
+        } else {
+          // Arrives with a later page.
+          return;
+        }
+        break;
+      }
+      default:
+        return;
+    }
+    rune.set([...items]);
+  };
+
+  const unsubscribe = ws?.subscribeList(name, (message: StateMessage) => {
+    const data = message.data as { events?: ListEvent<T>[] } | undefined;
+    if (Array.isArray(data?.events)) data.events.forEach(apply);
+  });
+
+  void load(pageSize).catch((error) => {
+    console.warn(`[GoSPA] Failed to load list ${name}:`, error);
+  });
+
+  return {
+    rune,
+    total,
+    hasMore: () => !done,
+    loadMore: () => load(pageSize),
+    reload,
+    dispose() {
+      if (disposed) return;
+      disposed = true;
+      unsubscribe?.();
+    },
+  };
+}
@@ -5,7 +5,7 @@ declare global {
 import { reactive } from "./signals.ts";
 import { Idiomorph } from "./idiomorph.ts";
 import { toHTMLString } from "./html-policy.ts";
-import { getSetup } from "./runtime-core.ts";
+import { getSetup, mountClientOnly } from "./runtime-core.ts";
 import { emitRuntimeSignal } from "./runtime-signals.ts";
 
 function getCSPNonce(): string | undefined {
@@ -708,6 +708,7 @@ function indexPrefetch(path: string, data: PageData): void {
 async function fetchPageFromServer(
   path: string,
   signal?: AbortSignal,
+  prefetch = false,
 ): Promise<PageData | null> {
   const existing = pendingRequests.get(path);
   if (existing) {
@@ -721,6 +722,8 @@ async function fetchPageFromServer(
         headers: {
           "X-Requested-With": "GoSPA-Navigate",
           Accept: "text/html",
+          // Lets the server tell speculative fetches from page views.
+          ...(prefetch ? { Purpose: "prefetch" } : {}),
         },
       });
 
@@ -908,7 +911,7 @@ async function reconcileDOM(data: PageData): Promise<void> {
   if (morphTarget && newContent) {
     Idiomorph.morph(morphTarget, newContent, {
       callbacks: {
-        beforeNodeMorphed: (oldNode, _newNode) => {
+        beforeNodeMorphed: (oldNode, newNode) => {
           // Compatibility with data-gospa-permanent
           if (
             oldNode instanceof Element &&
@@ -916,6 +919,15 @@ async function reconcileDOM(data: PageData): Promise<void> {
           ) {
             return false;
           }
+          // Keep server-only content the navigation response left out
+          if (
+            oldNode instanceof Element &&
+            newNode instanceof Element &&
+            oldNode.hasAttribute("data-gospa-server-only") &&
+            newNode.getAttribute("data-gospa-server-only") === "omitted"
+          ) {
+            return false;
+          }
           // Skip child diffing for inner-morph nodes
           if (
             oldNode instanceof Element &&
@@ -1301,6 +1313,7 @@ async function initNewContent(
 ): Promise<void> {
   // First, re-execute any scripts found in the new content
   executeScripts(container);
+  mountClientOnly(container);
 
   await initCriticalContent(container);
   // Re-run island setup for newly swapped content so SPA navigation mirrors
@@ -1984,7 +1997,7 @@ export async function prefetch(path: string): Promise<void> {
   if (existing && existing.expiresAt > Date.now()) return;
   if (existing) deletePrefetchByPath(path);
 
-  const data = await fetchPageFromServer(path);
+  const data = await fetchPageFromServer(path, undefined, true);
   if (data) {
     const ttl = Math.max(
       1000,
@@ -2150,6 +2163,7 @@ declare global {
     __GOSPA_CONFIG__?: {
       navigationOptions?: NavigationOptions;
       csrfToken?: string;
+      requestId?: string;
     };
   }
 }
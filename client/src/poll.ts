@@ -0,0 +1,198 @@
+// HTTP long-polling transport speaking the WebSocket protocol, for networks
+// that block WebSocket. The server side is fiber.PollHandler at /_gospa/poll.
+
+/** Header carrying the connection token, matching fiber.PollHeader. */
+export const POLL_HEADER = "X-GoSPA-Poll";
+
+// WebSocket readyState values, usable where WebSocket is not defined.
+export const SOCKET_CONNECTING = 0;
+export const SOCKET_OPEN = 1;
+export const SOCKET_CLOSING = 2;
+export const SOCKET_CLOSED = 3;
+
+/** The part of the WebSocket interface WSClient uses. */
+export interface SocketLike {
+  readonly readyState: number;
+  binaryType: BinaryType;
+  onopen: ((event: Event) => void) | null;
+  onclose: ((event: CloseEvent) => void) | null;
+  onerror: ((event: Event) => void) | null;
+  onmessage: ((event: MessageEvent) => void) | null;
+  send(data: string | ArrayBufferLike | Blob | ArrayBufferView): void;
+  close(code?: number, reason?: string): void;
+}
+
+function getCookie(name: string): string | undefined {
+  if (typeof document === "undefined") return undefined;
+  const cookie = document.cookie
+    .split("; ")
+    .find((row) => row.startsWith(`${name}=`));
+
+  return cookie
+    ? decodeURIComponent(cookie.split("=").slice(1).join("="))
+    : undefined;
+}
+
+function getCSRFToken(): string | undefined {
+  const configToken =
+    typeof window !== "undefined"
+      ? (window as any).__GOSPA_CONFIG__?.csrfToken
+      : undefined;
+  return typeof configToken === "string" && configToken
+    ? configToken
+    : getCookie("csrf_token");
+}
+
+/**
+ * PollSocket behaves like a WebSocket over plain HTTP requests: a POST opens
+ * the connection, each send is a POST, and a GET loop long-polls for the
+ * server's messages, which reach onmessage as parsed objects. Messages are
+ * always JSON.
+ */
+export class PollSocket implements SocketLike {
+  readyState = SOCKET_CONNECTING;
+  binaryType: BinaryType = "blob";
+  onopen: ((event: Event) => void) | null = null;
+  onclose: ((event: CloseEvent) => void) | null = null;
+  onerror: ((event: Event) => void) | null = null;
+  onmessage: ((event: MessageEvent) => void) | null = null;
+
+  private token = "";
+  private queue: string[] = [];
+  private sending = false;
+  private polling = false;
+  private aborter: AbortController | null = null;
+
+  constructor(private readonly url: string) {
+    void this.open();
+  }
+
+  send(data: string | ArrayBufferLike | Blob | ArrayBufferView): void {
+    if (this.readyState !== SOCKET_OPEN) return;
+    this.queue.push(typeof data === "string" ? data : String(data));
+    void this.flush();
+  }
+
+  close(code = 1000, reason = ""): void {
+    if (
+      this.readyState === SOCKET_CLOSING ||
+      this.readyState === SOCKET_CLOSED
+    )
+      return;
+    if (this.token) {
+      void fetch(this.url, {
+        method: "DELETE",
+        credentials: "same-origin",
+        headers: this.headers(),
+        keepalive: true,
+      }).catch(() => {});
+    }
+    this.finish(code, reason, true);
+  }
+
+  private headers(extra: Record<string, string> = {}): Record<string, string> {
+    const headers: Record<string, string> = { ...extra };
+    if (this.token) headers[POLL_HEADER] = this.token;
+    const csrfToken = getCSRFToken();
+    if (csrfToken) headers["X-CSRF-Token"] = csrfToken;
+    return headers;
+  }
+
+  private async open(): Promise<void> {
+    try {
+      const res = await fetch(this.url, {
+        method: "POST",
+        credentials: "same-origin",
+        headers: this.headers(),
+      });
+      if (!res.ok) throw new Error(`poll open failed: ${res.status}`);
+      const { token } = (await res.json()) as { token?: unknown };
+      if (typeof token !== "string" || !token) {
+        throw new Error("poll open failed: no token");
+      }
+      // close() was called while opening.
+      if (this.readyState !== SOCKET_CONNECTING) return;
+      this.token = token;
+      this.readyState = SOCKET_OPEN;
+      this.onopen?.(new Event("open"));
+    } catch (error) {
+      this.fail(error);
+    }
+  }
+
+  // flush posts queued messages one at a time, so the server sees them in
+  // order, and starts polling once the first one, the init, is through.
+  private async flush(): Promise<void> {
+    if (this.sending) return;
+    this.sending = true;
+    try {
+      while (this.queue.length > 0 && this.readyState === SOCKET_OPEN) {
+        const body = this.queue.shift()!;
+        const res = await fetch(this.url, {
+          method: "POST",
+          credentials: "same-origin",
+          headers: this.headers({ "Content-Type": "application/json" }),
+          body,
+        });
+        if (res.status === 410) {
+          this.finish(1006, "poll connection closed", false);
+          return;
+        }
+        if (!this.polling) {
+          this.polling = true;
+          void this.poll();
+        }
+      }
+    } catch (error) {
+      this.fail(error);
+    } finally {
+      this.sending = false;
+    }
+  }
+
+  private async poll(): Promise<void> {
+    while (this.readyState === SOCKET_OPEN) {
+      this.aborter = new AbortController();
+      let frames: unknown;
+      try {
+        const res = await fetch(this.url, {
+          credentials: "same-origin",
+          headers: this.headers({ Accept: "application/json" }),
+          signal: this.aborter.signal,
+        });
+        if (res.status === 410) {
+          this.finish(1006, "poll connection closed", false);
+          return;
+        }
+        if (!res.ok) throw new Error(`poll failed: ${res.status}`);
+        frames = await res.json();
+      } catch (error) {
+        this.fail(error);
+        return;
+      }
+      if (!Array.isArray(frames)) continue;
+      for (const data of frames) {
+        if (this.readyState !== SOCKET_OPEN) return;
+        this.onmessage?.({ data } as MessageEvent);
+      }
+    }
+  }
+
+  private fail(error: unknown): void {
+    if (this.readyState === SOCKET_CLOSED) return;
+    this.onerror?.(new Event("error"));
+    this.finish(
+      1006,
+      error instanceof Error ? error.message : String(error),
+      false,
+    );
+  }
+
+  private finish(code: number, reason: string, wasClean: boolean): void {
+    if (this.readyState === SOCKET_CLOSED) return;
+    this.readyState = SOCKET_CLOSED;
+    this.queue = [];
+    this.aborter?.abort();
+    this.onclose?.({ code, reason, wasClean } as CloseEvent);
+  }
+}
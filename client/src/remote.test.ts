@@ -117,6 +117,29 @@ describe("remote", () => {
     expect(result.status).toBe(400);
     expect(result.error).toBe("Bad Request");
     expect(result.code).toBe("BAD_REQUEST");
+    expect(result.validation).toBeUndefined();
+  });
+
+  it("should expose field errors of a validation failure", async () => {
+    fetchMock.mockImplementation(() =>
+      Promise.resolve({
+        ok: false,
+        status: 422,
+        headers: new Map([["content-type", "application/json"]]),
+        json: () =>
+          Promise.resolve({
+            error: "Validation failed",
+            code: "VALIDATION_FAILED",
+            validation: { fieldErrors: { email: "is required" } },
+          }),
+      } as unknown as Response),
+    );
+
+    const result = await remote("signup", {});
+
+    expect(result.ok).toBe(false);
+    expect(result.code).toBe("VALIDATION_FAILED");
+    expect(result.validation?.fieldErrors?.email).toBe("is required");
   });
 
   it("should handle network error", async () => {
@@ -246,6 +269,30 @@ describe("remote", () => {
     expect(result.ok).toBe(true);
     expect(result.status).toBe(204);
   });
+
+  it("should return file results as a Blob", async () => {
+    const file = new Blob(["id,name\n"], { type: "text/csv" });
+    fetchMock.mockImplementation(() =>
+      Promise.resolve({
+        ok: true,
+        status: 200,
+        headers: new Map([
+          ["content-type", "text/csv"],
+          [
+            "content-disposition",
+            "attachment; filename*=utf-8''users%20%C3%A9.csv",
+          ],
+        ]),
+        blob: () => Promise.resolve(file),
+      } as unknown as Response),
+    );
+
+    const result = await remote("exportUsers", null);
+
+    expect(result.ok).toBe(true);
+    expect(result.data).toBe(file);
+    expect(result.filename).toBe("users é.csv");
+  });
 });
 
 describe("remoteAction", () => {
@@ -1,6 +1,9 @@
 // GoSPA Remote Actions Client
 // Type-safe HTTP client for calling server-side remote actions
 
+import type { ActionValidationError } from "./forms.ts";
+import { getTransportFeaturesSync } from "./runtime-core.ts";
+
 /**
  * Configuration options for remote action calls
  */
@@ -17,12 +20,22 @@ export interface RemoteOptions {
  * Result from a remote action call
  */
 export interface RemoteResult<T = unknown> {
-  /** The response data on success */
+  /**
+   * The response data on success. For actions returning a
+   * routing.FileResult, this is the file's Blob.
+   */
   data?: T;
+  /** The file name of a routing.FileResult download */
+  filename?: string;
   /** Error message if the call failed */
   error?: string;
   /** Error code for programmatic handling */
   code?: string;
+  /**
+   * Field errors when code is VALIDATION_FAILED. Pass to applyFieldErrors
+   * to mark the matching form fields.
+   */
+  validation?: ActionValidationError;
   /** HTTP status code */
   status: number;
   /** Whether the request was successful */
@@ -63,6 +76,21 @@ export function configureRemote(options: { prefix?: string }): void {
   }
 }
 
+// newRequestId returns the X-Request-Id of a call, which names it in the
+// "cancel" frame that stops it on the server.
+function newRequestId(): string {
+  if (typeof crypto !== "undefined" && "randomUUID" in crypto) {
+    return crypto.randomUUID();
+  }
+  return `r${Date.now().toString(36)}${Math.random().toString(36).slice(2)}`;
+}
+
+// cancelOnServer cancels the action's context over the WebSocket, when the
+// runtime has one open.
+function cancelOnServer(requestId: string): void {
+  getTransportFeaturesSync()?.getWebSocketClient()?.cancelRequest(requestId);
+}
+
 /**
  * Get the current remote prefix
  */
@@ -70,6 +98,27 @@ export function getRemotePrefix(): string {
   return remotePrefix;
 }
 
+// attachmentFilename returns the file name of a Content-Disposition:
+// attachment header, "" for an unnamed attachment, or undefined when the
+// response is not a download.
+function attachmentFilename(header: string | null): string | undefined {
+  if (!header || !/^\s*attachment\b/i.test(header)) return undefined;
+  const encoded = /filename\*\s*=\s*(?:[\w-]+)?'[^']*'([^;]+)/i.exec(header);
+  if (encoded) {
+    try {
+      return decodeURIComponent(encoded[1].trim());
+    } catch {
+      // Fall through to the plain filename parameter.
+    }
+  }
+  const plain =
+    /filename\s*=\s*(?:"((?:[^"\\]|\\.)*)"|([^;]+))/i.exec(header);
+  if (!plain) return "";
+  return plain[1] !== undefined
+    ? plain[1].replace(/\\(.)/g, "$1")
+    : plain[2].trim();
+}
+
 /**
  * Call a remote action on the server via HTTP POST
  *
@@ -121,17 +170,27 @@ export async function remote<T = unknown>(
   }
 
   const controller = new AbortController();
+  const hasRequestId = Object.keys(options.headers ?? {}).some(
+    (key) => key.toLowerCase() === "x-request-id",
+  );
+  const requestId = hasRequestId ? undefined : newRequestId();
+  let responded = false;
+  // Stop the action on the server too when the caller gives up on it.
+  const abort = () => {
+    controller.abort();
+    if (requestId && !responded) cancelOnServer(requestId);
+  };
 
   let abortListener: (() => void) | undefined;
   if (externalSignal) {
-    abortListener = () => controller.abort();
+    abortListener = abort;
     externalSignal.addEventListener("abort", abortListener);
   }
 
   let timeoutId: ReturnType<typeof setTimeout> | undefined;
   const timeoutPromise = new Promise<never>((_, reject) => {
     timeoutId = setTimeout(() => {
-      controller.abort();
+      abort();
       reject(new Error("__GOSPA_TIMEOUT__"));
     }, timeout);
   });
@@ -145,6 +204,7 @@ export async function remote<T = unknown>(
           "Content-Type": "application/json",
           Accept: "application/json",
           ...(csrfToken ? { "X-CSRF-Token": csrfToken } : {}),
+          ...(requestId ? { "X-Request-Id": requestId } : {}),
           ...options.headers,
         },
         body: input !== undefined ? JSON.stringify(input) : undefined,
@@ -154,20 +214,34 @@ export async function remote<T = unknown>(
       timeoutPromise,
     ]);
 
+    responded = true;
     if (timeoutId !== undefined) clearTimeout(timeoutId);
 
     // Parse response body
     let data: T | undefined;
     let error: string | undefined;
     let code: string | undefined;
+    let validation: ActionValidationError | undefined;
+    let filename: string | undefined;
 
     const contentType = response.headers.get("content-type");
-    if (contentType?.includes("application/json")) {
+    const attachment = response.ok
+      ? attachmentFilename(response.headers.get("content-disposition"))
+      : undefined;
+    if (attachment !== undefined) {
+      // A routing.FileResult: hand the file over as a Blob.
+      data = (await response.blob()) as T;
+      filename = attachment;
+      code = "SUCCESS";
+    } else if (contentType?.includes("application/json")) {
       try {
         const json = await response.json();
         code = json.code;
         if (!response.ok) {
           error = json.error || `HTTP ${response.status}`;
+          if (json.validation && typeof json.validation === "object") {
+            validation = json.validation;
+          }
         } else {
           // Handle wrapped response format: { data: ..., code: "SUCCESS" }
           data = json.data !== undefined ? json.data : (json as T);
@@ -186,8 +260,10 @@ export async function remote<T = unknown>(
 
     return {
       data,
+      filename,
       error,
       code,
+      validation,
       status: response.status,
       ok: response.ok,
     };
@@ -257,11 +333,46 @@ export function remoteAction<TInput = unknown, TOutput = unknown>(
   };
 }
 
+/**
+ * Call a remote action returning a routing.FileResult and save the file,
+ * e.g. for a CSV export button. Resolves to the call's result either way.
+ *
+ * @example
+ * ```typescript
+ * const result = await downloadRemote('exportUsers', { format: 'csv' });
+ * if (!result.ok) console.error(result.error);
+ * ```
+ */
+export async function downloadRemote(
+  name: string,
+  input?: unknown,
+  options?: RemoteOptions,
+): Promise<RemoteResult<Blob>> {
+  const result = await remote<Blob>(name, input, options);
+  if (
+    result.ok &&
+    result.data instanceof Blob &&
+    typeof document !== "undefined"
+  ) {
+    const url = URL.createObjectURL(result.data);
+    const link = document.createElement("a");
+    link.href = url;
+    link.download = result.filename || name;
+    link.style.display = "none";
+    document.body.appendChild(link);
+    link.click();
+    link.remove();
+    setTimeout(() => URL.revokeObjectURL(url), 0);
+  }
+  return result;
+}
+
 // Expose to window for debugging
 if (typeof window !== "undefined") {
   (window as any).__GOSPA_REMOTE__ = {
     remote,
     remoteAction,
+    downloadRemote,
     configureRemote,
     getRemotePrefix,
   };
@@ -80,6 +80,8 @@ export interface RuntimeConfig {
   wsMaxReconnect?: number;
   /** WebSocket heartbeat interval (ms) */
   wsHeartbeat?: number;
+  /** Interval (ms) for batching outbound state updates; mirrors server-side sync coalescing */
+  wsBatchInterval?: number;
   /** Hydration mode ('immediate', 'idle', 'visible') */
   hydration?: {
     mode: "immediate" | "idle" | "visible" | "manual" | "progressive" | "lazy";
@@ -93,12 +95,14 @@ export interface RuntimeConfig {
   disableSanitization?: boolean;
   /** WebSocket serialization format */
   serializationFormat?: "json" | "msgpack";
-  /** Unified transport fallback settings (WebSocket -> SSE -> polling). */
+  /**
+   * Unified transport fallback settings: the WebSocket falls back to
+   * long-polling at pollUrl, then to SSE.
+   */
   transport?: {
     enabled?: boolean;
     sseUrl?: string;
     pollUrl?: string;
-    pollInterval?: number;
   };
 }
 
@@ -236,10 +240,10 @@ export function init(userConfig: Partial<RuntimeConfig> = {}): void {
           wsUrl: config.wsUrl,
           sseUrl: config.transport?.sseUrl,
           pollUrl: config.transport?.pollUrl,
-          pollInterval: config.transport?.pollInterval,
           wsReconnectDelay: config.wsReconnectDelay,
           wsMaxReconnect: config.wsMaxReconnect,
           wsHeartbeat: config.wsHeartbeat,
+          wsBatchInterval: config.wsBatchInterval,
           serializationFormat: config.serializationFormat,
           debug: Boolean(config.debug),
         });
@@ -389,72 +393,173 @@ function autoBindIsland(componentId: string, root: HTMLElement): void {
  * Scan DOM for GoSPA components and islands, initialize them.
  */
 export function autoInit(): void {
+  mountClientOnly(document);
+
   const componentRoots = document.querySelectorAll("[data-gospa-component]");
   componentRoots.forEach((root) => {
     const el = root as HTMLElement;
     if (el.getAttribute(COMPONENT_INIT_ATTR) === "true") return;
-    const name = el.getAttribute("data-gospa-component")!;
-    const id = el.id || `c-${Math.random().toString(36).substring(2, 9)}`;
-    if (!el.id) el.id = id;
-
-    const instance = createComponent(id, name);
-    const stateData = el.getAttribute("data-gospa-state");
-    if (stateData) {
-      try {
-        instance.states.fromJSON(JSON.parse(stateData));
-      } catch (e) {
-        if (config.debug)
-          console.error("Error parsing initial state for", name, e);
-      }
-    }
-    autoBindIsland(id, el);
-    el.setAttribute(COMPONENT_INIT_ATTR, "true");
+    whenHydrating(el, () => initComponentRoot(el));
   });
 
   const islandRoots = document.querySelectorAll("[data-gospa-island]");
   islandRoots.forEach((root) => {
     const el = root as HTMLElement;
     if (el.getAttribute(ISLAND_INIT_ATTR) === "true") return;
-    const name = el.getAttribute("data-gospa-island");
-    if (!name) return;
-
-    let setup = setupFunctions.get(name);
-    if (!setup) {
-      const globalSetups = (window as any).__GOSPA_SETUPS__;
-      if (globalSetups && typeof globalSetups[name] === "function") {
-        setup = globalSetups[name];
+    whenHydrating(el, () => initIslandRoot(el));
+  });
+}
+
+const CLIENT_ONLY_ATTR = "data-gospa-client-only";
+
+/**
+ * Replace the server placeholder of each templ.ClientOnly wrapper under root
+ * with the content of its <template>.
+ */
+export function mountClientOnly(root: ParentNode): void {
+  root
+    .querySelectorAll(`div[${CLIENT_ONLY_ATTR}=""]`)
+    .forEach((wrapper) => {
+      const tpl = Array.from(wrapper.children).find(
+        (child): child is HTMLTemplateElement =>
+          child instanceof HTMLTemplateElement,
+      );
+      if (!tpl) return;
+      wrapper.replaceChildren(document.importNode(tpl.content, true));
+      wrapper.setAttribute(CLIENT_ONLY_ATTR, "mounted");
+    });
+}
+
+const HYDRATE_ATTR = "data-gospa-hydrate";
+const HYDRATE_EVENTS = ["mouseenter", "touchstart", "focusin", "click"];
+const pendingHydration = new WeakSet<Element>();
+
+/**
+ * Run hydrate when the nearest data-gospa-hydrate boundary (templ.Hydrate)
+ * allows it: immediately, once visible, when idle, on first interaction, or
+ * never. Elements outside a boundary hydrate immediately.
+ */
+function whenHydrating(el: HTMLElement, hydrate: () => void): void {
+  const boundary = el.closest(`[${HYDRATE_ATTR}]`);
+  const mode = boundary?.getAttribute(HYDRATE_ATTR) || "immediate";
+  if (mode === "never" || pendingHydration.has(el)) return;
+  if (mode === "immediate") {
+    hydrate();
+    return;
+  }
+
+  pendingHydration.add(el);
+  const run = () => {
+    pendingHydration.delete(el);
+    if (el.isConnected) hydrate();
+  };
+  const numberAttr = (name: string) => {
+    const value = parseInt(boundary?.getAttribute(name) || "", 10);
+    return Number.isFinite(value) && value >= 0 ? value : undefined;
+  };
+
+  switch (mode) {
+    case "visible": {
+      if (typeof IntersectionObserver === "undefined") {
+        run();
+        return;
+      }
+      const observer = new IntersectionObserver(
+        (entries) => {
+          if (!entries.some((entry) => entry.isIntersecting)) return;
+          observer.disconnect();
+          run();
+        },
+        { rootMargin: `${numberAttr("data-gospa-threshold") ?? 0}px` },
+      );
+      observer.observe(el);
+      return;
+    }
+    case "idle": {
+      const timeout = numberAttr("data-gospa-defer");
+      if (typeof requestIdleCallback === "function") {
+        requestIdleCallback(run, timeout !== undefined ? { timeout } : {});
+      } else {
+        setTimeout(run, 1);
       }
+      return;
     }
+    case "interaction": {
+      const onFirst = () => {
+        HYDRATE_EVENTS.forEach((type) => el.removeEventListener(type, onFirst));
+        run();
+      };
+      HYDRATE_EVENTS.forEach((type) =>
+        el.addEventListener(type, onFirst, { passive: true }),
+      );
+      return;
+    }
+    default:
+      run();
+  }
+}
 
-    if (setup) {
-      try {
-        let stateData: Record<string, any> = {};
-        const stateAttr = el.getAttribute("data-gospa-state");
-        if (stateAttr) {
-          try {
-            stateData = JSON.parse(stateAttr);
-          } catch {
-            /* ignore */
-          }
-        }
+function initComponentRoot(el: HTMLElement): void {
+  if (el.getAttribute(COMPONENT_INIT_ATTR) === "true") return;
+  const name = el.getAttribute("data-gospa-component")!;
+  const id = el.id || `c-${Math.random().toString(36).substring(2, 9)}`;
+  if (!el.id) el.id = id;
 
-        let propsData: Record<string, any> = {};
-        const propsAttr = el.getAttribute("data-gospa-props");
-        if (propsAttr) {
-          try {
-            propsData = JSON.parse(propsAttr);
-          } catch {
-            /* ignore */
-          }
+  const instance = createComponent(id, name);
+  const stateData = el.getAttribute("data-gospa-state");
+  if (stateData) {
+    try {
+      instance.states.fromJSON(JSON.parse(stateData));
+    } catch (e) {
+      if (config.debug)
+        console.error("Error parsing initial state for", name, e);
+    }
+  }
+  autoBindIsland(id, el);
+  el.setAttribute(COMPONENT_INIT_ATTR, "true");
+}
+
+function initIslandRoot(el: HTMLElement): void {
+  if (el.getAttribute(ISLAND_INIT_ATTR) === "true") return;
+  const name = el.getAttribute("data-gospa-island");
+  if (!name) return;
+
+  let setup = setupFunctions.get(name);
+  if (!setup) {
+    const globalSetups = (window as any).__GOSPA_SETUPS__;
+    if (globalSetups && typeof globalSetups[name] === "function") {
+      setup = globalSetups[name];
+    }
+  }
+
+  if (setup) {
+    try {
+      let stateData: Record<string, any> = {};
+      const stateAttr = el.getAttribute("data-gospa-state");
+      if (stateAttr) {
+        try {
+          stateData = JSON.parse(stateAttr);
+        } catch {
+          /* ignore */
         }
+      }
 
-        setup(el, propsData, stateData);
-        el.setAttribute(ISLAND_INIT_ATTR, "true");
-      } catch (e) {
-        if (config.debug) console.error("Error initializing island", name, e);
+      let propsData: Record<string, any> = {};
+      const propsAttr = el.getAttribute("data-gospa-props");
+      if (propsAttr) {
+        try {
+          propsData = JSON.parse(propsAttr);
+        } catch {
+          /* ignore */
+        }
       }
+
+      setup(el, propsData, stateData);
+      el.setAttribute(ISLAND_INIT_ATTR, "true");
+    } catch (e) {
+      if (config.debug) console.error("Error initializing island", name, e);
     }
-  });
+  }
 }
 
 // Lazy module loaders using the aggregate bundle
@@ -68,19 +68,28 @@ export {
 import {
   remote,
   remoteAction,
+  downloadRemote,
   configureRemote,
   getRemotePrefix,
   type RemoteOptions,
   type RemoteResult,
 } from "./remote.ts";
 
-export { remote, remoteAction, configureRemote, getRemotePrefix };
+export {
+  remote,
+  remoteAction,
+  downloadRemote,
+  configureRemote,
+  getRemotePrefix,
+};
 export type { RemoteOptions, RemoteResult };
 
 // Forms / Actions enhancement
+import { applyFieldErrors } from "./forms.ts";
 export {
   enhanceForm,
   enhanceForms,
+  applyFieldErrors,
   type ActionEnhanceSuccess,
   type ActionRedirect,
   type ActionValidationError,
@@ -103,7 +112,8 @@ import type { NavigateOptions, NavigationOptions } from "./navigation.ts";
 export type { NavigateOptions, NavigationOptions };
 
 // WebSocket
-import type { StateMessage } from "./websocket.ts";
+import type { CallActionOptions, StateMessage } from "./websocket.ts";
+import type { LiveListOptions } from "./list.ts";
 export type { StateMessage };
 
 // Types
@@ -136,6 +146,53 @@ export async function sendAction(name: string, payload?: any) {
   return mod.sendAction(name, payload);
 }
 
+export async function callAction(
+  name: string,
+  payload?: any,
+  options?: CallActionOptions,
+) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.callAction(name, payload, options);
+}
+
+export async function subscribeTopic(topic: string) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.subscribeTopic(topic);
+}
+
+export async function unsubscribeTopic(topic: string) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.unsubscribeTopic(topic);
+}
+
+export async function subscribeKeys(prefixes: string[]) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.subscribeKeys(prefixes);
+}
+
+export async function unsubscribeKeys(prefixes: string[]) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.unsubscribeKeys(prefixes);
+}
+
+export async function crdtMap(key: string) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.crdtMap(key);
+}
+
+export async function crdtList(key: string) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.crdtList(key);
+}
+
+export async function liveList<T = unknown>(
+  name: string,
+  options?: LiveListOptions,
+) {
+  const mod = getTransportFeaturesSync() ?? (await getTransportFeatures());
+  return mod.liveList<T>(name, options);
+}
+
 // Navigation Full API
 export async function navigate(to: string, options?: any) {
   const syncMod = getNavigationFeaturesSync();
@@ -328,10 +385,17 @@ export async function measure(name: string, fn: any, metadata?: any) {
 import GoSPA from "./runtime-core.ts";
 (GoSPA as any).remote = remote;
 (GoSPA as any).remoteAction = remoteAction;
+(GoSPA as any).downloadRemote = downloadRemote;
+(GoSPA as any).applyFieldErrors = applyFieldErrors;
 
 // WebSocket & Navigation APIs
 (GoSPA as any).initWebSocket = initWebSocket;
 (GoSPA as any).sendAction = sendAction;
+(GoSPA as any).callAction = callAction;
+(GoSPA as any).subscribeTopic = subscribeTopic;
+(GoSPA as any).unsubscribeTopic = unsubscribeTopic;
+(GoSPA as any).subscribeKeys = subscribeKeys;
+(GoSPA as any).unsubscribeKeys = unsubscribeKeys;
 (GoSPA as any).navigate = navigate;
 (GoSPA as any).back = back;
 (GoSPA as any).prefetch = prefetch;
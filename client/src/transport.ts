@@ -10,14 +10,15 @@ export type TransportMode = "ws" | "sse" | "polling" | "none";
 export interface TransportConfig {
   wsUrl?: string;
   sseUrl?: string;
+  /** Long-polling endpoint the WebSocket falls back to; empty disables it. */
   pollUrl?: string;
-  pollInterval?: number;
   debug?: boolean;
   onMessage?: (message: StateMessage | Record<string, unknown>) => void;
   onModeChange?: (mode: TransportMode) => void;
   wsReconnectDelay?: number;
   wsMaxReconnect?: number;
   wsHeartbeat?: number;
+  wsBatchInterval?: number;
   serializationFormat?: "json" | "msgpack";
 }
 
@@ -25,7 +26,6 @@ export class TransportManager {
   private readonly config: Required<TransportConfig>;
   private ws: WSClient | null = null;
   private sse: SSEClient | null = null;
-  private pollTimer: ReturnType<typeof setInterval> | null = null;
   private mode: TransportMode = "none";
   private stopped = false;
 
@@ -34,13 +34,13 @@ export class TransportManager {
       wsUrl: config.wsUrl ?? "",
       sseUrl: config.sseUrl ?? "/_sse/connect",
       pollUrl: config.pollUrl ?? "/_gospa/poll",
-      pollInterval: config.pollInterval ?? 5000,
       debug: config.debug ?? false,
       onMessage: config.onMessage ?? (() => {}),
       onModeChange: config.onModeChange ?? (() => {}),
       wsReconnectDelay: config.wsReconnectDelay ?? 1000,
       wsMaxReconnect: config.wsMaxReconnect ?? 10,
       wsHeartbeat: config.wsHeartbeat ?? 30000,
+      wsBatchInterval: config.wsBatchInterval ?? 0,
       serializationFormat: config.serializationFormat ?? "json",
     };
   }
@@ -56,10 +56,7 @@ export class TransportManager {
       if (ok) return this.mode;
     }
 
-    const sseOk = this.startSSE();
-    if (sseOk) return this.mode;
-
-    this.startPolling();
+    this.startSSE();
     return this.mode;
   }
 
@@ -73,10 +70,6 @@ export class TransportManager {
       this.sse.disconnect();
       this.sse = null;
     }
-    if (this.pollTimer) {
-      clearInterval(this.pollTimer);
-      this.pollTimer = null;
-    }
     this.setMode("none");
   }
 
@@ -88,13 +81,15 @@ export class TransportManager {
         reconnectInterval: this.config.wsReconnectDelay,
         maxReconnectAttempts: this.config.wsMaxReconnect,
         heartbeatInterval: this.config.wsHeartbeat,
+        batchInterval: this.config.wsBatchInterval,
         serializationFormat: this.config.serializationFormat,
+        pollUrl: this.config.pollUrl,
         onConnectionFailed: () => {
           if (this.stopped) return;
           this.log(
             "WebSocket exhausted reconnects; switching transport fallback",
           );
-          this.startSSE() || this.startPolling();
+          this.startSSE();
         },
         onMessage: (msg: StateMessage) => {
           this.config.onMessage(msg);
@@ -103,7 +98,7 @@ export class TransportManager {
 
       await ws.connect();
       this.ws = ws;
-      this.setMode("ws");
+      this.setMode(ws.transport === "poll" ? "polling" : "ws");
       return true;
     } catch (err) {
       this.log("WebSocket connection failed", err);
@@ -127,14 +122,6 @@ export class TransportManager {
         this.config.onMessage(payload);
       });
 
-      sse.onError(() => {
-        if (this.stopped) return;
-        if (this.mode === "sse") {
-          this.log("SSE degraded; switching to polling");
-          this.startPolling();
-        }
-      });
-
       sse.connect();
       this.sse = sse;
       this.setMode("sse");
@@ -145,30 +132,6 @@ export class TransportManager {
     }
   }
 
-  private startPolling(): void {
-    if (this.pollTimer) return;
-    this.setMode("polling");
-    this.pollTimer = setInterval(async () => {
-      try {
-        const res = await fetch(this.config.pollUrl, {
-          credentials: "same-origin",
-          headers: { Accept: "application/json" },
-        });
-        if (!res.ok) return;
-        const payload = await res.json();
-        if (Array.isArray((payload as any)?.messages)) {
-          for (const msg of (payload as any).messages) {
-            this.config.onMessage(msg);
-          }
-          return;
-        }
-        this.config.onMessage(payload as Record<string, unknown>);
-      } catch (err) {
-        this.log("Polling request failed", err);
-      }
-    }, this.config.pollInterval);
-  }
-
   private setMode(mode: TransportMode): void {
     if (this.mode === mode) return;
     this.mode = mode;
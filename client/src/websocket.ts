@@ -1,4 +1,11 @@
 import { Rune, batch } from "./state.ts";
+import {
+  PollSocket,
+  SOCKET_CLOSED,
+  SOCKET_CONNECTING,
+  SOCKET_OPEN,
+  type SocketLike,
+} from "./poll.ts";
 
 type MsgPackModule = typeof import("@msgpack/msgpack");
 let msgPackModulePromise: Promise<MsgPackModule> | null = null;
@@ -34,7 +41,22 @@ export type MessageType =
   | "error"
   | "ping"
   | "pong"
-  | "action";
+  | "action"
+  | "cancel"
+  | "subscribe"
+  | "unsubscribe";
+
+// ActionProgress is a "progress" frame sent by WSClient.Progress on the
+// server while an action runs.
+export interface ActionProgress {
+  percent: number; // 0-100
+  message: string;
+}
+
+export interface CallActionOptions {
+  signal?: AbortSignal;
+  onProgress?: (progress: ActionProgress) => void;
+}
 
 export interface StateMessage {
   type:
@@ -46,8 +68,20 @@ export interface StateMessage {
     | "ping"
     | "pong"
     | "action"
+    | "action_ack"
+    | "cancel"
+    | "progress"
     | "patch"
-    | "compressed";
+    | "compressed"
+    | "persist"
+    | "crdt"
+    | "list"
+    | "list_patch"
+    | "resume"
+    | "subscribe"
+    | "unsubscribe"
+    | "subscribed"
+    | "unsubscribed";
   componentId?: string;
   action?: string;
   data?: any;
@@ -57,12 +91,24 @@ export interface StateMessage {
   patch?: Record<string, unknown>;
   compressed?: boolean;
   error?: string;
+  /** Stable error code sent with type "error" (e.g. "RATE_LIMITED"). */
+  code?: string;
   timestamp?: number;
   sessionToken?: string;
   clientId?: string;
   key?: string;
   value?: unknown;
   success?: boolean;
+  version?: number;
+  versions?: Record<string, number>;
+  conflict?: boolean;
+  ops?: unknown[];
+  seq?: number;
+  epoch?: string;
+  topic?: string;
+  /** Set on "init" when the server started a new session because the
+   * previous one belonged to another process. */
+  resync?: boolean;
 }
 
 export type WSTelemetryEventType =
@@ -74,7 +120,8 @@ export type WSTelemetryEventType =
   | "stale-message-dropped"
   | "invalid-message"
   | "patch-failure"
-  | "decompress-failure";
+  | "decompress-failure"
+  | "transport-fallback";
 
 export interface WSTelemetryEvent {
   type: WSTelemetryEventType;
@@ -104,6 +151,21 @@ function validateMessage(raw: unknown): StateMessage | null {
   if (typeof msg.key === "string") validated.key = msg.key;
   if (msg.value !== undefined) validated.value = msg.value;
   if (typeof msg.success === "boolean") validated.success = msg.success;
+  if (typeof msg.version === "number") validated.version = msg.version;
+  if (typeof msg.conflict === "boolean") validated.conflict = msg.conflict;
+  if (Array.isArray(msg.ops)) validated.ops = msg.ops;
+  if (typeof msg.seq === "number") validated.seq = msg.seq;
+  if (typeof msg.epoch === "string") validated.epoch = msg.epoch;
+  // Topic broadcasts carry the topic as "_topic".
+  if (typeof msg.topic === "string") validated.topic = msg.topic;
+  else if (typeof msg._topic === "string") validated.topic = msg._topic;
+  if (
+    msg.versions &&
+    typeof msg.versions === "object" &&
+    !Array.isArray(msg.versions)
+  ) {
+    validated.versions = msg.versions as Record<string, number>;
+  }
 
   if (msg.data !== undefined) {
     validated.data = msg.data;
@@ -154,6 +216,11 @@ export interface WebSocketConfig {
   reconnectJitterRatio?: number;
   reconnectMaxDelay?: number;
   heartbeatInterval?: number;
+  /**
+   * Coalesce outbound "update" messages per key and flush them every
+   * batchInterval ms. 0 sends each update immediately.
+   */
+  batchInterval?: number;
   staleStateGuard?: boolean;
   staleReplayWindowMs?: number;
   telemetry?: boolean;
@@ -164,6 +231,12 @@ export interface WebSocketConfig {
   onConnectionFailed?: (error: Error) => void;
   onMessage?: (message: StateMessage) => void;
   serializationFormat?: "json" | "msgpack";
+  /**
+   * HTTP long-polling endpoint speaking the same protocol, used when
+   * WebSocket is unavailable or the socket never opens. Once used, the rest
+   * of the tab's session sticks to it. Empty disables the fallback.
+   */
+  pollUrl?: string;
   /**
    * Persist session token/clientId in sessionStorage.
    * Disabled by default to reduce token exposure in XSS scenarios.
@@ -183,6 +256,27 @@ export interface WebSocketConfig {
   onQueueDrop?: (dropped: StateMessage, totalDropped: number) => void;
 }
 
+// How long a WebSocket may take to open before falling back to polling.
+// Some proxies hold the upgrade request open instead of refusing it.
+const WS_OPEN_TIMEOUT = 5000;
+const TRANSPORT_KEY = "gospa_ws_transport";
+
+function pollingRemembered(): boolean {
+  try {
+    return sessionStorage.getItem(TRANSPORT_KEY) === "poll";
+  } catch {
+    return false;
+  }
+}
+
+function rememberPolling(): void {
+  try {
+    sessionStorage.setItem(TRANSPORT_KEY, "poll");
+  } catch {
+    // Storage unavailable: retry the WebSocket on the next page.
+  }
+}
+
 // Helper functions for session persistence
 // NOTE: Token is now handled by HttpOnly cookies for security.
 // We only persist the clientId if needed for local identification.
@@ -210,6 +304,38 @@ function saveSession(data: SessionData): void {
   }
 }
 
+// Encrypted, server-sealed state blob (see Config.ClientPersistedStateKeys).
+// The blob is opaque to the client; it is only stored and sent back on init.
+const PERSISTED_STATE_KEY = "gospa_pstate";
+
+function loadPersistedState(): string | null {
+  try {
+    return localStorage.getItem(PERSISTED_STATE_KEY);
+  } catch {
+    return null;
+  }
+}
+
+function savePersistedState(data: {
+  blob?: unknown;
+  storage?: unknown;
+  maxAge?: unknown;
+}): void {
+  if (typeof data.blob !== "string" || data.blob === "") return;
+  const maxAge = typeof data.maxAge === "number" ? data.maxAge : 2592000;
+  try {
+    if (data.storage === "cookie") {
+      const secure = location.protocol === "https:" ? "; Secure" : "";
+      document.cookie = `${PERSISTED_STATE_KEY}=${data.blob}; Path=/; Max-Age=${maxAge}; SameSite=Strict${secure}`;
+      localStorage.removeItem(PERSISTED_STATE_KEY);
+    } else {
+      localStorage.setItem(PERSISTED_STATE_KEY, data.blob);
+    }
+  } catch (e) {
+    console.warn("[GoSPA] Failed to persist state:", e);
+  }
+}
+
 function clearSession(): void {
   try {
     localStorage.removeItem(SESSION_COOKIE_KEY);
@@ -220,7 +346,12 @@ function clearSession(): void {
 
 // WebSocket client
 export class WSClient {
-  private ws: WebSocket | null = null;
+  private ws: SocketLike | null = null;
+  // Whether connections go through the long-polling endpoint.
+  private usePolling = false;
+  // Set once a WebSocket has opened: later failures are outages, not a
+  // network blocking WebSocket, and don't switch to polling.
+  private wsOpened = false;
   private config: Required<WebSocketConfig>;
   private reconnectAttempts = 0;
   private heartbeatTimer: ReturnType<typeof setInterval> | null = null;
@@ -232,6 +363,8 @@ export class WSClient {
       resolve: (value: unknown) => void;
       reject: (error: Error) => void;
       timeout: ReturnType<typeof setTimeout>;
+      onProgress?: (progress: ActionProgress) => void;
+      rearm: () => void;
     }
   >();
   private requestId = 0;
@@ -242,6 +375,24 @@ export class WSClient {
   private lastPingSentAt: number | null = null;
   private lastConnectAt = 0;
   private allowReconnect = true;
+  private pendingUpdates = new Map<string, StateMessage>();
+  // Last server-acknowledged version per state key, used for conflict detection.
+  private keyVersions = new Map<string, number>();
+  // Position in the server's per-session change log, sent on reconnect so the
+  // server can replay only what was missed.
+  private resumeCursor: { epoch: string; seq: number } | null = null;
+  private topics = new Set<string>();
+  // State key prefixes this page wants global syncs for; empty means all.
+  private stateKeys = new Set<string>(pageStateKeys());
+  private crdtHandlers = new Map<
+    string,
+    Set<(message: StateMessage) => void>
+  >();
+  private listHandlers = new Map<
+    string,
+    Set<(message: StateMessage) => void>
+  >();
+  private batchTimer: ReturnType<typeof setTimeout> | null = null;
 
   constructor(config: WebSocketConfig) {
     this.config = {
@@ -252,6 +403,7 @@ export class WSClient {
       reconnectJitterRatio: 0.2,
       reconnectMaxDelay: 30000,
       heartbeatInterval: 30000,
+      batchInterval: 0,
       staleStateGuard: true,
       staleReplayWindowMs: 20000,
       telemetry: true,
@@ -262,6 +414,7 @@ export class WSClient {
       onConnectionFailed: () => {},
       onMessage: () => {},
       serializationFormat: "json",
+      pollUrl: "",
       persistSession: false,
       persistQueueOnUnload: true,
       maxQueuedMessages: 500,
@@ -269,6 +422,9 @@ export class WSClient {
       ...config,
     };
     this.connectionState = new Rune<ConnectionState>("disconnected");
+    this.usePolling =
+      Boolean(this.config.pollUrl) &&
+      (typeof WebSocket === "undefined" || pollingRemembered());
     this.sessionData = this.config.persistSession ? loadSession() : null;
     if (!this.config.persistSession) {
       clearSession();
@@ -332,6 +488,7 @@ export class WSClient {
       message.diff ||
       message.patch ||
       message.type === "init" ||
+      message.type === "resume" ||
       message.type === "update" ||
       message.type === "sync",
     );
@@ -355,11 +512,16 @@ export class WSClient {
     return this.connectionState.get() === "connected";
   }
 
+  /** The transport connections use: a WebSocket or HTTP long-polling. */
+  get transport(): "websocket" | "poll" {
+    return this.usePolling ? "poll" : "websocket";
+  }
+
   private stableConnectionTimer: ReturnType<typeof setTimeout> | null = null;
   private reconnectTimer: ReturnType<typeof setTimeout> | null = null;
 
   async connect(): Promise<void> {
-    if (this.config.serializationFormat === "msgpack") {
+    if (this.config.serializationFormat === "msgpack" && !this.usePolling) {
       await getMsgPackModule();
     }
 
@@ -367,18 +529,18 @@ export class WSClient {
       // If already connected or connecting, don't start another one
       if (
         this.ws &&
-        (this.ws.readyState === WebSocket.OPEN ||
-          this.ws.readyState === WebSocket.CONNECTING)
+        (this.ws.readyState === SOCKET_OPEN ||
+          this.ws.readyState === SOCKET_CONNECTING)
       ) {
-        if (this.ws.readyState === WebSocket.OPEN) {
+        if (this.ws.readyState === SOCKET_OPEN) {
           resolve();
         } else {
           // Wait for the existing attempt
           const check = setInterval(() => {
-            if (this.ws?.readyState === WebSocket.OPEN) {
+            if (this.ws?.readyState === SOCKET_OPEN) {
               clearInterval(check);
               resolve();
-            } else if (!this.ws || this.ws.readyState === WebSocket.CLOSED) {
+            } else if (!this.ws || this.ws.readyState === SOCKET_CLOSED) {
               clearInterval(check);
               reject(new Error("Connection failed"));
             }
@@ -392,18 +554,43 @@ export class WSClient {
 
       // SECURITY: Do NOT pass session token in URL - it leaks in logs/referrers
       // Instead, send it as the first message after connection opens
+      let opened = false;
+      // A socket that never opens hands over to long-polling.
+      const fallsBack = () =>
+        !opened && this.allowReconnect && this.canFallBack();
+      let openTimer: ReturnType<typeof setTimeout> | null = null;
       try {
-        this.ws = new WebSocket(this.config.url);
-        if (this.config.serializationFormat === "msgpack") {
-          this.ws.binaryType = "arraybuffer";
+        if (this.usePolling) {
+          this.ws = new PollSocket(this.config.pollUrl);
+        } else {
+          const socket = new WebSocket(this.config.url);
+          if (this.config.serializationFormat === "msgpack") {
+            socket.binaryType = "arraybuffer";
+          }
+          this.ws = socket;
+          if (this.canFallBack()) {
+            openTimer = setTimeout(() => {
+              if (!opened) socket.close();
+            }, WS_OPEN_TIMEOUT);
+          }
         }
       } catch (error) {
+        if (fallsBack()) {
+          this.fallBackToPolling();
+          this.ws = null;
+          this.connect().then(resolve, reject);
+          return;
+        }
         this.connectionState.set("disconnected");
         reject(error);
         return;
       }
 
       this.ws.onopen = () => {
+        opened = true;
+        if (openTimer) clearTimeout(openTimer);
+        if (!this.usePolling) this.wsOpened = true;
+        const reconnected = this.lastConnectAt > 0;
         this.connectionState.set("connected");
         this.lastConnectAt = Date.now();
         this.emitTelemetry("connect", {
@@ -426,26 +613,81 @@ export class WSClient {
 
         // SECURITY: Send session token as first message (not in URL)
         // Server will validate and associate this connection with the session
-        if (this.sessionData?.clientId) {
-          // Only send init if we have a clientId
+        const persisted = loadPersistedState();
+        // ID of the render that loaded this page, so the server logs this
+        // connection under the same request ID.
+        const requestId =
+          typeof window !== "undefined"
+            ? (window as any).__GOSPA_CONFIG__?.requestId
+            : undefined;
+        if (this.sessionData?.clientId || persisted || requestId) {
+          // Only send init if we have a clientId, persisted state to restore,
+          // or a request ID to correlate
           const initMsg: StateMessage = {
             type: "init",
-            clientId: this.sessionData.clientId,
+            clientId: this.sessionData?.clientId,
           };
+          if (persisted) {
+            initMsg.data = { persisted };
+          }
+          if (requestId) {
+            initMsg.data = { ...initMsg.data, requestId };
+          }
+          if (this.resumeCursor) {
+            initMsg.data = { ...initMsg.data, resume: this.resumeCursor };
+          }
           this.send(initMsg);
         }
 
         this.flushMessageQueue();
 
+        // Shared CRDT keys: re-join topics and fetch a fresh snapshot.
+        for (const key of this.crdtHandlers.keys()) {
+          this.send({ type: "crdt", payload: { key } });
+        }
+        // So do list subscriptions; reload the lists, which also catches up
+        // on events missed while disconnected.
+        if (reconnected) {
+          for (const [key, handlers] of this.listHandlers) {
+            const reset: StateMessage = {
+              type: "list_patch",
+              key,
+              data: { events: [{ op: "reset" }] },
+            };
+            handlers.forEach((handler) => handler(reset));
+          }
+        }
+        // Topic subscriptions live on the connection, so re-join them too.
+        for (const topic of this.topics) {
+          this.send({ type: "subscribe", payload: { topic } });
+        }
+        if (this.stateKeys.size > 0) {
+          this.send({
+            type: "subscribe",
+            payload: { keys: [...this.stateKeys] },
+          });
+        }
+
         // State HMR: Request fresh state from server on reconnect
         // This softly patches the runes locally without refreshing the page!
-        this.send({ type: "sync" });
+        // With a resume cursor the server answers the init with either the
+        // missed changes or a full snapshot, so no extra sync is needed.
+        if (!this.resumeCursor) {
+          this.send({ type: "sync" });
+        }
 
         this.config.onOpen();
         resolve();
       };
 
       this.ws.onclose = (event) => {
+        if (openTimer) clearTimeout(openTimer);
+        if (fallsBack()) {
+          this.fallBackToPolling();
+          this.ws = null;
+          this.connect().then(resolve, reject);
+          return;
+        }
         this.connectionState.set("disconnected");
         this.stopHeartbeat();
         if (this.stableConnectionTimer) {
@@ -476,7 +718,7 @@ export class WSClient {
 
       this.ws.onerror = (error) => {
         this.config.onError(error);
-        if (this.connectionState.get() === "connecting") {
+        if (this.connectionState.get() === "connecting" && !fallsBack()) {
           reject(new Error("WebSocket connection failed"));
         }
       };
@@ -487,8 +729,24 @@ export class WSClient {
     });
   }
 
+  private canFallBack(): boolean {
+    return (
+      !this.usePolling && !this.wsOpened && Boolean(this.config.pollUrl)
+    );
+  }
+
+  private fallBackToPolling(): void {
+    this.usePolling = true;
+    rememberPolling();
+    this.emitTelemetry("transport-fallback", { url: this.config.pollUrl });
+    console.warn(
+      "[GoSPA] WebSocket unavailable, falling back to HTTP long-polling.",
+    );
+  }
+
   disconnect(): void {
     this.allowReconnect = false;
+    this.flushBatchedUpdates();
     if (this.reconnectTimer) {
       clearTimeout(this.reconnectTimer);
       this.reconnectTimer = null;
@@ -600,9 +858,94 @@ export class WSClient {
     this.messageQueue.push(message);
   }
 
+  // Queue an "update" so only the latest value per key is sent each interval.
+  private batchUpdate(message: StateMessage): boolean {
+    const key = (message.payload as { key?: unknown } | undefined)?.key;
+    if (typeof key !== "string" || message.data?._requestId) return false;
+    const batchKey = `${message.componentId ?? ""}\u0000${key}`;
+    this.pendingUpdates.set(batchKey, message);
+    if (!this.batchTimer) {
+      this.batchTimer = setTimeout(
+        () => this.flushBatchedUpdates(),
+        this.config.batchInterval,
+      );
+    }
+    return true;
+  }
+
+  private flushBatchedUpdates(): void {
+    if (this.batchTimer) {
+      clearTimeout(this.batchTimer);
+      this.batchTimer = null;
+    }
+    const pending = Array.from(this.pendingUpdates.values());
+    this.pendingUpdates.clear();
+    for (const message of pending) {
+      this.sendNow(message);
+    }
+  }
+
   send(message: StateMessage): void {
-    if (this.ws?.readyState === WebSocket.OPEN) {
-      if (this.config.serializationFormat === "msgpack") {
+    this.stampVersion(message);
+    if (
+      this.config.batchInterval > 0 &&
+      message.type === "update" &&
+      this.batchUpdate(message)
+    ) {
+      return;
+    }
+    if (this.pendingUpdates.size > 0) {
+      // Keep ordering: batched updates go out before any other message.
+      this.flushBatchedUpdates();
+    }
+    this.sendNow(message);
+  }
+
+  // Attach the base version and write time so the server can order concurrent tabs.
+  private stampVersion(message: StateMessage): void {
+    const payload = message.payload as
+      | { key?: unknown; version?: number; ts?: number }
+      | undefined;
+    if (message.type !== "update" || typeof payload?.key !== "string") return;
+    const fullKey = message.componentId
+      ? `${message.componentId}.${payload.key}`
+      : payload.key;
+    const version = this.keyVersions.get(fullKey);
+    if (version !== undefined) payload.version = version;
+    payload.ts = Date.now();
+  }
+
+  private trackResume(message: StateMessage): void {
+    if (typeof message.seq !== "number") return;
+    if (
+      (message.type === "init" || message.type === "resume") &&
+      message.epoch
+    ) {
+      this.resumeCursor = { epoch: message.epoch, seq: message.seq };
+    } else if (this.resumeCursor && message.seq > this.resumeCursor.seq) {
+      this.resumeCursor.seq = message.seq;
+    }
+  }
+
+  private trackVersions(message: StateMessage): void {
+    if (message.type === "sync" && typeof message.version === "number") {
+      const key = message.componentId
+        ? `${message.componentId}.${message.key}`
+        : message.key;
+      if (key) this.keyVersions.set(key, message.version);
+    } else if (
+      (message.type === "patch" || message.type === "resume") &&
+      message.versions
+    ) {
+      for (const [key, version] of Object.entries(message.versions)) {
+        if (typeof version === "number") this.keyVersions.set(key, version);
+      }
+    }
+  }
+
+  private sendNow(message: StateMessage): void {
+    if (this.ws?.readyState === SOCKET_OPEN) {
+      if (this.config.serializationFormat === "msgpack" && !this.usePolling) {
         const msgpack = getMsgPackModuleSync();
         if (!msgpack) {
           this.enqueueMessage(message);
@@ -622,29 +965,68 @@ export class WSClient {
     }
   }
 
-  sendWithResponse<T>(message: StateMessage): Promise<T> {
+  sendWithResponse<T>(
+    message: StateMessage,
+    signal?: AbortSignal,
+    onProgress?: (progress: ActionProgress) => void,
+  ): Promise<T> {
     return new Promise((resolve, reject) => {
+      if (signal?.aborted) {
+        reject(new DOMException("Request aborted", "AbortError"));
+        return;
+      }
       const id = `req_${++this.requestId}`;
       message.data = { ...message.data, _requestId: id };
 
-      // Timeout after 30 seconds
-      const timeout = setTimeout(() => {
+      const onAbort = () => {
+        const pending = this.pendingRequests.get(id);
+        if (!pending) return;
+        clearTimeout(pending.timeout);
+        this.pendingRequests.delete(id);
+        this.cancelRequest(id);
+        reject(new DOMException("Request aborted", "AbortError"));
+      };
+      signal?.addEventListener("abort", onAbort, { once: true });
+
+      // Timeout after 30 seconds without a reply or progress report
+      const expire = () => {
         if (this.pendingRequests.has(id)) {
           this.pendingRequests.delete(id);
+          signal?.removeEventListener("abort", onAbort);
           reject(new Error("Request timeout"));
         }
-      }, 30000);
+      };
 
-      this.pendingRequests.set(id, {
-        resolve: resolve as (value: unknown) => void,
-        reject,
-        timeout,
-      });
+      const pending = {
+        resolve: (value: unknown) => {
+          signal?.removeEventListener("abort", onAbort);
+          resolve(value as T);
+        },
+        reject: (error: Error) => {
+          signal?.removeEventListener("abort", onAbort);
+          reject(error);
+        },
+        timeout: setTimeout(expire, 30000),
+        onProgress,
+        rearm: () => {
+          clearTimeout(pending.timeout);
+          pending.timeout = setTimeout(expire, 30000);
+        },
+      };
+      this.pendingRequests.set(id, pending);
 
       this.send(message);
     });
   }
 
+  /**
+   * Ask the server to cancel the call with requestId: an action sent with
+   * callAction, or a remote action this session called over HTTP.
+   */
+  cancelRequest(requestId: string): void {
+    this.send({ type: "cancel", payload: { requestId } });
+  }
+
   private async handleMessage(data: any): Promise<void> {
     try {
       let raw: any;
@@ -722,6 +1104,21 @@ export class WSClient {
         this.lastServerTimestamp = message.timestamp;
       }
 
+      if (message.type === "init" && message.resync) {
+        // The old session's versions and change log don't exist here.
+        this.keyVersions.clear();
+        this.resumeCursor = null;
+      }
+      this.trackVersions(message);
+      this.trackResume(message);
+
+      if (message.type === "persist") {
+        if (message.data && typeof message.data === "object") {
+          savePersistedState(message.data);
+        }
+        return;
+      }
+
       if (message.type === "patch" && !message.patch) {
         this.emitTelemetry("patch-failure", {
           reason: "patch_message_missing_patch_payload",
@@ -739,6 +1136,18 @@ export class WSClient {
         }
       }
 
+      // Progress of a pending action, reported by WSClient.Progress
+      if (message.type === "progress" && message.data?.requestId) {
+        const pending = this.pendingRequests.get(message.data.requestId);
+        if (pending) {
+          pending.rearm();
+          pending.onProgress?.({
+            percent: Number(message.data.percent) || 0,
+            message: String(message.data.message ?? ""),
+          });
+        }
+      }
+
       // Handle response to pending request
       if (message.data?._responseId) {
         const id = message.data._responseId as string;
@@ -750,7 +1159,10 @@ export class WSClient {
             const rawError = message.error || "Unknown error";
             // Use native Error object which stores message as plain text.
             // The danger only exists if the UI developer does el.innerHTML = err.message.
-            pending.reject(new Error(rawError));
+            // `code` carries the server's stable error code.
+            pending.reject(
+              Object.assign(new Error(rawError), { code: message.code }),
+            );
           } else {
             pending.resolve(message.data);
           }
@@ -767,12 +1179,112 @@ export class WSClient {
         );
       }
 
+      if (message.type === "crdt" && message.key) {
+        this.crdtHandlers.get(message.key)?.forEach((handler) => {
+          handler(message);
+        });
+      }
+
+      if (message.type === "list_patch" && message.key) {
+        this.listHandlers.get(message.key)?.forEach((handler) => {
+          handler(message);
+        });
+      }
+
       this.config.onMessage(message);
     } catch (error) {
       console.error("[GoSPA] Failed to handle WebSocket message:", error);
     }
   }
 
+  // Receive "crdt" frames for a shared key. The first subscriber joins the
+  // server topic, which replies with a snapshot.
+  subscribeCRDT(
+    key: string,
+    handler: (message: StateMessage) => void,
+  ): () => void {
+    let handlers = this.crdtHandlers.get(key);
+    if (!handlers) {
+      handlers = new Set();
+      this.crdtHandlers.set(key, handlers);
+      if (this.isConnected) this.send({ type: "crdt", payload: { key } });
+    }
+    handlers.add(handler);
+    return () => {
+      handlers.delete(handler);
+      if (handlers.size === 0) this.crdtHandlers.delete(key);
+    };
+  }
+
+  // Load a page of a server list source (app.RegisterListSource). The
+  // connection receives the list's events from then on.
+  fetchList(name: string, cursor: string, limit: number): Promise<unknown> {
+    return this.sendWithResponse({
+      type: "list",
+      payload: { list: name, cursor, limit },
+    });
+  }
+
+  // Receive "list_patch" frames for a list. The last handler to leave stops
+  // the server's events for it.
+  subscribeList(
+    name: string,
+    handler: (message: StateMessage) => void,
+  ): () => void {
+    let handlers = this.listHandlers.get(name);
+    if (!handlers) {
+      handlers = new Set();
+      this.listHandlers.set(name, handlers);
+    }
+    handlers.add(handler);
+    return () => {
+      handlers.delete(handler);
+      if (handlers.size === 0 && this.listHandlers.get(name) === handlers) {
+        this.listHandlers.delete(name);
+        if (this.isConnected) {
+          this.send({ type: "list", payload: { list: name, close: true } });
+        }
+      }
+    };
+  }
+
+  // Join a server topic so broadcasts for it (App.BroadcastStateToTopic)
+  // reach this client. The server's WSTopicAuthorizer must allow the topic;
+  // otherwise the promise rejects. Subscriptions survive reconnects.
+  async subscribe(topic: string): Promise<void> {
+    if (this.topics.has(topic)) return;
+    this.topics.add(topic);
+    try {
+      await this.sendWithResponse({ type: "subscribe", payload: { topic } });
+    } catch (error) {
+      this.topics.delete(topic);
+      throw error;
+    }
+  }
+
+  // Leave a topic joined with subscribe.
+  unsubscribe(topic: string): void {
+    if (!this.topics.delete(topic)) return;
+    this.send({ type: "unsubscribe", payload: { topic } });
+  }
+
+  // Limit global state syncs (App.BroadcastState) to keys starting with one
+  // of prefixes. Until the first call, or after every prefix is removed, the
+  // client receives all keys.
+  subscribeKeys(prefixes: string[]): void {
+    const added = prefixes.filter((p) => p && !this.stateKeys.has(p));
+    if (added.length === 0) return;
+    added.forEach((p) => this.stateKeys.add(p));
+    this.send({ type: "subscribe", payload: { keys: added } });
+  }
+
+  // Drop key prefixes declared with subscribeKeys.
+  unsubscribeKeys(prefixes: string[]): void {
+    const removed = prefixes.filter((p) => this.stateKeys.delete(p));
+    if (removed.length === 0) return;
+    this.send({ type: "unsubscribe", payload: { keys: removed } });
+  }
+
   // Sync global state request
   requestSync(): void {
     this.send({ type: "sync" });
@@ -787,6 +1299,24 @@ export class WSClient {
     });
   }
 
+  /**
+   * Call an action and wait for the server to finish it. Aborting signal
+   * cancels the context of a handler registered with
+   * fiber.RegisterContextActionHandler, and onProgress receives the
+   * progress it reports with WSClient.Progress.
+   */
+  callAction(
+    action: string,
+    payload: any = {},
+    options: CallActionOptions = {},
+  ): Promise<void> {
+    return this.sendWithResponse<unknown>(
+      { type: "action", action, payload },
+      options.signal,
+      options.onProgress,
+    ).then(() => undefined);
+  }
+
   // Request state from server
   requestState(componentId: string): Promise<Record<string, unknown>> {
     return this.sendWithResponse({
@@ -805,6 +1335,52 @@ export function sendAction(action: string, payload: any = {}): void {
   }
 }
 
+// Global action helper that resolves once the server handled the action
+export function callAction(
+  action: string,
+  payload: any = {},
+  options: CallActionOptions = {},
+): Promise<void> {
+  if (!clientInstance) {
+    return Promise.reject(new Error("WebSocket not initialized"));
+  }
+  return clientInstance.callAction(action, payload, options);
+}
+
+// Global topic helpers
+export function subscribeTopic(topic: string): Promise<void> {
+  if (!clientInstance) {
+    return Promise.reject(new Error("WebSocket not initialized"));
+  }
+  return clientInstance.subscribe(topic);
+}
+
+export function unsubscribeTopic(topic: string): void {
+  clientInstance?.unsubscribe(topic);
+}
+
+export function subscribeKeys(prefixes: string[]): void {
+  clientInstance?.subscribeKeys(prefixes);
+}
+
+export function unsubscribeKeys(prefixes: string[]): void {
+  clientInstance?.unsubscribeKeys(prefixes);
+}
+
+// pageStateKeys reads key prefixes declared by the page in
+// <meta name="gospa-state-keys" content="cpu., mem.">.
+function pageStateKeys(): string[] {
+  if (typeof document === "undefined") return [];
+  const content = document
+    .querySelector('meta[name="gospa-state-keys"]')
+    ?.getAttribute("content");
+  if (!content) return [];
+  return content
+    .split(",")
+    .map((k) => k.trim())
+    .filter(Boolean);
+}
+
 // Singleton instance
 let clientInstance: WSClient | null = null;
 
@@ -10,8 +10,11 @@ import (
 
 	"github.com/aydenstechdungeon/gospa"
 	"github.com/aydenstechdungeon/gospa/cli"
+	"github.com/aydenstechdungeon/gospa/plugin"
 
 	// Register built-in plugins
+	_ "github.com/aydenstechdungeon/gospa/plugin/admin"
+	_ "github.com/aydenstechdungeon/gospa/plugin/crud"
 	_ "github.com/aydenstechdungeon/gospa/plugin/image"
 	_ "github.com/aydenstechdungeon/gospa/plugin/postcss"
 	_ "github.com/aydenstechdungeon/gospa/plugin/qrcode"
@@ -85,6 +88,10 @@ func main() {
 		noStatic := fs.Bool("no-static", false, "Skip static asset copying")
 		noCompress := fs.Bool("no-compress", false, "Skip compression")
 		sourcemap := fs.Bool("sourcemap", false, "Generate source maps")
+		deploy := fs.Bool("deploy", false, "Generate a Dockerfile and docker-compose.yml in ./deploy")
+		k8s := fs.Bool("k8s", false, "With --deploy, also generate Kubernetes manifests")
+		var pruneState pruneStateFlag
+		fs.Var(&pruneState, "prune-state", "Drop DefaultState keys no component references (--prune-state=fail fails the build instead)")
 		_ = fs.Parse(os.Args[2:])
 		cfg := &cli.BuildConfig{
 			OutputDir:    *out,
@@ -100,6 +107,7 @@ func main() {
 			NoStatic:     *noStatic,
 			NoCompress:   *noCompress,
 			SourceMap:    *sourcemap,
+			PruneState:   string(pruneState),
 		}
 		if *platform != "" {
 			cfg.Platform = *platform
@@ -107,6 +115,17 @@ func main() {
 		if *arch != "" {
 			cfg.Arch = *arch
 		}
+		if *deploy {
+			projectCfg, err := cli.LoadConfig("")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+				os.Exit(1)
+			}
+			cfg.Deploy = projectCfg.DeployConfig()
+			if *k8s {
+				cfg.Deploy.Kubernetes = true
+			}
+		}
 		cli.Build(cfg)
 	case "generate":
 		fs := flag.NewFlagSet("generate", flag.ExitOnError)
@@ -119,6 +138,7 @@ func main() {
 		strict := fs.Bool("strict", false, "Strict type checking")
 		noTempl := fs.Bool("no-templ", false, "Skip templ generate")
 		watch := fs.Bool("watch", false, "Watch mode")
+		basePath := fs.String("base-path", "", "URL prefix for the routes of an app mounted with App.Mount, e.g. /shop")
 		_ = fs.Parse(os.Args[2:])
 		cli.Generate(&cli.GenerateConfig{
 			OutputDir:     *out,
@@ -130,6 +150,7 @@ func main() {
 			Strict:        *strict,
 			NoTempl:       *noTempl,
 			Watch:         *watch,
+			BasePath:      *basePath,
 		})
 	case "doctor":
 		fs := flag.NewFlagSet("doctor", flag.ExitOnError)
@@ -161,6 +182,17 @@ func main() {
 			Quiet:      *quiet,
 			Strict:     *strict,
 		})
+	case "audit":
+		fs := flag.NewFlagSet("audit", flag.ExitOnError)
+		rootDir := fs.String("root-dir", ".", "Project root directory to scan")
+		jsonOutput := fs.Bool("json", false, "JSON output")
+		failOn := fs.String("fail-on", "high", "Lowest severity that fails the audit (high, medium, low, none)")
+		_ = fs.Parse(os.Args[2:])
+		cli.Audit(&cli.AuditConfig{
+			RootDir:    *rootDir,
+			JSONOutput: *jsonOutput,
+			FailOn:     *failOn,
+		})
 	case "prune":
 		fs := flag.NewFlagSet("prune", flag.ExitOnError)
 		rootDir := fs.String("root-dir", ".", "Project root directory to analyze")
@@ -241,6 +273,58 @@ func main() {
 			Manifest:  *manifest,
 			Parallel:  *parallel,
 		})
+	case "deploy":
+		if len(os.Args) < 3 || os.Args[2] != "scaffold" {
+			fmt.Fprintln(os.Stderr, "Usage: gospa deploy scaffold --target systemd|caddy|nginx")
+			os.Exit(1)
+		}
+		deployScaffold(os.Args[3:])
+	case "bench":
+		if len(os.Args) > 2 && os.Args[2] == "ws" {
+			benchWS(os.Args[3:])
+			return
+		}
+		fs := flag.NewFlagSet("bench", flag.ExitOnError)
+		url := fs.String("url", "http://localhost:3000/", "Target URL")
+		concurrency := fs.Int("concurrency", 10, "Concurrent workers (connections in --ws mode)")
+		duration := fs.Duration("duration", 10*time.Second, "How long to generate load")
+		ws := fs.Bool("ws", false, "Measure WebSocket ping/pong round-trips instead of HTTP GETs")
+		timeout := fs.Duration("timeout", 10*time.Second, "Per-request timeout")
+		out := fs.String("out", "", "Export results to a .json or .csv file")
+		format := fs.String("format", "", "Export format: json or csv (default: from --out extension)")
+		baseline := fs.String("baseline", "", "Compare against a saved baseline and exit 1 on regression")
+		saveBaseline := fs.String("save-baseline", "", "Save this run as a baseline JSON file")
+		threshold := fs.Float64("threshold", 10, "Allowed regression against the baseline, in percent")
+		jsonOutput := fs.Bool("json", false, "JSON output")
+		_ = fs.Parse(os.Args[2:])
+		cli.Bench(&cli.BenchConfig{
+			URL:          *url,
+			Concurrency:  *concurrency,
+			Duration:     *duration,
+			WS:           *ws,
+			Timeout:      *timeout,
+			Output:       *out,
+			Format:       *format,
+			Baseline:     *baseline,
+			SaveBaseline: *saveBaseline,
+			Threshold:    *threshold,
+			JSONOutput:   *jsonOutput,
+		})
+	case "purge-state":
+		fs := flag.NewFlagSet("purge-state", flag.ExitOnError)
+		url := fs.String("url", "http://localhost:3000", "Server base URL")
+		olderThan := fs.Duration("older-than", 0, "Purge clients not seen for this long (default: the server's StateRetention)")
+		token := fs.String("token", os.Getenv("GOSPA_ADMIN_TOKEN"), "Bearer token for RemoteActionMiddleware (default: $GOSPA_ADMIN_TOKEN)")
+		timeout := fs.Duration("timeout", 30*time.Second, "Request timeout")
+		jsonOutput := fs.Bool("json", false, "JSON output")
+		_ = fs.Parse(os.Args[2:])
+		cli.PurgeState(&cli.PurgeStateConfig{
+			URL:        *url,
+			OlderThan:  *olderThan,
+			Token:      *token,
+			Timeout:    *timeout,
+			JSONOutput: *jsonOutput,
+		})
 	case "config":
 		fs := flag.NewFlagSet("config", flag.ExitOnError)
 		showCmd := fs.Bool("show", false, "Show effective config")
@@ -273,11 +357,112 @@ func main() {
 			fs.Usage()
 			os.Exit(1)
 		}
+	case "add":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(os.Stderr, "Usage: gospa add <feature> [args]")
+			os.Exit(1)
+		}
+		runPluginCommand("add:"+os.Args[2], os.Args[3:])
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
+		runPluginCommand(os.Args[1], os.Args[2:])
+	}
+}
+
+// runPluginCommand runs a command registered by a plugin, or prints usage if
+// no plugin provides it.
+func runPluginCommand(name string, args []string) {
+	found, err := plugin.RunCommand(name, args)
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", name)
 		usage()
 		os.Exit(1)
 	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// deployScaffold parses the flags of `gospa deploy scaffold`. Unset flags fall
+// back to the deploy section of the project config.
+func deployScaffold(args []string) {
+	fs := flag.NewFlagSet("deploy scaffold", flag.ExitOnError)
+	target := fs.String("target", "", "What to generate: systemd, caddy, or nginx")
+	out := fs.String("o", "", "Output directory (default: deploy)")
+	stdout := fs.Bool("stdout", false, "Print the config instead of writing a file")
+	domain := fs.String("domain", "", "Public host name served by the proxy")
+	port := fs.Int("port", 0, "Port the app listens on")
+	installDir := fs.String("install-dir", "", "Directory holding the built server and static/ (default: /opt/<name>)")
+	user := fs.String("user", "", "Service user for systemd (default: <name>)")
+	wsPath := fs.String("ws-path", "/_gospa/ws", "WebSocket endpoint path")
+	staticPrefix := fs.String("static-prefix", "/static", "URL prefix for static files")
+	_ = fs.Parse(args)
+
+	projectCfg, err := cli.LoadConfig("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := projectCfg.ScaffoldConfig(*target)
+	cfg.Stdout = *stdout
+	cfg.WSPath = *wsPath
+	cfg.StaticPrefix = *staticPrefix
+	if *out != "" {
+		cfg.OutputDir = *out
+	}
+	if *domain != "" {
+		cfg.Domain = *domain
+	}
+	if *port > 0 {
+		cfg.Port = *port
+	}
+	if *installDir != "" {
+		cfg.InstallDir = *installDir
+	}
+	if *user != "" {
+		cfg.User = *user
+	}
+	cli.DeployScaffold(cfg)
+}
+
+// benchWS parses the flags of `gospa bench ws`, the state sync load test.
+func benchWS(args []string) {
+	fs := flag.NewFlagSet("bench ws", flag.ExitOnError)
+	url := fs.String("url", "http://localhost:3000/", "Page URL for the session handshake (or ws(s):// endpoint)")
+	clients := fs.Int("clients", 50, "Simulated clients")
+	sessions := fs.Int("sessions", 1, "Sessions the clients are spread across")
+	senders := fs.Int("senders", 0, "Clients sending updates (default: all)")
+	rate := fs.Float64("rate", 1, "Updates per second sent by each sender")
+	duration := fs.Duration("duration", 10*time.Second, "How long to send updates")
+	rampUp := fs.Duration("ramp-up", 0, "Spread connection setup over this period")
+	drain := fs.Duration("drain", time.Second, "Wait for in-flight broadcasts after sending stops")
+	timeout := fs.Duration("timeout", 10*time.Second, "Handshake and write timeout")
+	out := fs.String("out", "", "Export results to a .json or .csv file")
+	format := fs.String("format", "", "Export format: json or csv (default: from --out extension)")
+	baseline := fs.String("baseline", "", "Compare against a saved baseline and exit 1 on regression")
+	saveBaseline := fs.String("save-baseline", "", "Save this run as a baseline JSON file")
+	threshold := fs.Float64("threshold", 10, "Allowed regression against the baseline, in percent")
+	jsonOutput := fs.Bool("json", false, "JSON output")
+	_ = fs.Parse(args)
+	cli.BenchWS(&cli.BenchWSConfig{
+		BenchConfig: cli.BenchConfig{
+			URL:          *url,
+			Concurrency:  *clients,
+			Duration:     *duration,
+			Timeout:      *timeout,
+			Output:       *out,
+			Format:       *format,
+			Baseline:     *baseline,
+			SaveBaseline: *saveBaseline,
+			Threshold:    *threshold,
+			JSONOutput:   *jsonOutput,
+		},
+		Sessions: *sessions,
+		Senders:  *senders,
+		Rate:     *rate,
+		RampUp:   *rampUp,
+		Drain:    *drain,
+	})
 }
 
 func usage() {
@@ -292,11 +477,17 @@ Commands:
   build           Build for production
   build-all       Build for all platforms
   generate        Generate routes and client artifacts
+  add <feature>   Run a plugin's add command (e.g. add crud <Model>)
   serve           Serve production build
   doctor          Validate local project/tooling setup
   verify          Run strict preflight checks (dev/CI gate)
+  audit           Report unsafe HTML output and CSRF/CORS misconfiguration
   prune           Analyze and prune unused state
   clean           Remove generated/build artifacts
+  deploy scaffold Generate a systemd unit or Caddy/nginx config
+  bench           Load-test a running server (HTTP or WebSocket)
+  bench ws        Load-test WebSocket state sync fan-out
+  purge-state     Purge state and sessions of inactive clients on a running server
   config          Config file management
   version         Print the CLI/framework version`)
 }
@@ -317,3 +508,25 @@ func splitCSV(input string) []string {
 	}
 	return parts
 }
+
+// pruneStateFlag is --prune-state: bare it means "warn", or it takes
+// =warn or =fail.
+type pruneStateFlag string
+
+func (f *pruneStateFlag) String() string { return string(*f) }
+
+func (f *pruneStateFlag) Set(value string) error {
+	switch value {
+	case "true", "warn":
+		*f = "warn"
+	case "false":
+		*f = ""
+	case "fail":
+		*f = "fail"
+	default:
+		return fmt.Errorf("want warn or fail, got %q", value)
+	}
+	return nil
+}
+
+func (f *pruneStateFlag) IsBoolFlag() bool { return true }
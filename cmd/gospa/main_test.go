@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -29,3 +31,34 @@ func TestSplitCSV(t *testing.T) {
 		})
 	}
 }
+
+func TestPruneStateFlag(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"--prune-state"}, "warn"},
+		{[]string{"--prune-state=fail"}, "fail"},
+	} {
+		fs := flag.NewFlagSet("build", flag.ContinueOnError)
+		var f pruneStateFlag
+		fs.Var(&f, "prune-state", "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("Parse(%v): %v", tt.args, err)
+		}
+		if string(f) != tt.want {
+			t.Errorf("Parse(%v) = %q, want %q", tt.args, f, tt.want)
+		}
+	}
+
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var f pruneStateFlag
+	fs.Var(&f, "prune-state", "")
+	if err := fs.Parse([]string{"--prune-state=loud"}); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
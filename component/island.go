@@ -24,6 +24,8 @@ const (
 	HydrationInteraction IslandHydrationMode = "interaction"
 	// HydrationLazy hydrates when explicitly triggered.
 	HydrationLazy IslandHydrationMode = "lazy"
+	// HydrationNever keeps the server-rendered HTML and never hydrates.
+	HydrationNever IslandHydrationMode = "never"
 )
 
 // IslandPriority defines the loading priority for an island.
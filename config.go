@@ -7,10 +7,17 @@ import (
 
 	fiberpkg "github.com/gofiber/fiber/v3"
 
+	"github.com/aydenstechdungeon/gospa/analytics"
+	"github.com/aydenstechdungeon/gospa/audit"
+	"github.com/aydenstechdungeon/gospa/cdn"
 	"github.com/aydenstechdungeon/gospa/compiler"
+	"github.com/aydenstechdungeon/gospa/db"
 	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/jobs"
+	"github.com/aydenstechdungeon/gospa/plugin/seo"
 	"github.com/aydenstechdungeon/gospa/routing"
 	"github.com/aydenstechdungeon/gospa/store"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
 )
 
 // Version is the current version of GoSPA.
@@ -104,6 +111,9 @@ type Config struct {
 	RoutesFS fs.FS
 	// DevMode enables development features.
 	DevMode bool
+	// Environment is the profile LoadConfig selected, such as "development"
+	// or "production". It is informational; New does not read it.
+	Environment string
 	// RuntimeScript is the path to the client runtime script.
 	RuntimeScript string
 	// StaticDir is the directory for static files.
@@ -120,6 +130,17 @@ type Config struct {
 	WebSocketPath string
 	// WebSocketMiddleware allows injecting session/auth middleware before WebSocket upgrade.
 	WebSocketMiddleware fiberpkg.Handler
+	// DisablePollFallback removes the HTTP long-polling endpoint at
+	// /_gospa/poll that the client runtime falls back to when a WebSocket
+	// cannot connect, e.g. behind proxies that block it.
+	DisablePollFallback bool
+	// ConfigureWebSocket, if set, can adjust the WebSocket handler settings
+	// GoSPA built from this Config before the endpoint is registered, e.g.
+	// to set GenerateID or handle custom message types in OnMessage. Wrap
+	// the OnMessage, OnConnect and OnDisconnect it finds rather than
+	// replacing them, or the built-in messages, App.OnClientConnect and the
+	// analytics stop working. Hub stays the app's.
+	ConfigureWebSocket func(*fiber.WebSocketConfig)
 	// Logger is the structured logger. Defaults to slog.Default().
 	Logger *slog.Logger
 
@@ -129,8 +150,18 @@ type Config struct {
 	// StateDiffing enables delta-only "patch" WebSocket messages for state syncs.
 	StateDiffing   bool
 	CacheTemplates bool // Cache compiled templates (SSG only)
+	// StateSyncCoalesceInterval merges high-frequency state syncs into one "patch" frame per
+	// client per interval (e.g. 16-50ms). The runtime batches outbound updates on the same cadence.
+	StateSyncCoalesceInterval time.Duration
 	// RuntimeTier specifies the complexity of the client runtime.
 	RuntimeTier compiler.RuntimeTier
+	// InlineRuntimeBootstrap inlines a small bootstrap into pages instead of
+	// loading the runtime up front: the page paints from its HTML and state,
+	// and the runtime, islands and WebSocket load on the first interaction
+	// or once the browser is idle. It improves LCP on slow networks; the
+	// interaction that triggers loading is not replayed. Root layouts that
+	// load the runtime themselves are unaffected.
+	InlineRuntimeBootstrap bool
 	// SimpleRuntimeSVGs allows SVG elements in the simple runtime sanitizer.
 	SimpleRuntimeSVGs bool
 	// DisableSanitization disables client-side HTML sanitization for SPA navigation.
@@ -149,6 +180,51 @@ type Config struct {
 	WSConnRateLimit float64
 	// WSConnBurst sets the burst capacity for WebSocket connection upgrades (default 15.0).
 	WSConnBurst float64
+	// WSPongWait drops server-side connections that send nothing, not even a pong, for this long (default 60s).
+	WSPongWait time.Duration
+	// WSPingPeriod is how often the server pings each connection (default 90% of WSPongWait).
+	WSPingPeriod time.Duration
+	// WSWriteWait bounds each server-side write; clients stuck writing for twice this long are reaped (default 10s).
+	WSWriteWait time.Duration
+	// WSResumeBufferSize keeps up to this many recent state changes per session in Storage so a
+	// reconnecting client replays what it missed instead of re-downloading all state (0 = disabled).
+	WSResumeBufferSize int
+	// WSResumeWindow is how long a change stays replayable after it is made (default 2m).
+	WSResumeWindow time.Duration
+	// WSTopicAuthorizer approves client "subscribe" messages so pages receive only the topic
+	// broadcasts for the data they show (see App.BroadcastStateToTopic). Nil rejects all client
+	// subscriptions; fiber.AllowTopicPrefixes covers public topics.
+	WSTopicAuthorizer fiber.TopicAuthorizer
+	// WSStateQuota bounds the keys and bytes each connection can write with "update" messages,
+	// rejecting or evicting over the limits (nil = no limit).
+	WSStateQuota *fiber.StateQuota
+
+	// PersistStateKeys limits which state keys are written to Storage (glob patterns, e.g. "cart.*").
+	// When empty, every key not matched by ExcludeStateKeys is persisted.
+	PersistStateKeys []string
+	// ExcludeStateKeys lists glob patterns for ephemeral state (open menus, cursors) that is never persisted.
+	ExcludeStateKeys []string
+
+	// Multi-Tab Conflict Options
+	// StateConflictPolicy resolves concurrent writes to the same key from tabs sharing a
+	// session: "lww" (last writer wins, default) or "reject" (stale writes are resynced).
+	StateConflictPolicy fiber.ConflictPolicy
+	// StateConflictResolver, when set, decides every conflict that has no per-key merge function.
+	StateConflictResolver fiber.ConflictResolver
+	// StateMergeFuncs maps state keys to deterministic merge functions for conflicting writes.
+	StateMergeFuncs map[string]fiber.MergeFunc
+
+	// Client-Persisted State Options
+	// ClientPersistedStateKeys lists state keys that are also persisted on the client in an
+	// AES-GCM encrypted, HMAC-signed blob so they survive Storage eviction.
+	ClientPersistedStateKeys []string
+	// ClientStateSecrets holds the secrets (at least 32 bytes each) used to seal client-persisted
+	// state. The first secret seals new blobs; the others are still accepted, allowing rotation.
+	ClientStateSecrets [][]byte
+	// ClientStateStorage selects where the runtime stores the blob: "localStorage" (default) or "cookie".
+	ClientStateStorage string
+	// ClientStateMaxAge bounds how long a persisted blob remains valid (default 30 days).
+	ClientStateMaxAge time.Duration
 
 	// Hydration Options
 	HydrationMode    string
@@ -165,6 +241,10 @@ type Config struct {
 	// Rendering Strategy Defaults
 	DefaultRenderStrategy  routing.RenderStrategy
 	DefaultRevalidateAfter time.Duration
+	// Locales are the locales pages varying by routing.VariantLocale are
+	// rendered in, e.g. "en", "fr-CA". The first is used when Accept-Language
+	// matches none of them.
+	Locales []string
 
 	// Remote Action Options
 	MaxRequestBodySize                int              // Maximum allowed size for remote action request bodies
@@ -181,6 +261,15 @@ type Config struct {
 	DisableCSRF           bool
 	ContentSecurityPolicy string
 	PublicOrigin          string
+	// CanonicalRedirect redirects page requests whose scheme or host differ
+	// from PublicOrigin to it with a 301, e.g. www.example.com to
+	// example.com and http to https, so search engines see one URL per page.
+	CanonicalRedirect bool
+	// TrailingSlash redirects page URLs to one form: TrailingSlashAlways
+	// turns /docs into /docs/, TrailingSlashNever /docs/ into /docs. The
+	// default, TrailingSlashPreserve, serves both, each with its own render
+	// cache entry.
+	TrailingSlash string
 	// StrictProduction enforces hard startup validation for production deployments.
 	StrictProduction bool
 	// AllowInsecureWS allows unsecure ws:// connections even on https:// pages.
@@ -195,12 +284,105 @@ type Config struct {
 
 	// Prefork enables Fiber's prefork mode.
 	Prefork bool
+	// Fiber is merged into the Fiber configuration GoSPA builds, so every
+	// non-zero field, such as ReadTimeout, Concurrency, DisableKeepalive or
+	// JSONEncoder, replaces GoSPA's value. GoSPA sets AppName, ServerHeader
+	// ("GoSPA"), BodyLimit (MaxRequestBodySize) and the go-json encoder and
+	// decoder; other fields keep Fiber's defaults.
+	Fiber fiberpkg.Config
+
+	// RequestMode runs the app without a long-lived server process, e.g. on
+	// AWS Lambda (see adapter/lambda) or Cloud Run with request-based CPU.
+	// WebSocket and prefork are disabled, and rendering is limited to SSR and
+	// SSG: ISR routes are served as SSG (bounded by SSGCacheTTL) because
+	// background revalidation may never run, and PPR routes as SSR.
+	RequestMode bool
+	// RealtimeURL is an absolute ws(s):// URL clients use for state sync
+	// instead of this app's WebSocketPath, such as a long-running GoSPA node
+	// sharing Storage and PubSub. Typically set together with RequestMode.
+	RealtimeURL string
+
+	// AutoTLSEmail is the contact address registered with Let's Encrypt by
+	// RunAutoTLS, used for expiry and policy notices. Optional.
+	AutoTLSEmail string
+	// AutoTLSCacheDir caches RunAutoTLS certificates on disk instead of in
+	// Storage. Defaults to ./.gospa/certs when Storage is in-memory.
+	AutoTLSCacheDir string
+
+	// UpgradeTimeout bounds how long RunWithUpgrades waits for a replacement
+	// process to report ready before keeping the old one (default 30s).
+	UpgradeTimeout time.Duration
+	// DrainTimeout bounds how long in-flight requests may take to finish when
+	// RunWithUpgrades hands off or shuts down (default 30s).
+	DrainTimeout time.Duration
 
 	// Storage defines the external storage backend for sessions and state.
 	Storage store.Storage
 
+	// StorageEncryptionKeys, when set, AES-GCM encrypts sessions and client
+	// state before they are written to Storage (see store.Encrypted). Keys
+	// must be at least 32 bytes; the first seals new values and the others
+	// are still accepted, allowing rotation. To encrypt everything, including
+	// SSG entries, wrap Storage with store.Encrypted instead.
+	StorageEncryptionKeys [][]byte
+
+	// StateRetention tracks when each client was last seen and purges the
+	// persisted state and sessions of clients gone longer than this, even if
+	// their entries would otherwise be kept alive. Storage must support sets.
+	// Zero disables tracking.
+	StateRetention time.Duration
+	// StateReapInterval is how often the purge runs (default 1h). With a
+	// lock-capable Storage only one process purges per interval.
+	StateReapInterval time.Duration
+
 	// PubSub defines the messaging backend for multi-process broadcasting.
 	PubSub store.PubSub
+	// PubSubDeadLetters keeps broadcasts that could not be published on
+	// PubSub, after retrying, in Storage for 24h so App.Hub.Redeliver can
+	// send them again. Storage must support sets.
+	PubSubDeadLetters bool
+
+	// JobBackend stores background jobs for App.Jobs. Defaults to Storage when
+	// it is shared (non-memory) and supports sets, else to process memory.
+	JobBackend jobs.Backend
+	// JobWorkers is the number of background jobs run concurrently (default 4).
+	JobWorkers int
+
+	// Audit records remote action and WebSocket action invocations through
+	// App.Audit. See package audit.
+	Audit *audit.Config
+	// Analytics enables cookie-free page view and WebSocket event counting
+	// through App.Analytics. See package analytics.
+	Analytics *analytics.Config
+
+	// Database gives every request a lazily begun transaction, available
+	// through db.Tx. See package db.
+	Database *db.Pool
+
+	// SEO serves /robots.txt and /sitemap.xml generated from the router, so
+	// deployments without a build step still have them. Start from
+	// seo.DefaultConfig; GenerateRobots and GenerateSitemap pick the files.
+	SEO *seo.Config
+
+	// ServiceWorker serves a service worker that precaches the runtime, the
+	// build's hashed assets and the SSG pages, so the site works offline and
+	// can be installed as a PWA. Unless NavigationOptions configures another
+	// worker, pages register it.
+	ServiceWorker *ServiceWorkerConfig
+
+	// Manifest is served as a web app manifest, by default at
+	// /manifest.webmanifest, with icons resolved through BuildManifest.
+	// Render its head tags with templ.Manifest and the root layout's
+	// "manifest" prop.
+	Manifest *templpkg.ManifestConfig
+
+	// CDNPurger clears edge caches whenever Invalidate, InvalidateTag,
+	// InvalidateKey or InvalidateAll runs, so invalidated pages do not linger
+	// at the CDN. Purgers that need absolute URLs use PublicOrigin. See
+	// package cdn.
+	CDNPurger cdn.Purger
+	// CDNPurgeTimeout bounds one purge (default 10s).
+	CDNPurgeTimeout time.Duration
 
 	// NavigationOptions configures optional client-side navigation behavior.
 	NavigationOptions NavigationOptions
@@ -210,6 +392,10 @@ type Config struct {
 	ISRSemaphoreLimit int
 	// ISRTimeout sets the maximum time for a background ISR revalidation.
 	ISRTimeout time.Duration
+	// Now returns the current time used to age SSG, ISR and PPR cache
+	// entries. Defaults to time.Now; tests can pass a manual clock such as
+	// gospatest.Clock.
+	Now func() time.Time
 
 	// IslandsBundlePath is the path to the islands bundle script.
 	IslandsBundlePath string
@@ -220,6 +406,17 @@ type Config struct {
 	BuildManifest map[string]string
 	// ManifestPath is the path to manifest.json (default: "./manifest.json").
 	ManifestPath string
+
+	// Diagnostics Options
+	// EnablePprof mounts net/http/pprof under /_gospa/debug/pprof/ and expvar
+	// under /_gospa/debug/vars.
+	EnablePprof bool
+	// PprofToken, when set, must be sent as "Authorization: Bearer <token>" or
+	// ?token=<token> to reach the debug endpoints.
+	PprofToken string
+	// DisableStartupSummary stops Run and its variants from printing the
+	// table of effective settings when the server starts.
+	DisableStartupSummary bool
 }
 
 // DefaultConfig returns the default configuration.
@@ -0,0 +1,272 @@
+package gospa
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment names understood by LoadConfig. Other names work too but get
+// no profile defaults.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// ConfigFile is the file LoadConfig reads from the working directory. Its
+// plugins section belongs to the plugins and is ignored here.
+const ConfigFile = "gospa.yaml"
+
+// configFile is the part of ConfigFile that LoadConfig reads.
+type configFile struct {
+	// Environment is used when neither GOSPA_ENV nor GOSPA_DEV is set.
+	Environment  string                            `yaml:"environment"`
+	Config       map[string]interface{}            `yaml:"config"`
+	Environments map[string]map[string]interface{} `yaml:"environments"`
+}
+
+// NewFromEnv creates an app from base overlaid with the settings LoadConfig
+// finds for the current environment.
+func NewFromEnv(base Config) (*App, error) {
+	config, err := LoadConfig(base)
+	if err != nil {
+		return nil, err
+	}
+	return New(config), nil
+}
+
+// LoadConfig returns base with these layers applied in order:
+//
+//  1. the profile defaults: DevMode is true for development and false for
+//     staging and production
+//  2. the config section of gospa.yaml
+//  3. the environments.<env> section of gospa.yaml
+//  4. GOSPA_<SETTING> environment variables, e.g. GOSPA_PUBLIC_ORIGIN or
+//     GOSPA_CACHE_TEMPLATES
+//
+// The environment is GOSPA_ENV, else development when GOSPA_DEV is true (as
+// `gospa dev` sets it), else the file's environment key, else production.
+// Only settings of string, bool, number, duration and list types can be
+// loaded; gospa.yaml names them in lower camel case (publicOrigin) and lists
+// in environment variables are comma separated.
+func LoadConfig(base Config) (Config, error) {
+	var file configFile
+	data, err := os.ReadFile(ConfigFile)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return base, fmt.Errorf("%s: %w", ConfigFile, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return base, err
+	}
+
+	config := base
+	config.Environment = resolveEnvironment(file.Environment)
+	switch config.Environment {
+	case EnvDevelopment:
+		config.DevMode = true
+	case EnvStaging, EnvProduction:
+		config.DevMode = false
+	}
+
+	v := reflect.ValueOf(&config).Elem()
+	fields := loadableFields(v.Type())
+	if err := applyConfigSection(v, fields, file.Config, "config"); err != nil {
+		return base, err
+	}
+	for name, section := range file.Environments {
+		if normalizeEnvironment(name) == config.Environment {
+			if err := applyConfigSection(v, fields, section, "environments."+name); err != nil {
+				return base, err
+			}
+		}
+	}
+	for key, index := range fields {
+		name := "GOSPA_" + envVarName(v.Type().Field(index).Name)
+		if raw, ok := os.LookupEnv(name); ok {
+			if err := setConfigField(v.Field(index), raw); err != nil {
+				return base, fmt.Errorf("%s (%s): %w", name, key, err)
+			}
+		}
+	}
+	return config, nil
+}
+
+// resolveEnvironment picks the environment name, see LoadConfig.
+func resolveEnvironment(fileDefault string) string {
+	if env := os.Getenv("GOSPA_ENV"); strings.TrimSpace(env) != "" {
+		return normalizeEnvironment(env)
+	}
+	if dev, err := strconv.ParseBool(os.Getenv("GOSPA_DEV")); err == nil && dev {
+		return EnvDevelopment
+	}
+	if strings.TrimSpace(fileDefault) != "" {
+		return normalizeEnvironment(fileDefault)
+	}
+	return EnvProduction
+}
+
+// normalizeEnvironment lower-cases name and expands common abbreviations.
+func normalizeEnvironment(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "dev", "develop":
+		return EnvDevelopment
+	case "stage":
+		return EnvStaging
+	case "prod":
+		return EnvProduction
+	}
+	return name
+}
+
+// loadableFields maps the lower-cased names of the Config fields LoadConfig
+// can set to their indexes.
+func loadableFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Name == "Environment" || !loadableType(f.Type) {
+			continue
+		}
+		fields[strings.ToLower(f.Name)] = i
+	}
+	return fields
+}
+
+func loadableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && loadableType(t.Elem())
+	}
+	return false
+}
+
+// applyConfigSection sets the fields named in a gospa.yaml section.
+func applyConfigSection(v reflect.Value, fields map[string]int, section map[string]interface{}, where string) error {
+	for key, value := range section {
+		index, ok := fields[strings.ToLower(key)]
+		if !ok {
+			return fmt.Errorf("%s: %s: unknown or unsupported setting %q", ConfigFile, where, key)
+		}
+		f := v.Field(index)
+		var err error
+		switch value := value.(type) {
+		case nil:
+			f.Set(reflect.Zero(f.Type()))
+		case []interface{}:
+			if f.Kind() != reflect.Slice {
+				err = fmt.Errorf("expected a single value, got a list")
+				break
+			}
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			err = setConfigSlice(f, items)
+		case map[string]interface{}:
+			err = fmt.Errorf("expected a value, got a mapping")
+		default:
+			err = setConfigField(f, fmt.Sprint(value))
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s.%s: %w", ConfigFile, where, key, err)
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setConfigField parses raw into f. Lists are comma separated.
+func setConfigField(f reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		var items []string
+		if raw != "" {
+			items = strings.Split(raw, ",")
+		}
+		return setConfigSlice(f, items)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return nil
+}
+
+func setConfigSlice(f reflect.Value, items []string) error {
+	s := reflect.MakeSlice(f.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setConfigField(s.Index(i), item); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	f.Set(s)
+	return nil
+}
+
+// envVarName converts a field name to upper snake case, keeping acronyms
+// together: WSMaxMessageSize becomes WS_MAX_MESSAGE_SIZE.
+func envVarName(field string) string {
+	runes := []rune(field)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package gospa
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `
+environment: development
+config:
+  appName: Shop
+  cacheTemplates: true
+  wsMaxMessageSize: 32768
+  allowedOrigins: [https://a.example]
+environments:
+  development:
+    allowInsecureWS: true
+  staging:
+    publicOrigin: https://staging.shop.example
+  prod:
+    publicOrigin: https://shop.example
+    ssgCacheTTL: 5m
+plugins:
+  postcss:
+    enabled: true
+`
+
+func writeTestConfigFile(t *testing.T, content string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(ConfigFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	writeTestConfigFile(t, testConfigFile)
+	t.Setenv("GOSPA_ENV", "")
+	t.Setenv("GOSPA_DEV", "")
+	base := DefaultConfig()
+
+	// The file's environment key selects development.
+	cfg, err := LoadConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != EnvDevelopment || !cfg.DevMode || !cfg.AllowInsecureWS {
+		t.Fatalf("development: env=%q DevMode=%v AllowInsecureWS=%v", cfg.Environment, cfg.DevMode, cfg.AllowInsecureWS)
+	}
+	if cfg.AppName != "Shop" || !cfg.CacheTemplates || cfg.WSMaxMessageSize != 32768 || !reflect.DeepEqual(cfg.AllowedOrigins, []string{"https://a.example"}) {
+		t.Fatalf("base section not applied: %+v", cfg)
+	}
+	if cfg.WebSocketPath != base.WebSocketPath {
+		t.Fatalf("unrelated setting changed: %q", cfg.WebSocketPath)
+	}
+
+	// GOSPA_ENV wins, abbreviations match, and variables override the file.
+	t.Setenv("GOSPA_ENV", "production")
+	t.Setenv("GOSPA_APP_NAME", "Shop (eu)")
+	t.Setenv("GOSPA_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+	t.Setenv("GOSPA_WS_MAX_MESSAGE_SIZE", "1024")
+	cfg, err = LoadConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != EnvProduction || cfg.DevMode || cfg.AllowInsecureWS {
+		t.Fatalf("production: env=%q DevMode=%v AllowInsecureWS=%v", cfg.Environment, cfg.DevMode, cfg.AllowInsecureWS)
+	}
+	if cfg.PublicOrigin != "https://shop.example" || cfg.SSGCacheTTL != 5*time.Minute {
+		t.Fatalf("production overlay not applied: origin=%q ttl=%v", cfg.PublicOrigin, cfg.SSGCacheTTL)
+	}
+	if cfg.AppName != "Shop (eu)" || cfg.WSMaxMessageSize != 1024 || !reflect.DeepEqual(cfg.AllowedOrigins, []string{"https://a.example", "https://b.example"}) {
+		t.Fatalf("environment variables not applied: %q %d %v", cfg.AppName, cfg.WSMaxMessageSize, cfg.AllowedOrigins)
+	}
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("GOSPA_ENV", "")
+	t.Setenv("GOSPA_DEV", "true")
+	base := DefaultConfig()
+	base.AppName = "Blog"
+
+	cfg, err := LoadConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Environment != EnvDevelopment || !cfg.DevMode || cfg.AppName != "Blog" {
+		t.Fatalf("GOSPA_DEV: env=%q DevMode=%v AppName=%q", cfg.Environment, cfg.DevMode, cfg.AppName)
+	}
+
+	t.Setenv("GOSPA_DEV", "")
+	base.DevMode = true
+	if cfg, _ = LoadConfig(base); cfg.Environment != EnvProduction || cfg.DevMode {
+		t.Fatalf("default: env=%q DevMode=%v", cfg.Environment, cfg.DevMode)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Setenv("GOSPA_ENV", "staging")
+	for name, tt := range map[string]struct {
+		file, env, want string
+	}{
+		"unknown key":      {file: "config:\n  devMod: true\n", want: `unknown or unsupported setting "devMod"`},
+		"unsupported type": {file: "config:\n  storage: redis\n", want: `unsupported setting "storage"`},
+		"bad duration":     {file: "environments:\n  staging:\n    ssgCacheTTL: 30\n", want: "environments.staging.ssgCacheTTL"},
+		"list for scalar":  {file: "config:\n  appName: [a, b]\n", want: "got a list"},
+		"bad variable":     {env: "yes please", want: "GOSPA_CACHE_TEMPLATES"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeTestConfigFile(t, tt.file)
+			if tt.env != "" {
+				t.Setenv("GOSPA_CACHE_TEMPLATES", tt.env)
+			}
+			if _, err := LoadConfig(DefaultConfig()); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadConfig() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	for field, want := range map[string]string{
+		"DevMode":                  "DEV_MODE",
+		"WSMaxMessageSize":         "WS_MAX_MESSAGE_SIZE",
+		"SSGCacheTTL":              "SSG_CACHE_TTL",
+		"AllowPortsWithInsecureWS": "ALLOW_PORTS_WITH_INSECURE_WS",
+		"EnableCSRF":               "ENABLE_CSRF",
+		"ISRSemaphoreLimit":        "ISR_SEMAPHORE_LIMIT",
+	} {
+		if got := envVarName(field); got != want {
+			t.Errorf("envVarName(%s) = %s, want %s", field, got, want)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -136,3 +138,24 @@ func TestMinimalConfig(t *testing.T) {
 		t.Errorf("expected WSHeartbeat to be 0, got %v", config.WSHeartbeat)
 	}
 }
+
+func TestNewMergesFiberConfig(t *testing.T) {
+	app := New(Config{
+		AppName: "shop",
+		Fiber: fiberpkg.Config{
+			ReadTimeout:      5 * time.Second,
+			Concurrency:      1024,
+			DisableKeepalive: true,
+			ServerHeader:     "edge",
+		},
+	})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	cfg := app.Fiber.Config()
+	if cfg.ReadTimeout != 5*time.Second || cfg.Concurrency != 1024 || !cfg.DisableKeepalive || cfg.ServerHeader != "edge" {
+		t.Fatalf("Fiber overrides not applied: %+v", cfg)
+	}
+	if cfg.AppName != "shop" || cfg.BodyLimit != 4*1024*1024 || cfg.JSONEncoder == nil {
+		t.Fatalf("GoSPA defaults lost: AppName=%q BodyLimit=%d", cfg.AppName, cfg.BodyLimit)
+	}
+}
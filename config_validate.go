@@ -0,0 +1,65 @@
+package gospa
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// Validate reports settings that contradict each other, each with the change
+// that resolves it. New runs it after applying defaults; a non-nil error is
+// logged and makes Prepare, Run and /readyz fail.
+func (c *Config) Validate() error {
+	var errs error
+	production := !c.DevMode
+
+	if c.Prefork && !c.RequestMode && production && isInMemoryStorage(c.Storage) {
+		errs = errors.Join(errs, errors.New("Prefork=true with in-memory Storage in production: every process keeps its own sessions, rate limits and render caches; set Storage to a shared backend (e.g. store/redis) or disable Prefork"))
+	}
+
+	switch c.TrailingSlash {
+	case "", TrailingSlashPreserve, TrailingSlashAlways, TrailingSlashNever:
+	default:
+		errs = errors.Join(errs, fmt.Errorf("TrailingSlash=%q; use always, never or preserve", c.TrailingSlash))
+	}
+	if c.CanonicalRedirect {
+		if origin, err := url.Parse(strings.TrimSpace(c.PublicOrigin)); err != nil || origin.Host == "" || (origin.Scheme != "http" && origin.Scheme != "https") {
+			errs = errors.Join(errs, errors.New("CanonicalRedirect=true needs PublicOrigin set to the canonical http(s) origin, e.g. https://example.com"))
+		}
+	}
+
+	if c.CompressState && c.RuntimeTier == RuntimeTierMicro {
+		errs = errors.Join(errs, errors.New("CompressState=true with RuntimeTier=micro: the micro runtime has no WebSocket client to decompress state; use RuntimeTierCore or RuntimeTierFull, or disable CompressState"))
+	}
+
+	if c.DisableCSRF && production && c.AllowUnauthenticatedRemoteActions && c.RemoteActionMiddleware == nil {
+		errs = errors.Join(errs, errors.New("DisableCSRF=true with AllowUnauthenticatedRemoteActions=true in production: any site can call remote actions with a visitor's cookies; keep CSRF enabled, set RemoteActionMiddleware, or set DevMode for local use"))
+	}
+
+	if needsTemplateCache(c.DefaultRenderStrategy) && !c.CacheTemplates {
+		errs = errors.Join(errs, fmt.Errorf("DefaultRenderStrategy=%s but CacheTemplates=false; enable CacheTemplates or use ssr", c.DefaultRenderStrategy))
+	}
+	for path, opts := range routing.GetAllRouteOptions() {
+		if opts.Runtime != "" && tierToLevel(opts.Runtime) == 0 && opts.Runtime != routing.RuntimeNone {
+			errs = errors.Join(errs, fmt.Errorf("route %q sets Runtime=%q; use full, core, micro or none", path, opts.Runtime))
+		}
+		strategy := opts.Strategy
+		if strategy == "" {
+			// Covered by the DefaultRenderStrategy check above.
+			continue
+		}
+		if needsTemplateCache(strategy) && !c.CacheTemplates {
+			errs = errors.Join(errs, fmt.Errorf("route %q uses %s but CacheTemplates=false; enable CacheTemplates or change strategy", path, strategy))
+		}
+	}
+
+	return errs
+}
+
+// needsTemplateCache reports whether strategy serves cached renders.
+func needsTemplateCache(strategy routing.RenderStrategy) bool {
+	return strategy == routing.StrategySSG || strategy == routing.StrategyISR || strategy == routing.StrategyPPR
+}
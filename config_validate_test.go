@@ -0,0 +1,94 @@
+package gospa
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"defaults", func(*Config) {}, ""},
+		{"prefork with memory storage", func(c *Config) { c.Prefork = true }, "Prefork=true with in-memory Storage"},
+		{"prefork with memory storage in dev", func(c *Config) { c.Prefork, c.DevMode = true, true }, ""},
+		{"prefork in request mode", func(c *Config) { c.Prefork, c.RequestMode = true, true }, ""},
+		{"compress with micro runtime", func(c *Config) { c.CompressState, c.RuntimeTier = true, RuntimeTierMicro }, "CompressState=true with RuntimeTier=micro"},
+		{"compress with core runtime", func(c *Config) { c.CompressState, c.RuntimeTier = true, RuntimeTierCore }, ""},
+		{"open remote actions without csrf", func(c *Config) { c.DisableCSRF, c.AllowUnauthenticatedRemoteActions = true, true }, "DisableCSRF=true with AllowUnauthenticatedRemoteActions=true"},
+		{"open remote actions without csrf in dev", func(c *Config) {
+			c.DisableCSRF, c.AllowUnauthenticatedRemoteActions, c.DevMode = true, true, true
+		}, ""},
+		{"default ssg without template cache", func(c *Config) { c.DefaultRenderStrategy, c.CacheTemplates = routing.StrategySSG, false }, "DefaultRenderStrategy=ssg but CacheTemplates=false"},
+		{"default isr with template cache", func(c *Config) { c.DefaultRenderStrategy, c.CacheTemplates = routing.StrategyISR, true }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReportsValidationErrors(t *testing.T) {
+	app := New(Config{Prefork: true, RoutesDir: t.TempDir()})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Prepare(); err == nil || !strings.Contains(err.Error(), "Prefork=true") {
+		t.Fatalf("Prepare() = %v, want the Prefork validation error", err)
+	}
+}
+
+func TestNewReportsShortStorageEncryptionKey(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), StorageEncryptionKeys: [][]byte{[]byte("short")}})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Prepare(); err == nil || !strings.Contains(err.Error(), "storage encryption") {
+		t.Fatalf("Prepare() = %v, want the storage encryption error", err)
+	}
+}
+
+func TestWriteStartupSummary(t *testing.T) {
+	storage := store.NewMemoryStorage()
+	defer func() { _ = storage.Close() }()
+	app := New(Config{
+		AppName:       "Shop",
+		RoutesDir:     t.TempDir(),
+		DevMode:       true,
+		CompressState: true,
+		Storage:       storage,
+		DisableCSRF:   true,
+	})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	var buf bytes.Buffer
+	app.writeStartupSummary(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"GoSPA " + Version,
+		"Shop",
+		"Mode            development",
+		"WebSocket       /_gospa/ws (compressed)",
+		"Storage         memory (this process only)",
+		"CSRF            off",
+		"Remote actions  /_gospa/remote (no middleware)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
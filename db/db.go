@@ -0,0 +1,201 @@
+// Package db ties database transactions to the request lifecycle.
+//
+// Register a pool in Config and every request gets a transaction that is
+// begun on first use, committed when the handler succeeds, and rolled back
+// when it returns an error or a 4xx/5xx status:
+//
+//	sqlDB, _ := sql.Open("pgx", dsn) // pgxpool users: stdlib.OpenDBFromPool(pool)
+//	app := gospa.New(gospa.Config{Database: db.New(sqlDB)})
+//
+//	// Remote actions and handlers with the request context:
+//	tx, err := db.Tx(ctx)
+//	// Load functions:
+//	ctx := db.Context(c)
+//	tx, err := db.Tx(ctx)
+//	// WebSocket action handlers and jobs, which have no request:
+//	err := pool.Run(ctx, func(ctx context.Context) error { ... })
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// scopeLocal is the Fiber local holding the request scope.
+const scopeLocal = "gospa.db"
+
+var (
+	// ErrNoScope is returned by Tx outside a request or Run.
+	ErrNoScope = errors.New("db: no request scope (mount Pool.Middleware or use Pool.Run)")
+	// ErrScopeDone is returned by Tx once the request's transaction has been
+	// committed or rolled back, e.g. from a streamed render.
+	ErrScopeDone = errors.New("db: request transaction already finished")
+)
+
+// Pool is a database registered for request-scoped transactions.
+type Pool struct {
+	DB *sql.DB
+	// TxOptions are used to begin each transaction.
+	TxOptions *sql.TxOptions
+	// LogQueries reports each statement run on a request transaction to the
+	// dev panel's Profile tab (DevMode only).
+	LogQueries bool
+}
+
+// New returns a Pool for sqlDB.
+func New(sqlDB *sql.DB) *Pool {
+	return &Pool{DB: sqlDB}
+}
+
+type scopeKey struct{}
+
+// scope holds the transactions of one request or Run call.
+type scope struct {
+	ctx     context.Context
+	pool    *Pool
+	onQuery QueryFunc
+
+	mu sync.Mutex
+	// pools are the pools whose transactions this scope ends.
+	pools map[*Pool]bool
+	txs   map[*Pool]*Transaction
+	done  map[*Pool]bool
+}
+
+func newScope(ctx context.Context, p *Pool, onQuery QueryFunc) *scope {
+	s := &scope{pool: p, onQuery: onQuery, pools: map[*Pool]bool{p: true}}
+	s.ctx = context.WithValue(ctx, scopeKey{}, s)
+	return s
+}
+
+func (s *scope) register(p *Pool) {
+	s.mu.Lock()
+	s.pools[p] = true
+	s.mu.Unlock()
+}
+
+func scopeFrom(ctx context.Context) *scope {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s
+}
+
+// Tx returns the transaction of the request's default pool (the first one
+// registered), beginning it on first use.
+func Tx(ctx context.Context) (*Transaction, error) {
+	s := scopeFrom(ctx)
+	if s == nil {
+		return nil, ErrNoScope
+	}
+	return s.tx(s.pool)
+}
+
+// Tx returns the request's transaction on p, beginning it on first use.
+func (p *Pool) Tx(ctx context.Context) (*Transaction, error) {
+	s := scopeFrom(ctx)
+	if s == nil {
+		return nil, ErrNoScope
+	}
+	return s.tx(p)
+}
+
+// Context returns the request context carrying the transaction scope, for
+// use from a Load function.
+func Context(c routing.LoadContext) context.Context {
+	if s, ok := c.Local(scopeLocal).(*scope); ok {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+func (s *scope) tx(p *Pool) (*Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx, ok := s.txs[p]; ok {
+		return tx, nil
+	}
+	if s.done[p] {
+		return nil, ErrScopeDone
+	}
+	if !s.pools[p] {
+		return nil, ErrNoScope
+	}
+	if s.txs == nil {
+		s.txs = make(map[*Pool]*Transaction)
+	}
+	// The transaction outlives any per-call deadline; it ends with the scope.
+	sqlTx, err := p.DB.BeginTx(context.WithoutCancel(s.ctx), p.TxOptions)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Transaction{Tx: sqlTx}
+	if p.LogQueries {
+		tx.onQuery = s.onQuery
+	}
+	s.txs[p] = tx
+	return tx, nil
+}
+
+// finish commits or rolls back the transaction on p, if one was begun.
+func (s *scope) finish(p *Pool, commit bool) error {
+	s.mu.Lock()
+	tx := s.txs[p]
+	delete(s.txs, p)
+	if s.done == nil {
+		s.done = make(map[*Pool]bool)
+	}
+	s.done[p] = true
+	s.mu.Unlock()
+	if tx == nil {
+		return nil
+	}
+	if commit {
+		return tx.Commit()
+	}
+	return tx.Rollback()
+}
+
+// Middleware gives each request a transaction scope for p. The transaction
+// commits when the handler returns nil with a status below 400 and rolls
+// back otherwise. logQueries, if non-nil, is called once per request and
+// returns where that request's statements are reported.
+func (p *Pool) Middleware(logQueries func(c gofiber.Ctx) QueryFunc) gofiber.Handler {
+	return func(c gofiber.Ctx) error {
+		s, ok := c.Locals(scopeLocal).(*scope)
+		if !ok {
+			var onQuery QueryFunc
+			if logQueries != nil {
+				onQuery = logQueries(c)
+			}
+			s = newScope(c.Context(), p, onQuery)
+			c.Locals(scopeLocal, s)
+			c.SetContext(s.ctx)
+		} else {
+			s.register(p)
+		}
+
+		err := c.Next()
+
+		commit := err == nil && c.Response().StatusCode() < gofiber.StatusBadRequest
+		if ferr := s.finish(p, commit); ferr != nil && err == nil {
+			return ferr
+		}
+		return err
+	}
+}
+
+// Run calls fn with a transaction scope for p, for work outside an HTTP
+// request such as WebSocket action handlers and jobs. The transaction
+// commits if fn returns nil and rolls back otherwise.
+func (p *Pool) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := newScope(ctx, p, nil)
+	err := fn(s.ctx)
+	if ferr := s.finish(p, err == nil); ferr != nil && err == nil {
+		return ferr
+	}
+	return err
+}
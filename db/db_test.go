@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// recorder is a minimal database/sql driver that records transaction events.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+}
+
+func (r *recorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.events
+	r.events = nil
+	return out
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &recConn{r}, nil }
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return r }
+
+type recConn struct{ r *recorder }
+
+func (c *recConn) Prepare(query string) (driver.Stmt, error) { return &recStmt{c.r, query}, nil }
+func (c *recConn) Close() error                              { return nil }
+func (c *recConn) Begin() (driver.Tx, error) {
+	c.r.add("begin")
+	return &recTx{c.r}, nil
+}
+
+type recTx struct{ r *recorder }
+
+func (t *recTx) Commit() error   { t.r.add("commit"); return nil }
+func (t *recTx) Rollback() error { t.r.add("rollback"); return nil }
+
+type recStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recStmt) Close() error  { return nil }
+func (s *recStmt) NumInput() int { return -1 }
+func (s *recStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.r.add(s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s *recStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func newTestPool(t *testing.T) (*Pool, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(rec)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return New(sqlDB), rec
+}
+
+func TestMiddlewareCommitsOrRollsBack(t *testing.T) {
+	pool, rec := newTestPool(t)
+	app := gofiber.New()
+	app.Use(pool.Middleware(nil))
+	app.Get("/ok", func(c gofiber.Ctx) error {
+		tx, err := Tx(c.Context())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(c.Context(), "INSERT ok")
+		return err
+	})
+	app.Get("/fail", func(c gofiber.Ctx) error {
+		tx, err := Tx(c.Context())
+		if err != nil {
+			return err
+		}
+		_, _ = tx.Exec("INSERT fail")
+		return c.SendStatus(gofiber.StatusUnprocessableEntity)
+	})
+	app.Get("/unused", func(c gofiber.Ctx) error { return c.SendString("ok") })
+
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{"/ok", []string{"begin", "INSERT ok", "commit"}},
+		{"/fail", []string{"begin", "INSERT fail", "rollback"}},
+		{"/unused", nil},
+	}
+	for _, tc := range cases {
+		if _, err := app.Test(httptest.NewRequest("GET", tc.path, nil)); err != nil {
+			t.Fatal(err)
+		}
+		got := rec.take()
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: events %v, want %v", tc.path, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s: events %v, want %v", tc.path, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestRunScopesTransaction(t *testing.T) {
+	pool, rec := newTestPool(t)
+
+	if _, err := Tx(context.Background()); !errors.Is(err, ErrNoScope) {
+		t.Fatalf("Tx outside scope = %v, want ErrNoScope", err)
+	}
+
+	var leaked context.Context
+	err := pool.Run(context.Background(), func(ctx context.Context) error {
+		leaked = ctx
+		tx, err := Tx(ctx)
+		if err != nil {
+			return err
+		}
+		again, _ := pool.Tx(ctx)
+		if again != tx {
+			t.Error("second Tx call began a new transaction")
+		}
+		return errors.New("handler failed")
+	})
+	if err == nil || err.Error() != "handler failed" {
+		t.Fatalf("Run = %v", err)
+	}
+	if got := rec.take(); len(got) != 2 || got[1] != "rollback" {
+		t.Fatalf("events %v, want begin, rollback", got)
+	}
+	if _, err := Tx(leaked); !errors.Is(err, ErrScopeDone) {
+		t.Fatalf("Tx after Run = %v, want ErrScopeDone", err)
+	}
+}
+
+func TestLogQueries(t *testing.T) {
+	pool, _ := newTestPool(t)
+	pool.LogQueries = true
+	var logged []Query
+	app := gofiber.New()
+	app.Use(pool.Middleware(func(gofiber.Ctx) QueryFunc {
+		return func(q Query) { logged = append(logged, q) }
+	}))
+	app.Get("/", func(c gofiber.Ctx) error {
+		tx, err := Tx(c.Context())
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE counters")
+		return err
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || logged[0].SQL != "UPDATE counters" || logged[0].Err != nil {
+		t.Fatalf("logged %+v", logged)
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Query describes one statement run on a request transaction.
+type Query struct {
+	SQL      string
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// QueryFunc receives the statements run on a request transaction.
+type QueryFunc func(q Query)
+
+// Transaction is a request transaction. Commit and Rollback are handled by the
+// middleware or Run; calling them directly ends the transaction early.
+type Transaction struct {
+	*sql.Tx
+	onQuery QueryFunc
+}
+
+func (t *Transaction) logQuery(query string, start time.Time, err error) {
+	if t.onQuery != nil {
+		t.onQuery(Query{SQL: query, Start: start, Duration: time.Since(start), Err: err})
+	}
+}
+
+// ExecContext executes a statement that returns no rows.
+func (t *Transaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := t.Tx.ExecContext(ctx, query, args...)
+	t.logQuery(query, start, err)
+	return res, err
+}
+
+// Exec is ExecContext with a background context.
+func (t *Transaction) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a statement that returns rows.
+func (t *Transaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := t.Tx.QueryContext(ctx, query, args...)
+	t.logQuery(query, start, err)
+	return rows, err
+}
+
+// Query is QueryContext with a background context.
+func (t *Transaction) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a statement expected to return at most one row.
+func (t *Transaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := t.Tx.QueryRowContext(ctx, query, args...)
+	t.logQuery(query, start, row.Err())
+	return row
+}
+
+// QueryRow is QueryRowContext with a background context.
+func (t *Transaction) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
@@ -0,0 +1,55 @@
+package gospa
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http/pprof" // #nosec //nolint:gosec // handlers are mounted explicitly under /_gospa/debug/ only when EnablePprof is set
+	"strings"
+
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+)
+
+// debugPrefix is where the pprof and expvar handlers are mounted.
+const debugPrefix = "/_gospa/debug"
+
+// setupDebugRoutes mounts net/http/pprof under /_gospa/debug/pprof/ and expvar
+// under /_gospa/debug/vars.
+func (a *App) setupDebugRoutes() {
+	debug := a.Fiber.Group(debugPrefix, a.debugAuthMiddleware())
+
+	debug.Get("/pprof", func(c gofiber.Ctx) error {
+		return c.Redirect().To(debugPrefix + "/pprof/")
+	})
+	debug.Get("/pprof/", adaptor.HTTPHandlerFunc(pprof.Index))
+	debug.Get("/pprof/cmdline", adaptor.HTTPHandlerFunc(pprof.Cmdline))
+	debug.Get("/pprof/profile", adaptor.HTTPHandlerFunc(pprof.Profile))
+	debug.Get("/pprof/symbol", adaptor.HTTPHandlerFunc(pprof.Symbol))
+	debug.Post("/pprof/symbol", adaptor.HTTPHandlerFunc(pprof.Symbol))
+	debug.Get("/pprof/trace", adaptor.HTTPHandlerFunc(pprof.Trace))
+	// pprof.Index only resolves named profiles under /debug/pprof/, so named
+	// profiles (heap, goroutine, allocs, ...) are dispatched here instead.
+	debug.Get("/pprof/:name", func(c gofiber.Ctx) error {
+		return adaptor.HTTPHandler(pprof.Handler(c.Params("name")))(c)
+	})
+	debug.Get("/vars", adaptor.HTTPHandler(expvar.Handler()))
+}
+
+// debugAuthMiddleware disables caching of debug responses and, when
+// PprofToken is set, requires it as a bearer token or ?token= query value.
+func (a *App) debugAuthMiddleware() gofiber.Handler {
+	return func(c gofiber.Ctx) error {
+		c.Set("Cache-Control", "no-store")
+		if a.Config.PprofToken == "" {
+			return c.Next()
+		}
+		token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = c.Query("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.Config.PprofToken)) != 1 {
+			return a.sendError(c, NewError(CodeDebugAuthRequired, gofiber.StatusUnauthorized, "Debug endpoints require a valid token"))
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package gospa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestDebugRoutes_DisabledByDefault(t *testing.T) {
+	app := New(Config{DevMode: true})
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	res, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/_gospa/debug/vars", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode == fiber.StatusOK {
+		t.Fatal("debug endpoints must not be mounted without EnablePprof")
+	}
+}
+
+func TestDebugRoutes_TokenProtected(t *testing.T) {
+	app := New(Config{DevMode: true, EnablePprof: true, PprofToken: "secret"})
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	res, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/_gospa/debug/vars", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("expected status %d without token, got %d", fiber.StatusUnauthorized, res.StatusCode)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/_gospa/debug/vars", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	res, err = app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d with bearer token, got %d", fiber.StatusOK, res.StatusCode)
+	}
+
+	res, err = app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/_gospa/debug/pprof/goroutine?debug=1&token=secret", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d for named profile, got %d", fiber.StatusOK, res.StatusCode)
+	}
+}
+
+func TestStrictProductionRequiresPprofToken(t *testing.T) {
+	cfg := StrictProductionConfig()
+	cfg.EnablePprof = true
+	if err := validateAndLogConfig(&cfg); err == nil {
+		t.Fatal("expected StrictProduction to reject EnablePprof without PprofToken")
+	}
+}
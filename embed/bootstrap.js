@@ -0,0 +1 @@
+(()=>{const w=window,b=w.__GOSPA_BOOT__;if(!b)return;const v=["pointerdown","keydown","touchstart","focusin"];let s=!1;const t=()=>{if(s)return;s=!0;for(const e of v)removeEventListener(e,t,!0);import(b.runtime).then(r=>{w.__GOSPA_RUNTIME_ESM__=r;r.init(b.init);for(const m of b.modules||[])import(m)})};for(const e of v)addEventListener(e,t,{capture:!0,passive:!0});const i=()=>{"requestIdleCallback"in w?requestIdleCallback(t,{timeout:b.timeout||3e3}):setTimeout(t,1)};document.readyState==="complete"?i():addEventListener("load",i,{once:!0})})();
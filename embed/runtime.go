@@ -28,6 +28,15 @@ func RuntimeJS(tier compiler.RuntimeTier) ([]byte, error) {
 	return runtimeFS.ReadFile(name)
 }
 
+// BootstrapJS returns the bootstrap script that pages inline instead of
+// loading the runtime up front. It reads window.__GOSPA_BOOT__, an object
+// with the runtime URL, the options for its init call and further modules,
+// and imports them on the first interaction or once the browser is idle.
+// It is a classic script, not a module.
+func BootstrapJS() ([]byte, error) {
+	return runtimeFS.ReadFile("bootstrap.js")
+}
+
 // RuntimeHash returns a truncated SHA256 hash of the runtime JavaScript.
 func RuntimeHash(tier compiler.RuntimeTier) (string, error) {
 	content, err := RuntimeJS(tier)
@@ -0,0 +1,210 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/routing/kit"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Error codes sent by the framework. Clients can rely on these values; the
+// messages next to them may change.
+const (
+	CodeInternal              = "INTERNAL_ERROR"
+	CodeBadRequest            = "BAD_REQUEST"
+	CodeUnauthorized          = "UNAUTHORIZED"
+	CodeForbidden             = "FORBIDDEN"
+	CodeNotFound              = "NOT_FOUND"
+	CodeConflict              = "CONFLICT"
+	CodeTimeout               = "TIMEOUT"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeUnavailable           = "SERVICE_UNAVAILABLE"
+	CodeValidationFailed      = "VALIDATION_FAILED"
+	CodeActionFailed          = "ACTION_FAILED"
+	CodeActionNotFound        = "ACTION_NOT_FOUND"
+	CodeInvalidActionName     = "INVALID_ACTION_NAME"
+	CodeInvalidJSON           = "INVALID_JSON"
+	CodeJSONTooDeep           = "JSON_TOO_DEEP"
+	CodeRequestTooLarge       = "REQUEST_TOO_LARGE"
+	CodeInvalidContentType    = "INVALID_CONTENT_TYPE"
+	CodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeRemoteAuthRequired    = "REMOTE_AUTH_REQUIRED"
+	CodeInvalidationAuth      = "INVALIDATION_AUTH_REQUIRED"
+	CodeInvalidInvalidation   = "INVALID_INVALIDATION_PAYLOAD"
+	CodeDebugAuthRequired     = "DEBUG_AUTH_REQUIRED"
+	CodeJobStatsFailed        = "JOB_STATS_FAILED"
+	CodeStatePurgeAuth        = "STATE_PURGE_AUTH_REQUIRED"
+	CodeInvalidStatePurge     = "INVALID_STATE_PURGE"
+	CodeStatePurgeFailed      = "STATE_PURGE_FAILED"
+	CodeCanceled              = "CANCELED"
+)
+
+// statusClientClosedRequest is the non-standard status, from nginx, for
+// requests the client gave up on.
+const statusClientClosedRequest = 499
+
+// Error is the framework's error envelope. Return one from a remote action,
+// Load function, or form action to choose the status and the stable Code the
+// client sees. Responses carry {"error": UserMessage, "code": Code}; Err is
+// logged but only sent to the client in DevMode.
+type Error struct {
+	Code        string
+	HTTPStatus  int
+	UserMessage string
+	// Err is the underlying cause.
+	Err error
+}
+
+// NewError returns an Error.
+func NewError(code string, httpStatus int, userMessage string) *Error {
+	return &Error{Code: code, HTTPStatus: httpStatus, UserMessage: userMessage}
+}
+
+// Common errors. Use Wrap to attach a cause.
+var (
+	ErrBadRequest   = NewError(CodeBadRequest, http.StatusBadRequest, "Bad request")
+	ErrUnauthorized = NewError(CodeUnauthorized, http.StatusUnauthorized, "Unauthorized")
+	ErrForbidden    = NewError(CodeForbidden, http.StatusForbidden, "Forbidden")
+	ErrNotFound     = NewError(CodeNotFound, http.StatusNotFound, "Not found")
+	ErrConflict     = NewError(CodeConflict, http.StatusConflict, "Conflict")
+	ErrTimeout      = NewError(CodeTimeout, http.StatusGatewayTimeout, "Request timed out")
+	ErrRateLimited  = NewError(CodeRateLimited, http.StatusTooManyRequests, "Too many requests")
+	ErrUnavailable  = NewError(CodeUnavailable, http.StatusServiceUnavailable, "Service unavailable")
+	ErrInternal     = NewError(CodeInternal, http.StatusInternalServerError, "Internal server error")
+
+	errActionFailed = NewError(CodeActionFailed, http.StatusInternalServerError, "Internal server error")
+	errCanceled     = NewError(CodeCanceled, statusClientClosedRequest, "Request canceled")
+)
+
+func (e *Error) Error() string {
+	msg := e.Code + ": " + e.UserMessage
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same Code, so
+// errors.Is(err, gospa.ErrNotFound) matches wrapped copies.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ErrorCode returns e.Code. The dev error overlay shows it.
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+// Wrap returns a copy of e with err as its cause.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithMessage returns a copy of e with a different user message.
+func (e *Error) WithMessage(msg string) *Error {
+	c := *e
+	c.UserMessage = msg
+	return &c
+}
+
+// Classify maps any error to an *Error:
+//
+//   - an *Error anywhere in the chain is returned as is
+//   - *routing.ValidationError becomes VALIDATION_FAILED (422)
+//   - kit.Error and kit.Fail keep their status
+//   - *fiber.AppError and Fiber errors keep their code and status
+//   - context.DeadlineExceeded becomes TIMEOUT (504)
+//   - context.Canceled becomes CANCELED (499)
+//   - anything else is INTERNAL_ERROR (500) wrapping err
+//
+// It returns nil for a nil error.
+func Classify(err error) *Error {
+	return classify(err, ErrInternal)
+}
+
+func classify(err error, fallback *Error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var validationErr *routing.ValidationError
+	if errors.As(err, &validationErr) {
+		return &Error{Code: CodeValidationFailed, HTTPStatus: http.StatusUnprocessableEntity, UserMessage: "Validation failed", Err: err}
+	}
+	if httpErr, ok := kit.AsError(err); ok {
+		e := statusError(httpErr.Status, err)
+		if msg, ok := httpErr.Body.(string); ok && msg != "" {
+			e.UserMessage = msg
+		}
+		return e
+	}
+	if failErr, ok := kit.AsFail(err); ok {
+		return statusError(failErr.Status, err)
+	}
+	var appErr *fiber.AppError
+	if errors.As(err, &appErr) {
+		return &Error{Code: string(appErr.Code), HTTPStatus: appErr.StatusCode, UserMessage: appErr.Message, Err: err}
+	}
+	var fiberErr *fiberpkg.Error
+	if errors.As(err, &fiberErr) {
+		return &Error{Code: string(fiber.StatusErrorCode(fiberErr.Code)), HTTPStatus: fiberErr.Code, UserMessage: fiberErr.Message, Err: err}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout.Wrap(err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return errCanceled.Wrap(err)
+	}
+	return fallback.Wrap(err)
+}
+
+func statusError(status int, err error) *Error {
+	msg := http.StatusText(status)
+	if msg == "" {
+		msg = "Request failed"
+	}
+	return &Error{Code: string(fiber.StatusErrorCode(status)), HTTPStatus: status, UserMessage: msg, Err: err}
+}
+
+// errorBody builds the JSON envelope for e. In DevMode the cause is included
+// as "debug".
+func (a *App) errorBody(c fiberpkg.Ctx, e *Error) fiberpkg.Map {
+	body := fiberpkg.Map{"error": e.UserMessage, "code": e.Code}
+	if id := fiber.GetRequestID(c); id != "" {
+		body["requestId"] = id
+	}
+	var validationErr *routing.ValidationError
+	if errors.As(e.Err, &validationErr) {
+		body["validation"] = validationErr
+	}
+	if a.Config.DevMode && e.Err != nil {
+		body["debug"] = e.Err.Error()
+	}
+	return body
+}
+
+// sendError classifies err and writes the standard JSON envelope.
+func (a *App) sendError(c fiberpkg.Ctx, err error) error {
+	e := Classify(err)
+	status := e.HTTPStatus
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	return c.Status(status).JSON(a.errorBody(c, e))
+}
@@ -0,0 +1,43 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/routing/kit"
+)
+
+func TestClassify(t *testing.T) {
+	cause := errors.New("no rows")
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		status int
+	}{
+		{"gospa error", fmt.Errorf("load: %w", ErrNotFound.Wrap(cause)), CodeNotFound, http.StatusNotFound},
+		{"validation", &routing.ValidationError{FormError: "bad"}, CodeValidationFailed, http.StatusUnprocessableEntity},
+		{"kit error", kit.Error(http.StatusForbidden, "nope"), "FORBIDDEN", http.StatusForbidden},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), CodeTimeout, http.StatusGatewayTimeout},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), CodeCanceled, statusClientClosedRequest},
+		{"plain", cause, CodeInternal, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if got.Code != tt.code || got.HTTPStatus != tt.status {
+				t.Fatalf("Classify() = %s/%d, want %s/%d", got.Code, got.HTTPStatus, tt.code, tt.status)
+			}
+		})
+	}
+	if Classify(nil) != nil {
+		t.Fatal("Classify(nil) should be nil")
+	}
+	if !errors.Is(ErrNotFound.Wrap(cause), ErrNotFound) || !errors.Is(ErrNotFound.Wrap(cause), cause) {
+		t.Fatal("wrapped error should match its code and its cause")
+	}
+}
@@ -16,14 +16,16 @@ func main() {
 		Addr: "localhost:6379",
 	})
 
-	app := gospa.New(gospa.Config{
+	app, err := gospa.NewFromEnv(gospa.Config{
 		RoutesDir: "./routes",
-		DevMode:   true,
 		AppName:   "counter",
 		Prefork:   true,
 		Storage:   redis.NewStore(rdb),
 		PubSub:    redis.NewPubSub(rdb),
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if err := app.Run(":3000"); err != nil {
 		log.Fatal(err)
@@ -10,11 +10,15 @@ import (
 )
 
 func main() {
-	app := gospa.New(gospa.Config{
+	// DevMode comes from the environment: gospa.yaml defaults to development,
+	// GOSPA_ENV=production overrides it.
+	app, err := gospa.NewFromEnv(gospa.Config{
 		RoutesDir: "./routes",
-		DevMode:   true,
 		AppName:   "counter",
 	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if err := app.Run(":3000"); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,44 @@
+package fiber
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextActionHandler handles a WebSocket action like ActionHandler, but on
+// its own goroutine and with a context that is canceled when the client sends
+// a "cancel" frame naming the action's request ID or disconnects. ActionID
+// returns that request ID. A returned *AppError is sent to the client with
+// its code.
+type ContextActionHandler func(ctx context.Context, client *WSClient, payload interface{}) error
+
+var (
+	contextActionHandlers  = make(map[string]ContextActionHandler)
+	contextActionHandlerMu sync.RWMutex
+)
+
+// RegisterContextActionHandler registers a cancellable action handler. It
+// takes precedence over an ActionHandler registered under the same name.
+func RegisterContextActionHandler(name string, handler ContextActionHandler) {
+	contextActionHandlerMu.Lock()
+	defer contextActionHandlerMu.Unlock()
+	contextActionHandlers[name] = handler
+}
+
+// GetContextActionHandler retrieves a cancellable action handler.
+func GetContextActionHandler(name string) (ContextActionHandler, bool) {
+	contextActionHandlerMu.RLock()
+	defer contextActionHandlerMu.RUnlock()
+	handler, ok := contextActionHandlers[name]
+	return handler, ok
+}
+
+type actionIDKey struct{}
+
+// ActionID returns the request ID of the action a ContextActionHandler's ctx
+// belongs to, for WSClient.Progress, or "" when the client sent the action
+// without one.
+func ActionID(ctx context.Context) string {
+	id, _ := ctx.Value(actionIDKey{}).(string)
+	return id
+}
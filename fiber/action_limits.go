@@ -0,0 +1,124 @@
+package fiber
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ActionOptions bounds how the handler of one action runs, so a slow or stuck
+// handler cannot pile up goroutines. Calls over a limit are rejected with an
+// ErrorCodeLimitExceeded error; calls are counted until their handler
+// returns, even after a timeout was reported to the client.
+type ActionOptions struct {
+	// MaxConcurrentPerClient bounds the calls one connection may have
+	// running (0 = no limit).
+	MaxConcurrentPerClient int
+	// MaxConcurrentGlobal bounds the calls running on this process across
+	// all connections (0 = no limit).
+	MaxConcurrentGlobal int
+	// Timeout answers the call with an ErrorCodeTimeout error once it has
+	// run this long (0 = none). A ContextActionHandler's context is canceled
+	// then; an ActionHandler runs on its own goroutine and is left to finish.
+	Timeout time.Duration
+}
+
+// actionLimiter enforces the ActionOptions of one action.
+type actionLimiter struct {
+	ActionOptions
+	running atomic.Int64
+}
+
+var (
+	actionLimiters  = make(map[string]*actionLimiter)
+	actionLimiterMu sync.RWMutex
+)
+
+// RegisterActionOptions sets the limits of action, replacing earlier ones.
+// Calls already running keep counting against the limits they started under.
+func RegisterActionOptions(action string, opts ActionOptions) {
+	actionLimiterMu.Lock()
+	defer actionLimiterMu.Unlock()
+	actionLimiters[action] = &actionLimiter{ActionOptions: opts}
+}
+
+// getActionLimiter returns the limiter of action, or nil without options.
+func getActionLimiter(action string) *actionLimiter {
+	actionLimiterMu.RLock()
+	defer actionLimiterMu.RUnlock()
+	return actionLimiters[action]
+}
+
+// timeout returns the action's timeout; l may be nil.
+func (l *actionLimiter) timeout() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.Timeout
+}
+
+// acquire reserves a call of action for client. It returns the function that
+// releases it, or the error reply when a limit is reached. l may be nil.
+func (l *actionLimiter) acquire(client *WSClient, action string) (func(), map[string]interface{}) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if n := l.running.Add(1); l.MaxConcurrentGlobal > 0 && n > int64(l.MaxConcurrentGlobal) {
+		l.running.Add(-1)
+		return nil, actionLimitError(action, "global", l.MaxConcurrentGlobal)
+	}
+	client.actionMu.Lock()
+	if l.MaxConcurrentPerClient > 0 && client.actionsRunning[action] >= l.MaxConcurrentPerClient {
+		client.actionMu.Unlock()
+		l.running.Add(-1)
+		return nil, actionLimitError(action, "client", l.MaxConcurrentPerClient)
+	}
+	if client.actionsRunning == nil {
+		client.actionsRunning = make(map[string]int)
+	}
+	client.actionsRunning[action]++
+	client.actionMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			client.actionMu.Lock()
+			if client.actionsRunning[action]--; client.actionsRunning[action] <= 0 {
+				delete(client.actionsRunning, action)
+			}
+			client.actionMu.Unlock()
+			l.running.Add(-1)
+		})
+	}, nil
+}
+
+// actionLimitError is the reply to a call over a concurrency limit. scope is
+// "client" or "global".
+func actionLimitError(action, scope string, limit int) map[string]interface{} {
+	reply := wsError(ErrorCodeLimitExceeded, "Too many concurrent calls of action "+action+" ("+scope+" limit "+strconv.Itoa(limit)+")")
+	reply["action"] = action
+	reply["scope"] = scope
+	reply["limit"] = limit
+	return reply
+}
+
+// runActionWithTimeout runs an ActionHandler on its own goroutine and calls
+// done with ErrorCodeTimeout if it has not returned after timeout, or with ""
+// once it returns in time. release is called when it returns either way.
+func runActionWithTimeout(client *WSClient, handler ActionHandler, payload interface{}, timeout time.Duration, release func(), done func(ErrorCode)) {
+	finished := make(chan struct{})
+	go func() {
+		defer release()
+		defer close(finished)
+		handler(client, payload)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-finished:
+		done("")
+	case <-timer.C:
+		done(ErrorCodeTimeout)
+	}
+}
@@ -0,0 +1,84 @@
+package fiber
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionOptionsConcurrencyLimits(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+	RegisterContextActionHandler("test-limits-report", func(ctx context.Context, _ *WSClient, _ interface{}) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	RegisterActionOptions("test-limits-report", ActionOptions{MaxConcurrentPerClient: 1, MaxConcurrentGlobal: 2})
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	a := newTopicTestClient(hub, "a", nil)
+	b := newTopicTestClient(hub, "b", nil)
+	c := newTopicTestClient(hub, "c", nil)
+	action := WSMessage{Type: "action", Action: "test-limits-report"}
+
+	DefaultMessageHandler(a, action)
+	<-started
+	DefaultMessageHandler(a, action)
+	if s := nextFrame(t, a); !strings.Contains(s, `"LIMIT_EXCEEDED"`) || !strings.Contains(s, `"scope":"client"`) {
+		t.Fatalf("second call from the same client = %s", s)
+	}
+	DefaultMessageHandler(b, action)
+	<-started
+	DefaultMessageHandler(c, action)
+	if s := nextFrame(t, c); !strings.Contains(s, `"LIMIT_EXCEEDED"`) || !strings.Contains(s, `"scope":"global"`) {
+		t.Fatalf("call over the global limit = %s", s)
+	}
+
+	close(release)
+	for _, client := range []*WSClient{a, b} {
+		if s := nextFrame(t, client); !strings.Contains(s, `"action_ack"`) {
+			t.Fatalf("finished call replied %s", s)
+		}
+	}
+	// The slots are free again.
+	DefaultMessageHandler(c, action)
+	<-started
+	if s := nextFrame(t, c); !strings.Contains(s, `"action_ack"`) {
+		t.Fatalf("call after the others finished = %s", s)
+	}
+}
+
+func TestActionOptionsTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	RegisterActionHandler("test-timeout-plain", func(*WSClient, interface{}) {
+		<-unblock
+	})
+	RegisterContextActionHandler("test-timeout-ctx", func(ctx context.Context, _ *WSClient, _ interface{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	opts := ActionOptions{Timeout: 20 * time.Millisecond, MaxConcurrentGlobal: 1}
+	RegisterActionOptions("test-timeout-plain", opts)
+	RegisterActionOptions("test-timeout-ctx", opts)
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c", nil)
+
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test-timeout-plain"})
+	if s := nextFrame(t, client); !strings.Contains(s, `"TIMEOUT"`) {
+		t.Fatalf("stuck handler replied %s", s)
+	}
+	// The stuck call still holds the only slot.
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test-timeout-plain"})
+	if s := nextFrame(t, client); !strings.Contains(s, `"LIMIT_EXCEEDED"`) {
+		t.Fatalf("call while the stuck one runs = %s", s)
+	}
+	close(unblock)
+
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test-timeout-ctx"})
+	if s := nextFrame(t, client); !strings.Contains(s, `"TIMEOUT"`) {
+		t.Fatalf("context handler past its timeout replied %s", s)
+	}
+}
@@ -0,0 +1,207 @@
+package fiber
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// CacheStatusHeader reports whether a response came from the cache ("HIT"),
+// was stored ("MISS"), or skipped it ("BYPASS").
+const CacheStatusHeader = "X-Cache"
+
+// CacheConfig configures CacheMiddleware.
+type CacheConfig struct {
+	// Storage holds cached responses. Use a shared backend (e.g. Redis) to
+	// share the cache across instances. Default: a new in-memory storage.
+	Storage store.Storage
+	// TTL is how long a response stays cached (default 1 minute).
+	TTL time.Duration
+	// KeyPrefix namespaces cache keys in Storage (default "gospa:cache:").
+	KeyPrefix string
+	// Vary lists request headers whose values are part of the cache key,
+	// e.g. "Accept-Language".
+	Vary []string
+	// BySession caches separately per GoSPA session. Requests without a valid
+	// session cookie are not cached.
+	BySession bool
+	// KeyFunc adds a caller-defined component to the key, such as a user ID.
+	// Returning "" skips the cache for that request.
+	KeyFunc func(c gofiber.Ctx) string
+	// Bypass skips the cache when it returns true, e.g. for admins or
+	// requests carrying an Authorization header.
+	Bypass func(c gofiber.Ctx) bool
+	// StatusCodes lists the response statuses that are cached (default 200).
+	StatusCodes []int
+	// MaxBodySize caps the size of a cached body in bytes (default 1MB).
+	MaxBodySize int
+}
+
+// DefaultCacheConfig returns the default response cache configuration.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		TTL:         time.Minute,
+		KeyPrefix:   "gospa:cache:",
+		StatusCodes: []int{gofiber.StatusOK},
+		MaxBodySize: 1 << 20,
+	}
+}
+
+// cachedResponse is a response as kept in Storage.
+type cachedResponse struct {
+	Status  int         `json:"s"`
+	Headers [][2]string `json:"h"`
+	Body    []byte      `json:"b"`
+}
+
+// CacheMiddleware caches GET and HEAD responses in Storage for TTL. The key
+// covers the method, path, query string, the Vary headers, and optionally the
+// session and KeyFunc result. Streamed responses, responses that set cookies,
+// and responses with Cache-Control no-store or no-cache are not stored, nor
+// are Cache-Control private responses unless the key is per user. Requests
+// with Cache-Control no-cache or no-store skip the cache.
+//
+//	app.Get("/api/products", fiber.CacheMiddleware(fiber.CacheConfig{TTL: 30 * time.Second}), listProducts)
+func CacheMiddleware(config CacheConfig) gofiber.Handler {
+	defaults := DefaultCacheConfig()
+	if config.Storage == nil {
+		config.Storage = store.NewMemoryStorage()
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = defaults.KeyPrefix
+	}
+	if len(config.StatusCodes) == 0 {
+		config.StatusCodes = defaults.StatusCodes
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaults.MaxBodySize
+	}
+	cacheable := make(map[int]struct{}, len(config.StatusCodes))
+	for _, code := range config.StatusCodes {
+		cacheable[code] = struct{}{}
+	}
+
+	return func(c gofiber.Ctx) error {
+		method := c.Method()
+		if method != gofiber.MethodGet && method != gofiber.MethodHead {
+			return c.Next()
+		}
+		key, ok := cacheKey(c, &config)
+		if !ok || requestSkipsCache(c) || (config.Bypass != nil && config.Bypass(c)) {
+			c.Set(CacheStatusHeader, "BYPASS")
+			return c.Next()
+		}
+
+		ctx := context.Background()
+		if data, err := config.Storage.Get(ctx, key); err == nil {
+			var entry cachedResponse
+			if json.Unmarshal(data, &entry) == nil {
+				// Replace headers earlier middleware already set; repeat the rest.
+				seen := make(map[string]struct{}, len(entry.Headers))
+				for _, h := range entry.Headers {
+					if _, ok := seen[h[0]]; ok {
+						c.Response().Header.Add(h[0], h[1])
+						continue
+					}
+					seen[h[0]] = struct{}{}
+					c.Set(h[0], h[1])
+				}
+				c.Set(CacheStatusHeader, "HIT")
+				c.Status(entry.Status)
+				return c.Send(entry.Body)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.IsBodyStream() {
+			return nil
+		}
+		if _, ok := cacheable[resp.StatusCode()]; !ok || !responseCacheable(resp, config.BySession || config.KeyFunc != nil) {
+			return nil
+		}
+		body := resp.Body()
+		if len(body) > config.MaxBodySize {
+			return nil
+		}
+		entry := cachedResponse{Status: resp.StatusCode(), Body: body}
+		for k, v := range resp.Header.All() {
+			name := string(k)
+			if strings.EqualFold(name, CacheStatusHeader) || strings.EqualFold(name, "Content-Length") || strings.EqualFold(name, "Date") {
+				continue
+			}
+			entry.Headers = append(entry.Headers, [2]string{name, string(v)})
+		}
+		data, err := json.Marshal(entry)
+		if err == nil {
+			_ = config.Storage.Set(ctx, key, data, config.TTL)
+		}
+		c.Set(CacheStatusHeader, "MISS")
+		return nil
+	}
+}
+
+// cacheKey builds the Storage key for the request. It reports false when the
+// request must not be cached (no session or an empty KeyFunc result).
+func cacheKey(c gofiber.Ctx, config *CacheConfig) (string, bool) {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(strconv.Itoa(len(s))))
+		h.Write([]byte{':'})
+		h.Write([]byte(s))
+	}
+	write(c.Method())
+	write(c.Path())
+	write(string(c.Request().URI().QueryString()))
+	for _, name := range config.Vary {
+		write(c.Get(name))
+	}
+	if config.BySession {
+		clientID, ok := requestStateStores(c).Sessions.ValidateSession(c.Cookies("gospa_session"))
+		if !ok {
+			return "", false
+		}
+		write(clientID)
+	}
+	if config.KeyFunc != nil {
+		part := config.KeyFunc(c)
+		if part == "" {
+			return "", false
+		}
+		write(part)
+	}
+	return config.KeyPrefix + hex.EncodeToString(h.Sum(nil)), true
+}
+
+// requestSkipsCache reports whether the client asked for a fresh response.
+func requestSkipsCache(c gofiber.Ctx) bool {
+	cc := strings.ToLower(c.Get("Cache-Control"))
+	return strings.Contains(cc, "no-cache") || strings.Contains(cc, "no-store")
+}
+
+// responseCacheable reports whether the handler allowed its response to be
+// stored. private is only honored for per-user keys.
+func responseCacheable(resp *fasthttp.Response, perUser bool) bool {
+	for range resp.Header.Cookies() {
+		return false
+	}
+	cc := strings.ToLower(string(resp.Header.Peek("Cache-Control")))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "no-cache") {
+		return false
+	}
+	return perUser || !strings.Contains(cc, "private")
+}
@@ -0,0 +1,104 @@
+package fiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	app := gofiber.New()
+	calls := 0
+	handler := func(c gofiber.Ctx) error {
+		calls++
+		return c.JSON(gofiber.Map{"n": calls})
+	}
+	mw := CacheMiddleware(CacheConfig{Vary: []string{"Accept-Language"}})
+	app.Get("/api", mw, handler)
+	app.Get("/private", mw, func(c gofiber.Ctx) error {
+		calls++
+		c.Set("Cache-Control", "private")
+		return c.SendString(strconv.Itoa(calls))
+	})
+
+	get := func(path string, headers map[string]string) (string, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.Header.Get(CacheStatusHeader), string(body)
+	}
+
+	if status, body := get("/api", nil); status != "MISS" || body != `{"n":1}` {
+		t.Fatalf("first request = %s %s", status, body)
+	}
+	if status, body := get("/api", nil); status != "HIT" || body != `{"n":1}` {
+		t.Fatalf("second request = %s %s", status, body)
+	}
+	if status, _ := get("/api?page=2", nil); status != "MISS" {
+		t.Fatalf("query string not in key, got %s", status)
+	}
+	if status, _ := get("/api", map[string]string{"Accept-Language": "de"}); status != "MISS" {
+		t.Fatalf("Vary header not in key, got %s", status)
+	}
+	if status, body := get("/api", map[string]string{"Cache-Control": "no-cache"}); status != "BYPASS" || body != `{"n":4}` {
+		t.Fatalf("no-cache request = %s %s", status, body)
+	}
+	get("/private", nil)
+	if status, _ := get("/private", nil); status == "HIT" {
+		t.Fatal("shared cache stored a private response")
+	}
+}
+
+func TestCacheMiddlewareBySession(t *testing.T) {
+	app := gofiber.New()
+	calls := 0
+	app.Get("/me", CacheMiddleware(CacheConfig{BySession: true}), func(c gofiber.Ctx) error {
+		calls++
+		return c.SendString(strconv.Itoa(calls))
+	})
+
+	tokenA, err := globalSessionStore.CreateSession("client-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenB, err := globalSessionStore.CreateSession("client-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(token string) string {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/me", nil)
+		if token != "" {
+			req.Header.Set("Cookie", "gospa_session="+token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.Header.Get(CacheStatusHeader) + " " + string(body)
+	}
+
+	if got := get(tokenA); got != "MISS 1" {
+		t.Fatalf("client a = %q", got)
+	}
+	if got := get(tokenB); got != "MISS 2" {
+		t.Fatalf("client b = %q", got)
+	}
+	if got := get(tokenA); got != "HIT 1" {
+		t.Fatalf("client a again = %q", got)
+	}
+	if got := get(""); got != "BYPASS 3" {
+		t.Fatalf("no session = %q", got)
+	}
+}
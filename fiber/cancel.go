@@ -0,0 +1,202 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// maxClientCalls bounds the cancellable calls one connection or session may
+// have running at once.
+const maxClientCalls = 64
+
+var (
+	// errTooManyCalls is returned by callRegistry.track when the owner is at
+	// maxClientCalls.
+	errTooManyCalls = errors.New("gospa: too many calls in flight")
+	// errCallInFlight is returned by callRegistry.track when the owner already
+	// has a running call with the request ID.
+	errCallInFlight = errors.New("gospa: request ID already in flight")
+)
+
+// callRegistry tracks the contexts of running calls by owner and request ID,
+// so a "cancel" frame naming the request can cancel them.
+type callRegistry struct {
+	mu    sync.Mutex
+	calls map[string]map[string]*trackedCall
+}
+
+// trackedCall is a running call.
+type trackedCall struct {
+	cancel context.CancelFunc
+}
+
+// track returns a context derived from parent that cancel(owner, id) or
+// cancelAll(owner) cancels, and the function to call once the call returns.
+func (r *callRegistry) track(parent context.Context, owner, id string) (context.Context, func(), error) {
+	ctx, cancel := context.WithCancel(parent)
+	call := &trackedCall{cancel: cancel}
+	r.mu.Lock()
+	if r.calls == nil {
+		r.calls = make(map[string]map[string]*trackedCall)
+	}
+	calls := r.calls[owner]
+	if _, ok := calls[id]; ok {
+		r.mu.Unlock()
+		cancel()
+		return nil, nil, errCallInFlight
+	}
+	if len(calls) >= maxClientCalls {
+		r.mu.Unlock()
+		cancel()
+		return nil, nil, errTooManyCalls
+	}
+	if calls == nil {
+		calls = make(map[string]*trackedCall)
+		r.calls[owner] = calls
+	}
+	calls[id] = call
+	r.mu.Unlock()
+	return ctx, func() {
+		r.mu.Lock()
+		// cancelAll may have dropped the owner's calls already.
+		if r.calls[owner][id] == call {
+			delete(r.calls[owner], id)
+			if len(r.calls[owner]) == 0 {
+				delete(r.calls, owner)
+			}
+		}
+		r.mu.Unlock()
+		cancel()
+	}, nil
+}
+
+// cancel cancels owner's call id and reports whether it was running.
+func (r *callRegistry) cancel(owner, id string) bool {
+	r.mu.Lock()
+	call, ok := r.calls[owner][id]
+	r.mu.Unlock()
+	if ok {
+		call.cancel()
+	}
+	return ok
+}
+
+// cancelAll cancels every call of owner.
+func (r *callRegistry) cancelAll(owner string) {
+	r.mu.Lock()
+	calls := r.calls[owner]
+	delete(r.calls, owner)
+	r.mu.Unlock()
+	for _, call := range calls {
+		call.cancel()
+	}
+}
+
+// sessionCallOwner is the callRegistry owner of HTTP calls made with the
+// session token.
+func sessionCallOwner(token string) string {
+	return "session:" + token
+}
+
+// TrackSessionCall returns a context derived from parent that is canceled
+// when a connection opened with the same gospa_session cookie sends a
+// "cancel" frame naming requestID, e.g. for a remote action the browser
+// called over HTTP. The returned function releases it once the call has
+// returned. Without a token or request ID it returns parent unchanged.
+func (h *WSHub) TrackSessionCall(parent context.Context, sessionToken, requestID string) (context.Context, func()) {
+	if h == nil || sessionToken == "" || requestID == "" {
+		return parent, func() {}
+	}
+	ctx, done, err := h.calls.track(parent, sessionCallOwner(sessionToken), requestID)
+	if err != nil {
+		// Runs without cancellation rather than failing the call.
+		return parent, func() {}
+	}
+	return ctx, done
+}
+
+// runContextAction starts handler for an "action" frame with request ID id.
+// Actions sent without one cannot be canceled but still run until the client
+// disconnects or timeout, if set, passes. done is called with the error code
+// once the handler returned.
+func runContextAction(client *WSClient, handler ContextActionHandler, id string, payload interface{}, timeout time.Duration, sendResponse func(map[string]interface{}), done func(ErrorCode)) {
+	parent := context.Background()
+	if id == "" {
+		id = generateComponentID()
+	} else {
+		parent = context.WithValue(parent, actionIDKey{}, id)
+	}
+	ctx, release, err := client.calls.track(parent, "", id)
+	if errors.Is(err, errCallInFlight) {
+		done(ErrorCodeInvalidPayload)
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Request ID already in flight"))
+		return
+	}
+	if err != nil {
+		done(ErrorCodeLimitExceeded)
+		sendResponse(wsError(ErrorCodeLimitExceeded, "Too many actions in flight"))
+		return
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		inner := release
+		release = func() {
+			cancel()
+			inner()
+		}
+	}
+	go func() {
+		defer release()
+		err := handler(ctx, client, payload)
+		var appErr *AppError
+		switch {
+		case err == nil:
+			done("")
+			sendResponse(map[string]interface{}{"type": "action_ack"})
+		case errors.As(err, &appErr):
+			done(appErr.Code)
+			sendResponse(wsError(appErr.Code, appErr.Message))
+		case errors.Is(ctx.Err(), context.DeadlineExceeded) && errors.Is(err, context.DeadlineExceeded):
+			done(ErrorCodeTimeout)
+			sendResponse(wsError(ErrorCodeTimeout, "Action timed out"))
+		case ctx.Err() != nil && errors.Is(err, context.Canceled):
+			done(ErrorCodeCanceled)
+			sendResponse(wsError(ErrorCodeCanceled, "Action canceled"))
+		default:
+			slog.Default().Error("ws action failed", "client", client.ID, "request_id", client.RequestID, "err", err)
+			done(ErrorCodeInternal)
+			sendResponse(wsError(ErrorCodeInternal, "Action failed"))
+		}
+	}()
+}
+
+// cancelRequest is the payload of a client "cancel" frame.
+type cancelRequest struct {
+	RequestID string `json:"requestId"`
+}
+
+// handleCancelMessage processes a client "cancel" frame: it cancels the
+// client's action, or its session's HTTP call, with the given request ID.
+func handleCancelMessage(client *WSClient, msg WSMessage, sendResponse func(map[string]interface{})) {
+	var req cancelRequest
+	b, ok := msg.Payload.([]byte)
+	if !ok {
+		b, _ = JSONMarshal(msg.Payload)
+	}
+	if err := JSONUnmarshal(b, &req); err != nil || !ValidRequestID(req.RequestID) {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid cancel payload"))
+		return
+	}
+	canceled := client.calls.cancel("", req.RequestID)
+	if !canceled && client.hub != nil && client.sessionToken != "" {
+		canceled = client.hub.calls.cancel(sessionCallOwner(client.sessionToken), req.RequestID)
+	}
+	sendResponse(map[string]interface{}{
+		"type": "cancel",
+		"data": map[string]interface{}{"requestId": req.RequestID, "canceled": canceled},
+	})
+}
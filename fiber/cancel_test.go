@@ -0,0 +1,99 @@
+package fiber
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancelFrame sends a "cancel" frame for id and returns the response.
+func cancelFrame(client *WSClient, id string) map[string]interface{} {
+	var resp map[string]interface{}
+	handleCancelMessage(client, WSMessage{Type: "cancel", Payload: map[string]interface{}{"requestId": id}}, func(p map[string]interface{}) {
+		resp = p
+	})
+	return resp
+}
+
+func TestCancelContextAction(t *testing.T) {
+	started := make(chan struct{}, 1)
+	RegisterContextActionHandler("test-cancel-export", func(ctx context.Context, _ *WSClient, _ interface{}) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c1", nil)
+	action := WSMessage{Type: "action", Action: "test-cancel-export", Data: map[string]interface{}{"_requestId": "req_1"}}
+
+	// The read loop is free while the action runs.
+	DefaultMessageHandler(client, action)
+	<-started
+	DefaultMessageHandler(client, action)
+	if s := nextFrame(t, client); !strings.Contains(s, string(ErrorCodeInvalidPayload)) {
+		t.Fatalf("second action with the same request ID = %s", s)
+	}
+
+	if resp := cancelFrame(client, "req_1"); resp["data"].(map[string]interface{})["canceled"] != true {
+		t.Fatalf("cancel = %v", resp)
+	}
+	if s := nextFrame(t, client); !strings.Contains(s, `"CANCELED"`) || !strings.Contains(s, `"req_1"`) {
+		t.Fatalf("canceled action replied %s", s)
+	}
+	if resp := cancelFrame(client, "req_1"); resp["data"].(map[string]interface{})["canceled"] != false {
+		t.Fatalf("cancel of a finished action = %v", resp)
+	}
+	if resp := cancelFrame(client, "bad id"); resp["type"] != "error" {
+		t.Fatalf("cancel with an invalid ID = %v", resp)
+	}
+
+	// Disconnecting cancels what is still running.
+	DefaultMessageHandler(client, action)
+	<-started
+	client.calls.mu.Lock()
+	running := len(client.calls.calls[""])
+	client.calls.mu.Unlock()
+	if running != 1 {
+		t.Fatalf("%d calls tracked, want 1", running)
+	}
+	client.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		client.calls.mu.Lock()
+		running = len(client.calls.calls)
+		client.calls.mu.Unlock()
+		if running == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("action kept running after the client disconnected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCancelSessionCall(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	ctx, done := hub.TrackSessionCall(context.Background(), "token-a", "call-1")
+	defer done()
+
+	other := newTopicTestClient(hub, "other", nil)
+	other.sessionToken = "token-b"
+	if resp := cancelFrame(other, "call-1"); resp["data"].(map[string]interface{})["canceled"] != false || ctx.Err() != nil {
+		t.Fatal("a connection of another session canceled the call")
+	}
+	same := newTopicTestClient(hub, "same", nil)
+	same.sessionToken = "token-a"
+	if resp := cancelFrame(same, "call-1"); resp["data"].(map[string]interface{})["canceled"] != true || ctx.Err() == nil {
+		t.Fatal("the session's connection could not cancel the call")
+	}
+
+	if ctx, done := hub.TrackSessionCall(context.Background(), "", "call-2"); ctx != context.Background() {
+		t.Fatal("a call without a session token was tracked")
+	} else {
+		done()
+	}
+}
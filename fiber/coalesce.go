@@ -0,0 +1,132 @@
+package fiber
+
+import (
+	"bytes"
+	"time"
+)
+
+// syncUpdate is a pre-parsed "sync" broadcast that can be merged into a
+// per-client patch instead of being sent as its own frame.
+type syncUpdate struct {
+	key     string
+	value   interface{}
+	version uint64
+	// seq is the session resume sequence number, 0 if untracked.
+	seq uint64
+}
+
+// parseSyncMessage extracts the state key and value from a "sync" broadcast.
+// Messages scoped to a request (success acks) or carrying extra fields the
+// patch format cannot express are not coalesced.
+func parseSyncMessage(message []byte) (*syncUpdate, bool) {
+	if !bytes.Contains(message, []byte(`"sync"`)) {
+		return nil, false
+	}
+	var msg struct {
+		Type        string      `json:"type"`
+		ComponentID string      `json:"componentId"`
+		Key         string      `json:"key"`
+		Value       interface{} `json:"value"`
+		Version     uint64      `json:"version"`
+		Seq         uint64      `json:"seq"`
+	}
+	if err := JSONUnmarshal(message, &msg); err != nil || msg.Type != "sync" || msg.Key == "" {
+		return nil, false
+	}
+	key := msg.Key
+	if msg.ComponentID != "" {
+		key = msg.ComponentID + "." + msg.Key
+	}
+	return &syncUpdate{key: key, value: msg.Value, version: msg.Version, seq: msg.Seq}, true
+}
+
+// deliver queues message for the client, merging sync updates into a pending
+// patch when coalescing is enabled. update may be nil, in which case message
+// is inspected here.
+func (c *WSClient) deliver(message []byte, update *syncUpdate) {
+	if c.coalesceInterval <= 0 {
+		c.trySend(message)
+		return
+	}
+	if update == nil {
+		if parsed, ok := parseSyncMessage(message); ok {
+			update = parsed
+		}
+	}
+	if update == nil {
+		// Preserve ordering: anything pending goes out before a non-sync frame.
+		c.flushCoalesced()
+		c.trySend(message)
+		return
+	}
+
+	c.coalesceMu.Lock()
+	if c.pendingPatch == nil {
+		c.pendingPatch = make(map[string]interface{})
+	}
+	c.pendingPatch[update.key] = update.value
+	if update.version > 0 {
+		if c.pendingVersions == nil {
+			c.pendingVersions = make(map[string]uint64)
+		}
+		c.pendingVersions[update.key] = update.version
+	}
+	c.pendingCount++
+	c.pendingSeq = max(c.pendingSeq, update.seq)
+	if c.coalesceTimer == nil {
+		c.coalesceTimer = time.AfterFunc(c.coalesceInterval, c.flushCoalesced)
+	}
+	c.coalesceMu.Unlock()
+}
+
+// flushCoalesced sends all pending updates as a single "patch" frame.
+func (c *WSClient) flushCoalesced() {
+	c.coalesceMu.Lock()
+	patch := c.pendingPatch
+	versions := c.pendingVersions
+	count := c.pendingCount
+	seq := c.pendingSeq
+	c.pendingPatch = nil
+	c.pendingVersions = nil
+	c.pendingCount = 0
+	c.pendingSeq = 0
+	if c.coalesceTimer != nil {
+		c.coalesceTimer.Stop()
+		c.coalesceTimer = nil
+	}
+	c.coalesceMu.Unlock()
+
+	if len(patch) == 0 {
+		return
+	}
+	frame := map[string]interface{}{
+		"type":      "patch",
+		"patch":     patch,
+		"coalesced": count,
+	}
+	if len(versions) > 0 {
+		frame["versions"] = versions
+	}
+	if seq > 0 {
+		frame["seq"] = seq
+	}
+	data, err := c.Marshal(frame)
+	if err != nil {
+		return
+	}
+	c.trySend(data)
+}
+
+// trySend queues data on the Send channel without blocking.
+func (c *WSClient) trySend(data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.Send <- data:
+	default:
+		// Client buffer full, skip to maintain real-time pressure
+	}
+}
@@ -0,0 +1,77 @@
+package fiber
+
+import (
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+func newCoalescingTestClient(interval time.Duration) *WSClient {
+	return NewWSClient("test", nil, WebSocketConfig{CoalesceInterval: interval})
+}
+
+func readFrame(t *testing.T, c *WSClient) map[string]interface{} {
+	t.Helper()
+	select {
+	case data := <-c.Send:
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("unmarshal frame: %v", err)
+		}
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for frame")
+	}
+	return nil
+}
+
+func TestCoalescedSyncMergesIntoSinglePatch(t *testing.T) {
+	c := newCoalescingTestClient(20 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		msg, _ := json.Marshal(map[string]interface{}{"type": "sync", "componentId": "slider", "key": "value", "value": i})
+		c.deliver(msg, nil)
+	}
+	msg, _ := json.Marshal(map[string]interface{}{"type": "sync", "key": "title", "value": "hi"})
+	c.deliver(msg, nil)
+
+	frame := readFrame(t, c)
+	if frame["type"] != "patch" {
+		t.Fatalf("expected patch frame, got %v", frame["type"])
+	}
+	patch := frame["patch"].(map[string]interface{})
+	if patch["slider.value"] != float64(4) || patch["title"] != "hi" {
+		t.Fatalf("unexpected patch contents: %v", patch)
+	}
+	if frame["coalesced"] != float64(6) {
+		t.Fatalf("expected 6 coalesced updates, got %v", frame["coalesced"])
+	}
+	select {
+	case extra := <-c.Send:
+		t.Fatalf("expected a single frame, got extra %s", extra)
+	default:
+	}
+}
+
+func TestCoalescingFlushesBeforeOtherMessages(t *testing.T) {
+	c := newCoalescingTestClient(time.Hour)
+	sync, _ := json.Marshal(map[string]interface{}{"type": "sync", "key": "count", "value": 1})
+	c.deliver(sync, nil)
+	c.deliver([]byte(`{"type":"error","error":"x"}`), nil)
+
+	if frame := readFrame(t, c); frame["type"] != "patch" {
+		t.Fatalf("expected pending patch to flush first, got %v", frame["type"])
+	}
+	if frame := readFrame(t, c); frame["type"] != "error" {
+		t.Fatalf("expected error frame second, got %v", frame["type"])
+	}
+}
+
+func TestDeliverWithoutCoalescingPassesThrough(t *testing.T) {
+	c := newCoalescingTestClient(0)
+	sync := []byte(`{"type":"sync","key":"count","value":1}`)
+	c.deliver(sync, nil)
+	if frame := readFrame(t, c); frame["type"] != "sync" {
+		t.Fatalf("expected raw sync frame, got %v", frame["type"])
+	}
+}
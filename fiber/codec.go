@@ -0,0 +1,63 @@
+package fiber
+
+import (
+	"sync/atomic"
+
+	json "github.com/goccy/go-json"
+)
+
+// JSONMarshalFunc encodes v as JSON.
+type JSONMarshalFunc func(v interface{}) ([]byte, error)
+
+// JSONUnmarshalFunc decodes JSON data into v.
+type JSONUnmarshalFunc func(data []byte, v interface{}) error
+
+type jsonCodec struct {
+	marshal   JSONMarshalFunc
+	unmarshal JSONUnmarshalFunc
+}
+
+var defaultJSONCodec = &jsonCodec{marshal: json.Marshal, unmarshal: json.Unmarshal}
+
+var activeJSONCodec atomic.Pointer[jsonCodec]
+
+func init() {
+	activeJSONCodec.Store(defaultJSONCodec)
+}
+
+// SetJSONCodec replaces the JSON codec used for WebSocket messages, state
+// sync, and request/state helpers in this package. Passing nil for either
+// function restores the goccy/go-json default for it.
+//
+// The codec must be compatible with encoding/json: the same struct tags,
+// json.RawMessage and json.Number handling, and Marshaler interfaces. It is
+// safe to call at any time, but is normally set once before the app starts.
+func SetJSONCodec(marshal JSONMarshalFunc, unmarshal JSONUnmarshalFunc) {
+	if marshal == nil && unmarshal == nil {
+		activeJSONCodec.Store(defaultJSONCodec)
+		return
+	}
+	if marshal == nil {
+		marshal = defaultJSONCodec.marshal
+	}
+	if unmarshal == nil {
+		unmarshal = defaultJSONCodec.unmarshal
+	}
+	activeJSONCodec.Store(&jsonCodec{marshal: marshal, unmarshal: unmarshal})
+}
+
+// HasCustomJSONCodec reports whether SetJSONCodec installed a non-default
+// codec.
+func HasCustomJSONCodec() bool {
+	return activeJSONCodec.Load() != defaultJSONCodec
+}
+
+// JSONMarshal encodes v with the configured codec.
+func JSONMarshal(v interface{}) ([]byte, error) {
+	return activeJSONCodec.Load().marshal(v)
+}
+
+// JSONUnmarshal decodes data into v with the configured codec.
+func JSONUnmarshal(data []byte, v interface{}) error {
+	return activeJSONCodec.Load().unmarshal(data, v)
+}
@@ -0,0 +1,40 @@
+package fiber
+
+import (
+	stdjson "encoding/json"
+	"testing"
+)
+
+func TestSetJSONCodec(t *testing.T) {
+	t.Cleanup(func() { SetJSONCodec(nil, nil) })
+
+	var marshals, unmarshals int
+	SetJSONCodec(func(v interface{}) ([]byte, error) {
+		marshals++
+		return stdjson.Marshal(v)
+	}, func(data []byte, v interface{}) error {
+		unmarshals++
+		return stdjson.Unmarshal(data, v)
+	})
+	if !HasCustomJSONCodec() {
+		t.Fatal("expected custom codec to be active")
+	}
+
+	client := &WSClient{}
+	data, err := client.Marshal(map[string]int{"a": 1})
+	if err != nil || string(data) != `{"a":1}` {
+		t.Fatalf("marshal = %s, %v", data, err)
+	}
+	var out map[string]int
+	if err := client.Unmarshal(data, &out); err != nil || out["a"] != 1 {
+		t.Fatalf("unmarshal = %v, %v", out, err)
+	}
+	if marshals != 1 || unmarshals != 1 {
+		t.Fatalf("codec calls = %d/%d, want 1/1", marshals, unmarshals)
+	}
+
+	SetJSONCodec(nil, nil)
+	if HasCustomJSONCodec() {
+		t.Fatal("nil codec should restore the default")
+	}
+}
@@ -191,15 +191,17 @@ func BrotliGzipMiddleware(config CompressionConfig) gofiber.Handler {
 			return nil
 		}
 
-		// Compress the response
+		// Compress the response. SetBody copies, so the buffer can be reused.
+		buf := acquireCompressBuffer()
+		defer releaseCompressBuffer(buf)
 		var compressed []byte
 		var encoding string
 
 		if useBrotli {
-			compressed = compressBrotli(body, &brotliWriterPool)
+			compressed = compressBrotli(body, &brotliWriterPool, buf)
 			encoding = "br"
 		} else if useGzip {
-			compressed = compressGzip(body, &gzipWriterPool)
+			compressed = compressGzip(body, &gzipWriterPool, buf)
 			encoding = "gzip"
 		}
 
@@ -221,13 +223,43 @@ func BrotliGzipMiddleware(config CompressionConfig) gofiber.Handler {
 	}
 }
 
-// compressBrotli compresses data using Brotli with writer pool.
-func compressBrotli(data []byte, pool *sync.Pool) []byte {
+// compressBufferPool recycles the buffers compressed output is written into.
+var compressBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// maxPooledCompressBuffer bounds the capacity of buffers returned to the pool.
+const maxPooledCompressBuffer = 4 << 20
+
+func acquireCompressBuffer() *bytes.Buffer {
+	buf := compressBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func releaseCompressBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledCompressBuffer {
+		compressBufferPool.Put(buf)
+	}
+}
+
+// defaultGzipWriterPool recycles gzip writers at the default compression
+// level, used for CompressState WebSocket payloads.
+var defaultGzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// compressBrotli compresses data into buf using Brotli with writer pool.
+// The result aliases buf.
+func compressBrotli(data []byte, pool *sync.Pool, buf *bytes.Buffer) []byte {
 	writer := pool.Get().(*brotli.Writer)
 	defer pool.Put(writer)
 
-	var buf bytes.Buffer
-	writer.Reset(&buf)
+	writer.Reset(buf)
 
 	_, err := writer.Write(data)
 	if err != nil {
@@ -242,13 +274,13 @@ func compressBrotli(data []byte, pool *sync.Pool) []byte {
 	return buf.Bytes()
 }
 
-// compressGzip compresses data using Gzip with writer pool.
-func compressGzip(data []byte, pool *sync.Pool) []byte {
+// compressGzip compresses data into buf using Gzip with writer pool.
+// The result aliases buf.
+func compressGzip(data []byte, pool *sync.Pool, buf *bytes.Buffer) []byte {
 	writer := pool.Get().(*gzip.Writer)
 	defer pool.Put(writer)
 
-	var buf bytes.Buffer
-	writer.Reset(&buf)
+	writer.Reset(buf)
 
 	_, err := writer.Write(data)
 	if err != nil {
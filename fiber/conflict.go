@@ -0,0 +1,158 @@
+package fiber
+
+import (
+	"sync"
+	"time"
+)
+
+// ConflictPolicy selects how concurrent updates to the same key are resolved
+// when no per-key merge function or resolver applies.
+type ConflictPolicy string
+
+const (
+	// ConflictLastWriteWins accepts the update with the newest client timestamp.
+	ConflictLastWriteWins ConflictPolicy = "lww"
+	// ConflictRejectStale rejects any update based on an outdated version.
+	ConflictRejectStale ConflictPolicy = "reject"
+)
+
+// StateConflict describes an update that was based on an outdated version of a key,
+// typically because another tab of the same session wrote it first.
+type StateConflict struct {
+	SessionID         string
+	Key               string
+	Current           interface{}
+	Incoming          interface{}
+	BaseVersion       uint64
+	CurrentVersion    uint64
+	CurrentTimestamp  int64
+	IncomingTimestamp int64
+}
+
+// ConflictResolver decides the outcome of a conflict. Returning accept=false
+// rejects the update and the client is resynced to the current value.
+type ConflictResolver func(conflict StateConflict) (value interface{}, accept bool)
+
+// MergeFunc deterministically merges a conflicting incoming value into the current one.
+type MergeFunc func(current, incoming interface{}) interface{}
+
+// ConflictConfig configures multi-tab conflict resolution. Per-key Mergers take
+// precedence over Resolver, which takes precedence over Policy.
+type ConflictConfig struct {
+	Policy   ConflictPolicy
+	Resolver ConflictResolver
+	Mergers  map[string]MergeFunc
+}
+
+// keyVersion is the last accepted write for a session-scoped key.
+type keyVersion struct {
+	version uint64
+	ts      int64
+	value   interface{}
+}
+
+// versionTracker keeps per-session key versions so updates from different
+// connections of the same session can be ordered.
+type versionTracker struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]keyVersion
+}
+
+func newVersionTracker() *versionTracker {
+	return &versionTracker{sessions: make(map[string]map[string]keyVersion)}
+}
+
+// conflictResult is the outcome of applying an update through the tracker.
+type conflictResult struct {
+	value    interface{}
+	version  uint64
+	accepted bool
+	conflict bool
+}
+
+// apply records an update for sessionID/key, resolving it against the last
+// accepted write when its base version is stale.
+func (t *versionTracker) apply(sessionID, key string, update WSStateUpdate, cfg *ConflictConfig) conflictResult {
+	ts := update.Timestamp
+	if ts == 0 {
+		ts = time.Now().UnixMilli()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys := t.sessions[sessionID]
+	if keys == nil {
+		keys = make(map[string]keyVersion)
+		t.sessions[sessionID] = keys
+	}
+	current, exists := keys[key]
+
+	// Unversioned updates (legacy clients) and up-to-date bases apply directly.
+	if !exists || update.Version == 0 || update.Version == current.version {
+		next := keyVersion{version: current.version + 1, ts: ts, value: update.Value}
+		keys[key] = next
+		return conflictResult{value: update.Value, version: next.version, accepted: true}
+	}
+
+	value, accepted := resolveConflict(StateConflict{
+		SessionID:         sessionID,
+		Key:               key,
+		Current:           current.value,
+		Incoming:          update.Value,
+		BaseVersion:       update.Version,
+		CurrentVersion:    current.version,
+		CurrentTimestamp:  current.ts,
+		IncomingTimestamp: ts,
+	}, cfg)
+	if !accepted {
+		return conflictResult{value: current.value, version: current.version, conflict: true}
+	}
+	if ts < current.ts {
+		ts = current.ts
+	}
+	next := keyVersion{version: current.version + 1, ts: ts, value: value}
+	keys[key] = next
+	return conflictResult{value: value, version: next.version, accepted: true, conflict: true}
+}
+
+func resolveConflict(conflict StateConflict, cfg *ConflictConfig) (interface{}, bool) {
+	policy := ConflictLastWriteWins
+	if cfg != nil {
+		if merge, ok := cfg.Mergers[conflict.Key]; ok && merge != nil {
+			return merge(conflict.Current, conflict.Incoming), true
+		}
+		if cfg.Resolver != nil {
+			return cfg.Resolver(conflict)
+		}
+		if cfg.Policy != "" {
+			policy = cfg.Policy
+		}
+	}
+	switch policy {
+	case ConflictRejectStale:
+		return conflict.Current, false
+	default:
+		if conflict.IncomingTimestamp >= conflict.CurrentTimestamp {
+			return conflict.Incoming, true
+		}
+		return conflict.Current, false
+	}
+}
+
+// current returns the latest accepted version for sessionID/key.
+func (t *versionTracker) current(sessionID, key string) uint64 {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessions[sessionID][key].version
+}
+
+// forget drops all versions for a session once its last connection is gone.
+func (t *versionTracker) forget(sessionID string) {
+	t.mu.Lock()
+	delete(t.sessions, sessionID)
+	t.mu.Unlock()
+}
@@ -0,0 +1,90 @@
+package fiber
+
+import "testing"
+
+func TestVersionTrackerAcceptsCurrentBase(t *testing.T) {
+	tracker := newVersionTracker()
+	first := tracker.apply("s1", "count", WSStateUpdate{Key: "count", Value: 1.0, Timestamp: 100}, nil)
+	if !first.accepted || first.version != 1 || first.conflict {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	second := tracker.apply("s1", "count", WSStateUpdate{Key: "count", Value: 2.0, Version: 1, Timestamp: 200}, nil)
+	if !second.accepted || second.version != 2 || second.conflict {
+		t.Fatalf("unexpected second result: %+v", second)
+	}
+	if got := tracker.current("s1", "count"); got != 2 {
+		t.Fatalf("expected version 2, got %d", got)
+	}
+	if got := tracker.current("s2", "count"); got != 0 {
+		t.Fatalf("sessions must not share versions, got %d", got)
+	}
+}
+
+func TestVersionTrackerLastWriteWins(t *testing.T) {
+	tracker := newVersionTracker()
+	tracker.apply("s1", "title", WSStateUpdate{Value: "a", Timestamp: 100}, nil)
+	tracker.apply("s1", "title", WSStateUpdate{Value: "b", Version: 1, Timestamp: 300}, nil)
+
+	// Stale base with an older timestamp loses.
+	older := tracker.apply("s1", "title", WSStateUpdate{Value: "c", Version: 1, Timestamp: 200}, nil)
+	if older.accepted || !older.conflict || older.value != "b" || older.version != 2 {
+		t.Fatalf("expected older write to be rejected, got %+v", older)
+	}
+
+	// Stale base with a newer timestamp wins.
+	newer := tracker.apply("s1", "title", WSStateUpdate{Value: "d", Version: 1, Timestamp: 400}, nil)
+	if !newer.accepted || !newer.conflict || newer.value != "d" || newer.version != 3 {
+		t.Fatalf("expected newer write to win, got %+v", newer)
+	}
+}
+
+func TestVersionTrackerRejectStale(t *testing.T) {
+	tracker := newVersionTracker()
+	cfg := &ConflictConfig{Policy: ConflictRejectStale}
+	tracker.apply("s1", "title", WSStateUpdate{Value: "a", Timestamp: 100}, cfg)
+	tracker.apply("s1", "title", WSStateUpdate{Value: "b", Version: 1, Timestamp: 200}, cfg)
+
+	res := tracker.apply("s1", "title", WSStateUpdate{Value: "c", Version: 1, Timestamp: 999}, cfg)
+	if res.accepted || res.value != "b" {
+		t.Fatalf("expected stale write to be rejected, got %+v", res)
+	}
+}
+
+func TestVersionTrackerMergeAndResolver(t *testing.T) {
+	tracker := newVersionTracker()
+	var resolved StateConflict
+	cfg := &ConflictConfig{
+		Policy: ConflictRejectStale,
+		Resolver: func(c StateConflict) (interface{}, bool) {
+			resolved = c
+			return "server", true
+		},
+		Mergers: map[string]MergeFunc{
+			"likes": func(current, incoming interface{}) interface{} {
+				return current.(float64) + incoming.(float64)
+			},
+		},
+	}
+
+	tracker.apply("s1", "likes", WSStateUpdate{Value: 1.0}, cfg)
+	tracker.apply("s1", "likes", WSStateUpdate{Value: 2.0, Version: 1}, cfg)
+	merged := tracker.apply("s1", "likes", WSStateUpdate{Value: 5.0, Version: 1}, cfg)
+	if !merged.accepted || merged.value != 7.0 {
+		t.Fatalf("expected merged value 7, got %+v", merged)
+	}
+
+	tracker.apply("s1", "title", WSStateUpdate{Value: "a"}, cfg)
+	tracker.apply("s1", "title", WSStateUpdate{Value: "b", Version: 1}, cfg)
+	res := tracker.apply("s1", "title", WSStateUpdate{Value: "c", Version: 1}, cfg)
+	if !res.accepted || res.value != "server" {
+		t.Fatalf("expected resolver value, got %+v", res)
+	}
+	if resolved.Current != "b" || resolved.Incoming != "c" || resolved.CurrentVersion != 2 {
+		t.Fatalf("unexpected conflict passed to resolver: %+v", resolved)
+	}
+
+	tracker.forget("s1")
+	if got := tracker.current("s1", "title"); got != 0 {
+		t.Fatalf("expected versions to be forgotten, got %d", got)
+	}
+}
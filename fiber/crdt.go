@@ -0,0 +1,156 @@
+package fiber
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/aydenstechdungeon/gospa/state"
+)
+
+// crdtChannel carries CRDT ops between processes.
+const crdtChannel = "gospa:crdt"
+
+// maxCRDTOpsPerMessage bounds the ops accepted in one client frame.
+const maxCRDTOpsPerMessage = 256
+
+// crdtEnvelope is the PubSub payload for shared CRDT ops.
+type crdtEnvelope struct {
+	Key string         `json:"key"`
+	Ops []state.CRDTOp `json:"ops"`
+}
+
+// crdtRegistry holds the CRDTs shared through a hub.
+type crdtRegistry struct {
+	mu       sync.RWMutex
+	entries  map[string]state.CRDT
+	unsubs   map[string]state.Unsubscribe
+	initOnce sync.Once
+}
+
+// crdtTopic is the hub topic clients join to receive ops for key.
+func crdtTopic(key string) string {
+	return "crdt:" + key
+}
+
+// ShareCRDT makes c a collaborative key. Local mutations and ops sent by
+// clients are published over the hub's PubSub so every process converges, and
+// subscribed clients receive them as "crdt" frames. Each process must share
+// its own replica under the same key. The returned function stops sharing.
+func (h *WSHub) ShareCRDT(key string, c state.CRDT) func() {
+	h.crdts.initOnce.Do(func() {
+		h.keepSubscription(h.pubsub.Subscribe(context.Background(), crdtChannel, h.receiveCRDT))
+	})
+
+	h.crdts.mu.Lock()
+	if unsub, ok := h.crdts.unsubs[key]; ok {
+		unsub()
+	}
+	h.crdts.entries[key] = c
+	h.crdts.unsubs[key] = c.OnLocalOps(func(ops []state.CRDTOp) {
+		h.publishCRDT(key, ops)
+	})
+	h.crdts.mu.Unlock()
+
+	return func() {
+		h.crdts.mu.Lock()
+		defer h.crdts.mu.Unlock()
+		if h.crdts.entries[key] != c {
+			return
+		}
+		h.crdts.unsubs[key]()
+		delete(h.crdts.entries, key)
+		delete(h.crdts.unsubs, key)
+	}
+}
+
+// SharedCRDT returns the CRDT shared under key.
+func (h *WSHub) SharedCRDT(key string) (state.CRDT, bool) {
+	h.crdts.mu.RLock()
+	defer h.crdts.mu.RUnlock()
+	c, ok := h.crdts.entries[key]
+	return c, ok
+}
+
+func (h *WSHub) publishCRDT(key string, ops []state.CRDTOp) {
+	data, err := JSONMarshal(crdtEnvelope{Key: key, Ops: ops})
+	if err != nil {
+		slog.Default().Warn("failed to encode CRDT ops", "key", key, "err", err)
+		return
+	}
+	_ = h.publish(crdtChannel, data)
+}
+
+// receiveCRDT merges ops from any process and forwards them to local subscribers.
+func (h *WSHub) receiveCRDT(message []byte) {
+	var env crdtEnvelope
+	if err := JSONUnmarshal(message, &env); err != nil || len(env.Ops) == 0 {
+		return
+	}
+	c, ok := h.SharedCRDT(env.Key)
+	if !ok {
+		return
+	}
+	c.Apply(env.Ops)
+
+	frame, err := JSONMarshal(map[string]interface{}{
+		"type": "crdt",
+		"key":  env.Key,
+		"crdt": c.CRDTType(),
+		"ops":  env.Ops,
+	})
+	if err != nil {
+		return
+	}
+	h.mu.RLock()
+	clients := h.ClientsByTopic[crdtTopic(env.Key)]
+	targets := make([]*WSClient, 0, len(clients))
+	for _, client := range clients {
+		targets = append(targets, client)
+	}
+	h.mu.RUnlock()
+	if len(targets) > 0 {
+		h.dispatchBroadcast(targets, frame)
+	}
+}
+
+// handleCRDTMessage processes a client "crdt" frame. A frame without ops
+// subscribes the client to the key and returns a snapshot; ops are published
+// to all replicas.
+func handleCRDTMessage(client *WSClient, msg WSMessage, sendResponse func(map[string]interface{})) {
+	var req crdtEnvelope
+	b, ok := msg.Payload.([]byte)
+	if !ok {
+		b, _ = JSONMarshal(msg.Payload)
+	}
+	if err := JSONUnmarshal(b, &req); err != nil || req.Key == "" {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid crdt payload"))
+		return
+	}
+	if client.hub == nil {
+		sendResponse(wsError(ErrorCodeNotFound, "Unknown crdt key: "+req.Key))
+		return
+	}
+	c, ok := client.hub.SharedCRDT(req.Key)
+	if !ok {
+		sendResponse(wsError(ErrorCodeNotFound, "Unknown crdt key: "+req.Key))
+		return
+	}
+
+	if len(req.Ops) == 0 {
+		client.hub.Subscribe(crdtTopic(req.Key), client.ID)
+		sendResponse(map[string]interface{}{
+			"type":     "crdt",
+			"key":      req.Key,
+			"crdt":     c.CRDTType(),
+			"ops":      c.Ops(),
+			"snapshot": true,
+		})
+		return
+	}
+	if len(req.Ops) > maxCRDTOpsPerMessage {
+		sendResponse(wsError(ErrorCodeLimitExceeded, "Too many crdt ops"))
+		return
+	}
+	client.hub.publishCRDT(req.Key, req.Ops)
+}
@@ -0,0 +1,49 @@
+package fiber
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/state"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestShareCRDTConvergesAcrossHubs(t *testing.T) {
+	ps := store.NewMemoryPubSub()
+	hubA, hubB := NewWSHub(ps), NewWSHub(ps)
+	defer hubA.Close()
+	defer hubB.Close()
+
+	todosA, todosB := state.NewCRDTList(), state.NewCRDTList()
+	stopA := hubA.ShareCRDT("todos", todosA)
+	defer stopA()
+	hubB.ShareCRDT("todos", todosB)
+
+	todosA.Append("write docs")
+	todosB.Append("ship release")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if todosA.Len() == 2 && reflect.DeepEqual(todosA.Values(), todosB.Values()) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("replicas did not converge: %v vs %v", todosA.Values(), todosB.Values())
+}
+
+func TestShareCRDTStopSharing(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+
+	m := state.NewCRDTMap()
+	stop := hub.ShareCRDT("doc", m)
+	if _, ok := hub.SharedCRDT("doc"); !ok {
+		t.Fatal("expected doc to be shared")
+	}
+	stop()
+	if _, ok := hub.SharedCRDT("doc"); ok {
+		t.Fatal("expected doc to be unshared")
+	}
+}
@@ -39,6 +39,8 @@ type DevConfig struct {
 	// When set, DevTools will subscribe to HMR file change events instead of using
 	// the legacy polling FileWatcher. This reduces CPU usage significantly.
 	HMRManager *HMRManager
+	// Hub enables time-travel commands that re-apply historical state to connected clients.
+	Hub *WSHub
 }
 
 // DefaultDevConfig returns default development configuration.
@@ -226,25 +228,40 @@ type DevTools struct {
 	stateLog  []StateLogEntry
 	mu        sync.RWMutex
 	stateKeys map[string]bool
+	// writeMu serializes writes; panel sockets are written from several goroutines
+	writeMu sync.Mutex
+	// Time travel bookkeeping, keyed by WebSocket client ID
+	nextEntryID uint64
+	lastValues  map[string]map[string]interface{}
+	cursors     map[string]int
+	replaying   map[string]map[string]int
 }
 
 // StateLogEntry represents a state change log entry.
 type StateLogEntry struct {
+	ID        uint64      `json:"id"`
 	Timestamp time.Time   `json:"timestamp"`
+	ClientID  string      `json:"clientId,omitempty"`
 	Key       string      `json:"key"`
 	OldValue  interface{} `json:"oldValue,omitempty"`
 	NewValue  interface{} `json:"newValue"`
-	Source    string      `json:"source"` // "client" or "server"
+	Source    string      `json:"source"` // "client", "server" or "timetravel"
 }
 
+// maxStateLogEntries bounds the state change log (and therefore time-travel depth).
+const maxStateLogEntries = 1000
+
 // NewDevTools creates new development tools.
 func NewDevTools(config DevConfig) *DevTools {
 	return &DevTools{
-		config:    config,
-		watcher:   nil, // Will use HMR watcher if available, otherwise falls back to nil (lazy init)
-		clients:   make(map[string]*websocket.Conn),
-		stateLog:  make([]StateLogEntry, 0),
-		stateKeys: make(map[string]bool),
+		config:     config,
+		watcher:    nil, // Will use HMR watcher if available, otherwise falls back to nil (lazy init)
+		clients:    make(map[string]*websocket.Conn),
+		stateLog:   make([]StateLogEntry, 0),
+		stateKeys:  make(map[string]bool),
+		lastValues: make(map[string]map[string]interface{}),
+		cursors:    make(map[string]int),
+		replaying:  make(map[string]map[string]int),
 	}
 }
 
@@ -289,10 +306,79 @@ func (d *DevTools) LogStateChange(key string, oldValue, newValue interface{}, so
 	}
 
 	d.mu.Lock()
-	defer d.mu.Unlock()
+	entry := d.appendEntryLocked("", key, oldValue, newValue, source)
+	d.mu.Unlock()
+
+	// Broadcast outside the lock; broadcastStateChange takes it again.
+	d.broadcastStateChange(entry)
+}
+
+// TrackClient records the current state of a client so later changes carry
+// accurate old values for the timeline. Safe to call on a nil DevTools.
+func (d *DevTools) TrackClient(client *WSClient) {
+	if d == nil || !d.config.Enabled {
+		return
+	}
+	values := client.State.ToMap()
+	d.mu.Lock()
+	d.lastValues[client.ID] = values
+	d.mu.Unlock()
+}
+
+// ForgetClient drops the time-travel history of a disconnected client.
+// Safe to call on a nil DevTools.
+func (d *DevTools) ForgetClient(clientID string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	delete(d.lastValues, clientID)
+	delete(d.cursors, clientID)
+	delete(d.replaying, clientID)
+	d.mu.Unlock()
+}
+
+// LogClientStateChange logs a change to a client's StateMap. It is called from
+// the StateMap OnChange path, so the previous value is taken from the last
+// change seen for that client. Safe to call on a nil DevTools.
+func (d *DevTools) LogClientStateChange(clientID, key string, value interface{}) {
+	if d == nil || !d.config.Enabled {
+		return
+	}
+
+	d.mu.Lock()
+	values := d.lastValues[clientID]
+	if values == nil {
+		values = make(map[string]interface{})
+		d.lastValues[clientID] = values
+	}
+	oldValue := values[key]
+	values[key] = value
+
+	source := "client"
+	if pending := d.replaying[clientID]; pending[key] > 0 {
+		// Echo of a time-travel write: log it but keep the cursor where it is.
+		source = "timetravel"
+		pending[key]--
+		if pending[key] == 0 {
+			delete(pending, key)
+		}
+	} else {
+		delete(d.cursors, clientID)
+	}
+	entry := d.appendEntryLocked(clientID, key, oldValue, value, source)
+	d.mu.Unlock()
 
+	d.broadcastStateChange(entry)
+}
+
+// appendEntryLocked records a log entry. Caller must hold d.mu.
+func (d *DevTools) appendEntryLocked(clientID, key string, oldValue, newValue interface{}, source string) StateLogEntry {
+	d.nextEntryID++
 	entry := StateLogEntry{
+		ID:        d.nextEntryID,
 		Timestamp: time.Now(),
+		ClientID:  clientID,
 		Key:       key,
 		OldValue:  oldValue,
 		NewValue:  newValue,
@@ -302,20 +388,105 @@ func (d *DevTools) LogStateChange(key string, oldValue, newValue interface{}, so
 	d.stateLog = append(d.stateLog, entry)
 	d.stateKeys[key] = true
 
-	// Keep only last 1000 entries
-	if len(d.stateLog) > 1000 {
-		d.stateLog = d.stateLog[len(d.stateLog)-1000:]
+	// Keep only the most recent entries
+	if len(d.stateLog) > maxStateLogEntries {
+		d.stateLog = d.stateLog[len(d.stateLog)-maxStateLogEntries:]
 	}
-
-	// Broadcast to dev tools clients
-	d.broadcastStateChange(entry)
+	return entry
 }
 
 // GetStateLog returns the state change log.
 func (d *DevTools) GetStateLog() []StateLogEntry {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.stateLog
+	out := make([]StateLogEntry, len(d.stateLog))
+	copy(out, d.stateLog)
+	return out
+}
+
+// timelineLocked returns the client's own (non time-travel) entries in order.
+// Caller must hold d.mu.
+func (d *DevTools) timelineLocked(clientID string) []StateLogEntry {
+	var timeline []StateLogEntry
+	for _, e := range d.stateLog {
+		if e.ClientID == clientID && e.Source != "timetravel" {
+			timeline = append(timeline, e)
+		}
+	}
+	return timeline
+}
+
+// snapshotAt reconstructs the client's state after the first position entries
+// of its timeline. Keys first changed later are reset to their prior value.
+func snapshotAt(timeline []StateLogEntry, position int) map[string]interface{} {
+	snapshot := make(map[string]interface{})
+	for i, e := range timeline {
+		if i < position {
+			snapshot[e.Key] = e.NewValue
+		} else if _, ok := snapshot[e.Key]; !ok && e.OldValue != nil {
+			snapshot[e.Key] = e.OldValue
+		}
+	}
+	return snapshot
+}
+
+// TimeTravel re-applies the client's state as of a timeline position. move
+// receives the current position and the timeline length and returns the target.
+func (d *DevTools) TimeTravel(clientID string, move func(current, length int) int) (int, error) {
+	if d.config.Hub == nil {
+		return 0, fmt.Errorf("time travel requires DevConfig.Hub")
+	}
+	client, ok := d.config.Hub.GetClient(clientID)
+	if !ok {
+		return 0, fmt.Errorf("client %s is not connected", clientID)
+	}
+
+	d.mu.Lock()
+	timeline := d.timelineLocked(clientID)
+	current, ok := d.cursors[clientID]
+	if !ok {
+		current = len(timeline)
+	}
+	target := move(current, len(timeline))
+	if target < 0 {
+		target = 0
+	}
+	if target > len(timeline) {
+		target = len(timeline)
+	}
+	snapshot := snapshotAt(timeline, target)
+	d.cursors[clientID] = target
+
+	pending := d.replaying[clientID]
+	if pending == nil {
+		pending = make(map[string]int)
+		d.replaying[clientID] = pending
+	}
+	settables := make(map[string]state.Settable, len(snapshot))
+	for key, value := range snapshot {
+		obs, exists := client.State.Get(key)
+		if !exists {
+			continue
+		}
+		settable, ok := obs.(state.Settable)
+		if !ok || fmt.Sprint(obs.GetAny()) == fmt.Sprint(value) {
+			continue
+		}
+		pending[key]++
+		settables[key] = settable
+	}
+	d.mu.Unlock()
+
+	for key, settable := range settables {
+		if err := settable.SetAny(snapshot[key]); err != nil {
+			d.mu.Lock()
+			if pending[key] > 0 {
+				pending[key]--
+			}
+			d.mu.Unlock()
+		}
+	}
+	return target, nil
 }
 
 // GetStateKeys returns all tracked state keys.
@@ -330,6 +501,13 @@ func (d *DevTools) GetStateKeys() []string {
 	return keys
 }
 
+// write sends a text frame to a dev tools client.
+func (d *DevTools) write(c *websocket.Conn, data []byte) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	return c.WriteMessage(websocket.TextMessage, data)
+}
+
 // broadcastStateChange broadcasts a state change to dev tools clients.
 func (d *DevTools) broadcastStateChange(entry StateLogEntry) {
 	data, err := json.Marshal(map[string]interface{}{
@@ -344,7 +522,7 @@ func (d *DevTools) broadcastStateChange(entry StateLogEntry) {
 	var failed []string
 	d.mu.RLock()
 	for clientID, conn := range d.clients {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		if err := d.write(conn, data); err != nil {
 			failed = append(failed, clientID)
 		}
 	}
@@ -403,7 +581,12 @@ func (d *DevTools) DevToolsHandler() fiberpkg.Handler {
 			case "clear_log":
 				d.mu.Lock()
 				d.stateLog = make([]StateLogEntry, 0)
+				d.cursors = make(map[string]int)
 				d.mu.Unlock()
+			case "get_clients":
+				d.sendClients(c)
+			case "undo", "redo", "time_travel":
+				d.handleTimeTravel(c, msgType, msg)
 			}
 		}
 	})
@@ -414,7 +597,7 @@ func (d *DevTools) sendInitialState(c *websocket.Conn) {
 		"type":    "init",
 		"enabled": d.config.Enabled,
 	})
-	_ = c.WriteMessage(websocket.TextMessage, data)
+	_ = d.write(c, data)
 }
 
 func (d *DevTools) sendStateLog(c *websocket.Conn) {
@@ -426,7 +609,60 @@ func (d *DevTools) sendStateLog(c *websocket.Conn) {
 		"type": "state_log",
 		"log":  log,
 	})
-	_ = c.WriteMessage(websocket.TextMessage, data)
+	_ = d.write(c, data)
+}
+
+func (d *DevTools) sendClients(c *websocket.Conn) {
+	clients := make([]map[string]string, 0)
+	if hub := d.config.Hub; hub != nil {
+		hub.Range(func(client *WSClient) bool {
+			clients = append(clients, map[string]string{"id": client.ID, "sessionId": client.SessionID})
+			return true
+		})
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":    "clients",
+		"clients": clients,
+	})
+	_ = d.write(c, data)
+}
+
+func (d *DevTools) handleTimeTravel(c *websocket.Conn, msgType string, msg map[string]interface{}) {
+	clientID, _ := msg["clientId"].(string)
+	var move func(current, length int) int
+	switch msgType {
+	case "undo":
+		move = func(current, _ int) int { return current - 1 }
+	case "redo":
+		move = func(current, _ int) int { return current + 1 }
+	default:
+		entryID, _ := msg["entryId"].(float64)
+		target := -1
+		d.mu.RLock()
+		for i, e := range d.timelineLocked(clientID) {
+			if e.ID == uint64(entryID) {
+				target = i + 1
+				break
+			}
+		}
+		d.mu.RUnlock()
+		move = func(current, _ int) int {
+			if target < 0 {
+				return current
+			}
+			return target
+		}
+	}
+
+	reply := map[string]interface{}{"type": "time_travel", "clientId": clientID}
+	position, err := d.TimeTravel(clientID, move)
+	if err != nil {
+		reply["error"] = err.Error()
+	} else {
+		reply["position"] = position
+	}
+	data, _ := json.Marshal(reply)
+	_ = d.write(c, data)
 }
 
 func (d *DevTools) sendStateKeys(c *websocket.Conn) {
@@ -435,7 +671,7 @@ func (d *DevTools) sendStateKeys(c *websocket.Conn) {
 		"type": "state_keys",
 		"keys": keys,
 	})
-	_ = c.WriteMessage(websocket.TextMessage, data)
+	_ = d.write(c, data)
 }
 
 // DevPanelHandler creates a handler for the dev panel UI.
@@ -467,7 +703,7 @@ func devPanelHTML(nonceAttr string) string {
 		header { display: flex; justify-content: space-between; align-items: center; padding: 1rem; background: #16213e; border-radius: 8px; margin-bottom: 1rem; }
 		h1 { font-size: 1.5rem; }
 		.status { display: flex; align-items: center; gap: 0.5rem; }
-		.status-dot { width: 10px; height: 10px; border-radius: 50%; background: #4ade80; }
+		.status-dot { width: 10px; height: 10px; border-radius: 50%%; background: #4ade80; }
 		.status-dot.inactive { background: #ef4444; }
 		.panel { background: #16213e; border-radius: 8px; padding: 1rem; margin-bottom: 1rem; }
 		.panel-header { display: flex; justify-content: space-between; align-items: center; margin-bottom: 1rem; }
@@ -489,6 +725,39 @@ func devPanelHTML(nonceAttr string) string {
 		.log-source.client { color: #60a5fa; }
 		.log-source.server { color: #f59e0b; }
 		.empty { text-align: center; padding: 2rem; color: #666; }
+		.tt-controls { display: flex; gap: 0.5rem; align-items: center; margin-bottom: 1rem; }
+		.tt-controls select { background: #0f0f23; color: #eee; border: 1px solid #333; border-radius: 6px; padding: 0.5rem; min-width: 220px; }
+		.tt-status { color: #888; font-size: 0.85rem; }
+		.timeline { max-height: 300px; overflow-y: auto; }
+		.tl-entry { display: grid; grid-template-columns: 24px 100px 150px 1fr; gap: 0.5rem; padding: 0.4rem 0.5rem; border-left: 2px solid #333; cursor: pointer; font-size: 0.85rem; }
+		.tl-entry:hover { background: #1a1a2e; }
+		.tl-entry.future { opacity: 0.45; }
+		.tl-entry.current { border-left-color: #e94560; background: #0f0f23; }
+		.tabs { display: flex; gap: 0.5rem; margin-bottom: 1rem; }
+		.tab { background: #16213e; color: #888; }
+		.tab.active { background: #0f0f23; color: #eee; }
+		.hidden { display: none; }
+		.route-table { width: 100%%; border-collapse: collapse; font-size: 0.85rem; }
+		.route-table th { text-align: left; color: #888; font-weight: 500; padding: 0.5rem; border-bottom: 1px solid #333; }
+		.route-table td { padding: 0.5rem; border-bottom: 1px solid #333; vertical-align: top; }
+		.route-path { color: #4ade80; font-family: monospace; }
+		.route-file { color: #888; font-family: monospace; font-size: 0.75rem; }
+		.strategy { padding: 0.1rem 0.5rem; border-radius: 4px; background: #0f0f23; font-family: monospace; }
+		.cache-entry { display: flex; gap: 0.5rem; align-items: center; margin-bottom: 0.25rem; font-family: monospace; }
+		.cache-entry.stale { color: #f59e0b; }
+		.btn-small { padding: 0.15rem 0.5rem; font-size: 0.75rem; }
+		.profile-list { max-height: 240px; overflow-y: auto; margin-bottom: 1rem; }
+		.profile-row { display: grid; grid-template-columns: 60px 1fr 80px 80px; gap: 0.5rem; padding: 0.4rem 0.5rem; border-bottom: 1px solid #333; cursor: pointer; font-size: 0.85rem; font-family: monospace; }
+		.profile-row:hover, .profile-row.selected { background: #0f0f23; }
+		.flame { position: relative; background: #0f0f23; border-radius: 6px; padding: 0.5rem 0; }
+		.flame-bar { position: absolute; height: 20px; border-radius: 3px; font-size: 0.7rem; line-height: 20px; padding: 0 4px; overflow: hidden; white-space: nowrap; color: #111; }
+		.flame-bar.match { background: #94a3b8; }
+		.flame-bar.loaders { background: #60a5fa; }
+		.flame-bar.sql { background: #2dd4bf; }
+		.flame-bar.layout { background: #a78bfa; }
+		.flame-bar.page { background: #4ade80; }
+		.flame-bar.slot { background: #f59e0b; }
+		.flame-bar.write { background: #e94560; }
 	</style>
 </head>
 <body>
@@ -501,6 +770,15 @@ func devPanelHTML(nonceAttr string) string {
 			</div>
 		</header>
 
+		<div class="tabs">
+			<button class="btn tab active" data-tab="stateTab">State</button>
+			<button class="btn tab" data-tab="routesTab">Routes</button>
+			<button class="btn tab" data-tab="profileTab">Profiler</button>
+			<button class="btn tab" data-tab="jobsTab">Jobs</button>
+			<button class="btn tab" data-tab="analyticsTab">Analytics</button>
+		</div>
+
+		<div id="stateTab">
 		<div class="panel">
 			<div class="panel-header">
 				<span class="panel-title">State Keys</span>
@@ -511,6 +789,22 @@ func devPanelHTML(nonceAttr string) string {
 			</div>
 		</div>
 
+		<div class="panel">
+			<div class="panel-header">
+				<span class="panel-title">Time Travel</span>
+				<button class="btn btn-secondary" id="refreshClientsBtn">Refresh Clients</button>
+			</div>
+			<div class="tt-controls">
+				<select id="clientSelect"><option value="">Select a client</option></select>
+				<button class="btn btn-secondary" id="undoBtn">Undo</button>
+				<button class="btn btn-secondary" id="redoBtn">Redo</button>
+				<span class="tt-status" id="ttStatus"></span>
+			</div>
+			<div class="timeline" id="timeline">
+				<div class="empty">Select a client to see its state timeline</div>
+			</div>
+		</div>
+
 		<div class="panel">
 			<div class="panel-header">
 				<span class="panel-title">State Change Log</span>
@@ -523,11 +817,66 @@ func devPanelHTML(nonceAttr string) string {
 				<div class="empty">No state changes logged</div>
 			</div>
 		</div>
+		</div>
+
+		<div id="routesTab" class="hidden">
+		<div class="panel">
+			<div class="panel-header">
+				<span class="panel-title">Routes</span>
+				<button class="btn btn-secondary" id="refreshRoutesBtn">Refresh</button>
+			</div>
+			<div id="routeContainer">
+				<div class="empty">No routes loaded</div>
+			</div>
+		</div>
+		</div>
+
+		<div id="profileTab" class="hidden">
+		<div class="panel">
+			<div class="panel-header">
+				<span class="panel-title">Request Profiles</span>
+				<div>
+					<button class="btn btn-secondary" id="clearProfilesBtn">Clear</button>
+					<button class="btn btn-primary" id="refreshProfilesBtn">Refresh</button>
+				</div>
+			</div>
+			<div class="profile-list" id="profileList">
+				<div class="empty">No page requests profiled yet</div>
+			</div>
+			<div class="flame" id="flame"></div>
+		</div>
+		</div>
+
+		<div id="jobsTab" class="hidden">
+		<div class="panel">
+			<div class="panel-header">
+				<span class="panel-title">Job Queue</span>
+				<button class="btn btn-secondary" id="refreshJobsBtn">Refresh</button>
+			</div>
+			<div id="jobsContainer">
+				<div class="empty">No job stats loaded</div>
+			</div>
+		</div>
+		</div>
+
+		<div id="analyticsTab" class="hidden">
+		<div class="panel">
+			<div class="panel-header">
+				<span class="panel-title">Analytics (last 7 days)</span>
+				<button class="btn btn-secondary" id="refreshAnalyticsBtn">Refresh</button>
+			</div>
+			<div id="analyticsContainer">
+				<div class="empty">No analytics loaded</div>
+			</div>
+		</div>
+		</div>
 	</div>
 
 	<script` + nonceAttr + `>
 		let ws = null;
 		let connected = false;
+		let stateLog = [];
+		let positions = {};
 
 		function connect() {
 			const protocol = (window.location.protocol === 'https:' && !%v) ? 'wss:' : 'ws:';
@@ -536,6 +885,9 @@ func devPanelHTML(nonceAttr string) string {
 			ws.onopen = function() {
 				connected = true;
 				updateStatus(true);
+				refreshKeys();
+				refreshLog();
+				refreshClients();
 			};
 
 			ws.onclose = function() {
@@ -565,10 +917,28 @@ func devPanelHTML(nonceAttr string) string {
 		function handleMessage(data) {
 			switch (data.type) {
 				case 'state_change':
+					stateLog.push(data.entry);
+					if (data.entry.source !== 'timetravel' && data.entry.clientId) {
+						delete positions[data.entry.clientId];
+					}
 					addLogEntry(data.entry);
+					renderTimeline();
 					break;
 				case 'state_log':
+					stateLog = data.log;
 					renderLog(data.log);
+					renderTimeline();
+					break;
+				case 'clients':
+					renderClients(data.clients);
+					break;
+				case 'time_travel':
+					if (data.error) {
+						document.getElementById('ttStatus').textContent = data.error;
+					} else {
+						positions[data.clientId] = data.position;
+						renderTimeline();
+					}
 					break;
 				case 'state_keys':
 					renderKeys(data.keys);
@@ -616,6 +986,74 @@ func devPanelHTML(nonceAttr string) string {
 			container.innerHTML = html;
 		}
 
+		function selectedClient() {
+			return document.getElementById('clientSelect').value;
+		}
+
+		function clientTimeline(clientId) {
+			return stateLog.filter(function(e) { return e.clientId === clientId && e.source !== 'timetravel'; });
+		}
+
+		function renderClients(clients) {
+			const select = document.getElementById('clientSelect');
+			const current = select.value;
+			select.textContent = '';
+			const placeholder = document.createElement('option');
+			placeholder.value = '';
+			placeholder.textContent = 'Select a client';
+			select.appendChild(placeholder);
+			clients.forEach(function(c) {
+				const opt = document.createElement('option');
+				opt.value = c.id;
+				opt.textContent = c.id + (c.sessionId ? ' (' + c.sessionId.slice(0, 8) + ')' : '');
+				select.appendChild(opt);
+			});
+			select.value = current;
+			renderTimeline();
+		}
+
+		function renderTimeline() {
+			const container = document.getElementById('timeline');
+			const clientId = selectedClient();
+			container.textContent = '';
+			const entries = clientId ? clientTimeline(clientId) : [];
+			const position = clientId in positions ? positions[clientId] : entries.length;
+			document.getElementById('ttStatus').textContent = clientId ? 'Step ' + position + ' of ' + entries.length : '';
+			if (entries.length === 0) {
+				const empty = document.createElement('div');
+				empty.className = 'empty';
+				empty.textContent = clientId ? 'No state changes for this client' : 'Select a client to see its state timeline';
+				container.appendChild(empty);
+				return;
+			}
+			entries.forEach(function(entry, i) {
+				const row = document.createElement('div');
+				row.className = 'tl-entry' + (i + 1 === position ? ' current' : '') + (i + 1 > position ? ' future' : '');
+				[String(i + 1), new Date(entry.timestamp).toLocaleTimeString(), entry.key, JSON.stringify(entry.newValue)].forEach(function(text, col) {
+					const span = document.createElement('span');
+					span.className = col === 2 ? 'log-key' : (col === 3 ? 'log-value' : 'log-time');
+					span.textContent = text;
+					row.appendChild(span);
+				});
+				row.addEventListener('click', function() {
+					sendCommand({ type: 'time_travel', clientId: clientId, entryId: entry.id });
+				});
+				container.appendChild(row);
+			});
+		}
+
+		function sendCommand(cmd) {
+			if (ws && connected && cmd.clientId) {
+				ws.send(JSON.stringify(cmd));
+			}
+		}
+
+		function refreshClients() {
+			if (ws && connected) {
+				ws.send(JSON.stringify({ type: 'get_clients' }));
+			}
+		}
+
 		function refreshLog() {
 			if (ws && connected) {
 				ws.send(JSON.stringify({ type: 'get_state_log' }));
@@ -633,11 +1071,335 @@ func devPanelHTML(nonceAttr string) string {
 				ws.send(JSON.stringify({ type: 'clear_log' }));
 			}
 			document.getElementById('logContainer').innerHTML = '<div class="empty">No state changes logged</div>';
+			stateLog = [];
+			positions = {};
+			renderTimeline();
+		}
+
+		function cell(text, className) {
+			const td = document.createElement('td');
+			if (className) td.className = className;
+			td.textContent = text;
+			return td;
+		}
+
+		function formatAge(seconds) {
+			if (seconds < 60) return Math.round(seconds) + 's';
+			if (seconds < 3600) return Math.round(seconds / 60) + 'm';
+			return Math.round(seconds / 3600) + 'h';
+		}
+
+		function renderRoutes(routes) {
+			const container = document.getElementById('routeContainer');
+			container.textContent = '';
+			if (routes.length === 0) {
+				const empty = document.createElement('div');
+				empty.className = 'empty';
+				empty.textContent = 'No page routes registered';
+				container.appendChild(empty);
+				return;
+			}
+			const table = document.createElement('table');
+			table.className = 'route-table';
+			const head = document.createElement('tr');
+			['Route', 'Strategy', 'Cache', 'Hits / Misses', ''].forEach(function(title) {
+				const th = document.createElement('th');
+				th.textContent = title;
+				head.appendChild(th);
+			});
+			table.appendChild(head);
+			routes.forEach(function(route) {
+				const row = document.createElement('tr');
+
+				const pathCell = cell('', '');
+				const path = document.createElement('div');
+				path.className = 'route-path';
+				path.textContent = route.path;
+				const file = document.createElement('div');
+				file.className = 'route-file';
+				file.textContent = route.file;
+				pathCell.appendChild(path);
+				pathCell.appendChild(file);
+				row.appendChild(pathCell);
+
+				const strategyCell = cell('', '');
+				const strategy = document.createElement('span');
+				strategy.className = 'strategy';
+				const revalidate = [route.revalidateAfter, route.revalidateCron ? 'cron ' + route.revalidateCron : ''].filter(Boolean).join(', ');
+				strategy.textContent = route.strategy + (revalidate ? ' (' + revalidate + ')' : '');
+				strategyCell.appendChild(strategy);
+				row.appendChild(strategyCell);
+
+				const cacheCell = cell('', '');
+				if (route.cache.length === 0) {
+					cacheCell.textContent = '—';
+				}
+				route.cache.forEach(function(entry) {
+					const div = document.createElement('div');
+					div.className = 'cache-entry' + (entry.stale ? ' stale' : '');
+					const label = document.createElement('span');
+					label.textContent = entry.kind + ' ' + entry.key + (entry.createdAt ? ' · ' + formatAge(entry.ageSeconds) : '') + (entry.stale ? ' · stale' : '');
+					const btn = document.createElement('button');
+					btn.className = 'btn btn-secondary btn-small';
+					btn.textContent = 'Invalidate';
+					btn.addEventListener('click', function() { invalidateRoute({ key: entry.key }); });
+					div.appendChild(label);
+					div.appendChild(btn);
+					cacheCell.appendChild(div);
+				});
+				row.appendChild(cacheCell);
+
+				row.appendChild(cell(route.stats.hits + ' / ' + route.stats.misses, ''));
+
+				const actionCell = cell('', '');
+				if (route.cache.length > 0) {
+					const btn = document.createElement('button');
+					btn.className = 'btn btn-primary btn-small';
+					btn.textContent = 'Invalidate all';
+					btn.addEventListener('click', function() { invalidateRoute({ route: route.path }); });
+					actionCell.appendChild(btn);
+				}
+				row.appendChild(actionCell);
+
+				table.appendChild(row);
+			});
+			container.appendChild(table);
+		}
+
+		function refreshRoutes() {
+			fetch('/_gospa/dev/routes', { cache: 'no-store' })
+				.then(function(res) { return res.json(); })
+				.then(function(data) { renderRoutes(data.routes || []); })
+				.catch(function() {});
+		}
+
+		function invalidateRoute(payload) {
+			fetch('/_gospa/dev/routes/invalidate', {
+				method: 'POST',
+				headers: { 'Content-Type': 'application/json' },
+				body: JSON.stringify(payload)
+			}).then(refreshRoutes).catch(function() {});
+		}
+
+		let profiles = [];
+		let selectedProfile = null;
+
+		function renderProfiles() {
+			const list = document.getElementById('profileList');
+			list.textContent = '';
+			if (profiles.length === 0) {
+				const empty = document.createElement('div');
+				empty.className = 'empty';
+				empty.textContent = 'No page requests profiled yet';
+				list.appendChild(empty);
+				renderFlame(null);
+				return;
+			}
+			profiles.forEach(function(p) {
+				const row = document.createElement('div');
+				row.className = 'profile-row' + (selectedProfile === p.id ? ' selected' : '');
+				[p.method, p.path, p.status, p.total.toFixed(1) + 'ms'].forEach(function(text) {
+					const span = document.createElement('span');
+					span.textContent = text;
+					row.appendChild(span);
+				});
+				row.addEventListener('click', function() {
+					selectedProfile = p.id;
+					renderProfiles();
+				});
+				list.appendChild(row);
+			});
+			renderFlame(profiles.find(function(p) { return p.id === selectedProfile; }) || null);
+		}
+
+		function renderFlame(profile) {
+			const flame = document.getElementById('flame');
+			flame.textContent = '';
+			if (!profile) {
+				flame.style.height = '0';
+				return;
+			}
+			const spans = profile.spans.slice().sort(function(a, b) { return a.start - b.start || b.duration - a.duration; });
+			const depths = [];
+			spans.forEach(function(s, i) {
+				let depth = 0;
+				for (let j = 0; j < i; j++) {
+					const parent = spans[j];
+					if (parent.start <= s.start && parent.start + parent.duration >= s.start + s.duration) {
+						depth = Math.max(depth, depths[j] + 1);
+					}
+				}
+				depths.push(depth);
+				const bar = document.createElement('div');
+				bar.className = 'flame-bar ' + s.phase;
+				bar.style.left = (profile.total ? s.start / profile.total * 100 : 0) + '%%';
+				bar.style.width = Math.max(profile.total ? s.duration / profile.total * 100 : 0, 0.5) + '%%';
+				bar.style.top = (depth * 24 + 8) + 'px';
+				bar.textContent = s.phase + (s.detail ? ' ' + s.detail : '') + ' ' + s.duration.toFixed(2) + 'ms';
+				bar.title = bar.textContent;
+				flame.appendChild(bar);
+			});
+			flame.style.height = ((Math.max.apply(null, depths) + 1) * 24 + 16) + 'px';
+		}
+
+		function refreshProfiles() {
+			fetch('/_gospa/dev/profile', { cache: 'no-store' })
+				.then(function(res) { return res.json(); })
+				.then(function(data) {
+					profiles = data.profiles || [];
+					if (selectedProfile === null && profiles.length > 0) selectedProfile = profiles[0].id;
+					renderProfiles();
+				})
+				.catch(function() {});
+		}
+
+		function clearProfiles() {
+			fetch('/_gospa/dev/profile', { method: 'DELETE' })
+				.then(function() {
+					profiles = [];
+					selectedProfile = null;
+					renderProfiles();
+				})
+				.catch(function() {});
+		}
+
+		function renderJobs(data) {
+			const container = document.getElementById('jobsContainer');
+			container.textContent = '';
+			const stats = data.stats || {};
+			const summary = document.createElement('div');
+			summary.className = 'state-keys';
+			[['Ready', stats.ready], ['Scheduled', stats.scheduled], ['Running', stats.running], ['Dead', stats.dead],
+				['Processed', stats.processed], ['Failed runs', stats.failed]].forEach(function(pair) {
+				const span = document.createElement('span');
+				span.className = 'state-key';
+				span.textContent = pair[0] + ': ' + (pair[1] || 0);
+				summary.appendChild(span);
+			});
+			container.appendChild(summary);
+
+			const names = {};
+			(data.handlers || []).forEach(function(name) { names[name] = 0; });
+			Object.keys(stats.byName || {}).forEach(function(name) { names[name] = stats.byName[name]; });
+			const keys = Object.keys(names).sort();
+			if (keys.length === 0) {
+				const empty = document.createElement('div');
+				empty.className = 'empty';
+				empty.textContent = 'No job handlers registered';
+				container.appendChild(empty);
+				return;
+			}
+			const table = document.createElement('table');
+			table.className = 'route-table';
+			const head = document.createElement('tr');
+			['Job', 'Queued', 'Handler'].forEach(function(text) {
+				const th = document.createElement('th');
+				th.textContent = text;
+				head.appendChild(th);
+			});
+			table.appendChild(head);
+			keys.forEach(function(name) {
+				const row = document.createElement('tr');
+				[name, names[name], (data.handlers || []).indexOf(name) >= 0 ? 'yes' : 'no'].forEach(function(text, i) {
+					const td = document.createElement('td');
+					td.textContent = text;
+					if (i === 0) td.className = 'route-path';
+					row.appendChild(td);
+				});
+				table.appendChild(row);
+			});
+			container.appendChild(table);
+		}
+
+		function renderAnalytics(data) {
+			const container = document.getElementById('analyticsContainer');
+			container.textContent = '';
+			if (!data) {
+				const empty = document.createElement('div');
+				empty.className = 'empty';
+				empty.textContent = 'Analytics is disabled; set Config.Analytics to enable it';
+				container.appendChild(empty);
+				return;
+			}
+			const summary = document.createElement('div');
+			summary.className = 'state-keys';
+			[['Views', data.views], ['SPA navigations', data.spaViews]].forEach(function(pair) {
+				const span = document.createElement('span');
+				span.className = 'state-key';
+				span.textContent = pair[0] + ': ' + (pair[1] || 0);
+				summary.appendChild(span);
+			});
+			container.appendChild(summary);
+			[['Route', data.pages], ['Referrer', data.referrers], ['Event', data.events]].forEach(function(section) {
+				const rows = section[1] || [];
+				if (rows.length === 0) return;
+				const table = document.createElement('table');
+				table.className = 'route-table';
+				const head = document.createElement('tr');
+				[section[0], 'Count'].forEach(function(text) {
+					const th = document.createElement('th');
+					th.textContent = text;
+					head.appendChild(th);
+				});
+				table.appendChild(head);
+				rows.forEach(function(item) {
+					const row = document.createElement('tr');
+					[item.name, item.count].forEach(function(text, i) {
+						const td = document.createElement('td');
+						td.textContent = text;
+						if (i === 0) td.className = 'route-path';
+						row.appendChild(td);
+					});
+					table.appendChild(row);
+				});
+				container.appendChild(table);
+			});
+		}
+
+		function refreshAnalytics() {
+			fetch('/_gospa/dev/analytics?days=7&format=json', { cache: 'no-store' })
+				.then(function(res) { return res.ok ? res.json() : null; })
+				.then(renderAnalytics)
+				.catch(function() {});
+		}
+
+		function refreshJobs() {
+			fetch('/_gospa/dev/jobs', { cache: 'no-store' })
+				.then(function(res) { return res.json(); })
+				.then(renderJobs)
+				.catch(function() {});
 		}
 
+		document.getElementById('refreshJobsBtn').addEventListener('click', refreshJobs);
+		document.getElementById('refreshAnalyticsBtn').addEventListener('click', refreshAnalytics);
+		document.getElementById('refreshProfilesBtn').addEventListener('click', refreshProfiles);
+		document.getElementById('clearProfilesBtn').addEventListener('click', clearProfiles);
+
+		document.querySelectorAll('.tab').forEach(function(tab) {
+			tab.addEventListener('click', function() {
+				document.querySelectorAll('.tab').forEach(function(t) {
+					t.classList.toggle('active', t === tab);
+					document.getElementById(t.dataset.tab).classList.toggle('hidden', t !== tab);
+				});
+				if (tab.dataset.tab === 'routesTab') refreshRoutes();
+				if (tab.dataset.tab === 'profileTab') refreshProfiles();
+				if (tab.dataset.tab === 'jobsTab') refreshJobs();
+				if (tab.dataset.tab === 'analyticsTab') refreshAnalytics();
+			});
+		});
+
+		document.getElementById('refreshRoutesBtn').addEventListener('click', refreshRoutes);
 		document.getElementById('refreshKeysBtn').addEventListener('click', refreshKeys);
 		document.getElementById('clearLogBtn').addEventListener('click', clearLog);
 		document.getElementById('refreshLogBtn').addEventListener('click', refreshLog);
+		document.getElementById('refreshClientsBtn').addEventListener('click', refreshClients);
+		document.getElementById('clientSelect').addEventListener('change', renderTimeline);
+		document.getElementById('undoBtn').addEventListener('click', function() {
+			sendCommand({ type: 'undo', clientId: selectedClient() });
+		});
+		document.getElementById('redoBtn').addEventListener('click', function() {
+			sendCommand({ type: 'redo', clientId: selectedClient() });
+		});
 		connect();
 		refreshKeys();
 		refreshLog();
@@ -0,0 +1,74 @@
+package fiber
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/state"
+)
+
+func TestDevToolsTimeTravel(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := NewWSClient("c1", nil, WebSocketConfig{})
+	count := state.NewRune(0)
+	client.State.Add("count", count)
+	hub.register(client)
+
+	d := NewDevTools(DevConfig{Enabled: true, Hub: hub})
+	d.TrackClient(client)
+	for _, v := range []int{1, 2, 3} {
+		count.Set(v)
+		d.LogClientStateChange(client.ID, "count", v)
+	}
+
+	pos, err := d.TimeTravel(client.ID, func(current, _ int) int { return current - 1 })
+	if err != nil || pos != 2 || count.Get() != 2 {
+		t.Fatalf("undo: pos=%d count=%d err=%v", pos, count.Get(), err)
+	}
+	// The OnChange echo of the replayed write must not reset the cursor.
+	d.LogClientStateChange(client.ID, "count", 2)
+
+	pos, _ = d.TimeTravel(client.ID, func(current, _ int) int { return current - 2 })
+	if pos != 0 || count.Get() != 0 {
+		t.Fatalf("undo to start: pos=%d count=%d", pos, count.Get())
+	}
+	d.LogClientStateChange(client.ID, "count", 0)
+
+	pos, _ = d.TimeTravel(client.ID, func(current, _ int) int { return current + 1 })
+	if pos != 1 || count.Get() != 1 {
+		t.Fatalf("redo: pos=%d count=%d", pos, count.Get())
+	}
+
+	log := d.GetStateLog()
+	if last := log[len(log)-1]; last.Source != "timetravel" {
+		t.Fatalf("expected replayed change to be tagged timetravel, got %q", last.Source)
+	}
+
+	if _, err := d.TimeTravel("missing", func(c, _ int) int { return c }); err == nil {
+		t.Fatal("expected error for unknown client")
+	}
+}
+
+func TestDevToolsLogStateChangeBroadcastDoesNotDeadlock(t *testing.T) {
+	d := NewDevTools(DevConfig{Enabled: true})
+	done := make(chan struct{})
+	go func() {
+		d.LogStateChange("k", nil, 1, "server")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("LogStateChange blocked")
+	}
+}
+
+func TestDevPanelHTMLFormatsCleanly(t *testing.T) {
+	html := fmt.Sprintf(devPanelHTML(""), false)
+	if strings.Contains(html, "%!") {
+		t.Fatal("dev panel HTML contains a formatting error")
+	}
+}
@@ -2,6 +2,7 @@
 package fiber
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 type ErrorInfo struct {
 	Message     string       `json:"message"`
 	Type        string       `json:"type"`
+	Code        string       `json:"code,omitempty"`
 	Stack       []StackFrame `json:"stack"`
 	File        string       `json:"file"`
 	Line        int          `json:"line"`
@@ -91,6 +93,10 @@ func (e *ErrorOverlay) parseError(err error, req *http.Request) *ErrorInfo {
 		Stack:     e.extractStack(err),
 		Timestamp: getCurrentTimestamp(),
 	}
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		info.Code = coded.ErrorCode()
+	}
 
 	// Extract file and line from first stack frame
 	if len(info.Stack) > 0 {
@@ -179,6 +185,11 @@ func (e *ErrorOverlay) renderHTML(info *ErrorInfo, nonce string) string {
 	// Build stack trace HTML
 	stackHTML := e.buildStackHTML(info.Stack)
 
+	typeLabel := info.Type
+	if info.Code != "" {
+		typeLabel = info.Code + " · " + info.Type
+	}
+
 	// Build request info HTML
 	requestHTML := ""
 	if info.Request != nil {
@@ -467,7 +478,7 @@ func (e *ErrorOverlay) renderHTML(info *ErrorInfo, nonce string) string {
 		getThemeColor(theme, "textMuted"),
 		getThemeColor(theme, "border"),
 		getThemeColor(theme, "codeBg"),
-		escapeHTML(info.Type),
+		escapeHTML(typeLabel),
 		escapeHTML(info.Message),
 		e.buildEditorURL(info.File, info.Line),
 		escapeHTML(info.File),
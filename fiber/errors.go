@@ -32,8 +32,66 @@ const (
 	ErrorCodeTimeout ErrorCode = "TIMEOUT"
 	// ErrorCodeUnavailable represents a service unavailable error
 	ErrorCodeUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	// ErrorCodeRateLimited represents a rate limit rejection
+	ErrorCodeRateLimited ErrorCode = "RATE_LIMITED"
+	// ErrorCodeInvalidPayload represents a malformed message or body
+	ErrorCodeInvalidPayload ErrorCode = "INVALID_PAYLOAD"
+	// ErrorCodeActionNotFound represents an unknown action name
+	ErrorCodeActionNotFound ErrorCode = "ACTION_NOT_FOUND"
+	// ErrorCodeUnknownMessage represents an unknown WebSocket message type
+	ErrorCodeUnknownMessage ErrorCode = "UNKNOWN_MESSAGE_TYPE"
+	// ErrorCodeSessionRequired represents a missing session token
+	ErrorCodeSessionRequired ErrorCode = "SESSION_REQUIRED"
+	// ErrorCodeInvalidSession represents an invalid or expired session
+	ErrorCodeInvalidSession ErrorCode = "INVALID_SESSION"
+	// ErrorCodeLimitExceeded represents a per-connection limit being reached
+	ErrorCodeLimitExceeded ErrorCode = "LIMIT_EXCEEDED"
+	// ErrorCodeCSRF represents a missing or mismatched CSRF token
+	ErrorCodeCSRF ErrorCode = "CSRF_INVALID"
+	// ErrorCodeCanceled represents a call the client canceled
+	ErrorCodeCanceled ErrorCode = "CANCELED"
 )
 
+// StatusErrorCode returns the code for an HTTP status without a more
+// specific one.
+func StatusErrorCode(status int) ErrorCode {
+	switch status {
+	case fiberpkg.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case fiberpkg.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case fiberpkg.StatusForbidden:
+		return ErrorCodeForbidden
+	case fiberpkg.StatusNotFound:
+		return ErrorCodeNotFound
+	case fiberpkg.StatusConflict:
+		return ErrorCodeConflict
+	case fiberpkg.StatusUnprocessableEntity:
+		return ErrorCodeValidation
+	case fiberpkg.StatusRequestTimeout, fiberpkg.StatusGatewayTimeout:
+		return ErrorCodeTimeout
+	case fiberpkg.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case fiberpkg.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	}
+	if status >= 500 {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeBadRequest
+}
+
+// ErrorBody is the standard error envelope, {"error": message, "code": code},
+// used by HTTP error responses and, with "type": "error", WebSocket replies.
+func ErrorBody(code ErrorCode, message string) fiberpkg.Map {
+	return fiberpkg.Map{"error": message, "code": code}
+}
+
+// wsError is ErrorBody as a WebSocket reply.
+func wsError(code ErrorCode, message string) map[string]interface{} {
+	return map[string]interface{}{"type": "error", "error": message, "code": code}
+}
+
 // AppError represents an application error.
 type AppError struct {
 	Code       ErrorCode              `json:"code"`
@@ -49,6 +107,11 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// ErrorCode returns the error's stable code.
+func (e *AppError) ErrorCode() string {
+	return string(e.Code)
+}
+
 // NewAppError creates a new application error.
 func NewAppError(code ErrorCode, message string, statusCode int) *AppError {
 	return &AppError{
@@ -124,9 +187,9 @@ func ErrorHandler(config ErrorHandlerConfig) fiberpkg.ErrorHandler {
 		case *AppError:
 			appErr = e
 		case *fiberpkg.Error:
-			appErr = NewAppError(ErrorCodeInternal, e.Message, e.Code)
+			appErr = NewAppError(StatusErrorCode(e.Code), e.Message, e.Code)
 		default:
-			slog.Default().Error("gospa internal error", "err", err)
+			slog.Default().Error("gospa internal error", "request_id", GetRequestID(c), "err", err)
 			message := "Internal server error"
 			if config.DevMode {
 				message = err.Error()
@@ -155,12 +218,13 @@ func ErrorHandler(config ErrorHandlerConfig) fiberpkg.ErrorHandler {
 		accept := c.Get("Accept")
 		if len(accept) >= 16 && accept[:16] == "application/json" {
 			// JSON response — never include state
-			return c.Status(appErr.StatusCode).JSON(fiberpkg.Map{
-				"error":   appErr.Code,
-				"message": appErr.Message,
-				"details": appErr.Details,
-				"recover": appErr.Recover,
-			})
+			body := ErrorBody(appErr.Code, appErr.Message)
+			body["details"] = appErr.Details
+			body["recover"] = appErr.Recover
+			if id := GetRequestID(c); id != "" {
+				body["requestId"] = id
+			}
+			return c.Status(appErr.StatusCode).JSON(body)
 		}
 
 		// HTML response
@@ -0,0 +1,73 @@
+package fiber
+
+import (
+	"log/slog"
+	"time"
+)
+
+// hubReapInterval is how often the hub scans for dead clients.
+const hubReapInterval = 5 * time.Second
+
+func (h *WSHub) reapLoop() {
+	ticker := time.NewTicker(hubReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			h.reapDead(now)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// reapDead force-unregisters clients that the connection deadlines failed to
+// clean up and returns how many it removed.
+func (h *WSHub) reapDead(now time.Time) int {
+	var dead []*WSClient
+	h.Range(func(client *WSClient) bool {
+		if reason := client.deadReason(now); reason != "" {
+			slog.Default().Warn("reaping dead websocket client", "client", client.ID, "reason", reason)
+			dead = append(dead, client)
+		}
+		return true
+	})
+	reaped := 0
+	for _, client := range dead {
+		if h.reap(client) {
+			reaped++
+		}
+	}
+	return reaped
+}
+
+// deadReason reports why client should be reaped, or "" if it looks alive.
+// Write and read deadlines normally end a dead connection well before these
+// thresholds; the reaper covers connections where they did not fire.
+func (c *WSClient) deadReason(now time.Time) string {
+	if since := c.writingSince.Load(); since != 0 && c.writeWait > 0 &&
+		now.Sub(time.Unix(0, since)) > 2*c.writeWait {
+		return "write stuck"
+	}
+	if seen := c.lastSeen.Load(); seen != 0 && c.pongWait > 0 &&
+		now.Sub(time.Unix(0, seen)) > 2*c.pongWait {
+		return "heartbeat timeout"
+	}
+	return ""
+}
+
+// reap removes client from the hub and runs its disconnect hook right away,
+// so presence stays accurate even while its goroutines are still unwinding.
+func (h *WSHub) reap(client *WSClient) bool {
+	if !h.remove(client) {
+		return false
+	}
+	// A stuck WritePump holds client.mu inside the blocked write. Closing the
+	// connection directly fails that write, after which Close can proceed.
+	if client.Conn != nil {
+		_ = client.Conn.Close()
+	}
+	go client.Close()
+	client.notifyDisconnect()
+	return true
+}
@@ -0,0 +1,88 @@
+package fiber
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestWSHubReapDead(t *testing.T) {
+	hub := NewWSHub(store.NewMemoryPubSub())
+	defer hub.Close()
+
+	var disconnects atomic.Int32
+	config := WebSocketConfig{
+		PongWait:     time.Minute,
+		WriteWait:    time.Second,
+		OnDisconnect: func(*WSClient) { disconnects.Add(1) },
+	}
+	now := time.Now()
+
+	stuck := NewWSClient("stuck", nil, config)
+	stuck.writingSince.Store(now.Add(-3 * time.Second).UnixNano())
+	silent := NewWSClient("silent", nil, config)
+	silent.lastSeen.Store(now.Add(-3 * time.Minute).UnixNano())
+	alive := NewWSClient("alive", nil, config)
+	alive.lastSeen.Store(now.UnixNano())
+	alive.writingSince.Store(now.UnixNano())
+	for _, c := range []*WSClient{stuck, silent, alive} {
+		hub.register(c)
+	}
+
+	if n := hub.reapDead(now); n != 2 {
+		t.Fatalf("reaped %d clients, want 2", n)
+	}
+	if _, ok := hub.GetClient("stuck"); ok {
+		t.Fatal("stuck client still registered")
+	}
+	if _, ok := hub.GetClient("silent"); ok {
+		t.Fatal("silent client still registered")
+	}
+	if _, ok := hub.GetClient("alive"); !ok {
+		t.Fatal("live client was reaped")
+	}
+
+	// The handler's own disconnect path must not fire the hook again.
+	stuck.notifyDisconnect()
+	if got := disconnects.Load(); got != 2 {
+		t.Fatalf("OnDisconnect ran %d times, want 2", got)
+	}
+}
+
+func TestWebSocketConfigHeartbeat(t *testing.T) {
+	pong, ping, write := WebSocketConfig{}.heartbeat()
+	if pong != defaultPongWait || ping != defaultPongWait*9/10 || write != defaultWriteWait {
+		t.Fatalf("defaults = %v/%v/%v", pong, ping, write)
+	}
+	pong, ping, _ = WebSocketConfig{PongWait: 10 * time.Second, PingPeriod: 20 * time.Second}.heartbeat()
+	if pong != 10*time.Second || ping != 9*time.Second {
+		t.Fatalf("ping period must stay below pong wait, got %v/%v", pong, ping)
+	}
+}
+
+func TestWSHubDrain(t *testing.T) {
+	hub := NewWSHub(store.NewMemoryPubSub())
+	defer hub.Close()
+
+	clients := []*WSClient{
+		NewWSClient("a", nil, WebSocketConfig{}),
+		NewWSClient("b", nil, WebSocketConfig{}),
+	}
+	for _, c := range clients {
+		hub.register(c)
+	}
+
+	if n := hub.Drain(); n != 2 {
+		t.Fatalf("drained %d clients, want 2", n)
+	}
+	for _, c := range clients {
+		c.mu.Lock()
+		closed := c.closed
+		c.mu.Unlock()
+		if !closed {
+			t.Fatalf("client %s not closed", c.ID)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+
+	json "github.com/goccy/go-json"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// clientChannelPrefix starts the PubSub channels of single clients.
+const clientChannelPrefix = "gospa:client:"
+
+// clientChannel is the PubSub channel for messages addressed to one client.
+// Every hub subscribes to the channels of its own clients, so BroadcastTo and
+// SendToClient reach a client on any node without sticky sessions.
+func clientChannel(clientID string) string {
+	return clientChannelPrefix + clientID
+}
+
+// hubSubscriptions holds the PubSub subscriptions a hub makes for its own
+// lifetime.
+type hubSubscriptions struct {
+	mu     sync.Mutex
+	unsubs []store.Unsubscribe
+	closed bool
+}
+
+// add keeps unsub until close, or calls it at once if the hub is closed.
+func (s *hubSubscriptions) add(unsub store.Unsubscribe) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		unsub()
+		return
+	}
+	s.unsubs = append(s.unsubs, unsub)
+	s.mu.Unlock()
+}
+
+// close cancels the subscriptions.
+func (s *hubSubscriptions) close() {
+	s.mu.Lock()
+	unsubs := s.unsubs
+	s.unsubs = nil
+	s.closed = true
+	s.mu.Unlock()
+	for _, unsub := range unsubs {
+		unsub()
+	}
+}
+
+// keepSubscription keeps a subscription until the hub is closed. Failures are
+// logged: the hub then only delivers messages published on this process.
+func (h *WSHub) keepSubscription(unsub store.Unsubscribe, err error) {
+	if err != nil {
+		slog.Default().Warn("pubsub subscribe failed", "err", err)
+		return
+	}
+	h.subs.add(unsub)
+}
+
+// subscribeClientPattern subscribes the hub to every client channel with one
+// pattern subscription when the PubSub supports it. Each process then receives
+// the messages of all clients and drops those of clients it does not hold,
+// instead of keeping a subscription, and with Redis a connection, per client.
+func (h *WSHub) subscribeClientPattern() {
+	ps, ok := h.pubsub.(store.PatternPubSub)
+	if !ok {
+		return
+	}
+	unsub, err := ps.PSubscribe(context.Background(), clientChannelPrefix+"*", func(_ context.Context, msg store.Message) {
+		if client, ok := h.GetClient(strings.TrimPrefix(msg.Channel, clientChannelPrefix)); ok {
+			client.deliverRemote(msg.Payload)
+		}
+	})
+	if err != nil {
+		if !errors.Is(err, store.ErrPatternsUnsupported) {
+			slog.Default().Warn("failed to subscribe to client channels", "err", err)
+		}
+		return
+	}
+	h.subs.add(unsub)
+	h.clientPattern = true
+}
+
+// clientEnvelope is a message published on a client channel.
+type clientEnvelope struct {
+	// Frame is queued as-is (BroadcastTo).
+	Frame []byte `json:"frame,omitempty"`
+	// Value is re-encoded in the client's serialization format (SendToClient).
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// subscribeClient subscribes the hub to client's channel, unless
+// subscribeClientPattern covers it. Failures are logged; the client still
+// receives messages sent from this process.
+func (h *WSHub) subscribeClient(client *WSClient) {
+	if h.clientPattern {
+		return
+	}
+	unsub, err := h.pubsub.Subscribe(context.Background(), clientChannel(client.ID), client.deliverRemote)
+	if err != nil {
+		slog.Default().Warn("failed to subscribe to client channel", "id", client.ID, "err", err)
+		return
+	}
+	client.mu.Lock()
+	if client.closed {
+		client.mu.Unlock()
+		unsub()
+		return
+	}
+	client.unsubRemote = unsub
+	client.mu.Unlock()
+}
+
+// unsubscribeClient drops the subscription made by subscribeClient.
+func (c *WSClient) unsubscribeClient() {
+	c.mu.Lock()
+	unsub := c.unsubRemote
+	c.unsubRemote = nil
+	c.mu.Unlock()
+	if unsub != nil {
+		unsub()
+	}
+}
+
+// publishToClient sends env to a client connected to another process.
+func (h *WSHub) publishToClient(clientID string, env clientEnvelope) error {
+	data, err := JSONMarshal(env)
+	if err != nil {
+		return err
+	}
+	return h.publish(clientChannel(clientID), data)
+}
+
+// deliverRemote queues a message published on the client's channel.
+func (c *WSClient) deliverRemote(message []byte) {
+	var env clientEnvelope
+	if err := JSONUnmarshal(message, &env); err != nil {
+		return
+	}
+	if len(env.Value) == 0 {
+		if len(env.Frame) > 0 {
+			c.trySend(env.Frame)
+		}
+		return
+	}
+	if c.serializer == nil && c.format != "msgpack" && !HasCustomJSONCodec() {
+		c.trySend(env.Value)
+		return
+	}
+	var v interface{}
+	if err := JSONUnmarshal(env.Value, &v); err == nil {
+		_ = c.SendJSON(v)
+	}
+}
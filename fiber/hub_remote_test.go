@@ -0,0 +1,108 @@
+package fiber
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// newNodePair returns two hubs sharing a PubSub, standing in for two nodes.
+func newNodePair(t *testing.T) (*WSHub, *WSHub) {
+	t.Helper()
+	pubsub := store.NewMemoryPubSub()
+	a, b := NewWSHub(pubsub), NewWSHub(pubsub)
+	t.Cleanup(a.Close)
+	t.Cleanup(b.Close)
+	return a, b
+}
+
+func receive(t *testing.T, c *WSClient) []byte {
+	t.Helper()
+	select {
+	case msg := <-c.Send:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("client %s received nothing", c.ID)
+	}
+	return nil
+}
+
+func TestBroadcastToRemoteClient(t *testing.T) {
+	nodeA, nodeB := newNodePair(t)
+	client := &WSClient{ID: "remote", Send: make(chan []byte, 4)}
+	nodeB.register(client)
+
+	nodeA.BroadcastTo([]string{"remote"}, []byte(`{"type":"alert"}`))
+	if msg := receive(t, client); string(msg) != `{"type":"alert"}` {
+		t.Fatalf("unexpected frame %q", msg)
+	}
+
+	if err := SendToClient(nodeA, "remote", map[string]interface{}{"type": "note", "n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, client); string(msg) != `{"n":1,"type":"note"}` {
+		t.Fatalf("unexpected frame %q", msg)
+	}
+
+	client.closed = true // no connection to close
+	nodeB.unregister(client)
+	nodeA.BroadcastTo([]string{"remote"}, []byte(`{"type":"late"}`))
+	select {
+	case msg := <-client.Send:
+		t.Fatalf("unregistered client received %q", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSendToRemoteClientUsesClientFormat(t *testing.T) {
+	nodeA, nodeB := newNodePair(t)
+	client := NewWSClient("packed", nil, WebSocketConfig{SerializationFormat: "msgpack"})
+	nodeB.register(client)
+
+	if err := SendToClient(nodeA, "packed", map[string]interface{}{"type": "note"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]interface{}
+	if err := msgpack.Unmarshal(receive(t, client), &msg); err != nil || msg["type"] != "note" {
+		t.Fatalf("expected a msgpack frame, got %v (%v)", msg, err)
+	}
+}
+
+// plainPubSub hides the pattern support of the PubSub it wraps.
+type plainPubSub struct{ store.PubSub }
+
+func TestBroadcastToRemoteClientWithoutPatterns(t *testing.T) {
+	pubsub := plainPubSub{store.NewMemoryPubSub()}
+	nodeA, nodeB := NewWSHub(pubsub), NewWSHub(pubsub)
+	defer nodeA.Close()
+	defer nodeB.Close()
+	if nodeB.clientPattern {
+		t.Fatal("hub used a pattern subscription on a plain PubSub")
+	}
+	client := &WSClient{ID: "remote", Send: make(chan []byte, 4)}
+	nodeB.register(client)
+
+	nodeA.BroadcastTo([]string{"remote"}, []byte(`{"type":"alert"}`))
+	if msg := receive(t, client); string(msg) != `{"type":"alert"}` {
+		t.Fatalf("unexpected frame %q", msg)
+	}
+}
+
+func TestCloseDropsSubscriptions(t *testing.T) {
+	nodeA, nodeB := newNodePair(t)
+	client := &WSClient{ID: "remote", Send: make(chan []byte, 4)}
+	nodeB.register(client)
+	if !nodeB.clientPattern {
+		t.Fatal("hub did not use a pattern subscription")
+	}
+
+	nodeB.Close()
+	nodeA.BroadcastTo([]string{"remote"}, []byte(`{"type":"late"}`))
+	select {
+	case msg := <-client.Send:
+		t.Fatalf("client of a closed hub received %q", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
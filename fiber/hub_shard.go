@@ -0,0 +1,333 @@
+package fiber
+
+import (
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Number of shards the hub splits its client registry across. Clients are
+	// placed by client ID and session indexes by session ID, so register,
+	// unregister, lookups, and broadcasts only contend within one shard.
+	hubShardCount = 32
+	// Size of each shard's broadcast queue
+	hubShardQueueSize = 256
+)
+
+var hubShardSeed = maphash.MakeSeed()
+
+// hubShard holds a slice of the hub's client registry. Each shard has its own
+// lock and delivery goroutine, so a full broadcast fans out in parallel.
+type hubShard struct {
+	mu      sync.RWMutex
+	clients map[string]*WSClient
+	// sessions indexes clients by session ID for the session IDs that hash
+	// to this shard; it is independent of where the clients themselves live.
+	sessions map[string]map[string]*WSClient
+	jobs     chan shardJob
+
+	acquired  atomic.Uint64
+	contended atomic.Uint64
+	waitNanos atomic.Int64
+}
+
+// shardJob is one broadcast delivered to every client in a shard.
+type shardJob struct {
+	message []byte
+	update  *syncUpdate
+	// exceptID skips one client (BroadcastExcept).
+	exceptID string
+	// direct sends the frame as-is, bypassing sync coalescing.
+	direct bool
+	// done, if set, is signalled once the shard has delivered the job.
+	done *sync.WaitGroup
+}
+
+// HubStats reports the size of a hub's client registry and how contended its
+// shard locks are.
+type HubStats struct {
+	Clients  int `json:"clients"`
+	Sessions int `json:"sessions"`
+	Shards   int `json:"shards"`
+	// MaxShardClients is the client count of the fullest shard.
+	MaxShardClients int `json:"maxShardClients"`
+	// LockAcquisitions counts shard lock acquisitions since the hub started.
+	LockAcquisitions uint64 `json:"lockAcquisitions"`
+	// LockContended counts acquisitions that had to wait for another holder.
+	LockContended uint64 `json:"lockContended"`
+	// LockWait is the total time spent waiting on contended shard locks.
+	LockWait time.Duration `json:"lockWait"`
+	// StateQuotaRejected counts updates rejected by a StateQuota.
+	StateQuotaRejected uint64 `json:"stateQuotaRejected"`
+	// StateQuotaEvicted counts keys evicted by a QuotaEvictOldest StateQuota.
+	StateQuotaEvicted uint64 `json:"stateQuotaEvicted"`
+	// SessionResyncs counts connections that presented a session token
+	// issued by another process under session affinity.
+	SessionResyncs uint64 `json:"sessionResyncs"`
+}
+
+func newHubShards(stop <-chan struct{}) []*hubShard {
+	shards := make([]*hubShard, hubShardCount)
+	for i := range shards {
+		s := &hubShard{
+			clients:  make(map[string]*WSClient),
+			sessions: make(map[string]map[string]*WSClient),
+			jobs:     make(chan shardJob, hubShardQueueSize),
+		}
+		shards[i] = s
+		go s.run(stop)
+	}
+	return shards
+}
+
+func (s *hubShard) lock() {
+	s.acquired.Add(1)
+	if s.mu.TryLock() {
+		return
+	}
+	start := time.Now()
+	s.mu.Lock()
+	s.contended.Add(1)
+	s.waitNanos.Add(int64(time.Since(start)))
+}
+
+func (s *hubShard) rlock() {
+	s.acquired.Add(1)
+	if s.mu.TryRLock() {
+		return
+	}
+	start := time.Now()
+	s.mu.RLock()
+	s.contended.Add(1)
+	s.waitNanos.Add(int64(time.Since(start)))
+}
+
+func (s *hubShard) run(stop <-chan struct{}) {
+	for {
+		select {
+		case job := <-s.jobs:
+			s.deliver(job)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *hubShard) deliver(job shardJob) {
+	s.rlock()
+	for id, client := range s.clients {
+		if id == job.exceptID {
+			continue
+		}
+		if job.update != nil && !client.wantsKey(job.update.key) {
+			continue
+		}
+		if job.direct {
+			client.trySend(job.message)
+		} else {
+			client.deliver(job.message, job.update)
+		}
+	}
+	s.mu.RUnlock()
+	if job.done != nil {
+		job.done.Done()
+	}
+}
+
+func (h *WSHub) shardFor(key string) *hubShard {
+	return h.shards[maphash.String(hubShardSeed, key)%hubShardCount]
+}
+
+// register adds client to the registry, replacing and closing any existing
+// client with the same ID. It is safe to call concurrently.
+func (h *WSHub) register(client *WSClient) {
+	shard := h.shardFor(client.ID)
+	shard.lock()
+	old := shard.clients[client.ID]
+	shard.clients[client.ID] = client
+	sessionID := client.SessionID
+	shard.mu.Unlock()
+
+	if old != nil && old != client {
+		h.unindexSession(old, old.SessionID)
+		h.dropTopics(old)
+		old.unsubscribeClient()
+		old.Close()
+	}
+	if sessionID != "" {
+		h.indexSession(client, sessionID)
+	}
+	if old != client {
+		h.subscribeClient(client)
+	}
+	slog.Default().Debug("client connected", "id", client.ID)
+}
+
+// unregister removes client from the registry and closes it. It is a no-op
+// if client has already been replaced by a newer connection with its ID.
+func (h *WSHub) unregister(client *WSClient) {
+	if h.remove(client) {
+		// Use guarded Close() to prevent double-close panics
+		client.Close()
+	}
+	slog.Default().Debug("client disconnected", "id", client.ID)
+}
+
+// remove drops client from the registry and its indexes without closing it.
+// It reports whether client was still the registered connection for its ID.
+func (h *WSHub) remove(client *WSClient) bool {
+	shard := h.shardFor(client.ID)
+	shard.lock()
+	existing, ok := shard.clients[client.ID]
+	if !ok || existing != client {
+		shard.mu.Unlock()
+		return false
+	}
+	delete(shard.clients, client.ID)
+	sessionID := client.SessionID
+	shard.mu.Unlock()
+
+	h.unindexSession(client, sessionID)
+	h.dropTopics(client)
+	client.unsubscribeClient()
+	return true
+}
+
+// bindSession sets the client's session ID once it has been authenticated and
+// indexes it for session-scoped broadcasts.
+func (h *WSHub) bindSession(client *WSClient, sessionID string) {
+	shard := h.shardFor(client.ID)
+	shard.lock()
+	prev := client.SessionID
+	client.SessionID = sessionID
+	_, registered := shard.clients[client.ID]
+	shard.mu.Unlock()
+
+	if prev == sessionID || !registered {
+		return
+	}
+	h.unindexSession(client, prev)
+	h.indexSession(client, sessionID)
+}
+
+func (h *WSHub) indexSession(client *WSClient, sessionID string) {
+	shard := h.shardFor(sessionID)
+	shard.lock()
+	if shard.sessions[sessionID] == nil {
+		shard.sessions[sessionID] = make(map[string]*WSClient)
+	}
+	shard.sessions[sessionID][client.ID] = client
+	shard.mu.Unlock()
+}
+
+func (h *WSHub) unindexSession(client *WSClient, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	shard := h.shardFor(sessionID)
+	shard.lock()
+	clients, ok := shard.sessions[sessionID]
+	if !ok || clients[client.ID] != client {
+		shard.mu.Unlock()
+		return
+	}
+	delete(clients, client.ID)
+	empty := len(clients) == 0
+	if empty {
+		delete(shard.sessions, sessionID)
+	}
+	shard.mu.Unlock()
+	if empty {
+		h.versions.forget(sessionID)
+	}
+}
+
+// dropTopics removes client from the topic index.
+func (h *WSHub) dropTopics(client *WSClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Cleanup topic-based indexing (PERF-02)
+	for topic := range client.topics {
+		if clients, ok := h.ClientsByTopic[topic]; ok && clients[client.ID] == client {
+			delete(clients, client.ID)
+			if len(clients) == 0 {
+				delete(h.ClientsByTopic, topic)
+			}
+		}
+	}
+}
+
+// sessionClients returns the clients bound to sessionID.
+func (h *WSHub) sessionClients(sessionID string) []*WSClient {
+	shard := h.shardFor(sessionID)
+	shard.rlock()
+	defer shard.mu.RUnlock()
+	clients := shard.sessions[sessionID]
+	if len(clients) == 0 {
+		return nil
+	}
+	targets := make([]*WSClient, 0, len(clients))
+	for _, client := range clients {
+		targets = append(targets, client)
+	}
+	return targets
+}
+
+// fanOut queues job on every shard. A shard whose queue is full delivers in
+// the caller's goroutine instead, so a stalled shard applies backpressure
+// rather than spawning goroutines or dropping the broadcast.
+func (h *WSHub) fanOut(job shardJob) {
+	if job.done != nil {
+		job.done.Add(len(h.shards))
+	}
+	for _, s := range h.shards {
+		select {
+		case s.jobs <- job:
+		default:
+			s.deliver(job)
+		}
+	}
+}
+
+// Range calls fn for each connected client until fn returns false. Clients
+// registered or removed during the call may or may not be visited.
+func (h *WSHub) Range(fn func(*WSClient) bool) {
+	for _, s := range h.shards {
+		s.rlock()
+		clients := make([]*WSClient, 0, len(s.clients))
+		for _, client := range s.clients {
+			clients = append(clients, client)
+		}
+		s.mu.RUnlock()
+		for _, client := range clients {
+			if !fn(client) {
+				return
+			}
+		}
+	}
+}
+
+// Stats returns the registry size and shard lock contention counters.
+func (h *WSHub) Stats() HubStats {
+	stats := HubStats{
+		Shards:             len(h.shards),
+		StateQuotaRejected: h.quotaRejected.Load(),
+		StateQuotaEvicted:  h.quotaEvicted.Load(),
+		SessionResyncs:     h.resyncs.Load(),
+	}
+	for _, s := range h.shards {
+		s.mu.RLock()
+		n := len(s.clients)
+		stats.Sessions += len(s.sessions)
+		s.mu.RUnlock()
+		stats.Clients += n
+		stats.MaxShardClients = max(stats.MaxShardClients, n)
+		stats.LockAcquisitions += s.acquired.Load()
+		stats.LockContended += s.contended.Load()
+		stats.LockWait += time.Duration(s.waitNanos.Load())
+	}
+	return stats
+}
@@ -0,0 +1,164 @@
+package fiber
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func newShardTestHub(tb testing.TB, n int) (*WSHub, []*WSClient) {
+	tb.Helper()
+	hub := NewWSHub(store.NewMemoryPubSub())
+	tb.Cleanup(hub.Close)
+	clients := make([]*WSClient, n)
+	for i := range clients {
+		clients[i] = &WSClient{ID: fmt.Sprintf("c%d", i), Send: make(chan []byte, 4)}
+		hub.register(clients[i])
+	}
+	return hub, clients
+}
+
+func TestWSHubFanOutReachesEveryShard(t *testing.T) {
+	hub, clients := newShardTestHub(t, 200)
+
+	var wg sync.WaitGroup
+	hub.fanOut(shardJob{message: []byte(`{"type":"ping"}`), direct: true, done: &wg})
+	wg.Wait()
+	for _, c := range clients {
+		if len(c.Send) != 1 {
+			t.Fatalf("client %s got %d messages, want 1", c.ID, len(c.Send))
+		}
+		<-c.Send
+	}
+
+	clients[0].closed = true // no connection to close
+	hub.unregister(clients[0])
+	hub.fanOut(shardJob{message: []byte(`{"type":"ping"}`), exceptID: clients[1].ID, direct: true, done: &wg})
+	wg.Wait()
+	if len(clients[1].Send) != 0 {
+		t.Fatal("excluded client received the broadcast")
+	}
+	if len(clients[2].Send) != 1 {
+		t.Fatal("remaining client missed the broadcast")
+	}
+	if hub.ClientCount() != 199 {
+		t.Fatalf("ClientCount = %d, want 199", hub.ClientCount())
+	}
+}
+
+func TestWSHubBroadcastExcept(t *testing.T) {
+	hub, clients := newShardTestHub(t, 3)
+	hub.BroadcastExcept(clients[0].ID, []byte("hi"))
+
+	for _, c := range clients[1:] {
+		select {
+		case msg := <-c.Send:
+			if string(msg) != "hi" {
+				t.Fatalf("unexpected message %q", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("client %s did not receive the broadcast", c.ID)
+		}
+	}
+	select {
+	case <-clients[0].Send:
+		t.Fatal("excluded client received the broadcast")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// BenchmarkWSHubFanOut measures the time for one full broadcast to reach
+// every client.
+func BenchmarkWSHubFanOut(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("clients=%d", n), func(b *testing.B) {
+			hub, clients := newShardTestHub(b, n)
+			msg := []byte(`{"type":"sync","key":"count","value":1}`)
+			update, _ := parseSyncMessage(msg)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var wg sync.WaitGroup
+				hub.fanOut(shardJob{message: msg, update: update, done: &wg})
+				wg.Wait()
+
+				b.StopTimer()
+				for _, c := range clients {
+					<-c.Send
+				}
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/client")
+		})
+	}
+}
+
+func TestWSHubSessionIndex(t *testing.T) {
+	hub, clients := newShardTestHub(t, 4)
+	hub.bindSession(clients[0], "s1")
+	hub.bindSession(clients[1], "s1")
+	hub.bindSession(clients[2], "s2")
+
+	if got := len(hub.sessionClients("s1")); got != 2 {
+		t.Fatalf("s1 has %d clients, want 2", got)
+	}
+	stats := hub.Stats()
+	if stats.Clients != 4 || stats.Sessions != 2 || stats.Shards != hubShardCount {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// A replacement connection with the same ID takes over its session slot.
+	replacement := &WSClient{ID: clients[0].ID, SessionID: "s1", Send: make(chan []byte, 4)}
+	clients[0].closed = true // no connection to close
+	hub.register(replacement)
+	if got, _ := hub.GetClient(clients[0].ID); got != replacement {
+		t.Fatal("expected the replacement client to be registered")
+	}
+	if got := len(hub.sessionClients("s1")); got != 2 {
+		t.Fatalf("s1 has %d clients after replacement, want 2", got)
+	}
+
+	// Unregistering the stale connection must not evict its replacement.
+	hub.unregister(clients[0])
+	if _, ok := hub.GetClient(clients[0].ID); !ok {
+		t.Fatal("stale unregister removed the replacement")
+	}
+
+	clients[2].closed = true
+	hub.unregister(clients[2])
+	if hub.sessionClients("s2") != nil || hub.Stats().Sessions != 1 {
+		t.Fatal("expected s2 to be dropped with its last client")
+	}
+}
+
+// BenchmarkWSHubChurn registers and unregisters clients from many goroutines
+// while lookups run, and reports how often a shard lock had to wait.
+func BenchmarkWSHubChurn(b *testing.B) {
+	hub := NewWSHub(store.NewMemoryPubSub())
+	defer hub.Close()
+	var seq atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			id := fmt.Sprintf("c%d", seq.Add(1))
+			client := &WSClient{ID: id, Send: make(chan []byte, 1)}
+			hub.register(client)
+			hub.bindSession(client, "s"+id)
+			_, _ = hub.GetClient(id)
+			client.closed = true
+			hub.unregister(client)
+		}
+	})
+	b.StopTimer()
+	stats := hub.Stats()
+	if stats.LockAcquisitions > 0 {
+		b.ReportMetric(float64(stats.LockContended)/float64(stats.LockAcquisitions)*100, "%contended")
+	}
+}
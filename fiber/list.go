@@ -0,0 +1,231 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultListPageSize is the page size for "list" frames without a limit.
+	defaultListPageSize = 50
+	// maxListPageSize bounds the items one "list" frame may ask for.
+	maxListPageSize = 500
+	// maxListCursorLen bounds the cursor a client may send.
+	maxListCursorLen = 1024
+	// listFetchTimeout bounds each ListSource.Fetch call.
+	listFetchTimeout = 10 * time.Second
+)
+
+// List event operations.
+const (
+	// ListUpsert adds an item or replaces the one with the same ID.
+	ListUpsert = "upsert"
+	// ListRemove removes the item with the event's ID.
+	ListRemove = "remove"
+	// ListReset tells clients to load the list again, e.g. after a bulk
+	// change or a reorder.
+	ListReset = "reset"
+)
+
+// ListItem is an element of a list, identified by ID so later events can
+// replace or remove it.
+type ListItem struct {
+	ID   string      `json:"id"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// ListPage is one page of a list.
+type ListPage struct {
+	Items []ListItem `json:"items"`
+	// Next is the cursor of the following page, "" on the last one.
+	Next string `json:"next,omitempty"`
+	// Total is the size of the whole list, if known.
+	Total int `json:"total,omitempty"`
+}
+
+// ListEvent is a change to a list, sent to every client that loaded it.
+type ListEvent struct {
+	// Op is ListUpsert, ListRemove or ListReset.
+	Op   string   `json:"op"`
+	Item ListItem `json:"item,omitempty"`
+	// Before places a new item in front of the item with this ID. Without
+	// it, clients append new items once they have loaded the last page.
+	Before string `json:"before,omitempty"`
+}
+
+// ListSource serves a large collection to clients page by page, instead of
+// keeping it in the StateMap where every change patches the whole value.
+type ListSource interface {
+	// Fetch returns up to limit items following cursor, "" for the first
+	// page, as client may see them. An *AppError is sent to the client with
+	// its code, so sources can answer ErrForbidden.
+	Fetch(ctx context.Context, client *WSClient, cursor string, limit int) (ListPage, error)
+}
+
+// ListSourceFunc adapts a function to ListSource.
+type ListSourceFunc func(ctx context.Context, client *WSClient, cursor string, limit int) (ListPage, error)
+
+// Fetch calls f.
+func (f ListSourceFunc) Fetch(ctx context.Context, client *WSClient, cursor string, limit int) (ListPage, error) {
+	return f(ctx, client, cursor, limit)
+}
+
+// listRegistry holds the lists served through a hub.
+type listRegistry struct {
+	mu sync.RWMutex
+	// sources holds pointers so the function RegisterListSource returns can
+	// tell its registration from a later one; ListSourceFuncs don't compare.
+	sources map[string]*ListSource
+}
+
+// listTopic is the hub topic clients join to receive events for list name.
+func listTopic(name string) string {
+	return "list:" + name
+}
+
+// RegisterListSource serves src as the list name. Clients load it a page at
+// a time with "list" frames and, once they have, receive the events passed
+// to PublishListEvents as "list_patch" frames. The returned function stops
+// serving it.
+func (h *WSHub) RegisterListSource(name string, src ListSource) func() {
+	entry := &src
+	h.lists.mu.Lock()
+	h.lists.sources[name] = entry
+	h.lists.mu.Unlock()
+	return func() {
+		h.lists.mu.Lock()
+		defer h.lists.mu.Unlock()
+		if h.lists.sources[name] == entry {
+			delete(h.lists.sources, name)
+		}
+	}
+}
+
+// ListSource returns the source registered as name.
+func (h *WSHub) ListSource(name string) (ListSource, bool) {
+	h.lists.mu.RLock()
+	defer h.lists.mu.RUnlock()
+	if entry, ok := h.lists.sources[name]; ok {
+		return *entry, true
+	}
+	return nil, false
+}
+
+// PublishListEvents sends events for the list name to the clients that
+// loaded it, on this or any other process sharing the hub's PubSub.
+func (h *WSHub) PublishListEvents(name string, events ...ListEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, ev := range events {
+		switch ev.Op {
+		case ListUpsert, ListRemove, ListReset:
+		default:
+			return errors.New("gospa: unknown list event op " + ev.Op)
+		}
+	}
+	frame, err := JSONMarshal(map[string]interface{}{
+		"type": "list_patch",
+		"key":  name,
+		"data": map[string]interface{}{"events": events},
+	})
+	if err != nil {
+		return err
+	}
+	h.BroadcastToTopic(listTopic(name), frame)
+	return nil
+}
+
+// listRequest is the payload of a client "list" frame.
+type listRequest struct {
+	List   string `json:"list"`
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+	// Close stops the events for the list.
+	Close bool `json:"close"`
+}
+
+// handleListMessage processes a client "list" frame: it replies with the page
+// following the cursor and subscribes the client to the list's events, or
+// unsubscribes it when the frame closes the list.
+func handleListMessage(client *WSClient, msg WSMessage, sendResponse func(map[string]interface{})) {
+	var req listRequest
+	b, ok := msg.Payload.([]byte)
+	if !ok {
+		b, _ = JSONMarshal(msg.Payload)
+	}
+	if err := JSONUnmarshal(b, &req); err != nil || req.List == "" || len(req.List) > maxTopicLen ||
+		len(req.Cursor) > maxListCursorLen || req.Limit < 0 {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid list payload"))
+		return
+	}
+	if client.hub == nil {
+		sendResponse(wsError(ErrorCodeUnavailable, "Lists are unavailable"))
+		return
+	}
+	src, ok := client.hub.ListSource(req.List)
+	if !ok {
+		sendResponse(wsError(ErrorCodeNotFound, "Unknown list: "+req.List))
+		return
+	}
+	if req.Close {
+		client.hub.Unsubscribe(listTopic(req.List), client.ID)
+		sendResponse(map[string]interface{}{"type": "list", "key": req.List, "data": map[string]interface{}{"closed": true}})
+		return
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultListPageSize
+	}
+	limit = min(limit, maxListPageSize)
+	// Subscribe before fetching, so no event published in between is missed.
+	topic := listTopic(req.List)
+	if !client.hub.subscribeWithLimit(topic, client, maxClientTopics) {
+		sendResponse(wsError(ErrorCodeLimitExceeded, "Too many open lists"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), listFetchTimeout)
+	defer cancel()
+	page, err := src.Fetch(ctx, client, req.Cursor, limit)
+	if err != nil {
+		if req.Cursor == "" {
+			// Nothing of the list is loaded.
+			client.hub.Unsubscribe(topic, client.ID)
+		}
+		var appErr *AppError
+		if errors.As(err, &appErr) {
+			sendResponse(wsError(appErr.Code, appErr.Message))
+			return
+		}
+		slog.Default().Error("list fetch failed", "list", req.List, "client", client.ID, "request_id", client.RequestID, "err", err)
+		sendResponse(wsError(ErrorCodeInternal, "Failed to load list"))
+		return
+	}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+	}
+	if page.Items == nil {
+		page.Items = []ListItem{}
+	}
+	sendResponse(map[string]interface{}{
+		"type": "list",
+		"key":  req.List,
+		"data": map[string]interface{}{
+			"cursor": req.Cursor,
+			"items":  page.Items,
+			"next":   page.Next,
+			"total":  page.Total,
+		},
+	})
+}
+
+// isReservedTopic reports whether topic belongs to a shared CRDT or list,
+// which clients join through their own frames.
+func isReservedTopic(topic string) bool {
+	return strings.HasPrefix(topic, crdtTopic("")) || strings.HasPrefix(topic, listTopic(""))
+}
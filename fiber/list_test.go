@@ -0,0 +1,94 @@
+package fiber
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listFrame sends a "list" frame with payload and returns the response.
+func listFrame(client *WSClient, payload map[string]interface{}) map[string]interface{} {
+	var resp map[string]interface{}
+	handleListMessage(client, WSMessage{Type: "list", Payload: payload}, func(p map[string]interface{}) {
+		resp = p
+	})
+	return resp
+}
+
+func TestListSourcePagesAndEvents(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	// 120 rows, paged by the index after the cursor.
+	stop := hub.RegisterListSource("rows", ListSourceFunc(func(_ context.Context, _ *WSClient, cursor string, limit int) (ListPage, error) {
+		start, _ := strconv.Atoi(cursor)
+		var page ListPage
+		for i := start; i < 120 && len(page.Items) < limit; i++ {
+			page.Items = append(page.Items, ListItem{ID: strconv.Itoa(i), Data: i})
+		}
+		if end := start + len(page.Items); end < 120 {
+			page.Next = strconv.Itoa(end)
+		}
+		page.Total = 120
+		return page, nil
+	}))
+	defer stop()
+	hub.RegisterListSource("secret", ListSourceFunc(func(context.Context, *WSClient, string, int) (ListPage, error) {
+		return ListPage{}, ErrForbidden
+	}))
+	viewer := newTopicTestClient(hub, "viewer", nil)
+	other := newTopicTestClient(hub, "other", nil)
+
+	resp := listFrame(viewer, map[string]interface{}{"list": "rows"})
+	data, _ := resp["data"].(map[string]interface{})
+	if resp["type"] != "list" || resp["key"] != "rows" || len(data["items"].([]ListItem)) != defaultListPageSize || data["next"] != "50" {
+		t.Fatalf("first page = %v", resp)
+	}
+	resp = listFrame(viewer, map[string]interface{}{"list": "rows", "cursor": "100", "limit": 1000})
+	data, _ = resp["data"].(map[string]interface{})
+	if items := data["items"].([]ListItem); len(items) != 20 || items[0].ID != "100" || data["next"] != "" {
+		t.Fatalf("last page = %v", resp)
+	}
+
+	for _, payload := range []map[string]interface{}{{"list": "missing"}, {"list": "secret"}, {"list": ""}, {"list": "rows", "limit": -1}} {
+		if resp := listFrame(other, payload); resp["type"] != "error" {
+			t.Fatalf("list %v = %v, want error", payload, resp)
+		}
+	}
+	if resp := listFrame(other, map[string]interface{}{"list": "secret"}); resp["code"] != ErrorCodeForbidden {
+		t.Fatalf("forbidden list = %v", resp)
+	}
+	if resp := topicFrame(other, "subscribe", listTopic("rows")); resp["type"] != "error" {
+		t.Fatalf("subscribe to a list topic = %v, want error", resp)
+	}
+
+	if err := hub.PublishListEvents("rows", ListEvent{Op: ListUpsert, Item: ListItem{ID: "7", Data: "seven"}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-viewer.Send:
+		if s := string(msg); !strings.Contains(s, `"list_patch"`) || !strings.Contains(s, `"seven"`) {
+			t.Fatalf("unexpected frame %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the client that loaded the list did not receive the event")
+	}
+	select {
+	case msg := <-other.Send:
+		t.Fatalf("a client that never loaded the list received %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := hub.PublishListEvents("rows", ListEvent{Op: "move"}); err == nil {
+		t.Fatal("PublishListEvents accepted an unknown op")
+	}
+
+	if resp := listFrame(viewer, map[string]interface{}{"list": "rows", "close": true}); resp["type"] != "list" {
+		t.Fatalf("close = %v", resp)
+	}
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.ClientsByTopic[listTopic("rows")]) != 0 {
+		t.Fatal("client still receives events after closing the list")
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"github.com/aydenstechdungeon/gospa/routing"
 	"github.com/aydenstechdungeon/gospa/state"
 	gospatempl "github.com/aydenstechdungeon/gospa/templ"
-	json "github.com/goccy/go-json"
 	gofiber "github.com/gofiber/fiber/v3"
 )
 
@@ -127,23 +126,28 @@ func StateMiddleware(config Config) gofiber.Handler {
 		// Encode appends a trailing newline; trim it for inline embedding.
 		escapedJSON := strings.TrimRight(buf.String(), "\n")
 		configScript := ""
+		configFields := make([]string, 0, 2)
 		if csrfToken, _ := c.Locals("gospa.csrf_token").(string); isValidCSRFToken(csrfToken) {
 			csrfJSON, err := stdjson.Marshal(csrfToken)
 			if err == nil {
-				configScript = `window.__GOSPA_CONFIG__ = Object.assign(window.__GOSPA_CONFIG__ || {}, { csrfToken: ` + string(csrfJSON) + ` });`
+				configFields = append(configFields, `csrfToken: `+string(csrfJSON))
 			}
 		}
-		stateScript := `<script` + nonceAttr + `>` + configScript + `window.__GOSPA_STATE__ = ` + escapedJSON + `;</script>`
-
-		runtimePath := config.RuntimeScript
-		if strings.HasPrefix(runtimePath, "/_gospa/runtime.js") {
-			opts := routing.GetRouteOptions(c.Path())
-			if opts.RuntimeTier != "" && opts.RuntimeTier != "full" {
-				runtimePath = "/_gospa/runtime-" + opts.RuntimeTier + ".js"
+		// The request ID lets the runtime tie its WebSocket to this render.
+		if requestID := GetRequestID(c); requestID != "" {
+			idJSON, err := stdjson.Marshal(requestID)
+			if err == nil {
+				configFields = append(configFields, `requestId: `+string(idJSON))
 			}
 		}
+		if len(configFields) > 0 {
+			configScript = `window.__GOSPA_CONFIG__ = Object.assign(window.__GOSPA_CONFIG__ || {}, { ` + strings.Join(configFields, ", ") + ` });`
+		}
+		stateScript := `<script` + nonceAttr + `>` + configScript + `window.__GOSPA_STATE__ = ` + escapedJSON + `;</script>`
+
+		runtimePath := routeRuntimeScript(config.RuntimeScript, routing.GetRouteOptions(c.Path()))
 
-		if config.StartupChecks {
+		if config.StartupChecks && runtimePath != "" {
 			stateScript += buildStartupSelfCheckScript(nonceAttr, startupCheckOptions{
 				RuntimeScript:   runtimePath,
 				WebSocketPath:   config.WebSocketPath,
@@ -153,7 +157,7 @@ func StateMiddleware(config Config) gofiber.Handler {
 		}
 
 		// Always inject the runtime script if not already present in the HTML.
-		if config.RuntimeScript != "" && !bytes.Contains(body, []byte(config.RuntimeScript)) {
+		if runtimePath != "" && !bytes.Contains(body, []byte(config.RuntimeScript)) {
 			stateScript += `<script src="` + runtimePath + `" type="module"` + nonceAttr + `></script>`
 		}
 
@@ -345,10 +349,12 @@ func generateCSPNonce() (string, error) {
 // This mitigates XSS risks compared to storing tokens in sessionStorage.
 func SessionMiddleware() gofiber.Handler {
 	return func(c gofiber.Ctx) error {
+		stores := requestStateStores(c)
 		cookie := c.Cookies("gospa_session")
-		if cookie != "" {
+		if cookie != "" && !stores.Sessions.Foreign(cookie) {
 			// Validate existing session
-			if _, ok := globalSessionStore.ValidateSession(cookie); ok {
+			if clientID, ok := stores.Sessions.ValidateSession(cookie); ok {
+				stores.ClientState.Touch(clientID)
 				c.Locals("gospa.session", cookie)
 				return c.Next()
 			}
@@ -356,10 +362,11 @@ func SessionMiddleware() gofiber.Handler {
 
 		// Create new session
 		clientID := generateComponentID()
-		token, err := globalSessionStore.CreateSession(clientID)
+		token, err := stores.Sessions.CreateSession(clientID)
 		if err != nil {
 			return c.Next()
 		}
+		stores.ClientState.Touch(clientID)
 
 		c.Cookie(&gofiber.Cookie{
 			Name:     "gospa_session",
@@ -389,9 +396,7 @@ func CSRFTokenMiddleware() gofiber.Handler {
 
 		cookie := c.Cookies("csrf_token")
 		if cookie == "" || !isValidCSRFToken(cookie) {
-			return c.Status(gofiber.StatusForbidden).JSON(gofiber.Map{
-				"error": "CSRF token missing",
-			})
+			return c.Status(gofiber.StatusForbidden).JSON(ErrorBody(ErrorCodeCSRF, "CSRF token missing"))
 		}
 
 		token := c.FormValue("_csrf")
@@ -399,15 +404,11 @@ func CSRFTokenMiddleware() gofiber.Handler {
 			token = c.Get("X-CSRF-Token")
 		}
 		if token == "" {
-			return c.Status(gofiber.StatusForbidden).JSON(gofiber.Map{
-				"error": "CSRF token mismatch",
-			})
+			return c.Status(gofiber.StatusForbidden).JSON(ErrorBody(ErrorCodeCSRF, "CSRF token mismatch"))
 		}
 
 		if subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) != 1 {
-			return c.Status(gofiber.StatusForbidden).JSON(gofiber.Map{
-				"error": "CSRF token mismatch",
-			})
+			return c.Status(gofiber.StatusForbidden).JSON(ErrorBody(ErrorCodeCSRF, "CSRF token mismatch"))
 		}
 
 		return c.Next()
@@ -424,6 +425,9 @@ type PreloadConfig struct {
 	// CSSLinks contains stylesheets to preload with high priority
 	CSSLinks []string
 	Enabled  bool
+	// LazyRuntime preloads only stylesheets, for pages that load the runtime
+	// after first paint.
+	LazyRuntime bool
 	// BuildManifest is the loaded manifest.json (optional)
 	BuildManifest map[string]string
 }
@@ -461,18 +465,21 @@ func PreloadHeadersMiddleware(config PreloadConfig) gofiber.Handler {
 			links = append(links, fmt.Sprintf("<%s>; rel=preload; as=style", css))
 		}
 
+		// Pages served without the runtime, or loading it lazily, only need
+		// their stylesheets.
+		runtimePath := routeRuntimeScript(config.RuntimeScript, routing.GetRouteOptions(c.Path()))
+		if config.LazyRuntime || (runtimePath == "" && config.RuntimeScript != "") {
+			if len(links) > 0 {
+				c.Set("Link", strings.Join(links, ", "))
+			}
+			return nil
+		}
+
 		// 2. Preload explicit core files
 		if config.CoreScript != "" {
 			links = append(links, fmt.Sprintf("<%s>; rel=modulepreload", config.CoreScript))
 		}
-		if config.RuntimeScript != "" {
-			runtimePath := config.RuntimeScript
-			if strings.HasPrefix(runtimePath, "/_gospa/runtime.js") {
-				opts := routing.GetRouteOptions(c.Path())
-				if opts.RuntimeTier != "" && opts.RuntimeTier != "full" {
-					runtimePath = "/_gospa/runtime-" + opts.RuntimeTier + ".js"
-				}
-			}
+		if runtimePath != "" {
 			links = append(links, fmt.Sprintf("<%s>; rel=modulepreload", runtimePath))
 		}
 
@@ -544,6 +551,23 @@ func PreloadHeadersMiddleware(config PreloadConfig) gofiber.Handler {
 	}
 }
 
+// routeRuntimeScript returns the runtime script a route with opts loads:
+// script, the tier's build of the embedded runtime, or "" when the route sets
+// Runtime to routing.RuntimeNone.
+func routeRuntimeScript(script string, opts routing.RouteOptions) string {
+	tier := opts.RuntimeTier
+	if opts.Runtime != "" {
+		tier = strings.ToLower(opts.Runtime)
+	}
+	if tier == routing.RuntimeNone {
+		return ""
+	}
+	if strings.HasPrefix(script, "/_gospa/runtime.js") && tier != "" && tier != "full" {
+		return "/_gospa/runtime-" + tier + ".js"
+	}
+	return script
+}
+
 // PreloadHeadersMiddlewareMinimal adds minimal preload headers for micro-runtime.
 func PreloadHeadersMiddlewareMinimal(config PreloadConfig) gofiber.Handler {
 	return func(c gofiber.Ctx) error {
@@ -710,6 +734,7 @@ func RequestLoggerMiddleware() gofiber.Handler {
 		start := time.Now()
 		err := c.Next()
 		logger.Info("request",
+			"request_id", GetRequestID(c),
 			"method", c.Method(),
 			"path", c.Path(),
 			"status", c.Response().StatusCode(),
@@ -724,9 +749,7 @@ func RecoveryMiddleware() gofiber.Handler {
 	return func(c gofiber.Ctx) error {
 		defer func() {
 			if r := recover(); r != nil {
-				_ = c.Status(gofiber.StatusInternalServerError).JSON(gofiber.Map{
-					"error": "Internal server error",
-				})
+				_ = c.Status(gofiber.StatusInternalServerError).JSON(ErrorBody(ErrorCodeInternal, "Internal server error"))
 			}
 		}()
 		return c.Next()
@@ -757,7 +780,7 @@ func RenderComponent(c gofiber.Ctx, config Config, component templ.Component, co
 	if stateMap != nil {
 		stateData := make(map[string]any)
 		if jsonData, err := stateMap.ToJSON(); err == nil {
-			_ = json.Unmarshal([]byte(jsonData), &stateData)
+			_ = JSONUnmarshal([]byte(jsonData), &stateData)
 		}
 		opts = append(opts, gospatempl.WithProps(stateData))
 	}
@@ -832,14 +855,12 @@ func JSONResponse(c gofiber.Ctx, status int, data interface{}) error {
 
 // JSONError sends a JSON error response.
 func JSONError(c gofiber.Ctx, status int, message string) error {
-	return c.Status(status).JSON(gofiber.Map{
-		"error": message,
-	})
+	return c.Status(status).JSON(ErrorBody(StatusErrorCode(status), message))
 }
 
 // ParseBody parses request body into a struct.
 func ParseBody(c gofiber.Ctx, v interface{}) error {
-	return json.Unmarshal(c.Body(), v)
+	return JSONUnmarshal(c.Body(), v)
 }
 
 // GetSessionState gets or creates session state.
@@ -854,7 +875,7 @@ func GetSessionState(c gofiber.Ctx, config Config) map[string]interface{} {
 	if err != nil {
 		return result
 	}
-	_ = json.Unmarshal([]byte(jsonData), &result)
+	_ = JSONUnmarshal([]byte(jsonData), &result)
 	return result
 }
 
@@ -874,7 +895,31 @@ func SetFlash(c gofiber.Ctx, key string, value interface{}) {
 	if !ok || token == "" {
 		return
 	}
-	_ = globalSessionStore.SetFlash(token, key, value)
+	_ = requestStateStores(c).Sessions.SetFlash(token, key, value)
+}
+
+// RevokeClientSessions invalidates every session token issued for clientID,
+// the ID the session's tokens resolve to, e.g. on logout from all devices or
+// after a security event. It returns the number of tokens revoked. It uses
+// the global stores; an app with stores of its own revokes through them,
+// see StateStores.RevokeClientSessions.
+func RevokeClientSessions(clientID string) (int, error) {
+	return GlobalStateStores().RevokeClientSessions(clientID)
+}
+
+// SessionClientID returns the client ID the request's gospa_session token
+// resolves to, or "" when the request has no valid session. Unlike the token,
+// the client ID is safe to log and store.
+func SessionClientID(c gofiber.Ctx) string {
+	token, _ := c.Locals("gospa.session").(string)
+	if token == "" {
+		token = c.Cookies("gospa_session")
+	}
+	if token == "" {
+		return ""
+	}
+	clientID, _ := requestStateStores(c).Sessions.ValidateSession(token)
+	return clientID
 }
 
 // GetFlashes retrieves and clears all flash messages from the current session.
@@ -883,5 +928,5 @@ func GetFlashes(c gofiber.Ctx) map[string]interface{} {
 	if !ok || token == "" {
 		return nil
 	}
-	return globalSessionStore.GetFlashes(token)
+	return requestStateStores(c).Sessions.GetFlashes(token)
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/aydenstechdungeon/gospa/routing"
 	"github.com/aydenstechdungeon/gospa/state"
 	gofiber "github.com/gofiber/fiber/v3"
 )
@@ -108,6 +109,27 @@ func TestPreloadHeadersMiddleware(t *testing.T) {
 	}
 }
 
+func TestPreloadHeadersMiddleware_RouteWithoutRuntime(t *testing.T) {
+	routing.RegisterPageWithOptions("/preload-static", nil, routing.RouteOptions{Runtime: routing.RuntimeNone})
+	app := gofiber.New()
+	config := DefaultPreloadConfig()
+	config.CSSLinks = []string{"/style.css"}
+	app.Use(PreloadHeadersMiddleware(config))
+	app.Get("/preload-static", func(c gofiber.Ctx) error {
+		c.Set("Content-Type", "text/html")
+		return c.SendString("<html></html>")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/preload-static", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if link := resp.Header.Get("Link"); link != "</style.css>; rel=preload; as=style" {
+		t.Errorf("Link = %q, want only the stylesheet", link)
+	}
+}
+
 func TestFlashMessages(t *testing.T) {
 	app := gofiber.New()
 	// Mock session middleware
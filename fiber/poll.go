@@ -0,0 +1,221 @@
+package fiber
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"time"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// PollHeader carries the token of a connection opened through PollHandler.
+const PollHeader = "X-GoSPA-Poll"
+
+const (
+	// maxPollWait is the longest a poll waits for messages.
+	maxPollWait = 25 * time.Second
+	// maxPollBatch bounds the messages one poll returns.
+	maxPollBatch = 64
+)
+
+// pollConn is a client connected through PollHandler.
+type pollConn struct {
+	token  string
+	client *WSClient
+	// mu serializes the first message, which starts the session, with the
+	// ones after it.
+	mu      sync.Mutex
+	started bool
+	release func()
+}
+
+// PollHandler serves the WebSocket protocol over plain HTTP requests, for
+// networks that block WebSocket. Its clients live in config.Hub next to the
+// WebSocket ones, so sessions, broadcasts, topics, actions and the config's
+// hooks work the same for both:
+//
+//   - POST without the X-GoSPA-Poll header opens a connection and returns
+//     {"token": "..."}.
+//   - POST with the token sends one message. The first one is handled like
+//     the first WebSocket message, which carries the init data.
+//   - GET with the token waits up to 25s for messages and returns them as a
+//     JSON array, or 410 Gone once the connection is closed.
+//   - DELETE with the token closes the connection.
+//
+// Messages are JSON whatever config.SerializationFormat says. Clients that
+// stop polling are dropped by the hub reaper after twice PongWait. limiter
+// limits opens per IP; nil uses the global WebSocket connection limiter.
+func PollHandler(config WebSocketConfig, limiter *ConnectionRateLimiter) fiberpkg.Handler {
+	if config.Hub == nil {
+		config.Hub = NewWSHub(nil)
+		go config.Hub.Run()
+	}
+	if config.GenerateID == nil {
+		config.GenerateID = generateComponentID
+	}
+	if config.OnMessage == nil {
+		config.OnMessage = DefaultMessageHandler
+	}
+	if limiter == nil {
+		limiter = globalConnRateLimiter
+	}
+	var mu sync.Mutex
+	conns := make(map[string]*pollConn)
+
+	open := func(c fiberpkg.Ctx) error {
+		if ip := GetIPFromContext(c); !limiter.Allow(ip) {
+			slog.Default().Warn("poll rate limit exceeded", "ip", ip)
+			return c.Status(fiberpkg.StatusTooManyRequests).JSON(ErrorBody(ErrorCodeRateLimited, "Rate limit exceeded. Please try again later."))
+		}
+		client := NewWSClient("conn_"+generateSecureToken()[:8], nil, config)
+		client.RequestID, _ = c.Locals(requestIDLocal).(string)
+		if config.WSMaxMessageSize > 0 {
+			client.maxMessageSize = int64(config.WSMaxMessageSize)
+		}
+		// Poll responses frame messages as a JSON array.
+		client.format, client.serializer, client.deserializer = "json", nil, nil
+
+		pc := &pollConn{token: generateSecureToken(), client: client}
+		client.onDisconnect = func(client *WSClient) {
+			mu.Lock()
+			delete(conns, pc.token)
+			mu.Unlock()
+			pc.mu.Lock()
+			started, release := pc.started, pc.release
+			pc.release = nil
+			pc.mu.Unlock()
+			if release != nil {
+				release()
+			}
+			if started && config.OnDisconnect != nil {
+				config.OnDisconnect(client)
+			}
+		}
+		mu.Lock()
+		conns[pc.token] = pc
+		mu.Unlock()
+		config.Hub.register(client)
+		client.markSeen()
+		return c.JSON(fiberpkg.Map{"token": pc.token})
+	}
+
+	closeConn := func(pc *pollConn) {
+		config.Hub.unregister(pc.client)
+		pc.client.notifyDisconnect()
+	}
+
+	send := func(c fiberpkg.Ctx, pc *pollConn) error {
+		client := pc.client
+		body := c.Body()
+		if int64(len(body)) > client.maxMessageSize {
+			return c.Status(fiberpkg.StatusRequestEntityTooLarge).JSON(ErrorBody(ErrorCodeLimitExceeded, "Message too large"))
+		}
+		client.markSeen()
+
+		pc.mu.Lock()
+		if !pc.started {
+			var initMsg WSMessage
+			if err := client.Unmarshal(body, &initMsg); err != nil {
+				pc.mu.Unlock()
+				closeConn(pc)
+				return c.Status(fiberpkg.StatusBadRequest).JSON(ErrorBody(ErrorCodeInvalidPayload, "Invalid initial message format"))
+			}
+			sessionToken := c.Cookies("gospa_session")
+			if sessionToken == "" {
+				sessionToken, _ = c.Locals("gospa.session").(string)
+			}
+			release, err := startSession(config, client, initMsg, sessionToken, c.Cookies(ClientPersistCookieName))
+			if err != nil {
+				pc.mu.Unlock()
+				closeConn(pc)
+				return c.Status(fiberpkg.StatusInternalServerError).JSON(ErrorBody(ErrorCodeInternal, "Failed to create session"))
+			}
+			pc.started, pc.release = true, release
+			pc.mu.Unlock()
+			config.OnMessage(client, initMsg)
+			return c.SendStatus(fiberpkg.StatusNoContent)
+		}
+		pc.mu.Unlock()
+
+		if msg, ok := client.decodeMessage(body); ok {
+			config.OnMessage(client, msg)
+		}
+		return c.SendStatus(fiberpkg.StatusNoContent)
+	}
+
+	poll := func(c fiberpkg.Ctx, pc *pollConn) error {
+		client := pc.client
+		client.markSeen()
+		defer client.markSeen()
+		timer := time.NewTimer(min(maxPollWait, client.pongWait/2))
+		defer timer.Stop()
+
+		var frames [][]byte
+		select {
+		case frame, ok := <-client.Send:
+			if !ok {
+				closeConn(pc)
+				return c.SendStatus(fiberpkg.StatusGone)
+			}
+			frames = append(frames, frame)
+		case <-timer.C:
+		case <-c.RequestCtx().Done():
+		}
+	batch:
+		for len(frames) > 0 && len(frames) < maxPollBatch {
+			select {
+			case frame, ok := <-client.Send:
+				if !ok {
+					// The next poll reports the close.
+					break batch
+				}
+				frames = append(frames, frame)
+			default:
+				break batch
+			}
+		}
+
+		c.Set(fiberpkg.HeaderContentType, fiberpkg.MIMEApplicationJSON)
+		return c.Send(append(append([]byte{'['}, bytes.Join(frames, []byte{','})...), ']'))
+	}
+
+	return func(c fiberpkg.Ctx) error {
+		c.Set("Cache-Control", "no-store")
+		token := c.Get(PollHeader)
+		if token == "" {
+			if c.Method() != fiberpkg.MethodPost {
+				return c.Status(fiberpkg.StatusBadRequest).JSON(ErrorBody(ErrorCodeBadRequest, "Missing "+PollHeader+" header"))
+			}
+			return open(c)
+		}
+		mu.Lock()
+		pc := conns[token]
+		mu.Unlock()
+		if pc == nil {
+			return c.SendStatus(fiberpkg.StatusGone)
+		}
+		if pc.client.isClosed() {
+			// Closed by the hub, e.g. Drain.
+			closeConn(pc)
+			return c.SendStatus(fiberpkg.StatusGone)
+		}
+		switch c.Method() {
+		case fiberpkg.MethodGet:
+			return poll(c, pc)
+		case fiberpkg.MethodPost:
+			return send(c, pc)
+		case fiberpkg.MethodDelete:
+			closeConn(pc)
+			return c.SendStatus(fiberpkg.StatusNoContent)
+		}
+		return c.SendStatus(fiberpkg.StatusMethodNotAllowed)
+	}
+}
+
+// isClosed reports whether the client has been closed.
+func (c *WSClient) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
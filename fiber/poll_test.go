@@ -0,0 +1,96 @@
+package fiber
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestPollHandler(t *testing.T) {
+	hub := NewWSHub(nil)
+	go hub.Run()
+	defer hub.Close()
+	var connects, disconnects atomic.Int32
+	limiter := NewConnectionRateLimiter(store.NewMemoryStorage())
+	defer limiter.Close()
+	app := fiberpkg.New()
+	app.All("/_gospa/poll", PollHandler(WebSocketConfig{
+		Hub:          hub,
+		PongWait:     200 * time.Millisecond,
+		OnConnect:    func(*WSClient) { connects.Add(1) },
+		OnDisconnect: func(*WSClient) { disconnects.Add(1) },
+	}, limiter))
+
+	do := func(method, token, body string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/_gospa/poll", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(PollHeader, token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+	poll := func(token string) []map[string]interface{} {
+		t.Helper()
+		status, body := do(http.MethodGet, token, "")
+		var frames []map[string]interface{}
+		if status != http.StatusOK || json.Unmarshal([]byte(body), &frames) != nil {
+			t.Fatalf("poll = %d %s", status, body)
+		}
+		return frames
+	}
+
+	status, body := do(http.MethodPost, "", "")
+	var opened struct{ Token string }
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &opened) != nil || opened.Token == "" {
+		t.Fatalf("open = %d %s", status, body)
+	}
+	if hub.ClientCount() != 1 {
+		t.Fatalf("hub has %d clients after open", hub.ClientCount())
+	}
+
+	if status, _ := do(http.MethodPost, opened.Token, `{"type":"init"}`); status != http.StatusNoContent {
+		t.Fatalf("init = %d", status)
+	}
+	if frames := poll(opened.Token); len(frames) == 0 || frames[0]["type"] != "init" {
+		t.Fatalf("first poll = %v", frames)
+	}
+	if connects.Load() != 1 {
+		t.Fatalf("OnConnect ran %d times", connects.Load())
+	}
+
+	do(http.MethodPost, opened.Token, `{"type":"ping"}`)
+	if frames := poll(opened.Token); len(frames) != 1 || frames[0]["type"] != "pong" {
+		t.Fatalf("ping poll = %v", frames)
+	}
+	// Nothing queued: the poll times out with an empty batch.
+	if frames := poll(opened.Token); len(frames) != 0 {
+		t.Fatalf("idle poll = %v", frames)
+	}
+
+	if status, _ := do(http.MethodDelete, opened.Token, ""); status != http.StatusNoContent {
+		t.Fatalf("close = %d", status)
+	}
+	if status, _ := do(http.MethodGet, opened.Token, ""); status != http.StatusGone {
+		t.Fatalf("poll after close = %d", status)
+	}
+	if hub.ClientCount() != 0 || disconnects.Load() != 1 {
+		t.Fatalf("after close: %d clients, %d disconnects", hub.ClientCount(), disconnects.Load())
+	}
+	if status, _ := do(http.MethodGet, "", ""); status != http.StatusBadRequest {
+		t.Fatalf("poll without token = %d", status)
+	}
+}
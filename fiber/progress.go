@@ -0,0 +1,32 @@
+package fiber
+
+import (
+	"errors"
+	"math"
+)
+
+// errInvalidActionID is returned by WSClient.Progress for an action ID the
+// client could not have sent.
+var errInvalidActionID = errors.New("gospa: progress needs the action's request ID")
+
+// Progress reports how far the action with request ID actionID has got, as
+// a percentage clamped to 0-100 and an optional message, e.g. for a
+// progress bar while an export is generated. The client sees
+// {"type":"progress","data":{"requestId","percent","message"}}, which
+// callAction's onProgress option receives.
+func (c *WSClient) Progress(actionID string, pct float64, msg string) error {
+	if !ValidRequestID(actionID) {
+		return errInvalidActionID
+	}
+	if math.IsNaN(pct) {
+		pct = 0
+	}
+	return c.SendJSON(map[string]interface{}{
+		"type": "progress",
+		"data": map[string]interface{}{
+			"requestId": actionID,
+			"percent":   math.Min(math.Max(pct, 0), 100),
+			"message":   msg,
+		},
+	})
+}
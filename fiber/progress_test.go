@@ -0,0 +1,50 @@
+package fiber
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nextFrame returns the next frame queued for client.
+func nextFrame(t *testing.T, client *WSClient) string {
+	t.Helper()
+	select {
+	case msg := <-client.Send:
+		return string(msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame sent to the client")
+		return ""
+	}
+}
+
+func TestActionProgress(t *testing.T) {
+	RegisterContextActionHandler("test-progress-report", func(ctx context.Context, client *WSClient, _ interface{}) error {
+		if err := client.Progress(ActionID(ctx), 40, "halfway"); err != nil {
+			return err
+		}
+		return client.Progress(ActionID(ctx), 250, "")
+	})
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c1", nil)
+
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test-progress-report", Data: map[string]interface{}{"_requestId": "req_9"}})
+	if s := nextFrame(t, client); s != `{"data":{"message":"halfway","percent":40,"requestId":"req_9"},"type":"progress"}` {
+		t.Fatalf("first progress frame = %s", s)
+	}
+	if s := nextFrame(t, client); !strings.Contains(s, `"percent":100`) || !strings.Contains(s, `"type":"progress"`) {
+		t.Fatalf("clamped progress frame = %s", s)
+	}
+	if s := nextFrame(t, client); !strings.Contains(s, `"action_ack"`) || !strings.Contains(s, `"_responseId":"req_9"`) {
+		t.Fatalf("action reply = %s", s)
+	}
+
+	if err := client.Progress("", 10, ""); err == nil {
+		t.Fatal("progress without an action ID was sent")
+	}
+	if id := ActionID(context.Background()); id != "" {
+		t.Fatalf("ActionID outside an action = %q", id)
+	}
+}
@@ -0,0 +1,283 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+const (
+	// publishAttempts is how often a message is tried on the PubSub,
+	// counting the first attempt, before it is given up.
+	publishAttempts = 4
+	// publishBackoff is the wait before the first retry; it doubles after
+	// each one.
+	publishBackoff = 50 * time.Millisecond
+	// publishRetryQueueSize bounds the failed messages waiting for a retry.
+	// Messages failing while it is full are given up at once.
+	publishRetryQueueSize = 256
+	// defaultDeadLetterKey is the Storage key of the dead-letter index.
+	defaultDeadLetterKey = "gospa:deadletter"
+	// defaultDeadLetterTTL is how long dead letters are kept.
+	defaultDeadLetterTTL = 24 * time.Hour
+)
+
+// PublishError describes a message the hub gave up publishing on its PubSub.
+type PublishError struct {
+	Channel string
+	Message []byte
+	// Attempts is how often publishing was tried.
+	Attempts int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("gospa: publish on %s failed after %d attempts: %v", e.Channel, e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// DeadLetter is a message kept after publishing it failed, see
+// WSHub.SetDeadLetter.
+type DeadLetter struct {
+	ID      string    `json:"id"`
+	Channel string    `json:"channel"`
+	Message []byte    `json:"message"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+// DeadLetterConfig configures where a hub keeps the messages it could not
+// publish.
+type DeadLetterConfig struct {
+	// Storage holds the dead letters. It must implement store.SetStorage.
+	// Processes sharing it and Key share their dead letters.
+	Storage store.Storage
+	// Key prefixes the Storage keys used (default "gospa:deadletter").
+	Key string
+	// TTL is how long dead letters are kept (default 24h).
+	TTL time.Duration
+}
+
+// deadLetters is a configured DeadLetterConfig.
+type deadLetters struct {
+	storage store.Storage
+	sets    store.SetStorage
+	key     string
+	ttl     time.Duration
+}
+
+// publishFailures holds what happens to messages the hub gives up on.
+type publishFailures struct {
+	mu         sync.RWMutex
+	handlers   []func(*PublishError)
+	deadLetter *deadLetters
+}
+
+// pendingPublish is a failed message waiting for a retry.
+type pendingPublish struct {
+	channel string
+	data    []byte
+	err     error
+}
+
+// OnPublishError registers fn to be called with each message the hub gives
+// up publishing on its PubSub, after retrying with backoff. Such messages
+// reach no process, not even this one. Handlers must not block.
+func (h *WSHub) OnPublishError(fn func(*PublishError)) {
+	if fn == nil {
+		return
+	}
+	h.failures.mu.Lock()
+	defer h.failures.mu.Unlock()
+	h.failures.handlers = append(h.failures.handlers, fn)
+}
+
+// SetDeadLetter keeps the messages the hub gives up publishing in
+// cfg.Storage, where DeadLetters lists them and Redeliver publishes them
+// again.
+func (h *WSHub) SetDeadLetter(cfg DeadLetterConfig) error {
+	sets, ok := cfg.Storage.(store.SetStorage)
+	if !ok {
+		return errors.New("gospa: dead-letter storage must implement store.SetStorage")
+	}
+	dl := &deadLetters{storage: cfg.Storage, sets: sets, key: cfg.Key, ttl: cfg.TTL}
+	if dl.key == "" {
+		dl.key = defaultDeadLetterKey
+	}
+	if dl.ttl <= 0 {
+		dl.ttl = defaultDeadLetterTTL
+	}
+	h.failures.mu.Lock()
+	defer h.failures.mu.Unlock()
+	h.failures.deadLetter = dl
+	return nil
+}
+
+// publish sends data on channel through the hub's PubSub. A failed message
+// is retried in the background, in order with other failed ones, and handed
+// to the OnPublishError handlers and the dead-letter buffer once the retries
+// are exhausted. It returns an error only when the message is given up at
+// once because too many are waiting for a retry.
+func (h *WSHub) publish(channel string, data []byte) error {
+	err := h.pubsub.Publish(context.Background(), channel, data)
+	if err == nil {
+		return nil
+	}
+	select {
+	case h.retries <- pendingPublish{channel: channel, data: data, err: err}:
+		return nil
+	default:
+		perr := &PublishError{Channel: channel, Message: data, Attempts: 1, Err: err}
+		h.publishFailed(perr)
+		return perr
+	}
+}
+
+// retryLoop retries failed messages with exponential backoff until the hub
+// is closed.
+func (h *WSHub) retryLoop() {
+	for {
+		select {
+		case <-h.stop:
+			return
+		case p := <-h.retries:
+			err := p.err
+			backoff := publishBackoff
+			attempts := 1
+			for ; attempts < publishAttempts && err != nil; attempts++ {
+				select {
+				case <-h.stop:
+					return
+				case <-time.After(backoff):
+				}
+				backoff *= 2
+				err = h.pubsub.Publish(context.Background(), p.channel, p.data)
+			}
+			if err != nil {
+				h.publishFailed(&PublishError{Channel: p.channel, Message: p.data, Attempts: attempts, Err: err})
+			}
+		}
+	}
+}
+
+// publishFailed reports a message the hub gave up on.
+func (h *WSHub) publishFailed(perr *PublishError) {
+	slog.Default().Warn("pubsub publish failed", "channel", perr.Channel, "attempts", perr.Attempts, "err", perr.Err)
+	h.failures.mu.RLock()
+	handlers := h.failures.handlers
+	dl := h.failures.deadLetter
+	h.failures.mu.RUnlock()
+	// Stored first, so handlers find the letter in DeadLetters.
+	if dl != nil {
+		if err := dl.add(perr); err != nil {
+			slog.Default().Error("failed to store dead letter", "channel", perr.Channel, "err", err)
+		}
+	}
+	for _, fn := range handlers {
+		fn(perr)
+	}
+}
+
+// add stores perr's message as a dead letter.
+func (dl *deadLetters) add(perr *PublishError) error {
+	ctx := context.Background()
+	letter := DeadLetter{
+		ID:      newRequestID(),
+		Channel: perr.Channel,
+		Message: perr.Message,
+		Error:   perr.Err.Error(),
+		Time:    time.Now(),
+	}
+	data, err := JSONMarshal(letter)
+	if err != nil {
+		return err
+	}
+	if err := dl.storage.Set(ctx, dl.letterKey(letter.ID), data, dl.ttl); err != nil {
+		return err
+	}
+	return dl.sets.SAdd(ctx, dl.key, letter.ID, dl.ttl)
+}
+
+// letterKey is the Storage key of the dead letter id.
+func (dl *deadLetters) letterKey(id string) string {
+	return dl.key + ":" + id
+}
+
+// remove deletes the dead letter id.
+func (dl *deadLetters) remove(ctx context.Context, id string) error {
+	if err := dl.storage.Delete(ctx, dl.letterKey(id)); err != nil {
+		return err
+	}
+	return dl.sets.SRem(ctx, dl.key, id)
+}
+
+// DeadLetters returns the stored dead letters, oldest first, or nil without
+// SetDeadLetter.
+func (h *WSHub) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	h.failures.mu.RLock()
+	dl := h.failures.deadLetter
+	h.failures.mu.RUnlock()
+	if dl == nil {
+		return nil, nil
+	}
+	ids, err := dl.sets.SMembers(ctx, dl.key)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]DeadLetter, 0, len(ids))
+	for _, id := range ids {
+		data, err := dl.storage.Get(ctx, dl.letterKey(id))
+		if errors.Is(err, store.ErrNotFound) {
+			// Expired before the index.
+			_ = dl.sets.SRem(ctx, dl.key, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var letter DeadLetter
+		if err := JSONUnmarshal(data, &letter); err != nil {
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	slices.SortFunc(letters, func(a, b DeadLetter) int {
+		return a.Time.Compare(b.Time)
+	})
+	return letters, nil
+}
+
+// Redeliver publishes the stored dead letters again, oldest first, and
+// removes those that went through. It returns how many did.
+func (h *WSHub) Redeliver(ctx context.Context) (int, error) {
+	letters, err := h.DeadLetters(ctx)
+	if err != nil {
+		return 0, err
+	}
+	h.failures.mu.RLock()
+	dl := h.failures.deadLetter
+	h.failures.mu.RUnlock()
+	sent := 0
+	for _, letter := range letters {
+		if err := h.pubsub.Publish(ctx, letter.Channel, letter.Message); err != nil {
+			return sent, err
+		}
+		sent++
+		if err := dl.remove(ctx, letter.ID); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
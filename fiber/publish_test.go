@@ -0,0 +1,84 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// flakyPubSub fails the first failures publishes.
+type flakyPubSub struct {
+	*store.MemoryPubSub
+	failures atomic.Int32
+}
+
+func (p *flakyPubSub) Publish(ctx context.Context, channel string, message []byte) error {
+	if p.failures.Add(-1) >= 0 {
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryPubSub.Publish(ctx, channel, message)
+}
+
+func TestPublishRetriesThenDeadLetters(t *testing.T) {
+	ps := &flakyPubSub{MemoryPubSub: store.NewMemoryPubSub()}
+	hub := NewWSHub(ps)
+	defer hub.Close()
+	if err := hub.SetDeadLetter(DeadLetterConfig{Storage: store.NewMemoryStorage()}); err != nil {
+		t.Fatal(err)
+	}
+	failed := make(chan *PublishError, 1)
+	hub.OnPublishError(func(perr *PublishError) { failed <- perr })
+	received := make(chan []byte, 2)
+	_, _ = ps.Subscribe(context.Background(), "test", func(msg []byte) { received <- msg })
+
+	// Two failures are covered by the retries.
+	ps.failures.Store(2)
+	if err := hub.publish("test", []byte("retried")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-received:
+		if string(msg) != "retried" {
+			t.Fatalf("received %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the message was not retried")
+	}
+
+	ps.failures.Store(publishAttempts)
+	_ = hub.publish("test", []byte("lost"))
+	select {
+	case perr := <-failed:
+		if perr.Attempts != publishAttempts || string(perr.Message) != "lost" {
+			t.Fatalf("publish error = %+v", perr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnPublishError was not called")
+	}
+	letters, err := hub.DeadLetters(context.Background())
+	if err != nil || len(letters) != 1 || letters[0].Channel != "test" || string(letters[0].Message) != "lost" {
+		t.Fatalf("dead letters = %+v, %v", letters, err)
+	}
+
+	if n, err := hub.Redeliver(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Redeliver = %d, %v", n, err)
+	}
+	if msg := <-received; string(msg) != "lost" {
+		t.Fatalf("redelivered %q", msg)
+	}
+	if letters, _ := hub.DeadLetters(context.Background()); len(letters) != 0 {
+		t.Fatalf("dead letters after Redeliver = %+v", letters)
+	}
+}
+
+func TestSetDeadLetterNeedsSetStorage(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	if err := hub.SetDeadLetter(DeadLetterConfig{Storage: struct{ store.Storage }{store.NewMemoryStorage()}}); err == nil {
+		t.Fatal("SetDeadLetter accepted storage without set support")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
 )
 
 func BenchmarkSSEBrokerBroadcastToTopic(b *testing.B) {
@@ -36,3 +37,70 @@ func BenchmarkWSHubDispatchBroadcast(b *testing.B) {
 		hub.dispatchBroadcast(clients, msg)
 	}
 }
+
+// benchStatePayload builds a state map shaped like typical page state: scalars,
+// a nested user object, and a list of records, with numbers as json.Number
+// when decoded with UseNumber.
+func benchStatePayload(items int, numbers bool) map[string]interface{} {
+	num := func(n int) interface{} {
+		if numbers {
+			return json.Number(fmt.Sprint(n))
+		}
+		return float64(n)
+	}
+	list := make([]interface{}, items)
+	for i := range list {
+		list[i] = map[string]interface{}{"id": num(i), "title": fmt.Sprintf("item %d", i), "done": i%2 == 0}
+	}
+	return map[string]interface{}{
+		"count":   num(42),
+		"query":   "search",
+		"loading": false,
+		"user":    map[string]interface{}{"id": num(7), "name": "Ada", "roles": []interface{}{"admin", "dev"}},
+		"items":   list,
+	}
+}
+
+func BenchmarkComputeStateDiff(b *testing.B) {
+	for _, tc := range []struct {
+		name    string
+		items   int
+		numbers bool
+	}{
+		{"small", 10, false},
+		{"large", 1000, false},
+		{"large-json-number", 1000, true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			prev := benchStatePayload(tc.items, tc.numbers)
+			next := benchStatePayload(tc.items, tc.numbers)
+			next["query"] = "changed"
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if diff := computeStateDiff(prev, next); len(diff) != 1 {
+					b.Fatalf("unexpected diff: %v", diff)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompressState covers the gzip+base64 step applied to large state
+// payloads before they are sent to the client.
+func BenchmarkCompressState(b *testing.B) {
+	data, err := json.Marshal(benchStatePayload(1000, false))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := compressToBase64(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
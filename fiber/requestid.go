@@ -0,0 +1,87 @@
+package fiber
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// RequestIDHeader carries the request ID on requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDLocal is the locals key for the request ID. It is a string so the
+// WebSocket connection, which copies locals by name, still sees it.
+const requestIDLocal = "gospa.request_id"
+
+// maxRequestIDLen bounds the incoming request ID that is trusted as is.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware gives every request an ID. A valid incoming
+// X-Request-ID (from a proxy or the client) is kept, otherwise a random one
+// is generated and written to the request header, so handlers reading
+// X-Request-ID see it too. The ID is echoed in the X-Request-ID and
+// Server-Timing response headers and stored on the request context.
+func RequestIDMiddleware() gofiber.Handler {
+	return func(c gofiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !ValidRequestID(id) {
+			id = newRequestID()
+			c.Request().Header.Set(RequestIDHeader, id)
+		}
+		c.Locals(requestIDLocal, id)
+		c.SetContext(context.WithValue(c.Context(), requestIDKey{}, id))
+		c.Set(RequestIDHeader, id)
+		c.Append("Server-Timing", `reqid;desc="`+id+`"`)
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the ID set by RequestIDMiddleware, or "".
+func GetRequestID(c gofiber.Ctx) string {
+	id, _ := c.Locals(requestIDLocal).(string)
+	return id
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, which may be a
+// fiber.Ctx or the context from its Context method, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	id, _ := ctx.Value(requestIDLocal).(string)
+	return id
+}
+
+// ValidRequestID reports whether id is a non-empty request ID of at most 128
+// letters, digits, '-', '_', '.' or ':'. Anything else could break log lines
+// and headers, so it is replaced rather than propagated.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return generateComponentID()
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,64 @@
+package fiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := gofiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c gofiber.Ctx) error {
+		if RequestIDFromContext(c.Context()) != GetRequestID(c) || RequestIDFromContext(c) != GetRequestID(c) {
+			t.Errorf("context and locals disagree")
+		}
+		return c.SendString(c.Get(RequestIDHeader))
+	})
+
+	get := func(incoming string) (string, string, string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if incoming != "" {
+			req.Header.Set(RequestIDHeader, incoming)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.Header.Get(RequestIDHeader), resp.Header.Get("Server-Timing"), string(body)
+	}
+
+	id, timing, seen := get("edge-42:abc")
+	if id != "edge-42:abc" || seen != id || timing != `reqid;desc="edge-42:abc"` {
+		t.Fatalf("incoming ID not kept: header=%q timing=%q handler=%q", id, timing, seen)
+	}
+
+	id, _, seen = get(`bad id"<script>`)
+	if !ValidRequestID(id) || strings.Contains(id, "script") || seen != id {
+		t.Fatalf("invalid incoming ID should be replaced, got header=%q handler=%q", id, seen)
+	}
+
+	other, _, _ := get("")
+	if other == "" || other == id {
+		t.Fatalf("expected a fresh ID per request, got %q after %q", other, id)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"":                           false,
+		"0af7651916cd43dd8448eb211c": true,
+		"req_1.2-3:4":                true,
+		"has space":                  false,
+		strings.Repeat("a", 129):     false,
+	} {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
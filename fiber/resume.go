@@ -0,0 +1,275 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// defaultResumeWindow is how long state changes stay replayable when
+// NewResumeLog is given no window.
+const defaultResumeWindow = 2 * time.Minute
+
+// ResumeLog keeps a short, per-session history of state changes in Storage so
+// a client that reconnects can catch up from the last sequence number it saw
+// instead of downloading a full snapshot.
+//
+// Each session's log is bounded by entry count and age. Appends are serialized
+// within a process; with shared Storage across processes, keep a session's
+// connections on one process (sticky sessions) so sequence numbers stay gap-free.
+type ResumeLog struct {
+	storage store.Storage
+	size    int
+	window  time.Duration
+	mu      sync.Mutex
+}
+
+// resumeBuffer is the stored form of one session's log.
+type resumeBuffer struct {
+	// Epoch identifies this log instance. A client cursor from an expired or
+	// evicted log never matches a new one, even if sequence numbers overlap.
+	Epoch   string        `json:"epoch"`
+	Seq     uint64        `json:"seq"`
+	Entries []resumeEntry `json:"entries,omitempty"`
+}
+
+type resumeEntry struct {
+	Seq     uint64      `json:"seq"`
+	At      int64       `json:"at"`
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Version uint64      `json:"version,omitempty"`
+}
+
+// resumePatch is the merged set of changes a client missed.
+type resumePatch struct {
+	seq      uint64
+	count    int
+	patch    map[string]interface{}
+	versions map[string]uint64
+}
+
+// NewResumeLog returns a log keeping up to size changes per session, each for
+// at most window (default 2m). It returns nil, which disables resume, when
+// size is not positive.
+func NewResumeLog(storage store.Storage, size int, window time.Duration) *ResumeLog {
+	if size <= 0 {
+		return nil
+	}
+	if storage == nil {
+		storage = store.NewMemoryStorage()
+	}
+	if window <= 0 {
+		window = defaultResumeWindow
+	}
+	return &ResumeLog{storage: storage, size: size, window: window}
+}
+
+func resumeKey(sessionID string) string {
+	return "gospa:resume:" + sessionID
+}
+
+// Append records a change to key for sessionID and returns its sequence
+// number, or 0 if the log is disabled or could not be written.
+func (l *ResumeLog) Append(sessionID, key string, value interface{}, version uint64) uint64 {
+	if l == nil || sessionID == "" {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	buf := l.load(sessionID)
+	if buf == nil {
+		buf = &resumeBuffer{Epoch: generateSecureToken()[:16]}
+	}
+	buf.Seq++
+	buf.Entries = append(buf.Entries, resumeEntry{
+		Seq:     buf.Seq,
+		At:      now.UnixMilli(),
+		Key:     key,
+		Value:   value,
+		Version: version,
+	})
+	l.trim(buf, now)
+	if !l.save(sessionID, buf) {
+		return 0
+	}
+	return buf.Seq
+}
+
+// Cursor returns the epoch and latest sequence number of sessionID's log,
+// starting an empty log if there is none.
+func (l *ResumeLog) Cursor(sessionID string) (epoch string, seq uint64) {
+	if l == nil || sessionID == "" {
+		return "", 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buf := l.load(sessionID)
+	if buf == nil {
+		buf = &resumeBuffer{Epoch: generateSecureToken()[:16]}
+		if !l.save(sessionID, buf) {
+			return "", 0
+		}
+	}
+	return buf.Epoch, buf.Seq
+}
+
+// Replay merges the changes made to sessionID after seq. It reports false when
+// the log can no longer cover that range (different epoch, entries expired or
+// dropped), in which case the client needs a full snapshot.
+func (l *ResumeLog) Replay(sessionID, epoch string, seq uint64) (resumePatch, bool) {
+	if l == nil || sessionID == "" || epoch == "" {
+		return resumePatch{}, false
+	}
+	l.mu.Lock()
+	buf := l.load(sessionID)
+	l.mu.Unlock()
+	if buf == nil || buf.Epoch != epoch || seq > buf.Seq {
+		return resumePatch{}, false
+	}
+	l.trim(buf, time.Now())
+
+	out := resumePatch{seq: buf.Seq}
+	if seq == buf.Seq {
+		return out, true
+	}
+	if len(buf.Entries) == 0 || buf.Entries[0].Seq > seq+1 {
+		return resumePatch{}, false
+	}
+	out.patch = make(map[string]interface{})
+	for _, e := range buf.Entries {
+		if e.Seq <= seq {
+			continue
+		}
+		out.patch[e.Key] = e.Value
+		if e.Version > 0 {
+			if out.versions == nil {
+				out.versions = make(map[string]uint64)
+			}
+			out.versions[e.Key] = e.Version
+		}
+		out.count++
+	}
+	return out, true
+}
+
+// trim drops entries older than the window and beyond the size bound.
+func (l *ResumeLog) trim(buf *resumeBuffer, now time.Time) {
+	cutoff := now.Add(-l.window).UnixMilli()
+	drop := 0
+	for drop < len(buf.Entries) && buf.Entries[drop].At < cutoff {
+		drop++
+	}
+	if n := len(buf.Entries) - drop; n > l.size {
+		drop += n - l.size
+	}
+	if drop > 0 {
+		buf.Entries = append(buf.Entries[:0], buf.Entries[drop:]...)
+	}
+}
+
+func (l *ResumeLog) load(sessionID string) *resumeBuffer {
+	data, err := l.storage.Get(context.Background(), resumeKey(sessionID))
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			slog.Default().Warn("failed to load resume log", "session_id", sessionID, "err", err)
+		}
+		return nil
+	}
+	var buf resumeBuffer
+	if err := JSONUnmarshal(data, &buf); err != nil || buf.Epoch == "" {
+		return nil
+	}
+	return &buf
+}
+
+func (l *ResumeLog) save(sessionID string, buf *resumeBuffer) bool {
+	data, err := JSONMarshal(buf)
+	if err == nil {
+		err = l.storage.Set(context.Background(), resumeKey(sessionID), data, l.window)
+	}
+	if err != nil {
+		slog.Default().Warn("failed to save resume log", "session_id", sessionID, "err", err)
+		return false
+	}
+	return true
+}
+
+// resumeCursor reads the {"epoch","seq"} cursor a reconnecting client sends
+// under "resume" in its init message data.
+func resumeCursor(data map[string]interface{}) (epoch string, seq uint64, ok bool) {
+	cursor, _ := data["resume"].(map[string]interface{})
+	if cursor == nil {
+		return "", 0, false
+	}
+	epoch, _ = cursor["epoch"].(string)
+	var signed int64
+	switch n := cursor["seq"].(type) {
+	case float64:
+		signed = int64(n)
+	case int64:
+		signed = n
+	case int32:
+		signed = int64(n)
+	case int16:
+		signed = int64(n)
+	case int8:
+		signed = int64(n)
+	case int:
+		signed = int64(n)
+	case uint64:
+		seq = n
+	case uint32:
+		seq = uint64(n)
+	case uint16:
+		seq = uint64(n)
+	case uint8:
+		seq = uint64(n)
+	default:
+		return "", 0, false
+	}
+	if signed < 0 {
+		return "", 0, false
+	}
+	if signed > 0 {
+		seq = uint64(signed)
+	}
+	return epoch, seq, epoch != ""
+}
+
+// sendResume replays the changes the client missed since its cursor as one
+// "resume" frame. It reports false, and sends nothing, when the log cannot
+// cover the gap.
+func (c *WSClient) sendResume(epoch string, seq uint64) bool {
+	missed, ok := c.resume.Replay(c.SessionID, epoch, seq)
+	if !ok {
+		return false
+	}
+	if c.stateDiffing {
+		c.lastSentStateMu.Lock()
+		c.lastSentState = c.State.ToMap()
+		c.lastSentStateMu.Unlock()
+	}
+	frame := map[string]interface{}{
+		"type":     "resume",
+		"clientId": c.SessionID,
+		"epoch":    epoch,
+		"seq":      missed.seq,
+		"replayed": missed.count,
+	}
+	if len(missed.patch) > 0 {
+		frame["patch"] = missed.patch
+	}
+	if len(missed.versions) > 0 {
+		frame["versions"] = missed.versions
+	}
+	c.sendEncodedPayload(frame)
+	return true
+}
@@ -0,0 +1,130 @@
+package fiber
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+func TestResumeLogReplay(t *testing.T) {
+	rlog := NewResumeLog(store.NewMemoryStorage(), 10, time.Minute)
+	epoch, seq := rlog.Cursor("s1")
+	if epoch == "" || seq != 0 {
+		t.Fatalf("new cursor = %q/%d", epoch, seq)
+	}
+	rlog.Append("s1", "count", 1, 0)
+	rlog.Append("s1", "todo.title", "a", 3)
+	if got := rlog.Append("s1", "count", 2, 0); got != 3 {
+		t.Fatalf("third append got seq %d, want 3", got)
+	}
+
+	missed, ok := rlog.Replay("s1", epoch, 1)
+	if !ok || missed.seq != 3 || missed.count != 2 {
+		t.Fatalf("replay from 1 = %+v, %v", missed, ok)
+	}
+	if missed.patch["count"] != float64(2) || missed.patch["todo.title"] != "a" || missed.versions["todo.title"] != 3 {
+		t.Fatalf("unexpected patch %v / %v", missed.patch, missed.versions)
+	}
+	if missed, ok := rlog.Replay("s1", epoch, 3); !ok || missed.count != 0 {
+		t.Fatalf("up-to-date replay = %+v, %v", missed, ok)
+	}
+	if _, ok := rlog.Replay("s1", "other", 1); ok {
+		t.Fatal("replay accepted a cursor from another epoch")
+	}
+	if _, ok := rlog.Replay("s1", epoch, 4); ok {
+		t.Fatal("replay accepted a cursor ahead of the rlog")
+	}
+}
+
+func TestResumeLogBounds(t *testing.T) {
+	rlog := NewResumeLog(store.NewMemoryStorage(), 2, time.Minute)
+	epoch, _ := rlog.Cursor("s1")
+	for i := 1; i <= 4; i++ {
+		rlog.Append("s1", "n", i, 0)
+	}
+	if _, ok := rlog.Replay("s1", epoch, 1); ok {
+		t.Fatal("replay succeeded although change 2 was dropped")
+	}
+	if missed, ok := rlog.Replay("s1", epoch, 2); !ok || missed.patch["n"] != float64(4) {
+		t.Fatalf("replay from 2 = %+v, %v", missed, ok)
+	}
+
+	buf := rlog.load("s1")
+	rlog.trim(buf, time.Now().Add(2*time.Minute))
+	if len(buf.Entries) != 0 {
+		t.Fatalf("expected expired entries to be trimmed, got %d", len(buf.Entries))
+	}
+}
+
+func TestResumeLogDisabled(t *testing.T) {
+	rlog := NewResumeLog(nil, 0, 0)
+	if rlog != nil {
+		t.Fatal("expected a zero size to disable the rlog")
+	}
+	if seq := rlog.Append("s1", "k", 1, 0); seq != 0 {
+		t.Fatalf("disabled rlog assigned seq %d", seq)
+	}
+	if epoch, _ := rlog.Cursor("s1"); epoch != "" {
+		t.Fatal("disabled rlog returned a cursor")
+	}
+}
+
+func TestResumeCursor(t *testing.T) {
+	epoch, seq, ok := resumeCursor(map[string]interface{}{
+		"resume": map[string]interface{}{"epoch": "e1", "seq": float64(7)},
+	})
+	if !ok || epoch != "e1" || seq != 7 {
+		t.Fatalf("cursor = %q/%d/%v", epoch, seq, ok)
+	}
+	for _, data := range []map[string]interface{}{
+		nil,
+		{"resume": map[string]interface{}{"epoch": "e1", "seq": float64(-1)}},
+		{"resume": map[string]interface{}{"seq": float64(1)}},
+		{"resume": map[string]interface{}{"epoch": "e1", "seq": "1"}},
+	} {
+		if _, _, ok := resumeCursor(data); ok {
+			t.Fatalf("accepted invalid cursor %v", data)
+		}
+	}
+}
+
+func TestSendResume(t *testing.T) {
+	rlog := NewResumeLog(store.NewMemoryStorage(), 10, time.Minute)
+	c := NewWSClient("c1", nil, WebSocketConfig{Resume: rlog})
+	c.SessionID = "s1"
+	epoch, _ := rlog.Cursor("s1")
+	rlog.Append("s1", "count", 5, 2)
+
+	if !c.sendResume(epoch, 0) {
+		t.Fatal("expected resume to cover the gap")
+	}
+	frame := readFrame(t, c)
+	if frame["type"] != "resume" || frame["seq"] != float64(1) || frame["epoch"] != epoch {
+		t.Fatalf("unexpected resume frame %v", frame)
+	}
+	if patch := frame["patch"].(map[string]interface{}); patch["count"] != float64(5) {
+		t.Fatalf("unexpected patch %v", patch)
+	}
+
+	if c.sendResume("stale", 0) {
+		t.Fatal("resume succeeded for an unknown epoch")
+	}
+	select {
+	case msg := <-c.Send:
+		t.Fatalf("failed resume sent a frame: %s", msg)
+	default:
+	}
+}
+
+func TestCoalescedPatchCarriesSeq(t *testing.T) {
+	c := newCoalescingTestClient(10 * time.Millisecond)
+	for seq := 1; seq <= 3; seq++ {
+		msg, _ := json.Marshal(map[string]interface{}{"type": "sync", "key": "n", "value": seq, "seq": seq})
+		c.deliver(msg, nil)
+	}
+	if frame := readFrame(t, c); frame["seq"] != float64(3) {
+		t.Fatalf("patch seq = %v, want 3", frame["seq"])
+	}
+}
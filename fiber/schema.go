@@ -0,0 +1,191 @@
+package fiber
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// MessageSchema constrains the payload of inbound WebSocket messages of one
+// type or action. ReadPump checks it before OnMessage or any action handler
+// runs, and replies with an ErrorCodeInvalidPayload error on mismatch.
+type MessageSchema struct {
+	// MaxSize bounds the JSON-encoded payload in bytes (0 = only
+	// WSMaxMessageSize applies).
+	MaxSize int
+	// MaxDepth bounds payload nesting (0 = the message limit of 64).
+	MaxDepth int
+	// Target, if set, is a value of the struct type the payload must decode
+	// into, e.g. MoveInput{}. Unknown fields and mismatched types are
+	// rejected, and handlers receive the decoded *MoveInput as the payload.
+	Target interface{}
+	// Validate runs last, on the decoded Target or else the raw payload. A
+	// non-nil error rejects the message and is sent to the client.
+	Validate func(payload interface{}) error
+}
+
+// SchemaRejectStats counts the payloads one schema rejected, by reason.
+type SchemaRejectStats struct {
+	// Oversized payloads exceeded MaxSize.
+	Oversized uint64 `json:"oversized"`
+	// TooDeep payloads exceeded MaxDepth.
+	TooDeep uint64 `json:"tooDeep"`
+	// Malformed payloads did not decode into Target.
+	Malformed uint64 `json:"malformed"`
+	// Invalid payloads failed Validate.
+	Invalid uint64 `json:"invalid"`
+}
+
+// registeredSchema is a MessageSchema with its reject counters.
+type registeredSchema struct {
+	MessageSchema
+	target    reflect.Type
+	oversized atomic.Uint64
+	tooDeep   atomic.Uint64
+	malformed atomic.Uint64
+	invalid   atomic.Uint64
+}
+
+var (
+	messageSchemas = make(map[string]*registeredSchema)
+	schemaMu       sync.RWMutex
+)
+
+// actionSchemaKey is the registry key of an action schema.
+func actionSchemaKey(action string) string {
+	return "action:" + action
+}
+
+// RegisterMessageSchema constrains payloads of messages with WSMessage.Type
+// msgType, such as "update" or a custom type handled in OnMessage. For
+// "action" it applies to actions without their own RegisterActionSchema.
+func RegisterMessageSchema(msgType string, schema MessageSchema) {
+	registerSchema(msgType, schema)
+}
+
+// RegisterActionSchema constrains payloads of "action" messages for action.
+func RegisterActionSchema(action string, schema MessageSchema) {
+	registerSchema(actionSchemaKey(action), schema)
+}
+
+func registerSchema(key string, schema MessageSchema) {
+	s := &registeredSchema{MessageSchema: schema}
+	if schema.Target != nil {
+		s.target = reflect.TypeOf(schema.Target)
+		if s.target.Kind() == reflect.Ptr {
+			s.target = s.target.Elem()
+		}
+	}
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	messageSchemas[key] = s
+}
+
+// MessageSchemaRejects returns reject counters for every registered schema,
+// keyed by message type or "action:<name>".
+func MessageSchemaRejects() map[string]SchemaRejectStats {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	stats := make(map[string]SchemaRejectStats, len(messageSchemas))
+	for key, s := range messageSchemas {
+		stats[key] = SchemaRejectStats{
+			Oversized: s.oversized.Load(),
+			TooDeep:   s.tooDeep.Load(),
+			Malformed: s.malformed.Load(),
+			Invalid:   s.invalid.Load(),
+		}
+	}
+	return stats
+}
+
+// lookupSchema returns the schema for msg, preferring an action schema.
+func lookupSchema(msg *WSMessage) *registeredSchema {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	if len(messageSchemas) == 0 {
+		return nil
+	}
+	if msg.Type == "action" && msg.Action != "" {
+		if s, ok := messageSchemas[actionSchemaKey(msg.Action)]; ok {
+			return s
+		}
+	}
+	return messageSchemas[msg.Type]
+}
+
+// checkSchema applies the registered schema, if any, to msg and replaces its
+// payload with the decoded Target. It reports false after replying to a
+// rejected message.
+func (c *WSClient) checkSchema(msg *WSMessage) bool {
+	s := lookupSchema(msg)
+	if s == nil {
+		return true
+	}
+	start := time.Now()
+	reject := func(counter *atomic.Uint64, message string) bool {
+		counter.Add(1)
+		if msg.Type == "action" {
+			c.notifyAction(msg.Action, msg.Payload, ErrorCodeInvalidPayload, start)
+		}
+		reply := wsError(ErrorCodeInvalidPayload, message)
+		if reqID := msg.Data["_requestId"]; reqID != nil {
+			reply["data"] = map[string]interface{}{"_responseId": reqID}
+		}
+		_ = c.SendJSON(reply)
+		return false
+	}
+
+	raw, ok := msg.Payload.([]byte)
+	if !ok {
+		var err error
+		if raw, err = JSONMarshal(msg.Payload); err != nil {
+			return reject(&s.malformed, "Invalid payload")
+		}
+	}
+	if s.MaxSize > 0 && len(raw) > s.MaxSize {
+		return reject(&s.oversized, "Payload too large")
+	}
+	if s.MaxDepth > 0 {
+		if err := validateJSONDepth(raw, s.MaxDepth); err != nil {
+			return reject(&s.tooDeep, "Payload nesting too deep")
+		}
+	}
+
+	payload := msg.Payload
+	if s.target != nil {
+		if msg.Payload == nil {
+			return reject(&s.malformed, "Payload required")
+		}
+		v := reflect.New(s.target)
+		if err := decodeStrict(raw, v.Interface()); err != nil {
+			return reject(&s.malformed, "Payload does not match the expected shape")
+		}
+		payload = v.Interface()
+	}
+	if s.Validate != nil {
+		if err := s.Validate(payload); err != nil {
+			return reject(&s.invalid, err.Error())
+		}
+	}
+	msg.Payload = payload
+	return true
+}
+
+// decodeStrict decodes raw into v, rejecting unknown fields. With a codec
+// set with SetJSONCodec, the strict decoder only checks the payload and the
+// codec decodes it.
+func decodeStrict(raw []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if !HasCustomJSONCodec() {
+		return dec.Decode(v)
+	}
+	if err := dec.Decode(reflect.New(reflect.TypeOf(v).Elem()).Interface()); err != nil {
+		return err
+	}
+	return JSONUnmarshal(raw, v)
+}
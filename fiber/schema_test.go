@@ -0,0 +1,138 @@
+package fiber
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+type moveInput struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// schemaReply returns the frame checkSchema queued for client, if any.
+func schemaReply(t *testing.T, client *WSClient) map[string]interface{} {
+	t.Helper()
+	select {
+	case raw := <-client.Send:
+		var reply map[string]interface{}
+		if err := json.Unmarshal(raw, &reply); err != nil {
+			t.Fatal(err)
+		}
+		return reply
+	default:
+		return nil
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	RegisterActionSchema("test.move", MessageSchema{
+		MaxSize: 64,
+		Target:  moveInput{},
+		Validate: func(payload interface{}) error {
+			if payload.(*moveInput).X < 0 {
+				return errors.New("x must not be negative")
+			}
+			return nil
+		},
+	})
+	RegisterMessageSchema("test.note", MessageSchema{MaxDepth: 2})
+	defer func() {
+		schemaMu.Lock()
+		delete(messageSchemas, "action:test.move")
+		delete(messageSchemas, "test.note")
+		schemaMu.Unlock()
+	}()
+
+	var events []ActionEvent
+	client := NewWSClient("c1", nil, WebSocketConfig{
+		OnAction: func(_ *WSClient, ev ActionEvent) { events = append(events, ev) },
+	})
+
+	msg := WSMessage{Type: "action", Action: "test.move", Payload: map[string]interface{}{"x": 1, "y": 2}}
+	if !client.checkSchema(&msg) {
+		t.Fatalf("valid payload rejected: %v", schemaReply(t, client))
+	}
+	if in, ok := msg.Payload.(*moveInput); !ok || in.X != 1 || in.Y != 2 {
+		t.Fatalf("payload = %#v, want *moveInput{1, 2}", msg.Payload)
+	}
+
+	rejected := []struct {
+		name string
+		msg  WSMessage
+		want string
+	}{
+		{"unknown field", WSMessage{Type: "action", Action: "test.move", Payload: map[string]interface{}{"x": 1, "z": 3}}, "expected shape"},
+		{"wrong type", WSMessage{Type: "action", Action: "test.move", Payload: map[string]interface{}{"x": "1"}}, "expected shape"},
+		{"missing", WSMessage{Type: "action", Action: "test.move"}, "required"},
+		{"oversized", WSMessage{Type: "action", Action: "test.move", Payload: map[string]interface{}{"x": strings.Repeat("a", 64)}}, "too large"},
+		{"invalid", WSMessage{Type: "action", Action: "test.move", Payload: []byte(`{"x":-1}`), Data: map[string]interface{}{"_requestId": "r1"}}, "negative"},
+		{"too deep", WSMessage{Type: "test.note", Payload: map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1}}}}, "too deep"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if client.checkSchema(&tt.msg) {
+				t.Fatal("payload accepted")
+			}
+			reply := schemaReply(t, client)
+			if reply["code"] != string(ErrorCodeInvalidPayload) || !strings.Contains(reply["error"].(string), tt.want) {
+				t.Fatalf("reply = %v, want %q", reply, tt.want)
+			}
+			if id, ok := tt.msg.Data["_requestId"]; ok {
+				if data, _ := reply["data"].(map[string]interface{}); data["_responseId"] != id {
+					t.Fatalf("reply data = %v, want _responseId %v", reply["data"], id)
+				}
+			}
+		})
+	}
+
+	other := WSMessage{Type: "action", Action: "test.other", Payload: map[string]interface{}{"any": true}}
+	if !client.checkSchema(&other) {
+		t.Fatal("action without a schema rejected")
+	}
+
+	stats := MessageSchemaRejects()
+	if got := stats["action:test.move"]; got != (SchemaRejectStats{Oversized: 1, Malformed: 3, Invalid: 1}) {
+		t.Fatalf("action stats = %+v", got)
+	}
+	if got := stats["test.note"]; got != (SchemaRejectStats{TooDeep: 1}) {
+		t.Fatalf("note stats = %+v", got)
+	}
+	if len(events) != 5 || events[0].Code != ErrorCodeInvalidPayload {
+		t.Fatalf("action events = %+v", events)
+	}
+}
+
+func TestCheckSchemaUsesJSONCodec(t *testing.T) {
+	RegisterActionSchema("test.codec", MessageSchema{Target: moveInput{}})
+	defer func() {
+		schemaMu.Lock()
+		delete(messageSchemas, "action:test.codec")
+		schemaMu.Unlock()
+	}()
+	t.Cleanup(func() { SetJSONCodec(nil, nil) })
+	var marshals, unmarshals int
+	SetJSONCodec(func(v interface{}) ([]byte, error) {
+		marshals++
+		return json.Marshal(v)
+	}, func(data []byte, v interface{}) error {
+		unmarshals++
+		return json.Unmarshal(data, v)
+	})
+
+	client := NewWSClient("c1", nil, WebSocketConfig{})
+	msg := WSMessage{Type: "action", Action: "test.codec", Payload: map[string]interface{}{"x": 3}}
+	if !client.checkSchema(&msg) {
+		t.Fatalf("valid payload rejected: %v", schemaReply(t, client))
+	}
+	if marshals == 0 || unmarshals == 0 {
+		t.Fatalf("codec used for %d marshals and %d unmarshals", marshals, unmarshals)
+	}
+	msg = WSMessage{Type: "action", Action: "test.codec", Payload: map[string]interface{}{"x": 3, "extra": true}}
+	if client.checkSchema(&msg) {
+		t.Fatal("unknown field accepted with a custom codec")
+	}
+}
@@ -0,0 +1,54 @@
+package fiber
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// affinitySeparator separates the process ID from the random part of a
+// session token issued under session affinity.
+const affinitySeparator = "."
+
+// NewProcessID returns a random identifier for this process, for
+// SetSessionAffinity.
+func NewProcessID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// SetAffinity embeds processID in every session token the store issues, so a
+// token presented to another process, whose in-memory store cannot know it,
+// is recognized as Foreign. An empty processID turns affinity off.
+func (s *SessionStore) SetAffinity(processID string) {
+	s.affinity = processID
+}
+
+// Foreign reports whether token was issued by another process. It is always
+// false without affinity.
+func (s *SessionStore) Foreign(token string) bool {
+	if s.affinity == "" || token == "" {
+		return false
+	}
+	return !strings.HasPrefix(token, s.affinity+affinitySeparator)
+}
+
+// tokenFor prefixes a freshly generated token with the process ID.
+func (s *SessionStore) tokenFor(random string) string {
+	if s.affinity == "" || random == "" {
+		return random
+	}
+	return s.affinity + affinitySeparator + random
+}
+
+// SetSessionAffinity sets the process ID embedded in session tokens of the
+// global session store. Use it when processes keep sessions in memory, as
+// with Prefork and no shared Storage: a connection that lands on a process
+// that did not issue its token gets a new session and a full state resync
+// instead of a session ID another process may reuse.
+func SetSessionAffinity(processID string) {
+	globalSessionStore.SetAffinity(processID)
+}
@@ -0,0 +1,62 @@
+package fiber
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestSessionStoreAffinity(t *testing.T) {
+	storage := store.NewMemoryStorage()
+	defer func() { _ = storage.Close() }()
+	s := NewSessionStore(storage)
+	s.SetAffinity("p1")
+
+	token, err := s.CreateSession("alice")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if !strings.HasPrefix(token, "p1.") {
+		t.Fatalf("token %q does not carry the process ID", token)
+	}
+	if s.Foreign(token) {
+		t.Fatal("own token reported as foreign")
+	}
+	if id, ok := s.ValidateSession(token); !ok || id != "alice" {
+		t.Fatalf("ValidateSession = %q, %v", id, ok)
+	}
+
+	other := NewSessionStore(storage)
+	other.SetAffinity("p2")
+	if !other.Foreign(token) || !other.Foreign("no-prefix") {
+		t.Fatal("tokens of another process not reported as foreign")
+	}
+	other.SetAffinity("")
+	if other.Foreign(token) {
+		t.Fatal("Foreign without affinity")
+	}
+}
+
+func TestStartSessionResyncsForeignToken(t *testing.T) {
+	prev := globalSessionStore
+	defer func() { globalSessionStore = prev }()
+	globalSessionStore = NewSessionStore(store.NewMemoryStorage())
+	globalSessionStore.SetAffinity("p2")
+
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c1", nil)
+	release, err := startSession(WebSocketConfig{Hub: hub, GenerateID: generateComponentID}, client, WSMessage{Type: "init"}, "p1.deadbeef", "")
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+	defer release()
+
+	if s := nextFrame(t, client); !strings.Contains(s, `"type":"init"`) || !strings.Contains(s, `"resync":true`) {
+		t.Fatalf("init for a foreign token = %s", s)
+	}
+	if n := hub.Stats().SessionResyncs; n != 1 {
+		t.Fatalf("SessionResyncs = %d, want 1", n)
+	}
+}
@@ -373,14 +373,12 @@ func (b *SSEBroker) SSESubscribeHandler() fiberpkg.Handler {
 		}
 
 		if err := json.Unmarshal(c.Body(), &req); err != nil {
-			return c.Status(400).JSON(fiberpkg.Map{"error": "invalid request"})
+			return c.Status(400).JSON(ErrorBody(ErrorCodeInvalidPayload, "invalid request"))
 		}
 
 		// Validate that the client exists and is currently connected.
 		if !b.clientExists(req.ClientID) {
-			return c.Status(404).JSON(fiberpkg.Map{
-				"error": "client not found or not connected",
-			})
+			return c.Status(404).JSON(ErrorBody(ErrorCodeNotFound, "client not found or not connected"))
 		}
 
 		// SECURITY FIX: Verify that the requester is authorized to subscribe this client.
@@ -393,22 +391,18 @@ func (b *SSEBroker) SSESubscribeHandler() fiberpkg.Handler {
 		}
 
 		if sessionToken == "" {
-			return c.Status(401).JSON(fiberpkg.Map{"error": "authentication required"})
+			return c.Status(401).JSON(ErrorBody(ErrorCodeSessionRequired, "authentication required"))
 		}
 
-		requesterID, ok := globalSessionStore.ValidateSession(sessionToken)
+		requesterID, ok := requestStateStores(c).Sessions.ValidateSession(sessionToken)
 		if !ok || requesterID != req.ClientID {
-			return c.Status(403).JSON(fiberpkg.Map{
-				"error": "unauthorized subscription request",
-			})
+			return c.Status(403).JSON(ErrorBody(ErrorCodeForbidden, "unauthorized subscription request"))
 		}
 
 		// Additional custom authorization if configured
 		if b.authorizeSubscribe != nil {
 			if !b.authorizeSubscribe(c, req.ClientID) {
-				return c.Status(403).JSON(fiberpkg.Map{
-					"error": "custom authorization failed",
-				})
+				return c.Status(403).JSON(ErrorBody(ErrorCodeForbidden, "custom authorization failed"))
 			}
 		}
 
@@ -439,14 +433,12 @@ func (b *SSEBroker) SSEUnsubscribeHandler() fiberpkg.Handler {
 		}
 
 		if err := json.Unmarshal(c.Body(), &req); err != nil {
-			return c.Status(400).JSON(fiberpkg.Map{"error": "invalid request"})
+			return c.Status(400).JSON(ErrorBody(ErrorCodeInvalidPayload, "invalid request"))
 		}
 
 		// Validate that the client exists and is currently connected
 		if !b.clientExists(req.ClientID) {
-			return c.Status(404).JSON(fiberpkg.Map{
-				"error": "client not found or not connected",
-			})
+			return c.Status(404).JSON(ErrorBody(ErrorCodeNotFound, "client not found or not connected"))
 		}
 
 		// SECURITY FIX: Require authentication for unsubscribe operations.
@@ -459,25 +451,21 @@ func (b *SSEBroker) SSEUnsubscribeHandler() fiberpkg.Handler {
 		}
 
 		if sessionToken == "" {
-			return c.Status(401).JSON(fiberpkg.Map{"error": "authentication required"})
+			return c.Status(401).JSON(ErrorBody(ErrorCodeSessionRequired, "authentication required"))
 		}
 
-		requesterID, ok := globalSessionStore.ValidateSession(sessionToken)
+		requesterID, ok := requestStateStores(c).Sessions.ValidateSession(sessionToken)
 		if !ok {
-			return c.Status(401).JSON(fiberpkg.Map{"error": "invalid session"})
+			return c.Status(401).JSON(ErrorBody(ErrorCodeInvalidSession, "invalid session"))
 		}
 		if requesterID != req.ClientID {
-			return c.Status(403).JSON(fiberpkg.Map{
-				"error": "unauthorized unsubscription request",
-			})
+			return c.Status(403).JSON(ErrorBody(ErrorCodeForbidden, "unauthorized unsubscription request"))
 		}
 
 		// Additional custom authorization if configured
 		if b.authorizeSubscribe != nil {
 			if !b.authorizeSubscribe(c, req.ClientID) {
-				return c.Status(403).JSON(fiberpkg.Map{
-					"error": "custom authorization failed",
-				})
+				return c.Status(403).JSON(ErrorBody(ErrorCodeForbidden, "custom authorization failed"))
 			}
 		}
 
@@ -0,0 +1,62 @@
+package fiber
+
+import (
+	"fmt"
+	"path"
+)
+
+// StateKeyFilter decides which state keys are written to the ClientStateStore.
+// Patterns use path.Match glob syntax (e.g. "ui.*", "*.cursor").
+type StateKeyFilter struct {
+	include []string
+	exclude []string
+}
+
+// NewStateKeyFilter validates the include/exclude patterns. An empty include
+// list persists every key that is not excluded; exclusions always win.
+func NewStateKeyFilter(include, exclude []string) (*StateKeyFilter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid state key pattern %q: %w", pattern, err)
+		}
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	return &StateKeyFilter{include: include, exclude: exclude}, nil
+}
+
+// Allows reports whether key should be persisted. A nil filter allows every key.
+func (f *StateKeyFilter) Allows(key string) bool {
+	if f == nil {
+		return true
+	}
+	for _, pattern := range f.exclude {
+		if ok, _ := path.Match(pattern, key); ok {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the subset of values allowed by the filter.
+func (f *StateKeyFilter) Apply(values map[string]interface{}) map[string]interface{} {
+	if f == nil {
+		return values
+	}
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if f.Allows(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package fiber
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/state"
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+func TestStateKeyFilterAllows(t *testing.T) {
+	filter, err := NewStateKeyFilter([]string{"cart.*", "theme"}, []string{"cart.draft"})
+	if err != nil {
+		t.Fatalf("NewStateKeyFilter: %v", err)
+	}
+	cases := map[string]bool{
+		"cart.items": true,
+		"cart.draft": false,
+		"theme":      true,
+		"ui.menu":    false,
+	}
+	for key, want := range cases {
+		if got := filter.Allows(key); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestStateKeyFilterExcludeOnly(t *testing.T) {
+	filter, err := NewStateKeyFilter(nil, []string{"*.cursor", "menuOpen"})
+	if err != nil {
+		t.Fatalf("NewStateKeyFilter: %v", err)
+	}
+	if filter.Allows("editor.cursor") || filter.Allows("menuOpen") {
+		t.Fatal("excluded keys must not be persisted")
+	}
+	if !filter.Allows("editor.text") {
+		t.Fatal("keys not excluded should be persisted when no include list is set")
+	}
+}
+
+func TestStateKeyFilterRejectsBadPattern(t *testing.T) {
+	if _, err := NewStateKeyFilter([]string{"["}, nil); err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+	if f, err := NewStateKeyFilter(nil, nil); err != nil || f != nil {
+		t.Fatalf("expected nil filter for empty patterns, got %v, %v", f, err)
+	}
+}
+
+func TestClientStateStoreSaveAppliesFilter(t *testing.T) {
+	storage := store.NewMemoryStorage()
+	defer func() { _ = storage.Close() }()
+	cs := NewClientStateStore(storage)
+	filter, _ := NewStateKeyFilter(nil, []string{"ui.*"})
+	cs.SetKeyFilter(filter)
+
+	sm := state.NewStateMap()
+	sm.Add("count", state.NewRune(3))
+	sm.Add("ui.menuOpen", state.NewRune(true))
+	cs.Save("client-1", sm)
+
+	raw, err := storage.Get(context.Background(), "state:client-1")
+	if err != nil {
+		t.Fatalf("expected saved state: %v", err)
+	}
+	var saved map[string]interface{}
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := saved["ui.menuOpen"]; ok {
+		t.Fatal("excluded key was persisted")
+	}
+	if _, ok := saved["count"]; !ok {
+		t.Fatal("allowed key was not persisted")
+	}
+}
@@ -0,0 +1,139 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// clientIndexKey is the set of client IDs whose last-seen time is tracked.
+const clientIndexKey = "gospa:clients"
+
+// ErrRetentionUnsupported is returned when state retention is enabled on a
+// Storage that does not implement store.SetStorage.
+var ErrRetentionUnsupported = errors.New("gospa: state retention needs a Storage implementing store.SetStorage")
+
+func lastSeenKey(clientID string) string {
+	return "client:" + clientID + ":seen"
+}
+
+// PurgeResult reports the outcome of purging inactive clients.
+type PurgeResult struct {
+	// Scanned is the number of tracked clients examined.
+	Scanned int `json:"scanned"`
+	// Purged is the number of clients whose state was removed.
+	Purged int `json:"purged"`
+	// Sessions is the number of session tokens revoked along the way.
+	Sessions int `json:"sessions"`
+}
+
+// SetRetention enables last-seen tracking for clients, kept for d after
+// their last activity. Zero disables tracking.
+func (s *ClientStateStore) SetRetention(d time.Duration) error {
+	if d > 0 {
+		if _, ok := s.storage.(store.SetStorage); !ok {
+			return ErrRetentionUnsupported
+		}
+	}
+	s.retention = d
+	return nil
+}
+
+// Touch records that clientID was active now. It is a no-op unless
+// retention is enabled.
+func (s *ClientStateStore) Touch(clientID string) {
+	sets, ok := s.storage.(store.SetStorage)
+	if s.retention <= 0 || !ok || clientID == "" {
+		return
+	}
+	ctx := context.Background()
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	_ = s.storage.Set(ctx, lastSeenKey(clientID), []byte(now), s.retention)
+	_ = sets.SAdd(ctx, clientIndexKey, clientID, s.retention)
+}
+
+// Purge removes the state, session tokens and last-seen record of every
+// tracked client not seen within olderThan. Clients whose last-seen record
+// already expired are purged as well.
+func (s *ClientStateStore) Purge(ctx context.Context, sessions *SessionStore, olderThan time.Duration) (PurgeResult, error) {
+	var res PurgeResult
+	sets, ok := s.storage.(store.SetStorage)
+	if !ok {
+		return res, ErrRetentionUnsupported
+	}
+	if olderThan <= 0 {
+		return res, errors.New("gospa: purge age must be positive")
+	}
+	ids, err := sets.SMembers(ctx, clientIndexKey)
+	if err != nil {
+		return res, err
+	}
+	cutoff := time.Now().Add(-olderThan).UnixNano()
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		res.Scanned++
+		seen, err := s.storage.Get(ctx, lastSeenKey(id))
+		if err == nil {
+			if t, perr := strconv.ParseInt(string(seen), 10, 64); perr == nil && t >= cutoff {
+				continue
+			}
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return res, err
+		}
+		if err := s.storage.Delete(ctx, "state:"+id); err != nil {
+			return res, err
+		}
+		if sessions != nil {
+			n, err := sessions.RemoveClientSessions(id)
+			if err != nil {
+				return res, err
+			}
+			res.Sessions += n
+		}
+		_ = s.storage.Delete(ctx, lastSeenKey(id))
+		_ = sets.SRem(ctx, clientIndexKey, id)
+		res.Purged++
+	}
+	return res, nil
+}
+
+// SetRetention enables last-seen tracking on the stores' client state, so
+// PurgeInactive can remove clients that never return.
+func (s *StateStores) SetRetention(d time.Duration) error {
+	return s.ClientState.SetRetention(d)
+}
+
+// Touch records activity for clientID in the stores' client state.
+func (s *StateStores) Touch(clientID string) {
+	s.ClientState.Touch(clientID)
+}
+
+// PurgeInactive removes the persisted state and sessions of clients not seen
+// within olderThan from the stores.
+func (s *StateStores) PurgeInactive(ctx context.Context, olderThan time.Duration) (PurgeResult, error) {
+	return s.ClientState.Purge(ctx, s.Sessions, olderThan)
+}
+
+// SetStateRetention enables last-seen tracking on the global client state
+// store, so PurgeInactiveClients can remove clients that never return. An
+// app with stores of its own tracks its clients through them, see
+// StateStores.SetRetention.
+func SetStateRetention(d time.Duration) error {
+	return GlobalStateStores().SetRetention(d)
+}
+
+// TouchClient records activity for clientID in the global client state store.
+func TouchClient(clientID string) {
+	GlobalStateStores().Touch(clientID)
+}
+
+// PurgeInactiveClients removes the persisted state and sessions of clients
+// not seen within olderThan from the global stores.
+func PurgeInactiveClients(ctx context.Context, olderThan time.Duration) (PurgeResult, error) {
+	return GlobalStateStores().PurgeInactive(ctx, olderThan)
+}
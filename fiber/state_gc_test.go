@@ -0,0 +1,69 @@
+package fiber
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/state"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestClientStateStorePurge(t *testing.T) {
+	ctx := context.Background()
+	storage := store.NewMemoryStorage()
+	defer func() { _ = storage.Close() }()
+	states := NewClientStateStore(storage)
+	sessions := NewSessionStore(storage)
+	if err := states.SetRetention(time.Hour); err != nil {
+		t.Fatalf("SetRetention: %v", err)
+	}
+
+	for _, id := range []string{"gone", "active"} {
+		sm := state.NewStateMap()
+		sm.Add("count", state.NewRune(1))
+		states.Save(id, sm)
+		if _, err := sessions.CreateSession(id); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		states.Touch(id)
+	}
+	old := strconv.FormatInt(time.Now().Add(-2*time.Hour).UnixNano(), 10)
+	_ = storage.Set(ctx, lastSeenKey("gone"), []byte(old), time.Hour)
+
+	res, err := states.Purge(ctx, sessions, time.Hour)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if res != (PurgeResult{Scanned: 2, Purged: 1, Sessions: 1}) {
+		t.Fatalf("Purge = %+v", res)
+	}
+	if _, ok := states.Get("gone"); ok {
+		t.Fatal("state of the inactive client survived the purge")
+	}
+	if _, ok := states.Get("active"); !ok {
+		t.Fatal("state of the active client was purged")
+	}
+	if res, _ := states.Purge(ctx, sessions, time.Hour); res.Scanned != 1 {
+		t.Fatalf("purged client still tracked: %+v", res)
+	}
+}
+
+func TestClientStateStoreTouchWithoutRetention(t *testing.T) {
+	storage := store.NewMemoryStorage()
+	defer func() { _ = storage.Close() }()
+	states := NewClientStateStore(storage)
+	states.Touch("alice")
+	if _, err := storage.Get(context.Background(), lastSeenKey("alice")); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("Touch wrote a last-seen record with retention disabled: %v", err)
+	}
+}
+
+func TestSetRetentionNeedsSetStorage(t *testing.T) {
+	states := NewClientStateStore(kvOnlyStorage{store.NewMemoryStorage()})
+	if err := states.SetRetention(time.Hour); !errors.Is(err, ErrRetentionUnsupported) {
+		t.Fatalf("SetRetention = %v, want ErrRetentionUnsupported", err)
+	}
+}
@@ -0,0 +1,278 @@
+package fiber
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/state"
+	json "github.com/goccy/go-json"
+)
+
+// ClientPersistCookieName is the cookie used when persisted state is stored in a cookie.
+const ClientPersistCookieName = "gospa_pstate"
+
+// Client-side persistence storage modes.
+const (
+	ClientPersistLocalStorage = "localStorage"
+	ClientPersistCookie       = "cookie"
+)
+
+// minClientPersistSecretLen is the minimum accepted secret length in bytes.
+const minClientPersistSecretLen = 32
+
+// maxClientPersistBlobLen bounds the size of an inbound sealed blob (cookies cap at ~4KB).
+const maxClientPersistBlobLen = 8 * 1024
+
+var (
+	// ErrClientStateInvalid is returned when a persisted blob fails decoding or verification.
+	ErrClientStateInvalid = errors.New("invalid persisted client state")
+	// ErrClientStateExpired is returned when a persisted blob is older than MaxAge.
+	ErrClientStateExpired = errors.New("persisted client state expired")
+)
+
+// ClientPersistConfig configures encrypted, signed persistence of selected
+// state keys on the client (cookie or localStorage).
+type ClientPersistConfig struct {
+	// Keys lists the state keys that are persisted client-side.
+	Keys []string
+	// Secrets are the key material used to encrypt and sign blobs. The first
+	// secret seals new blobs; the remaining ones are only accepted when opening,
+	// which allows rotating keys without invalidating existing clients.
+	Secrets [][]byte
+	// Storage selects where the runtime stores the blob: "localStorage" (default) or "cookie".
+	Storage string
+	// MaxAge is how long a sealed blob stays valid (default 30 days).
+	MaxAge time.Duration
+}
+
+// clientPersistKey holds the derived key material for one secret.
+type clientPersistKey struct {
+	id     [4]byte
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// ClientStatePersistence seals and opens client-persisted state blobs.
+// Blobs are AES-GCM encrypted and HMAC-SHA256 signed with keys derived from
+// the configured secrets.
+type ClientStatePersistence struct {
+	keys    []clientPersistKey
+	tracked map[string]struct{}
+	storage string
+	maxAge  time.Duration
+}
+
+// clientPersistPayload is the plaintext sealed inside a blob.
+type clientPersistPayload struct {
+	Values   map[string]interface{} `json:"v"`
+	IssuedAt int64                  `json:"iat"`
+}
+
+// NewClientStatePersistence validates config and derives the key material.
+func NewClientStatePersistence(config ClientPersistConfig) (*ClientStatePersistence, error) {
+	if len(config.Secrets) == 0 {
+		return nil, fmt.Errorf("client state persistence requires at least one secret")
+	}
+	storage := config.Storage
+	switch storage {
+	case "":
+		storage = ClientPersistLocalStorage
+	case ClientPersistLocalStorage, ClientPersistCookie:
+	default:
+		return nil, fmt.Errorf("unknown client state persistence storage %q", config.Storage)
+	}
+	maxAge := config.MaxAge
+	if maxAge <= 0 {
+		maxAge = 30 * 24 * time.Hour
+	}
+
+	p := &ClientStatePersistence{
+		keys:    make([]clientPersistKey, 0, len(config.Secrets)),
+		tracked: make(map[string]struct{}, len(config.Keys)),
+		storage: storage,
+		maxAge:  maxAge,
+	}
+	for i, secret := range config.Secrets {
+		if len(secret) < minClientPersistSecretLen {
+			return nil, fmt.Errorf("client state persistence secret %d must be at least %d bytes", i, minClientPersistSecretLen)
+		}
+		encKey := deriveClientPersistKey(secret, "gospa-client-state-enc")
+		block, err := aes.NewCipher(encKey)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		macKey := deriveClientPersistKey(secret, "gospa-client-state-mac")
+		var id [4]byte
+		copy(id[:], deriveClientPersistKey(macKey, "gospa-client-state-id"))
+		p.keys = append(p.keys, clientPersistKey{id: id, aead: aead, macKey: macKey})
+	}
+	for _, key := range config.Keys {
+		if key != "" {
+			p.tracked[key] = struct{}{}
+		}
+	}
+	return p, nil
+}
+
+func deriveClientPersistKey(secret []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// Tracks reports whether key is persisted client-side.
+func (p *ClientStatePersistence) Tracks(key string) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.tracked[key]
+	return ok
+}
+
+// Storage returns the configured client storage mode.
+func (p *ClientStatePersistence) Storage() string {
+	return p.storage
+}
+
+// MaxAge returns how long sealed blobs remain valid.
+func (p *ClientStatePersistence) MaxAge() time.Duration {
+	return p.maxAge
+}
+
+// Select returns the subset of values whose keys are persisted client-side.
+func (p *ClientStatePersistence) Select(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(p.tracked))
+	for key := range p.tracked {
+		if v, ok := values[key]; ok {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// Seal encrypts and signs the tracked subset of values with the active secret.
+func (p *ClientStatePersistence) Seal(values map[string]interface{}) (string, error) {
+	plaintext, err := json.Marshal(clientPersistPayload{
+		Values:   p.Select(values),
+		IssuedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	key := p.keys[0]
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	body := make([]byte, 0, len(key.id)+len(nonce)+len(plaintext)+key.aead.Overhead())
+	body = append(body, key.id[:]...)
+	body = append(body, nonce...)
+	body = key.aead.Seal(body, nonce, plaintext, key.id[:])
+
+	mac := hmac.New(sha256.New, key.macKey)
+	_, _ = mac.Write(body)
+	return base64.RawURLEncoding.EncodeToString(body) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Open verifies and decrypts a sealed blob. Only tracked keys are returned.
+// rotated is true when the blob was sealed with a secret other than the active
+// one, signalling that the caller should re-seal it.
+func (p *ClientStatePersistence) Open(blob string) (values map[string]interface{}, rotated bool, err error) {
+	if blob == "" || len(blob) > maxClientPersistBlobLen {
+		return nil, false, ErrClientStateInvalid
+	}
+	encBody, encSig, ok := strings.Cut(blob, ".")
+	if !ok {
+		return nil, false, ErrClientStateInvalid
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encBody)
+	if err != nil {
+		return nil, false, ErrClientStateInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil || len(body) < 4 {
+		return nil, false, ErrClientStateInvalid
+	}
+
+	for i, key := range p.keys {
+		if !hmac.Equal(body[:4], key.id[:]) {
+			continue
+		}
+		mac := hmac.New(sha256.New, key.macKey)
+		_, _ = mac.Write(body)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, false, ErrClientStateInvalid
+		}
+		nonceSize := key.aead.NonceSize()
+		if len(body) < 4+nonceSize {
+			return nil, false, ErrClientStateInvalid
+		}
+		plaintext, err := key.aead.Open(nil, body[4:4+nonceSize], body[4+nonceSize:], key.id[:])
+		if err != nil {
+			return nil, false, ErrClientStateInvalid
+		}
+		var payload clientPersistPayload
+		if err := json.Unmarshal(plaintext, &payload); err != nil {
+			return nil, false, ErrClientStateInvalid
+		}
+		if time.Since(time.Unix(payload.IssuedAt, 0)) > p.maxAge {
+			return nil, false, ErrClientStateExpired
+		}
+		return p.Select(payload.Values), i > 0, nil
+	}
+	return nil, false, ErrClientStateInvalid
+}
+
+// restoreClientPersistedState verifies blob and adds any persisted keys that
+// are missing from the client's state. Blobs sealed with a rotated-out secret
+// are re-sealed with the active one.
+func restoreClientPersistedState(client *WSClient, p *ClientStatePersistence, blob string) {
+	values, rotated, err := p.Open(blob)
+	if err != nil {
+		slog.Default().Debug("discarding persisted client state", "client", client.ID, "err", err)
+		return
+	}
+	for key, value := range values {
+		if _, exists := client.State.Get(key); !exists {
+			client.State.Add(key, state.NewRune(value))
+		}
+	}
+	if rotated {
+		sendClientPersistedState(client, p)
+	}
+}
+
+// sendClientPersistedState seals the client's tracked state and sends it to
+// the runtime for storage.
+func sendClientPersistedState(client *WSClient, p *ClientStatePersistence) {
+	if p == nil {
+		return
+	}
+	blob, err := p.Seal(client.State.ToMap())
+	if err != nil {
+		slog.Default().Warn("failed to seal persisted client state", "client", client.ID, "err", err)
+		return
+	}
+	_ = client.SendJSON(map[string]interface{}{
+		"type": "persist",
+		"data": map[string]interface{}{
+			"blob":    blob,
+			"storage": p.storage,
+			"maxAge":  int(p.maxAge.Seconds()),
+		},
+	})
+}
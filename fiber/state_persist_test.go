@@ -0,0 +1,149 @@
+package fiber
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPersistSecret(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestClientStatePersistenceRoundTrip(t *testing.T) {
+	p, err := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme", "cart"},
+		Secrets: [][]byte{testPersistSecret('a')},
+	})
+	if err != nil {
+		t.Fatalf("NewClientStatePersistence: %v", err)
+	}
+
+	blob, err := p.Seal(map[string]interface{}{
+		"theme":  "dark",
+		"cart":   []interface{}{"sku-1"},
+		"cursor": 42.0,
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if strings.Contains(blob, "dark") {
+		t.Fatal("sealed blob leaks plaintext")
+	}
+
+	values, rotated, err := p.Open(blob)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if rotated {
+		t.Fatal("blob sealed with active secret reported as rotated")
+	}
+	if values["theme"] != "dark" {
+		t.Fatalf("expected theme=dark, got %v", values["theme"])
+	}
+	if _, ok := values["cursor"]; ok {
+		t.Fatal("untracked key must not be persisted")
+	}
+}
+
+func TestClientStatePersistenceRejectsTampering(t *testing.T) {
+	p, err := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme"},
+		Secrets: [][]byte{testPersistSecret('a')},
+	})
+	if err != nil {
+		t.Fatalf("NewClientStatePersistence: %v", err)
+	}
+	blob, err := p.Seal(map[string]interface{}{"theme": "dark"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	body, sig, _ := strings.Cut(blob, ".")
+	flipped := []byte(body)
+	if flipped[10] == 'A' {
+		flipped[10] = 'B'
+	} else {
+		flipped[10] = 'A'
+	}
+	for _, bad := range []string{
+		string(flipped) + "." + sig,
+		body + "." + sig[:len(sig)-2] + "AA",
+		body,
+		"",
+	} {
+		if _, _, err := p.Open(bad); !errors.Is(err, ErrClientStateInvalid) {
+			t.Fatalf("expected ErrClientStateInvalid for %q, got %v", bad, err)
+		}
+	}
+
+	other, _ := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme"},
+		Secrets: [][]byte{testPersistSecret('b')},
+	})
+	if _, _, err := other.Open(blob); !errors.Is(err, ErrClientStateInvalid) {
+		t.Fatalf("expected blob from foreign secret to be rejected, got %v", err)
+	}
+}
+
+func TestClientStatePersistenceKeyRotation(t *testing.T) {
+	oldP, _ := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme"},
+		Secrets: [][]byte{testPersistSecret('a')},
+	})
+	blob, err := oldP.Seal(map[string]interface{}{"theme": "light"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	rotatedP, err := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme"},
+		Secrets: [][]byte{testPersistSecret('b'), testPersistSecret('a')},
+	})
+	if err != nil {
+		t.Fatalf("NewClientStatePersistence: %v", err)
+	}
+	values, rotated, err := rotatedP.Open(blob)
+	if err != nil {
+		t.Fatalf("Open after rotation: %v", err)
+	}
+	if !rotated {
+		t.Fatal("expected rotated=true for blob sealed with previous secret")
+	}
+	if values["theme"] != "light" {
+		t.Fatalf("expected theme=light, got %v", values["theme"])
+	}
+}
+
+func TestClientStatePersistenceExpiry(t *testing.T) {
+	p, _ := NewClientStatePersistence(ClientPersistConfig{
+		Keys:    []string{"theme"},
+		Secrets: [][]byte{testPersistSecret('a')},
+		MaxAge:  time.Nanosecond,
+	})
+	blob, err := p.Seal(map[string]interface{}{"theme": "dark"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, _, err := p.Open(blob); !errors.Is(err, ErrClientStateExpired) {
+		t.Fatalf("expected ErrClientStateExpired, got %v", err)
+	}
+}
+
+func TestNewClientStatePersistenceValidation(t *testing.T) {
+	if _, err := NewClientStatePersistence(ClientPersistConfig{Keys: []string{"a"}}); err == nil {
+		t.Fatal("expected error without secrets")
+	}
+	if _, err := NewClientStatePersistence(ClientPersistConfig{Secrets: [][]byte{[]byte("short")}}); err == nil {
+		t.Fatal("expected error for short secret")
+	}
+	if _, err := NewClientStatePersistence(ClientPersistConfig{
+		Secrets: [][]byte{testPersistSecret('a')},
+		Storage: "indexeddb",
+	}); err == nil {
+		t.Fatal("expected error for unknown storage")
+	}
+}
@@ -0,0 +1,176 @@
+package fiber
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+)
+
+// QuotaPolicy decides what happens to an "update" that would take a client
+// over its StateQuota key or byte limit.
+type QuotaPolicy string
+
+const (
+	// QuotaReject answers the update with an ErrorCodeLimitExceeded error
+	// and leaves the state unchanged.
+	QuotaReject QuotaPolicy = "reject"
+	// QuotaEvictOldest removes the keys the client created least recently
+	// updated until the update fits. Keys the server declared are never
+	// evicted; when they alone exceed the limits, the update is rejected.
+	QuotaEvictOldest QuotaPolicy = "evict-oldest"
+)
+
+// StateQuota bounds the state one connection can write with "update"
+// messages, where every unknown key creates a new Rune. Sizes are those of
+// the JSON-encoded values. Zero fields impose no limit.
+type StateQuota struct {
+	// MaxKeys bounds the keys the client may create.
+	MaxKeys int
+	// MaxValueBytes bounds one value. Larger updates are always rejected.
+	MaxValueBytes int
+	// MaxTotalBytes bounds the values the client has written, together.
+	MaxTotalBytes int
+	// Policy applies to updates over MaxKeys or MaxTotalBytes (default
+	// QuotaReject).
+	Policy QuotaPolicy
+}
+
+// stateQuota enforces a StateQuota for one client.
+type stateQuota struct {
+	StateQuota
+	mu sync.Mutex
+	// sizes holds the size of every key the client wrote.
+	sizes map[string]int
+	total int
+	// created orders the keys the client created, least recently updated
+	// first.
+	created  *list.List
+	elements map[string]*list.Element
+}
+
+// newStateQuota returns the tracker of cfg, or nil without one.
+func newStateQuota(cfg *StateQuota) *stateQuota {
+	if cfg == nil {
+		return nil
+	}
+	return &stateQuota{
+		StateQuota: *cfg,
+		sizes:      make(map[string]int),
+		created:    list.New(),
+		elements:   make(map[string]*list.Element),
+	}
+}
+
+// admit accounts for an update of key to a value of size bytes. isNew is
+// true when the update creates the key. It returns the keys to remove from
+// the client's state to make room, or the error reply when the update is
+// rejected.
+func (q *stateQuota) admit(key string, size int, isNew bool) ([]string, map[string]interface{}) {
+	if q.MaxValueBytes > 0 && size > q.MaxValueBytes {
+		return nil, quotaError("value", q.MaxValueBytes)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	total := q.total - q.sizes[key] + size
+	keys := q.created.Len()
+	if isNew {
+		keys++
+	}
+	var evict []string
+	for (q.MaxKeys > 0 && keys > q.MaxKeys) || (q.MaxTotalBytes > 0 && total > q.MaxTotalBytes) {
+		if q.Policy != QuotaEvictOldest {
+			return nil, q.overError(keys, total)
+		}
+		victim := q.oldestOtherThan(key, len(evict))
+		if victim == "" {
+			return nil, q.overError(keys, total)
+		}
+		evict = append(evict, victim)
+		total -= q.sizes[victim]
+		keys--
+	}
+
+	for _, victim := range evict {
+		q.forgetLocked(victim)
+	}
+	q.total = total
+	q.sizes[key] = size
+	if el, ok := q.elements[key]; ok {
+		q.created.MoveToBack(el)
+	} else if isNew {
+		q.elements[key] = q.created.PushBack(key)
+	}
+	return evict, nil
+}
+
+// oldestOtherThan returns the least recently updated created key after the
+// first skip ones, other than key, or "" when there is none.
+func (q *stateQuota) oldestOtherThan(key string, skip int) string {
+	for el := q.created.Front(); el != nil; el = el.Next() {
+		k := el.Value.(string)
+		if k == key {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		return k
+	}
+	return ""
+}
+
+// forgetLocked drops key; q.mu must be held.
+func (q *stateQuota) forgetLocked(key string) {
+	q.total -= q.sizes[key]
+	delete(q.sizes, key)
+	if el, ok := q.elements[key]; ok {
+		q.created.Remove(el)
+		delete(q.elements, key)
+	}
+}
+
+// overError is the reply to an update over the key or byte limit.
+func (q *stateQuota) overError(keys, total int) map[string]interface{} {
+	if q.MaxKeys > 0 && keys > q.MaxKeys {
+		return quotaError("keys", q.MaxKeys)
+	}
+	return quotaError("total", q.MaxTotalBytes)
+}
+
+// quotaError is the reply to an update over a StateQuota limit. limit is
+// "keys", "value" or "total".
+func quotaError(limit string, n int) map[string]interface{} {
+	reply := wsError(ErrorCodeLimitExceeded, "State quota exceeded ("+limit+" limit "+strconv.Itoa(n)+")")
+	reply["quota"] = limit
+	reply["limit"] = n
+	return reply
+}
+
+// admitUpdate applies the client's quota to an update of key to value. It
+// removes evicted keys from the client's state, or sends the error reply and
+// returns false when the update is rejected.
+func (c *WSClient) admitUpdate(key string, value interface{}, sendResponse func(map[string]interface{})) bool {
+	encoded, err := JSONMarshal(value)
+	if err != nil {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid update value"))
+		return false
+	}
+	_, exists := c.State.Get(key)
+	evict, reply := c.quota.admit(key, len(encoded), !exists)
+	if reply != nil {
+		if c.hub != nil {
+			c.hub.quotaRejected.Add(1)
+		}
+		sendResponse(reply)
+		return false
+	}
+	for _, victim := range evict {
+		c.State.Remove(victim)
+	}
+	if c.hub != nil && len(evict) > 0 {
+		c.hub.quotaEvicted.Add(uint64(len(evict)))
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package fiber
+
+import (
+	"strings"
+	"testing"
+)
+
+func updateFrame(key, value string) WSMessage {
+	return WSMessage{Type: "update", Payload: []byte(`{"key":"` + key + `","value":` + value + `}`)}
+}
+
+func TestStateQuotaReject(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "q", nil)
+	client.quota = newStateQuota(&StateQuota{MaxKeys: 2, MaxValueBytes: 16, MaxTotalBytes: 20})
+	client.State.AddAny("server", "declared")
+
+	for _, key := range []string{"a", "b"} {
+		DefaultMessageHandler(client, updateFrame(key, `"12345678"`))
+		if s := nextFrame(t, client); !strings.Contains(s, `"success":true`) {
+			t.Fatalf("update of %s = %s", key, s)
+		}
+	}
+	DefaultMessageHandler(client, updateFrame("c", `1`))
+	if s := nextFrame(t, client); !strings.Contains(s, `"LIMIT_EXCEEDED"`) || !strings.Contains(s, `"quota":"keys"`) {
+		t.Fatalf("update over MaxKeys = %s", s)
+	}
+	if _, ok := client.State.Get("c"); ok {
+		t.Fatal("rejected key was created")
+	}
+	DefaultMessageHandler(client, updateFrame("a", `"12345678901234567890"`))
+	if s := nextFrame(t, client); !strings.Contains(s, `"quota":"value"`) {
+		t.Fatalf("update over MaxValueBytes = %s", s)
+	}
+	// Updating a server key counts towards the total.
+	DefaultMessageHandler(client, updateFrame("server", `"12345"`))
+	if s := nextFrame(t, client); !strings.Contains(s, `"quota":"total"`) {
+		t.Fatalf("update over MaxTotalBytes = %s", s)
+	}
+	if got := hub.Stats().StateQuotaRejected; got != 3 {
+		t.Fatalf("StateQuotaRejected = %d, want 3", got)
+	}
+}
+
+func TestStateQuotaEvictOldest(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "q", nil)
+	client.quota = newStateQuota(&StateQuota{MaxKeys: 2, Policy: QuotaEvictOldest})
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		DefaultMessageHandler(client, updateFrame(key, `1`))
+		if s := nextFrame(t, client); !strings.Contains(s, `"success":true`) {
+			t.Fatalf("update of %s = %s", key, s)
+		}
+	}
+	// b was updated least recently.
+	if _, ok := client.State.Get("b"); ok {
+		t.Fatal("least recently updated key was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := client.State.Get(key); !ok {
+			t.Fatalf("key %s was evicted", key)
+		}
+	}
+	if got := hub.Stats().StateQuotaEvicted; got != 1 {
+		t.Fatalf("StateQuotaEvicted = %d, want 1", got)
+	}
+}
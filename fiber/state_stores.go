@@ -0,0 +1,60 @@
+package fiber
+
+import (
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// stateStoresLocal is the Locals key StateStoresMiddleware sets.
+const stateStoresLocal = "gospa.state_stores"
+
+// StateStores are the session and client state stores a set of handlers
+// reads and writes. By default handlers use the global stores set by
+// InitStateStores; an app served for one host among several carries its
+// own, so apps sharing a Storage backend keep their sessions and client
+// state apart.
+type StateStores struct {
+	Sessions    *SessionStore
+	ClientState *ClientStateStore
+}
+
+// GlobalStateStores returns the global stores as last set by
+// InitStateStores, along with their persistence filter, retention and
+// session affinity. Later calls to InitStateStores don't change them.
+func GlobalStateStores() *StateStores {
+	return &StateStores{Sessions: globalSessionStore, ClientState: globalClientStateStore}
+}
+
+// RevokeClientSessions invalidates every session token the stores issued
+// for clientID and returns the number revoked, see RevokeClientSessions.
+func (s *StateStores) RevokeClientSessions(clientID string) (int, error) {
+	return s.Sessions.RemoveClientSessions(clientID)
+}
+
+// StateStoresMiddleware makes SessionMiddleware, flashes, SessionClientID,
+// the state sync and SSE handlers and the other request helpers after it
+// use stores instead of the global ones. Nil keeps the global ones.
+func StateStoresMiddleware(stores *StateStores) gofiber.Handler {
+	return func(c gofiber.Ctx) error {
+		if stores != nil {
+			c.Locals(stateStoresLocal, stores)
+		}
+		return c.Next()
+	}
+}
+
+// requestStateStores returns the stores StateStoresMiddleware set for c, or
+// the global ones.
+func requestStateStores(c gofiber.Ctx) *StateStores {
+	if stores, ok := c.Locals(stateStoresLocal).(*StateStores); ok {
+		return stores
+	}
+	return GlobalStateStores()
+}
+
+// stores returns the stores config names, or the global ones.
+func (config WebSocketConfig) stores() *StateStores {
+	if config.Stores != nil {
+		return config.Stores
+	}
+	return GlobalStateStores()
+}
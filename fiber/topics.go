@@ -0,0 +1,219 @@
+package fiber
+
+import (
+	"slices"
+	"strings"
+)
+
+// TopicAuthorizer decides whether client may subscribe itself to topic with
+// a "subscribe" message. Values set by middleware during the handshake are
+// available through client.Conn.Locals.
+type TopicAuthorizer func(client *WSClient, topic string) bool
+
+// AllowTopicPrefixes returns a TopicAuthorizer that lets any client subscribe
+// to topics starting with one of prefixes, for public data such as
+// "ticker:" or "post:".
+func AllowTopicPrefixes(prefixes ...string) TopicAuthorizer {
+	return func(_ *WSClient, topic string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(topic, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+const (
+	// maxTopicLen bounds the topic name a client may send.
+	maxTopicLen = 256
+	// maxClientTopics bounds the topics one connection may join itself.
+	maxClientTopics = 64
+)
+
+// topicRequest is the payload of "subscribe" and "unsubscribe" frames. A
+// frame carries either a hub topic or a list of state key prefixes.
+type topicRequest struct {
+	Topic string   `json:"topic"`
+	Keys  []string `json:"keys"`
+}
+
+// handleTopicMessage processes client "subscribe" and "unsubscribe" frames.
+// Subscriptions are rejected unless the connection has a TopicAuthorizer that
+// allows the topic, so topics the server assigns with WSHub.Subscribe stay
+// private by default. Topics used by shared CRDTs and lists cannot be joined
+// this way.
+func handleTopicMessage(client *WSClient, msg WSMessage, sendResponse func(map[string]interface{})) {
+	var req topicRequest
+	b, ok := msg.Payload.([]byte)
+	if !ok {
+		b, _ = JSONMarshal(msg.Payload)
+	}
+	if err := JSONUnmarshal(b, &req); err != nil {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid topic"))
+		return
+	}
+	if req.Topic == "" && len(req.Keys) > 0 {
+		handleKeyInterest(client, msg.Type, req.Keys, sendResponse)
+		return
+	}
+	if req.Topic == "" || len(req.Topic) > maxTopicLen || isReservedTopic(req.Topic) {
+		sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid topic"))
+		return
+	}
+	if client.hub == nil {
+		reply := wsError(ErrorCodeUnavailable, "Topics are unavailable")
+		reply["topic"] = req.Topic
+		sendResponse(reply)
+		return
+	}
+
+	if msg.Type == "unsubscribe" {
+		client.hub.Unsubscribe(req.Topic, client.ID)
+		sendResponse(map[string]interface{}{"type": "unsubscribed", "topic": req.Topic})
+		return
+	}
+	if client.authorizeTopic == nil || !client.authorizeTopic(client, req.Topic) {
+		reply := wsError(ErrorCodeForbidden, "Not authorized for topic: "+req.Topic)
+		reply["topic"] = req.Topic
+		sendResponse(reply)
+		return
+	}
+	if !client.hub.subscribeWithLimit(req.Topic, client, maxClientTopics) {
+		reply := wsError(ErrorCodeLimitExceeded, "Too many topic subscriptions")
+		reply["topic"] = req.Topic
+		sendResponse(reply)
+		return
+	}
+	sendResponse(map[string]interface{}{"type": "subscribed", "topic": req.Topic})
+}
+
+// handleKeyInterest adds or removes state key prefixes for client. Declaring
+// interest only narrows what the client already receives, so no authorization
+// is needed.
+func handleKeyInterest(client *WSClient, typ string, keys []string, sendResponse func(map[string]interface{})) {
+	for _, k := range keys {
+		if k == "" || len(k) > maxTopicLen {
+			sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid key prefix"))
+			return
+		}
+	}
+	var ok bool
+	if typ == "unsubscribe" {
+		ok = client.RemoveKeyInterest(keys...)
+	} else {
+		ok = client.AddKeyInterest(keys...)
+	}
+	if !ok {
+		reply := wsError(ErrorCodeLimitExceeded, "Too many key subscriptions")
+		reply["keys"] = keys
+		sendResponse(reply)
+		return
+	}
+	sendResponse(map[string]interface{}{"type": typ + "d", "keys": keys})
+}
+
+// AddKeyInterest limits the global state syncs client receives (BroadcastState)
+// to keys starting with one of its declared prefixes. A client that has
+// declared no prefixes receives every key. Session- and topic-scoped syncs are
+// not filtered. It reports false, changing nothing, if the client would exceed
+// the prefix limit.
+func (c *WSClient) AddKeyInterest(prefixes ...string) bool {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	var next []string
+	if cur := c.keyPrefixes.Load(); cur != nil {
+		next = append(next, *cur...)
+	}
+	for _, p := range prefixes {
+		if !slices.Contains(next, p) {
+			next = append(next, p)
+		}
+	}
+	if len(next) > maxClientTopics {
+		return false
+	}
+	c.keyPrefixes.Store(&next)
+	return true
+}
+
+// RemoveKeyInterest drops declared key prefixes. Once the last one is
+// removed the client receives every key again.
+func (c *WSClient) RemoveKeyInterest(prefixes ...string) bool {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	cur := c.keyPrefixes.Load()
+	if cur == nil {
+		return true
+	}
+	next := slices.DeleteFunc(slices.Clone(*cur), func(p string) bool {
+		return slices.Contains(prefixes, p)
+	})
+	if len(next) == 0 {
+		c.keyPrefixes.Store(nil)
+	} else {
+		c.keyPrefixes.Store(&next)
+	}
+	return true
+}
+
+// wantsKey reports whether a global sync for key should reach the client.
+func (c *WSClient) wantsKey(key string) bool {
+	prefixes := c.keyPrefixes.Load()
+	if prefixes == nil {
+		return true
+	}
+	for _, p := range *prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeySubscribers returns the number of clients connected to this process
+// that receive global syncs for key (see WSClient.AddKeyInterest).
+func (h *WSHub) KeySubscribers(key string) int {
+	n := 0
+	h.Range(func(client *WSClient) bool {
+		if client.wantsKey(key) {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// subscribeWithLimit adds client to topic unless it already has limit
+// topics. Joining a topic it is already in always succeeds.
+func (h *WSHub) subscribeWithLimit(topic string, client *WSClient, limit int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !client.topics[topic] && len(client.topics) >= limit {
+		return false
+	}
+	if h.ClientsByTopic[topic] == nil {
+		h.ClientsByTopic[topic] = make(map[string]*WSClient)
+	}
+	h.ClientsByTopic[topic][client.ID] = client
+	client.topics[topic] = true
+	return true
+}
+
+// BroadcastStateToTopic sends a state update only to clients subscribed to
+// topic, on this or any other process sharing the hub's PubSub.
+func BroadcastStateToTopic(hub *WSHub, topic, key string, value interface{}) error {
+	if hub == nil {
+		return nil
+	}
+	data, err := JSONMarshal(map[string]interface{}{
+		"type":   "sync",
+		"key":    key,
+		"value":  value,
+		"_topic": topic,
+	})
+	if err != nil {
+		return err
+	}
+	return hub.publish("gospa:broadcast", data)
+}
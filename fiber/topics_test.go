@@ -0,0 +1,157 @@
+package fiber
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// topicFrame sends a subscribe or unsubscribe frame for topic and returns the
+// response.
+func topicFrame(client *WSClient, typ, topic string) map[string]interface{} {
+	var resp map[string]interface{}
+	handleTopicMessage(client, WSMessage{Type: typ, Payload: map[string]interface{}{"topic": topic}}, func(p map[string]interface{}) {
+		resp = p
+	})
+	return resp
+}
+
+func newTopicTestClient(hub *WSHub, id string, authorize TopicAuthorizer) *WSClient {
+	client := NewWSClient(id, nil, WebSocketConfig{AuthorizeTopic: authorize})
+	client.hub = hub
+	hub.register(client)
+	return client
+}
+
+func TestTopicSubscribeRejectedWithoutAuthorizer(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c1", nil)
+
+	if resp := topicFrame(client, "subscribe", "post:1"); resp["type"] != "error" {
+		t.Fatalf("subscribe without authorizer = %v, want error", resp)
+	}
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.ClientsByTopic["post:1"]) != 0 {
+		t.Fatal("client joined topic without authorization")
+	}
+}
+
+func TestTopicSubscribeReceivesOnlyTopicBroadcasts(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	allow := AllowTopicPrefixes("post:")
+	viewer := newTopicTestClient(hub, "viewer", allow)
+	other := newTopicTestClient(hub, "other", allow)
+
+	if resp := topicFrame(viewer, "subscribe", "post:1"); resp["type"] != "subscribed" || resp["topic"] != "post:1" {
+		t.Fatalf("subscribe = %v", resp)
+	}
+	for _, topic := range []string{"user:1", "crdt:doc", ""} {
+		if resp := topicFrame(viewer, "subscribe", topic); resp["type"] != "error" {
+			t.Fatalf("subscribe %q = %v, want error", topic, resp)
+		}
+	}
+
+	if err := BroadcastStateToTopic(hub, "post:1", "likes", 3); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-viewer.Send:
+		if !strings.Contains(string(msg), `"likes"`) {
+			t.Fatalf("unexpected frame %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscriber did not receive the topic broadcast")
+	}
+	select {
+	case msg := <-other.Send:
+		t.Fatalf("non-subscriber received %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if resp := topicFrame(viewer, "unsubscribe", "post:1"); resp["type"] != "unsubscribed" {
+		t.Fatalf("unsubscribe = %v", resp)
+	}
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.ClientsByTopic["post:1"]) != 0 || len(viewer.topics) != 0 {
+		t.Fatal("client still subscribed after unsubscribe")
+	}
+}
+
+func TestTopicSubscribeLimit(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	client := newTopicTestClient(hub, "c1", AllowTopicPrefixes(""))
+
+	for i := 0; i < maxClientTopics; i++ {
+		if resp := topicFrame(client, "subscribe", fmt.Sprintf("t:%d", i)); resp["type"] != "subscribed" {
+			t.Fatalf("subscribe %d = %v", i, resp)
+		}
+	}
+	if resp := topicFrame(client, "subscribe", "t:extra"); resp["type"] != "error" {
+		t.Fatalf("subscribe past limit = %v, want error", resp)
+	}
+	if resp := topicFrame(client, "subscribe", "t:0"); resp["type"] != "subscribed" {
+		t.Fatalf("re-subscribe at limit = %v, want subscribed", resp)
+	}
+}
+
+func TestBroadcastStateFiltersByKeyInterest(t *testing.T) {
+	hub := NewWSHub(nil)
+	defer hub.Close()
+	go hub.Run()
+	cpu := newTopicTestClient(hub, "cpu", nil)
+	all := newTopicTestClient(hub, "all", nil)
+
+	if resp := topicFrame(cpu, "subscribe", ""); resp["type"] != "error" {
+		t.Fatalf("empty subscribe = %v, want error", resp)
+	}
+	var resp map[string]interface{}
+	handleTopicMessage(cpu, WSMessage{Type: "subscribe", Payload: map[string]interface{}{"keys": []string{"cpu."}}}, func(p map[string]interface{}) {
+		resp = p
+	})
+	if resp["type"] != "subscribed" {
+		t.Fatalf("key subscribe = %v", resp)
+	}
+
+	if err := BroadcastState(hub, "mem.used", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := BroadcastState(hub, "cpu.load", 2); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-all.Send:
+			got += string(msg)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("unfiltered client received %d of 2 frames", i)
+		}
+	}
+	if !strings.Contains(got, "mem.used") || !strings.Contains(got, "cpu.load") {
+		t.Fatalf("unfiltered client got %s", got)
+	}
+	select {
+	case msg := <-cpu.Send:
+		if !strings.Contains(string(msg), "cpu.load") {
+			t.Fatalf("filtered client got %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("filtered client did not receive cpu.load")
+	}
+	select {
+	case msg := <-cpu.Send:
+		t.Fatalf("filtered client got %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cpu.RemoveKeyInterest("cpu.")
+	if !cpu.wantsKey("mem.used") {
+		t.Fatal("client with no key prefixes should receive every key")
+	}
+}
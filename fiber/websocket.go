@@ -7,12 +7,14 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aydenstechdungeon/gospa/state"
@@ -24,10 +26,10 @@ import (
 )
 
 const (
-	// Time allowed to keep an idle connection alive.
-	pongWait = 60 * time.Second
-	// Send pings to peer with this period.
-	pingPeriod = (pongWait * 9) / 10
+	// Default time allowed to keep an idle connection alive.
+	defaultPongWait = 60 * time.Second
+	// Default time allowed to write a frame to the peer.
+	defaultWriteWait = 10 * time.Second
 )
 
 // ConnectionRateLimiter implements per-IP rate limiting for WebSocket connections
@@ -139,7 +141,7 @@ func (rl *ConnectionRateLimiter) Allow(ip string) bool {
 		data, err := storage.Get(context.Background(), key)
 		if err == nil {
 			var b rateBucket
-			if json.Unmarshal(data, &b) == nil {
+			if JSONUnmarshal(data, &b) == nil {
 				bucket = &b
 			}
 		}
@@ -149,7 +151,7 @@ func (rl *ConnectionRateLimiter) Allow(ip string) bool {
 				Tokens:     maxTokens - 1,
 				LastRefill: now,
 			}
-			newBytes, _ := json.Marshal(bucket)
+			newBytes, _ := JSONMarshal(bucket)
 			_ = storage.Set(context.Background(), key, newBytes, 10*time.Minute)
 			return true
 		}
@@ -167,7 +169,7 @@ func (rl *ConnectionRateLimiter) Allow(ip string) bool {
 			allowed = true
 		}
 
-		newBytes, _ := json.Marshal(bucket)
+		newBytes, _ := JSONMarshal(bucket)
 		_ = storage.Set(context.Background(), key, newBytes, 10*time.Minute)
 
 		return allowed
@@ -256,8 +258,15 @@ type sessionEntry struct {
 const SessionTTL = 24 * time.Hour
 
 // SessionStore maps session tokens to client IDs for secure HTTP state sync.
+// It also keeps a per-client index of issued tokens under
+// "client:<id>:sessions" so they can all be revoked at once.
 type SessionStore struct {
 	storage store.Storage
+	// indexMu serializes read-modify-write updates of the client index on
+	// backends that don't implement store.SetStorage.
+	indexMu sync.Mutex
+	// affinity is the process ID embedded in issued tokens ("" = none)
+	affinity string
 }
 
 // NewSessionStore creates a new session store.
@@ -270,7 +279,7 @@ func NewSessionStore(storage store.Storage) *SessionStore {
 // CreateSession creates a new session token for a client ID.
 // Returns the session token and an error if token generation or persistence fails.
 func (s *SessionStore) CreateSession(clientID string) (string, error) {
-	token := generateSecureToken()
+	token := s.tokenFor(generateSecureToken())
 	if token == "" {
 		return "", fmt.Errorf("failed to generate secure session token")
 	}
@@ -278,11 +287,17 @@ func (s *SessionStore) CreateSession(clientID string) (string, error) {
 		ClientID:  clientID,
 		ExpiresAt: time.Now().Add(SessionTTL),
 	}
-	bytes, err := json.Marshal(entry)
+	bytes, err := JSONMarshal(entry)
 	if err != nil {
 		return "", err
 	}
-	if err := s.storage.Set(context.Background(), token, bytes, SessionTTL); err != nil {
+	ctx := context.Background()
+	if err := s.storage.Set(ctx, token, bytes, SessionTTL); err != nil {
+		return "", err
+	}
+	// A token that can't be indexed couldn't be revoked, so don't issue it.
+	if err := s.indexSession(ctx, clientID, token); err != nil {
+		_ = s.storage.Delete(ctx, token)
 		return "", err
 	}
 	return token, nil
@@ -295,7 +310,7 @@ func (s *SessionStore) ValidateSession(token string) (string, bool) {
 		return "", false
 	}
 	var entry sessionEntry
-	if err := json.Unmarshal(bytes, &entry); err != nil {
+	if err := JSONUnmarshal(bytes, &entry); err != nil {
 		return "", false
 	}
 	if time.Now().After(entry.ExpiresAt) {
@@ -316,7 +331,7 @@ func (s *SessionStore) SetFlash(token, key string, value interface{}) error {
 	var flashes map[string]interface{}
 	data, err := s.storage.Get(context.Background(), fKey)
 	if err == nil {
-		_ = json.Unmarshal(data, &flashes)
+		_ = JSONUnmarshal(data, &flashes)
 	}
 	if flashes == nil {
 		flashes = make(map[string]interface{})
@@ -324,7 +339,7 @@ func (s *SessionStore) SetFlash(token, key string, value interface{}) error {
 
 	flashes[key] = value
 
-	newData, err := json.Marshal(flashes)
+	newData, err := JSONMarshal(flashes)
 	if err != nil {
 		return err
 	}
@@ -349,7 +364,7 @@ func (s *SessionStore) GetFlashes(token string) map[string]interface{} {
 	_ = s.storage.Delete(context.Background(), fKey)
 
 	var flashes map[string]interface{}
-	if err := json.Unmarshal(data, &flashes); err != nil {
+	if err := JSONUnmarshal(data, &flashes); err != nil {
 		return nil
 	}
 	return flashes
@@ -357,15 +372,116 @@ func (s *SessionStore) GetFlashes(token string) map[string]interface{} {
 
 // RemoveSession removes a session token.
 func (s *SessionStore) RemoveSession(token string) {
-	_ = s.storage.Delete(context.Background(), token)
+	ctx := context.Background()
+	if data, err := s.storage.Get(ctx, token); err == nil {
+		var entry sessionEntry
+		if JSONUnmarshal(data, &entry) == nil && entry.ClientID != "" {
+			s.unindexSession(ctx, entry.ClientID, token)
+		}
+	}
+	_ = s.storage.Delete(ctx, token)
+}
+
+// RemoveClientSessions revokes every session token issued for a client ID and
+// returns how many were removed. Tokens created while it runs may survive.
+func (s *SessionStore) RemoveClientSessions(clientID string) (int, error) {
+	ctx := context.Background()
+	tokens, err := s.clientSessions(ctx, clientID)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, token := range tokens {
+		if _, err := s.storage.Get(ctx, token); err == nil {
+			removed++
+		}
+		if err := s.storage.Delete(ctx, token); err != nil {
+			return removed, err
+		}
+		_ = s.storage.Delete(ctx, "flash:"+token)
+	}
+	return removed, s.storage.Delete(ctx, clientSessionsKey(clientID))
 }
 
-// RemoveClientSessions removes all sessions for a client ID.
-// NOTE: Depending on the storage backend, this might not be easily achievable without secondary indices.
-// For KV-only stores, this operation shouldn't be relied upon.
-func (s *SessionStore) RemoveClientSessions(_ string) {
-	// Not practically supported in KV stores without key scanning.
-	// Since sessions expire naturally through TTL, this is a no-op for now.
+func clientSessionsKey(clientID string) string {
+	return "client:" + clientID + ":sessions"
+}
+
+// indexSession records token under clientID. Backends with set support do
+// this atomically; others fall back to a JSON list updated under indexMu,
+// which is only atomic within this process.
+func (s *SessionStore) indexSession(ctx context.Context, clientID, token string) error {
+	key := clientSessionsKey(clientID)
+	if sets, ok := s.storage.(store.SetStorage); ok {
+		return sets.SAdd(ctx, key, token, SessionTTL)
+	}
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	tokens, err := s.clientSessions(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	// Drop tokens that have already expired so the list stays bounded.
+	live := make([]string, 0, len(tokens)+1)
+	for _, t := range tokens {
+		if _, err := s.storage.Get(ctx, t); err == nil {
+			live = append(live, t)
+		}
+	}
+	data, err := JSONMarshal(append(live, token))
+	if err != nil {
+		return err
+	}
+	return s.storage.Set(ctx, key, data, SessionTTL)
+}
+
+func (s *SessionStore) unindexSession(ctx context.Context, clientID, token string) {
+	key := clientSessionsKey(clientID)
+	if sets, ok := s.storage.(store.SetStorage); ok {
+		_ = sets.SRem(ctx, key, token)
+		return
+	}
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	tokens, err := s.clientSessions(ctx, clientID)
+	if err != nil {
+		return
+	}
+	kept := tokens[:0]
+	for _, t := range tokens {
+		if t != token {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		_ = s.storage.Delete(ctx, key)
+		return
+	}
+	if data, err := JSONMarshal(kept); err == nil {
+		_ = s.storage.Set(ctx, key, data, SessionTTL)
+	}
+}
+
+// clientSessions returns the tokens indexed for clientID.
+func (s *SessionStore) clientSessions(ctx context.Context, clientID string) ([]string, error) {
+	key := clientSessionsKey(clientID)
+	if sets, ok := s.storage.(store.SetStorage); ok {
+		return sets.SMembers(ctx, key)
+	}
+	data, err := s.storage.Get(ctx, key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []string
+	if err := JSONUnmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
 }
 
 // Global session store for HTTP state sync. Defaulting to in-memory.
@@ -385,7 +501,9 @@ func generateSecureToken() string {
 
 // ClientStateStore persists client state by client ID for session restoration.
 type ClientStateStore struct {
-	storage store.Storage
+	storage   store.Storage
+	filter    *StateKeyFilter
+	retention time.Duration
 }
 
 // NewClientStateStore creates a new client state store.
@@ -395,9 +513,25 @@ func NewClientStateStore(storage store.Storage) *ClientStateStore {
 	}
 }
 
+// SetKeyFilter restricts which state keys are persisted. A nil filter persists all keys.
+func (s *ClientStateStore) SetKeyFilter(filter *StateKeyFilter) {
+	s.filter = filter
+}
+
+// Persists reports whether changes to key should be written to storage.
+func (s *ClientStateStore) Persists(key string) bool {
+	return s.filter.Allows(key)
+}
+
 // Save saves a client's state.
 func (s *ClientStateStore) Save(clientID string, sm *state.StateMap) {
-	bytes, err := sm.MarshalJSON()
+	var bytes []byte
+	var err error
+	if s.filter == nil {
+		bytes, err = sm.MarshalJSON()
+	} else {
+		bytes, err = JSONMarshal(s.filter.Apply(sm.ToMap()))
+	}
 	if err == nil {
 		_ = s.storage.Set(context.Background(), "state:"+clientID, bytes, SessionTTL)
 	}
@@ -411,7 +545,7 @@ func (s *ClientStateStore) Get(clientID string) (*state.StateMap, bool) {
 	}
 	sm := state.NewStateMap()
 	var raw map[string]interface{}
-	if err := json.Unmarshal(bytes, &raw); err != nil {
+	if err := JSONUnmarshal(bytes, &raw); err != nil {
 		return nil, false
 	}
 	for k, v := range raw {
@@ -431,16 +565,39 @@ var globalClientStateStore = NewClientStateStore(store.NewMemoryStorage())
 
 // InitStores updates the global stores to use the provided storage backend.
 func InitStores(storage store.Storage) {
-	globalSessionStore = NewSessionStore(storage)
-	globalClientStateStore = NewClientStateStore(storage)
+	InitStateStores(storage)
 	globalConnRateLimiter.SetStorage(storage)
 	globalRemoteActionRateLimiter.SetStorage(storage)
 }
 
+// InitStateStores updates only the global session and client state stores,
+// e.g. to keep them in a store.Encrypted Storage while rate limits stay in
+// the plain one passed to InitStores.
+func InitStateStores(storage store.Storage) {
+	prev := globalClientStateStore
+	affinity := globalSessionStore.affinity
+	globalSessionStore = NewSessionStore(storage)
+	globalSessionStore.affinity = affinity
+	globalClientStateStore = NewClientStateStore(storage)
+	globalClientStateStore.filter = prev.filter
+	if _, ok := storage.(store.SetStorage); ok {
+		globalClientStateStore.retention = prev.retention
+	}
+}
+
+// SetStatePersistenceFilter restricts which state keys the global client state
+// store persists, so ephemeral UI state is not written on every save.
+func SetStatePersistenceFilter(filter *StateKeyFilter) {
+	globalClientStateStore.SetKeyFilter(filter)
+}
+
 // WSClient represents a connected WebSocket client.
 type WSClient struct {
 	ID        string
 	SessionID string
+	// RequestID is the ID of the page render that opened the connection, as
+	// sent by the runtime, or else of the upgrade request.
+	RequestID string
 	Conn      *websocket.Conn
 	Send      chan []byte
 	State     *state.StateMap
@@ -458,6 +615,9 @@ type WSClient struct {
 	actionMu         sync.Mutex
 	actionTokens     float64
 	actionLastRefill time.Time
+	// actionsRunning counts running calls per action with ActionOptions,
+	// guarded by actionMu.
+	actionsRunning map[string]int
 	// transport
 	format string
 	// Custom serializer/deserializer from config
@@ -465,6 +625,56 @@ type WSClient struct {
 	deserializer func([]byte, interface{}) error
 	// Topic-based subscriptions for performance (PERF-02)
 	topics map[string]bool
+	// authorizeTopic approves client "subscribe" messages (nil rejects them)
+	authorizeTopic TopicAuthorizer
+	// keyPrefixes limits global state syncs to matching keys (nil = all keys)
+	keysMu      sync.Mutex
+	keyPrefixes atomic.Pointer[[]string]
+	// Sync coalescing: pending patch flushed every coalesceInterval
+	coalesceInterval time.Duration
+	coalesceMu       sync.Mutex
+	coalesceTimer    *time.Timer
+	pendingPatch     map[string]interface{}
+	pendingVersions  map[string]uint64
+	pendingCount     int
+	pendingSeq       uint64
+	// Multi-tab conflict resolution
+	versions  *versionTracker
+	conflicts *ConflictConfig
+	// hub gives access to shared CRDT keys
+	hub *WSHub
+	// sessionToken is the gospa_session cookie the connection was opened
+	// with, whose HTTP calls its "cancel" frames may cancel.
+	sessionToken string
+	// calls tracks the running ContextActionHandler calls.
+	calls callRegistry
+	// resume records session changes for reconnect replay (nil = disabled)
+	resume *ResumeLog
+	// quota bounds the state created by "update" messages (nil = none)
+	quota *stateQuota
+	// resync marks a connection whose session token another process issued
+	resync bool
+	// unsubRemote cancels the hub's subscription to this client's channel
+	unsubRemote store.Unsubscribe
+	// Heartbeat timeouts, see WebSocketConfig
+	pongWait   time.Duration
+	pingPeriod time.Duration
+	writeWait  time.Duration
+	// writingSince is when the in-flight write started (UnixNano), 0 if idle.
+	writingSince atomic.Int64
+	// lastSeen is when the peer last sent a frame or pong (UnixNano), 0 until
+	// ReadPump starts.
+	lastSeen atomic.Int64
+	// onDisconnect runs once, from the handler or the hub reaper.
+	onDisconnect   func(*WSClient)
+	disconnectOnce sync.Once
+	// onAction observes handled and rejected "action" messages.
+	onAction func(*WSClient, ActionEvent)
+	// onError observes read and write failures that end the connection.
+	// readDone is set once ReadPump stops, after which write failures are
+	// expected and not reported.
+	onError  func(*WSClient, error)
+	readDone atomic.Bool
 }
 
 // WSMessage represents a WebSocket message.
@@ -482,28 +692,59 @@ type WSMessage struct {
 type WSStateUpdate struct {
 	Key   string      `json:"key" msgpack:"key"`
 	Value interface{} `json:"value" msgpack:"value"`
+	// Version is the key version the client based this update on (0 = unversioned).
+	Version uint64 `json:"version,omitempty" msgpack:"version,omitempty"`
+	// Timestamp is the client's write time in Unix milliseconds, used for last-writer-wins.
+	Timestamp int64 `json:"ts,omitempty" msgpack:"ts,omitempty"`
 }
 
 // WSHub maintains the set of active clients and broadcasts messages.
 type WSHub struct {
-	Clients          map[string]*WSClient
-	ClientsBySession map[string]map[string]*WSClient // SessionID -> {ClientID -> *WSClient}
-	ClientsByTopic   map[string]map[string]*WSClient // Topic -> {ClientID -> *WSClient}
-	Register         chan *WSClient
-	Unregister       chan *WSClient
-	Broadcast        chan []byte
-	mu               sync.RWMutex
-	pubsub           store.PubSub
-	stop             chan struct{}
+	ClientsByTopic map[string]map[string]*WSClient // Topic -> {ClientID -> *WSClient}
+	// Register and Unregister are serviced by Run. The WebSocket handler
+	// registers clients directly, so they are only needed by custom handlers.
+	Register   chan *WSClient
+	Unregister chan *WSClient
+	Broadcast  chan []byte
+	// mu guards ClientsByTopic and client topic sets. Clients and sessions
+	// live in shards, each with its own lock.
+	mu     sync.RWMutex
+	pubsub store.PubSub
+	stop   chan struct{}
 	// stopOnce ensures Close() is idempotent and never panics on double-call.
 	stopOnce sync.Once
 	// workerPool is a set of channels for parallel message delivery
 	jobQueue chan broadcastJob
+	// shards partition the client registry and session index
+	shards []*hubShard
+	// versions orders concurrent updates from connections sharing a session
+	versions *versionTracker
+	// crdts holds collaborative keys registered with ShareCRDT
+	crdts crdtRegistry
+	// lists holds the sources registered with RegisterListSource
+	lists listRegistry
+	// calls tracks HTTP calls that connections of the same session can cancel
+	calls callRegistry
+	// retries queues messages whose publish failed for retryLoop
+	retries chan pendingPublish
+	// failures reports messages given up after the retries
+	failures publishFailures
+	// quotaRejected and quotaEvicted count StateQuota enforcement
+	quotaRejected atomic.Uint64
+	quotaEvicted  atomic.Uint64
+	// resyncs counts connections that arrived with another process's session
+	resyncs atomic.Uint64
+	// subs holds the hub's PubSub subscriptions, dropped by Close
+	subs hubSubscriptions
+	// clientPattern is set when one pattern subscription serves every client
+	// channel instead of a subscription per client
+	clientPattern bool
 }
 
 type broadcastJob struct {
 	clients []*WSClient
 	message []byte
+	update  *syncUpdate
 }
 
 const (
@@ -519,30 +760,40 @@ func NewWSHub(pubsub store.PubSub) *WSHub {
 		pubsub = store.NewMemoryPubSub()
 	}
 	h := &WSHub{
-		Clients:          make(map[string]*WSClient),
-		ClientsBySession: make(map[string]map[string]*WSClient),
-		ClientsByTopic:   make(map[string]map[string]*WSClient),
-		Register:         make(chan *WSClient),
-		Unregister:       make(chan *WSClient),
-		Broadcast:        make(chan []byte, 256),
-		pubsub:           pubsub,
-		stop:             make(chan struct{}),
-		jobQueue:         make(chan broadcastJob, broadcastJobQueueSize),
+		ClientsByTopic: make(map[string]map[string]*WSClient),
+		Register:       make(chan *WSClient),
+		Unregister:     make(chan *WSClient),
+		Broadcast:      make(chan []byte, 256),
+		pubsub:         pubsub,
+		stop:           make(chan struct{}),
+		jobQueue:       make(chan broadcastJob, broadcastJobQueueSize),
+		versions:       newVersionTracker(),
+		crdts: crdtRegistry{
+			entries: make(map[string]state.CRDT),
+			unsubs:  make(map[string]state.Unsubscribe),
+		},
+		lists:   listRegistry{sources: make(map[string]*ListSource)},
+		retries: make(chan pendingPublish, publishRetryQueueSize),
 	}
 
 	// Start broadcast workers
 	for i := 0; i < broadcastWorkerCount; i++ {
 		go h.broadcastWorker()
 	}
+	h.shards = newHubShards(h.stop)
+	go h.reapLoop()
+	go h.retryLoop()
+
+	h.subscribeClientPattern()
 
 	// Subscribe to a global broadcast channel for state syncing across processes
-	_, _ = h.pubsub.Subscribe(context.Background(), "gospa:broadcast", func(message []byte) {
+	h.keepSubscription(h.pubsub.Subscribe(context.Background(), "gospa:broadcast", func(message []byte) {
 		var msgData map[string]interface{}
 		var sessionID string
 		var topic string
 
 		// Best effort parse to restrict session/topic scope
-		if err := json.Unmarshal(message, &msgData); err == nil {
+		if err := JSONUnmarshal(message, &msgData); err == nil {
 			if sid, ok := msgData["_sessionID"].(string); ok {
 				sessionID = sid
 			}
@@ -551,32 +802,27 @@ func NewWSHub(pubsub store.PubSub) *WSHub {
 			}
 		}
 
-		h.mu.RLock()
+		if topic == "" && sessionID == "" {
+			// Full broadcast: each shard delivers to its own clients.
+			update, _ := parseSyncMessage(message)
+			h.fanOut(shardJob{message: message, update: update})
+			return
+		}
+
 		var targets []*WSClient
-		switch {
-		case topic != "":
+		if topic != "" {
 			// PERF-02: Topic-based O(1) lookup
+			h.mu.RLock()
 			if clients, ok := h.ClientsByTopic[topic]; ok {
 				targets = make([]*WSClient, 0, len(clients))
 				for _, client := range clients {
 					targets = append(targets, client)
 				}
 			}
-		case sessionID != "":
-			if clients, ok := h.ClientsBySession[sessionID]; ok {
-				targets = make([]*WSClient, 0, len(clients))
-				for _, client := range clients {
-					targets = append(targets, client)
-				}
-			}
-		default:
-			// Fallback: full broadcast (avoid if possible)
-			targets = make([]*WSClient, 0, len(h.Clients))
-			for _, client := range h.Clients {
-				targets = append(targets, client)
-			}
+			h.mu.RUnlock()
+		} else {
+			targets = h.sessionClients(sessionID)
 		}
-		h.mu.RUnlock()
 
 		if len(targets) == 0 {
 			return
@@ -584,7 +830,7 @@ func NewWSHub(pubsub store.PubSub) *WSHub {
 
 		// Parallelize delivery across workers
 		h.dispatchBroadcast(targets, message)
-	})
+	}))
 
 	return h
 }
@@ -594,65 +840,15 @@ func (h *WSHub) Run() {
 	for {
 		select {
 		case client := <-h.Register:
-			var oldClientToClose *WSClient
-			h.mu.Lock()
-			if oldClient, ok := h.Clients[client.ID]; ok {
-				// Cleanup existing indexing
-				if oldClient.SessionID != "" {
-					if clients, ok := h.ClientsBySession[oldClient.SessionID]; ok {
-						delete(clients, oldClient.ID)
-						if len(clients) == 0 {
-							delete(h.ClientsBySession, oldClient.SessionID)
-						}
-					}
-				}
-				oldClientToClose = oldClient
-			}
-
-			h.Clients[client.ID] = client
-			if client.SessionID != "" {
-				if h.ClientsBySession[client.SessionID] == nil {
-					h.ClientsBySession[client.SessionID] = make(map[string]*WSClient)
-				}
-				h.ClientsBySession[client.SessionID][client.ID] = client
-			}
-			h.mu.Unlock()
-			if oldClientToClose != nil {
-				oldClientToClose.Close()
-			}
-			slog.Default().Info("client connected", "id", client.ID)
+			h.register(client)
 
 		case client := <-h.Unregister:
-			h.mu.Lock()
-			if existing, ok := h.Clients[client.ID]; ok && existing == client {
-				delete(h.Clients, client.ID)
-				if client.SessionID != "" {
-					if clients, ok := h.ClientsBySession[client.SessionID]; ok {
-						delete(clients, client.ID)
-						if len(clients) == 0 {
-							delete(h.ClientsBySession, client.SessionID)
-						}
-					}
-				}
-				// Cleanup topic-based indexing (PERF-02)
-				for topic := range client.topics {
-					if clients, ok := h.ClientsByTopic[topic]; ok {
-						delete(clients, client.ID)
-						if len(clients) == 0 {
-							delete(h.ClientsByTopic, topic)
-						}
-					}
-				}
-				// Use guarded Close() to prevent double-close panics
-				client.Close()
-			}
-			h.mu.Unlock()
-			slog.Default().Info("client disconnected", "id", client.ID)
+			h.unregister(client)
 
 		case message := <-h.Broadcast:
 			// Instead of directly sending to local clients, publish to the PubSub system.
 			// The PubSub subscription handler will broadcast it locally.
-			_ = h.pubsub.Publish(context.Background(), "gospa:broadcast", message)
+			_ = h.publish("gospa:broadcast", message)
 		case <-h.stop:
 			close(h.jobQueue)
 			return
@@ -663,11 +859,7 @@ func (h *WSHub) Run() {
 func (h *WSHub) broadcastWorker() {
 	for job := range h.jobQueue {
 		for _, client := range job.clients {
-			select {
-			case client.Send <- job.message:
-			default:
-				// Client buffer full, skip to maintain real-time pressure
-			}
+			client.deliver(job.message, job.update)
 		}
 	}
 }
@@ -677,24 +869,23 @@ func (h *WSHub) broadcastWorker() {
 // delivery without re-parsing on the receiving end.
 func (h *WSHub) BroadcastToTopic(topic string, message []byte) {
 	var msgData map[string]interface{}
-	if err := json.Unmarshal(message, &msgData); err == nil {
+	if err := JSONUnmarshal(message, &msgData); err == nil {
 		msgData["_topic"] = topic
-		if updated, err := json.Marshal(msgData); err == nil {
+		if updated, err := JSONMarshal(msgData); err == nil {
 			message = updated
 		}
 	}
-	_ = h.pubsub.Publish(context.Background(), "gospa:broadcast", message)
+	_ = h.publish("gospa:broadcast", message)
 }
 
 // Subscribe adds a client to a topic.
 func (h *WSHub) Subscribe(topic string, clientID string) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	client, ok := h.Clients[clientID]
+	client, ok := h.GetClient(clientID)
 	if !ok {
 		return
 	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	if h.ClientsByTopic[topic] == nil {
 		h.ClientsByTopic[topic] = make(map[string]*WSClient)
@@ -705,13 +896,12 @@ func (h *WSHub) Subscribe(topic string, clientID string) {
 
 // Unsubscribe removes a client from a topic.
 func (h *WSHub) Unsubscribe(topic string, clientID string) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	client, ok := h.Clients[clientID]
+	client, ok := h.GetClient(clientID)
 	if !ok {
 		return
 	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	if clients, ok := h.ClientsByTopic[topic]; ok {
 		delete(clients, clientID)
@@ -727,6 +917,9 @@ func (h *WSHub) dispatchBroadcast(clients []*WSClient, message []byte) {
 		return
 	}
 
+	// Parse sync updates once so coalescing clients don't each decode the frame
+	update, _ := parseSyncMessage(message)
+
 	// Split clients into chunks and dispatch to workers
 	chunkSize := (len(clients) + broadcastWorkerCount - 1) / broadcastWorkerCount
 	if chunkSize < 10 {
@@ -739,80 +932,89 @@ func (h *WSHub) dispatchBroadcast(clients []*WSClient, message []byte) {
 			end = len(clients)
 		}
 		select {
-		case h.jobQueue <- broadcastJob{clients: clients[i:end], message: message}:
+		case h.jobQueue <- broadcastJob{clients: clients[i:end], message: message, update: update}:
 		default:
 			// Queue full: bounded fallback in caller goroutine (no goroutine burst).
-			h.deliverChunkNonBlocking(clients[i:end], message)
+			h.deliverChunkNonBlocking(clients[i:end], message, update)
 		}
 	}
 }
 
-func (h *WSHub) deliverChunkNonBlocking(clients []*WSClient, message []byte) {
+func (h *WSHub) deliverChunkNonBlocking(clients []*WSClient, message []byte, update *syncUpdate) {
 	for _, client := range clients {
-		select {
-		case client.Send <- message:
-		default:
-		}
+		client.deliver(message, update)
 	}
 }
 
-// Close explicitly stops the WSHub loop.
+// Close explicitly stops the WSHub loop and drops its PubSub subscriptions.
 // It is safe to call Close multiple times.
 func (h *WSHub) Close() {
 	h.stopOnce.Do(func() {
 		close(h.stop)
+		h.subs.close()
 	})
 }
 
-// BroadcastTo broadcasts a message to specific clients.
-func (h *WSHub) BroadcastTo(clientIDs []string, message []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// Drain closes every local client with a 1012 (service restart) close frame so
+// browsers reconnect, to another instance or a replacement process, and
+// resume from their cursor. It returns the number of clients closed.
+func (h *WSHub) Drain() int {
+	var clients []*WSClient
+	for _, s := range h.shards {
+		s.rlock()
+		for _, client := range s.clients {
+			clients = append(clients, client)
+		}
+		s.mu.RUnlock()
+	}
+	for _, client := range clients {
+		client.CloseWithCode(websocket.CloseServiceRestart, "server restarting")
+	}
+	return len(clients)
+}
 
+// BroadcastTo broadcasts a message to specific clients. Clients connected to
+// another process sharing the hub's PubSub are reached through their channel.
+func (h *WSHub) BroadcastTo(clientIDs []string, message []byte) {
 	for _, id := range clientIDs {
-		if client, ok := h.Clients[id]; ok {
-			select {
-			case client.Send <- message:
-			default:
-				// Client buffer full, skip
-			}
+		if client, ok := h.GetClient(id); ok {
+			client.trySend(message)
+			continue
 		}
+		// Not connected here: route through the client's PubSub channel
+		_ = h.publishToClient(id, clientEnvelope{Frame: message})
 	}
 }
 
 // BroadcastExcept broadcasts to all clients except the specified one.
+// Delivery is asynchronous, one goroutine per client shard.
 func (h *WSHub) BroadcastExcept(exceptID string, message []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for id, client := range h.Clients {
-		if id != exceptID {
-			select {
-			case client.Send <- message:
-			default:
-				// Client buffer full, skip
-			}
-		}
-	}
+	h.fanOut(shardJob{message: message, exceptID: exceptID, direct: true})
 }
 
 // GetClient retrieves a client by ID.
 func (h *WSHub) GetClient(id string) (*WSClient, bool) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	client, ok := h.Clients[id]
+	shard := h.shardFor(id)
+	shard.rlock()
+	defer shard.mu.RUnlock()
+	client, ok := shard.clients[id]
 	return client, ok
 }
 
 // ClientCount returns the number of connected clients.
 func (h *WSHub) ClientCount() int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return len(h.Clients)
+	n := 0
+	for _, s := range h.shards {
+		s.rlock()
+		n += len(s.clients)
+		s.mu.RUnlock()
+	}
+	return n
 }
 
 // NewWSClient creates a new WebSocket client.
 func NewWSClient(id string, conn *websocket.Conn, config WebSocketConfig) *WSClient {
+	pongWait, pingPeriod, writeWait := config.heartbeat()
 	return &WSClient{
 		ID:               id,
 		Conn:             conn,
@@ -829,6 +1031,16 @@ func NewWSClient(id string, conn *websocket.Conn, config WebSocketConfig) *WSCli
 		serializer:       config.Serializer,
 		deserializer:     config.Deserializer,
 		topics:           make(map[string]bool),
+		authorizeTopic:   config.AuthorizeTopic,
+		coalesceInterval: config.CoalesceInterval,
+		pongWait:         pongWait,
+		pingPeriod:       pingPeriod,
+		writeWait:        writeWait,
+		onDisconnect:     config.OnDisconnect,
+		onAction:         config.OnAction,
+		onError:          config.OnError,
+		resume:           config.Resume,
+		quota:            newStateQuota(config.StateQuota),
 	}
 }
 
@@ -868,57 +1080,71 @@ func validateJSONDepth(data []byte, maxDepth int) error {
 // ReadPump pumps messages from the WebSocket connection to the hub.
 func (c *WSClient) ReadPump(hub *WSHub, onMessage func(*WSClient, WSMessage)) {
 	defer func() {
-		hub.Unregister <- c
+		hub.unregister(c)
 		c.Close()
 	}()
 
 	// Limit inbound message size to prevent DoS attacks
 	c.Conn.SetReadLimit(c.maxMessageSize)
-	_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.markSeen()
+	_ = c.Conn.SetReadDeadline(time.Now().Add(c.pongWait))
 	c.Conn.SetPongHandler(func(string) error {
-		_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.markSeen()
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.pongWait))
 		return nil
 	})
 
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
+			c.readDone.Store(true)
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure, websocket.CloseAbnormalClosure) {
-				slog.Default().Warn("ws disconnect", "client", c.ID, "err", err)
+				slog.Default().Warn("ws disconnect", "client", c.ID, "request_id", c.RequestID, "err", err)
+				c.reportError(err)
 			}
 			break
 		}
 
 		// Reset read deadline on every message received to keep the connection alive
-		_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.markSeen()
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.pongWait))
 
-		// Validate JSON nesting depth to prevent stack overflow attacks
-		if c.format != "msgpack" {
-			if err := validateJSONDepth(message, maxJSONDepth); err != nil {
-				c.SendError("JSON nesting too deep")
-				continue
-			}
+		if msg, ok := c.decodeMessage(message); ok {
+			onMessage(c, msg)
 		}
+	}
+}
 
-		var msg WSMessage
-		if err := c.Unmarshal(message, &msg); err != nil {
-			c.SendError("Invalid message format")
-			continue
+// decodeMessage validates and decodes an inbound message. Invalid messages
+// are answered with an error and reported as not ok.
+func (c *WSClient) decodeMessage(message []byte) (WSMessage, bool) {
+	var msg WSMessage
+	// Validate JSON nesting depth to prevent stack overflow attacks
+	if c.format != "msgpack" {
+		if err := validateJSONDepth(message, maxJSONDepth); err != nil {
+			c.SendErrorCode(ErrorCodeInvalidPayload, "JSON nesting too deep")
+			return msg, false
 		}
+	}
 
-		// Sanitize field lengths to prevent injection via long strings
-		if len(msg.Action) > maxActionNameLen {
-			c.SendError("Action name too long")
-			continue
-		}
+	if err := c.Unmarshal(message, &msg); err != nil {
+		c.SendErrorCode(ErrorCodeInvalidPayload, "Invalid message format")
+		return msg, false
+	}
 
-		onMessage(c, msg)
+	// Sanitize field lengths to prevent injection via long strings
+	if len(msg.Action) > maxActionNameLen {
+		c.SendErrorCode(ErrorCodeInvalidPayload, "Action name too long")
+		return msg, false
 	}
+
+	// Enforce payload schemas registered for the type or action
+	return msg, c.checkSchema(&msg)
 }
 
 // WritePump pumps messages from the hub to the WebSocket connection.
 func (c *WSClient) WritePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.Close()
@@ -936,10 +1162,13 @@ func (c *WSClient) WritePump() {
 			if c.format == "msgpack" {
 				messageType = websocket.BinaryMessage
 			}
-			err := c.Conn.WriteMessage(messageType, message)
+			err := c.writeFrame(messageType, message)
 			c.mu.Unlock()
 
 			if err != nil {
+				if !c.readDone.Load() {
+					c.reportError(err)
+				}
 				return
 			}
 		case <-ticker.C:
@@ -948,16 +1177,49 @@ func (c *WSClient) WritePump() {
 				c.mu.Unlock()
 				return
 			}
-			err := c.Conn.WriteMessage(websocket.PingMessage, nil)
+			err := c.writeFrame(websocket.PingMessage, nil)
 			c.mu.Unlock()
 
 			if err != nil {
+				if !c.readDone.Load() {
+					c.reportError(err)
+				}
 				return
 			}
 		}
 	}
 }
 
+// writeFrame writes one frame under the write deadline and records that a
+// write is in flight, so the hub reaper can spot a WritePump stuck past it.
+// The caller holds c.mu.
+func (c *WSClient) writeFrame(messageType int, data []byte) error {
+	c.writingSince.Store(time.Now().UnixNano())
+	defer c.writingSince.Store(0)
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+func (c *WSClient) markSeen() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// notifyDisconnect runs the OnDisconnect hook at most once.
+func (c *WSClient) notifyDisconnect() {
+	c.disconnectOnce.Do(func() {
+		if c.onDisconnect != nil {
+			c.onDisconnect(c)
+		}
+	})
+}
+
+// reportError runs the OnError hook for a read or write failure.
+func (c *WSClient) reportError(err error) {
+	if c.onError != nil {
+		c.onError(c, err)
+	}
+}
+
 // Marshal marshals a value using the client's configured format.
 func (c *WSClient) Marshal(v interface{}) ([]byte, error) {
 	if c.serializer != nil {
@@ -966,7 +1228,7 @@ func (c *WSClient) Marshal(v interface{}) ([]byte, error) {
 	if c.format == "msgpack" {
 		return msgpack.Marshal(v)
 	}
-	return json.Marshal(v)
+	return JSONMarshal(v)
 }
 
 // Unmarshal unmarshals a value using the client's configured format.
@@ -981,7 +1243,7 @@ func (c *WSClient) Unmarshal(data []byte, v interface{}) error {
 		}
 		return msgpack.Unmarshal(data, v)
 	}
-	return json.Unmarshal(data, v)
+	return JSONUnmarshal(data, v)
 }
 
 // isSafeMsgpackTarget returns true if the target type is a known safe struct type
@@ -1022,12 +1284,14 @@ func (c *WSClient) SendJSON(v interface{}) error {
 	return nil
 }
 
-// SendError sends an error message to the client.
+// SendError sends an error message to the client with code BAD_REQUEST.
 func (c *WSClient) SendError(message string) {
-	_ = c.SendJSON(map[string]interface{}{
-		"type":  "error",
-		"error": message,
-	})
+	c.SendErrorCode(ErrorCodeBadRequest, message)
+}
+
+// SendErrorCode sends an error message with a stable code to the client.
+func (c *WSClient) SendErrorCode(code ErrorCode, message string) {
+	_ = c.SendJSON(wsError(code, message))
 }
 
 // SendState sends the current state to the client.
@@ -1081,7 +1345,7 @@ func (c *WSClient) SendState() {
 		}
 	}
 	if err != nil {
-		c.SendError("Failed to serialize state")
+		c.SendErrorCode(ErrorCodeInternal, "Failed to serialize state")
 		return
 	}
 	c.sendEncodedPayload(map[string]interface{}{
@@ -1092,6 +1356,9 @@ func (c *WSClient) SendState() {
 
 // SendInitWithSession sends the initial state with session info for HTTP state sync.
 func (c *WSClient) SendInitWithSession() {
+	// Resume cursor the client sends back when it reconnects. It is read before
+	// the snapshot so a concurrent change is replayed rather than skipped.
+	epoch, seq := c.resume.Cursor(c.SessionID)
 	stateMap := c.State.ToMap()
 	if c.stateDiffing {
 		c.lastSentStateMu.Lock()
@@ -1117,14 +1384,25 @@ func (c *WSClient) SendInitWithSession() {
 		}
 	}
 	if err != nil {
-		c.SendError("Failed to serialize state")
+		c.SendErrorCode(ErrorCodeInternal, "Failed to serialize state")
 		return
 	}
-	c.sendEncodedPayload(map[string]interface{}{
+	msg := map[string]interface{}{
 		"type":     "init",
 		"state":    stateData,
 		"clientId": c.SessionID,
-	})
+	}
+	if c.RequestID != "" {
+		msg["requestId"] = c.RequestID
+	}
+	if c.resync {
+		msg["resync"] = true
+	}
+	if epoch != "" {
+		msg["epoch"] = epoch
+		msg["seq"] = seq
+	}
+	c.sendEncodedPayload(msg)
 }
 
 // sendEncodedPayload marshals msg and optionally gzip-compresses it before
@@ -1133,17 +1411,17 @@ func (c *WSClient) sendEncodedPayload(payload interface{}) {
 	if c.compress {
 		data, err := c.Marshal(payload)
 		if err != nil {
-			c.SendError(fmt.Sprintf("state encode error: %v", err))
+			c.SendErrorCode(ErrorCodeInternal, fmt.Sprintf("state encode error: %v", err))
 			return
 		}
-		compressed, err := compressBytes(data)
+		encoded, err := compressToBase64(data)
 		if err != nil {
-			c.SendError(fmt.Sprintf("state compress error: %v", err))
+			c.SendErrorCode(ErrorCodeInternal, fmt.Sprintf("state compress error: %v", err))
 			return
 		}
 		_ = c.SendJSON(map[string]interface{}{
 			"type":       "compressed",
-			"data":       base64.StdEncoding.EncodeToString(compressed),
+			"data":       encoded,
 			"compressed": true,
 		})
 		return
@@ -1151,17 +1429,23 @@ func (c *WSClient) sendEncodedPayload(payload interface{}) {
 	_ = c.SendJSON(payload)
 }
 
-// compressBytes gzip-compresses data and returns the compressed bytes.
-func compressBytes(data []byte) ([]byte, error) {
-	var buf bytes.Buffer
-	gw := gzip.NewWriter(&buf)
+// compressToBase64 gzip-compresses data and returns it base64-encoded. The
+// gzip writer and output buffer are pooled, since CompressState compresses
+// every outbound state message.
+func compressToBase64(data []byte) (string, error) {
+	buf := acquireCompressBuffer()
+	defer releaseCompressBuffer(buf)
+	gw := defaultGzipWriterPool.Get().(*gzip.Writer)
+	defer defaultGzipWriterPool.Put(gw)
+
+	gw.Reset(buf)
 	if _, err := gw.Write(data); err != nil {
-		return nil, err
+		return "", err
 	}
 	if err := gw.Close(); err != nil {
-		return nil, err
+		return "", err
 	}
-	return buf.Bytes(), nil
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
 }
 
 // computeStateDiff returns only the keys where newState differs from prevState,
@@ -1191,16 +1475,14 @@ func deepEqualDepth(a, b interface{}, depth int) bool {
 	if depth > maxDeepEqualDepth {
 		return false
 	}
-	if a == b {
-		return true
-	}
 
 	// Handle nil cases
 	if a == nil || b == nil {
 		return a == b
 	}
 
-	// Type check - different types can't be equal
+	// Type check - different types can't be equal. Comparing the interfaces
+	// with == here would panic for maps and slices.
 	typeA, typeB := reflect.TypeOf(a), reflect.TypeOf(b)
 	if typeA != typeB {
 		return false
@@ -1211,6 +1493,11 @@ func deepEqualDepth(a, b interface{}, depth int) bool {
 	case string:
 		bv, ok := b.(string)
 		return ok && av == bv
+	case json.Number:
+		// Compared as literals: "1" and "1.0" count as a change, which only
+		// costs a redundant patch entry.
+		bv, ok := b.(json.Number)
+		return ok && av == bv
 	case int:
 		bv, ok := b.(int)
 		return ok && av == bv
@@ -1322,11 +1609,36 @@ func deepEqualDepth(a, b interface{}, depth int) bool {
 func (c *WSClient) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+// CloseWithCode sends a close frame with code and reason before closing the
+// client, so the browser sees a clean close instead of a dropped socket.
+func (c *WSClient) CloseWithCode(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed && c.Conn != nil {
+		_ = c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(c.writeWait))
+	}
+	c.closeLocked()
+}
 
+// closeLocked closes the client. The caller holds c.mu.
+func (c *WSClient) closeLocked() {
 	if !c.closed {
 		c.closed = true
+		c.coalesceMu.Lock()
+		if c.coalesceTimer != nil {
+			c.coalesceTimer.Stop()
+			c.coalesceTimer = nil
+		}
+		c.pendingPatch = nil
+		c.coalesceMu.Unlock()
 		close(c.Send)
-		_ = c.Conn.Close()
+		if c.Conn != nil {
+			_ = c.Conn.Close()
+		}
+		c.calls.cancelAll("")
 	}
 }
 
@@ -1338,8 +1650,15 @@ type WebSocketConfig struct {
 	OnConnect func(*WSClient)
 	// OnDisconnect is called when a client disconnects.
 	OnDisconnect func(*WSClient)
+	// OnError is called when reading from or writing to a client fails,
+	// e.g. an abnormal close or a timed-out write, before it disconnects.
+	// Normal closes are not errors.
+	OnError func(*WSClient, error)
 	// OnMessage is called when a message is received.
 	OnMessage func(*WSClient, WSMessage)
+	// OnAction is called after DefaultMessageHandler handled or rejected an
+	// "action" message, e.g. to audit it.
+	OnAction func(*WSClient, ActionEvent)
 	// GenerateID generates a client ID.
 	GenerateID func() string
 	// CompressState enables gzip compression of outbound state payloads.
@@ -1357,6 +1676,56 @@ type WebSocketConfig struct {
 	SerializationFormat string
 	// WSMaxMessageSize limits the maximum payload size for WebSocket messages.
 	WSMaxMessageSize int
+	// ClientPersistence enables encrypted client-side persistence of selected state keys.
+	ClientPersistence *ClientStatePersistence
+	// DevTools, when set, receives every client state change for the dev panel timeline.
+	DevTools *DevTools
+	// Conflicts configures how concurrent updates from tabs sharing a session are resolved.
+	Conflicts *ConflictConfig
+	// CoalesceInterval merges "sync" broadcasts per client into a single "patch"
+	// frame sent at most once per interval (e.g. 16-50ms). Zero disables coalescing.
+	CoalesceInterval time.Duration
+	// PongWait is how long a connection may go without any frame or pong from
+	// the peer before it is dropped (default 60s).
+	PongWait time.Duration
+	// PingPeriod is how often the server pings the peer. It must be shorter
+	// than PongWait (default 90% of PongWait).
+	PingPeriod time.Duration
+	// WriteWait bounds each write to the peer (default 10s). The hub reaper
+	// force-unregisters clients whose write is stuck for twice this long.
+	WriteWait time.Duration
+	// Resume keeps recent state changes per session so a reconnecting client
+	// can replay what it missed instead of receiving a full snapshot. Nil
+	// disables it; see NewResumeLog.
+	Resume *ResumeLog
+	// AuthorizeTopic approves "subscribe" messages, letting clients join hub
+	// topics for the data they are viewing. Nil rejects all client
+	// subscriptions; topics joined with WSHub.Subscribe are unaffected.
+	AuthorizeTopic TopicAuthorizer
+	// StateQuota bounds the keys and bytes each connection can write with
+	// "update" messages. Nil imposes no limit.
+	StateQuota *StateQuota
+	// Stores are the session and client state stores connections use. Nil
+	// uses the global ones.
+	Stores *StateStores
+}
+
+// heartbeat returns the PongWait, PingPeriod, and WriteWait to use, with
+// defaults applied and PingPeriod kept below PongWait.
+func (config WebSocketConfig) heartbeat() (pongWait, pingPeriod, writeWait time.Duration) {
+	pongWait = config.PongWait
+	if pongWait <= 0 {
+		pongWait = defaultPongWait
+	}
+	pingPeriod = config.PingPeriod
+	if pingPeriod <= 0 || pingPeriod >= pongWait {
+		pingPeriod = (pongWait * 9) / 10
+	}
+	writeWait = config.WriteWait
+	if writeWait <= 0 {
+		writeWait = defaultWriteWait
+	}
+	return pongWait, pingPeriod, writeWait
 }
 
 // DefaultWebSocketConfig returns default WebSocket configuration.
@@ -1389,8 +1758,6 @@ func WebSocketHandler(config WebSocketConfig) fiberpkg.Handler {
 		if config.WSMaxMessageSize > 0 {
 			c.SetReadLimit(int64(config.WSMaxMessageSize))
 		}
-		var sessionID string
-		var restoredState *state.StateMap
 
 		// Session is validated from the HttpOnly gospa_session cookie
 		// sent automatically with the WebSocket handshake.
@@ -1401,6 +1768,7 @@ func WebSocketHandler(config WebSocketConfig) fiberpkg.Handler {
 		// Create client with placeholder session (will be updated after auth)
 		client := NewWSClient(connID, c, config)
 		client.SessionID = "" // Will be set after session validation
+		client.RequestID, _ = c.Locals(requestIDLocal).(string)
 		if config.WSMaxMessageSize > 0 {
 			client.maxMessageSize = int64(config.WSMaxMessageSize)
 		}
@@ -1410,14 +1778,7 @@ func WebSocketHandler(config WebSocketConfig) fiberpkg.Handler {
 		client.serializer = config.Serializer
 		client.deserializer = config.Deserializer
 
-		// Register client with timeout to prevent blocking if hub is slow
-		select {
-		case config.Hub.Register <- client:
-		case <-time.After(5 * time.Second):
-			slog.Default().Warn("ws register timeout", "client", connID)
-			_ = c.Close()
-			return
-		}
+		config.Hub.register(client)
 
 		// Set up read deadline for initial auth message
 		_ = c.SetReadDeadline(time.Now().Add(10 * time.Second))
@@ -1425,157 +1786,234 @@ func WebSocketHandler(config WebSocketConfig) fiberpkg.Handler {
 		// Wait for first message (should be init with session token)
 		_, firstMsg, err := c.ReadMessage()
 		if err != nil {
-			slog.Default().Warn("failed to read initial ws message", "client", connID, "err", err)
-			config.Hub.Unregister <- client
+			slog.Default().Warn("failed to read initial ws message", "client", connID, "request_id", client.RequestID, "err", err)
+			config.Hub.unregister(client)
 			_ = c.Close()
 			return
 		}
 
 		var initMsg WSMessage
 		if err := client.Unmarshal(firstMsg, &initMsg); err != nil {
-			slog.Default().Warn("invalid initial ws message format", "client", connID, "err", err)
-			client.SendError("Invalid initial message format")
-			config.Hub.Unregister <- client
+			slog.Default().Warn("invalid initial ws message format", "client", connID, "request_id", client.RequestID, "err", err)
+			client.SendErrorCode(ErrorCodeInvalidPayload, "Invalid initial message format")
+			config.Hub.unregister(client)
 			_ = c.Close()
 			return
 		}
 
 		// Handle session authentication
 		// 1. Try cookie from middleware locals or direct header (most secure)
-		cookieToken := c.Cookies("gospa_session")
-		if cookieToken == "" {
+		sessionToken := c.Cookies("gospa_session")
+		if sessionToken == "" {
 			// Fallback: check if it was set in locals by middleware
 			if l, ok := c.Locals("gospa.session").(string); ok {
-				cookieToken = l
+				sessionToken = l
 			}
 		}
+		release, err := startSession(config, client, initMsg, sessionToken, c.Cookies(ClientPersistCookieName))
+		if err != nil {
+			client.SendErrorCode(ErrorCodeInternal, "Failed to create session")
+			_ = c.Close()
+			return
+		}
 
-		if cookieToken != "" {
-			if prevSessionID, ok := globalSessionStore.ValidateSession(cookieToken); ok {
-				if savedState, hasState := globalClientStateStore.Get(prevSessionID); hasState {
-					sessionID = prevSessionID
-					restoredState = savedState
-				}
-			}
+		// Reset read deadline for normal operation
+		_ = c.SetReadDeadline(time.Now().Add(client.pongWait))
+
+		// Handle messages
+		onMessage := config.OnMessage
+		if onMessage == nil {
+			onMessage = DefaultMessageHandler
 		}
 
-		// 2. Fallback: Removed for security (token-in-body anti-pattern)
-		// To maintain backward compatibility, we still allow the sessionID to be empty
-		// here, and it will be generated below if cookieToken was missing.
+		// Call init function with the first message received (so it's not lost)
+		onMessage(client, initMsg)
 
-		// If no valid session, generate new session ID
-		if sessionID == "" {
-			sessionID = config.GenerateID()
-			_, err := globalSessionStore.CreateSession(sessionID)
-			if err != nil {
-				slog.Default().Error("failed to create websocket session", "session_id", sessionID, "err", err)
-				client.SendError("Failed to create session")
-				_ = c.Close()
-				return
+		// Start write pump
+		go client.WritePump()
+
+		// Continue with normal read pump
+		client.ReadPump(config.Hub, onMessage)
+		release()
+
+		// Call onDisconnect hook, unless the hub reaper already has
+		client.notifyDisconnect()
+	})
+}
+
+// startSession authenticates client from its first message and the session
+// cookie token, restores or creates its session state, runs the connect hooks
+// and sends the initial state. The returned function saves the final state
+// and stops tracking changes; call it once the client has gone.
+func startSession(config WebSocketConfig, client *WSClient, initMsg WSMessage, sessionToken, persistedCookie string) (func(), error) {
+	if id, ok := initMsg.Data["requestId"].(string); ok && ValidRequestID(id) {
+		client.RequestID = id
+	}
+	client.sessionToken = sessionToken
+	stores := config.stores()
+
+	// Resume the session the cookie token names if its state is still stored
+	var sessionID string
+	var restoredState *state.StateMap
+	if stores.Sessions.Foreign(sessionToken) {
+		// Issued by another process under session affinity: start over
+		// and tell the client to drop what it holds for the old session.
+		client.resync = true
+		config.Hub.resyncs.Add(1)
+		slog.Default().Debug("websocket session from another process, resyncing", "request_id", client.RequestID)
+	} else if sessionToken != "" {
+		if prevSessionID, ok := stores.Sessions.ValidateSession(sessionToken); ok {
+			if savedState, hasState := stores.ClientState.Get(prevSessionID); hasState {
+				sessionID = prevSessionID
+				restoredState = savedState
 			}
 		}
+	}
+
+	// Fallback: Removed for security (token-in-body anti-pattern)
+	// To maintain backward compatibility, we still allow the sessionID to be empty
+	// here, and it will be generated below if sessionToken was missing.
+
+	// If no valid session, generate new session ID
+	if sessionID == "" {
+		sessionID = config.GenerateID()
+		_, err := stores.Sessions.CreateSession(sessionID)
+		if err != nil {
+			slog.Default().Error("failed to create websocket session", "session_id", sessionID, "request_id", client.RequestID, "err", err)
+			return nil, err
+		}
+	}
 
-		// Update client with session ID
-		client.SessionID = sessionID
+	// Update client with session ID and index it for session broadcasts
+	config.Hub.bindSession(client, sessionID)
+	stores.ClientState.Touch(sessionID)
+	client.versions = config.Hub.versions
+	client.conflicts = config.Conflicts
+	client.hub = config.Hub
 
-		// Set up state change handler BEFORE sending initial state
-		// This ensures we don't miss the first state change for new sessions
-		var saveMutex sync.Mutex
-		var saveTimer *time.Timer
+	// Set up state change handler BEFORE sending initial state
+	// This ensures we don't miss the first state change for new sessions
+	var saveMutex sync.Mutex
+	var saveTimer *time.Timer
+	var persistDirty bool
 
-		// Clean up saveTimer and OnChange on disconnect to prevent
-		// the callback from firing after the client is gone
-		defer func() {
-			saveMutex.Lock()
-			if saveTimer != nil {
-				saveTimer.Stop()
-			}
-			saveMutex.Unlock()
-			client.State.OnChange = nil
-		}()
+	// Clean up saveTimer and OnChange on disconnect to prevent
+	// the callback from firing after the client is gone
+	cleanup := func() {
+		saveMutex.Lock()
+		if saveTimer != nil {
+			saveTimer.Stop()
+		}
+		saveMutex.Unlock()
+		client.State.OnChange = nil
+		config.DevTools.ForgetClient(client.ID)
+		stores.ClientState.Touch(sessionID)
+	}
 
-		client.State.OnChange = func(key string, value any) {
-			// Save state to persistent store safely, debounced
+	client.State.OnChange = func(key string, value any) {
+		config.DevTools.LogClientStateChange(client.ID, key, value)
+
+		// Save state to persistent store safely, debounced. Keys excluded by
+		// the persistence filter don't schedule a write.
+		if stores.ClientState.Persists(key) || config.ClientPersistence.Tracks(key) {
 			saveMutex.Lock()
 			if saveTimer != nil {
 				saveTimer.Stop()
 			}
+			if config.ClientPersistence.Tracks(key) {
+				persistDirty = true
+			}
 			saveTimer = time.AfterFunc(100*time.Millisecond, func() {
-				globalClientStateStore.Save(sessionID, client.State)
+				stores.ClientState.Save(sessionID, client.State)
+				saveMutex.Lock()
+				dirty := persistDirty
+				persistDirty = false
+				saveMutex.Unlock()
+				if dirty {
+					sendClientPersistedState(client, config.ClientPersistence)
+				}
 			})
 			saveMutex.Unlock()
-
-			// Parse componentId and local key for Svelte updates
-			componentID := ""
-			localKey := key
-			if dotIdx := strings.Index(key, "."); dotIdx > 0 {
-				componentID = key[:dotIdx]
-				localKey = key[dotIdx+1:]
-			}
-
-			// Broadcast state change to all clients sharing this session ID via pubsub
-			syncMsg := map[string]interface{}{
-				"type":        "sync",
-				"componentId": componentID,
-				"key":         localKey,
-				"value":       value,
-				"_sessionID":  sessionID,
-			}
-			data, err := json.Marshal(syncMsg)
-			if err == nil {
-				_ = config.Hub.pubsub.Publish(context.Background(), "gospa:broadcast", data)
-			}
 		}
 
-		// Restore previous state if available, passing pointer
-		if restoredState != nil {
-			client.State = restoredState
-		} else {
-			// Save initial state for new sessions
-			globalClientStateStore.Save(sessionID, client.State)
+		// Parse componentId and local key for Svelte updates
+		componentID := ""
+		localKey := key
+		if dotIdx := strings.Index(key, "."); dotIdx > 0 {
+			componentID = key[:dotIdx]
+			localKey = key[dotIdx+1:]
 		}
 
-		// Reset read deadline for normal operation
-		_ = c.SetReadDeadline(time.Now().Add(pongWait))
-
-		// Call global connect handlers (for initial state sync)
-		callConnectHandlers(client)
-
-		// Call onConnect hook
-		if config.OnConnect != nil {
-			config.OnConnect(client)
+		// Broadcast state change to all clients sharing this session ID via pubsub
+		syncMsg := map[string]interface{}{
+			"type":        "sync",
+			"componentId": componentID,
+			"key":         localKey,
+			"value":       value,
+			"_sessionID":  sessionID,
 		}
-
-		// Send initial state
-		client.SendInitWithSession()
-
-		// Handle messages
-		onMessage := config.OnMessage
-		if onMessage == nil {
-			onMessage = DefaultMessageHandler
+		version := config.Hub.versions.current(sessionID, key)
+		if version > 0 {
+			syncMsg["version"] = version
 		}
+		if seq := config.Resume.Append(sessionID, key, value, version); seq > 0 {
+			syncMsg["seq"] = seq
+		}
+		data, err := JSONMarshal(syncMsg)
+		if err == nil {
+			_ = config.Hub.publish("gospa:broadcast", data)
+		}
+	}
 
-		// Call init function with the first message received (so it's not lost)
-		onMessage(client, initMsg)
+	// Restore previous state if available, passing pointer
+	if restoredState != nil {
+		restoredState.OnChange = client.State.OnChange
+		client.State = restoredState
+	} else {
+		// Save initial state for new sessions
+		stores.ClientState.Save(sessionID, client.State)
+	}
 
-		// Start write pump
-		go client.WritePump()
+	// Fill in keys persisted client-side (e.g. after Storage eviction)
+	if config.ClientPersistence != nil {
+		blob, _ := initMsg.Data["persisted"].(string)
+		if blob == "" {
+			blob = persistedCookie
+		}
+		if blob != "" {
+			restoreClientPersistedState(client, config.ClientPersistence, blob)
+		}
+	}
 
-		// Continue with normal read pump
-		client.ReadPump(config.Hub, onMessage)
+	// Seed the dev panel timeline with the state the client starts from
+	config.DevTools.TrackClient(client)
 
-		// Save final state before disconnect
-		globalClientStateStore.Save(sessionID, client.State)
+	// Call global connect handlers (for initial state sync)
+	callConnectHandlers(client)
 
-		// Note: We don't remove the session on disconnect so the client can reconnect
-		// Sessions will be cleaned up by the session store when they expire (if TTL is implemented)
-		// globalSessionStore.RemoveClientSessions(clientID)
+	// Call onConnect hook
+	if config.OnConnect != nil {
+		config.OnConnect(client)
+	}
 
-		// Call onDisconnect hook
-		if config.OnDisconnect != nil {
-			config.OnDisconnect(client)
+	// Send initial state, or only what a returning client missed
+	resumed := false
+	if restoredState != nil {
+		if epoch, seq, ok := resumeCursor(initMsg.Data); ok {
+			resumed = client.sendResume(epoch, seq)
 		}
-	})
+	}
+	if !resumed {
+		client.SendInitWithSession()
+	}
+
+	// Note: We don't remove the session on disconnect so the client can reconnect.
+	// Sessions expire after SessionTTL, or can be revoked with RevokeClientSessions.
+	return func() {
+		// Save final state before disconnect
+		stores.ClientState.Save(sessionID, client.State)
+		cleanup()
+	}, nil
 }
 
 // DefaultMessageHandler handles incoming WebSocket messages.
@@ -1621,23 +2059,20 @@ func DefaultMessageHandler(client *WSClient, msg WSMessage) {
 				unmarshalErr = client.deserializer(payloadBytes, &update)
 			} else {
 				// Try to re-marshal if it's already a map (JSON case)
-				b, _ := json.Marshal(msg.Payload)
+				b, _ := JSONMarshal(msg.Payload)
 				unmarshalErr = client.deserializer(b, &update)
 			}
 		} else {
 			if payloadIsBytes {
-				unmarshalErr = json.Unmarshal(payloadBytes, &update)
+				unmarshalErr = JSONUnmarshal(payloadBytes, &update)
 			} else {
 				// Already unmarshaled by json into interface{}
-				b, _ := json.Marshal(msg.Payload)
-				unmarshalErr = json.Unmarshal(b, &update)
+				b, _ := JSONMarshal(msg.Payload)
+				unmarshalErr = JSONUnmarshal(b, &update)
 			}
 		}
 		if unmarshalErr != nil {
-			sendResponse(map[string]interface{}{
-				"type":  "error",
-				"error": "Invalid update payload",
-			})
+			sendResponse(wsError(ErrorCodeInvalidPayload, "Invalid update payload"))
 			return
 		}
 
@@ -1647,34 +2082,77 @@ func DefaultMessageHandler(client *WSClient, msg WSMessage) {
 			stateKey = msg.ComponentID + "." + update.Key
 		}
 
+		if client.quota != nil && !client.admitUpdate(stateKey, update.Value, sendResponse) {
+			return
+		}
+
+		// Order the write against other tabs of the same session
+		value := update.Value
+		var version uint64
+		if client.versions != nil {
+			result := client.versions.apply(client.SessionID, stateKey, update, client.conflicts)
+			if !result.accepted {
+				sendResponse(map[string]interface{}{
+					"type":        "sync",
+					"componentId": msg.ComponentID,
+					"key":         update.Key,
+					"value":       result.value,
+					"version":     result.version,
+					"conflict":    true,
+					"success":     false,
+				})
+				return
+			}
+			value = result.value
+			version = result.version
+		}
+
 		// Update state
 		if obs, ok := client.State.Get(stateKey); ok {
 			if settable, isSettable := obs.(state.Settable); isSettable {
-				_ = settable.SetAny(update.Value)
+				_ = settable.SetAny(value)
 			}
 		} else {
-			r := state.NewRune(update.Value)
+			r := state.NewRune(value)
 			client.State.Add(stateKey, r)
 		}
 
 		// Send success to requesting client
-		sendResponse(map[string]interface{}{
+		response := map[string]interface{}{
 			"type":        "sync",
 			"componentId": msg.ComponentID,
 			"key":         update.Key,
-			"value":       update.Value,
+			"value":       value,
 			"success":     true,
-		})
+		}
+		if version > 0 {
+			response["version"] = version
+		}
+		sendResponse(response)
 
 	case "sync":
 		client.SendState()
 
+	case "crdt":
+		handleCRDTMessage(client, msg, sendResponse)
+
+	case "list":
+		handleListMessage(client, msg, sendResponse)
+
+	case "subscribe", "unsubscribe":
+		handleTopicMessage(client, msg, sendResponse)
+
+	case "cancel":
+		handleCancelMessage(client, msg, sendResponse)
+
 	case "ping":
 		sendResponse(map[string]interface{}{
 			"type": "pong",
 		})
 
 	case "action":
+		start := time.Now()
+		action := msg.Action
 		client.actionMu.Lock()
 		now := time.Now()
 		elapsed := now.Sub(client.actionLastRefill).Seconds()
@@ -1686,56 +2164,110 @@ func DefaultMessageHandler(client *WSClient, msg WSMessage) {
 
 		if client.actionTokens < 1.0 {
 			client.actionMu.Unlock()
-			sendResponse(map[string]interface{}{
-				"type":  "error",
-				"error": "Rate limit exceeded",
-			})
+			client.notifyAction(action, msg.Payload, ErrorCodeRateLimited, start)
+			sendResponse(wsError(ErrorCodeRateLimited, "Rate limit exceeded"))
 			return
 		}
 		client.actionTokens -= 1.0
 		client.actionMu.Unlock()
 
-		action := msg.Action
 		if action == "" {
-			sendResponse(map[string]interface{}{
-				"type":  "error",
-				"error": "Action name required",
-			})
+			client.notifyAction(action, msg.Payload, ErrorCodeInvalidPayload, start)
+			sendResponse(wsError(ErrorCodeInvalidPayload, "Action name required"))
 			return
 		}
 
 		// Look for action handlers in the hub or app
-		slog.Default().Debug("ws action received", "action", action, "client", client.ID)
-
-		if handler, ok := GetActionHandler(action); ok {
-			var payload interface{}
-			if b, ok := msg.Payload.([]byte); ok {
-				// If it's a byte slice, it's either RawMessage or direct binary
-				if client.format == "msgpack" {
-					payload = b // Keep as bytes for msgpack (handler might decode it)
-				} else {
-					payload = json.RawMessage(b)
-				}
+		slog.Default().Debug("ws action received", "action", action, "client", client.ID, "request_id", client.RequestID)
+
+		var payload interface{}
+		if b, ok := msg.Payload.([]byte); ok {
+			// If it's a byte slice, it's either RawMessage or direct binary
+			if client.format == "msgpack" {
+				payload = b // Keep as bytes for msgpack (handler might decode it)
 			} else {
-				payload = msg.Payload
+				payload = json.RawMessage(b)
 			}
+		} else {
+			payload = msg.Payload
+		}
+		ctxHandler, isCtx := GetContextActionHandler(action)
+		handler, isPlain := GetActionHandler(action)
+		if !isCtx && !isPlain {
+			client.notifyAction(action, msg.Payload, ErrorCodeActionNotFound, start)
+			sendResponse(wsError(ErrorCodeActionNotFound, "Unknown action: "+action))
+			return
+		}
+		limiter := getActionLimiter(action)
+		release, reply := limiter.acquire(client, action)
+		if reply != nil {
+			client.notifyAction(action, msg.Payload, ErrorCodeLimitExceeded, start)
+			sendResponse(reply)
+			return
+		}
+		notify := func(code ErrorCode) {
+			client.notifyAction(action, msg.Payload, code, start)
+		}
+		switch timeout := limiter.timeout(); {
+		case isCtx:
+			id, _ := reqID.(string)
+			if !ValidRequestID(id) {
+				id = ""
+			}
+			runContextAction(client, ctxHandler, id, payload, timeout, sendResponse, func(code ErrorCode) {
+				release()
+				notify(code)
+			})
+		case timeout > 0:
+			runActionWithTimeout(client, handler, payload, timeout, release, func(code ErrorCode) {
+				notify(code)
+				if code == ErrorCodeTimeout {
+					sendResponse(wsError(ErrorCodeTimeout, "Action timed out"))
+					return
+				}
+				sendResponse(map[string]interface{}{"type": "action_ack"})
+			})
+		default:
 			handler(client, payload)
+			release()
+			notify("")
 			sendResponse(map[string]interface{}{
 				"type": "action_ack",
 			})
-		} else {
-			sendResponse(map[string]interface{}{
-				"type":  "error",
-				"error": "Unknown action: " + action,
-			})
 		}
 
 	default:
-		sendResponse(map[string]interface{}{
-			"type":  "error",
-			"error": "Unknown message type: " + msg.Type,
-		})
+		sendResponse(wsError(ErrorCodeUnknownMessage, "Unknown message type: "+msg.Type))
+	}
+}
+
+// ActionEvent describes an "action" message after DefaultMessageHandler
+// handled or rejected it. See WebSocketConfig.OnAction.
+type ActionEvent struct {
+	Action string
+	// Payload is the message's WSMessage.Payload.
+	Payload interface{}
+	// Code is empty when the handler ran, otherwise why the action was
+	// rejected (ErrorCodeRateLimited, ErrorCodeInvalidPayload or
+	// ErrorCodeActionNotFound), over an ActionOptions limit
+	// (ErrorCodeLimitExceeded) or past its timeout (ErrorCodeTimeout).
+	// ContextActionHandler calls are reported once they return, with the
+	// code sent to the client, e.g. ErrorCodeCanceled.
+	Code     ErrorCode
+	Duration time.Duration
+}
+
+// notifyAction reports an action message to the OnAction hook.
+func (c *WSClient) notifyAction(action string, payload interface{}, code ErrorCode, start time.Time) {
+	if c.onAction == nil {
+		return
 	}
+	c.onAction(c, ActionEvent{
+		Action:   action,
+		Payload:  payload,
+		Code:     code,
+		Duration: time.Since(start),
+	})
 }
 
 // ActionHandler is a function that handles a WebSocket action.
@@ -1793,6 +2325,13 @@ func callConnectHandlers(client *WSClient) {
 
 // WebSocketUpgradeMiddleware enforces per-IP rate limiting before WebSocket upgrade.
 func WebSocketUpgradeMiddleware() fiberpkg.Handler {
+	return WebSocketUpgradeMiddlewareWithLimiter(globalConnRateLimiter)
+}
+
+// WebSocketUpgradeMiddlewareWithLimiter is WebSocketUpgradeMiddleware with
+// its own limiter, for endpoints whose traffic should not count against the
+// global connection limit.
+func WebSocketUpgradeMiddlewareWithLimiter(rl *ConnectionRateLimiter) fiberpkg.Handler {
 	return func(c fiberpkg.Ctx) error {
 		// Check if WebSocket upgrade request
 		if !c.IsWebSocket() {
@@ -1801,11 +2340,9 @@ func WebSocketUpgradeMiddleware() fiberpkg.Handler {
 
 		// SECURITY: Apply per-IP rate limiting for WebSocket connections
 		clientIP := GetIPFromContext(c)
-		if !globalConnRateLimiter.Allow(clientIP) {
+		if !rl.Allow(clientIP) {
 			slog.Default().Warn("ws rate limit exceeded", "ip", clientIP)
-			return c.Status(fiberpkg.StatusTooManyRequests).JSON(fiberpkg.Map{
-				"error": "Rate limit exceeded. Please try again later.",
-			})
+			return c.Status(fiberpkg.StatusTooManyRequests).JSON(ErrorBody(ErrorCodeRateLimited, "Rate limit exceeded. Please try again later."))
 		}
 
 		return c.Next()
@@ -1818,9 +2355,7 @@ func RemoteActionRateLimitMiddleware() fiberpkg.Handler {
 		clientIP := GetIPFromContext(c)
 		if !globalRemoteActionRateLimiter.Allow(clientIP) {
 			slog.Default().Warn("remote action rate limit exceeded", "ip", clientIP)
-			return c.Status(fiberpkg.StatusTooManyRequests).JSON(fiberpkg.Map{
-				"error": "Rate limit exceeded. Please try again later.",
-			})
+			return c.Status(fiberpkg.StatusTooManyRequests).JSON(ErrorBody(ErrorCodeRateLimited, "Rate limit exceeded. Please try again later."))
 		}
 		return c.Next()
 	}
@@ -1832,30 +2367,23 @@ func StateSyncHandler(_ *WSHub) fiberpkg.Handler {
 		sessionToken := c.Get("X-Session-Token")
 
 		if sessionToken == "" {
-			return c.Status(fiberpkg.StatusUnauthorized).JSON(fiberpkg.Map{
-				"error": "Session token required",
-			})
+			return c.Status(fiberpkg.StatusUnauthorized).JSON(ErrorBody(ErrorCodeSessionRequired, "Session token required"))
 		}
 
-		sessionID, ok := globalSessionStore.ValidateSession(sessionToken)
+		stores := requestStateStores(c)
+		sessionID, ok := stores.Sessions.ValidateSession(sessionToken)
 		if !ok {
-			return c.Status(fiberpkg.StatusUnauthorized).JSON(fiberpkg.Map{
-				"error": "Invalid session",
-			})
+			return c.Status(fiberpkg.StatusUnauthorized).JSON(ErrorBody(ErrorCodeInvalidSession, "Invalid session"))
 		}
 
-		stateMap, ok := globalClientStateStore.Get(sessionID)
+		stateMap, ok := stores.ClientState.Get(sessionID)
 		if !ok {
-			return c.Status(fiberpkg.StatusNotFound).JSON(fiberpkg.Map{
-				"error": "Session state not found",
-			})
+			return c.Status(fiberpkg.StatusNotFound).JSON(ErrorBody(ErrorCodeNotFound, "Session state not found"))
 		}
 
 		var update WSStateUpdate
-		if err := json.Unmarshal(c.Body(), &update); err != nil {
-			return c.Status(fiberpkg.StatusBadRequest).JSON(fiberpkg.Map{
-				"error": "Invalid update payload",
-			})
+		if err := JSONUnmarshal(c.Body(), &update); err != nil {
+			return c.Status(fiberpkg.StatusBadRequest).JSON(ErrorBody(ErrorCodeInvalidPayload, "Invalid update payload"))
 		}
 
 		if obs, ok := stateMap.Get(update.Key); ok {
@@ -1873,12 +2401,13 @@ func StateSyncHandler(_ *WSHub) fiberpkg.Handler {
 	}
 }
 
-// BroadcastState broadcasts state to all connected clients.
+// BroadcastState broadcasts state to all connected clients, except those
+// that declared interest only in other keys (see WSClient.AddKeyInterest).
 func BroadcastState(hub *WSHub, key string, value interface{}) error {
 	if hub == nil {
 		return nil
 	}
-	data, err := json.Marshal(map[string]interface{}{
+	data, err := JSONMarshal(map[string]interface{}{
 		"type":  "sync",
 		"key":   key,
 		"value": value,
@@ -1891,6 +2420,25 @@ func BroadcastState(hub *WSHub, key string, value interface{}) error {
 	return nil
 }
 
+// BroadcastStateLocal is BroadcastState for the clients connected to this
+// process only, for state every process produces on its own.
+func BroadcastStateLocal(hub *WSHub, key string, value interface{}) error {
+	if hub == nil {
+		return nil
+	}
+	data, err := JSONMarshal(map[string]interface{}{
+		"type":  "sync",
+		"key":   key,
+		"value": value,
+	})
+	if err != nil {
+		return err
+	}
+	update, _ := parseSyncMessage(data)
+	hub.fanOut(shardJob{message: data, update: update})
+	return nil
+}
+
 // CloseGlobalRateLimiters stops the background cleanup goroutines of the
 // global rate limiter instances to prevent goroutine leaks on shutdown.
 func CloseGlobalRateLimiters() {
@@ -1898,11 +2446,16 @@ func CloseGlobalRateLimiters() {
 	globalRemoteActionRateLimiter.Close()
 }
 
-// SendToClient sends a JSON message to a specific client by ID.
+// SendToClient sends a JSON message to a specific client by ID, on this or any
+// other process sharing the hub's PubSub.
 func SendToClient(hub *WSHub, clientID string, message interface{}) error {
-	client, ok := hub.GetClient(clientID)
-	if !ok {
-		return nil
+	if client, ok := hub.GetClient(clientID); ok {
+		return client.SendJSON(message)
+	}
+	// The client may be connected to another process sharing the hub's PubSub
+	value, err := JSONMarshal(message)
+	if err != nil {
+		return err
 	}
-	return client.SendJSON(message)
+	return hub.publishToClient(clientID, clientEnvelope{Value: value})
 }
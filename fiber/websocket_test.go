@@ -0,0 +1,156 @@
+package fiber
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+func TestDeepEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"equal strings", "a", "a", true},
+		{"different types", 1, int64(1), false},
+		{"json numbers", json.Number("42"), json.Number("42"), true},
+		{"different json numbers", json.Number("42"), json.Number("43"), false},
+		{"json number vs float", json.Number("42"), float64(42), false},
+		{"equal maps", map[string]interface{}{"a": 1, "b": []interface{}{"x"}}, map[string]interface{}{"a": 1, "b": []interface{}{"x"}}, true},
+		{"different maps", map[string]interface{}{"a": 1}, map[string]interface{}{"a": 2}, false},
+		{"typed maps", map[string]int{"a": 1}, map[string]int{"a": 1}, true},
+		{"typed slices", []string{"a"}, []string{"b"}, false},
+		{"nil vs value", nil, "a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deepEqual(tt.a, tt.b); got != tt.want {
+				t.Fatalf("deepEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStateDiffWithMapValues(t *testing.T) {
+	shared := map[string]interface{}{"n": 1}
+	prev := map[string]interface{}{"user": shared, "tags": []string{"a"}, "count": 1}
+	next := map[string]interface{}{"user": shared, "tags": []string{"a", "b"}, "count": 1}
+
+	diff := computeStateDiff(prev, next)
+	if len(diff) != 1 || diff["tags"] == nil {
+		t.Fatalf("expected only tags to differ, got %v", diff)
+	}
+}
+
+func TestCompressToBase64RoundTrip(t *testing.T) {
+	// Compress twice so the second call reuses pooled buffers and writers.
+	for _, in := range [][]byte{bytes.Repeat([]byte(`{"count":1}`), 200), []byte(`{"a":true}`)} {
+		enc, err := compressToBase64(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out, in) {
+			t.Fatalf("round trip mismatch: got %q", out)
+		}
+	}
+}
+
+// kvOnlyStorage hides MemoryStorage's set support to exercise the fallback
+// client session index.
+type kvOnlyStorage struct{ store.Storage }
+
+func TestSessionStoreRemoveClientSessions(t *testing.T) {
+	for name, storage := range map[string]store.Storage{
+		"sets":    store.NewMemoryStorage(),
+		"kv-only": kvOnlyStorage{store.NewMemoryStorage()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSessionStore(storage)
+			a1, _ := s.CreateSession("alice")
+			a2, _ := s.CreateSession("alice")
+			b1, _ := s.CreateSession("bob")
+
+			s.RemoveSession(a2)
+			a3, _ := s.CreateSession("alice")
+
+			n, err := s.RemoveClientSessions("alice")
+			if err != nil || n != 2 {
+				t.Fatalf("RemoveClientSessions = %d, %v; want 2", n, err)
+			}
+			for _, token := range []string{a1, a2, a3} {
+				if _, ok := s.ValidateSession(token); ok {
+					t.Fatalf("token %s still valid after revocation", token)
+				}
+			}
+			if id, ok := s.ValidateSession(b1); !ok || id != "bob" {
+				t.Fatal("other clients' sessions must survive")
+			}
+			if n, _ := s.RemoveClientSessions("alice"); n != 0 {
+				t.Fatalf("second revocation removed %d tokens", n)
+			}
+		})
+	}
+}
+
+func TestDefaultMessageHandlerReportsActions(t *testing.T) {
+	RegisterActionHandler("test.onaction", func(*WSClient, interface{}) {})
+	defer func() {
+		actionMu.Lock()
+		delete(actionHandlers, "test.onaction")
+		actionMu.Unlock()
+	}()
+
+	var events []ActionEvent
+	client := NewWSClient("c1", nil, WebSocketConfig{
+		OnAction: func(_ *WSClient, ev ActionEvent) { events = append(events, ev) },
+	})
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test.onaction", Payload: map[string]interface{}{"id": 1}})
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test.missing"})
+	DefaultMessageHandler(client, WSMessage{Type: "action"})
+
+	want := []struct {
+		action string
+		code   ErrorCode
+	}{
+		{"test.onaction", ""},
+		{"test.missing", ErrorCodeActionNotFound},
+		{"", ErrorCodeInvalidPayload},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		if events[i].Action != w.action || events[i].Code != w.code {
+			t.Errorf("event %d = %q/%q, want %q/%q", i, events[i].Action, events[i].Code, w.action, w.code)
+		}
+	}
+	if events[0].Payload == nil {
+		t.Error("payload not reported")
+	}
+
+	client.actionMu.Lock()
+	client.actionTokens = 0
+	client.actionMu.Unlock()
+	DefaultMessageHandler(client, WSMessage{Type: "action", Action: "test.onaction"})
+	if last := events[len(events)-1]; last.Code != ErrorCodeRateLimited {
+		t.Errorf("rate-limited action code = %q", last.Code)
+	}
+}
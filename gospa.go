@@ -6,14 +6,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/aydenstechdungeon/gospa/analytics"
+	"github.com/aydenstechdungeon/gospa/audit"
 	"github.com/aydenstechdungeon/gospa/embed"
 	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/jobs"
 	"github.com/aydenstechdungeon/gospa/plugin"
 	"github.com/aydenstechdungeon/gospa/routing"
 	"github.com/aydenstechdungeon/gospa/routing/kit"
@@ -78,6 +84,17 @@ type App struct {
 	Hub *fiber.WSHub
 	// StateMap is the global state map.
 	StateMap *state.StateMap
+	// DevTools records client state changes for the dev panel (DevMode with WebSockets only).
+	DevTools *fiber.DevTools
+	// Jobs is the background job queue. Workers start with the server, except
+	// in RequestMode.
+	Jobs *jobs.Queue
+	// Analytics counts page views and WebSocket events when Config.Analytics
+	// is set; nil otherwise.
+	Analytics *analytics.Collector
+	// Audit records remote action and WebSocket action invocations when
+	// Config.Audit is set; nil otherwise.
+	Audit *audit.Auditor
 	// pluginMiddleware stores middleware from runtime plugins.
 	pluginMiddleware []fiberpkg.Handler
 	// pluginTemplateFuncs stores template functions from plugins.
@@ -112,6 +129,11 @@ type App struct {
 	cacheKeyIndex map[string]map[string]struct{}
 	// pprShellBuilding guards against duplicate PPR shell builds under concurrent load.
 	pprShellBuilding sync.Map
+	// seoRobots and seoSitemap cache the files served for Config.SEO.
+	seoRobots  seoFile
+	seoSitemap seoFile
+	// serviceWorker caches the script served for Config.ServiceWorker.
+	serviceWorker serviceWorker
 	// cacheStatsMu protects route and slot cache metrics.
 	cacheStatsMu sync.RWMutex
 	// routeCacheStats tracks cache metrics by route path.
@@ -123,6 +145,61 @@ type App struct {
 	cancel context.CancelFunc
 	// startupErr stores configuration failures that should block server startup.
 	startupErr error
+	// clientPersistence seals client-persisted state when ClientPersistedStateKeys is set.
+	clientPersistence *fiber.ClientStatePersistence
+	// profilesMu protects profiles.
+	profilesMu sync.Mutex
+	// profiles holds recent DevMode request profiles, oldest first.
+	profiles []*RequestProfile
+	// rootPropsOnce guards building rootProps.
+	rootPropsOnce sync.Once
+	// rootProps is the Config-derived part of the root layout props.
+	rootProps *rootPropsTemplate
+	// cacheOrigin identifies this process on the cache invalidation channel.
+	cacheOrigin string
+	// cacheInvalidationUnsub cancels the cache invalidation subscription.
+	cacheInvalidationUnsub store.Unsubscribe
+	// stateStores are the session and client state stores the app's
+	// handlers use, the global ones as set from its Config by New.
+	stateStores *fiber.StateStores
+	// navHooksMu protects navHooks, the handlers registered with OnNavigate.
+	navHooksMu sync.RWMutex
+	navHooks   []func(NavEvent)
+	// renderHooksMu protects renderHooks, the handlers registered with
+	// OnRenderHTML.
+	renderHooksMu sync.RWMutex
+	renderHooks   []func(*routing.Route, []byte) []byte
+	// wsHooksMu protects wsHooks, the WebSocket lifecycle handlers.
+	wsHooksMu sync.RWMutex
+	wsHooks   wsEventHooks
+	// prepareOnce guards Prepare; prepareErr is its result. prepared is set
+	// once Prepare has run.
+	prepareOnce sync.Once
+	prepareErr  error
+	prepared    bool
+	// wsEndpoints are the endpoints added with WebSocket.
+	wsEndpoints []*wsEndpoint
+	// mounts are the apps added with Mount.
+	mounts []*App
+	// hosts maps host names to the apps added with Host; hostHandlers serve
+	// them once Prepare has run.
+	hosts        map[string]*App
+	hostHandlers map[string]fiberpkg.Handler
+	// httpClosers are the net/http and HTTP/3 servers started by RunH2C and
+	// RunHTTP3; httpClosersMu protects it.
+	httpClosersMu sync.Mutex
+	httpClosers   []io.Closer
+	// autoTLSHosts are the domains passed to RunAutoTLS.
+	autoTLSHosts map[string]struct{}
+	// remoteInflight tracks remote action calls that reuse results, so an
+	// identical call waits for the running one; remoteInflightMu protects it.
+	remoteInflightMu sync.Mutex
+	remoteInflight   map[string]chan struct{}
+	// live are the runes added with Live; liveWake wakes the goroutine that
+	// runs them, started by the first Live call. liveMu protects both.
+	liveMu   sync.Mutex
+	live     []*liveRune
+	liveWake chan struct{}
 }
 
 var defaultApp *App
@@ -133,9 +210,6 @@ func New(config Config) *App {
 	applyDefaultConfig(&config)
 	startupErr := validateAndLogConfig(&config)
 
-	fiber.SetConnectionRateLimiter(config.WSConnBurst, config.WSConnRateLimit)
-	state.SetNotificationQueueSize(config.NotificationBufferSize)
-
 	// Load build manifest if available
 	if len(config.BuildManifest) == 0 && config.ManifestPath != "" {
 		if _, err := os.Stat(config.ManifestPath); err == nil {
@@ -151,7 +225,7 @@ func New(config Config) *App {
 
 	if config.Storage == nil {
 		if config.Prefork {
-			config.Logger.Warn("Prefork enabled with in-memory Storage: sessions will NOT be shared between processes")
+			config.Logger.Warn("Prefork enabled with in-memory Storage: sessions will NOT be shared between processes; clients landing on another process get a new session")
 		}
 		config.Storage = store.NewMemoryStorage()
 	}
@@ -162,7 +236,9 @@ func New(config Config) *App {
 		config.PubSub = store.NewMemoryPubSub()
 	}
 
-	fiber.InitStores(config.Storage)
+	if err := applyGlobalSettings(&config); err != nil {
+		startupErr = errors.Join(startupErr, err)
+	}
 
 	var routerSource interface{}
 	if config.RoutesFS != nil {
@@ -176,6 +252,8 @@ func New(config Config) *App {
 		AppName:      config.AppName,
 		ServerHeader: "GoSPA",
 		BodyLimit:    config.MaxRequestBodySize,
+		JSONEncoder:  fiber.JSONMarshal,
+		JSONDecoder:  fiber.JSONUnmarshal,
 	}
 	if config.DevMode {
 		config.Logger.Warn("DevMode is enabled — disable in production")
@@ -188,26 +266,60 @@ func New(config Config) *App {
 			config.Logger.Error("CRITICAL: PublicOrigin must be set in production mode for secure WebSocket and absolute URL generation.")
 		}
 	}
+	mergeFiberConfig(&fiberConfig, config.Fiber)
 	fiberApp := fiberpkg.New(fiberConfig)
 
 	var hub *fiber.WSHub
 	if config.EnableWebSocket {
 		hub = fiber.NewWSHub(config.PubSub)
+		if config.PubSubDeadLetters {
+			if err := hub.SetDeadLetter(fiber.DeadLetterConfig{Storage: config.Storage}); err != nil {
+				startupErr = errors.Join(startupErr, fmt.Errorf("pubsub dead letters: %w", err))
+			}
+		}
 		go hub.Run()
 	}
 
+	var clientPersistence *fiber.ClientStatePersistence
+	if len(config.ClientPersistedStateKeys) > 0 {
+		p, err := fiber.NewClientStatePersistence(fiber.ClientPersistConfig{
+			Keys:    config.ClientPersistedStateKeys,
+			Secrets: config.ClientStateSecrets,
+			Storage: config.ClientStateStorage,
+			MaxAge:  config.ClientStateMaxAge,
+		})
+		if err != nil {
+			startupErr = errors.Join(startupErr, fmt.Errorf("client-persisted state: %w", err))
+		} else {
+			clientPersistence = p
+		}
+	}
+
 	stateMap := state.NewStateMap()
 	for k, v := range config.DefaultState {
 		r := state.NewRune(v)
 		stateMap.Add(k, r)
 	}
 
+	var devTools *fiber.DevTools
+	if config.DevMode && hub != nil {
+		devTools = fiber.NewDevTools(fiber.DevConfig{
+			Enabled:         true,
+			AllowInsecureWS: config.AllowInsecureWS,
+			Hub:             hub,
+		})
+	}
+
 	app := &App{
 		Config:              config,
 		Router:              router,
 		Fiber:               fiberApp,
 		Hub:                 hub,
 		StateMap:            stateMap,
+		DevTools:            devTools,
+		Jobs:                newJobQueue(&config),
+		Analytics:           newAnalytics(&config),
+		Audit:               newAuditor(&config),
 		pluginTemplateFuncs: make(map[string]any),
 		ssgCache:            make(map[string]ssgEntry),
 		ssgCacheKeys:        make([]string, 0),
@@ -220,8 +332,14 @@ func New(config Config) *App {
 		routeCacheStats:     make(map[string]*routeCacheStats),
 		slotCacheStats:      make(map[string]*slotCacheStat),
 		startupErr:          startupErr,
+		clientPersistence:   clientPersistence,
+		stateStores:         fiber.GlobalStateStores(),
 	}
 	app.ctx, app.cancel = context.WithCancel(context.Background())
+	app.subscribeCacheInvalidation()
+	if app.Analytics != nil {
+		app.OnNavigate(app.recordPageView)
+	}
 	if startupErr != nil {
 		app.Logger().Error("GoSPA startup validation failed", "err", startupErr)
 	}
@@ -237,6 +355,38 @@ func New(config Config) *App {
 	return app
 }
 
+// applyGlobalSettings installs the package-level settings of config: the
+// WebSocket connection limits, notification queue size, session stores
+// (encrypted with StorageEncryptionKeys) and state persistence filter.
+func applyGlobalSettings(config *Config) error {
+	fiber.SetConnectionRateLimiter(config.WSConnBurst, config.WSConnRateLimit)
+	state.SetNotificationQueueSize(config.NotificationBufferSize)
+	fiber.InitStores(config.Storage)
+	// Each prefork process keeps its own in-memory sessions, so bind tokens
+	// to the process that issued them.
+	if config.Prefork && isInMemoryStorage(config.Storage) {
+		fiber.SetSessionAffinity(processID)
+	} else {
+		fiber.SetSessionAffinity("")
+	}
+	var encErr error
+	if len(config.StorageEncryptionKeys) > 0 {
+		encrypted, err := store.Encrypted(config.Storage, config.StorageEncryptionKeys[0], config.StorageEncryptionKeys[1:]...)
+		if err != nil {
+			encErr = fmt.Errorf("storage encryption: %w", err)
+		} else {
+			fiber.InitStateStores(encrypted)
+		}
+	}
+	persistFilter, err := fiber.NewStateKeyFilter(config.PersistStateKeys, config.ExcludeStateKeys)
+	fiber.SetStatePersistenceFilter(persistFilter)
+	var retentionErr error
+	if rerr := fiber.SetStateRetention(config.StateRetention); rerr != nil {
+		retentionErr = fmt.Errorf("state retention: %w", rerr)
+	}
+	return errors.Join(encErr, err, retentionErr)
+}
+
 func applyDefaultConfig(config *Config) {
 	if config.AppName == "" {
 		config.AppName = "GoSPA Application"
@@ -247,6 +397,10 @@ func applyDefaultConfig(config *Config) {
 		// If it's already false and path is empty, we set defaults but keep it disabled.
 		config.EnableWebSocket = true
 	}
+	if config.RequestMode {
+		config.EnableWebSocket = false
+		config.Prefork = false
+	}
 	if config.RoutesDir == "" {
 		config.RoutesDir = "./routes"
 	}
@@ -262,6 +416,8 @@ func applyDefaultConfig(config *Config) {
 	if config.DefaultState == nil {
 		config.DefaultState = make(map[string]interface{})
 	}
+	// Keys gospa build --prune-state found unused.
+	config.DefaultState = state.PruneDefaultState(config.DefaultState)
 	if config.WebSocketPath == "" {
 		config.WebSocketPath = "/_gospa/ws"
 	}
@@ -271,6 +427,13 @@ func applyDefaultConfig(config *Config) {
 	if config.MaxRequestBodySize == 0 {
 		config.MaxRequestBodySize = 4 * 1024 * 1024
 	}
+	if config.ServiceWorker != nil && config.NavigationOptions.ServiceWorkerNavigationCaching == nil {
+		enabled := true
+		config.NavigationOptions.ServiceWorkerNavigationCaching = &NavigationServiceWorkerCachingConfig{
+			Enabled: &enabled,
+			Path:    serviceWorkerPath(config.ServiceWorker),
+		}
+	}
 
 	switch {
 	case config.SSGCacheMaxEntries == 0:
@@ -309,7 +472,7 @@ func validateAndLogConfig(config *Config) error {
 	if config.Logger == nil {
 		config.Logger = slog.Default()
 	}
-	var validationErr error
+	validationErr := config.Validate()
 
 	// Validation: HydrationTimeout must be within 0-10s to prevent hanging or UI jank
 	if config.HydrationTimeout < 0 {
@@ -330,6 +493,21 @@ func validateAndLogConfig(config *Config) error {
 		config.HydrationMode = "visible"
 	}
 
+	if config.StateSyncCoalesceInterval < 0 {
+		config.StateSyncCoalesceInterval = 0
+	} else if config.StateSyncCoalesceInterval > time.Second {
+		config.Logger.Warn("StateSyncCoalesceInterval is above 1s; state updates will feel laggy", "value", config.StateSyncCoalesceInterval)
+	}
+
+	switch config.StateConflictPolicy {
+	case "":
+		config.StateConflictPolicy = fiber.ConflictLastWriteWins
+	case fiber.ConflictLastWriteWins, fiber.ConflictRejectStale:
+	default:
+		config.Logger.Warn("Unknown StateConflictPolicy, defaulting to last-writer-wins", "policy", config.StateConflictPolicy)
+		config.StateConflictPolicy = fiber.ConflictLastWriteWins
+	}
+
 	// GOSPA_WS_INSECURE env var provides a quick override for development.
 	// SECURITY: We block this override in production (DevMode: false) to prevent
 	// accidental mixed-content exposure from leaked environment variables.
@@ -361,16 +539,35 @@ func validateAndLogConfig(config *Config) error {
 		if strategy == "" {
 			strategy = routing.StrategySSR
 		}
-		needsTemplateCache := strategy == routing.StrategySSG || strategy == routing.StrategyISR || strategy == routing.StrategyPPR
-		if needsTemplateCache && !config.CacheTemplates {
-			validationErr = errors.Join(validationErr, fmt.Errorf("route %q uses %s but CacheTemplates=false; enable CacheTemplates or change strategy", path, strategy))
+		if opts.RevalidateCron != "" {
+			if _, err := jobs.ParseCron(opts.RevalidateCron); err != nil {
+				validationErr = errors.Join(validationErr, fmt.Errorf("route %q RevalidateCron: %w", path, err))
+			} else if strategy != routing.StrategyISR {
+				config.Logger.Warn("RevalidateCron is ignored because the route does not use isr", "path", path, "strategy", strategy)
+			}
+		}
+		for _, variant := range opts.Variants {
+			switch variant {
+			case routing.VariantLocale:
+				if len(config.Locales) == 0 {
+					config.Logger.Warn("Locale variant is ignored because Locales is empty", "path", path)
+				}
+			case routing.VariantDevice:
+			default:
+				validationErr = errors.Join(validationErr, fmt.Errorf("route %q has unknown variant %q", path, variant))
+			}
 		}
 		if strategy == routing.StrategySSG && config.SSGCacheTTL == 0 {
 			config.Logger.Warn("SSG route caches forever because SSGCacheTTL=0", "path", path)
 		}
 	}
 
-	if config.Prefork && isInMemoryStorage(config.Storage) {
+	// SECURITY: pprof exposes command line, heap contents, and goroutine stacks.
+	if config.EnablePprof && !config.DevMode && config.PprofToken == "" {
+		config.Logger.Warn("EnablePprof is set without PprofToken in production. Protect /_gospa/debug/ with PprofToken or another layer.")
+	}
+
+	if config.Prefork && config.DevMode && isInMemoryStorage(config.Storage) {
 		config.Logger.Warn("Prefork with in-memory cache/storage detected: render caches are process-local; use distributed Storage for seamless ISR/SSG/PPR")
 	}
 
@@ -399,16 +596,34 @@ func validateAndLogConfig(config *Config) error {
 		if config.ISRTimeout <= 0 {
 			validationErr = errors.Join(validationErr, fmt.Errorf("StrictProduction requires ISRTimeout > 0"))
 		}
+		if config.EnablePprof && config.PprofToken == "" {
+			validationErr = errors.Join(validationErr, fmt.Errorf("StrictProduction requires PprofToken when EnablePprof=true"))
+		}
 	}
 
 	return validationErr
 }
 
+// mergeFiberConfig copies the non-zero fields of override into dst.
+func mergeFiberConfig(dst *fiberpkg.Config, override fiberpkg.Config) {
+	d := reflect.ValueOf(dst).Elem()
+	o := reflect.ValueOf(override)
+	for i := 0; i < o.NumField(); i++ {
+		if f := o.Field(i); !f.IsZero() && d.Field(i).CanSet() {
+			d.Field(i).Set(f)
+		}
+	}
+}
+
+// processID identifies this process in session tokens under prefork with
+// in-memory Storage.
+var processID = fiber.NewProcessID()
+
 func isInMemoryStorage(storage store.Storage) bool {
 	if storage == nil {
 		return true
 	}
-	_, ok := storage.(*store.MemoryStorage)
+	_, ok := store.Unwrap(storage).(*store.MemoryStorage)
 	return ok
 }
 
@@ -458,39 +673,28 @@ func (a *App) setupRoutes() {
 	}
 
 	if a.Hub != nil {
-		handlers := []fiberpkg.Handler{
-			fiber.SessionMiddleware(),
-			fiber.WebSocketUpgradeMiddleware(),
-		}
-		if a.Config.WebSocketMiddleware != nil {
-			handlers = append(handlers, a.Config.WebSocketMiddleware)
-		}
-		handlers = append(handlers, fiber.WebSocketHandler(fiber.WebSocketConfig{
-			Hub:                 a.Hub,
-			CompressState:       a.Config.CompressState,
-			StateDiffing:        a.Config.StateDiffing,
-			Serializer:          a.Config.StateSerializer,
-			Deserializer:        a.Config.StateDeserializer,
-			SerializationFormat: a.Config.SerializationFormat,
-			WSMaxMessageSize:    a.Config.WSMaxMessageSize,
-		}))
-		hAny := make([]any, len(handlers))
-		for i, h := range handlers {
-			hAny[i] = h
-		}
-		a.Fiber.Get(a.Config.WebSocketPath, hAny[0], hAny[1:]...)
+		a.registerWebSocket(a.mainWebSocket())
+	}
+	for _, ep := range a.wsEndpoints {
+		a.registerWebSocket(ep)
+	}
+
+	if a.DevTools != nil {
+		a.Fiber.Get("/_gospa/dev", func(c fiberpkg.Ctx) error {
+			c.Set("Cache-Control", "no-store")
+			return c.Next()
+		}, a.DevTools.DevPanelHandler())
+		a.Fiber.Get("/_gospa/dev/ws", fiber.WebSocketUpgradeMiddleware(), a.DevTools.DevToolsHandler())
 	}
 
-	remoteHandlers := []fiberpkg.Handler{
-		fiber.SessionMiddleware(),
-		fiber.RemoteActionRateLimitMiddleware(),
+	remoteHandlers := []fiberpkg.Handler{fiber.SessionMiddleware()}
+	if a.Audit != nil {
+		remoteHandlers = append(remoteHandlers, a.auditRemoteAction)
 	}
+	remoteHandlers = append(remoteHandlers, fiber.RemoteActionRateLimitMiddleware())
 	if !a.Config.DevMode && a.Config.RemoteActionMiddleware == nil && !a.Config.AllowUnauthenticatedRemoteActions {
 		remoteHandlers = append(remoteHandlers, func(c fiberpkg.Ctx) error {
-			return c.Status(fiberpkg.StatusUnauthorized).JSON(fiberpkg.Map{
-				"error": "Remote actions require RemoteActionMiddleware in production",
-				"code":  "REMOTE_AUTH_REQUIRED",
-			})
+			return a.sendError(c, NewError(CodeRemoteAuthRequired, fiberpkg.StatusUnauthorized, "Remote actions require RemoteActionMiddleware in production"))
 		})
 	}
 	if a.Config.RemoteActionMiddleware != nil {
@@ -506,10 +710,7 @@ func (a *App) setupRoutes() {
 	invalidateHandlers := []fiberpkg.Handler{fiber.SessionMiddleware()}
 	if !a.Config.DevMode && a.Config.RemoteActionMiddleware == nil && !a.Config.AllowUnauthenticatedRemoteActions {
 		invalidateHandlers = append(invalidateHandlers, func(c fiberpkg.Ctx) error {
-			return c.Status(fiberpkg.StatusUnauthorized).JSON(fiberpkg.Map{
-				"error": "Cache invalidation requires RemoteActionMiddleware in production",
-				"code":  "INVALIDATION_AUTH_REQUIRED",
-			})
+			return a.sendError(c, NewError(CodeInvalidationAuth, fiberpkg.StatusUnauthorized, "Cache invalidation requires RemoteActionMiddleware in production"))
 		})
 	}
 	if a.Config.RemoteActionMiddleware != nil {
@@ -521,10 +722,44 @@ func (a *App) setupRoutes() {
 		ihAny[i] = h
 	}
 	a.Fiber.Post("/_gospa/invalidate", ihAny[0], ihAny[1:]...)
+	if a.Config.StateRetention > 0 {
+		purgeHandlers := []any{fiber.SessionMiddleware()}
+		if !a.Config.DevMode && a.Config.RemoteActionMiddleware == nil && !a.Config.AllowUnauthenticatedRemoteActions {
+			purgeHandlers = append(purgeHandlers, func(c fiberpkg.Ctx) error {
+				return a.sendError(c, NewError(CodeStatePurgeAuth, fiberpkg.StatusUnauthorized, "State purge requires RemoteActionMiddleware in production"))
+			})
+		}
+		if a.Config.RemoteActionMiddleware != nil {
+			purgeHandlers = append(purgeHandlers, a.Config.RemoteActionMiddleware)
+		}
+		purgeHandlers = append(purgeHandlers, a.handleStatePurge)
+		a.Fiber.Post("/_gospa/state/purge", purgeHandlers[0], purgeHandlers[1:]...)
+	}
 	if a.Config.DevMode {
 		a.Fiber.Get("/__gospa/cache", a.handleCacheStats)
+		a.Fiber.Get("/_gospa/dev/routes", func(c fiberpkg.Ctx) error {
+			c.Set("Cache-Control", "no-store")
+			return c.Next()
+		}, a.handleRouteTable)
+		a.Fiber.Post("/_gospa/dev/routes/invalidate", a.handleRouteInvalidate)
+		a.Fiber.Get("/_gospa/dev/profile", func(c fiberpkg.Ctx) error {
+			c.Set("Cache-Control", "no-store")
+			return c.Next()
+		}, a.handleRequestProfiles)
+		a.Fiber.Delete("/_gospa/dev/profile", a.handleRequestProfiles)
+		a.Fiber.Get("/_gospa/dev/jobs", func(c fiberpkg.Ctx) error {
+			c.Set("Cache-Control", "no-store")
+			return c.Next()
+		}, a.handleJobStats)
+	}
+	a.setupAnalyticsRoutes()
+	a.setupHealthRoutes()
+	a.setupSEORoutes()
+	a.setupServiceWorkerRoutes()
+	a.setupManifestRoutes()
+	if a.Config.EnablePprof {
+		a.setupDebugRoutes()
 	}
-	a.Fiber.Get("/_gospa/poll", a.handleTransportPoll)
 
 	if _, err := os.Stat(a.Config.StaticDir); err == nil {
 		a.Fiber.Use(a.Config.StaticPrefix, static.New(a.Config.StaticDir, static.Config{
@@ -561,58 +796,38 @@ func (a *App) setupRoutes() {
 func (a *App) handleRemoteAction(c fiberpkg.Ctx) error {
 	name := c.Params("name")
 	if len(name) > 256 {
-		return c.Status(fiberpkg.StatusBadRequest).JSON(fiberpkg.Map{
-			"error": "Action name too long",
-			"code":  "INVALID_ACTION_NAME",
-		})
+		return a.sendError(c, NewError(CodeInvalidActionName, fiberpkg.StatusBadRequest, "Action name too long"))
 	}
 	fn, ok := routing.GetRemoteAction(name)
 	if !ok {
-		return c.Status(fiberpkg.StatusNotFound).JSON(fiberpkg.Map{
-			"error": "Remote action not found",
-			"code":  "ACTION_NOT_FOUND",
-		})
+		return a.sendError(c, NewError(CodeActionNotFound, fiberpkg.StatusNotFound, "Remote action not found"))
 	}
 
 	var input interface{}
 	if contentLength := c.Request().Header.ContentLength(); contentLength > a.Config.MaxRequestBodySize {
-		return c.Status(fiberpkg.StatusRequestEntityTooLarge).JSON(fiberpkg.Map{
-			"error": "Request body too large",
-			"code":  "REQUEST_TOO_LARGE",
-		})
+		return a.sendError(c, NewError(CodeRequestTooLarge, fiberpkg.StatusRequestEntityTooLarge, "Request body too large"))
 	}
 
 	if body := c.Body(); len(body) > 0 {
 		if !strings.Contains(c.Get("Content-Type"), "application/json") {
-			return c.Status(fiberpkg.StatusUnsupportedMediaType).JSON(fiberpkg.Map{
-				"error": "Unsupported Media Type: expected application/json",
-				"code":  "INVALID_CONTENT_TYPE",
-			})
+			return a.sendError(c, NewError(CodeInvalidContentType, fiberpkg.StatusUnsupportedMediaType, "Unsupported Media Type: expected application/json"))
 		}
 		if len(body) > a.Config.MaxRequestBodySize {
-			return c.Status(fiberpkg.StatusRequestEntityTooLarge).JSON(fiberpkg.Map{
-				"error": "Request body too large",
-				"code":  "REQUEST_TOO_LARGE",
-			})
+			return a.sendError(c, NewError(CodeRequestTooLarge, fiberpkg.StatusRequestEntityTooLarge, "Request body too large"))
 		}
 		var err error
 		input, err = decodeRemoteActionBody(body)
 		if err != nil {
 			if errors.Is(err, ErrJSONTooDeep) {
-				return c.Status(fiberpkg.StatusBadRequest).JSON(fiberpkg.Map{
-					"error": "JSON nesting too deep",
-					"code":  "JSON_TOO_DEEP",
-				})
+				return a.sendError(c, NewError(CodeJSONTooDeep, fiberpkg.StatusBadRequest, "JSON nesting too deep"))
 			}
-			return c.Status(fiberpkg.StatusBadRequest).JSON(fiberpkg.Map{
-				"error": "Invalid input JSON",
-				"code":  "INVALID_JSON",
-			})
+			return a.sendError(c, NewError(CodeInvalidJSON, fiberpkg.StatusBadRequest, "Invalid input JSON"))
 		}
 	}
 
 	headers := make(map[string]string, 4)
-	if requestID := string(c.Request().Header.Peek("X-Request-Id")); requestID != "" {
+	requestID := fiber.GetRequestID(c)
+	if requestID != "" {
 		headers["X-Request-Id"] = requestID
 	}
 	if traceParent := string(c.Request().Header.Peek("Traceparent")); traceParent != "" {
@@ -628,32 +843,61 @@ func (a *App) handleRemoteAction(c fiberpkg.Ctx) error {
 	rc := routing.RemoteContext{
 		IP:        c.IP(),
 		UserAgent: string(c.Request().Header.UserAgent()),
-		RequestID: c.Get("X-Request-Id"),
+		RequestID: requestID,
 		SessionID: c.Get("X-Session-Id"),
 		Headers:   headers,
 	}
 
-	result, err := fn(c.Context(), rc, input)
-	if err != nil {
-		a.Logger().Error("remote action error", "action", name, "err", err)
-
-		response := fiberpkg.Map{
-			"error": "Internal server error",
-			"code":  "ACTION_FAILED",
+	if len(c.Get(IdempotencyKeyHeader)) > maxIdempotencyKeyLen {
+		return a.sendError(c, NewError(CodeInvalidIdempotencyKey, fiberpkg.StatusBadRequest, "Idempotency-Key too long"))
+	}
+	reuse, reusable := remoteReuseFor(c, name, routing.GetRemoteActionOptions(name), c.Body())
+	if reusable {
+		release, err := a.claimRemoteCall(c.Context(), reuse.key)
+		if err != nil {
+			return err
 		}
+		defer release()
+		stored, err := a.loadRemoteResult(c.Context(), reuse)
+		if err != nil {
+			a.Logger().Warn("failed to load remote action result", "action", name, "err", err)
+		} else if stored != nil {
+			return a.sendStoredRemoteResult(c, reuse, stored)
+		}
+	}
 
-		// Include debug info in DevMode
-		if a.Config.DevMode {
-			response["debug"] = err.Error()
+	// A "cancel" frame from the session's WebSocket naming the request ID
+	// cancels the action's context.
+	ctx, done := a.Hub.TrackSessionCall(c.Context(), c.Cookies("gospa_session"), requestID)
+	defer done()
+	result, err := fn(ctx, rc, input)
+	if err != nil {
+		// Unclassified errors keep the ACTION_FAILED code clients already
+		// expect from failing actions.
+		e := classify(err, errActionFailed)
+		if e.HTTPStatus >= fiberpkg.StatusInternalServerError {
+			a.Logger().Error("remote action error", "action", name, "code", e.Code, "request_id", requestID, "err", err)
 		}
+		return a.sendError(c, e)
+	}
 
-		return c.Status(fiberpkg.StatusInternalServerError).JSON(response)
+	switch file := result.(type) {
+	case routing.FileResult:
+		return sendRemoteFile(c, &file)
+	case *routing.FileResult:
+		if file != nil {
+			return sendRemoteFile(c, file)
+		}
 	}
 
-	return c.JSON(fiberpkg.Map{
+	if err := c.JSON(fiberpkg.Map{
 		"data": result,
 		"code": "SUCCESS",
-	})
+	}); err != nil || !reusable {
+		return err
+	}
+	a.saveRemoteResult(c.Context(), reuse, append([]byte(nil), c.Response().Body()...))
+	return nil
 }
 
 func (a *App) handleInvalidate(c fiberpkg.Ctx) error {
@@ -664,10 +908,7 @@ func (a *App) handleInvalidate(c fiberpkg.Ctx) error {
 		All  bool   `json:"all"`
 	}
 	if err := c.Bind().Body(&payload); err != nil {
-		return c.Status(fiberpkg.StatusBadRequest).JSON(fiberpkg.Map{
-			"error": "Invalid invalidation payload",
-			"code":  "INVALID_INVALIDATION_PAYLOAD",
-		})
+		return a.sendError(c, NewError(CodeInvalidInvalidation, fiberpkg.StatusBadRequest, "Invalid invalidation payload"))
 	}
 
 	invalidated := 0
@@ -750,6 +991,18 @@ func (a *App) applyPluginMiddleware() {
 }
 
 func (a *App) setupMiddleware() {
+	// Tenants registered with Host get the request before any of this app's
+	// middleware.
+	a.Fiber.Use(a.serveHost)
+	a.Fiber.Use(fiber.StateStoresMiddleware(a.stateStores))
+
+	if a.Config.CanonicalRedirect || (a.Config.TrailingSlash != "" && a.Config.TrailingSlash != TrailingSlashPreserve) {
+		a.Fiber.Use(a.canonicalMiddleware())
+	}
+
+	// Request IDs come first so hooks and every log line can use them.
+	a.Fiber.Use(fiber.RequestIDMiddleware())
+
 	// 1. Global Hooks (SvelteKit hooks.server.go style)
 	for _, hook := range routing.GetHooks() {
 		a.Fiber.Use(hook)
@@ -760,6 +1013,7 @@ func (a *App) setupMiddleware() {
 	}))
 	if a.Config.DevMode {
 		a.Fiber.Use(logger.New())
+		a.Fiber.Use(a.profilerMiddleware())
 	}
 	a.Fiber.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed,
@@ -772,6 +1026,9 @@ func (a *App) setupMiddleware() {
 		a.Fiber.Use(fiber.CSRFSetTokenMiddleware())
 		a.Fiber.Use(fiber.CSRFTokenMiddleware())
 	}
+	if a.Config.Database != nil {
+		a.Fiber.Use(a.Config.Database.Middleware(a.sqlQueryLogger))
+	}
 	if !a.Config.DisableSPA {
 		a.Fiber.Use(fiber.SPANavigationMiddleware())
 	}
@@ -779,6 +1036,7 @@ func (a *App) setupMiddleware() {
 	preloadConfig.RuntimeScript = a.getRuntimePath()
 	preloadConfig.CSSLinks = a.Config.PreloadCSS
 	preloadConfig.BuildManifest = a.Config.BuildManifest
+	preloadConfig.LazyRuntime = a.Config.InlineRuntimeBootstrap
 	a.Fiber.Use(fiber.PreloadHeadersMiddleware(preloadConfig))
 
 	spaConfig := fiber.DefaultConfig()
@@ -805,43 +1063,67 @@ func (a *App) Logger() *slog.Logger {
 	return slog.Default()
 }
 
+// Prepare mounts the internal and page routes without starting a listener.
+// Run and RunTLS call it; adapters that feed requests to a.Fiber themselves
+// (see adapter/lambda) call it once before serving. Later calls return the
+// first result.
+func (a *App) Prepare() error {
+	a.prepareOnce.Do(func() {
+		a.prepared = true
+		if a.startupErr != nil {
+			a.prepareErr = fmt.Errorf("gospa startup validation failed: %w", a.startupErr)
+			return
+		}
+		if err := a.prepareTenants(); err != nil {
+			a.prepareErr = err
+			return
+		}
+		if err := plugin.TriggerHook(plugin.BeforeServe, map[string]interface{}{
+			"fiber":  a.Fiber,
+			"config": a.Config,
+		}); err != nil {
+			a.Logger().Error("plugin BeforeServe hook failed", "err", err)
+		}
+		a.applyPluginMiddleware()
+		a.setupRoutes()
+		a.prepareErr = a.RegisterRoutes()
+		if a.prepareErr == nil && !a.Config.RequestMode {
+			if a.Jobs != nil {
+				a.Jobs.Start(a.Context())
+			}
+			if a.Analytics != nil {
+				a.Analytics.Start(a.Context())
+			}
+			if a.Audit != nil {
+				a.Audit.Start(a.Context())
+			}
+			a.startISRSchedules()
+			a.startStateReaper()
+			for _, sub := range a.mounts {
+				sub.startISRSchedules()
+			}
+		}
+	})
+	return a.prepareErr
+}
+
 // Run starts the GoSPA application on the specified address.
 func (a *App) Run(addr string) error {
-	if a.startupErr != nil {
-		return fmt.Errorf("gospa startup validation failed: %w", a.startupErr)
-	}
-	if err := plugin.TriggerHook(plugin.BeforeServe, map[string]interface{}{
-		"fiber":  a.Fiber,
-		"config": a.Config,
-	}); err != nil {
-		a.Logger().Error("plugin BeforeServe hook failed", "err", err)
-	}
-	a.applyPluginMiddleware()
-	a.setupRoutes()
-	if err := a.RegisterRoutes(); err != nil {
+	if err := a.Prepare(); err != nil {
 		return err
 	}
 	a.Logger().Info("starting GoSPA", "version", Version, "addr", addr)
+	a.printStartupSummary()
 	return a.Fiber.Listen(addr)
 }
 
 // RunTLS starts the GoSPA application on the specified address with TLS.
 func (a *App) RunTLS(addr, certFile, keyFile string) error {
-	if a.startupErr != nil {
-		return fmt.Errorf("gospa startup validation failed: %w", a.startupErr)
-	}
-	if err := plugin.TriggerHook(plugin.BeforeServe, map[string]interface{}{
-		"fiber":  a.Fiber,
-		"config": a.Config,
-	}); err != nil {
-		a.Logger().Error("plugin BeforeServe hook failed", "err", err)
-	}
-	a.applyPluginMiddleware()
-	a.setupRoutes()
-	if err := a.RegisterRoutes(); err != nil {
+	if err := a.Prepare(); err != nil {
 		return err
 	}
 	a.Logger().Info("starting GoSPA (TLS)", "version", Version, "addr", addr)
+	a.printStartupSummary()
 	return a.Fiber.Listen(addr, fiberpkg.ListenConfig{
 		CertFile:    certFile,
 		CertKeyFile: keyFile,
@@ -853,18 +1135,43 @@ func (a *App) Shutdown() error {
 	if a.cancel != nil {
 		a.cancel()
 	}
+	if a.cacheInvalidationUnsub != nil {
+		a.cacheInvalidationUnsub()
+	}
+	for _, sub := range a.mounts {
+		sub.cancel()
+		if sub.cacheInvalidationUnsub != nil {
+			sub.cacheInvalidationUnsub()
+		}
+	}
+	a.shutdownTenants()
 	if err := plugin.TriggerHook(plugin.BeforePrune, nil); err != nil {
 		a.Logger().Error("plugin BeforePrune hook failed", "err", err)
 	}
 	if a.Hub != nil {
 		a.Hub.Close()
 	}
+	a.closeWebSockets()
+	if a.Jobs != nil {
+		a.Jobs.Stop()
+	}
+	if a.Analytics != nil {
+		if err := a.Analytics.Close(context.Background()); err != nil {
+			a.Logger().Error("analytics flush failed", "err", err)
+		}
+	}
+	if a.Audit != nil {
+		if err := a.Audit.Close(context.Background()); err != nil {
+			a.Logger().Error("audit flush failed", "err", err)
+		}
+	}
 	fiber.CloseGlobalRateLimiters()
 	if closer, ok := a.Config.Storage.(interface{ Close() error }); ok {
 		if err := closer.Close(); err != nil {
 			a.Logger().Error("Storage close failed", "err", err)
 		}
 	}
+	a.closeHTTPServers()
 	err := a.Fiber.Shutdown()
 	if err := plugin.TriggerHook(plugin.AfterPrune, nil); err != nil {
 		a.Logger().Error("plugin AfterPrune hook failed", "err", err)
@@ -880,8 +1187,28 @@ func (a *App) Context() context.Context {
 	return a.ctx
 }
 
-// RegisterRoutes manually triggers route registration.
+// StateStores returns the session and client state stores the app's
+// handlers use. A tenant created with NewTenant has its own.
+func (a *App) StateStores() *fiber.StateStores {
+	return a.stateStores
+}
+
+// RevokeClientSessions invalidates every session token the app issued for
+// clientID, e.g. on logout from all devices, and returns the number revoked.
+// Unlike fiber.RevokeClientSessions it reaches the sessions of a tenant.
+func (a *App) RevokeClientSessions(clientID string) (int, error) {
+	return a.stateStores.RevokeClientSessions(clientID)
+}
+
+// RegisterRoutes manually triggers route registration. The pages of mounted
+// apps are registered first, so they take precedence over dynamic routes of
+// the app.
 func (a *App) RegisterRoutes() error {
+	for _, sub := range a.mounts {
+		if err := sub.RegisterRoutes(); err != nil {
+			return fmt.Errorf("app mounted at %s: %w", sub.Router.BasePath(), err)
+		}
+	}
 	if err := a.Scan(); err != nil {
 		return err
 	}
@@ -1194,11 +1521,50 @@ func (a *App) Broadcast(message []byte) {
 	}
 }
 
-// BroadcastState broadcasts a state update to all connected clients.
+// BroadcastState broadcasts a state update to all connected clients. Clients
+// that subscribed to state key prefixes only receive matching keys.
 func (a *App) BroadcastState(key string, value interface{}) error {
 	return fiber.BroadcastState(a.Hub, key, value)
 }
 
+// BroadcastStateToTopic broadcasts a state update only to clients subscribed
+// to topic, either by the server (Hub.Subscribe) or by a "subscribe" message
+// approved by WSTopicAuthorizer.
+func (a *App) BroadcastStateToTopic(topic, key string, value interface{}) error {
+	return fiber.BroadcastStateToTopic(a.Hub, topic, key, value)
+}
+
+// ShareCRDT registers a collaborative CRDT (see state.NewCRDTMap and state.NewCRDTList)
+// under key and adds it to the application's global state. Ops merge across clients
+// and processes through the configured PubSub.
+func (a *App) ShareCRDT(key string, c state.CRDT) *App {
+	a.StateMap.Add(key, c)
+	if a.Hub != nil {
+		a.Hub.ShareCRDT(key, c)
+	}
+	return a
+}
+
+// RegisterListSource serves src as the list name over the WebSocket, so a
+// large collection loads a page at a time (liveList in the client runtime)
+// instead of living in the global state. Send its changes with
+// PublishListEvents.
+func (a *App) RegisterListSource(name string, src fiber.ListSource) *App {
+	if a.Hub != nil {
+		a.Hub.RegisterListSource(name, src)
+	}
+	return a
+}
+
+// PublishListEvents sends changes to the list name to the clients that
+// loaded it, across processes through the configured PubSub.
+func (a *App) PublishListEvents(name string, events ...fiber.ListEvent) error {
+	if a.Hub == nil {
+		return nil
+	}
+	return a.Hub.PublishListEvents(name, events...)
+}
+
 // Computed adds a computed state variable to the application's global state.
 // It automatically updates when its dependencies change and broadcasts the result to all clients.
 func (a *App) Computed(key string, deps []string, fn func(map[string]interface{}) interface{}) *App {
@@ -1211,7 +1577,7 @@ func Broadcast(message interface{}) error {
 	if defaultApp == nil || defaultApp.Hub == nil {
 		return fmt.Errorf("gospa app not initialized or websocket not enabled")
 	}
-	b, err := json.Marshal(message)
+	b, err := fiber.JSONMarshal(message)
 	if err != nil {
 		return err
 	}
@@ -1219,6 +1585,20 @@ func Broadcast(message interface{}) error {
 	return nil
 }
 
+// SetJSONCodec replaces the JSON codec used for WebSocket messages, state
+// sync, Broadcast, and remote action request and response bodies. Passing
+// nil restores the goccy/go-json default. The Fiber app's c.JSON and body
+// binding also go through it. Changes apply to subsequent messages, so it is
+// safe to call at any time, though usually it is set once at startup.
+//
+// The codec must behave like encoding/json. For remote actions, unmarshal
+// should decode numbers into json.Number when the target is interface{}
+// (sonic: sonic.Config{UseNumber: true}.Froze().Unmarshal) to keep large
+// integers exact, as the default decoder does.
+func SetJSONCodec(marshal func(v interface{}) ([]byte, error), unmarshal func(data []byte, v interface{}) error) {
+	fiber.SetJSONCodec(marshal, unmarshal)
+}
+
 type fiberLoadContext struct {
 	c fiberpkg.Ctx
 }
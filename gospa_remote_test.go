@@ -3,6 +3,8 @@ package gospa
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -154,3 +156,164 @@ func TestRemoteActionMiddleware_BlocksRequestBeforeHandler(t *testing.T) {
 		t.Fatalf("expected blocked error response, got %#v", body)
 	}
 }
+
+func TestRemoteAction_TypedValidationFailed(t *testing.T) {
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	type signup struct {
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"min=13"`
+	}
+	routing.RegisterRemoteActionTyped(name, func(_ context.Context, _ routing.RemoteContext, in signup) (string, error) {
+		return "welcome " + in.Email, nil
+	})
+
+	app := New(Config{DevMode: true})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	post := func(payload string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		addValidCSRF(req)
+		res, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return res.StatusCode, body
+	}
+
+	status, body := post(`{"email":"nope","age":9}`)
+	if status != fiber.StatusUnprocessableEntity || body["code"] != "VALIDATION_FAILED" {
+		t.Fatalf("expected 422 VALIDATION_FAILED, got %d %#v", status, body)
+	}
+	fields, _ := body["validation"].(map[string]any)["fieldErrors"].(map[string]any)
+	if fields["email"] != "must be a valid email address" || fields["age"] != "must be at least 13" {
+		t.Fatalf("unexpected field errors %#v", fields)
+	}
+
+	status, body = post(`{"email":"ada@example.com","age":36}`)
+	if status != fiber.StatusOK || body["data"] != "welcome ada@example.com" {
+		t.Fatalf("expected success, got %d %#v", status, body)
+	}
+}
+
+func TestRemoteAction_ErrorEnvelope(t *testing.T) {
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	routing.RegisterRemoteAction(name, func(_ context.Context, _ routing.RemoteContext, input interface{}) (interface{}, error) {
+		if input == "stock" {
+			return nil, NewError("OUT_OF_STOCK", fiber.StatusConflict, "That item is sold out")
+		}
+		return nil, errors.New("db down")
+	})
+
+	app := New(Config{AllowUnauthenticatedRemoteActions: true})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	post := func(payload string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		addValidCSRF(req)
+		res, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return res.StatusCode, body
+	}
+
+	status, body := post(`"stock"`)
+	if status != fiber.StatusConflict || body["code"] != "OUT_OF_STOCK" || body["error"] != "That item is sold out" {
+		t.Fatalf("expected 409 OUT_OF_STOCK, got %d %#v", status, body)
+	}
+
+	status, body = post(`"other"`)
+	if status != fiber.StatusInternalServerError || body["code"] != CodeActionFailed {
+		t.Fatalf("expected 500 ACTION_FAILED, got %d %#v", status, body)
+	}
+	if _, ok := body["debug"]; ok {
+		t.Fatalf("debug info leaked outside DevMode: %#v", body)
+	}
+}
+
+func TestRemoteAction_RequestID(t *testing.T) {
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	routing.RegisterRemoteAction(name, func(ctx context.Context, rc routing.RemoteContext, _ interface{}) (interface{}, error) {
+		if RequestID(ctx) != rc.RequestID {
+			t.Errorf("RequestID(ctx) = %q, RemoteContext.RequestID = %q", RequestID(ctx), rc.RequestID)
+		}
+		return nil, ErrConflict
+	})
+
+	app := New(Config{AllowUnauthenticatedRemoteActions: true})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, nil)
+	req.Header.Set("X-Request-ID", "trace-123")
+	addValidCSRF(req)
+	res, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if res.Header.Get("X-Request-ID") != "trace-123" || body["requestId"] != "trace-123" {
+		t.Fatalf("request ID not propagated: header=%q body=%#v", res.Header.Get("X-Request-ID"), body)
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed.Store(true)
+	return nil
+}
+
+func TestRemoteAction_FileResult(t *testing.T) {
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	csv := &closeRecorder{Reader: strings.NewReader("id,name\n1,Ada\n")}
+	routing.RegisterRemoteAction(name, func(_ context.Context, _ routing.RemoteContext, _ interface{}) (interface{}, error) {
+		return &routing.FileResult{Name: "reports/users é.csv", Reader: csv}, nil
+	})
+
+	app := New(Config{AllowUnauthenticatedRemoteActions: true})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, nil)
+	addValidCSRF(req)
+	res, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "id,name\n1,Ada\n" {
+		t.Fatalf("body = %q", body)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if cd := res.Header.Get("Content-Disposition"); cd != "attachment; filename*=utf-8''users%20%C3%A9.csv" {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+	if !csv.closed.Load() {
+		t.Fatal("the file reader was not closed")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
 )
 
 // ─── DefaultConfig ────────────────────────────────────────────────────────────
@@ -87,6 +90,27 @@ func TestNew_HubInitialized(t *testing.T) {
 	}
 }
 
+func TestConfigureWebSocket(t *testing.T) {
+	var got fiber.WebSocketConfig
+	app := New(Config{
+		RoutesFS:       fstest.MapFS{},
+		DevMode:        true,
+		CacheTemplates: true,
+		ConfigureWebSocket: func(c *fiber.WebSocketConfig) {
+			got = *c
+			c.GenerateID = func() string { return "fixed" }
+			c.Hub = nil
+		},
+	})
+	defer func() { _ = app.Shutdown() }()
+	if err := app.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if got.Hub != app.Hub || got.OnMessage == nil || got.OnConnect == nil {
+		t.Fatalf("ConfigureWebSocket got %+v, want the app's hub and handlers", got)
+	}
+}
+
 func TestNew_StateMapInitialized(t *testing.T) {
 	app := New(Config{})
 	defer func() { _ = app.Fiber.Shutdown() }()
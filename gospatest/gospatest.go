@@ -0,0 +1,324 @@
+// Package gospatest runs a GoSPA app in-process for tests.
+//
+//	func TestAbout(t *testing.T) {
+//		app := gospatest.NewApp(t, gospa.Config{RoutesDir: "./routes"})
+//		doc := app.Client.Render("/about")
+//		doc.AssertText("h1", "About us")
+//
+//		app.Client.Remote("subscribe", map[string]any{"email": "a@b.c"}).AssertStatus(200)
+//	}
+//
+// Requests go through app.Fiber.Test, so no port is opened. WebSocket
+// clients connect over an in-memory listener.
+package gospatest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa"
+	fiberpkg "github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// requestTimeout bounds a single test request.
+const requestTimeout = 10 * time.Second
+
+// App is a prepared gospa.App with a test client and a manual clock.
+type App struct {
+	*gospa.App
+	// Client is a default client. Use NewClient for a second visitor.
+	Client *Client
+	// Clock drives Config.Now unless the config set its own.
+	Clock *Clock
+
+	t        testing.TB
+	lnOnce   sync.Once
+	listener *fasthttputil.InmemoryListener
+}
+
+// NewApp creates and prepares an app from config and shuts it down when the
+// test ends. Config.Now defaults to the returned Clock, which starts at the
+// current time. Startup validation errors fail the test.
+func NewApp(t testing.TB, config gospa.Config) *App {
+	t.Helper()
+	clock := NewClock(time.Now())
+	if config.Now == nil {
+		config.Now = clock.Now
+	}
+	app := &App{App: gospa.New(config), Clock: clock, t: t}
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+		if app.listener != nil {
+			_ = app.listener.Close()
+		}
+	})
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("gospatest: prepare app: %v", err)
+	}
+	app.Client = app.NewClient()
+	return app
+}
+
+// dial connects to the app over an in-memory listener, serving it on first
+// use.
+func (a *App) dial() (net.Conn, error) {
+	a.lnOnce.Do(func() {
+		a.listener = fasthttputil.NewInmemoryListener()
+		go func() {
+			_ = a.Fiber.Listener(a.listener, fiberpkg.ListenConfig{DisableStartupMessage: true})
+		}()
+	})
+	return a.listener.Dial()
+}
+
+// Client sends requests to an App and keeps its cookies, like one browser.
+type Client struct {
+	app *App
+	t   testing.TB
+	// Header is sent with every request.
+	Header  http.Header
+	mu      sync.Mutex
+	cookies map[string]*http.Cookie
+}
+
+// NewClient returns a client with its own cookies.
+func (a *App) NewClient() *Client {
+	return &Client{app: a, t: a.t, Header: http.Header{}, cookies: map[string]*http.Cookie{}}
+}
+
+// Get requests path.
+func (c *Client) Get(path string) *Response {
+	c.t.Helper()
+	return c.Do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// Post sends body to path with the given content type.
+func (c *Client) Post(path, contentType string, body io.Reader) *Response {
+	c.t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(req)
+}
+
+// PostForm submits form to path as a form action would.
+func (c *Client) PostForm(path string, form url.Values) *Response {
+	c.t.Helper()
+	return c.Post(path, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+// PostJSON sends v as JSON to path.
+func (c *Client) PostJSON(path string, v any) *Response {
+	c.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatalf("gospatest: encode %s body: %v", path, err)
+	}
+	return c.Post(path, "application/json", bytes.NewReader(body))
+}
+
+// Remote calls the remote action name with input.
+func (c *Client) Remote(name string, input any) *Response {
+	c.t.Helper()
+	return c.PostJSON(c.app.Config.RemotePrefix+"/"+url.PathEscape(name), input)
+}
+
+// Render requests path, checks that it answered 200 with HTML, and parses
+// the page.
+func (c *Client) Render(path string) *Document {
+	c.t.Helper()
+	res := c.Get(path).AssertStatus(http.StatusOK)
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		c.t.Fatalf("gospatest: GET %s: expected HTML, got Content-Type %q", path, ct)
+	}
+	return res.HTML()
+}
+
+// Do sends req with the client's headers and cookies. Mutating requests get
+// a CSRF token the way the runtime sends one.
+func (c *Client) Do(req *http.Request) *Response {
+	c.t.Helper()
+	for k, vs := range c.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead && req.Header.Get("X-CSRF-Token") == "" {
+		req.Header.Set("X-CSRF-Token", c.csrfToken())
+	}
+	for _, cookie := range c.cookieList() {
+		req.AddCookie(cookie)
+	}
+
+	res, err := c.app.Fiber.Test(req, fiberpkg.TestConfig{Timeout: requestTimeout, FailOnTimeout: true})
+	if err != nil {
+		c.t.Fatalf("gospatest: %s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.t.Fatalf("gospatest: %s %s: read body: %v", req.Method, req.URL.Path, err)
+	}
+	c.storeCookies(res.Cookies())
+	return &Response{t: c.t, Request: req, StatusCode: res.StatusCode, Header: res.Header, Body: body}
+}
+
+// Cookie returns the value of the named cookie, or "".
+func (c *Client) Cookie(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cookie, ok := c.cookies[name]; ok {
+		return cookie.Value
+	}
+	return ""
+}
+
+// csrfToken returns the csrf_token cookie, creating one if the client has
+// not been issued a token yet.
+func (c *Client) csrfToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cookie, ok := c.cookies["csrf_token"]; ok {
+		return cookie.Value
+	}
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+	c.cookies["csrf_token"] = &http.Cookie{Name: "csrf_token", Value: token}
+	return token
+}
+
+func (c *Client) cookieList() []*http.Cookie {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]*http.Cookie, 0, len(c.cookies))
+	for _, cookie := range c.cookies {
+		out = append(out, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return out
+}
+
+func (c *Client) storeCookies(cookies []*http.Cookie) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cookie := range cookies {
+		if cookie.MaxAge < 0 || cookie.Value == "" || (!cookie.Expires.IsZero() && cookie.Expires.Before(time.Now())) {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+		c.cookies[cookie.Name] = cookie
+	}
+}
+
+// Response is a completed request with its body read.
+type Response struct {
+	t          testing.TB
+	Request    *http.Request
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// String returns the body.
+func (r *Response) String() string {
+	return string(r.Body)
+}
+
+// JSON decodes the body into v.
+func (r *Response) JSON(v any) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("gospatest: %s: decode JSON: %v\n%s", r.target(), err, r.Body)
+	}
+}
+
+// Data decodes the "data" field of a remote action result into v.
+func (r *Response) Data(v any) {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	r.JSON(&envelope)
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.t.Fatalf("gospatest: %s: decode data: %v\n%s", r.target(), err, r.Body)
+	}
+}
+
+// AssertStatus fails the test unless the response has status code.
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+	if r.StatusCode != code {
+		r.t.Fatalf("gospatest: %s: status %d, want %d\n%s", r.target(), r.StatusCode, code, truncate(r.Body))
+	}
+	return r
+}
+
+// AssertCode fails the test unless the body is an error envelope with code.
+func (r *Response) AssertCode(code string) *Response {
+	r.t.Helper()
+	var envelope struct {
+		Code string `json:"code"`
+	}
+	r.JSON(&envelope)
+	if envelope.Code != code {
+		r.t.Fatalf("gospatest: %s: code %q, want %q\n%s", r.target(), envelope.Code, code, truncate(r.Body))
+	}
+	return r
+}
+
+// AssertContains fails the test unless the body contains s.
+func (r *Response) AssertContains(s string) *Response {
+	r.t.Helper()
+	if !bytes.Contains(r.Body, []byte(s)) {
+		r.t.Fatalf("gospatest: %s: body does not contain %q\n%s", r.target(), s, truncate(r.Body))
+	}
+	return r
+}
+
+// AssertNotContains fails the test if the body contains s.
+func (r *Response) AssertNotContains(s string) *Response {
+	r.t.Helper()
+	if bytes.Contains(r.Body, []byte(s)) {
+		r.t.Fatalf("gospatest: %s: body contains %q\n%s", r.target(), s, truncate(r.Body))
+	}
+	return r
+}
+
+// AssertHeader fails the test unless header key equals want.
+func (r *Response) AssertHeader(key, want string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != want {
+		r.t.Fatalf("gospatest: %s: header %s = %q, want %q", r.target(), key, got, want)
+	}
+	return r
+}
+
+// HTML parses the body as an HTML document.
+func (r *Response) HTML() *Document {
+	r.t.Helper()
+	return parseDocument(r.t, r.target(), r.Body)
+}
+
+func (r *Response) target() string {
+	return r.Request.Method + " " + r.Request.URL.RequestURI()
+}
+
+// truncate shortens a body for failure messages.
+func truncate(body []byte) string {
+	const limit = 2048
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,120 @@
+package gospatest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func htmlPage(body func() string) routing.ComponentFunc {
+	return func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, body())
+			return err
+		})
+	}
+}
+
+// usePassthroughRootLayout installs a root layout that renders its children
+// unchanged; only pages with a root layout take the ISR caching path.
+func usePassthroughRootLayout(tb testing.TB) {
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(func(children templ.Component, _ map[string]interface{}) templ.Component {
+		return children
+	}, "")
+	tb.Cleanup(func() { routing.RegisterRootLayout(prev, "") })
+}
+
+func TestRenderAndRemote(t *testing.T) {
+	routing.RegisterPage("/gospatest/about", htmlPage(func() string {
+		return `<main><h1 class="title big">About   us</h1><ul><li><a href="/a">A</a></li><li><a href="/b">B</a></li></ul></main>`
+	}))
+	routing.RegisterRemoteAction("gospatest.echo", func(_ context.Context, _ routing.RemoteContext, input interface{}) (interface{}, error) {
+		if input == "fail" {
+			return nil, gospa.ErrConflict
+		}
+		return input, nil
+	})
+
+	app := NewApp(t, gospa.Config{
+		RoutesFS:                          fstest.MapFS{"gospatest/about/page.templ": {}},
+		AllowUnauthenticatedRemoteActions: true,
+		// TestClockDrivesISR registers an ISR route globally.
+		CacheTemplates: true,
+	})
+
+	doc := app.Client.Render("/gospatest/about")
+	doc.AssertText("h1.title", "About us").
+		AssertCount("main ul li a", 2).
+		AssertExists("a[href='/b']")
+	if href, _ := doc.First("li a").Attr("href"); href != "/a" {
+		t.Fatalf("first link href = %q", href)
+	}
+
+	var out string
+	app.Client.Remote("gospatest.echo", "hi").AssertStatus(http.StatusOK).Data(&out)
+	if out != "hi" {
+		t.Fatalf("remote data = %q", out)
+	}
+	app.Client.Remote("gospatest.echo", "fail").AssertStatus(http.StatusConflict).AssertCode(gospa.CodeConflict)
+}
+
+func TestClockDrivesISR(t *testing.T) {
+	var renders atomic.Int32
+	routing.RegisterPageWithOptions("/gospatest/isr", htmlPage(func() string {
+		return fmt.Sprintf("<p>render %d</p>", renders.Add(1))
+	}), routing.RouteOptions{Strategy: routing.StrategyISR, RevalidateAfter: time.Minute})
+	usePassthroughRootLayout(t)
+
+	app := NewApp(t, gospa.Config{
+		RoutesFS:       fstest.MapFS{"gospatest/isr/page.templ": {}},
+		CacheTemplates: true,
+	})
+
+	app.Client.Render("/gospatest/isr").AssertText("p", "render 1")
+	app.Client.Render("/gospatest/isr").AssertText("p", "render 1")
+
+	app.Clock.Advance(2 * time.Minute)
+	app.Client.Render("/gospatest/isr").AssertText("p", "render 1") // stale, revalidates in the background
+
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Client.Render("/gospatest/isr").Text("p") != "render 2" {
+		if time.Now().After(deadline) {
+			t.Fatal("page was not revalidated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWSClient(t *testing.T) {
+	var got atomic.Value
+	fiber.RegisterActionHandler("gospatest.ping", func(_ *fiber.WSClient, payload interface{}) {
+		got.Store(payload)
+	})
+
+	app := NewApp(t, gospa.Config{RoutesFS: fstest.MapFS{}, EnableWebSocket: true, DevMode: true, CacheTemplates: true})
+	ws := app.Client.DialWS()
+
+	init := ws.Init()
+	if init["clientId"] == nil {
+		t.Fatalf("init reply without clientId: %v", init)
+	}
+	sync := ws.Update("count", 3)
+	if sync["key"] != "count" || sync["value"] != float64(3) || sync["success"] != true {
+		t.Fatalf("unexpected sync reply: %v", sync)
+	}
+	ws.Action("gospatest.ping", map[string]any{"n": 1})
+	if payload, _ := got.Load().(map[string]interface{}); payload["n"] != float64(1) {
+		t.Fatalf("action payload = %#v", got.Load())
+	}
+}
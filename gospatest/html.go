@@ -0,0 +1,255 @@
+package gospatest
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// Document is a parsed HTML page. Selectors support tag names, #id, .class,
+// [attr] and [attr=value], combined without spaces ("a.nav[href='/']") and
+// separated by spaces for descendants ("nav li a").
+type Document struct {
+	t      testing.TB
+	target string
+	root   *html.Node
+}
+
+// Element is a node found in a Document.
+type Element struct {
+	node *html.Node
+}
+
+func parseDocument(t testing.TB, target string, body []byte) *Document {
+	t.Helper()
+	root, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gospatest: %s: parse HTML: %v", target, err)
+	}
+	return &Document{t: t, target: target, root: root}
+}
+
+// Find returns the elements matching selector in document order.
+func (d *Document) Find(selector string) []Element {
+	d.t.Helper()
+	steps, err := parseSelector(selector)
+	if err != nil {
+		d.t.Fatalf("gospatest: %v", err)
+	}
+	var out []Element
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && matchPath(n, steps) {
+			out = append(out, Element{node: n})
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(d.root)
+	return out
+}
+
+// First returns the first element matching selector and fails the test if
+// there is none.
+func (d *Document) First(selector string) Element {
+	d.t.Helper()
+	found := d.Find(selector)
+	if len(found) == 0 {
+		d.t.Fatalf("gospatest: %s: no element matches %q", d.target, selector)
+	}
+	return found[0]
+}
+
+// Text returns the text of the first element matching selector.
+func (d *Document) Text(selector string) string {
+	d.t.Helper()
+	return d.First(selector).Text()
+}
+
+// AssertExists fails the test unless selector matches an element.
+func (d *Document) AssertExists(selector string) *Document {
+	d.t.Helper()
+	d.First(selector)
+	return d
+}
+
+// AssertCount fails the test unless selector matches exactly n elements.
+func (d *Document) AssertCount(selector string, n int) *Document {
+	d.t.Helper()
+	if got := len(d.Find(selector)); got != n {
+		d.t.Fatalf("gospatest: %s: %q matches %d elements, want %d", d.target, selector, got, n)
+	}
+	return d
+}
+
+// AssertText fails the test unless the first element matching selector
+// contains text.
+func (d *Document) AssertText(selector, text string) *Document {
+	d.t.Helper()
+	if got := d.Text(selector); !strings.Contains(got, text) {
+		d.t.Fatalf("gospatest: %s: %q has text %q, want it to contain %q", d.target, selector, got, text)
+	}
+	return d
+}
+
+// Text returns the element's text with runs of whitespace collapsed.
+func (e Element) Text() string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteByte(' ')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(e.node)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// Attr returns the value of the named attribute and whether it is present.
+func (e Element) Attr(name string) (string, bool) {
+	for _, a := range e.node.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// HTML returns the element's outer HTML.
+func (e Element) HTML() string {
+	var buf bytes.Buffer
+	_ = html.Render(&buf, e.node)
+	return buf.String()
+}
+
+// compound is one space-separated part of a selector.
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrMatch
+}
+
+type attrMatch struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+func parseSelector(selector string) ([]compound, error) {
+	fields := strings.Fields(selector)
+	if len(fields) == 0 {
+		return nil, selectorError(selector)
+	}
+	steps := make([]compound, 0, len(fields))
+	for _, f := range fields {
+		c, err := parseCompound(f)
+		if err != nil {
+			return nil, selectorError(selector)
+		}
+		steps = append(steps, c)
+	}
+	return steps, nil
+}
+
+type selectorError string
+
+func (e selectorError) Error() string {
+	return "invalid selector " + strconv.Quote(string(e))
+}
+
+func parseCompound(s string) (compound, error) {
+	var c compound
+	i := 0
+	name := func() string {
+		start := i
+		for i < len(s) && !strings.ContainsRune("#.[", rune(s[i])) {
+			i++
+		}
+		return s[start:i]
+	}
+	c.tag = strings.ToLower(name())
+	for i < len(s) {
+		switch s[i] {
+		case '#':
+			i++
+			c.id = name()
+		case '.':
+			i++
+			c.classes = append(c.classes, name())
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return c, selectorError(s)
+			}
+			body := s[i+1 : i+end]
+			i += end + 1
+			key, value, hasValue := strings.Cut(body, "=")
+			c.attrs = append(c.attrs, attrMatch{name: key, value: strings.Trim(value, `"'`), hasValue: hasValue})
+		default:
+			return c, selectorError(s)
+		}
+	}
+	return c, nil
+}
+
+// matchPath reports whether n matches the last step and its ancestors match
+// the earlier steps in order.
+func matchPath(n *html.Node, steps []compound) bool {
+	last := len(steps) - 1
+	if !steps[last].matches(n) {
+		return false
+	}
+	for p, i := n.Parent, last-1; i >= 0; p = p.Parent {
+		if p == nil {
+			return false
+		}
+		if p.Type == html.ElementNode && steps[i].matches(p) {
+			i--
+		}
+	}
+	return true
+}
+
+func (c compound) matches(n *html.Node) bool {
+	if c.tag != "" && c.tag != "*" && n.Data != c.tag {
+		return false
+	}
+	e := Element{node: n}
+	if c.id != "" {
+		if id, _ := e.Attr("id"); id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		class, _ := e.Attr("class")
+		have := strings.Fields(class)
+		for _, want := range c.classes {
+			found := false
+			for _, h := range have {
+				if h == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := e.Attr(a.name)
+		if !ok || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package gospatest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// wsTimeout bounds how long Expect waits for a message.
+const wsTimeout = 5 * time.Second
+
+// Message is a decoded WebSocket frame from the server.
+type Message map[string]any
+
+// Type returns the message's "type".
+func (m Message) Type() string {
+	t, _ := m["type"].(string)
+	return t
+}
+
+// WSClient speaks the runtime's JSON WebSocket protocol (init, update,
+// action) to an App. It shares its Client's cookies, and so its session.
+type WSClient struct {
+	t    testing.TB
+	conn *websocket.Conn
+}
+
+// DialWS opens a WebSocket to the app's WebSocketPath with c's cookies. The
+// app must have EnableWebSocket set. The connection is closed when the test
+// ends.
+func (c *Client) DialWS() *WSClient {
+	c.t.Helper()
+	if c.app.Hub == nil {
+		c.t.Fatal("gospatest: DialWS needs Config.EnableWebSocket")
+	}
+	header := http.Header{}
+	cookies := c.cookieList()
+	parts := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		parts = append(parts, cookie.String())
+	}
+	if len(parts) > 0 {
+		header.Set("Cookie", strings.Join(parts, "; "))
+	}
+	dialer := websocket.Dialer{
+		NetDial:          func(string, string) (net.Conn, error) { return c.app.dial() },
+		HandshakeTimeout: wsTimeout,
+	}
+	conn, res, err := dialer.Dial("ws://gospatest"+c.app.Config.WebSocketPath, header)
+	if err != nil {
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		c.t.Fatalf("gospatest: dial %s: %v (status %d)", c.app.Config.WebSocketPath, err, status)
+	}
+	if res != nil && res.Body != nil {
+		_ = res.Body.Close()
+	}
+	ws := &WSClient{t: c.t, conn: conn}
+	c.t.Cleanup(ws.Close)
+	return ws
+}
+
+// Init sends the "init" handshake and returns the server's "init" reply,
+// whose "state" holds the session state.
+func (w *WSClient) Init() Message {
+	w.t.Helper()
+	w.Send(Message{"type": "init"})
+	return w.Expect("init")
+}
+
+// Update sets a state key and returns the server's "sync" reply.
+func (w *WSClient) Update(key string, value any) Message {
+	w.t.Helper()
+	w.Send(Message{"type": "update", "payload": map[string]any{"key": key, "value": value}})
+	return w.Expect("sync")
+}
+
+// Action runs a registered WebSocket action and returns its "action_ack".
+func (w *WSClient) Action(name string, payload any) Message {
+	w.t.Helper()
+	w.Send(Message{"type": "action", "action": name, "payload": payload})
+	return w.Expect("action_ack")
+}
+
+// Send writes msg as a JSON frame.
+func (w *WSClient) Send(msg any) {
+	w.t.Helper()
+	if err := w.conn.WriteJSON(msg); err != nil {
+		w.t.Fatalf("gospatest: ws send: %v", err)
+	}
+}
+
+// Next returns the next message, failing the test after 5s without one.
+// Compressed frames are decoded.
+func (w *WSClient) Next() Message {
+	w.t.Helper()
+	_ = w.conn.SetReadDeadline(time.Now().Add(wsTimeout))
+	_, data, err := w.conn.ReadMessage()
+	if err != nil {
+		w.t.Fatalf("gospatest: ws read: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.t.Fatalf("gospatest: ws decode %q: %v", data, err)
+	}
+	if msg.Type() == "compressed" {
+		return w.decompress(msg)
+	}
+	return msg
+}
+
+// Expect reads until a message of type typ arrives and returns it. Other
+// messages are skipped, except "error" replies, which fail the test when
+// typ is not "error".
+func (w *WSClient) Expect(typ string) Message {
+	w.t.Helper()
+	for {
+		msg := w.Next()
+		switch msg.Type() {
+		case typ:
+			return msg
+		case "error":
+			w.t.Fatalf("gospatest: ws error while waiting for %q: %v (%v)", typ, msg["error"], msg["code"])
+		}
+	}
+}
+
+// Close closes the connection. It is safe to call more than once.
+func (w *WSClient) Close() {
+	_ = w.conn.Close()
+}
+
+func (w *WSClient) decompress(msg Message) Message {
+	w.t.Helper()
+	encoded, _ := msg["data"].(string)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		w.t.Fatalf("gospatest: ws compressed frame: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		w.t.Fatalf("gospatest: ws compressed frame: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		w.t.Fatalf("gospatest: ws compressed frame: %v", err)
+	}
+	var inner Message
+	if err := json.Unmarshal(data, &inner); err != nil {
+		w.t.Fatalf("gospatest: ws compressed frame: %v", err)
+	}
+	return inner
+}
@@ -0,0 +1,41 @@
+package gospa
+
+import (
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// Handler creates an app from config and returns a Fiber handler serving it
+// from an existing Fiber app, see App.Handler.
+func Handler(config Config) fiberpkg.Handler {
+	return New(config).Handler()
+}
+
+// Handler prepares the app's routes and returns a Fiber handler that serves
+// them, so GoSPA can run inside an existing Fiber app instead of owning the
+// server:
+//
+//	api := fiber.New()
+//	api.Use(logger.New())
+//	api.Get("/api/health", health)
+//	app := gospa.New(gospa.Config{RoutesDir: "./routes"})
+//	api.Use(app.Handler())
+//	defer app.Shutdown()
+//	api.Listen(":3000")
+//
+// Register it after the existing app's own routes: the handler answers every
+// request that reaches it, with a 404 when no GoSPA route matches. Middleware
+// of the existing app runs first and its Locals are visible to Load functions
+// and remote actions. If preparation fails, every request returns that error.
+func (a *App) Handler() fiberpkg.Handler {
+	if err := a.Prepare(); err != nil {
+		a.Logger().Error("GoSPA handler is not serving", "err", err)
+		return func(fiberpkg.Ctx) error {
+			return err
+		}
+	}
+	serve := a.Fiber.Handler()
+	return func(c fiberpkg.Ctx) error {
+		serve(c.RequestCtx())
+		return nil
+	}
+}
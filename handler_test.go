@@ -0,0 +1,77 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/a-h/templ"
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func TestHandlerInExistingFiberApp(t *testing.T) {
+	routing.RegisterPage("/handler-page", func(props map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "hello %v", props["user"])
+			return err
+		})
+	})
+	routing.RegisterLoad("/handler-page", func(c routing.LoadContext) (map[string]interface{}, error) {
+		return map[string]interface{}{"user": c.Local("user")}, nil
+	})
+
+	existing := fiberpkg.New()
+	existing.Use(func(c fiberpkg.Ctx) error {
+		c.Locals("user", "ada")
+		return c.Next()
+	})
+	existing.Get("/api/ping", func(c fiberpkg.Ctx) error {
+		return c.SendString("pong")
+	})
+	app := New(Config{RoutesFS: fstest.MapFS{"handler-page/page.templ": &fstest.MapFile{}}, DevMode: true})
+	defer func() { _ = app.Shutdown() }()
+	existing.Use(app.Handler())
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := existing.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if status, body := get("/api/ping"); status != http.StatusOK || body != "pong" {
+		t.Errorf("existing route: %d %q", status, body)
+	}
+	if status, body := get("/handler-page"); status != http.StatusOK || !strings.Contains(body, "hello ada") {
+		t.Errorf("GoSPA page: %d\n%s", status, body)
+	}
+	if status, _ := get(healthPath); status != http.StatusOK {
+		t.Errorf("internal route: %d", status)
+	}
+	if status, _ := get("/handler-missing"); status != http.StatusNotFound {
+		t.Errorf("unknown path: %d, want 404", status)
+	}
+}
+
+func TestHandlerReportsStartupErrors(t *testing.T) {
+	existing := fiberpkg.New()
+	existing.Use(Handler(Config{Prefork: true, RoutesDir: t.TempDir()}))
+	resp, err := existing.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+}
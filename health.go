@@ -0,0 +1,55 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+const (
+	// healthPath answers liveness probes: the process is up and serving.
+	healthPath = "/_gospa/healthz"
+	// readyPath answers readiness probes: the app started cleanly, is not
+	// shutting down, and can reach its Storage backend.
+	readyPath = "/_gospa/readyz"
+	// readyStorageTimeout bounds the Storage round-trip made by readyPath.
+	readyStorageTimeout = 2 * time.Second
+)
+
+// setupHealthRoutes mounts the liveness and readiness endpoints used by
+// container orchestrators and load balancers.
+func (a *App) setupHealthRoutes() {
+	a.Fiber.Get(healthPath, func(c gofiber.Ctx) error {
+		c.Set("Cache-Control", "no-store")
+		return c.SendString("ok")
+	})
+	a.Fiber.Get(readyPath, func(c gofiber.Ctx) error {
+		c.Set("Cache-Control", "no-store")
+		if err := a.ready(); err != nil {
+			return c.Status(gofiber.StatusServiceUnavailable).SendString(err.Error())
+		}
+		return c.SendString("ok")
+	})
+}
+
+// ready reports why the app should not receive traffic, or nil.
+func (a *App) ready() error {
+	if a.startupErr != nil {
+		return errors.New("startup validation failed")
+	}
+	if a.Context().Err() != nil {
+		return errors.New("shutting down")
+	}
+	if a.Config.Storage == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(a.Context(), readyStorageTimeout)
+	defer cancel()
+	if _, err := a.Config.Storage.Get(ctx, "gospa:readyz"); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return errors.New("storage unavailable")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package gospa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	app := New(Config{})
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	for _, path := range []string{healthPath, readyPath} {
+		res, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", path, err)
+		}
+		if res.StatusCode != fiber.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", path, fiber.StatusOK, res.StatusCode)
+		}
+		if cc := res.Header.Get("Cache-Control"); cc != "no-store" {
+			t.Fatalf("%s: Cache-Control = %q", path, cc)
+		}
+	}
+
+	app.cancel()
+	res, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, readyPath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected readiness to fail during shutdown, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,137 @@
+package gospa
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// Host serves every request for host with tenant, a separate app with its
+// own routes, middleware, render caches, DefaultState and WebSocket hub, so
+// one binary can serve many domains. host is compared case-insensitively
+// with the request's host name without the port (c.Hostname, which follows
+// Fiber's proxy settings); register a tenant under several hosts for aliases
+// such as www. Requests for other hosts are served by a itself.
+//
+// The tenant keeps its sessions and client state in its own Storage, which
+// must not be a's. Call Host before Prepare or Run. Prepare prepares the
+// tenants and Shutdown stops them, so don't run them separately. NewTenant
+// creates a tenant whose Storage and PubSub are namespaces of a's.
+func (a *App) Host(host string, tenant *App) error {
+	host = strings.ToLower(strings.TrimSpace(host))
+	switch {
+	case tenant == nil || tenant == a:
+		return errors.New("gospa: Host needs another app")
+	case host == "" || strings.ContainsAny(host, "/:"):
+		return fmt.Errorf("gospa: Host %q must be a host name without scheme, port or path", host)
+	case a.prepared:
+		return errors.New("gospa: Host must be called before Prepare or Run")
+	case tenant.prepared || tenant.Router.BasePath() != "" || len(tenant.hosts) > 0:
+		return errors.New("gospa: the tenant app is already running, mounted or has hosts of its own")
+	case a.hosts[host] != nil:
+		return fmt.Errorf("gospa: an app is already registered for host %s", host)
+	case tenant.Config.Storage == a.Config.Storage:
+		return fmt.Errorf("gospa: the app for host %s shares this app's Storage; use NewTenant or store.WithPrefix to keep its sessions and state apart", host)
+	}
+	if a.hosts == nil {
+		a.hosts = make(map[string]*App)
+	}
+	// New set the session stores and other package-level settings from the
+	// tenant's Config; a's apply to the whole server. The tenant keeps the
+	// stores New made for it.
+	if err := applyGlobalSettings(&a.Config); err != nil {
+		return fmt.Errorf("gospa: Host %s: %w", host, err)
+	}
+	a.stateStores = fiber.GlobalStateStores()
+	a.hosts[host] = tenant
+	return nil
+}
+
+// NewTenant creates an app from config and serves it for host, see Host. If
+// config leaves Storage or PubSub unset, the tenant uses a's with keys and
+// channels prefixed by "tenant:<host>:", so tenants sharing a backend keep
+// their render caches, sessions, client state, rate limits, jobs and
+// broadcasts apart. Session cookies are per host too. An unset
+// StateRetention and StateReapInterval default to a's, so the tenant's
+// inactive clients are purged like a's.
+func (a *App) NewTenant(host string, config Config) (*App, error) {
+	namespace := "tenant:" + strings.ToLower(strings.TrimSpace(host)) + ":"
+	if config.Storage == nil {
+		config.Storage = store.WithPrefix(a.Config.Storage, namespace)
+	}
+	if config.StateRetention == 0 {
+		config.StateRetention = a.Config.StateRetention
+	}
+	if config.StateReapInterval == 0 {
+		config.StateReapInterval = a.Config.StateReapInterval
+	}
+	if config.PubSub == nil {
+		config.PubSub = store.WithPrefixPubSub(a.Config.PubSub, namespace)
+	}
+	tenant := New(config)
+	if err := a.Host(host, tenant); err != nil {
+		_ = tenant.Shutdown()
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// serveHost hands requests for a host registered with Host to its tenant.
+func (a *App) serveHost(c fiberpkg.Ctx) error {
+	if len(a.hostHandlers) == 0 {
+		return c.Next()
+	}
+	if h := a.hostHandlers[strings.ToLower(c.Hostname())]; h != nil {
+		return h(c)
+	}
+	return c.Next()
+}
+
+// prepareTenants prepares the apps registered with Host and builds the
+// handlers serveHost uses.
+func (a *App) prepareTenants() error {
+	if len(a.hosts) == 0 {
+		return nil
+	}
+	handlers := make(map[string]fiberpkg.Handler, len(a.hosts))
+	for _, host := range a.hostNames() {
+		tenant := a.hosts[host]
+		if err := tenant.Prepare(); err != nil {
+			return fmt.Errorf("tenant %s: %w", host, err)
+		}
+		handlers[host] = tenant.Handler()
+	}
+	a.hostHandlers = handlers
+	return nil
+}
+
+// shutdownTenants shuts down the apps registered with Host.
+func (a *App) shutdownTenants() {
+	done := make(map[*App]bool, len(a.hosts))
+	for _, host := range a.hostNames() {
+		tenant := a.hosts[host]
+		if done[tenant] {
+			continue
+		}
+		done[tenant] = true
+		if err := tenant.Shutdown(); err != nil && !errors.Is(err, fiberpkg.ErrNotRunning) {
+			a.Logger().Error("tenant shutdown failed", "host", host, "err", err)
+		}
+	}
+}
+
+// hostNames returns the hosts registered with Host, sorted.
+func (a *App) hostNames() []string {
+	names := make([]string, 0, len(a.hosts))
+	for host := range a.hosts {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+	return names
+}
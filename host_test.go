@@ -0,0 +1,126 @@
+package gospa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func TestHost(t *testing.T) {
+	routing.RegisterPage("/host-docs", textComponent("docs tenant"))
+	routing.RegisterPage("/host-main", textComponent("main app"))
+
+	app := New(Config{RoutesFS: fstest.MapFS{"host-main/page.templ": &fstest.MapFile{}}, DevMode: true})
+	docs, err := app.NewTenant("Docs.Example.com", Config{
+		RoutesFS:     fstest.MapFS{"host-docs/page.templ": &fstest.MapFile{}},
+		DevMode:      true,
+		DefaultState: map[string]interface{}{"theme": "dark"},
+	})
+	if err != nil {
+		t.Fatalf("NewTenant: %v", err)
+	}
+	if err := app.Host("www.docs.example.com", docs); err != nil {
+		t.Fatalf("Host alias: %v", err)
+	}
+	if err := app.Host("docs.example.com", New(Config{RoutesFS: fstest.MapFS{}, DevMode: true})); err == nil {
+		t.Fatal("second app for the same host was accepted")
+	}
+	if err := app.Host("https://x.example.com", docs); err == nil {
+		t.Fatal("host with scheme was accepted")
+	}
+	if err := app.Host("shared.example.com", New(Config{RoutesFS: fstest.MapFS{}, DevMode: true, Storage: app.Config.Storage})); err == nil {
+		t.Fatal("tenant sharing the parent's Storage was accepted")
+	}
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+
+	get := func(host, path string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		resp, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("GET %s%s: %v", host, path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	for _, tt := range []struct {
+		host, path string
+		status     int
+		want       string
+	}{
+		{"docs.example.com:8080", "/host-docs", http.StatusOK, "docs tenant"},
+		{"www.docs.example.com", "/host-docs", http.StatusOK, "docs tenant"},
+		{"docs.example.com", "/host-main", http.StatusNotFound, ""},
+		{"example.com", "/host-main", http.StatusOK, "main app"},
+		{"example.com", "/host-docs", http.StatusNotFound, ""},
+	} {
+		status, body := get(tt.host, tt.path)
+		if status != tt.status || !strings.Contains(body, tt.want) {
+			t.Errorf("GET %s%s = %d, want %d with %q:\n%s", tt.host, tt.path, status, tt.status, tt.want, body)
+		}
+	}
+
+	// The tenant's keys live in a namespace of the parent's Storage.
+	ctx := context.Background()
+	if store.Unwrap(docs.Config.Storage) != app.Config.Storage {
+		t.Fatal("tenant Storage does not wrap the parent's")
+	}
+	_ = docs.Config.Storage.Set(ctx, "k", []byte("v"), 0)
+	if v, _ := app.Config.Storage.Get(ctx, "tenant:docs.example.com:k"); string(v) != "v" {
+		t.Fatalf("namespaced key = %q", v)
+	}
+	if _, err := app.Config.Storage.Get(ctx, "k"); err == nil {
+		t.Fatal("tenant key leaked into the parent's namespace")
+	}
+
+	// Sessions and client state are per tenant.
+	token, err := docs.stateStores.Sessions.CreateSession("docs-client")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, ok := app.stateStores.Sessions.ValidateSession(token); ok {
+		t.Fatal("tenant session token is valid for the parent app")
+	}
+	if id, ok := docs.stateStores.Sessions.ValidateSession(token); !ok || id != "docs-client" {
+		t.Fatalf("tenant session = %q, %v", id, ok)
+	}
+	docs.stateStores.ClientState.Save("docs-client", docs.StateMap)
+	if _, err := app.Config.Storage.Get(ctx, "tenant:docs.example.com:state:docs-client"); err != nil {
+		t.Fatalf("tenant client state is not namespaced: %v", err)
+	}
+	if _, ok := app.stateStores.ClientState.Get("docs-client"); ok {
+		t.Fatal("tenant client state is visible to the parent app")
+	}
+	if n, err := fiber.RevokeClientSessions("docs-client"); err != nil || n != 0 {
+		t.Fatalf("global revoke = %d, %v", n, err)
+	}
+	if n, err := docs.RevokeClientSessions("docs-client"); err != nil || n != 1 {
+		t.Fatalf("tenant revoke = %d, %v", n, err)
+	}
+	if _, ok := docs.stateStores.Sessions.ValidateSession(token); ok {
+		t.Fatal("revoked tenant session is still valid")
+	}
+
+	var summary strings.Builder
+	app.writeStartupSummary(&summary)
+	if !strings.Contains(summary.String(), "Hosts           docs.example.com, www.docs.example.com") {
+		t.Errorf("summary does not list the hosts:\n%s", summary.String())
+	}
+
+	_ = app.Shutdown()
+	if docs.Context().Err() == nil {
+		t.Fatal("Shutdown did not stop the tenant")
+	}
+}
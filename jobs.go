@@ -0,0 +1,35 @@
+package gospa
+
+import (
+	"github.com/aydenstechdungeon/gospa/jobs"
+	"github.com/aydenstechdungeon/gospa/store"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// newJobQueue builds App.Jobs. Without a JobBackend, jobs persist in Storage
+// when it is shared between instances, and in process memory otherwise.
+func newJobQueue(config *Config) *jobs.Queue {
+	backend := config.JobBackend
+	if backend == nil && config.Storage != nil && !isInMemoryStorage(config.Storage) {
+		if _, ok := config.Storage.(store.SetStorage); ok {
+			backend, _ = jobs.NewStorageBackend(config.Storage)
+		}
+	}
+	return jobs.New(jobs.Config{
+		Backend: backend,
+		Workers: config.JobWorkers,
+		Logger:  config.Logger,
+	})
+}
+
+// handleJobStats serves queue depth for the dev panel's Jobs tab.
+func (a *App) handleJobStats(c fiberpkg.Ctx) error {
+	stats, err := a.Jobs.Stats(c.Context())
+	if err != nil {
+		return a.sendError(c, NewError(CodeJobStatsFailed, fiberpkg.StatusInternalServerError, "Failed to read job stats").Wrap(err))
+	}
+	return c.JSON(fiberpkg.Map{
+		"stats":    stats,
+		"handlers": a.Jobs.Handlers(),
+	})
+}
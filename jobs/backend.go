@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Backend stores jobs for a Queue. Implementations must be safe for
+// concurrent use.
+type Backend interface {
+	// Push stores job to run at job.RunAt, replacing a pending or claimed
+	// job with the same ID.
+	Push(ctx context.Context, job *Job) error
+	// Claim marks the job among names due earliest at or before now as
+	// claimed and returns it, or returns nil when none is due. A job claimed
+	// longer than lease ago is claimable again.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, names []string) (*Job, error)
+	// Complete removes a finished job.
+	Complete(ctx context.Context, id string) error
+	// Bury moves a job that ran out of attempts to the dead-letter list.
+	Bury(ctx context.Context, job *Job) error
+	// Stats counts jobs by state as of now.
+	Stats(ctx context.Context, now time.Time) (Stats, error)
+}
+
+// defaultMaxDead bounds the dead-letter list of the built-in backends.
+const defaultMaxDead = 1000
+
+// MemoryBackend keeps jobs in process memory. Jobs are lost on restart.
+type MemoryBackend struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+	dead []*Job
+}
+
+// NewMemoryBackend returns an empty in-memory backend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{jobs: make(map[string]*Job)}
+}
+
+// Push implements Backend.
+func (m *MemoryBackend) Push(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *job
+	m.jobs[job.ID] = &stored
+	return nil
+}
+
+// Claim implements Backend.
+func (m *MemoryBackend) Claim(_ context.Context, now time.Time, lease time.Duration, names []string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var next *Job
+	for _, job := range m.jobs {
+		if claimable(job, now, lease, names) && (next == nil || job.RunAt.Before(next.RunAt)) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.ClaimedAt = now
+	claimed := *next
+	return &claimed, nil
+}
+
+// Complete implements Backend.
+func (m *MemoryBackend) Complete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+// Bury implements Backend.
+func (m *MemoryBackend) Bury(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, job.ID)
+	dead := *job
+	m.dead = append(m.dead, &dead)
+	if len(m.dead) > defaultMaxDead {
+		m.dead = m.dead[len(m.dead)-defaultMaxDead:]
+	}
+	return nil
+}
+
+// Dead returns the dead-letter jobs, oldest first.
+func (m *MemoryBackend) Dead() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Job, len(m.dead))
+	for i, job := range m.dead {
+		out[i] = *job
+	}
+	return out
+}
+
+// Stats implements Backend.
+func (m *MemoryBackend) Stats(_ context.Context, now time.Time) (Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := Stats{Dead: len(m.dead), ByName: make(map[string]int)}
+	for _, job := range m.jobs {
+		countJob(&stats, job, now)
+	}
+	return stats, nil
+}
+
+// claimable reports whether job may be claimed by a worker handling names.
+func claimable(job *Job, now time.Time, lease time.Duration, names []string) bool {
+	if job.RunAt.After(now) || !slices.Contains(names, job.Name) {
+		return false
+	}
+	return job.ClaimedAt.IsZero() || now.Sub(job.ClaimedAt) >= lease
+}
+
+// countJob adds a pending or claimed job to stats.
+func countJob(stats *Stats, job *Job, now time.Time) {
+	switch {
+	case !job.ClaimedAt.IsZero():
+		stats.Running++
+		return
+	case job.RunAt.After(now):
+		stats.Scheduled++
+	default:
+		stats.Ready++
+	}
+	stats.ByName[job.Name]++
+}
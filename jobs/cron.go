@@ -0,0 +1,177 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule enqueues a job whenever its cron spec matches.
+type schedule struct {
+	spec    Cron
+	name    string
+	payload interface{}
+}
+
+// Schedule enqueues a job named name with payload whenever spec matches, in
+// local time. spec is a five-field cron expression (minute hour day-of-month
+// month day-of-week) supporting *, lists, ranges, and steps, or one of
+// @hourly, @daily, @weekly, @monthly, and @yearly. Schedules fire in every
+// process that starts the queue, so register them on one instance when
+// several share a backend.
+func (q *Queue) Schedule(spec, name string, payload interface{}) error {
+	parsed, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, &schedule{spec: parsed, name: name, payload: payload})
+	return nil
+}
+
+// runSchedules checks the schedules at the start of every minute.
+func (q *Queue) runSchedules(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		q.mu.RLock()
+		due := make([]*schedule, 0, len(q.schedules))
+		for _, s := range q.schedules {
+			if s.spec.Matches(next) {
+				due = append(due, s)
+			}
+		}
+		q.mu.RUnlock()
+		for _, s := range due {
+			if _, err := q.EnqueueContext(ctx, s.name, s.payload); err != nil {
+				q.config.Logger.Error("jobs: scheduled enqueue failed", "job", s.name, "err", err)
+			}
+		}
+	}
+}
+
+// Cron is a parsed cron expression. It holds one bit per allowed value of
+// each field.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field; when both day fields are
+	// restricted, either may match, as in standard cron.
+	domAny, dowAny bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a five-field cron expression or descriptor as accepted by
+// Queue.Schedule.
+func ParseCron(spec string) (Cron, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("jobs: cron spec %q must have 5 fields", spec)
+	}
+	var c Cron
+	var err error
+	bounds := []struct {
+		dst      *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	}
+	for i, b := range bounds {
+		if *b.dst, err = parseCronField(fields[i], b.min, b.max); err != nil {
+			return Cron{}, fmt.Errorf("jobs: cron spec %q: %w", spec, err)
+		}
+	}
+	// Sunday is both 0 and 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField parses a comma-separated list of *, n, a-b, with an
+// optional /step, into a bit set.
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var errA, errB error
+			start, errA = strconv.Atoi(a)
+			end, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = n, n
+			if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches reports whether t, truncated to the minute, fires the spec.
+func (c Cron) Matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		ts, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		spec string
+		at   string
+		want bool
+	}{
+		{"* * * * *", "2026-03-04 05:06", true},
+		{"*/15 * * * *", "2026-03-04 05:30", true},
+		{"*/15 * * * *", "2026-03-04 05:31", false},
+		{"0 9-17 * * 1-5", "2026-03-04 12:00", true},  // Wednesday
+		{"0 9-17 * * 1-5", "2026-03-07 12:00", false}, // Saturday
+		{"30 2 1,15 * *", "2026-03-15 02:30", true},
+		{"0 0 * * 7", "2026-03-08 00:00", true},  // Sunday as 7
+		{"0 0 13 * 5", "2026-03-13 00:00", true}, // Friday the 13th: either day field
+		{"0 0 13 * 5", "2026-03-06 00:00", true},
+		{"0 0 13 * 5", "2026-03-05 00:00", false},
+		{"@daily", "2026-03-04 00:00", true},
+		{"@hourly", "2026-03-04 05:01", false},
+	}
+	for _, tt := range tests {
+		spec, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.spec, err)
+		}
+		if got := spec.Matches(at(tt.at)); got != tt.want {
+			t.Errorf("%q at %s = %v, want %v", tt.spec, tt.at, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", bad)
+		}
+	}
+}
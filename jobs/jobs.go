@@ -0,0 +1,370 @@
+// Package jobs provides a background job queue for GoSPA applications.
+//
+// Handlers are registered by name and run by a pool of workers. Failed jobs
+// are retried with exponential backoff and moved to a dead-letter list once
+// they run out of attempts. Jobs are delivered at least once, so handlers
+// should be idempotent.
+//
+//	app.Jobs.Register("email.welcome", func(ctx context.Context, job *jobs.Job) error {
+//		var p WelcomePayload
+//		if err := job.Bind(&p); err != nil {
+//			return err
+//		}
+//		return sendWelcome(ctx, p)
+//	})
+//	_, err := app.Jobs.Enqueue("email.welcome", WelcomePayload{UserID: id})
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	mrand "math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Handler runs a job. Returning an error schedules a retry.
+type Handler func(ctx context.Context, job *Job) error
+
+// Job is a unit of work stored in a Backend.
+type Job struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Attempt counts the failed runs so far.
+	Attempt     int       `json:"attempt"`
+	MaxAttempts int       `json:"maxAttempts"`
+	RunAt       time.Time `json:"runAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastError   string    `json:"lastError,omitempty"`
+	// ClaimedAt is set by the Backend while a worker runs the job.
+	ClaimedAt time.Time `json:"claimedAt"`
+}
+
+// Bind decodes the job payload into v.
+func (j *Job) Bind(v interface{}) error {
+	if len(j.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(j.Payload, v)
+}
+
+// EnqueueOptions controls when and how often a job runs.
+type EnqueueOptions struct {
+	// Delay postpones the first run.
+	Delay time.Duration
+	// RunAt schedules the first run at a fixed time; it takes precedence
+	// over Delay.
+	RunAt time.Time
+	// MaxAttempts overrides Config.MaxAttempts for this job.
+	MaxAttempts int
+}
+
+// Config configures a Queue.
+type Config struct {
+	// Backend stores jobs (default: NewMemoryBackend()).
+	Backend Backend
+	// Workers is the number of jobs run concurrently (default 4).
+	Workers int
+	// PollInterval is how often idle workers check the backend for due jobs
+	// (default 1s). Jobs enqueued in this process wake a worker immediately.
+	PollInterval time.Duration
+	// Lease is how long a claimed job may run before the backend hands it to
+	// another worker, and the handler's timeout (default 5 minutes).
+	Lease time.Duration
+	// MaxAttempts is how many times a job runs before it is moved to the
+	// dead-letter list (default 5).
+	MaxAttempts int
+	// Backoff returns the delay before retry number attempt (1-based).
+	// Default: exponential from 1s, capped at 1h, with jitter.
+	Backoff func(attempt int) time.Duration
+	// Logger receives worker errors (default slog.Default()).
+	Logger *slog.Logger
+}
+
+// Stats describes the queue for monitoring and the dev panel.
+type Stats struct {
+	Ready     int `json:"ready"`     // Due and waiting for a worker
+	Scheduled int `json:"scheduled"` // Waiting for their run time, including retries
+	Running   int `json:"running"`   // Claimed by a worker
+	Dead      int `json:"dead"`      // Out of attempts
+	// ByName counts ready and scheduled jobs per job name.
+	ByName map[string]int `json:"byName"`
+	// Processed and Failed count runs in this process since Start.
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+}
+
+// Queue enqueues jobs and runs them on a worker pool.
+type Queue struct {
+	config Config
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []*schedule
+
+	wake      chan struct{}
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+// New returns a Queue. Call Start to run workers; a Queue that is never
+// started can still enqueue jobs for workers in another process.
+func New(config Config) *Queue {
+	if config.Backend == nil {
+		config.Backend = NewMemoryBackend()
+	}
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.Lease <= 0 {
+		config.Lease = 5 * time.Minute
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.Backoff == nil {
+		config.Backoff = DefaultBackoff
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &Queue{
+		config:   config,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// DefaultBackoff waits 2^(attempt-1) seconds, capped at one hour, plus up to
+// 20% jitter so retries of a failed batch spread out.
+func DefaultBackoff(attempt int) time.Duration {
+	d := time.Hour
+	if attempt < 13 {
+		d = min(time.Second<<max(attempt-1, 0), time.Hour)
+	}
+	return d + time.Duration(mrand.Int64N(int64(d)/5+1)) // #nosec G404 -- jitter
+}
+
+// Register sets the handler for jobs named name. Workers only claim jobs
+// whose name has a handler in this process.
+func (q *Queue) Register(name string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[name] = h
+}
+
+// Handlers returns the registered job names, sorted.
+func (q *Queue) Handlers() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	names := make([]string, 0, len(q.handlers))
+	for name := range q.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (q *Queue) handler(name string) Handler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[name]
+}
+
+// Enqueue stores a job named name with payload encoded as JSON.
+func (q *Queue) Enqueue(name string, payload interface{}, opts ...EnqueueOptions) (*Job, error) {
+	return q.EnqueueContext(context.Background(), name, payload, opts...)
+}
+
+// EnqueueContext is Enqueue with a context for the backend call.
+func (q *Queue) EnqueueContext(ctx context.Context, name string, payload interface{}, opts ...EnqueueOptions) (*Job, error) {
+	if name == "" {
+		return nil, errors.New("jobs: job name is required")
+	}
+	var opt EnqueueOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var raw json.RawMessage
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("jobs: encode payload for %q: %w", name, err)
+		}
+		raw = data
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:          newJobID(),
+		Name:        name,
+		Payload:     raw,
+		MaxAttempts: opt.MaxAttempts,
+		RunAt:       now.Add(opt.Delay),
+		CreatedAt:   now,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.config.MaxAttempts
+	}
+	if !opt.RunAt.IsZero() {
+		job.RunAt = opt.RunAt
+	}
+	if err := q.config.Backend.Push(ctx, job); err != nil {
+		return nil, fmt.Errorf("jobs: enqueue %q: %w", name, err)
+	}
+	if !job.RunAt.After(now) {
+		q.notify()
+	}
+	return job, nil
+}
+
+// Stats reports queue depth from the backend and this process's counters.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	stats, err := q.config.Backend.Stats(ctx, time.Now())
+	stats.Processed = q.processed.Load()
+	stats.Failed = q.failed.Load()
+	return stats, err
+}
+
+// Start runs the workers and the cron scheduler until ctx is canceled or
+// Stop is called. Calling Start on a running queue does nothing.
+func (q *Queue) Start(ctx context.Context) {
+	q.mu.Lock()
+	if q.cancel != nil {
+		q.mu.Unlock()
+		return
+	}
+	ctx, q.cancel = context.WithCancel(ctx)
+	q.mu.Unlock()
+
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+	q.wg.Add(1)
+	go q.runSchedules(ctx)
+}
+
+// Stop stops claiming jobs, cancels running handlers, and waits for the
+// workers to return. Interrupted jobs go back to the queue without using an
+// attempt.
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	cancel := q.cancel
+	q.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	q.wg.Wait()
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	timer := time.NewTimer(q.config.PollInterval)
+	defer timer.Stop()
+
+	for ctx.Err() == nil {
+		job, err := q.config.Backend.Claim(ctx, time.Now(), q.config.Lease, q.Handlers())
+		if err != nil && ctx.Err() == nil {
+			q.config.Logger.Error("jobs: claim failed", "err", err)
+		}
+		if job != nil {
+			q.run(ctx, job)
+			continue
+		}
+		timer.Reset(q.config.PollInterval)
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// run executes job and records the outcome in the backend.
+func (q *Queue) run(ctx context.Context, job *Job) {
+	// Backend calls use a context that outlives Stop so outcomes are saved.
+	bg := context.WithoutCancel(ctx)
+	h := q.handler(job.Name)
+	if h == nil {
+		// Unregistered after the claim; leave it for another worker.
+		job.ClaimedAt = time.Time{}
+		_ = q.config.Backend.Push(bg, job)
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, q.config.Lease)
+	err := callHandler(runCtx, h, job)
+	cancel()
+
+	switch {
+	case err == nil:
+		q.processed.Add(1)
+		if err := q.config.Backend.Complete(bg, job.ID); err != nil {
+			q.config.Logger.Error("jobs: complete failed", "job", job.Name, "id", job.ID, "err", err)
+		}
+		return
+	case ctx.Err() != nil:
+		// Stopped mid-run: requeue without spending an attempt.
+		job.ClaimedAt = time.Time{}
+		if err := q.config.Backend.Push(bg, job); err != nil {
+			q.config.Logger.Error("jobs: requeue failed", "job", job.Name, "id", job.ID, "err", err)
+		}
+		return
+	}
+
+	q.failed.Add(1)
+	job.Attempt++
+	job.LastError = err.Error()
+	job.ClaimedAt = time.Time{}
+	if job.Attempt >= job.MaxAttempts {
+		q.config.Logger.Error("jobs: job failed permanently", "job", job.Name, "id", job.ID, "attempts", job.Attempt, "err", err)
+		if err := q.config.Backend.Bury(bg, job); err != nil {
+			q.config.Logger.Error("jobs: bury failed", "job", job.Name, "id", job.ID, "err", err)
+		}
+		return
+	}
+	job.RunAt = time.Now().Add(q.config.Backoff(job.Attempt))
+	q.config.Logger.Warn("jobs: job failed, retrying", "job", job.Name, "id", job.ID, "attempt", job.Attempt, "retryAt", job.RunAt, "err", err)
+	if err := q.config.Backend.Push(bg, job); err != nil {
+		q.config.Logger.Error("jobs: retry failed", "job", job.Name, "id", job.ID, "err", err)
+	}
+}
+
+// callHandler runs h, turning a panic into an error.
+func callHandler(ctx context.Context, h Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jobs: handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
+
+func newJobID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	q := New(Config{PollInterval: 10 * time.Millisecond})
+	var got atomic.Value
+	q.Register("greet", func(_ context.Context, job *Job) error {
+		var p struct{ Name string }
+		if err := job.Bind(&p); err != nil {
+			return err
+		}
+		got.Store(p.Name)
+		return nil
+	})
+	q.Start(context.Background())
+	defer q.Stop()
+
+	if _, err := q.Enqueue("greet", map[string]string{"Name": "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return got.Load() == "ada" })
+
+	stats, err := q.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Processed != 1 || stats.Ready != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestQueueRetriesThenBuries(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := New(Config{
+		Backend:      backend,
+		PollInterval: 5 * time.Millisecond,
+		Backoff:      func(int) time.Duration { return 0 },
+	})
+	var runs atomic.Int32
+	q.Register("flaky", func(context.Context, *Job) error {
+		runs.Add(1)
+		return errors.New("boom")
+	})
+	q.Start(context.Background())
+	defer q.Stop()
+
+	if _, err := q.Enqueue("flaky", nil, EnqueueOptions{MaxAttempts: 3}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(backend.Dead()) == 1 })
+	if runs.Load() != 3 {
+		t.Fatalf("ran %d times, want 3", runs.Load())
+	}
+	dead := backend.Dead()[0]
+	if dead.Attempt != 3 || dead.LastError != "boom" {
+		t.Fatalf("dead job = %+v", dead)
+	}
+}
+
+func TestQueueDelayAndUnregisteredNames(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := New(Config{Backend: backend})
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := q.Enqueue("later", nil, EnqueueOptions{Delay: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue("other", nil); err != nil {
+		t.Fatal(err)
+	}
+	job, err := backend.Claim(ctx, now, time.Minute, []string{"later"})
+	if err != nil || job != nil {
+		t.Fatalf("claimed a job before its run time: %+v, %v", job, err)
+	}
+	stats, _ := q.Stats(ctx)
+	if stats.Scheduled != 1 || stats.Ready != 1 || stats.ByName["other"] != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestMemoryBackendLease(t *testing.T) {
+	backend := NewMemoryBackend()
+	ctx := context.Background()
+	now := time.Now()
+	_ = backend.Push(ctx, &Job{ID: "1", Name: "a", RunAt: now})
+
+	if job, _ := backend.Claim(ctx, now, time.Minute, []string{"a"}); job == nil {
+		t.Fatal("expected a claim")
+	}
+	if job, _ := backend.Claim(ctx, now.Add(time.Second), time.Minute, []string{"a"}); job != nil {
+		t.Fatal("claimed job was handed out again within its lease")
+	}
+	if job, _ := backend.Claim(ctx, now.Add(2*time.Minute), time.Minute, []string{"a"}); job == nil {
+		t.Fatal("expired lease was not reclaimed")
+	}
+}
+
+func TestStorageBackend(t *testing.T) {
+	storage := store.NewMemoryStorage()
+	backend, err := NewStorageBackend(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	_ = backend.Push(ctx, &Job{ID: "1", Name: "a", RunAt: now.Add(-time.Second)})
+	_ = backend.Push(ctx, &Job{ID: "2", Name: "a", RunAt: now.Add(-time.Minute)})
+
+	// A second backend on the same storage sees the same jobs.
+	other, _ := NewStorageBackend(storage)
+	job, err := other.Claim(ctx, now, time.Minute, []string{"a"})
+	if err != nil || job == nil || job.ID != "2" {
+		t.Fatalf("Claim() = %+v, %v; want job 2", job, err)
+	}
+	if err := other.Complete(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	job, _ = backend.Claim(ctx, now, time.Minute, []string{"a"})
+	if job == nil || job.ID != "1" {
+		t.Fatalf("Claim() = %+v, want job 1", job)
+	}
+	if err := backend.Bury(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := backend.Stats(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Dead != 1 || stats.Ready+stats.Scheduled+stats.Running != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	if d := DefaultBackoff(1); d < time.Second || d > 1200*time.Millisecond {
+		t.Fatalf("DefaultBackoff(1) = %v", d)
+	}
+	if d := DefaultBackoff(40); d < time.Hour || d > 72*time.Minute {
+		t.Fatalf("DefaultBackoff(40) = %v", d)
+	}
+}
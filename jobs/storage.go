@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+)
+
+const storageKeyPrefix = "gospa:jobs:"
+
+// StorageBackend persists jobs in a store.Storage that also implements
+// store.SetStorage, such as the Redis store, so queued jobs survive restarts
+// and are visible to every instance sharing it. Each job is one key, indexed
+// by a set; claims scan the index, so it suits queues of up to a few
+// thousand pending jobs. Claims are serialized within a process only, so two
+// instances can occasionally claim the same job; handlers must be idempotent.
+type StorageBackend struct {
+	storage store.Storage
+	sets    store.SetStorage
+	mu      sync.Mutex
+}
+
+// NewStorageBackend returns a backend persisting jobs in storage.
+func NewStorageBackend(storage store.Storage) (*StorageBackend, error) {
+	sets, ok := storage.(store.SetStorage)
+	if !ok {
+		return nil, fmt.Errorf("jobs: storage %T does not implement store.SetStorage", storage)
+	}
+	return &StorageBackend{storage: storage, sets: sets}, nil
+}
+
+func jobKey(id string) string { return storageKeyPrefix + "job:" + id }
+
+const (
+	pendingKey = storageKeyPrefix + "pending"
+	deadKey    = storageKeyPrefix + "dead"
+)
+
+// Push implements Backend.
+func (s *StorageBackend) Push(ctx context.Context, job *Job) error {
+	if err := s.save(ctx, job); err != nil {
+		return err
+	}
+	return s.sets.SAdd(ctx, pendingKey, job.ID, 0)
+}
+
+// Claim implements Backend.
+func (s *StorageBackend) Claim(ctx context.Context, now time.Time, lease time.Duration, names []string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next *Job
+	err := s.scan(ctx, func(job *Job) {
+		if claimable(job, now, lease, names) && (next == nil || job.RunAt.Before(next.RunAt)) {
+			next = job
+		}
+	})
+	if err != nil || next == nil {
+		return nil, err
+	}
+	next.ClaimedAt = now
+	if err := s.save(ctx, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Complete implements Backend.
+func (s *StorageBackend) Complete(ctx context.Context, id string) error {
+	if err := s.sets.SRem(ctx, pendingKey, id); err != nil {
+		return err
+	}
+	return ignoreNotFound(s.storage.Delete(ctx, jobKey(id)))
+}
+
+// Bury implements Backend. Dead jobs are kept until deleted from Storage.
+func (s *StorageBackend) Bury(ctx context.Context, job *Job) error {
+	if err := s.save(ctx, job); err != nil {
+		return err
+	}
+	if err := s.sets.SRem(ctx, pendingKey, job.ID); err != nil {
+		return err
+	}
+	return s.sets.SAdd(ctx, deadKey, job.ID, 0)
+}
+
+// Stats implements Backend.
+func (s *StorageBackend) Stats(ctx context.Context, now time.Time) (Stats, error) {
+	stats := Stats{ByName: make(map[string]int)}
+	if err := s.scan(ctx, func(job *Job) { countJob(&stats, job, now) }); err != nil {
+		return stats, err
+	}
+	dead, err := s.sets.SMembers(ctx, deadKey)
+	stats.Dead = len(dead)
+	return stats, err
+}
+
+// scan calls fn for every pending job, dropping index entries whose job is
+// gone.
+func (s *StorageBackend) scan(ctx context.Context, fn func(*Job)) error {
+	ids, err := s.sets.SMembers(ctx, pendingKey)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		data, err := s.storage.Get(ctx, jobKey(id))
+		if errors.Is(err, store.ErrNotFound) {
+			_ = s.sets.SRem(ctx, pendingKey, id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			continue
+		}
+		fn(&job)
+	}
+	return nil
+}
+
+func (s *StorageBackend) save(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.storage.Set(ctx, jobKey(job.ID), data, 0)
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,219 @@
+package gospa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+)
+
+// HTTP3Server is an HTTP/3 server bound to its handler, such as *http3.Server
+// from github.com/quic-go/quic-go/http3.
+type HTTP3Server interface {
+	ListenAndServeTLS(certFile, keyFile string) error
+	Close() error
+}
+
+// HTTP3Factory returns an HTTP3Server listening on addr (UDP) and serving h:
+//
+//	func(addr string, h http.Handler) gospa.HTTP3Server {
+//		return &http3.Server{Addr: addr, Handler: h}
+//	}
+type HTTP3Factory func(addr string, h http.Handler) HTTP3Server
+
+// httpReadHeaderTimeout bounds request header reads on net/http listeners.
+const httpReadHeaderTimeout = 10 * time.Second
+
+// RunH2C starts the app on addr with HTTP/1.1 and cleartext HTTP/2 (h2c),
+// for deployments behind a proxy or load balancer that speaks HTTP/2 to its
+// backends. Requests are served through net/http; WebSocket upgrades are
+// handed to Fiber's own server on the same connection.
+func (a *App) RunH2C(addr string) error {
+	if err := a.Prepare(); err != nil {
+		return err
+	}
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv := a.newHTTPServer(addr, a.netHTTPHandler(""))
+	srv.Protocols = protocols
+
+	a.Logger().Info("starting GoSPA (h2c)", "version", Version, "addr", addr)
+	a.printStartupSummary()
+	return ignoreServerClosed(srv.ListenAndServe())
+}
+
+// RunHTTP3 starts the app on addr with TLS over TCP (HTTP/1.1 and HTTP/2)
+// and, experimentally, HTTP/3 over QUIC on the same port using the server
+// built by newHTTP3. TCP responses advertise HTTP/3 with an Alt-Svc header so
+// browsers switch on their next request.
+func (a *App) RunHTTP3(addr, certFile, keyFile string, newHTTP3 HTTP3Factory) error {
+	if newHTTP3 == nil {
+		return errors.New("gospa: RunHTTP3 requires an HTTP3Factory")
+	}
+	if err := a.Prepare(); err != nil {
+		return err
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("gospa: invalid address %q: %w", addr, err)
+	}
+
+	h3 := newHTTP3(addr, a.netHTTPHandler(""))
+	tcp := a.newHTTPServer(addr, a.netHTTPHandler(`h3=":`+port+`"; ma=86400`))
+	a.trackHTTPCloser(h3)
+
+	errc := make(chan error, 2)
+	go func() { errc <- ignoreServerClosed(h3.ListenAndServeTLS(certFile, keyFile)) }()
+	go func() { errc <- ignoreServerClosed(tcp.ListenAndServeTLS(certFile, keyFile)) }()
+
+	a.Logger().Info("starting GoSPA (TLS + HTTP/3)", "version", Version, "addr", addr)
+	a.printStartupSummary()
+	err = <-errc
+	// One listener stopped; take the other down with it.
+	_ = h3.Close()
+	_ = tcp.Close()
+	if err2 := <-errc; err == nil {
+		err = err2
+	}
+	return err
+}
+
+// newHTTPServer returns a net/http server for h, tracked so Shutdown stops it.
+func (a *App) newHTTPServer(addr string, h http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+	a.trackHTTPCloser(srv)
+	return srv
+}
+
+func (a *App) trackHTTPCloser(c io.Closer) {
+	a.httpClosersMu.Lock()
+	a.httpClosers = append(a.httpClosers, c)
+	a.httpClosersMu.Unlock()
+}
+
+// closeHTTPServers stops the listeners started by RunH2C and RunHTTP3.
+func (a *App) closeHTTPServers() {
+	a.httpClosersMu.Lock()
+	closers := a.httpClosers
+	a.httpClosers = nil
+	a.httpClosersMu.Unlock()
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			a.Logger().Error("listener close failed", "err", err)
+		}
+	}
+}
+
+// HTTPHandler prepares the app's routes and returns them as a net/http
+// handler, for servers and routers built on the standard library:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/api/", api)
+//	mux.Handle("/", app.HTTPHandler())
+//	http.ListenAndServe(":3000", mux)
+//
+// Pages, render strategies, remote actions and the WebSocket state protocol
+// work as with Run. WebSocket upgrades take over the HTTP/1.1 connection, so
+// middleware wrapping the ResponseWriter must keep hijacking reachable (by
+// implementing http.Hijacker or Unwrap). Values that net/http middleware put
+// in the request context are not visible to GoSPA; pass them as request
+// headers. If preparation fails, the error is logged and every request gets
+// a 500.
+func (a *App) HTTPHandler() http.Handler {
+	if err := a.Prepare(); err != nil {
+		a.Logger().Error("GoSPA handler is not serving", "err", err)
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		})
+	}
+	return a.netHTTPHandler("")
+}
+
+// netHTTPHandler serves the Fiber app through net/http. altSvc, if set, is
+// added to every response.
+func (a *App) netHTTPHandler(altSvc string) http.Handler {
+	serve := adaptor.FiberApp(a.Fiber)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if altSvc != "" {
+			w.Header().Set("Alt-Svc", altSvc)
+		}
+		if r.ProtoMajor == 1 && isUpgradeRequest(r) {
+			if a.serveUpgrade(w, r) {
+				return
+			}
+		}
+		serve(w, r)
+	})
+}
+
+// isUpgradeRequest reports whether r asks to switch protocols (WebSocket).
+func isUpgradeRequest(r *http.Request) bool {
+	if r.Header.Get("Upgrade") == "" {
+		return false
+	}
+	for _, v := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveUpgrade hands an HTTP/1.1 upgrade request to Fiber's fasthttp server,
+// which owns the WebSocket implementation, by hijacking the connection and
+// replaying the request on it. It reports false if the connection cannot be
+// hijacked.
+func (a *App) serveUpgrade(w http.ResponseWriter, r *http.Request) bool {
+	var replay bytes.Buffer
+	if err := r.Write(&replay); err != nil {
+		return false
+	}
+	// ResponseController finds the hijacker behind middleware that wraps w
+	// and implements Unwrap.
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return false
+	}
+	buffered := &replayConn{Conn: conn, r: io.MultiReader(&replay, rw.Reader)}
+	// ServeConn skips Fiber's startup, so build the route tree first as the
+	// adaptor does for plain requests; otherwise an upgrade arriving before
+	// any other request finds no routes.
+	a.Fiber.Handler()
+	go func() {
+		if err := a.Fiber.Server().ServeConn(buffered); err != nil && !errors.Is(err, net.ErrClosed) {
+			a.Logger().Debug("upgraded connection closed", "err", err)
+		}
+	}()
+	return true
+}
+
+// replayConn reads the replayed request and any bytes net/http had already
+// buffered before continuing with the connection itself.
+type replayConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func ignoreServerClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
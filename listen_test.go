@@ -0,0 +1,141 @@
+package gospa
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	wsclient "github.com/fasthttp/websocket"
+	websocket "github.com/gofiber/contrib/v3/websocket"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+func newListenTestApp(t *testing.T) *App {
+	t.Helper()
+	app := New(Config{DevMode: true, RoutesDir: t.TempDir(), EnableWebSocket: false, WebSocketPath: "/_gospa/ws"})
+	app.Fiber.Get("/hello", func(c gofiber.Ctx) error {
+		return c.SendString("hi")
+	})
+	app.Fiber.Get("/echo", websocket.New(func(c *websocket.Conn) {
+		mt, msg, err := c.ReadMessage()
+		if err == nil {
+			_ = c.WriteMessage(mt, msg)
+		}
+	}))
+	if err := app.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = app.Fiber.Shutdown() })
+	return app
+}
+
+func TestNetHTTPHandlerServesH2C(t *testing.T) {
+	app := newListenTestApp(t)
+	srv := httptest.NewUnstartedServer(app.netHTTPHandler(`h3=":443"; ma=86400`))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 || string(body) != "hi" {
+		t.Fatalf("got %s %q, want HTTP/2 \"hi\"", resp.Proto, body)
+	}
+	if got := resp.Header.Get("Alt-Svc"); got != `h3=":443"; ma=86400` {
+		t.Fatalf("Alt-Svc = %q", got)
+	}
+}
+
+func TestNetHTTPHandlerUpgradesWebSocket(t *testing.T) {
+	app := newListenTestApp(t)
+	srv := httptest.NewServer(app.netHTTPHandler(""))
+	defer srv.Close()
+
+	conn, _, err := wsclient.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/echo", nil)
+	if err != nil {
+		t.Fatalf("dial through net/http failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := conn.WriteMessage(wsclient.TextMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "ping" {
+		t.Fatalf("echo = %q, %v", msg, err)
+	}
+}
+
+// statusRecorder wraps a ResponseWriter the way logging middleware does.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func TestHTTPHandlerInServeMux(t *testing.T) {
+	app := newListenTestApp(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "stdlib")
+	})
+	mux.Handle("/", app.HTTPHandler())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(&statusRecorder{ResponseWriter: w}, r)
+	}))
+	defer srv.Close()
+
+	for path, want := range map[string]string{"/api/ping": "stdlib", "/hello": "hi"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("GET %s = %q, want %q", path, body, want)
+		}
+	}
+
+	// The upgrade reaches the hijacker through the wrapping middleware.
+	conn, _, err := wsclient.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/echo", nil)
+	if err != nil {
+		t.Fatalf("dial through the mux failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := conn.WriteMessage(wsclient.TextMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "ping" {
+		t.Fatalf("echo = %q, %v", msg, err)
+	}
+}
+
+func TestIsUpgradeRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if isUpgradeRequest(r) {
+		t.Fatal("plain request reported as upgrade")
+	}
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	if !isUpgradeRequest(r) {
+		t.Fatal("upgrade request not detected")
+	}
+}
@@ -0,0 +1,107 @@
+package gospa
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/state"
+)
+
+// liveCheckInterval is how often the App looks for clients that started or
+// stopped receiving a live rune's key, besides on connects and disconnects.
+const liveCheckInterval = time.Second
+
+// liveRune is a rune added with Live. cancel and subscribers belong to the
+// superviseLive goroutine.
+type liveRune struct {
+	key string
+	src state.Live
+	// cancel stops the running source; nil while it is paused.
+	cancel context.CancelFunc
+	// subscribers is the client count seen at the last check.
+	subscribers int
+}
+
+// Live adds a live rune (state.NewTicker, state.FromChannel) to the global
+// state under key. The App runs it while a client connected to this process
+// receives key and pauses it while none does, and sends each new value to
+// those clients. Every process runs its own copy, so values are not relayed
+// through the PubSub. Without WebSockets the rune never runs.
+func (a *App) Live(key string, src state.Live) *App {
+	a.StateMap.Add(key, src)
+	if a.Hub == nil {
+		return a
+	}
+	src.SubscribeAny(func(v any) {
+		if err := fiber.BroadcastStateLocal(a.Hub, key, v); err != nil {
+			a.Logger().Error("live state broadcast failed", "key", key, "err", err)
+		}
+	})
+	a.liveMu.Lock()
+	a.live = append(a.live, &liveRune{key: key, src: src})
+	if a.liveWake == nil {
+		a.liveWake = make(chan struct{}, 1)
+		go a.superviseLive(a.liveWake)
+	}
+	a.liveMu.Unlock()
+	a.wakeLive()
+	return a
+}
+
+// wakeLive makes superviseLive check the live runes now.
+func (a *App) wakeLive() {
+	a.liveMu.Lock()
+	defer a.liveMu.Unlock()
+	if a.liveWake == nil {
+		return
+	}
+	select {
+	case a.liveWake <- struct{}{}:
+	default:
+	}
+}
+
+// superviseLive starts and pauses the live runes as clients come and go,
+// until the App shuts down.
+func (a *App) superviseLive(wake <-chan struct{}) {
+	ctx := a.Context()
+	t := time.NewTicker(liveCheckInterval)
+	defer t.Stop()
+	for {
+		a.checkLive(ctx)
+		select {
+		case <-ctx.Done():
+			// The sources run under ctx, so they stop as well.
+			return
+		case <-t.C:
+		case <-wake:
+		}
+	}
+}
+
+// checkLive runs each live rune that has subscribers and pauses the others.
+func (a *App) checkLive(ctx context.Context) {
+	a.liveMu.Lock()
+	runes := slices.Clone(a.live)
+	a.liveMu.Unlock()
+	for _, r := range runes {
+		n := a.Hub.KeySubscribers(r.key)
+		switch {
+		case n == 0 && r.cancel != nil:
+			r.cancel()
+			r.cancel = nil
+		case n > 0 && r.cancel == nil:
+			runCtx, cancel := context.WithCancel(ctx)
+			r.cancel = cancel
+			go r.src.Run(runCtx)
+		case n > r.subscribers:
+			// Clients that joined while it runs get the current value.
+			if err := fiber.BroadcastStateLocal(a.Hub, r.key, r.src.GetAny()); err != nil {
+				a.Logger().Error("live state broadcast failed", "key", r.key, "err", err)
+			}
+		}
+		r.subscribers = n
+	}
+}
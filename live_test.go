@@ -0,0 +1,57 @@
+package gospa
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/state"
+)
+
+func TestLiveRunsOnlyWithSubscribers(t *testing.T) {
+	app := New(Config{DevMode: true, CacheTemplates: true, EnableWebSocket: true})
+	defer func() { _ = app.Shutdown() }()
+
+	ch := make(chan int)
+	app.Live("progress", state.FromChannel(ch))
+
+	// Nothing reads the channel while no client is connected.
+	select {
+	case ch <- 1:
+		t.Fatal("live rune ran without subscribers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	client := fiber.NewWSClient("live", nil, fiber.WebSocketConfig{Hub: app.Hub})
+	app.Hub.Register <- client
+	app.clientConnected(client)
+	select {
+	case ch <- 42:
+	case <-time.After(3 * time.Second):
+		t.Fatal("live rune did not start for a connected client")
+	}
+	for received := false; !received; {
+		select {
+		case msg := <-client.Send:
+			s := string(msg)
+			received = strings.Contains(s, `"progress"`) && strings.Contains(s, "42")
+		case <-time.After(3 * time.Second):
+			t.Fatal("client did not receive the live value")
+		}
+	}
+
+	// A client interested only in other keys does not keep it running.
+	client.AddKeyInterest("other.")
+	app.wakeLive()
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case ch <- 0:
+		case <-deadline:
+			t.Fatal("live rune kept running without subscribers")
+		case <-time.After(50 * time.Millisecond):
+			return
+		}
+	}
+}
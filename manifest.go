@@ -0,0 +1,64 @@
+package gospa
+
+import (
+	"strings"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
+)
+
+func (a *App) setupManifestRoutes() {
+	if a.Config.Manifest == nil {
+		return
+	}
+	manifest := a.webAppManifest()
+	a.Fiber.Get(manifest.Href, func(c fiberpkg.Ctx) error {
+		return c.JSON(manifest, "application/manifest+json")
+	})
+}
+
+// webAppManifest returns Config.Manifest with the defaults browsers need to
+// install the site and its icons resolved through BuildManifest, or nil.
+func (a *App) webAppManifest() *templpkg.ManifestConfig {
+	if a.Config.Manifest == nil {
+		return nil
+	}
+	m := *a.Config.Manifest
+	if m.Href == "" {
+		m.Href = templpkg.DefaultManifestPath
+	}
+	if m.Name == "" {
+		m.Name = a.Config.AppName
+	}
+	if m.StartURL == "" {
+		m.StartURL = "/"
+	}
+	if m.Display == "" {
+		m.Display = "standalone"
+	}
+	m.Icons = make([]templpkg.ManifestIcon, len(a.Config.Manifest.Icons))
+	for i, icon := range a.Config.Manifest.Icons {
+		icon.Src = a.assetURL(icon.Src)
+		m.Icons[i] = icon
+	}
+	return &m
+}
+
+// assetURL returns the URL to load the static file at path with, so the
+// browser and the service worker pick up each build's copy. BuildManifest
+// maps a file to its hashed copy or, as gospa build writes it, to a hash of
+// its content, which is added as a query.
+func (a *App) assetURL(path string) string {
+	hashed, ok := a.Config.BuildManifest[strings.TrimPrefix(path, "/")]
+	switch {
+	case !ok || hashed == "":
+		return path
+	case strings.ContainsAny(hashed, "/."):
+		return "/" + strings.TrimPrefix(hashed, "/")
+	}
+	if len(hashed) > 12 {
+		hashed = hashed[:12]
+	}
+	return path + "?v=" + hashed
+}
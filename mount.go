@@ -0,0 +1,84 @@
+package gospa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// Mount serves the pages of sub under prefix, e.g. /shop, so a large codebase
+// can keep each module in its own routes tree. Generate the module's routes
+// with the same prefix (gospa generate --base-path /shop) so its registry
+// entries don't collide with the parent's.
+//
+// sub renders its pages with its own layouts, middleware files, DefaultState
+// and render settings. The parent provides everything else: the listener,
+// global middleware, internal routes, the WebSocket hub, Storage and PubSub.
+// sub's own hub is closed and its Fiber field becomes the parent's. Call
+// Mount before Prepare or Run, and don't run sub itself; the parent's
+// Shutdown stops it.
+func (a *App) Mount(prefix string, sub *App) error {
+	prefix = "/" + strings.Trim(prefix, "/")
+	switch {
+	case sub == nil || sub == a:
+		return errors.New("gospa: Mount needs another app")
+	case prefix == "/" || strings.ContainsAny(prefix, ":*?[]"):
+		return fmt.Errorf("gospa: Mount prefix %q must be a static path below /", prefix)
+	case a.prepared:
+		return errors.New("gospa: Mount must be called before Prepare or Run")
+	case sub.prepared || sub.Router.BasePath() != "":
+		return errors.New("gospa: the app is already running or mounted")
+	case sub.startupErr != nil:
+		return fmt.Errorf("gospa: app mounted at %s: %w", prefix, sub.startupErr)
+	}
+	for _, m := range a.mounts {
+		if m.Router.BasePath() == prefix {
+			return fmt.Errorf("gospa: an app is already mounted at %s", prefix)
+		}
+	}
+
+	// Share the parent's hub, Storage and PubSub, and point the pages at the
+	// endpoints the parent serves.
+	if sub.Hub != nil && sub.Hub != a.Hub {
+		sub.Hub.Close()
+	}
+	sub.Hub, sub.DevTools = a.Hub, a.DevTools
+	if sub.cacheInvalidationUnsub != nil {
+		sub.cacheInvalidationUnsub()
+		sub.cacheInvalidationUnsub = nil
+	}
+	if sub.Config.Storage != a.Config.Storage {
+		if closer, ok := sub.Config.Storage.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+	}
+	sub.Config.Storage, sub.Config.PubSub = a.Config.Storage, a.Config.PubSub
+	sub.Config.EnableWebSocket = a.Config.EnableWebSocket
+	sub.Config.WebSocketPath = a.Config.WebSocketPath
+	sub.Config.RemotePrefix = a.Config.RemotePrefix
+	sub.Fiber = a.Fiber
+	sub.subscribeCacheInvalidation()
+	// New set the session stores and other package-level settings from
+	// sub's Config; the parent's apply to the whole server.
+	if err := applyGlobalSettings(&a.Config); err != nil {
+		return fmt.Errorf("gospa: Mount: %w", err)
+	}
+	a.stateStores = fiber.GlobalStateStores()
+	sub.stateStores = a.stateStores
+
+	sub.Router.SetBasePath(prefix)
+	a.mounts = append(a.mounts, sub)
+	return nil
+}
+
+// pages returns the app's pages followed by those of its mounted apps.
+func (a *App) pages() []*routing.Route {
+	pages := a.Router.GetPages()
+	for _, sub := range a.mounts {
+		pages = append(pages, sub.Router.GetPages()...)
+	}
+	return pages
+}
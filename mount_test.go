@@ -0,0 +1,103 @@
+package gospa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/a-h/templ"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func textComponent(text string) routing.ComponentFunc {
+	return func(map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, text)
+			return err
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	routing.RegisterPage("/:mountslug", textComponent("parent slug"))
+	routing.RegisterPage("/mount-shop", textComponent("shop home"))
+	routing.RegisterPage("/mount-shop/cart", textComponent("shop cart"))
+	routing.RegisterLayout("/mount-shop", func(children templ.Component, _ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<shop-layout>")
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</shop-layout>")
+			return err
+		})
+	})
+
+	app := New(Config{RoutesFS: fstest.MapFS{"[mountslug]/page.templ": &fstest.MapFile{}}, DevMode: true})
+	defer func() { _ = app.Shutdown() }()
+	shop := New(Config{
+		RoutesFS: fstest.MapFS{
+			"layout.templ":      &fstest.MapFile{},
+			"page.templ":        &fstest.MapFile{},
+			"cart/page.templ":   &fstest.MapFile{},
+			"orders/page.templ": &fstest.MapFile{},
+		},
+		DevMode: true,
+	})
+
+	if err := app.Mount("/", shop); err == nil {
+		t.Fatal("Mount(/) succeeded")
+	}
+	if err := app.Mount("/mount-shop/", shop); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	if err := app.Mount("/mount-shop", New(Config{RoutesFS: fstest.MapFS{}, DevMode: true})); err == nil {
+		t.Fatal("second Mount at the same prefix succeeded")
+	}
+	if shop.Hub != app.Hub || shop.Config.Storage != app.Config.Storage || shop.Config.PubSub != app.Config.PubSub {
+		t.Fatal("mounted app does not share the hub, Storage and PubSub")
+	}
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if err := app.Mount("/late", New(Config{RoutesFS: fstest.MapFS{}, DevMode: true})); err == nil {
+		t.Fatal("Mount after Prepare succeeded")
+	}
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	for path, want := range map[string]string{
+		"/mount-shop":      "<shop-layout>shop home</shop-layout>",
+		"/mount-shop/cart": "<shop-layout>shop cart</shop-layout>",
+		"/anything":        "parent slug",
+	} {
+		if status, body := get(path); status != http.StatusOK || !strings.Contains(body, want) {
+			t.Errorf("GET %s = %d, want %q in:\n%s", path, status, want, body)
+		}
+	}
+	if got := len(app.pages()); got != 4 {
+		t.Errorf("pages() has %d routes, want 4", got)
+	}
+}
+
+func TestMountReportsConfigError(t *testing.T) {
+	app := New(Config{RoutesFS: fstest.MapFS{}, DevMode: true, StorageEncryptionKeys: [][]byte{[]byte("short")}})
+	defer func() { _ = app.Shutdown() }()
+	sub := New(Config{RoutesFS: fstest.MapFS{}, DevMode: true})
+	if err := app.Mount("/sub", sub); err == nil || !strings.Contains(err.Error(), "storage encryption") {
+		t.Fatalf("Mount = %v, want the storage encryption error", err)
+	}
+}
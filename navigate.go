@@ -0,0 +1,91 @@
+package gospa
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// NavEvent describes one page view served by the app, either a full page
+// load or a client-side SPA navigation.
+type NavEvent struct {
+	// Path is the requested path, without the query string.
+	Path string
+	// Host is the requested host name, without the port.
+	Host string
+	// RoutePath is the matched route pattern, e.g. "/blog/:slug".
+	RoutePath string
+	// Referrer is the Referer header. For SPA navigations it is the page the
+	// user navigated from.
+	Referrer string
+	// SPA is true for client-side navigations and false for full page loads.
+	SPA bool
+	// Prefetch is true when the runtime fetched the page speculatively, before
+	// the user navigated to it. The view happens later, if at all.
+	Prefetch bool
+	// SessionID is the client ID of the request's session, or "". It is not
+	// the session token.
+	SessionID string
+	// RequestID is the request's X-Request-ID.
+	RequestID string
+	// UserAgent is the User-Agent header.
+	UserAgent string
+	// Status is the response status code.
+	Status int
+	// Time is when the request started and Duration how long the page took to
+	// load and render.
+	Time     time.Time
+	Duration time.Duration
+}
+
+// OnNavigate registers fn to be called for every page view, including SPA
+// navigations and requests for cached pages, but not __data requests. Use it
+// for server-side analytics without a third-party script. Handlers run on the
+// request goroutine after the page renders and must not block; hand slow work
+// to a goroutine or a.Jobs.
+func (a *App) OnNavigate(fn func(NavEvent)) {
+	if fn == nil {
+		return
+	}
+	a.navHooksMu.Lock()
+	defer a.navHooksMu.Unlock()
+	a.navHooks = append(a.navHooks, fn)
+}
+
+// navigationHooks returns the registered OnNavigate handlers.
+func (a *App) navigationHooks() []func(NavEvent) {
+	a.navHooksMu.RLock()
+	defer a.navHooksMu.RUnlock()
+	return a.navHooks
+}
+
+// emitNavigation reports the page view c to the OnNavigate handlers.
+func (a *App) emitNavigation(c fiberpkg.Ctx, route *routing.Route, start time.Time) {
+	hooks := a.navigationHooks()
+	if len(hooks) == 0 || c.Query("__data") == "1" {
+		return
+	}
+	purpose := strings.ToLower(c.Get("Sec-Purpose") + c.Get("Purpose"))
+	// Strings from c alias fasthttp buffers that are reused once the request
+	// ends, and handlers may keep the event.
+	ev := NavEvent{
+		Path:      strings.Clone(c.Path()),
+		Host:      strings.Clone(c.Hostname()),
+		RoutePath: route.Path,
+		Referrer:  strings.Clone(c.Get(fiberpkg.HeaderReferer)),
+		SPA:       fiber.IsSPANavigation(c) || c.Get("X-Requested-With") == "GoSPA-Navigate",
+		Prefetch:  strings.Contains(purpose, "prefetch"),
+		SessionID: fiber.SessionClientID(c),
+		RequestID: strings.Clone(fiber.GetRequestID(c)),
+		UserAgent: strings.Clone(c.Get(fiberpkg.HeaderUserAgent)),
+		Status:    c.Response().StatusCode(),
+		Time:      start,
+		Duration:  time.Since(start),
+	}
+	for _, fn := range hooks {
+		fn(ev)
+	}
+}
@@ -0,0 +1,70 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestOnNavigate(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-on-navigate-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>ok</p>")
+			return err
+		})
+	})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	var events []NavEvent
+	app.OnNavigate(func(ev NavEvent) { events = append(events, ev) })
+
+	send := func(target string, headers map[string]string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	send(routePath, nil)
+	send(routePath, map[string]string{"X-Requested-With": "GoSPA-Navigate", "Referer": "http://example.com/from"})
+	send(routePath, map[string]string{"X-Requested-With": "GoSPA-Navigate", "Purpose": "prefetch"})
+	send(routePath+"?__data=1", nil)
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	full, nav, prefetch := events[0], events[1], events[2]
+	if full.SPA || full.Path != routePath || full.RoutePath != routePath || full.Status != http.StatusOK {
+		t.Fatalf("unexpected full load event: %+v", full)
+	}
+	if full.RequestID == "" || full.Time.IsZero() {
+		t.Fatalf("full load event misses request data: %+v", full)
+	}
+	if !nav.SPA || nav.Prefetch || nav.Referrer != "http://example.com/from" {
+		t.Fatalf("unexpected SPA navigation event: %+v", nav)
+	}
+	if !prefetch.SPA || !prefetch.Prefetch {
+		t.Fatalf("unexpected prefetch event: %+v", prefetch)
+	}
+}
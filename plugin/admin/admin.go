@@ -0,0 +1,244 @@
+// Package admin scaffolds an /admin route group for a GoSPA project.
+//
+// `gospa add admin Post User` writes, under routes/admin:
+//
+//   - admin.go: the App, Authorize and AuthorizeAction variables and the
+//     admin.sessions.revoke, admin.cache.invalidate and admin.state.set
+//     remote actions
+//   - middleware.go: the guard running Authorize before every /admin page
+//   - a layout and dashboard, and pages for connected sessions, global state
+//     keys and the page caches
+//   - CRUD pages for each model, generated by the crud plugin under
+//     routes/admin/<plural> with their remote actions behind AuthorizeAction
+//
+// Everything is rejected until the application assigns Authorize and
+// AuthorizeAction.
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/aydenstechdungeon/gospa/plugin"
+	"github.com/aydenstechdungeon/gospa/plugin/crud"
+)
+
+// Plugin provides the `add:admin` command.
+type Plugin struct{}
+
+// New creates a new admin scaffolding plugin.
+func New() *Plugin {
+	return &Plugin{}
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return "admin"
+}
+
+// Init initializes the plugin.
+func (p *Plugin) Init() error {
+	return nil
+}
+
+// Dependencies returns required dependencies.
+func (p *Plugin) Dependencies() []plugin.Dependency {
+	return nil
+}
+
+// OnHook handles lifecycle hooks.
+func (p *Plugin) OnHook(_ plugin.Hook, _ map[string]interface{}) error {
+	return nil
+}
+
+// Commands returns CLI commands.
+func (p *Plugin) Commands() []plugin.Command {
+	return []plugin.Command{
+		{
+			Name:        "add:admin",
+			Description: "Scaffold an auth-guarded /admin area with CRUD pages for model structs",
+			Action:      p.addCommand,
+			Flags: []plugin.Flag{
+				{Name: "dir", Description: "Project directory", Default: "."},
+				{Name: "routes", Description: "Routes directory, relative to the project", Default: "routes"},
+				{Name: "force", Description: "Overwrite existing files", Default: false},
+			},
+		},
+	}
+}
+
+func (p *Plugin) addCommand(args []string) error {
+	fset := flag.NewFlagSet("add admin", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "Project directory")
+	routes := fset.String("routes", "routes", "Routes directory, relative to the project")
+	force := fset.Bool("force", false, "Overwrite existing files")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := Generate(Options{
+		ProjectDir: *dir,
+		RoutesDir:  *routes,
+		Models:     fset.Args(),
+		Force:      *force,
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Println("  created", f)
+	}
+	fmt.Println("\nNext: assign admin.App, admin.Authorize and admin.AuthorizeAction, implement the model stores, and run `gospa generate`.")
+	return nil
+}
+
+// Options configures Generate.
+type Options struct {
+	// ProjectDir is the directory holding go.mod.
+	ProjectDir string
+	// RoutesDir is the routes directory, relative to ProjectDir.
+	RoutesDir string
+	// Models are the struct type names that get CRUD pages.
+	Models []string
+	// Force overwrites existing files.
+	Force bool
+}
+
+// model is a model section linked from the dashboard.
+type model struct {
+	Name    string
+	Package string
+	URL     string
+	Actions string
+	Import  string
+}
+
+// scaffold is the template data.
+type scaffold struct {
+	Import string
+	Models []model
+}
+
+// Generate writes the admin area and returns the created files.
+func Generate(opts Options) ([]string, error) {
+	if opts.ProjectDir == "" {
+		opts.ProjectDir = "."
+	}
+	if opts.RoutesDir == "" {
+		opts.RoutesDir = "routes"
+	}
+	modulePath, err := readModulePath(filepath.Join(opts.ProjectDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	routesImport := modulePath + "/" + filepath.ToSlash(filepath.Clean(opts.RoutesDir))
+	data := scaffold{Import: routesImport + "/admin"}
+
+	base := filepath.Join(opts.ProjectDir, opts.RoutesDir, "admin")
+	outputs := []struct {
+		path  string
+		tmpl  string
+		gofmt bool
+	}{
+		{"admin.go", adminTmpl, true},
+		{"middleware.go", middlewareTmpl, true},
+		{"layout.templ", layoutTmpl, false},
+		{"+page.server.go", dashboardServerTmpl, true},
+		{"page.templ", dashboardPageTmpl, false},
+		{filepath.Join("sessions", "+page.server.go"), sessionsServerTmpl, true},
+		{filepath.Join("sessions", "page.templ"), sessionsPageTmpl, false},
+		{filepath.Join("state", "+page.server.go"), stateServerTmpl, true},
+		{filepath.Join("state", "page.templ"), statePageTmpl, false},
+		{filepath.Join("cache", "page.templ"), cachePageTmpl, false},
+	}
+	if !opts.Force {
+		for _, o := range outputs {
+			if _, err := os.Stat(filepath.Join(base, o.path)); err == nil {
+				return nil, fmt.Errorf("admin: %s already exists (use --force to overwrite)", filepath.Join(base, o.path))
+			}
+		}
+	}
+
+	var created []string
+	for _, name := range opts.Models {
+		files, err := crud.Generate(crud.Options{
+			ProjectDir: opts.ProjectDir,
+			RoutesDir:  opts.RoutesDir,
+			Model:      name,
+			Prefix:     "admin",
+			Force:      opts.Force,
+		})
+		created = append(created, files...)
+		if err != nil {
+			return created, err
+		}
+		// crud.go comes first and sits in routes/admin/<plural>.
+		pkg := filepath.Base(filepath.Dir(files[0]))
+		data.Models = append(data.Models, model{
+			Name:    name,
+			Package: pkg,
+			URL:     "/admin/" + pkg,
+			Actions: "admin." + pkg,
+			Import:  data.Import + "/" + pkg,
+		})
+	}
+
+	for _, o := range outputs {
+		src, err := render(o.tmpl, data)
+		if err != nil {
+			return created, fmt.Errorf("admin: %s: %w", o.path, err)
+		}
+		if o.gofmt {
+			if src, err = format.Source(src); err != nil {
+				return created, fmt.Errorf("admin: generated invalid Go for %s: %w", o.path, err)
+			}
+		}
+		path := filepath.Join(base, o.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return created, err
+		}
+		if err := os.WriteFile(path, src, 0600); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+func render(text string, data scaffold) ([]byte, error) {
+	t, err := template.New("admin").Delims("[[", "]]").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readModulePath(goMod string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(goMod))
+	if err != nil {
+		return "", fmt.Errorf("admin: reading go.mod: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", errors.New("admin: go.mod has no module line")
+}
+
+func init() {
+	if err := plugin.Register(New()); err != nil {
+		panic("failed to register admin plugin: " + err.Error())
+	}
+}
@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const postModel = `package models
+
+type Post struct {
+	ID    int64  ` + "`json:\"id\"`" + `
+	Title string ` + "`json:\"title\" validate:\"required\"`" + `
+}
+`
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n\ngo 1.26\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "models"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "models", "post.go"), []byte(postModel), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerateScaffold(t *testing.T) {
+	dir := writeProject(t)
+	files, err := Generate(Options{ProjectDir: dir, Models: []string{"Post"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 18 {
+		t.Fatalf("created %d files, want 18: %v", len(files), files)
+	}
+
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(dir, "routes", "admin", rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	admin := read("admin.go")
+	for _, want := range []string{
+		`"example.com/shop/routes/admin/posts"`,
+		`{Name: "Post", URL: "/admin/posts"}`,
+		`posts.Authorize = func(`,
+		`return authorize(ctx, rc, "admin.posts."+action)`,
+		`routing.RegisterRemoteAction("admin."+name`,
+		`register("sessions.revoke"`,
+		`register("cache.invalidate"`,
+		`register("state.set"`,
+	} {
+		if !strings.Contains(admin, want) {
+			t.Errorf("admin.go missing %q", want)
+		}
+	}
+	if !strings.Contains(read("middleware.go"), `routing.RegisterMiddleware("/admin"`) {
+		t.Error("middleware.go does not guard /admin")
+	}
+	if !strings.Contains(read(filepath.Join("sessions", "+page.server.go")), `"example.com/shop/routes/admin"`) {
+		t.Error("sessions loader does not import the admin package")
+	}
+	if !strings.Contains(read(filepath.Join("posts", "page.templ")), `href="/admin/posts/new"`) {
+		t.Error("model pages are not nested under /admin")
+	}
+
+	if _, err := Generate(Options{ProjectDir: dir}); err == nil {
+		t.Fatal("second Generate overwrote files without Force")
+	}
+	if _, err := Generate(Options{ProjectDir: dir, Models: []string{"Post"}, Force: true}); err != nil {
+		t.Fatalf("Generate with Force: %v", err)
+	}
+}
+
+func TestGenerateWithoutModels(t *testing.T) {
+	dir := writeProject(t)
+	files, err := Generate(Options{ProjectDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 10 {
+		t.Fatalf("created %d files, want 10: %v", len(files), files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "routes", "admin", "admin.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), ".Authorize = ") {
+		t.Error("admin.go wires model packages that were not generated")
+	}
+}
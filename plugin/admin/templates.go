@@ -0,0 +1,448 @@
+package admin
+
+// Templates use [[ ]] delimiters so templ's { } expressions pass through.
+
+const header = "// Code generated by `gospa add admin`. This file is yours to edit; it is not regenerated.\n\n"
+
+const adminTmpl = header + `package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/routing/kit"
+	"github.com/aydenstechdungeon/gospa/state"
+	fiberpkg "github.com/gofiber/fiber/v3"
+[[- range .Models]]
+	"[[.Import]]"
+[[- end]]
+)
+
+// App is the application the admin pages manage. Assign it after gospa.New.
+var App *gospa.App
+
+// Authorize decides who may open the /admin pages; return an error to reject
+// the request. While it is nil every request is rejected.
+var Authorize func(c fiberpkg.Ctx) error
+
+// AuthorizeAction decides who may call the admin.* remote actions, including
+// those of the model pages. While it is nil every call is rejected. Remote
+// actions only see the request's context, so have RemoteActionMiddleware
+// store the signed-in user there with c.SetContext.
+var AuthorizeAction func(ctx context.Context, rc routing.RemoteContext, action string) error
+
+// Model is a model with CRUD pages under /admin.
+type Model struct {
+	Name string
+	URL  string
+}
+
+// Models are linked from the dashboard.
+var Models = []Model{
+[[- range .Models]]
+	{Name: "[[.Name]]", URL: "[[.URL]]"},
+[[- end]]
+}
+
+var errAppNotSet = errors.New("admin: App is not set")
+
+func init() {
+[[- range .Models]]
+	[[.Package]].Authorize = func(ctx context.Context, rc routing.RemoteContext, action string) error {
+		return authorize(ctx, rc, "[[.Actions]]."+action)
+	}
+[[- end]]
+
+	register("sessions.revoke", func(_ context.Context, input map[string]interface{}) (interface{}, error) {
+		clientID, _ := input["clientId"].(string)
+		if clientID == "" {
+			return nil, kit.Error(400, "clientId is required")
+		}
+		revoked, err := App.RevokeClientSessions(clientID)
+		if err != nil {
+			return nil, err
+		}
+		disconnected := 0
+		if App.Hub != nil {
+			App.Hub.Range(func(client *fiber.WSClient) bool {
+				if client.SessionID == clientID {
+					client.Close()
+					disconnected++
+				}
+				return true
+			})
+		}
+		return map[string]interface{}{"revoked": revoked, "disconnected": disconnected}, nil
+	})
+	register("cache.invalidate", func(_ context.Context, input map[string]interface{}) (interface{}, error) {
+		path, _ := input["path"].(string)
+		tag, _ := input["tag"].(string)
+		all := input["all"] == true || input["all"] == "true"
+		var n int
+		switch {
+		case all:
+			n = App.InvalidateAll()
+		case tag != "":
+			n = App.InvalidateTag(tag)
+		case path != "":
+			n = App.Invalidate(path)
+		default:
+			return nil, kit.Error(400, "path, tag or all is required")
+		}
+		return map[string]interface{}{"invalidated": n}, nil
+	})
+	register("state.set", func(_ context.Context, input map[string]interface{}) (interface{}, error) {
+		key, _ := input["key"].(string)
+		raw, _ := input["value"].(string)
+		obs, ok := App.StateMap.Get(key)
+		if !ok {
+			return nil, kit.Error(404, "unknown state key")
+		}
+		settable, ok := obs.(state.Settable)
+		if !ok {
+			return nil, kit.Error(400, "state key is read-only")
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, kit.Error(400, "value must be JSON")
+		}
+		if err := settable.SetAny(value); err != nil {
+			return nil, err
+		}
+		if err := App.BroadcastState(key, value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "value": value}, nil
+	})
+}
+
+// register adds the admin.<name> remote action, checking App and
+// AuthorizeAction first.
+func register(name string, fn func(ctx context.Context, input map[string]interface{}) (interface{}, error)) {
+	routing.RegisterRemoteAction("admin."+name, func(ctx context.Context, rc routing.RemoteContext, input interface{}) (interface{}, error) {
+		if App == nil {
+			return nil, errAppNotSet
+		}
+		if err := authorize(ctx, rc, "admin."+name); err != nil {
+			return nil, err
+		}
+		m, _ := input.(map[string]interface{})
+		return fn(ctx, m)
+	})
+}
+
+func authorize(ctx context.Context, rc routing.RemoteContext, action string) error {
+	if AuthorizeAction == nil {
+		return kit.Error(403, "admin access required")
+	}
+	return AuthorizeAction(ctx, rc, action)
+}
+
+// Client is a connected WebSocket client.
+type Client struct {
+	ID        string
+	SessionID string
+	RequestID string
+}
+
+// Clients lists the connected WebSocket clients, ordered by session.
+func Clients() []Client {
+	var clients []Client
+	if App == nil || App.Hub == nil {
+		return clients
+	}
+	App.Hub.Range(func(c *fiber.WSClient) bool {
+		clients = append(clients, Client{ID: c.ID, SessionID: c.SessionID, RequestID: c.RequestID})
+		return true
+	})
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].SessionID != clients[j].SessionID {
+			return clients[i].SessionID < clients[j].SessionID
+		}
+		return clients[i].ID < clients[j].ID
+	})
+	return clients
+}
+
+// StateEntry is a global state key and its JSON value.
+type StateEntry struct {
+	Key      string
+	JSON     string
+	Settable bool
+}
+
+// State lists the global state keys, sorted.
+func State() []StateEntry {
+	var entries []StateEntry
+	if App == nil {
+		return entries
+	}
+	for key, value := range App.StateMap.ToMap() {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			raw = []byte("null")
+		}
+		obs, _ := App.StateMap.Get(key)
+		_, settable := obs.(state.Settable)
+		entries = append(entries, StateEntry{Key: key, JSON: string(raw), Settable: settable})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// ClientsOf converts the sessions page's load data.
+func ClientsOf(v interface{}) []Client {
+	clients, _ := v.([]Client)
+	return clients
+}
+
+// StateOf converts the state page's load data.
+func StateOf(v interface{}) []StateEntry {
+	entries, _ := v.([]StateEntry)
+	return entries
+}
+`
+
+const middlewareTmpl = header + `package admin
+
+import (
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func init() {
+	routing.RegisterMiddleware("/admin", func(c fiberpkg.Ctx) error {
+		if Authorize == nil {
+			return fiberpkg.NewError(fiberpkg.StatusForbidden, "admin access required")
+		}
+		if err := Authorize(c); err != nil {
+			return err
+		}
+		return c.Next()
+	})
+}
+`
+
+const dashboardServerTmpl = header + `package admin
+
+import "github.com/aydenstechdungeon/gospa/routing"
+
+// Load counts what the dashboard shows.
+func Load(_ routing.LoadContext) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"clients": len(Clients()),
+		"keys":    len(State()),
+	}, nil
+}
+`
+
+// script submits forms marked with data-admin-action through GoSPA.remote
+// and reloads the page on success.
+const script = `
+templ adminScript() {
+	<script nonce={ gospatempl.GetNonce(ctx) }>
+		document.querySelectorAll('form[data-admin-action]').forEach(function (form) {
+			form.addEventListener('submit', async function (event) {
+				event.preventDefault();
+				if (form.dataset.adminConfirm && !confirm(form.dataset.adminConfirm)) return;
+				var input = {};
+				for (var el of form.elements) {
+					if (!el.name) continue;
+					input[el.name] = el.type === 'checkbox' ? el.checked : el.value;
+				}
+				var res = await GoSPA.remote(form.dataset.adminAction, input);
+				if (res.code !== 'SUCCESS') { alert(res.error || 'Request failed'); return; }
+				location.reload();
+			});
+		});
+	</script>
+}
+`
+
+const layoutTmpl = `package admin
+
+templ Layout(children templ.Component) {
+	<div class="admin">
+		<nav class="admin-nav">
+			<a href="/admin">Dashboard</a>
+			for _, m := range Models {
+				<a href={ templ.SafeURL(m.URL) }>{ m.Name }</a>
+			}
+			<a href="/admin/sessions">Sessions</a>
+			<a href="/admin/state">State</a>
+			<a href="/admin/cache">Cache</a>
+		</nav>
+		@children
+	</div>
+}
+`
+
+const dashboardPageTmpl = `package admin
+
+import "strconv"
+
+templ Page(clients int, keys int) {
+	<main class="admin-main">
+		<h1>Admin</h1>
+		<ul>
+			<li><a href="/admin/sessions">{ strconv.Itoa(clients) } connected clients</a></li>
+			<li><a href="/admin/state">{ strconv.Itoa(keys) } global state keys</a></li>
+			<li><a href="/admin/cache">Page caches</a></li>
+		</ul>
+		if len(Models) > 0 {
+			<h2>Models</h2>
+			<ul>
+				for _, m := range Models {
+					<li><a href={ templ.SafeURL(m.URL) }>{ m.Name }</a></li>
+				}
+			</ul>
+		}
+	</main>
+}
+`
+
+const sessionsServerTmpl = header + `package sessions
+
+import (
+	"github.com/aydenstechdungeon/gospa/routing"
+	"[[.Import]]"
+)
+
+// Load lists the connected WebSocket clients.
+func Load(_ routing.LoadContext) (map[string]interface{}, error) {
+	return map[string]interface{}{"clients": admin.Clients()}, nil
+}
+`
+
+const sessionsPageTmpl = `package sessions
+
+import (
+	gospatempl "github.com/aydenstechdungeon/gospa/templ"
+	"[[.Import]]"
+)
+
+templ Page(clients interface{}) {
+	<main class="admin-main">
+		<h1>Sessions</h1>
+		<p>Revoking a session invalidates every token issued to it and disconnects its live connections.</p>
+		<table>
+			<thead>
+				<tr>
+					<th>Session</th>
+					<th>Connection</th>
+					<th>Request</th>
+					<th></th>
+				</tr>
+			</thead>
+			<tbody>
+				for _, c := range admin.ClientsOf(clients) {
+					<tr>
+						<td>{ c.SessionID }</td>
+						<td>{ c.ID }</td>
+						<td>{ c.RequestID }</td>
+						<td>
+							<form data-admin-action="admin.sessions.revoke" data-admin-confirm="Revoke this session?">
+								<input type="hidden" name="clientId" value={ c.SessionID }/>
+								<button type="submit">Revoke</button>
+							</form>
+						</td>
+					</tr>
+				}
+			</tbody>
+		</table>
+		<h2>Revoke by session ID</h2>
+		<form data-admin-action="admin.sessions.revoke">
+			<input type="text" name="clientId" required/>
+			<button type="submit">Revoke</button>
+		</form>
+	</main>
+	@adminScript()
+}
+` + script
+
+const stateServerTmpl = header + `package state
+
+import (
+	"github.com/aydenstechdungeon/gospa/routing"
+	"[[.Import]]"
+)
+
+// Load lists the global state keys.
+func Load(_ routing.LoadContext) (map[string]interface{}, error) {
+	return map[string]interface{}{"entries": admin.State()}, nil
+}
+`
+
+const statePageTmpl = `package state
+
+import (
+	gospatempl "github.com/aydenstechdungeon/gospa/templ"
+	"[[.Import]]"
+)
+
+templ Page(entries interface{}) {
+	<main class="admin-main">
+		<h1>Global state</h1>
+		<p>Saving a value sets the key and broadcasts it to every connected client.</p>
+		<table>
+			<thead>
+				<tr>
+					<th>Key</th>
+					<th>Value (JSON)</th>
+				</tr>
+			</thead>
+			<tbody>
+				for _, e := range admin.StateOf(entries) {
+					<tr>
+						<td>{ e.Key }</td>
+						<td>
+							if e.Settable {
+								<form data-admin-action="admin.state.set">
+									<input type="hidden" name="key" value={ e.Key }/>
+									<input type="text" name="value" value={ e.JSON }/>
+									<button type="submit">Save</button>
+								</form>
+							} else {
+								<code>{ e.JSON }</code>
+							}
+						</td>
+					</tr>
+				}
+			</tbody>
+		</table>
+	</main>
+	@adminScript()
+}
+` + script
+
+const cachePageTmpl = `package cache
+
+import gospatempl "github.com/aydenstechdungeon/gospa/templ"
+
+templ Page() {
+	<main class="admin-main">
+		<h1>Page caches</h1>
+		<p>Invalidated pages are rendered again on their next request.</p>
+		<form data-admin-action="admin.cache.invalidate">
+			<label for="path">Path</label>
+			<input type="text" id="path" name="path" placeholder="/blog/hello" required/>
+			<button type="submit">Invalidate path</button>
+		</form>
+		<form data-admin-action="admin.cache.invalidate">
+			<label for="tag">Tag</label>
+			<input type="text" id="tag" name="tag" required/>
+			<button type="submit">Invalidate tag</button>
+		</form>
+		<form data-admin-action="admin.cache.invalidate" data-admin-confirm="Invalidate every cached page?">
+			<input type="hidden" name="all" value="true"/>
+			<button type="submit">Invalidate all</button>
+		</form>
+	</main>
+	@adminScript()
+}
+` + script
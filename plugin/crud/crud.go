@@ -0,0 +1,550 @@
+// Package crud scaffolds CRUD pages and remote actions for a Go struct.
+//
+// `gospa add crud Post` finds `type Post struct` in the project and writes,
+// under routes/posts:
+//
+//   - crud.go: a PostStore interface, remote actions (posts.list, posts.get,
+//     posts.create, posts.update, posts.delete) and validation from the
+//     struct's `validate` tags
+//   - list, detail, new and edit pages with their Load functions
+//
+// The generated code depends on no ORM: implement PostStore with any database
+// library and assign it to posts.Store before the app starts.
+package crud
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/aydenstechdungeon/gospa/plugin"
+)
+
+// Plugin provides the `add:crud` command.
+type Plugin struct{}
+
+// New creates a new CRUD scaffolding plugin.
+func New() *Plugin {
+	return &Plugin{}
+}
+
+// Name returns the plugin name.
+func (p *Plugin) Name() string {
+	return "crud"
+}
+
+// Init initializes the plugin.
+func (p *Plugin) Init() error {
+	return nil
+}
+
+// Dependencies returns required dependencies.
+func (p *Plugin) Dependencies() []plugin.Dependency {
+	return nil
+}
+
+// OnHook handles lifecycle hooks.
+func (p *Plugin) OnHook(_ plugin.Hook, _ map[string]interface{}) error {
+	return nil
+}
+
+// Commands returns CLI commands.
+func (p *Plugin) Commands() []plugin.Command {
+	return []plugin.Command{
+		{
+			Name:        "add:crud",
+			Description: "Scaffold CRUD pages and remote actions for a model struct",
+			Action:      p.addCommand,
+			Flags: []plugin.Flag{
+				{Name: "dir", Description: "Project directory", Default: "."},
+				{Name: "routes", Description: "Routes directory, relative to the project", Default: "routes"},
+				{Name: "plural", Description: "URL and package name (default: lowercase plural of the model)"},
+				{Name: "prefix", Description: "Route group to nest the pages in, e.g. admin"},
+				{Name: "force", Description: "Overwrite existing files", Default: false},
+			},
+		},
+	}
+}
+
+func (p *Plugin) addCommand(args []string) error {
+	fset := flag.NewFlagSet("add crud", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "Project directory")
+	routes := fset.String("routes", "routes", "Routes directory, relative to the project")
+	plural := fset.String("plural", "", "URL and package name (default: lowercase plural of the model)")
+	prefix := fset.String("prefix", "", "Route group to nest the pages in, e.g. admin")
+	force := fset.Bool("force", false, "Overwrite existing files")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 {
+		return errors.New("usage: gospa add crud <Model> [--dir .] [--routes routes] [--plural name] [--prefix group] [--force]")
+	}
+
+	files, err := Generate(Options{
+		ProjectDir: *dir,
+		RoutesDir:  *routes,
+		Model:      fset.Arg(0),
+		Plural:     *plural,
+		Prefix:     *prefix,
+		Force:      *force,
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Println("  created", f)
+	}
+	fmt.Println("\nNext: implement the store interface in crud.go, assign it to Store, and run `gospa generate`.")
+	return nil
+}
+
+// Options configures Generate.
+type Options struct {
+	// ProjectDir is the directory holding go.mod.
+	ProjectDir string
+	// RoutesDir is the routes directory, relative to ProjectDir.
+	RoutesDir string
+	// Model is the struct type name.
+	Model string
+	// Plural overrides the URL segment and package name.
+	Plural string
+	// Prefix nests the scaffold in a route group: with Prefix "admin" the
+	// pages are written to routes/admin/posts, served at /admin/posts, and
+	// the remote actions are named admin.posts.*.
+	Prefix string
+	// Force overwrites existing files.
+	Force bool
+}
+
+// field is one struct field exposed by the scaffold.
+type field struct {
+	Name  string
+	JSON  string
+	Label string
+	// Kind is string, int, float, bool, or other (shown but not editable).
+	Kind  string
+	Type  string
+	Rules []rule
+}
+
+// rule is one check generated from a `validate` tag.
+type rule struct {
+	Cond string
+	Msg  string
+}
+
+// Input returns the HTML input type for the field.
+func (f field) Input() string {
+	switch f.Kind {
+	case "int", "float":
+		return "number"
+	case "bool":
+		return "checkbox"
+	}
+	for _, r := range f.Rules {
+		if r.Msg == "must be an email address" {
+			return "email"
+		}
+	}
+	return "text"
+}
+
+// Editable reports whether the field appears in forms.
+func (f field) Editable() bool {
+	return f.Kind != "other"
+}
+
+// model is the template data.
+type model struct {
+	Name        string
+	Plural      string
+	Package     string
+	ModelPkg    string
+	ModelImport string
+	RoutesPkg   string
+	// URL is the list page's path, e.g. /posts.
+	URL string
+	// Actions prefixes the remote action names, e.g. posts.
+	Actions string
+	ID      field
+	Fields  []field
+}
+
+// uses reports whether any validation rule calls fn.
+func (m *model) uses(fn string) bool {
+	for _, f := range m.Fields {
+		for _, r := range f.Rules {
+			if strings.Contains(r.Cond, fn+"(") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UsesStrings reports whether crud.go imports strings.
+func (m *model) UsesStrings() bool {
+	return m.uses("strings.TrimSpace") || m.uses("strings.Contains")
+}
+
+// UsesUTF8 reports whether crud.go imports unicode/utf8.
+func (m *model) UsesUTF8() bool {
+	return m.uses("utf8.RuneCountInString")
+}
+
+// StringID reports whether the model's ID is a string.
+func (m *model) StringID() bool {
+	return m.ID.Kind == "string"
+}
+
+// Generate writes the scaffold for opts.Model and returns the created files.
+func Generate(opts Options) ([]string, error) {
+	if opts.ProjectDir == "" {
+		opts.ProjectDir = "."
+	}
+	if opts.RoutesDir == "" {
+		opts.RoutesDir = "routes"
+	}
+	modulePath, err := readModulePath(filepath.Join(opts.ProjectDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	m, err := findModel(opts.ProjectDir, opts.Model)
+	if err != nil {
+		return nil, err
+	}
+	m.Plural = opts.Plural
+	if m.Plural == "" {
+		m.Plural = pluralize(strings.ToLower(m.Name))
+	}
+	if !isIdent(m.Plural) {
+		return nil, fmt.Errorf("crud: %q is not a valid package name; pass --plural", m.Plural)
+	}
+	rel := m.Plural
+	if prefix := strings.Trim(filepath.ToSlash(opts.Prefix), "/"); prefix != "" {
+		for _, seg := range strings.Split(prefix, "/") {
+			if !isIdent(seg) {
+				return nil, fmt.Errorf("crud: prefix segment %q is not a valid package name", seg)
+			}
+		}
+		rel = prefix + "/" + rel
+	}
+	m.Package = m.Plural
+	m.URL = "/" + rel
+	m.Actions = strings.ReplaceAll(rel, "/", ".")
+	m.ModelImport = joinImport(modulePath, m.ModelImport)
+	m.RoutesPkg = joinImport(modulePath, filepath.ToSlash(filepath.Join(opts.RoutesDir, rel)))
+
+	base := filepath.Join(opts.ProjectDir, opts.RoutesDir, filepath.FromSlash(rel))
+	outputs := []struct {
+		path  string
+		tmpl  string
+		gofmt bool
+	}{
+		{"crud.go", crudTmpl, true},
+		{"+page.server.go", listServerTmpl, true},
+		{"page.templ", listPageTmpl, false},
+		{filepath.Join("new", "page.templ"), newPageTmpl, false},
+		{filepath.Join("_id", "+page.server.go"), itemServerTmpl("id"), true},
+		{filepath.Join("_id", "page.templ"), detailPageTmpl, false},
+		{filepath.Join("_id", "edit", "+page.server.go"), itemServerTmpl("edit"), true},
+		{filepath.Join("_id", "edit", "page.templ"), editPageTmpl, false},
+	}
+	if !opts.Force {
+		for _, o := range outputs {
+			if _, err := os.Stat(filepath.Join(base, o.path)); err == nil {
+				return nil, fmt.Errorf("crud: %s already exists (use --force to overwrite)", filepath.Join(base, o.path))
+			}
+		}
+	}
+
+	created := make([]string, 0, len(outputs))
+	for _, o := range outputs {
+		src, err := render(o.tmpl, m)
+		if err != nil {
+			return created, fmt.Errorf("crud: %s: %w", o.path, err)
+		}
+		if o.gofmt {
+			if src, err = format.Source(src); err != nil {
+				return created, fmt.Errorf("crud: generated invalid Go for %s: %w", o.path, err)
+			}
+		}
+		path := filepath.Join(base, o.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return created, err
+		}
+		if err := os.WriteFile(path, src, 0600); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+func render(text string, m *model) ([]byte, error) {
+	funcs := template.FuncMap{
+		"form": func(m *model, edit bool) map[string]interface{} {
+			indent := "\t\t\t"
+			if edit {
+				indent += "\t"
+			}
+			return map[string]interface{}{"M": m, "Edit": edit, "Indent": indent}
+		},
+	}
+	t, err := template.New("crud").Delims("[[", "]]").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readModulePath(goMod string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(goMod))
+	if err != nil {
+		return "", fmt.Errorf("crud: reading go.mod: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", errors.New("crud: go.mod has no module line")
+}
+
+func joinImport(modulePath, rel string) string {
+	if rel == "" || rel == "." {
+		return modulePath
+	}
+	return modulePath + "/" + rel
+}
+
+// findModel locates `type <name> struct` in the project, skipping hidden,
+// underscore, vendor and node_modules directories.
+func findModel(projectDir, name string) (*model, error) {
+	var found *model
+	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			base := d.Name()
+			if path != projectDir && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") ||
+				base == "vendor" || base == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if found != nil || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.Name.Name != name {
+					continue
+				}
+				rel, _ := filepath.Rel(projectDir, filepath.Dir(path))
+				found, err = modelFromStruct(name, file.Name.Name, filepath.ToSlash(rel), st)
+				if err != nil {
+					return err
+				}
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("crud: type %s struct not found under %s", name, projectDir)
+	}
+	return found, nil
+}
+
+func modelFromStruct(name, pkg, rel string, st *ast.StructType) (*model, error) {
+	if pkg == "main" {
+		return nil, fmt.Errorf("crud: %s is declared in package main; move it to an importable package", name)
+	}
+	m := &model{Name: name, ModelPkg: pkg, ModelImport: rel}
+	hasID := false
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			continue
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			if unq, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(unq)
+			}
+		}
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		typ := exprString(f.Type)
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fd := field{
+				Name:  ident.Name,
+				JSON:  jsonName,
+				Label: label(ident.Name),
+				Kind:  kindOf(typ),
+				Type:  typ,
+			}
+			if fd.JSON == "" {
+				fd.JSON = ident.Name
+			}
+			fd.Rules = rules(fd, tag.Get("validate"))
+			if ident.Name == "ID" {
+				m.ID = fd
+				hasID = true
+				continue
+			}
+			m.Fields = append(m.Fields, fd)
+		}
+	}
+	if !hasID {
+		return nil, fmt.Errorf("crud: %s has no ID field", name)
+	}
+	if m.ID.Kind != "string" && m.ID.Kind != "int" {
+		return nil, fmt.Errorf("crud: %s.ID must be a string or integer, got %s", name, m.ID.Type)
+	}
+	return m, nil
+}
+
+func exprString(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	}
+	return "other"
+}
+
+func kindOf(typ string) string {
+	switch typ {
+	case "string":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "int"
+	case "float32", "float64":
+		return "float"
+	case "bool":
+		return "bool"
+	}
+	return "other"
+}
+
+// rules turns the required, min, max and email validate tags into checks
+// on the field of a value named item.
+func rules(f field, tag string) []rule {
+	if f.Kind == "other" || f.Kind == "bool" {
+		return nil
+	}
+	ref := "item." + f.Name
+	var out []rule
+	for _, part := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "required":
+			if f.Kind == "string" {
+				out = append(out, rule{"strings.TrimSpace(" + ref + ") == \"\"", "is required"})
+			} else {
+				out = append(out, rule{ref + " == 0", "is required"})
+			}
+		case "min", "max":
+			if _, err := strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+			op, word := "<", "at least"
+			if key == "max" {
+				op, word = ">", "at most"
+			}
+			if f.Kind == "string" {
+				out = append(out, rule{"utf8.RuneCountInString(" + ref + ") " + op + " " + val, "must be " + word + " " + val + " characters"})
+			} else {
+				out = append(out, rule{ref + " " + op + " " + val, "must be " + word + " " + val})
+			}
+		case "email":
+			if f.Kind == "string" {
+				out = append(out, rule{ref + " != \"\" && !strings.Contains(" + ref + ", \"@\")", "must be an email address"})
+			}
+		}
+	}
+	return out
+}
+
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// label turns CreatedAt into "Created at".
+func label(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			b.WriteByte(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isIdent(s string) bool {
+	if s == "" || token.IsKeyword(s) {
+		return false
+	}
+	for i, r := range s {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	if err := plugin.Register(New()); err != nil {
+		panic("failed to register crud plugin: " + err.Error())
+	}
+}
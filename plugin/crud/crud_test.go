@@ -0,0 +1,150 @@
+package crud
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const postModel = `package models
+
+import "time"
+
+type Post struct {
+	ID        int64     ` + "`json:\"id\"`" + `
+	Title     string    ` + "`json:\"title\" validate:\"required,max=120\"`" + `
+	Email     string    ` + "`json:\"email\" validate:\"email\"`" + `
+	Published bool      ` + "`json:\"published\"`" + `
+	CreatedAt time.Time ` + "`json:\"createdAt\"`" + `
+	secret    string
+}
+`
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/blog\n\ngo 1.26\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "models"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "models", "post.go"), []byte(postModel), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGenerateScaffold(t *testing.T) {
+	dir := writeProject(t)
+	files, err := Generate(Options{ProjectDir: dir, Model: "Post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 8 {
+		t.Fatalf("created %d files, want 8: %v", len(files), files)
+	}
+
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(dir, "routes", "posts", rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	crud := read("crud.go")
+	for _, want := range []string{
+		`"example.com/blog/models"`,
+		"type PostStore interface",
+		`routing.RegisterRemoteAction("posts."+name`,
+		`strings.TrimSpace(item.Title) == ""`,
+		"utf8.RuneCountInString(item.Title) > 120",
+		`errs["email"] = "Email must be an email address"`,
+		"func ParseID(s string) (int64, error)",
+	} {
+		if !strings.Contains(crud, want) {
+			t.Errorf("crud.go missing %q", want)
+		}
+	}
+	if strings.Contains(crud, "secret") {
+		t.Error("crud.go references an unexported field")
+	}
+
+	edit := read(filepath.Join("_id", "edit", "page.templ"))
+	for _, want := range []string{
+		`"example.com/blog/routes/posts"`,
+		`name="id" value={ fmt.Sprint(p.ID) } data-kind="number"`,
+		`checked?={ p.Published }`,
+		`value={ fmt.Sprint(p.Title) }`,
+	} {
+		if !strings.Contains(edit, want) {
+			t.Errorf("edit page missing %q", want)
+		}
+	}
+	if strings.Contains(edit, "p.CreatedAt") {
+		t.Error("edit form includes a non-editable time.Time field")
+	}
+
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Post"}); err == nil {
+		t.Fatal("second Generate overwrote files without Force")
+	}
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Post", Force: true}); err != nil {
+		t.Fatalf("Generate with Force: %v", err)
+	}
+}
+
+func TestGeneratePrefix(t *testing.T) {
+	dir := writeProject(t)
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Post", Prefix: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "routes", "admin", "posts")
+	crud, err := os.ReadFile(filepath.Join(base, "crud.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(crud), `routing.RegisterRemoteAction("admin.posts."+name`) {
+		t.Error("remote actions are not namespaced by the prefix")
+	}
+	detail, err := os.ReadFile(filepath.Join(base, "_id", "page.templ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"example.com/blog/routes/admin/posts"`,
+		`fmt.Sprintf("/admin/posts/%v/edit", p.ID)`,
+		`data-crud-action="admin.posts.delete" data-crud-redirect="/admin/posts"`,
+	} {
+		if !strings.Contains(string(detail), want) {
+			t.Errorf("detail page missing %q", want)
+		}
+	}
+
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Post", Prefix: "my-admin"}); err == nil {
+		t.Fatal("prefix that is not a package name accepted")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := writeProject(t)
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Comment"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("missing model err = %v", err)
+	}
+
+	noID := "package models\n\ntype Tag struct {\n\tName string\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "models", "tag.go"), []byte(noID), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(Options{ProjectDir: dir, Model: "Tag"}); err == nil || !strings.Contains(err.Error(), "no ID") {
+		t.Fatalf("model without ID err = %v", err)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	for in, want := range map[string]string{"post": "posts", "category": "categories", "day": "days", "box": "boxes"} {
+		if got := pluralize(in); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package crud
+
+// Templates use [[ ]] delimiters so templ's { } expressions pass through.
+
+const header = "// Code generated by `gospa add crud [[.Name]]`. This file is yours to edit; it is not regenerated.\n\n"
+
+const crudTmpl = header + `package [[.Package]]
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+[[- if .UsesStrings]]
+	"strings"
+[[- end]]
+[[- if .UsesUTF8]]
+	"unicode/utf8"
+[[- end]]
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"[[.ModelImport]]"
+)
+
+// [[.Name]]Store persists [[.Name]] values. Implement it with any database
+// library and assign it to Store before the app starts.
+type [[.Name]]Store interface {
+	List(ctx context.Context) ([][[.ModelPkg]].[[.Name]], error)
+	// Get returns nil, nil when no [[.Name]] has the ID.
+	Get(ctx context.Context, id [[.ID.Type]]) (*[[.ModelPkg]].[[.Name]], error)
+	// Create stores item and sets its ID.
+	Create(ctx context.Context, item *[[.ModelPkg]].[[.Name]]) error
+	Update(ctx context.Context, item *[[.ModelPkg]].[[.Name]]) error
+	Delete(ctx context.Context, id [[.ID.Type]]) error
+}
+
+// Store backs the [[.Plural]] pages and remote actions.
+var Store [[.Name]]Store
+
+// Authorize, if set, is called before each [[.Actions]].* remote action
+// ("list", "get", "create", "update", "delete"). Returning an error rejects
+// the call.
+var Authorize func(ctx context.Context, rc routing.RemoteContext, action string) error
+
+var errStoreNotSet = errors.New("[[.Plural]]: Store is not set")
+
+func init() {
+	register("list", func(ctx context.Context, _ interface{}) (interface{}, error) {
+		items, err := Store.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"items": items}, nil
+	})
+	register("get", func(ctx context.Context, input interface{}) (interface{}, error) {
+		id, err := idFrom(input)
+		if err != nil {
+			return nil, err
+		}
+		item, err := Store.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"item": item}, nil
+	})
+	register("create", func(ctx context.Context, input interface{}) (interface{}, error) {
+		var item [[.ModelPkg]].[[.Name]]
+		if err := decode(input, &item); err != nil {
+			return nil, err
+		}
+		if errs := Validate[[.Name]](&item); len(errs) > 0 {
+			return map[string]interface{}{"errors": errs}, nil
+		}
+		if err := Store.Create(ctx, &item); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"item": item}, nil
+	})
+	register("update", func(ctx context.Context, input interface{}) (interface{}, error) {
+		var item [[.ModelPkg]].[[.Name]]
+		if err := decode(input, &item); err != nil {
+			return nil, err
+		}
+		if errs := Validate[[.Name]](&item); len(errs) > 0 {
+			return map[string]interface{}{"errors": errs}, nil
+		}
+		if err := Store.Update(ctx, &item); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"item": item}, nil
+	})
+	register("delete", func(ctx context.Context, input interface{}) (interface{}, error) {
+		id, err := idFrom(input)
+		if err != nil {
+			return nil, err
+		}
+		if err := Store.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"deleted": true}, nil
+	})
+}
+
+// register adds the [[.Actions]].<name> remote action, checking Store and
+// Authorize first.
+func register(name string, fn func(ctx context.Context, input interface{}) (interface{}, error)) {
+	routing.RegisterRemoteAction("[[.Actions]]."+name, func(ctx context.Context, rc routing.RemoteContext, input interface{}) (interface{}, error) {
+		if Store == nil {
+			return nil, errStoreNotSet
+		}
+		if Authorize != nil {
+			if err := Authorize(ctx, rc, name); err != nil {
+				return nil, err
+			}
+		}
+		return fn(ctx, input)
+	})
+}
+
+// Validate[[.Name]] checks item against its validate tags and returns
+// messages keyed by JSON field name.
+func Validate[[.Name]](item *[[.ModelPkg]].[[.Name]]) map[string]string {
+	errs := make(map[string]string)
+[[- range .Fields]][[$f := .]][[range .Rules]]
+	if _, ok := errs["[[$f.JSON]]"]; !ok && [[.Cond]] {
+		errs["[[$f.JSON]]"] = "[[$f.Label]] [[.Msg]]"
+	}
+[[- end]][[end]]
+	return errs
+}
+
+// ParseID converts a route parameter to a [[.Name]] ID.
+func ParseID(s string) ([[.ID.Type]], error) {
+[[- if .StringID]]
+	if s == "" {
+		return "", errors.New("[[.Plural]]: empty id")
+	}
+	return s, nil
+[[- else]]
+	n, err := strconv.ParseInt(s, 10, 64)
+	return [[.ID.Type]](n), err
+[[- end]]
+}
+
+// Items converts the list page's load data.
+func Items(v interface{}) [][[.ModelPkg]].[[.Name]] {
+	items, _ := v.([][[.ModelPkg]].[[.Name]])
+	return items
+}
+
+// Item converts the detail and edit pages' load data.
+func Item(v interface{}) *[[.ModelPkg]].[[.Name]] {
+	item, _ := v.(*[[.ModelPkg]].[[.Name]])
+	return item
+}
+
+// idFrom reads {"id": ...} from a remote action input.
+func idFrom(input interface{}) ([[.ID.Type]], error) {
+	m, _ := input.(map[string]interface{})
+	switch v := m["id"].(type) {
+	case string:
+		return ParseID(v)
+	case float64:
+		return ParseID(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	var zero [[.ID.Type]]
+	return zero, errors.New("[[.Plural]]: id is required")
+}
+
+func decode(input, out interface{}) error {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+`
+
+const listServerTmpl = header + `package [[.Package]]
+
+import (
+	"github.com/aydenstechdungeon/gospa/db"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// Load lists every [[.Name]].
+func Load(c routing.LoadContext) (map[string]interface{}, error) {
+	if Store == nil {
+		return nil, errStoreNotSet
+	}
+	items, err := Store.List(db.Context(c))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"items": items}, nil
+}
+`
+
+// itemServerTmpl is the Load function shared by the detail and edit pages.
+func itemServerTmpl(pkg string) string {
+	return header + "package " + pkg + `
+
+import (
+	"errors"
+
+	"github.com/aydenstechdungeon/gospa/db"
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/routing/kit"
+	"[[.RoutesPkg]]"
+)
+
+// Load fetches the [[.Name]] named by the id route parameter.
+func Load(c routing.LoadContext) (map[string]interface{}, error) {
+	if [[.Package]].Store == nil {
+		return nil, errors.New("[[.Plural]]: Store is not set")
+	}
+	id, err := [[.Package]].ParseID(c.Param("id"))
+	if err != nil {
+		return nil, kit.Error(404, "[[.Name]] not found")
+	}
+	item, err := [[.Package]].Store.Get(db.Context(c), id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, kit.Error(404, "[[.Name]] not found")
+	}
+	return map[string]interface{}{"item": item}, nil
+}
+`
+}
+
+// formScript submits forms marked with data-crud-action through GoSPA.remote,
+// shows validation messages, and redirects on success.
+const formScript = `[[define "script"]]
+	<script nonce={ gospatempl.GetNonce(ctx) }>
+		document.querySelectorAll('form[data-crud-action]').forEach(function (form) {
+			form.addEventListener('submit', async function (event) {
+				event.preventDefault();
+				if (form.dataset.crudConfirm && !confirm(form.dataset.crudConfirm)) return;
+				var input = {};
+				for (var el of form.elements) {
+					if (!el.name) continue;
+					if (el.type === 'checkbox') input[el.name] = el.checked;
+					else if (el.type === 'number' || el.dataset.kind === 'number') input[el.name] = el.value === '' ? 0 : Number(el.value);
+					else input[el.name] = el.value;
+				}
+				form.querySelectorAll('[data-error-for]').forEach(function (el) { el.textContent = ''; });
+				var res = await GoSPA.remote(form.dataset.crudAction, input);
+				if (res.code !== 'SUCCESS') { alert(res.error || 'Request failed'); return; }
+				if (res.data && res.data.errors) {
+					Object.keys(res.data.errors).forEach(function (name) {
+						var el = form.querySelector('[data-error-for="' + name + '"]');
+						if (el) el.textContent = res.data.errors[name];
+					});
+					return;
+				}
+				var to = form.dataset.crudRedirect;
+				if (res.data && res.data.item) to += encodeURIComponent(res.data.item['[[.ID.JSON]]']);
+				location.href = to;
+			});
+		});
+	</script>
+[[end]]`
+
+// formFields renders an input per editable field; with .Edit set the values
+// come from item.
+const formFields = `[[define "fields"]][[$edit := .Edit]][[$i := .Indent]][[range .M.Fields]][[if .Editable]]
+[[$i]]<div class="crud-field">
+[[$i]]	<label for="[[.JSON]]">[[.Label]]</label>
+[[- if eq .Input "checkbox"]]
+[[$i]]	<input type="checkbox" id="[[.JSON]]" name="[[.JSON]]"[[if $edit]] checked?={ p.[[.Name]] }[[end]]/>
+[[- else]]
+[[$i]]	<input type="[[.Input]]" id="[[.JSON]]" name="[[.JSON]]"[[if eq .Kind "float"]] step="any"[[end]][[if $edit]] value={ fmt.Sprint(p.[[.Name]]) }[[end]]/>
+[[- end]]
+[[$i]]	<p class="crud-error" data-error-for="[[.JSON]]"></p>
+[[$i]]</div>
+[[- end]][[end]][[end]]`
+
+const listPageTmpl = `package [[.Package]]
+
+import "fmt"
+
+templ Page(items interface{}) {
+	<main class="crud">
+		<h1>[[.Name]]s</h1>
+		<p><a href="[[.URL]]/new">New [[.Name]]</a></p>
+		<table>
+			<thead>
+				<tr>
+					<th>ID</th>
+[[- range .Fields]]
+					<th>[[.Label]]</th>
+[[- end]]
+					<th></th>
+				</tr>
+			</thead>
+			<tbody>
+				for _, item := range Items(items) {
+					<tr>
+						<td>{ fmt.Sprint(item.ID) }</td>
+[[- range .Fields]]
+						<td>{ fmt.Sprint(item.[[.Name]]) }</td>
+[[- end]]
+						<td><a href={ templ.SafeURL(fmt.Sprintf("[[.URL]]/%v", item.ID)) }>View</a></td>
+					</tr>
+				}
+			</tbody>
+		</table>
+	</main>
+}
+`
+
+const detailPageTmpl = `package id
+
+import (
+	"fmt"
+
+	gospatempl "github.com/aydenstechdungeon/gospa/templ"
+	"[[.RoutesPkg]]"
+)
+
+templ Page(item interface{}) {
+	if p := [[.Package]].Item(item); p != nil {
+		<main class="crud">
+			<h1>[[.Name]] { fmt.Sprint(p.ID) }</h1>
+			<dl>
+[[- range .Fields]]
+				<dt>[[.Label]]</dt>
+				<dd>{ fmt.Sprint(p.[[.Name]]) }</dd>
+[[- end]]
+			</dl>
+			<p>
+				<a href={ templ.SafeURL(fmt.Sprintf("[[.URL]]/%v/edit", p.ID)) }>Edit</a>
+				<a href="[[.URL]]">Back</a>
+			</p>
+			<form data-crud-action="[[.Actions]].delete" data-crud-redirect="[[.URL]]" data-crud-confirm="Delete this [[.Name]]?">
+				<input type="hidden" name="id" value={ fmt.Sprint(p.ID) }/>
+				<button type="submit">Delete</button>
+			</form>
+		</main>
+		@formScript()
+	}
+}
+` + "\ntempl formScript() {[[template \"script\" .]]}\n" + formScript
+
+const newPageTmpl = `package new
+
+import gospatempl "github.com/aydenstechdungeon/gospa/templ"
+
+templ Page() {
+	<main class="crud">
+		<h1>New [[.Name]]</h1>
+		<form data-crud-action="[[.Actions]].create" data-crud-redirect="[[.URL]]/">
+[[- template "fields" (form . false)]]
+			<button type="submit">Create</button>
+		</form>
+		<p><a href="[[.URL]]">Back</a></p>
+	</main>
+	@formScript()
+}
+` + "\ntempl formScript() {[[template \"script\" .]]}\n" + formScript + formFields
+
+const editPageTmpl = `package edit
+
+import (
+	"fmt"
+
+	gospatempl "github.com/aydenstechdungeon/gospa/templ"
+	"[[.RoutesPkg]]"
+)
+
+templ Page(item interface{}) {
+	if p := [[.Package]].Item(item); p != nil {
+		<main class="crud">
+			<h1>Edit [[.Name]] { fmt.Sprint(p.ID) }</h1>
+			<form data-crud-action="[[.Actions]].update" data-crud-redirect="[[.URL]]/">
+				<input type="hidden" name="[[.ID.JSON]]" value={ fmt.Sprint(p.ID) }[[if not .StringID]] data-kind="number"[[end]]/>
+[[- template "fields" (form . true)]]
+				<button type="submit">Save</button>
+			</form>
+			<p><a href={ templ.SafeURL(fmt.Sprintf("[[.URL]]/%v", p.ID)) }>Cancel</a></p>
+		</main>
+		@formScript()
+	}
+}
+` + "\ntempl formScript() {[[template \"script\" .]]}\n" + formScript + formFields
@@ -91,6 +91,17 @@ type Config struct {
 
 	// RoutesDir is where route files are located.
 	RoutesDir string `yaml:"routes_dir" json:"routesDir"`
+
+	// Disallow lists path prefixes robots.txt asks crawlers to skip.
+	Disallow []string `yaml:"disallow" json:"disallow"`
+
+	// RevalidateAfter is how long an App serves its generated robots.txt and
+	// sitemap.xml before rebuilding them in the background (default 1h).
+	RevalidateAfter time.Duration `yaml:"revalidate_after" json:"revalidateAfter"`
+
+	// DynamicPages adds pages the router cannot list, such as one per blog
+	// post for /blog/[slug], to the sitemap an App serves.
+	DynamicPages func(ctx context.Context) ([]PageSEO, error) `yaml:"-" json:"-"`
 }
 
 // MetaConfig represents SEO metadata for a page.
@@ -379,6 +390,19 @@ func (p *Plugin) discoverPages(projectDir string) ([]PageSEO, error) {
 
 // generateSitemap generates sitemap.xml.
 func (p *Plugin) generateSitemap(pages []PageSEO) error {
+	sitemapPath := filepath.Join(p.config.OutputDir, "sitemap.xml")
+	return os.WriteFile(sitemapPath, Sitemap(p.config.SiteURL, pages), 0600)
+}
+
+// generateRobots generates robots.txt.
+func (p *Plugin) generateRobots() error {
+	robotsPath := filepath.Join(p.config.OutputDir, "robots.txt")
+	return os.WriteFile(robotsPath, Robots(p.config), 0600)
+}
+
+// Sitemap renders a sitemap.xml listing pages under siteURL. NoIndex pages
+// are skipped, as are empty Modified, ChangeFreq and Priority fields.
+func Sitemap(siteURL string, pages []PageSEO) []byte {
 	var sb strings.Builder
 	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
 	sb.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
@@ -388,32 +412,37 @@ func (p *Plugin) generateSitemap(pages []PageSEO) error {
 			continue
 		}
 
-		url := p.config.SiteURL + page.Path
 		sb.WriteString("  <url>\n")
-		fmt.Fprintf(&sb, "    <loc>%s</loc>\n", url)
-		fmt.Fprintf(&sb, "    <lastmod>%s</lastmod>\n", page.Modified)
-		fmt.Fprintf(&sb, "    <changefreq>%s</changefreq>\n", page.ChangeFreq)
-		fmt.Fprintf(&sb, "    <priority>%.1f</priority>\n", page.Priority)
+		fmt.Fprintf(&sb, "    <loc>%s</loc>\n", html.EscapeString(siteURL+page.Path))
+		if page.Modified != "" {
+			fmt.Fprintf(&sb, "    <lastmod>%s</lastmod>\n", html.EscapeString(page.Modified))
+		}
+		if page.ChangeFreq != "" {
+			fmt.Fprintf(&sb, "    <changefreq>%s</changefreq>\n", html.EscapeString(page.ChangeFreq))
+		}
+		if page.Priority > 0 {
+			fmt.Fprintf(&sb, "    <priority>%.1f</priority>\n", page.Priority)
+		}
 		sb.WriteString("  </url>\n")
 	}
 
 	sb.WriteString("</urlset>\n")
-
-	sitemapPath := filepath.Join(p.config.OutputDir, "sitemap.xml")
-	return os.WriteFile(sitemapPath, []byte(sb.String()), 0600)
+	return []byte(sb.String())
 }
 
-// generateRobots generates robots.txt.
-func (p *Plugin) generateRobots() error {
+// Robots renders a robots.txt that allows everything except cfg.Disallow and
+// points crawlers at the sitemap.
+func Robots(cfg *Config) []byte {
 	var sb strings.Builder
-	sb.WriteString("# robots.txt for " + p.config.SiteName + "\n")
+	sb.WriteString("# robots.txt for " + cfg.SiteName + "\n")
 	sb.WriteString("User-agent: *\n")
 	sb.WriteString("Allow: /\n")
+	for _, prefix := range cfg.Disallow {
+		sb.WriteString("Disallow: " + prefix + "\n")
+	}
 	sb.WriteString("\n")
-	sb.WriteString("Sitemap: " + p.config.SiteURL + "/sitemap.xml\n")
-
-	robotsPath := filepath.Join(p.config.OutputDir, "robots.txt")
-	return os.WriteFile(robotsPath, []byte(sb.String()), 0600)
+	sb.WriteString("Sitemap: " + cfg.SiteURL + "/sitemap.xml\n")
+	return []byte(sb.String())
 }
 
 // generateMetaTags generates meta tags for a page.
@@ -0,0 +1,154 @@
+package gospa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/store"
+	json "github.com/goccy/go-json"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+const (
+	// IdempotencyKeyHeader lets clients retry a remote action without running
+	// its side effects twice.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	remoteResultKeyPrefix = "gospa:remote:"
+	defaultIdempotencyTTL = 24 * time.Hour
+	maxIdempotencyKeyLen  = 255
+)
+
+// remoteReuse says where a remote action result is looked up and stored.
+type remoteReuse struct {
+	key        string
+	ttl        time.Duration
+	inputHash  string
+	idempotent bool
+}
+
+// storedRemoteResult is a successful remote action response in Storage.
+type storedRemoteResult struct {
+	InputHash string `json:"i,omitempty"`
+	Body      []byte `json:"b"`
+}
+
+// remoteReuseFor returns how the call may reuse a stored result: by its
+// Idempotency-Key header, or by input when the action has a CacheTTL. Keys
+// are scoped to the caller's session cookie, or its IP without one.
+func remoteReuseFor(c fiberpkg.Ctx, name string, opts routing.RemoteActionOptions, body []byte) (remoteReuse, bool) {
+	scope := c.Cookies("gospa_session")
+	if scope == "" {
+		scope = "ip:" + c.IP()
+	}
+	inputSum := sha256.Sum256(body)
+	inputHash := hex.EncodeToString(inputSum[:])
+
+	if idemKey := c.Get(IdempotencyKeyHeader); idemKey != "" {
+		ttl := opts.IdempotencyTTL
+		if ttl <= 0 {
+			ttl = defaultIdempotencyTTL
+		}
+		return remoteReuse{
+			key:        remoteResultKeyPrefix + "idem:" + hashParts(name, scope, idemKey),
+			ttl:        ttl,
+			inputHash:  inputHash,
+			idempotent: true,
+		}, true
+	}
+	if opts.CacheTTL > 0 {
+		return remoteReuse{
+			key: remoteResultKeyPrefix + "cache:" + hashParts(name, scope, inputHash),
+			ttl: opts.CacheTTL,
+		}, true
+	}
+	return remoteReuse{}, false
+}
+
+// hashParts hashes length-prefixed parts so no two part lists collide.
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(strconv.Itoa(len(p))))
+		h.Write([]byte{':'})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claimRemoteCall waits for an identical call already running in this
+// process to finish, then claims the key. Callers must call the returned
+// release func.
+func (a *App) claimRemoteCall(ctx context.Context, key string) (func(), error) {
+	for {
+		a.remoteInflightMu.Lock()
+		if a.remoteInflight == nil {
+			a.remoteInflight = make(map[string]chan struct{})
+		}
+		done, busy := a.remoteInflight[key]
+		if !busy {
+			done = make(chan struct{})
+			a.remoteInflight[key] = done
+			a.remoteInflightMu.Unlock()
+			return func() {
+				a.remoteInflightMu.Lock()
+				delete(a.remoteInflight, key)
+				a.remoteInflightMu.Unlock()
+				close(done)
+			}, nil
+		}
+		a.remoteInflightMu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// loadRemoteResult returns a stored result for reuse, or nil.
+func (a *App) loadRemoteResult(ctx context.Context, reuse remoteReuse) (*storedRemoteResult, error) {
+	data, err := a.Config.Storage.Get(ctx, reuse.key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored storedRemoteResult
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+// saveRemoteResult stores a successful response body for reuse.
+func (a *App) saveRemoteResult(ctx context.Context, reuse remoteReuse, body []byte) {
+	data, err := json.Marshal(storedRemoteResult{InputHash: reuse.inputHash, Body: body})
+	if err == nil {
+		err = a.Config.Storage.Set(ctx, reuse.key, data, reuse.ttl)
+	}
+	if err != nil {
+		a.Logger().Warn("failed to store remote action result", "err", err)
+	}
+}
+
+// sendStoredRemoteResult answers with a stored result. An idempotency key
+// reused with different input is rejected rather than replayed.
+func (a *App) sendStoredRemoteResult(c fiberpkg.Ctx, reuse remoteReuse, stored *storedRemoteResult) error {
+	if reuse.idempotent {
+		if stored.InputHash != reuse.inputHash {
+			return a.sendError(c, NewError(CodeIdempotencyKeyReused, fiberpkg.StatusUnprocessableEntity, "Idempotency-Key was already used with different input"))
+		}
+		c.Set(IdempotentReplayedHeader, "true")
+	}
+	c.Set(fiberpkg.HeaderContentType, fiberpkg.MIMEApplicationJSONCharsetUTF8)
+	return c.Send(stored.Body)
+}
@@ -0,0 +1,85 @@
+package gospa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/gofiber/fiber/v3"
+)
+
+func newRemoteCacheTestApp(t *testing.T, opts routing.RemoteActionOptions) (*App, string, *atomic.Int32) {
+	t.Helper()
+	name := strings.ReplaceAll(t.Name(), "/", "_")
+	calls := new(atomic.Int32)
+	routing.RegisterRemoteActionWithOptions(name, func(_ context.Context, _ routing.RemoteContext, input interface{}) (interface{}, error) {
+		return map[string]interface{}{"n": calls.Add(1), "input": input}, nil
+	}, opts)
+
+	app := New(Config{DevMode: true})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	t.Cleanup(func() { _ = app.Fiber.Shutdown() })
+	return app, name, calls
+}
+
+func callRemote(t *testing.T, app *App, name, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/remote/"+name, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	addValidCSRF(req)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	data, _ := io.ReadAll(res.Body)
+	return res, string(data)
+}
+
+func TestRemoteActionCacheTTL(t *testing.T) {
+	app, name, calls := newRemoteCacheTestApp(t, routing.RemoteActionOptions{CacheTTL: time.Minute})
+
+	_, first := callRemote(t, app, name, `{"q":1}`, nil)
+	_, second := callRemote(t, app, name, `{"q":1}`, nil)
+	if first != second || calls.Load() != 1 {
+		t.Fatalf("identical call ran the action again: %d calls, %s vs %s", calls.Load(), first, second)
+	}
+	callRemote(t, app, name, `{"q":2}`, nil)
+	if calls.Load() != 2 {
+		t.Fatalf("different input must run the action, got %d calls", calls.Load())
+	}
+}
+
+func TestRemoteActionIdempotencyKey(t *testing.T) {
+	app, name, calls := newRemoteCacheTestApp(t, routing.RemoteActionOptions{})
+	key := map[string]string{IdempotencyKeyHeader: "order-42"}
+
+	_, first := callRemote(t, app, name, `{"item":"a"}`, key)
+	res, retry := callRemote(t, app, name, `{"item":"a"}`, key)
+	if calls.Load() != 1 || retry != first {
+		t.Fatalf("retry ran the action again: %d calls", calls.Load())
+	}
+	if res.Header.Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("replayed response missing Idempotent-Replayed header")
+	}
+
+	res, _ = callRemote(t, app, name, `{"item":"b"}`, key)
+	if res.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Fatalf("key reused with different input: status %d, want 422", res.StatusCode)
+	}
+
+	// Without a key or CacheTTL every call runs.
+	callRemote(t, app, name, `{"item":"a"}`, nil)
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
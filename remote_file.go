@@ -0,0 +1,38 @@
+package gospa
+
+import (
+	"mime"
+	"path/filepath"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// sendRemoteFile streams a remote action's file result as a download.
+func sendRemoteFile(c fiberpkg.Ctx, file *routing.FileResult) error {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(file.Name))
+	}
+	if contentType == "" {
+		contentType = fiberpkg.MIMEOctetStream
+	}
+	disposition := "attachment"
+	if name := filepath.Base(file.Name); file.Name != "" && name != "." && name != "/" {
+		// FormatMediaType quotes the name, or encodes it per RFC 2231 when it
+		// is not ASCII, and returns "" for names it cannot encode.
+		if d := mime.FormatMediaType("attachment", map[string]string{"filename": name}); d != "" {
+			disposition = d
+		}
+	}
+	c.Set(fiberpkg.HeaderContentType, contentType)
+	c.Set(fiberpkg.HeaderContentDisposition, disposition)
+	c.Set(fiberpkg.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiberpkg.HeaderCacheControl, "no-store")
+	if file.Reader == nil {
+		return c.Send(nil)
+	}
+	// fasthttp closes the stream once it is sent when it is an io.Closer.
+	return c.SendStream(file.Reader)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
 )
 
 // ErrJSONTooDeep is returned when remote action JSON exceeds [remoteJSONMaxNesting].
@@ -15,10 +17,19 @@ const remoteJSONMaxNesting = 64
 
 // decodeRemoteActionBody parses JSON for remote actions with bounded nesting depth and
 // json.Number for numeric values (avoids float64 surprises for large integers).
+// A codec installed with SetJSONCodec takes over decoding once nesting has
+// been checked.
 func decodeRemoteActionBody(body []byte) (interface{}, error) {
 	if err := validateJSONMaxNesting(body, remoteJSONMaxNesting); err != nil {
 		return nil, err
 	}
+	if fiber.HasCustomJSONCodec() {
+		var v interface{}
+		if err := fiber.JSONUnmarshal(body, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
 	dec := json.NewDecoder(bytes.NewReader(body))
 	dec.UseNumber()
 	var v interface{}
@@ -49,3 +49,29 @@ func TestDecodeRemoteActionBody_RejectsTrailingJSON(t *testing.T) {
 		t.Fatalf("expected trailing data error, got %v", err)
 	}
 }
+
+func TestDecodeRemoteActionBody_CustomCodec(t *testing.T) {
+	var calls int
+	SetJSONCodec(nil, func(data []byte, v interface{}) error {
+		calls++
+		return stdjson.Unmarshal(data, v)
+	})
+	t.Cleanup(func() { SetJSONCodec(nil, nil) })
+
+	v, err := decodeRemoteActionBody([]byte(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected custom codec to decode the body, calls=%d", calls)
+	}
+	if m, ok := v.(map[string]interface{}); !ok || m["a"] != float64(1) {
+		t.Fatalf("unexpected value: %#v", v)
+	}
+
+	n := remoteJSONMaxNesting + 1
+	deep := []byte(strings.Repeat(`[`, n) + strings.Repeat(`]`, n))
+	if _, err := decodeRemoteActionBody(deep); !errors.Is(err, ErrJSONTooDeep) {
+		t.Fatalf("nesting limit must still apply, got %v", err)
+	}
+}
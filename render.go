@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/embed"
 	gospafiber "github.com/aydenstechdungeon/gospa/fiber"
 	"github.com/aydenstechdungeon/gospa/routing"
 	"github.com/aydenstechdungeon/gospa/routing/kit"
@@ -20,10 +24,18 @@ import (
 )
 
 // renderRoute renders a route with its layout chain.
-func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[string]interface{}) error {
-	cacheKey := routeCacheKey(c)
+func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[string]interface{}) (err error) {
+	start := time.Now()
+	defer func() {
+		if err == nil {
+			a.emitNavigation(c, route, start)
+		}
+	}()
 	ctx := c.Context()
 	opts := routing.GetRouteOptions(route.Path)
+	baseKey := routeCacheKey(c)
+	variant := a.requestVariant(c, opts)
+	cacheKey := variantCacheKey(baseKey, variant)
 
 	effStrategy := opts.Strategy
 	if effStrategy == "" {
@@ -32,11 +44,31 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	if effStrategy == "" {
 		effStrategy = routing.StrategySSR
 	}
+	if a.Config.RequestMode {
+		effStrategy = requestModeStrategy(effStrategy)
+	}
+	prof := requestProfileFromCtx(c)
+	if prof != nil {
+		prof.record("match", "", prof.start, time.Now())
+		prof.Route = route.Path
+		prof.Strategy = string(effStrategy)
+		ctx = withRequestProfile(ctx, prof)
+	}
 	if !a.Config.CacheTemplates && (effStrategy == routing.StrategySSG || effStrategy == routing.StrategyISR || effStrategy == routing.StrategyPPR) {
 		return c.Status(gofiber.StatusInternalServerError).SendString(
 			fmt.Sprintf("render strategy %q requires CacheTemplates=true", effStrategy),
 		)
 	}
+	if robots := opts.Robots(); robots != "" {
+		c.Set("X-Robots-Tag", robots)
+	}
+	if hasRouteCacheHeaders(opts) {
+		defer func() {
+			if err == nil {
+				a.applyRouteCacheHeaders(c, opts, route.Path, string(effStrategy))
+			}
+		}()
+	}
 
 	// 1. SSG Strategy
 	if a.Config.CacheTemplates && effStrategy == routing.StrategySSG {
@@ -52,20 +84,20 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			a.ssgCacheMu.RUnlock()
 		}
 
-		if hit && a.Config.SSGCacheTTL > 0 && time.Since(entry.createdAt) >= a.Config.SSGCacheTTL {
+		if hit && a.Config.SSGCacheTTL > 0 && a.now().Sub(entry.createdAt) >= a.Config.SSGCacheTTL {
 			hit = false
 		}
 
 		if hit {
 			a.recordCacheHit(cacheKey)
-			c.Set("Content-Type", "text/html")
+			a.setRenderHeader(c, RenderDetails{Strategy: effStrategy, Cache: CacheHit, GeneratedAt: entry.createdAt, Locale: variant.Locale, Device: variant.Device})
 			currentNonce, _ := c.Locals("gospa.csp_nonce").(string)
 			if currentNonce != "" {
 				c.Set("Cache-Control", "no-cache")
 			} else {
 				c.Set("Cache-Control", "public, max-age=31536000, immutable")
 			}
-			return c.Send(a.replaceNonces(entry.html, currentNonce))
+			return a.sendCachedPage(c, entry, currentNonce)
 		}
 	}
 	if a.Config.CacheTemplates && effStrategy == routing.StrategyISR {
@@ -91,29 +123,33 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			a.ssgCacheMu.RUnlock()
 		}
 
-		if hit && a.Config.SSGCacheTTL > 0 && time.Since(entry.createdAt) >= a.Config.SSGCacheTTL {
+		if hit && a.Config.SSGCacheTTL > 0 && a.now().Sub(entry.createdAt) >= a.Config.SSGCacheTTL {
 			hit = false
 		}
 
 		if hit {
 			a.recordCacheHit(cacheKey)
-			age := time.Since(entry.createdAt)
+			age := a.now().Sub(entry.createdAt)
+			status := CacheHit
 			if ttl > 0 && age >= ttl {
+				status = CacheStale
 				a.recordCacheStaleServed(cacheKey)
-				if _, alreadyRunning := a.isrRevalidating.LoadOrStore(cacheKey, true); !alreadyRunning {
-					a.recordCacheRevalidation(cacheKey)
-					go a.backgroundRevalidate(cacheKey, route) // #nosec //nolint:gosec // intentional: background revalidation uses independent context
+				// cacheKey may alias the request's path buffer, which fasthttp
+				// reuses once the request is done.
+				key := strings.Clone(cacheKey)
+				if _, alreadyRunning := a.isrRevalidating.LoadOrStore(key, true); !alreadyRunning {
+					a.recordCacheRevalidation(key)
+					go a.backgroundRevalidate(key, route) // #nosec //nolint:gosec // intentional: background revalidation uses independent context
 				}
 			}
-			c.Set("Content-Type", "text/html")
-
+			a.setRenderHeader(c, RenderDetails{Strategy: effStrategy, Cache: status, GeneratedAt: entry.createdAt, Locale: variant.Locale, Device: variant.Device})
 			currentNonce, _ := c.Locals("gospa.csp_nonce").(string)
 			if currentNonce != "" {
 				c.Set("Cache-Control", "no-cache")
 			} else {
-				c.Set("Cache-Control", fmt.Sprintf("public, s-maxage=%d, stale-while-revalidate=%d", ttlSec, ttlSec))
+				c.Set("Cache-Control", isrCacheControl(ttlSec))
 			}
-			return c.Send(a.replaceNonces(entry.html, currentNonce))
+			return a.sendCachedPage(c, entry, currentNonce)
 		}
 		a.recordCacheMiss(cacheKey)
 	}
@@ -130,7 +166,7 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 		} else {
 			a.pprShellMu.RLock()
 			p, hit := a.pprShellCache[cacheKey]
-			if hit && (a.Config.SSGCacheTTL <= 0 || time.Since(p.createdAt) < a.Config.SSGCacheTTL) {
+			if hit && (a.Config.SSGCacheTTL <= 0 || a.now().Sub(p.createdAt) < a.Config.SSGCacheTTL) {
 				shell = p.html
 				shellHit = true
 			}
@@ -139,7 +175,9 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 
 		if shellHit {
 			a.recordCacheHit(cacheKey)
-			result, err := a.applyPPRSlots(ctx, route, shell, c.Path(), opts)
+			info := RenderDetails{Strategy: effStrategy, Cache: CacheHit, GeneratedAt: a.now(), Locale: variant.Locale, Device: variant.Device}
+			a.setRenderHeader(c, info)
+			result, err := a.applyPPRSlots(withRenderInfo(ctx, info), route, shell, c.Path(), opts)
 			if err != nil {
 				a.Logger().Error("PPR slot error", "err", err)
 			}
@@ -149,11 +187,18 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			currentNonce, _ := c.Locals("gospa.csp_nonce").(string)
 			result = a.replaceNonces(result, currentNonce)
 
-			return c.Send(result)
+			return c.Send(a.transformHTML(route, result))
 		}
 		a.recordCacheMiss(cacheKey)
 	}
 
+	info := RenderDetails{Strategy: effStrategy, Cache: CacheBypass, GeneratedAt: a.now(), Locale: variant.Locale, Device: variant.Device}
+	if effStrategy != routing.StrategySSR {
+		info.Cache = CacheMiss
+	}
+	ctx = withRenderInfo(ctx, info)
+	a.setRenderHeader(c, info)
+
 	layouts := a.Router.ResolveLayoutChain(route)
 	if routeParams == nil {
 		routeParams = map[string]interface{}{}
@@ -162,7 +207,9 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	tier, tierReason := a.resolveTierWithReason(opts, layouts)
 	c.Set("X-GoSPA-Runtime-Tier", tier)
 	c.Set("X-GoSPA-Runtime-Tier-Reason", tierReason)
-	c.Set("X-GoSPA-Runtime-Script", a.getRuntimePathForTier(tier))
+	if script := a.getRuntimePathForTier(tier); script != "" {
+		c.Set("X-GoSPA-Runtime-Script", script)
+	}
 	if a.Config.DevMode {
 		a.Logger().Debug(
 			"runtime decision",
@@ -174,7 +221,9 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	}
 
 	// Resolve data load chain
+	endLoaders := prof.span("loaders", "")
 	loadedProps, depKeys, err := a.resolveLoadChain(c, route, layouts)
+	endLoaders()
 	if err != nil {
 		if redirectErr, ok := kit.AsRedirect(err); ok {
 			if c.Query("__data") == "1" {
@@ -212,7 +261,25 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			}
 			return a.renderError(c, httpErr.Status, fmt.Errorf("HTTP %d", httpErr.Status))
 		}
-		a.Logger().Error("Load error", "err", err)
+		var appErr *Error
+		if errors.As(err, &appErr) {
+			if appErr.HTTPStatus >= gofiber.StatusInternalServerError {
+				a.Logger().Error("Load error", "code", appErr.Code, "request_id", gospafiber.GetRequestID(c), "err", err)
+			}
+			if c.Query("__data") == "1" {
+				return c.Status(appErr.HTTPStatus).JSON(gofiber.Map{
+					"kind":      "error",
+					"status":    appErr.HTTPStatus,
+					"error":     appErr.UserMessage,
+					"code":      appErr.Code,
+					"requestId": gospafiber.GetRequestID(c),
+					"path":      c.Path(),
+					"routePath": route.Path,
+				})
+			}
+			return a.renderError(c, appErr.HTTPStatus, appErr)
+		}
+		a.Logger().Error("Load error", "request_id", gospafiber.GetRequestID(c), "err", err)
 		return a.renderError(c, gofiber.StatusInternalServerError, err)
 	}
 
@@ -221,7 +288,7 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 		loadedProps[k] = v
 	}
 	cacheTags := a.defaultCacheTags(route.Path, string(effStrategy))
-	cacheKeys := a.defaultCacheKeys(cacheKey)
+	cacheKeys := a.defaultCacheKeys(baseKey)
 	cacheTags = append(cacheTags, dependencyTags(depKeys)...)
 	cacheKeys = append(cacheKeys, dependencyKeys(depKeys)...)
 	c.Set("X-GoSPA-Cache-Tags", strings.Join(cacheTags, ","))
@@ -244,10 +311,19 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	if nonce, ok := c.Locals("gospa.csp_nonce").(string); ok && nonce != "" {
 		ctx = templpkg.WithNonce(ctx, nonce)
 	}
+	// SPA navigations leave out templpkg.ServerOnly content. Cached strategies
+	// always render the full page, since the entry also serves full loads.
+	if effStrategy == routing.StrategySSR && gospafiber.IsSPANavigation(c) {
+		ctx = templpkg.WithSPANavigation(ctx)
+		c.Append("Vary", "X-Requested-With")
+	}
 	registry := state.NewRegistry()
 	ctx = context.WithValue(ctx, state.RegistryContextKey, registry)
+	// One templ render context for the layouts and the page, so Once blocks
+	// such as ScopedCSS styles are written once per page.
+	ctx = templ.InitializeContext(ctx)
 
-	content := a.buildPageContent(route, loadedProps, c.Path())
+	content := profiledComponent(prof, "page", route.Path, a.buildPageContent(route, loadedProps, c.Path()))
 	content = a.wrapWithLayouts(content, layouts, loadedProps, c.Path())
 
 	c.Set("Content-Type", "text/html")
@@ -255,25 +331,34 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	rootLayoutFunc := routing.GetRootLayout()
 	if rootLayoutFunc != nil {
 		rootProps := a.buildRootLayoutProps(c, routeParams, tier)
+		defer releaseRootLayoutProps(rootProps)
 		// Merge loaded props into root props if they don't conflict
 		for k, v := range loadedProps {
 			if _, ok := rootProps[k]; !ok {
 				rootProps[k] = v
 			}
 		}
-		wrappedContent := rootLayoutFunc(content, rootProps)
+		addPageMetaProps(rootProps, route.Path)
+		rootLayout := templpkg.WithLayoutSlots(rootLayoutFunc(content, rootProps), templpkg.LayoutSlotsOf(content))
+		wrappedContent := profiledComponent(prof, "layout", route.Path, rootLayout)
 
 		if a.Config.CacheTemplates && effStrategy == routing.StrategySSG {
-			var buf bytes.Buffer
-			if err := wrappedContent.Render(ctx, &buf); err != nil {
+			buf := acquireRenderBuffer()
+			defer releaseRenderBuffer(buf)
+			if err := wrappedContent.Render(ctx, buf); err != nil {
 				a.Logger().Error("SSG render error", "err", err)
 				return a.renderError(c, gofiber.StatusInternalServerError, err)
 			}
 
-			htmlBytes := buf.Bytes()
+			page := a.transformHTML(route, buf.Bytes())
+
 			// Prepare for caching: replace the current nonce with a placeholder.
+			// The cache keeps htmlBytes, so it must not alias the pooled buffer.
+			var htmlBytes []byte
 			if nonce, ok := c.Locals("gospa.csp_nonce").(string); ok && nonce != "" {
-				htmlBytes = bytes.ReplaceAll(htmlBytes, []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+				htmlBytes = bytes.ReplaceAll(page, []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+			} else {
+				htmlBytes = bytes.Clone(page)
 			}
 
 			a.storeSsgEntry(cacheKey, htmlBytes, cacheTags, cacheKeys)
@@ -282,7 +367,7 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			} else {
 				c.Set("Cache-Control", "public, max-age=31536000, immutable")
 			}
-			return c.Send(buf.Bytes())
+			return sendRendered(c, page)
 		}
 
 		if a.Config.CacheTemplates && effStrategy == routing.StrategyISR {
@@ -294,25 +379,31 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			if ttlSec <= 0 {
 				ttlSec = 1
 			}
-			var buf bytes.Buffer
-			if err := wrappedContent.Render(ctx, &buf); err != nil {
+			buf := acquireRenderBuffer()
+			defer releaseRenderBuffer(buf)
+			if err := wrappedContent.Render(ctx, buf); err != nil {
 				a.Logger().Error("ISR render error", "err", err)
 				return a.renderError(c, gofiber.StatusInternalServerError, err)
 			}
 
-			htmlBytes := buf.Bytes()
+			page := a.transformHTML(route, buf.Bytes())
+
 			// Prepare for caching: replace the current nonce with a placeholder.
+			// The cache keeps htmlBytes, so it must not alias the pooled buffer.
+			var htmlBytes []byte
 			if nonce, ok := c.Locals("gospa.csp_nonce").(string); ok && nonce != "" {
-				htmlBytes = bytes.ReplaceAll(htmlBytes, []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+				htmlBytes = bytes.ReplaceAll(page, []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+			} else {
+				htmlBytes = bytes.Clone(page)
 			}
 
 			a.storeSsgEntry(cacheKey, htmlBytes, cacheTags, cacheKeys)
 			if nonce, _ := c.Locals("gospa.csp_nonce").(string); nonce != "" {
 				c.Set("Cache-Control", "no-cache")
 			} else {
-				c.Set("Cache-Control", fmt.Sprintf("public, s-maxage=%d, stale-while-revalidate=%d", ttlSec, ttlSec))
+				c.Set("Cache-Control", isrCacheControl(ttlSec))
 			}
-			return c.Send(buf.Bytes())
+			return sendRendered(c, page)
 		}
 
 		if a.Config.CacheTemplates && effStrategy == routing.StrategyPPR {
@@ -329,25 +420,31 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 					ld := loadingFn(map[string]interface{}{})
 					ld = a.wrapWithLayouts(ld, layouts, loadedProps, c.Path())
 					rootProps := a.buildRootLayoutProps(c, loadedProps, tier)
+					defer releaseRootLayoutProps(rootProps)
 					// Merge loaded props into root props if they don't conflict
 					for k, v := range loadedProps {
 						if _, ok := rootProps[k]; !ok {
 							rootProps[k] = v
 						}
 					}
-					shellContent = rootLayoutFunc(ld, rootProps)
+					addPageMetaProps(rootProps, route.Path)
+					shellContent = profiledComponent(prof, "layout", route.Path, rootLayoutFunc(ld, rootProps))
 				}
 
-				var shellBuf bytes.Buffer
-				if err := shellContent.Render(shellCtx, &shellBuf); err != nil {
+				shellBuf := acquireRenderBuffer()
+				defer releaseRenderBuffer(shellBuf)
+				if err := shellContent.Render(shellCtx, shellBuf); err != nil {
 					a.Logger().Error("PPR shell render error", "err", err)
 					return a.renderError(c, gofiber.StatusInternalServerError, err)
 				}
 
-				shellBytes := shellBuf.Bytes()
 				// Prepare for caching: replace current nonce with a placeholder.
+				// The cache keeps shellBytes, so it must not alias the pooled buffer.
+				var shellBytes []byte
 				if nonce, ok := c.Locals("gospa.csp_nonce").(string); ok && nonce != "" {
-					shellBytes = bytes.ReplaceAll(shellBytes, []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+					shellBytes = bytes.ReplaceAll(shellBuf.Bytes(), []byte(nonce), []byte("__GOSPA_NONCE_PLACEHOLDER__"))
+				} else {
+					shellBytes = bytes.Clone(shellBuf.Bytes())
 				}
 
 				a.storePprShell(cacheKey, shellBytes, cacheTags, cacheKeys)
@@ -357,7 +454,7 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 					return a.renderError(c, gofiber.StatusInternalServerError, err)
 				}
 				c.Set("Cache-Control", "no-store")
-				return c.Send(result)
+				return sendRendered(c, a.transformHTML(route, result))
 			}
 			<-actual.(chan struct{})
 
@@ -370,7 +467,7 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 			} else {
 				a.pprShellMu.RLock()
 				p, hit := a.pprShellCache[cacheKey]
-				if hit && (a.Config.SSGCacheTTL <= 0 || time.Since(p.createdAt) < a.Config.SSGCacheTTL) {
+				if hit && (a.Config.SSGCacheTTL <= 0 || a.now().Sub(p.createdAt) < a.Config.SSGCacheTTL) {
 					shellHTML, shellOk = p.html, true
 				}
 				a.pprShellMu.RUnlock()
@@ -383,30 +480,30 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 				}
 				c.Set("Cache-Control", "no-store")
 				currentNonce, _ := c.Locals("gospa.csp_nonce").(string)
-				return c.Send(a.replaceNonces(result, currentNonce))
+				return c.Send(a.transformHTML(route, a.replaceNonces(result, currentNonce)))
 			}
 
-			var fallbackBuf bytes.Buffer
-			if err := wrappedContent.Render(ctx, &fallbackBuf); err != nil {
+			fallbackBuf := acquireRenderBuffer()
+			defer releaseRenderBuffer(fallbackBuf)
+			if err := wrappedContent.Render(ctx, fallbackBuf); err != nil {
 				a.Logger().Error("PPR fallback render error", "err", err)
 				return a.renderError(c, gofiber.StatusInternalServerError, err)
 			}
 			c.Set("Cache-Control", "no-store")
-			return c.Send(fallbackBuf.Bytes())
+			return sendRendered(c, a.transformHTML(route, fallbackBuf.Bytes()))
 		}
 
 		c.Set("Cache-Control", "no-store")
-		var buf bytes.Buffer
-		if err := wrappedContent.Render(ctx, &buf); err != nil {
+		buf := acquireRenderBuffer()
+		defer releaseRenderBuffer(buf)
+		if err := wrappedContent.Render(ctx, buf); err != nil {
 			a.Logger().Error("render error", "err", err)
 			return a.renderError(c, gofiber.StatusInternalServerError, err)
 		}
-		return c.Send(buf.Bytes())
+		return sendRendered(c, a.transformHTML(route, buf.Bytes()))
 	}
 
-	wsURL := a.getWSUrl(c)
 	runtimePath := a.getRuntimePath()
-	wsRD, wsMR, wsHB := a.normalizeWSConfig()
 
 	c.Set("Cache-Control", "no-store")
 	cspNonce, _ := c.Locals("gospa.csp_nonce").(string)
@@ -414,47 +511,78 @@ func (a *App) renderRoute(c gofiber.Ctx, route *routing.Route, routeParams map[s
 	if cspNonce != "" {
 		nonceFmt = ` nonce="` + html.EscapeString(cspNonce) + `"`
 	}
-	var out bytes.Buffer
-	_, _ = fmt.Fprint(&out, `<!DOCTYPE html><html lang="en" data-gospa-auto><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>`)
-	// SECURITY: Escape AppName to prevent XSS via title injection.
-	_, _ = fmt.Fprint(&out, html.EscapeString(a.Config.AppName))
-	_, _ = fmt.Fprint(&out, `</title></head><body><div id="app" data-gospa-root><main>`)
-	if err := content.Render(ctx, &out); err != nil {
+	out := acquireRenderBuffer()
+	defer releaseRenderBuffer(out)
+	_, _ = fmt.Fprint(out, `<!DOCTYPE html><html lang="en" data-gospa-auto><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>`)
+	meta, _ := routing.GetPageMeta(route.Path)
+	title := meta.Title
+	if title == "" {
+		title = a.Config.AppName
+	}
+	// SECURITY: Escape the title and description to prevent XSS via head injection.
+	_, _ = fmt.Fprint(out, html.EscapeString(title))
+	_, _ = fmt.Fprint(out, `</title>`)
+	if meta.Description != "" {
+		_, _ = fmt.Fprintf(out, `<meta name="description" content="%s">`, html.EscapeString(meta.Description))
+	}
+	if robots := opts.Robots(); robots != "" {
+		_, _ = fmt.Fprintf(out, `<meta name="robots" content="%s">`, robots)
+	}
+	_, _ = fmt.Fprint(out, `</head><body><div id="app" data-gospa-root><main>`)
+	if err := profiledComponent(prof, "layout", route.Path, content).Render(ctx, out); err != nil {
 		a.Logger().Error("render error", "err", err)
 		return a.renderError(c, gofiber.StatusInternalServerError, err)
 	}
-	_, _ = fmt.Fprint(&out, `</main></div>`)
+	_, _ = fmt.Fprint(out, `</main></div>`)
 
-	// Determine the highest required runtime tier for this page and all its layouts
-	maxTierLevel := tierToLevel(opts.RuntimeTier)
-	for _, l := range layouts {
-		if lTier := routing.GetLayoutTier(l.Path); lTier != "" {
-			if level := tierToLevel(lTier); level > maxTierLevel {
+	// Determine the highest required runtime tier for this page and all its
+	// layouts, unless the route picks its runtime outright.
+	if opts.Runtime == "" {
+		maxTierLevel := tierToLevel(opts.RuntimeTier)
+		for _, l := range layouts {
+			if lTier := routing.GetLayoutTier(l.Path); lTier != "" {
+				if level := tierToLevel(lTier); level > maxTierLevel {
+					maxTierLevel = level
+				}
+			}
+		}
+		if rootTier := routing.GetLayoutTier(""); rootTier != "" {
+			if level := tierToLevel(rootTier); level > maxTierLevel {
 				maxTierLevel = level
 			}
 		}
+		tier = levelToTier(maxTierLevel)
 	}
-	if rootTier := routing.GetLayoutTier(""); rootTier != "" {
-		if level := tierToLevel(rootTier); level > maxTierLevel {
-			maxTierLevel = level
+	if tier != routing.RuntimeNone {
+		runtimePathForPage := runtimePath
+		if tier != "" && tier != "full" && strings.HasPrefix(runtimePath, "/_gospa/runtime.js") {
+			runtimePathForPage = "/_gospa/runtime-" + tier + ".js"
 		}
+		a.writeRuntimeScripts(c, out, runtimePathForPage, nonceFmt)
 	}
-	tier = levelToTier(maxTierLevel)
-	runtimePathForPage := runtimePath
-	if tier != "" && tier != "full" && strings.HasPrefix(runtimePath, "/_gospa/runtime.js") {
-		runtimePathForPage = "/_gospa/runtime-" + tier + ".js"
+
+	// Centralized State Registry
+	data, _ := json.Marshal(registry.GetData())
+	_, _ = fmt.Fprintf(out, `<script id="__GOSPA_DATA__" type="application/json"%s>%s</script>`, nonceFmt, string(data))
+
+	// Handle Deferred Slots
+	for _, slotName := range opts.DeferredSlots {
+		endSlot := prof.span("slot", slotName)
+		_, _ = out.WriteString(a.renderDeferredSlotToBuffer(route, slotName, routeParams, c.Path(), nonceFmt))
+		endSlot()
 	}
 
-	_, _ = fmt.Fprintf(&out, `<script src="%s" type="module"%s></script>`, runtimePathForPage, nonceFmt)
+	_, _ = fmt.Fprint(out, `</body></html>`)
+	return sendRendered(c, a.transformHTML(route, out.Bytes()))
+}
+
+// writeRuntimeScripts writes the client runtime, its configuration and the
+// islands bundle into a page rendered without a root layout.
+func (a *App) writeRuntimeScripts(c gofiber.Ctx, out *bytes.Buffer, runtimePathForPage, nonceFmt string) {
+	wsURL := a.getWSUrl(c)
+	wsRD, wsMR, wsHB := a.normalizeWSConfig()
 	csrfToken, _ := c.Locals("gospa.csrf_token").(string)
-	_, _ = fmt.Fprintf(&out, `<script type="module"%s>
-	import * as runtime from %s;
-	window.__GOSPA_RUNTIME_ESM__ = runtime;
-	window.__GOSPA_CONFIG__ = {
-		navigationOptions: %s,
-		csrfToken: %s,
-	};
-runtime.init({
+	initConfig := fmt.Sprintf(`{
 	wsUrl: %s,
 	serializationFormat: %s,
 	debug: %v,
@@ -463,6 +591,7 @@ runtime.init({
 	wsReconnectDelay: %d,
 	wsMaxReconnect: %d,
 	wsHeartbeat: %d,
+	wsBatchInterval: %d,
 	hydration: {
 		mode: %s,
 		timeout: %d
@@ -470,11 +599,9 @@ runtime.init({
 	transport: {
 		enabled: true,
 		sseUrl: %s,
-		pollUrl: %s,
-		pollInterval: %d
+		pollUrl: %s
 	}
-});
-	</script>`, nonceFmt, toJS(runtimePathForPage), toJS(a.Config.NavigationOptions), toJS(csrfToken), toJS(wsURL), toJS(string(a.Config.SerializationFormat)), a.Config.DevMode, a.Config.SimpleRuntimeSVGs, a.Config.DisableSanitization, wsRD, wsMR, wsHB, toJS(a.Config.HydrationMode), a.Config.HydrationTimeout, toJS("/_sse/connect"), toJS("/_gospa/poll"), 5000)
+}`, toJS(wsURL), toJS(string(a.Config.SerializationFormat)), a.Config.DevMode, a.Config.SimpleRuntimeSVGs, a.Config.DisableSanitization, wsRD, wsMR, wsHB, a.Config.StateSyncCoalesceInterval.Milliseconds(), toJS(a.Config.HydrationMode), a.Config.HydrationTimeout, toJS("/_sse/connect"), toJS(a.pollURL()))
 
 	// Islands bundle — loads and registers all island setup functions
 	// Only include if the file exists (islands are optional)
@@ -482,21 +609,41 @@ runtime.init({
 	if islandsPath == "" {
 		islandsPath = "static/js/islands.js"
 	}
-	if _, err := os.Stat(islandsPath); err == nil {
-		_, _ = fmt.Fprintf(&out, `<script src="/%s" type="module"%s></script>`, html.EscapeString(islandsPath), nonceFmt)
-	}
-
-	// Centralized State Registry
-	data, _ := json.Marshal(registry.GetData())
-	_, _ = fmt.Fprintf(&out, `<script id="__GOSPA_DATA__" type="application/json"%s>%s</script>`, nonceFmt, string(data))
+	_, err := os.Stat(islandsPath)
+	hasIslands := err == nil
 
-	// Handle Deferred Slots
-	for _, slotName := range opts.DeferredSlots {
-		_, _ = out.WriteString(a.renderDeferredSlotToBuffer(route, slotName, routeParams, c.Path(), nonceFmt))
+	// The inline bootstrap loads the runtime, then the islands, after first
+	// paint instead of fetching them up front.
+	if a.Config.InlineRuntimeBootstrap {
+		if bootstrap, err := embed.BootstrapJS(); err == nil {
+			var modules []string
+			if hasIslands {
+				modules = append(modules, "/"+islandsPath)
+			}
+			_, _ = fmt.Fprintf(out, `<script%s>
+	window.__GOSPA_CONFIG__ = {
+		navigationOptions: %s,
+		csrfToken: %s,
+	};
+	window.__GOSPA_BOOT__ = {runtime: %s, modules: %s, init: %s};
+%s</script>`, nonceFmt, toJS(a.Config.NavigationOptions), toJS(csrfToken), toJS(runtimePathForPage), toJS(modules), initConfig, bootstrap)
+			return
+		}
 	}
 
-	_, _ = fmt.Fprint(&out, `</body></html>`)
-	return c.Send(out.Bytes())
+	_, _ = fmt.Fprintf(out, `<script src="%s" type="module"%s></script>`, runtimePathForPage, nonceFmt)
+	_, _ = fmt.Fprintf(out, `<script type="module"%s>
+	import * as runtime from %s;
+	window.__GOSPA_RUNTIME_ESM__ = runtime;
+	window.__GOSPA_CONFIG__ = {
+		navigationOptions: %s,
+		csrfToken: %s,
+	};
+runtime.init(%s);
+	</script>`, nonceFmt, toJS(runtimePathForPage), toJS(a.Config.NavigationOptions), toJS(csrfToken), initConfig)
+	if hasIslands {
+		_, _ = fmt.Fprintf(out, `<script src="/%s" type="module"%s></script>`, html.EscapeString(islandsPath), nonceFmt)
+	}
 }
 
 func extractRouteParams(c gofiber.Ctx, route *routing.Route) map[string]interface{} {
@@ -547,35 +694,59 @@ func (a *App) resolveLoadChainWithContext(lc routing.LoadContext, route *routing
 	scope := kit.NewExecutionScope()
 	runErr := scope.Run(func() error {
 		var immediateParent map[string]interface{}
-
-		// 1. Root Layout Loader
-		if loader := routing.GetLayoutLoad(""); loader != nil {
-			loadCtx := &helperLoadContext{LoadContext: lc, parentData: nil}
-			scope.SetParentData(nil)
-			data, err := loader(loadCtx)
-			if err != nil {
-				return err
+		// Named layout loader results, shared by every layout in the chain.
+		named := make(map[string]interface{})
+
+		runLayout := func(path string, parentData map[string]interface{}) error {
+			loader := routing.GetLayoutLoad(path)
+			loaders := routing.GetLayoutLoaders(path)
+			if loader == nil && len(loaders) == 0 {
+				return nil
+			}
+			loadCtx := &helperLoadContext{LoadContext: lc, parentData: parentData}
+			scope.SetParentData(parentData)
+			data := make(map[string]interface{})
+			if loader != nil {
+				loaded, err := loader(loadCtx)
+				if err != nil {
+					return err
+				}
+				for k, v := range loaded {
+					data[k] = v
+				}
+			}
+			names := make([]string, 0, len(loaders))
+			for name := range loaders {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				v, ok := named[name]
+				if !ok {
+					var err error
+					if v, err = loaders[name](loadCtx); err != nil {
+						return err
+					}
+					named[name] = v
+				}
+				data[name] = v
 			}
 			for k, v := range data {
 				props[k] = v
 			}
-			immediateParent = cloneMap(data)
+			immediateParent = data
+			return nil
+		}
+
+		// 1. Root Layout Loader
+		if err := runLayout("", nil); err != nil {
+			return err
 		}
 
 		// 2. Nested Layout Loaders
 		for _, layout := range layouts {
-			if loader := routing.GetLayoutLoad(layout.Path); loader != nil {
-				parentData := cloneMap(immediateParent)
-				loadCtx := &helperLoadContext{LoadContext: lc, parentData: parentData}
-				scope.SetParentData(parentData)
-				data, err := loader(loadCtx)
-				if err != nil {
-					return err
-				}
-				for k, v := range data {
-					props[k] = v
-				}
-				immediateParent = cloneMap(data)
+			if err := runLayout(layout.Path, cloneMap(immediateParent)); err != nil {
+				return err
 			}
 		}
 
@@ -625,6 +796,7 @@ type staticLoadContext struct {
 	path   string
 	params map[string]string
 	query  url.Values
+	locals map[string]interface{}
 }
 
 func routeCacheKey(c gofiber.Ctx) string {
@@ -735,7 +907,7 @@ func (s *staticLoadContext) Method() string { return "GET" }
 
 func (s *staticLoadContext) Path() string { return s.path }
 
-func (s *staticLoadContext) Local(string) interface{} { return nil }
+func (s *staticLoadContext) Local(key string) interface{} { return s.locals[key] }
 
 func (a *App) resolveTier(opts routing.RouteOptions, layouts []*routing.Route) string {
 	tier, _ := a.resolveTierWithReason(opts, layouts)
@@ -743,6 +915,10 @@ func (a *App) resolveTier(opts routing.RouteOptions, layouts []*routing.Route) s
 }
 
 func (a *App) resolveTierWithReason(opts routing.RouteOptions, layouts []*routing.Route) (string, string) {
+	if opts.Runtime != "" {
+		tier := strings.ToLower(opts.Runtime)
+		return tier, "route:" + tier
+	}
 	maxLevel := tierToLevel(string(a.Config.RuntimeTier))
 	reasonParts := []string{fmt.Sprintf("config:%s", levelToTier(maxLevel))}
 	if pLevel := tierToLevel(opts.RuntimeTier); pLevel > maxLevel {
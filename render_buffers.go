@@ -0,0 +1,39 @@
+package gospa
+
+import (
+	"bytes"
+	"sync"
+
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// maxPooledRenderBuffer bounds the capacity of buffers returned to the pool,
+// so one unusually large page doesn't pin its buffer forever.
+const maxPooledRenderBuffer = 4 << 20
+
+// renderBufferPool recycles the buffers pages are rendered into.
+var renderBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func acquireRenderBuffer() *bytes.Buffer {
+	return renderBufferPool.Get().(*bytes.Buffer)
+}
+
+func releaseRenderBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledRenderBuffer {
+		return
+	}
+	buf.Reset()
+	renderBufferPool.Put(buf)
+}
+
+// sendRendered copies html into the response body. c.Send keeps a reference
+// to its argument until the response is written, which would outlive a
+// pooled buffer.
+func sendRendered(c gofiber.Ctx, html []byte) error {
+	c.Response().SetBody(html)
+	return nil
+}
@@ -0,0 +1,167 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+// registerBenchSSGPage registers an SSG page rendering body and returns its
+// path and route.
+func registerBenchSSGPage(name, body string) (string, *routing.Route) {
+	routePath := fmt.Sprintf("/%s-%d", name, time.Now().UnixNano())
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, body)
+			return err
+		})
+	}, routing.RouteOptions{Strategy: routing.StrategySSG})
+	return routePath, &routing.Route{Path: routePath}
+}
+
+// usePassthroughRootLayout installs a root layout that renders its children
+// unchanged; only pages with a root layout take the SSG caching path.
+func usePassthroughRootLayout(tb testing.TB) {
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(func(children templ.Component, _ map[string]interface{}) templ.Component {
+		return children
+	}, "")
+	tb.Cleanup(func() { routing.RegisterRootLayout(prev, "") })
+}
+
+func TestSSGCacheDoesNotAliasRenderBuffer(t *testing.T) {
+	app := New(Config{CacheTemplates: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.Config.Storage = nil // force in-memory path
+
+	usePassthroughRootLayout(t)
+	routePath, route := registerBenchSSGPage("ssg-alias", "<p>cached</p>")
+	f := gofiber.New()
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI(routePath)
+	c := f.AcquireCtx(reqCtx)
+	if err := app.renderRoute(c, route, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scribble over whatever the pool hands out next.
+	for i := 0; i < 8; i++ {
+		buf := acquireRenderBuffer()
+		buf.WriteString(strings.Repeat("x", 256))
+		releaseRenderBuffer(buf)
+	}
+
+	app.ssgCacheMu.RLock()
+	entry, ok := app.ssgCache[routeCacheKey(c)]
+	app.ssgCacheMu.RUnlock()
+	if !ok || !strings.Contains(string(entry.html), "<p>cached</p>") {
+		t.Fatalf("cached SSG entry corrupted: %q", entry.html)
+	}
+	if body := string(reqCtx.Response.Body()); !strings.Contains(body, "<p>cached</p>") {
+		t.Fatalf("response body corrupted: %q", body)
+	}
+}
+
+// BenchmarkRenderRouteSSGMiss measures the render-and-store path taken on an
+// SSG cache miss.
+func BenchmarkRenderRouteSSGMiss(b *testing.B) {
+	app := New(Config{CacheTemplates: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.Config.Storage = nil // force in-memory path
+
+	usePassthroughRootLayout(b)
+	routePath, route := registerBenchSSGPage("bench-ssg", strings.Repeat("<p>bench</p>", 512))
+	f := gofiber.New()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(routePath)
+		c := f.AcquireCtx(reqCtx)
+		app.invalidateCacheKey(routeCacheKey(c))
+		if err := app.renderRoute(c, route, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSSGHitReferencesCachedHTML(t *testing.T) {
+	app := New(Config{CacheTemplates: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.Config.Storage = nil // force in-memory path
+
+	usePassthroughRootLayout(t)
+	routePath, route := registerBenchSSGPage("ssg-hit", "<p>hit</p>")
+	f := gofiber.New()
+	for i := 0; i < 2; i++ {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(routePath)
+		c := f.AcquireCtx(reqCtx)
+		if err := app.renderRoute(c, route, nil); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			continue
+		}
+
+		app.ssgCacheMu.RLock()
+		entry := app.ssgCache[routeCacheKey(c)]
+		app.ssgCacheMu.RUnlock()
+		body := reqCtx.Response.Body()
+		if entry.hasNonce || len(body) == 0 || &body[0] != &entry.html[0] {
+			t.Fatal("expected the cache hit to reference the cached HTML")
+		}
+		if ct := string(reqCtx.Response.Header.ContentType()); !strings.HasPrefix(ct, "text/html") {
+			t.Fatalf("Content-Type = %q", ct)
+		}
+	}
+}
+
+func TestSendCachedPageRewritesNonce(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	entry := newSsgEntry([]byte(`<script nonce="`+noncePlaceholder+`"></script>`), time.Now())
+	if !entry.hasNonce {
+		t.Fatal("expected entry to be flagged as containing a nonce placeholder")
+	}
+	reqCtx := &fasthttp.RequestCtx{}
+	if err := app.sendCachedPage(gofiber.New().AcquireCtx(reqCtx), entry, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if body := string(reqCtx.Response.Body()); body != `<script nonce="abc"></script>` {
+		t.Fatalf("body = %q", body)
+	}
+	if !strings.Contains(string(entry.html), noncePlaceholder) {
+		t.Fatal("cached HTML must not be modified")
+	}
+}
+
+// BenchmarkRenderRouteSSGHit measures serving a page from the SSG cache.
+func BenchmarkRenderRouteSSGHit(b *testing.B) {
+	app := New(Config{CacheTemplates: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.Config.Storage = nil // force in-memory path
+
+	routePath, route := registerBenchSSGPage("bench-ssg-hit", strings.Repeat("<p>bench</p>", 512))
+	f := gofiber.New()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(routePath)
+		if err := app.renderRoute(f.AcquireCtx(reqCtx), route, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package gospa
+
+import (
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+const (
+	headerSurrogateControl = "Surrogate-Control"
+	headerSurrogateKey     = "Surrogate-Key"
+)
+
+func hasRouteCacheHeaders(opts routing.RouteOptions) bool {
+	return opts.CacheControl != "" || opts.SurrogateControl != "" || len(opts.SurrogateKeys) > 0
+}
+
+// applyRouteCacheHeaders applies a route's CacheControl, SurrogateControl and
+// SurrogateKeys over the headers its strategy set. Error responses keep the
+// strategy's headers so a CDN never caches them.
+func (a *App) applyRouteCacheHeaders(c gofiber.Ctx, opts routing.RouteOptions, routePath, strategy string) {
+	if c.Response().StatusCode() >= gofiber.StatusBadRequest {
+		return
+	}
+	if opts.CacheControl != "" {
+		c.Set(gofiber.HeaderCacheControl, opts.CacheControl)
+	}
+	if opts.SurrogateControl == "" && len(opts.SurrogateKeys) == 0 {
+		return
+	}
+	if opts.SurrogateControl != "" {
+		c.Set(headerSurrogateControl, opts.SurrogateControl)
+	}
+
+	// Cache hits skip the load chain, so only rendered responses carry the
+	// dependency tags in X-GoSPA-Cache-Tags.
+	var tags []string
+	if rendered := c.GetRespHeader("X-GoSPA-Cache-Tags"); rendered != "" {
+		tags = strings.Split(rendered, ",")
+	} else {
+		tags = a.defaultCacheTags(routePath, strategy)
+	}
+	keys := make([]string, 0, len(tags)+len(opts.SurrogateKeys))
+	seen := make(map[string]struct{}, cap(keys))
+	for _, k := range append(tags, opts.SurrogateKeys...) {
+		k = strings.TrimSpace(k)
+		if _, dup := seen[k]; k == "" || dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	c.Set(headerSurrogateKey, strings.Join(keys, " "))
+}
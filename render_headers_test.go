@@ -0,0 +1,79 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestRenderRoute_RouteCacheHeaders(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-cache-headers-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	fail := false
+
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>ok</p>")
+			return err
+		})
+	}, routing.RouteOptions{
+		CacheControl:     "public, s-maxage=60, stale-while-revalidate=300",
+		SurrogateControl: "max-age=3600",
+		SurrogateKeys:    []string{"posts", "route:" + routePath},
+	})
+	routing.RegisterLoad(routePath, func(_ routing.LoadContext) (map[string]interface{}, error) {
+		if fail {
+			return nil, ErrNotFound
+		}
+		return map[string]interface{}{}, nil
+	})
+
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	for _, target := range []string{routePath, routePath + "?__data=1"} {
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		if got := resp.Header.Get("Cache-Control"); got != "public, s-maxage=60, stale-while-revalidate=300" {
+			t.Fatalf("%s: Cache-Control = %q", target, got)
+		}
+		if got := resp.Header.Get("Surrogate-Control"); got != "max-age=3600" {
+			t.Fatalf("%s: Surrogate-Control = %q", target, got)
+		}
+		want := "route:" + routePath + " strategy:ssr posts"
+		if got := resp.Header.Get("Surrogate-Key"); got != want {
+			t.Fatalf("%s: Surrogate-Key = %q, want %q", target, got, want)
+		}
+	}
+
+	fail = true
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, routePath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Cache-Control"); got == "public, s-maxage=60, stale-while-revalidate=300" {
+		t.Fatal("error response must not carry the route's Cache-Control")
+	}
+	if got := resp.Header.Get("Surrogate-Key"); got != "" {
+		t.Fatalf("error response Surrogate-Key = %q", got)
+	}
+}
@@ -0,0 +1,31 @@
+package gospa
+
+import "github.com/aydenstechdungeon/gospa/routing"
+
+// OnRenderHTML registers fn to rewrite the HTML of every page the app
+// renders, e.g. to inject an analytics snippet, point asset URLs at a CDN
+// or strip comments. Handlers run in registration order before the page is
+// cached or sent, under every strategy: SSG and ISR pages when they are
+// rendered, so cache hits serve the rewritten HTML without running them
+// again, and SSR and PPR pages on every request, PPR after its dynamic
+// slots are filled. fn may modify html in place and return it, but must not
+// keep it after returning.
+func (a *App) OnRenderHTML(fn func(route *routing.Route, html []byte) []byte) {
+	if fn == nil {
+		return
+	}
+	a.renderHooksMu.Lock()
+	defer a.renderHooksMu.Unlock()
+	a.renderHooks = append(a.renderHooks, fn)
+}
+
+// transformHTML runs the OnRenderHTML handlers over the page html of route.
+func (a *App) transformHTML(route *routing.Route, html []byte) []byte {
+	a.renderHooksMu.RLock()
+	hooks := a.renderHooks
+	a.renderHooksMu.RUnlock()
+	for _, fn := range hooks {
+		html = fn(route, html)
+	}
+	return html
+}
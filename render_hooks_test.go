@@ -0,0 +1,69 @@
+package gospa
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestOnRenderHTML(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), CacheTemplates: true})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	suffix := time.Now().UnixNano()
+	ssrPath := fmt.Sprintf("/test-render-hook-ssr-%d", suffix)
+	isrPath := fmt.Sprintf("/test-render-hook-isr-%d", suffix)
+	page := func(_ map[string]interface{}) templ.Component {
+		return templ.Raw(`<!-- note --><img src="/static/a.png">`)
+	}
+	routing.RegisterPage(ssrPath, page)
+	routing.RegisterPageWithOptions(isrPath, page, routing.RouteOptions{Strategy: routing.StrategyISR, RevalidateAfter: time.Hour})
+
+	var routes []string
+	app.OnRenderHTML(func(route *routing.Route, html []byte) []byte {
+		routes = append(routes, route.Path)
+		return bytes.ReplaceAll(html, []byte(`src="/static/`), []byte(`src="https://cdn.example.com/static/`))
+	})
+	app.OnRenderHTML(func(_ *routing.Route, html []byte) []byte {
+		return bytes.ReplaceAll(html, []byte("<!-- note -->"), nil)
+	})
+	app.OnRenderHTML(nil)
+
+	ssrRoute := &routing.Route{Path: ssrPath}
+	app.Get(ssrPath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, ssrRoute, map[string]interface{}{})
+	})
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, ssrPath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	want := `<img src="https://cdn.example.com/static/a.png">`
+	if !strings.Contains(string(body), want) || strings.Contains(string(body), "<!-- note -->") {
+		t.Fatalf("SSR body = %s", body)
+	}
+
+	isrRoute := &routing.Route{Path: isrPath}
+	app.initSemaphore()
+	app.regeneratePage(isrPath, isrRoute, nil)
+	app.ssgCacheMu.RLock()
+	entry := app.ssgCache[isrPath]
+	app.ssgCacheMu.RUnlock()
+	if string(entry.html) != want {
+		t.Fatalf("cached ISR page = %q", entry.html)
+	}
+	if len(routes) != 2 || routes[0] != ssrPath || routes[1] != isrPath {
+		t.Fatalf("hook routes = %v", routes)
+	}
+}
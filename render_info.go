@@ -0,0 +1,79 @@
+package gospa
+
+import (
+	"context"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// RenderHeader is the DevMode response header describing how a page was
+// served, e.g. "strategy=isr; cache=stale; generated=2026-01-02T15:04:05Z".
+const RenderHeader = "X-GoSPA-Render"
+
+// CacheStatus says whether a page was served from the render cache.
+type CacheStatus string
+
+const (
+	// CacheHit is a page served from the SSG/ISR cache, or a PPR page whose
+	// shell came from the cache.
+	CacheHit CacheStatus = "hit"
+	// CacheStale is an ISR page served from the cache past RevalidateAfter
+	// while it is regenerated in the background.
+	CacheStale CacheStatus = "stale"
+	// CacheMiss is a cacheable page rendered because it was not cached.
+	CacheMiss CacheStatus = "miss"
+	// CacheBypass is a page whose strategy is not cached, i.e. SSR.
+	CacheBypass CacheStatus = "bypass"
+)
+
+// RenderDetails describes how the page being served was produced.
+type RenderDetails struct {
+	// Strategy is the effective render strategy of the route.
+	Strategy routing.RenderStrategy
+	// Cache is the render cache outcome.
+	Cache CacheStatus
+	// GeneratedAt is when the HTML was rendered. For a PPR page, it is when
+	// its dynamic slots were rendered.
+	GeneratedAt time.Time
+	// Locale is the locale the page is rendered in when its route varies
+	// by routing.VariantLocale.
+	Locale string
+	// Device is DeviceMobile or DeviceDesktop when the route varies by
+	// routing.VariantDevice.
+	Device string
+}
+
+type renderInfoKey struct{}
+
+// RenderInfo returns how the page being rendered with ctx is produced, for
+// templ components to read from their ctx; outside a page render it is zero.
+// A cached page keeps what its components rendered on the miss that filled
+// the cache, so use the RenderHeader to see how a hit was served.
+func RenderInfo(ctx context.Context) RenderDetails {
+	info, _ := ctx.Value(renderInfoKey{}).(RenderDetails)
+	return info
+}
+
+func withRenderInfo(ctx context.Context, info RenderDetails) context.Context {
+	return context.WithValue(ctx, renderInfoKey{}, info)
+}
+
+// setRenderHeader reports info in the RenderHeader in DevMode.
+func (a *App) setRenderHeader(c gofiber.Ctx, info RenderDetails) {
+	if !a.Config.DevMode {
+		return
+	}
+	value := "strategy=" + string(info.Strategy) + "; cache=" + string(info.Cache)
+	if !info.GeneratedAt.IsZero() {
+		value += "; generated=" + info.GeneratedAt.UTC().Format(time.RFC3339)
+	}
+	if info.Locale != "" {
+		value += "; locale=" + info.Locale
+	}
+	if info.Device != "" {
+		value += "; device=" + info.Device
+	}
+	c.Set(RenderHeader, value)
+}
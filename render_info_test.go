@@ -0,0 +1,83 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestRenderInfo(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), DevMode: true, CacheTemplates: true})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-render-info-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			info := RenderInfo(ctx)
+			_, err := fmt.Fprintf(w, "<p>%s %s</p>", info.Strategy, info.Cache)
+			return err
+		})
+	}, routing.RouteOptions{Strategy: routing.StrategySSG})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	get := func() (string, string) {
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, routePath, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp.Header.Get(RenderHeader), string(body)
+	}
+	header, body := get()
+	if !strings.HasPrefix(header, "strategy=ssg; cache=miss; generated=") || !strings.Contains(body, "<p>ssg miss</p>") {
+		t.Fatalf("miss: %s = %q, body %s", RenderHeader, header, body)
+	}
+	generated := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	app.Config.Now = func() time.Time { return generated }
+	app.storeSsgEntry(routePath, []byte("<p>cached</p>"), nil, nil)
+	header, body = get()
+	if header != "strategy=ssg; cache=hit; generated=2026-01-02T15:04:05Z" || body != "<p>cached</p>" {
+		t.Fatalf("hit: %s = %q, body %s", RenderHeader, header, body)
+	}
+
+	if info := RenderInfo(context.Background()); info != (RenderDetails{}) {
+		t.Fatalf("RenderInfo outside a render = %+v", info)
+	}
+}
+
+func TestRenderHeaderOnlyInDevMode(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir()})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-render-info-prod-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.Raw("<p>ok</p>")
+	})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, routePath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if got := resp.Header.Get(RenderHeader); got != "" {
+		t.Fatalf("%s = %q outside DevMode", RenderHeader, got)
+	}
+}
@@ -0,0 +1,170 @@
+package gospa
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// RouteCacheEntry describes one cached render of a route.
+type RouteCacheEntry struct {
+	// Key is the cache key (request path plus normalized query).
+	Key string `json:"key"`
+	// Kind is "ssg" for full pages (SSG/ISR) or "ppr" for static shells.
+	Kind string `json:"kind"`
+	// CreatedAt is when the entry was rendered; zero if unknown (PPR shells in Storage).
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	// AgeSeconds is the entry age at snapshot time.
+	AgeSeconds float64 `json:"ageSeconds"`
+	// Stale reports whether the entry is past its ISR revalidation window or SSGCacheTTL.
+	Stale bool `json:"stale"`
+}
+
+// RouteInfo describes a page route as seen by the renderer.
+type RouteInfo struct {
+	Path            string            `json:"path"`
+	File            string            `json:"file"`
+	Params          []string          `json:"params,omitempty"`
+	Strategy        string            `json:"strategy"`
+	RevalidateAfter string            `json:"revalidateAfter,omitempty"`
+	RevalidateCron  string            `json:"revalidateCron,omitempty"`
+	DynamicSlots    []string          `json:"dynamicSlots,omitempty"`
+	Cache           []RouteCacheEntry `json:"cache"`
+	Stats           routeCacheStats   `json:"stats"`
+}
+
+// RouteTable returns the live page route table with each route's effective
+// render strategy and the state of its SSG/ISR/PPR cache entries.
+func (a *App) RouteTable() []RouteInfo {
+	now := a.now()
+	pages := a.Router.GetPages()
+	out := make([]RouteInfo, 0, len(pages))
+	for _, route := range pages {
+		opts := routing.GetRouteOptions(route.Path)
+		strategy := opts.Strategy
+		if strategy == "" {
+			strategy = a.Config.DefaultRenderStrategy
+		}
+		if strategy == "" {
+			strategy = routing.StrategySSR
+		}
+		if a.Config.RequestMode {
+			strategy = requestModeStrategy(strategy)
+		}
+		ttl := opts.RevalidateAfter
+		if ttl == 0 {
+			ttl = a.Config.DefaultRevalidateAfter
+		}
+
+		info := RouteInfo{
+			Path:         route.Path,
+			File:         route.File,
+			Params:       route.Params,
+			Strategy:     string(strategy),
+			DynamicSlots: opts.DynamicSlots,
+			Cache:        []RouteCacheEntry{},
+		}
+		if strategy == routing.StrategyISR && ttl > 0 {
+			info.RevalidateAfter = ttl.String()
+		}
+		if strategy == routing.StrategyISR {
+			info.RevalidateCron = opts.RevalidateCron
+		}
+
+		keys := a.collectCacheKeysByTag("route:" + route.Path)
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, entry := range a.lookupCacheEntries(key) {
+				if !entry.CreatedAt.IsZero() {
+					age := now.Sub(entry.CreatedAt)
+					entry.AgeSeconds = age.Seconds()
+					entry.Stale = (a.Config.SSGCacheTTL > 0 && age >= a.Config.SSGCacheTTL) ||
+						(strategy == routing.StrategyISR && ttl > 0 && age >= ttl)
+				}
+				info.Cache = append(info.Cache, entry)
+			}
+		}
+
+		a.cacheStatsMu.RLock()
+		for _, key := range keys {
+			if stats := a.routeCacheStats[key]; stats != nil {
+				info.Stats.Hits += stats.Hits
+				info.Stats.Misses += stats.Misses
+				info.Stats.StaleServed += stats.StaleServed
+				info.Stats.Revalidations += stats.Revalidations
+				info.Stats.Invalidations += stats.Invalidations
+			}
+		}
+		a.cacheStatsMu.RUnlock()
+
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// lookupCacheEntries returns the SSG and PPR entries stored under key.
+func (a *App) lookupCacheEntries(key string) []RouteCacheEntry {
+	var entries []RouteCacheEntry
+	if a.Config.Storage != nil {
+		if data, err := a.Config.Storage.Get(a.Context(), "gospa:ssg:"+key); err == nil {
+			if entry, ok := decodeSsgEntry(data); ok {
+				entries = append(entries, RouteCacheEntry{Key: key, Kind: "ssg", CreatedAt: entry.createdAt})
+			}
+		}
+		if _, err := a.Config.Storage.Get(a.Context(), "gospa:ppr:"+key); err == nil {
+			entries = append(entries, RouteCacheEntry{Key: key, Kind: "ppr"})
+		}
+		return entries
+	}
+
+	a.ssgCacheMu.RLock()
+	if entry, ok := a.ssgCache[key]; ok {
+		entries = append(entries, RouteCacheEntry{Key: key, Kind: "ssg", CreatedAt: entry.createdAt})
+	}
+	a.ssgCacheMu.RUnlock()
+
+	a.pprShellMu.RLock()
+	if entry, ok := a.pprShellCache[key]; ok {
+		entries = append(entries, RouteCacheEntry{Key: key, Kind: "ppr", CreatedAt: entry.createdAt})
+	}
+	a.pprShellMu.RUnlock()
+	return entries
+}
+
+func (a *App) handleRouteTable(c gofiber.Ctx) error {
+	if !a.Config.DevMode {
+		return c.SendStatus(gofiber.StatusNotFound)
+	}
+	return c.JSON(gofiber.Map{
+		"generatedAt": time.Now().UTC().Format(time.RFC3339),
+		"routes":      a.RouteTable(),
+	})
+}
+
+func (a *App) handleRouteInvalidate(c gofiber.Ctx) error {
+	if !a.Config.DevMode {
+		return c.SendStatus(gofiber.StatusNotFound)
+	}
+	var payload struct {
+		Key   string `json:"key"`
+		Route string `json:"route"`
+	}
+	if err := c.Bind().Body(&payload); err != nil || (payload.Key == "" && payload.Route == "") {
+		return a.sendError(c, NewError(CodeInvalidInvalidation, gofiber.StatusBadRequest, "Invalid invalidation payload"))
+	}
+
+	invalidated := 0
+	if payload.Key != "" {
+		invalidated += a.Invalidate(payload.Key)
+	}
+	if payload.Route != "" {
+		invalidated += a.InvalidateTag("route:" + payload.Route)
+	}
+	return c.JSON(gofiber.Map{
+		"ok":          true,
+		"invalidated": invalidated,
+	})
+}
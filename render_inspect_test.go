@@ -0,0 +1,94 @@
+package gospa
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func TestRouteTableReportsStrategyAndCache(t *testing.T) {
+	routing.RegisterPageWithOptions("/inspect-isr", func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, _ io.Writer) error { return nil })
+	}, routing.RouteOptions{Strategy: routing.StrategyISR, RevalidateAfter: time.Minute})
+
+	app := New(Config{
+		DevMode: true,
+		RoutesFS: fstest.MapFS{
+			"inspect-isr/page.templ": &fstest.MapFile{},
+			"inspect-ssr/page.templ": &fstest.MapFile{},
+		},
+	})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Router.Scan(); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+
+	app.storeSsgEntry("/inspect-isr", []byte("page"), app.defaultCacheTags("/inspect-isr", "isr"), app.defaultCacheKeys("/inspect-isr"))
+	app.ssgCacheMu.Lock()
+	entry := app.ssgCache["/inspect-isr"]
+	entry.createdAt = time.Now().Add(-2 * time.Minute)
+	app.ssgCache["/inspect-isr"] = entry
+	app.ssgCacheMu.Unlock()
+
+	routes := app.RouteTable()
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+	isr, ssr := routes[0], routes[1]
+	if isr.Path != "/inspect-isr" || isr.Strategy != string(routing.StrategyISR) || isr.RevalidateAfter != "1m0s" {
+		t.Fatalf("unexpected isr route: %+v", isr)
+	}
+	if len(isr.Cache) != 1 || isr.Cache[0].Kind != "ssg" || !isr.Cache[0].Stale {
+		t.Fatalf("expected one stale ssg entry, got %+v", isr.Cache)
+	}
+	if ssr.Strategy != string(routing.StrategySSR) || len(ssr.Cache) != 0 {
+		t.Fatalf("unexpected ssr route: %+v", ssr)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/dev/routes/invalidate", strings.NewReader(`{"route":"/inspect-isr"}`))
+	req.Header.Set("Content-Type", "application/json")
+	addValidCSRF(req)
+	resp, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var payload struct {
+		Invalidated int `json:"invalidated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if payload.Invalidated != 1 {
+		t.Fatalf("expected 1 invalidation, got %d", payload.Invalidated)
+	}
+	if routes := app.RouteTable(); len(routes[0].Cache) != 0 {
+		t.Fatalf("expected cache to be empty after invalidation, got %+v", routes[0].Cache)
+	}
+}
+
+func TestRouteTableEndpointRequiresDevMode(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/_gospa/dev/routes", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		t.Fatalf("expected route table to be unavailable outside DevMode")
+	}
+}
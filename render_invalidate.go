@@ -1,6 +1,37 @@
 package gospa
 
-import "strings"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/cdn"
+	json "github.com/goccy/go-json"
+)
+
+// cacheInvalidationChannel carries route cache invalidations between processes
+// sharing Config.PubSub. Each process keeps its own tag/key indexes (and, with
+// in-memory Storage, its own pages), so invalidations must run everywhere.
+const cacheInvalidationChannel = "gospa:cache:invalidate"
+
+const (
+	invalidateOpPath = "path"
+	invalidateOpTag  = "tag"
+	invalidateOpKey  = "key"
+	invalidateOpAll  = "all"
+)
+
+// cacheInvalidation is the event published by Invalidate, InvalidateTag,
+// InvalidateKey, and InvalidateAll.
+type cacheInvalidation struct {
+	// Origin identifies the publishing process so it can skip its own events.
+	Origin string `json:"origin"`
+	Op     string `json:"op"`
+	Value  string `json:"value,omitempty"`
+}
 
 func (a *App) defaultCacheTags(routePath, strategy string) []string {
 	normalized := strings.TrimSpace(routePath)
@@ -131,11 +162,17 @@ func (a *App) collectCacheKeysByKey(indexKey string) []string {
 }
 
 // Invalidate removes cache entries associated with the provided route path.
+// Other processes sharing Config.PubSub drop theirs asynchronously; the count
+// covers this process only, as for InvalidateTag, InvalidateKey, and
+// InvalidateAll.
 func (a *App) Invalidate(path string) int {
 	if path == "" {
 		return 0
 	}
-	return a.invalidateCacheKey(path)
+	invalidated := a.invalidatePath(path)
+	a.publishInvalidation(invalidateOpPath, path)
+	a.purgeCDN(cdn.Purge{Paths: []string{path}})
+	return invalidated
 }
 
 // InvalidateTag removes all cache entries indexed under the provided tag.
@@ -143,6 +180,14 @@ func (a *App) InvalidateTag(tag string) int {
 	if tag == "" {
 		return 0
 	}
+	paths := a.collectCacheKeysByTag(tag)
+	invalidated := a.invalidateTag(tag)
+	a.publishInvalidation(invalidateOpTag, tag)
+	a.purgeCDN(cdn.Purge{Paths: paths, Tags: []string{tag}})
+	return invalidated
+}
+
+func (a *App) invalidateTag(tag string) int {
 	keys := a.collectCacheKeysByTag(tag)
 	count := 0
 	for _, key := range keys {
@@ -158,6 +203,19 @@ func (a *App) InvalidateKey(key string) int {
 	if key == "" {
 		return 0
 	}
+	paths := a.collectCacheKeysByKey(key)
+	// Keys named after a path reach the CDN even when this process has not
+	// cached the page.
+	if path := strings.TrimPrefix(key, "path:"); strings.HasPrefix(path, "/") && !slices.Contains(paths, path) {
+		paths = append(paths, path)
+	}
+	invalidated := a.invalidateKey(key)
+	a.publishInvalidation(invalidateOpKey, key)
+	a.purgeCDN(cdn.Purge{Paths: paths})
+	return invalidated
+}
+
+func (a *App) invalidateKey(key string) int {
 	keys := a.collectCacheKeysByKey(key)
 	count := 0
 	for _, cacheKey := range keys {
@@ -171,6 +229,13 @@ func (a *App) InvalidateKey(key string) int {
 // InvalidateAll removes all in-memory route caches and clears index mappings.
 // Returns number of entries removed across SSG and PPR caches.
 func (a *App) InvalidateAll() int {
+	invalidated := a.invalidateAll()
+	a.publishInvalidation(invalidateOpAll, "")
+	a.purgeCDN(cdn.Purge{All: true})
+	return invalidated
+}
+
+func (a *App) invalidateAll() int {
 	invalidated := 0
 
 	a.ssgCacheMu.Lock()
@@ -192,6 +257,18 @@ func (a *App) InvalidateAll() int {
 	a.cacheKeyIndex = make(map[string]map[string]struct{})
 	a.cacheIndexMu.Unlock()
 
+	a.seoRobots.reset()
+	a.seoSitemap.reset()
+
+	return invalidated
+}
+
+// invalidatePath removes the cache entries of path and of its variants.
+func (a *App) invalidatePath(path string) int {
+	invalidated := 0
+	for _, key := range a.variantCacheKeys(path) {
+		invalidated += a.invalidateCacheKey(key)
+	}
 	return invalidated
 }
 
@@ -243,3 +320,77 @@ func (a *App) invalidateCacheKey(cacheKey string) int {
 	}
 	return invalidated
 }
+
+// subscribeCacheInvalidation applies invalidations published by other
+// processes. Without it each prefork child or node would keep serving pages
+// that were invalidated elsewhere.
+func (a *App) subscribeCacheInvalidation() {
+	if a.Config.PubSub == nil {
+		return
+	}
+	origin := make([]byte, 8)
+	_, _ = rand.Read(origin)
+	a.cacheOrigin = hex.EncodeToString(origin)
+	unsub, err := a.Config.PubSub.Subscribe(a.Context(), cacheInvalidationChannel, a.applyCacheInvalidation)
+	if err != nil {
+		a.Logger().Warn("route cache invalidations will not reach other processes", "err", err)
+		return
+	}
+	a.cacheInvalidationUnsub = unsub
+}
+
+// publishInvalidation tells other processes to run the same invalidation.
+// Failures are logged; the local invalidation has already happened.
+func (a *App) publishInvalidation(op, value string) {
+	if a.Config.PubSub == nil || a.cacheInvalidationUnsub == nil {
+		return
+	}
+	data, err := json.Marshal(cacheInvalidation{Origin: a.cacheOrigin, Op: op, Value: value})
+	if err != nil {
+		return
+	}
+	if err := a.Config.PubSub.Publish(a.Context(), cacheInvalidationChannel, data); err != nil {
+		a.Logger().Warn("failed to publish route cache invalidation", "op", op, "value", value, "err", err)
+	}
+}
+
+// purgeCDN clears the pages from Config.CDNPurger's edge cache. Only the
+// process that invalidated purges; failures are logged.
+func (a *App) purgeCDN(p cdn.Purge) {
+	if a.Config.CDNPurger == nil || (!p.All && len(p.Paths) == 0 && len(p.Tags) == 0) {
+		return
+	}
+	p.Origin = a.Config.PublicOrigin
+	timeout := a.Config.CDNPurgeTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(a.Context(), timeout)
+	defer cancel()
+	if err := a.Config.CDNPurger.Purge(ctx, p); err != nil {
+		a.Logger().Warn("CDN purge failed", "paths", p.Paths, "tags", p.Tags, "all", p.All, "err", err)
+	}
+}
+
+func (a *App) applyCacheInvalidation(message []byte) {
+	var event cacheInvalidation
+	if err := json.Unmarshal(message, &event); err != nil || event.Origin == a.cacheOrigin {
+		return
+	}
+	switch event.Op {
+	case invalidateOpPath:
+		if event.Value != "" {
+			a.invalidatePath(event.Value)
+		}
+	case invalidateOpTag:
+		if event.Value != "" {
+			a.invalidateTag(event.Value)
+		}
+	case invalidateOpKey:
+		if event.Value != "" {
+			a.invalidateKey(event.Value)
+		}
+	case invalidateOpAll:
+		a.invalidateAll()
+	}
+}
@@ -1,6 +1,14 @@
 package gospa
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/cdn"
+	"github.com/aydenstechdungeon/gospa/store"
+)
 
 func TestInvalidateTagAndKey(t *testing.T) {
 	app := New(Config{SSGCacheMaxEntries: 10, Prefork: false})
@@ -59,3 +67,73 @@ func TestInvalidateDependencyTagAndKey(t *testing.T) {
 		t.Fatalf("expected 1 invalidation by dep key, got %d", n)
 	}
 }
+
+func TestInvalidationReachesOtherProcesses(t *testing.T) {
+	pubsub := store.NewMemoryPubSub()
+	apps := make([]*App, 2)
+	for i := range apps {
+		apps[i] = New(Config{SSGCacheMaxEntries: 10, PubSub: pubsub})
+		apps[i].Config.Storage = nil
+		defer func(a *App) { _ = a.Fiber.Shutdown() }(apps[i])
+		apps[i].storeSsgEntry("/docs/a", []byte("a"), []string{"docs"}, []string{"path:/docs/a"})
+		apps[i].storeSsgEntry("/docs/b", []byte("b"), nil, nil)
+	}
+
+	cached := func(a *App, key string) bool {
+		a.ssgCacheMu.RLock()
+		defer a.ssgCacheMu.RUnlock()
+		_, ok := a.ssgCache[key]
+		return ok
+	}
+	waitGone := func(key string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for cached(apps[1], key) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s still cached in the other process", key)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if n := apps[0].InvalidateTag("docs"); n != 1 {
+		t.Fatalf("expected 1 local invalidation, got %d", n)
+	}
+	waitGone("/docs/a")
+	if !cached(apps[1], "/docs/b") {
+		t.Fatal("untagged entry was invalidated")
+	}
+
+	apps[0].Invalidate("/docs/b")
+	waitGone("/docs/b")
+}
+
+func TestInvalidatePurgesCDN(t *testing.T) {
+	var purges []cdn.Purge
+	app := New(Config{
+		SSGCacheMaxEntries: 10,
+		PublicOrigin:       "https://example.com",
+		CDNPurger: cdn.PurgerFunc(func(_ context.Context, p cdn.Purge) error {
+			purges = append(purges, p)
+			return nil
+		}),
+	})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	app.storeSsgEntry("/docs/a", []byte("a"), []string{"docs"}, []string{"path:/docs/a"})
+	app.InvalidateTag("docs")
+	app.Invalidate("/pricing")
+	app.InvalidateKey("path:/about")
+	app.InvalidateAll()
+
+	want := []cdn.Purge{
+		{Origin: "https://example.com", Paths: []string{"/docs/a"}, Tags: []string{"docs"}},
+		{Origin: "https://example.com", Paths: []string{"/pricing"}},
+		{Origin: "https://example.com", Paths: []string{"/about"}},
+		{Origin: "https://example.com", All: true},
+	}
+	if !reflect.DeepEqual(purges, want) {
+		t.Fatalf("purges = %+v\nwant %+v", purges, want)
+	}
+}
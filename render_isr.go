@@ -2,9 +2,13 @@ package gospa
 
 import (
 	"context"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/aydenstechdungeon/gospa/jobs"
 	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/store"
 )
 
 // initSemaphore initializes the ISR semaphore if not already done.
@@ -19,45 +23,170 @@ func (a *App) initSemaphore() {
 }
 
 func (a *App) backgroundRevalidate(cacheKey string, routeSnap interface{}) {
+	defer a.isrRevalidating.Delete(cacheKey)
 	route, _ := routeSnap.(*routing.Route)
-	routeParams := map[string]interface{}{}
-	routePath := routePathFromCacheKey(cacheKey)
-	if matchedRoute, params := a.Router.Match(routePath); matchedRoute != nil {
-		route = matchedRoute
-		for k, v := range params {
-			routeParams[k] = v
-		}
-	}
+	route, routeParams := a.revalidationTarget(cacheKey, route)
 	if route == nil {
 		a.Logger().Error("ISR: invalid route snapshot type", "path", cacheKey)
 		return
 	}
-	defer a.isrRevalidating.Delete(cacheKey)
 	select {
 	case a.isrSemaphore <- struct{}{}:
 		defer func() { <-a.isrSemaphore }()
 	default:
 		return
 	}
+	a.regeneratePage(cacheKey, route, routeParams)
+}
+
+// revalidationTarget resolves the route and params that rendered cacheKey,
+// falling back to route when the path no longer matches.
+func (a *App) revalidationTarget(cacheKey string, route *routing.Route) (*routing.Route, map[string]interface{}) {
+	routeParams := map[string]interface{}{}
+	if matchedRoute, params := a.Router.Match(routePathFromCacheKey(cacheKey)); matchedRoute != nil {
+		route = matchedRoute
+		for k, v := range params {
+			routeParams[k] = v
+		}
+	}
+	return route, routeParams
+}
+
+// regeneratePage re-renders cacheKey and replaces its cache entry.
+func (a *App) regeneratePage(cacheKey string, route *routing.Route, routeParams map[string]interface{}) {
 	timeout := a.Config.ISRTimeout
 	if timeout <= 0 {
 		timeout = 60 * time.Second
 	}
 	bgCtx, cancel := context.WithTimeout(a.Context(), timeout)
 	defer cancel()
-	freshHTML, err := a.buildPageHTML(bgCtx, route, routeParams, cacheKey)
+	pageKey, variant := splitVariantKey(cacheKey)
+	bgCtx = withRenderInfo(bgCtx, RenderDetails{Locale: variant.Locale, Device: variant.Device})
+	freshHTML, err := a.buildPageHTML(bgCtx, route, routeParams, pageKey)
 	if err != nil {
 		a.Logger().Error("ISR background render error", "path", cacheKey, "err", err)
 		return
 	}
 	strategy := string(routing.GetRouteOptions(route.Path).Strategy)
 	tags := a.defaultCacheTags(route.Path, strategy)
-	keys := a.defaultCacheKeys(cacheKey)
+	keys := a.defaultCacheKeys(pageKey)
 	layouts := a.Router.ResolveLayoutChain(route)
-	loadContext := newStaticLoadContext(cacheKey, routeParams)
+	loadContext := newStaticLoadContext(pageKey, routeParams)
+	loadContext.locals = variant.locals()
 	if _, depKeys, depErr := a.resolveLoadChainWithContext(loadContext, route, layouts); depErr == nil {
 		tags = append(tags, dependencyTags(depKeys)...)
 		keys = append(keys, dependencyKeys(depKeys)...)
 	}
 	a.storeSsgEntry(cacheKey, freshHTML, tags, keys)
 }
+
+const (
+	// isrScheduleLockPrefix namespaces the Storage locks that let one
+	// instance run each scheduled regeneration.
+	isrScheduleLockPrefix = "gospa:isr:cron:"
+	// isrScheduleLockTTL outlives clock skew between instances, so a late
+	// instance still finds the lock for a tick another one ran.
+	isrScheduleLockTTL = 10 * time.Minute
+)
+
+// scheduledISRRoute is an ISR page with a RevalidateCron schedule.
+type scheduledISRRoute struct {
+	route *routing.Route
+	cron  jobs.Cron
+}
+
+// startISRSchedules re-renders ISR pages that set RevalidateCron on their
+// schedule until the app shuts down.
+func (a *App) startISRSchedules() {
+	if !a.Config.CacheTemplates || a.Config.RequestMode {
+		return
+	}
+	var scheduled []scheduledISRRoute
+	for _, route := range a.Router.GetPages() {
+		opts := routing.GetRouteOptions(route.Path)
+		strategy := opts.Strategy
+		if strategy == "" {
+			strategy = a.Config.DefaultRenderStrategy
+		}
+		if opts.RevalidateCron == "" || strategy != routing.StrategyISR {
+			continue
+		}
+		cron, err := jobs.ParseCron(opts.RevalidateCron)
+		if err != nil {
+			// Reported by startup validation.
+			continue
+		}
+		scheduled = append(scheduled, scheduledISRRoute{route: route, cron: cron})
+	}
+	if len(scheduled) == 0 {
+		return
+	}
+	a.initSemaphore()
+	go a.runISRSchedules(scheduled)
+}
+
+// runISRSchedules checks the schedules at the start of every minute.
+func (a *App) runISRSchedules(scheduled []scheduledISRRoute) {
+	ctx := a.Context()
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, s := range scheduled {
+			if s.cron.Matches(next) && a.claimISRSchedule(ctx, s.route.Path, next) {
+				go a.regenerateScheduled(ctx, s.route)
+			}
+		}
+	}
+}
+
+// regenerateScheduled re-renders every cached page of route, plus the page
+// itself for static routes without variants that have not been rendered yet. Pages of dynamic
+// routes are found in this instance's cache index, so with shared Storage
+// only the pages this instance has rendered are refreshed.
+func (a *App) regenerateScheduled(ctx context.Context, route *routing.Route) {
+	keys := a.collectCacheKeysByTag("route:" + route.Path)
+	if !route.IsDynamic && len(routing.GetRouteOptions(route.Path).Variants) == 0 && !slices.Contains(keys, route.Path) {
+		keys = append(keys, route.Path)
+	}
+	for _, cacheKey := range keys {
+		select {
+		case a.isrSemaphore <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		target, routeParams := a.revalidationTarget(cacheKey, route)
+		a.recordCacheRevalidation(cacheKey)
+		a.regeneratePage(cacheKey, target, routeParams)
+		<-a.isrSemaphore
+	}
+}
+
+// claimISRSchedule reports whether this instance runs the schedule of
+// routePath for the tick at. With a store.LockStorage backend exactly one
+// instance wins; other shared backends are claimed on a best-effort basis.
+func (a *App) claimISRSchedule(ctx context.Context, routePath string, at time.Time) bool {
+	if a.Config.Storage == nil {
+		return true
+	}
+	key := isrScheduleLockPrefix + hashParts(routePath, strconv.FormatInt(at.Unix(), 10))
+	if locker, ok := a.Config.Storage.(store.LockStorage); ok {
+		won, err := locker.SetNX(ctx, key, []byte("1"), isrScheduleLockTTL)
+		if err != nil {
+			a.Logger().Warn("ISR: schedule lock failed", "path", routePath, "err", err)
+			return false
+		}
+		return won
+	}
+	if _, err := a.Config.Storage.Get(ctx, key); err == nil {
+		return false
+	}
+	_ = a.Config.Storage.Set(ctx, key, []byte("1"), isrScheduleLockTTL)
+	return true
+}
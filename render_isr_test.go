@@ -0,0 +1,61 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func TestClaimISRScheduleOncePerTick(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	ctx := context.Background()
+	tick := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	if !app.claimISRSchedule(ctx, "/news", tick) {
+		t.Fatal("first claim failed")
+	}
+	if app.claimISRSchedule(ctx, "/news", tick) {
+		t.Fatal("second claim for the same tick succeeded")
+	}
+	if !app.claimISRSchedule(ctx, "/news", tick.Add(time.Hour)) || !app.claimISRSchedule(ctx, "/other", tick) {
+		t.Fatal("claims for another tick or route should succeed")
+	}
+}
+
+func TestRegenerateScheduledRendersStaticRoute(t *testing.T) {
+	var renders atomic.Int32
+	routePath := "/isr-cron-" + strings.ReplaceAll(time.Now().Format("150405.000000000"), ".", "")
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "<p>render %d</p>", renders.Add(1))
+			return err
+		})
+	}, routing.RouteOptions{Strategy: routing.StrategyISR, RevalidateAfter: time.Hour, RevalidateCron: "@hourly"})
+
+	app := New(Config{CacheTemplates: true})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+	app.initSemaphore()
+
+	route := &routing.Route{Path: routePath}
+	for want := 1; want <= 2; want++ {
+		app.regenerateScheduled(context.Background(), route)
+		app.ssgCacheMu.RLock()
+		entry, ok := app.ssgCache[routePath]
+		app.ssgCacheMu.RUnlock()
+		if !ok || !strings.Contains(string(entry.html), fmt.Sprintf("<p>render %d</p>", want)) {
+			t.Fatalf("run %d: cached page = %q", want, entry.html)
+		}
+	}
+	if len(app.isrSemaphore) != 0 {
+		t.Fatal("scheduled regeneration leaked a semaphore slot")
+	}
+}
@@ -59,3 +59,47 @@ func TestRenderRoute_DataEndpointHandlesKitFail(t *testing.T) {
 		t.Fatalf("unexpected payload: %+v", payload)
 	}
 }
+
+func TestRenderRoute_DataEndpointHandlesError(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-load-error-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, _ io.Writer) error {
+			return nil
+		})
+	})
+	routing.RegisterLoad(routePath, func(_ routing.LoadContext) (map[string]interface{}, error) {
+		return nil, ErrNotFound.WithMessage("Post not found")
+	})
+
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, routePath+"?__data=1", nil)
+	resp, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Kind  string `json:"kind"`
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if payload.Kind != "error" || payload.Code != CodeNotFound || payload.Error != "Post not found" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
@@ -116,3 +116,51 @@ func TestRenderRoute_LoadKitErrorDataAndSSR(t *testing.T) {
 		t.Fatalf("expected 418 on SSR request, got %d", respSSR.StatusCode)
 	}
 }
+
+func TestResolveLoadChain_NamedLayoutLoaders(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := "/named-layout-loaders/page"
+	layoutPath := "/named-layout-loaders"
+
+	userLoads := 0
+	loadUser := func(_ routing.LoadContext) (interface{}, error) {
+		userLoads++
+		return "ada", nil
+	}
+	routing.RegisterLayoutLoader("", "user", loadUser)
+	routing.RegisterLayoutLoader(layoutPath, "user", loadUser)
+	routing.RegisterLayoutLoader(layoutPath, "nav", func(_ routing.LoadContext) (interface{}, error) {
+		return []string{"home", "settings"}, nil
+	})
+	routing.RegisterLoad(routePath, func(c routing.LoadContext) (map[string]interface{}, error) {
+		parent, err := kit.Parent[map[string]interface{}](c)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"pageUser": parent["user"]}, nil
+	})
+	defer routing.RegisterLayoutLoader("", "user", nil)
+	defer routing.RegisterLayoutLoader(layoutPath, "user", nil)
+	defer routing.RegisterLayoutLoader(layoutPath, "nav", nil)
+	defer routing.RegisterLoad(routePath, nil)
+
+	props, _, err := app.resolveLoadChainWithContext(
+		newStaticLoadContext(routePath, nil),
+		&routing.Route{Path: routePath},
+		[]*routing.Route{{Path: layoutPath}},
+	)
+	if err != nil {
+		t.Fatalf("resolveLoadChainWithContext failed: %v", err)
+	}
+	if userLoads != 1 {
+		t.Fatalf("shared loader ran %d times, want 1", userLoads)
+	}
+	if props["user"] != "ada" || props["pageUser"] != "ada" {
+		t.Fatalf("unexpected user props: %v", props)
+	}
+	if nav, _ := props["nav"].([]string); len(nav) != 2 {
+		t.Fatalf("unexpected nav prop: %v", props["nav"])
+	}
+}
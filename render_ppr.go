@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/aydenstechdungeon/gospa/routing"
 	templpkg "github.com/aydenstechdungeon/gospa/templ"
@@ -47,7 +46,7 @@ func (a *App) storePprShell(key string, shell []byte, tags, keys []string) {
 	}
 	a.pprShellKeys = append(a.pprShellKeys, key)
 	a.pprShellIndex[key] = struct{}{}
-	a.pprShellCache[key] = pprEntry{html: shell, createdAt: time.Now()}
+	a.pprShellCache[key] = pprEntry{html: shell, createdAt: a.now()}
 	a.indexCacheEntry(key, tags, keys)
 }
 
@@ -57,6 +56,7 @@ func (a *App) applyPPRSlots(ctx context.Context, route *routing.Route, shell []b
 		params = map[string]string{}
 	}
 
+	prof := requestProfileFromContext(ctx)
 	result := shell
 	for _, slotName := range opts.DynamicSlots {
 		slotFn := routing.GetSlot(route.Path, slotName)
@@ -68,7 +68,7 @@ func (a *App) applyPPRSlots(ctx context.Context, route *routing.Route, shell []b
 			slotProps[k] = v
 		}
 		var slotBuf bytes.Buffer
-		if err := slotFn(slotProps).Render(ctx, &slotBuf); err != nil {
+		if err := profiledComponent(prof, "slot", slotName, slotFn(slotProps)).Render(ctx, &slotBuf); err != nil {
 			a.Logger().Error("PPR slot render error", "slot", slotName, "err", err)
 			a.recordSlotRender(path, slotName, true)
 			continue
@@ -0,0 +1,235 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/db"
+	"github.com/aydenstechdungeon/gospa/fiber"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// maxRequestProfiles bounds the number of profiles kept for /_gospa/dev/profile.
+const maxRequestProfiles = 100
+
+// profilePhases lists the phases reported in the Server-Timing header, in order.
+var profilePhases = []string{"match", "loaders", "sql", "layout", "page", "slot", "write"}
+
+type requestProfileKey struct{}
+
+// ProfileSpan is one timed phase of a request. Start and Duration are in
+// milliseconds relative to the start of the request, so spans can be drawn
+// as a flame chart: a span nests under any span whose range contains it.
+type ProfileSpan struct {
+	Phase    string  `json:"phase"`
+	Detail   string  `json:"detail,omitempty"`
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+}
+
+// RequestProfile is the per-phase timing of a single page request in DevMode.
+type RequestProfile struct {
+	ID        uint64             `json:"id"`
+	RequestID string             `json:"requestId,omitempty"`
+	Method    string             `json:"method"`
+	Path      string             `json:"path"`
+	Route     string             `json:"route"`
+	Strategy  string             `json:"strategy"`
+	Status    int                `json:"status"`
+	Time      time.Time          `json:"time"`
+	Total     float64            `json:"total"`
+	Phases    map[string]float64 `json:"phases"`
+	Spans     []ProfileSpan      `json:"spans"`
+
+	mu    sync.Mutex
+	start time.Time
+}
+
+var requestProfileSeq atomic.Uint64
+
+func msSince(from, to time.Time) float64 {
+	return float64(to.Sub(from).Microseconds()) / 1000
+}
+
+// span starts timing a phase and returns the function that ends it.
+// It is safe to call on a nil profile.
+func (p *RequestProfile) span(phase, detail string) func() {
+	if p == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		p.record(phase, detail, begin, time.Now())
+	}
+}
+
+func (p *RequestProfile) record(phase, detail string, begin, end time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Spans = append(p.Spans, ProfileSpan{
+		Phase:    phase,
+		Detail:   detail,
+		Start:    msSince(p.start, begin),
+		Duration: msSince(begin, end),
+	})
+}
+
+// finish records the write phase and computes per-phase totals. Layout time
+// is reported as self time: page renders nested inside it are subtracted.
+func (p *RequestProfile) finish(end time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Total = msSince(p.start, end)
+
+	lastEnd := 0.0
+	for _, s := range p.Spans {
+		if e := s.Start + s.Duration; e > lastEnd {
+			lastEnd = e
+		}
+	}
+	p.Spans = append(p.Spans, ProfileSpan{Phase: "write", Start: lastEnd, Duration: p.Total - lastEnd})
+
+	p.Phases = make(map[string]float64, len(profilePhases))
+	for _, s := range p.Spans {
+		p.Phases[s.Phase] += s.Duration
+	}
+	if layout, ok := p.Phases["layout"]; ok {
+		p.Phases["layout"] = max(layout-p.Phases["page"], 0)
+	}
+}
+
+// serverTiming formats the phase totals as a Server-Timing header value.
+func (p *RequestProfile) serverTiming() string {
+	parts := make([]string, 0, len(profilePhases)+1)
+	for _, phase := range profilePhases {
+		if dur, ok := p.Phases[phase]; ok {
+			parts = append(parts, fmt.Sprintf("%s;dur=%.2f", phase, dur))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("total;dur=%.2f", p.Total))
+	return strings.Join(parts, ", ")
+}
+
+func requestProfileFromCtx(c gofiber.Ctx) *RequestProfile {
+	p, _ := c.Locals("gospa.profile").(*RequestProfile)
+	return p
+}
+
+func withRequestProfile(ctx context.Context, p *RequestProfile) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestProfileKey{}, p)
+}
+
+func requestProfileFromContext(ctx context.Context) *RequestProfile {
+	p, _ := ctx.Value(requestProfileKey{}).(*RequestProfile)
+	return p
+}
+
+// profiledComponent times the render of comp as the given phase.
+func profiledComponent(p *RequestProfile, phase, detail string, comp templ.Component) templ.Component {
+	if p == nil {
+		return comp
+	}
+	profiled := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		defer p.span(phase, detail)()
+		return comp.Render(ctx, w)
+	})
+	// Keep the page's layout slots visible to wrapWithLayouts.
+	return templpkg.WithLayoutSlots(profiled, templpkg.LayoutSlotsOf(comp))
+}
+
+// profilerMiddleware records a RequestProfile for every page request and
+// reports it via the Server-Timing header. Only mounted in DevMode.
+func (a *App) profilerMiddleware() gofiber.Handler {
+	return func(c gofiber.Ctx) error {
+		if strings.HasPrefix(c.Path(), "/_gospa/") {
+			return c.Next()
+		}
+		now := time.Now()
+		p := &RequestProfile{
+			ID:        requestProfileSeq.Add(1),
+			RequestID: fiber.GetRequestID(c),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Time:      now,
+			start:     now,
+		}
+		c.Locals("gospa.profile", p)
+
+		err := c.Next()
+
+		if p.Route == "" {
+			return err
+		}
+		p.finish(time.Now())
+		p.Status = c.Response().StatusCode()
+		c.Append("Server-Timing", p.serverTiming())
+		a.storeRequestProfile(p)
+		return err
+	}
+}
+
+// sqlQueryLogger reports the statements of a request's db transaction as
+// "sql" spans of its profile. Only profiled (DevMode) requests log queries.
+func (a *App) sqlQueryLogger(c gofiber.Ctx) db.QueryFunc {
+	p := requestProfileFromCtx(c)
+	if p == nil {
+		return nil
+	}
+	return func(q db.Query) {
+		detail := q.SQL
+		if len(detail) > 120 {
+			detail = detail[:117] + "..."
+		}
+		if q.Err != nil {
+			detail += " (error)"
+		}
+		p.record("sql", detail, q.Start, q.Start.Add(q.Duration))
+	}
+}
+
+func (a *App) storeRequestProfile(p *RequestProfile) {
+	a.profilesMu.Lock()
+	defer a.profilesMu.Unlock()
+	a.profiles = append(a.profiles, p)
+	if len(a.profiles) > maxRequestProfiles {
+		a.profiles = a.profiles[len(a.profiles)-maxRequestProfiles:]
+	}
+}
+
+// RequestProfiles returns the most recent page request profiles, newest first.
+// Profiles are only recorded in DevMode.
+func (a *App) RequestProfiles() []*RequestProfile {
+	a.profilesMu.Lock()
+	defer a.profilesMu.Unlock()
+	out := make([]*RequestProfile, 0, len(a.profiles))
+	for i := len(a.profiles) - 1; i >= 0; i-- {
+		out = append(out, a.profiles[i])
+	}
+	return out
+}
+
+func (a *App) handleRequestProfiles(c gofiber.Ctx) error {
+	if !a.Config.DevMode {
+		return c.SendStatus(gofiber.StatusNotFound)
+	}
+	if c.Method() == gofiber.MethodDelete {
+		a.profilesMu.Lock()
+		a.profiles = nil
+		a.profilesMu.Unlock()
+		return c.SendStatus(gofiber.StatusNoContent)
+	}
+	return c.JSON(gofiber.Map{
+		"generatedAt": time.Now().UTC().Format(time.RFC3339),
+		"profiles":    a.RequestProfiles(),
+	})
+}
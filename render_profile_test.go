@@ -0,0 +1,82 @@
+package gospa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestRequestProfileFinishComputesLayoutSelfTime(t *testing.T) {
+	start := time.Now()
+	p := &RequestProfile{start: start}
+	p.record("match", "", start, start.Add(time.Millisecond))
+	p.record("layout", "/", start.Add(time.Millisecond), start.Add(6*time.Millisecond))
+	p.record("page", "/", start.Add(2*time.Millisecond), start.Add(5*time.Millisecond))
+	p.finish(start.Add(8 * time.Millisecond))
+
+	if p.Phases["layout"] != 2 || p.Phases["page"] != 3 || p.Phases["write"] != 2 {
+		t.Fatalf("unexpected phases: %+v", p.Phases)
+	}
+	want := "match;dur=1.00, layout;dur=2.00, page;dur=3.00, write;dur=2.00, total;dur=8.00"
+	if got := p.serverTiming(); got != want {
+		t.Fatalf("serverTiming() = %q, want %q", got, want)
+	}
+}
+
+func TestProfilerRecordsPageRequest(t *testing.T) {
+	app := New(Config{DevMode: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-profile-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>profiled</p>")
+			return err
+		})
+	})
+	routing.RegisterLoad(routePath, func(_ routing.LoadContext) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+	app.setupRoutes()
+
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, routePath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	timing := resp.Header.Get("Server-Timing")
+	for _, phase := range []string{"match;", "loaders;", "layout;", "page;", "write;", "total;"} {
+		if !strings.Contains(timing, phase) {
+			t.Fatalf("expected %q in Server-Timing %q", phase, timing)
+		}
+	}
+
+	resp, err = app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/_gospa/dev/profile", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var payload struct {
+		Profiles []RequestProfile `json:"profiles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(payload.Profiles) != 1 || payload.Profiles[0].Route != routePath || len(payload.Profiles[0].Spans) == 0 {
+		t.Fatalf("unexpected profiles: %+v", payload.Profiles)
+	}
+}
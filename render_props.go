@@ -0,0 +1,124 @@
+package gospa
+
+import (
+	"maps"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// maxPooledRootProps bounds the size of props maps returned to the pool, so
+// one page with a huge load result doesn't pin a large map forever.
+const maxPooledRootProps = 64
+
+// rootPropsPool recycles the per-request root layout props maps.
+var rootPropsPool = sync.Pool{
+	New: func() any {
+		return make(map[string]interface{}, 16)
+	},
+}
+
+// rootPropsTemplate is the part of the root layout props that depends only
+// on Config. It is built once per App.
+type rootPropsTemplate struct {
+	static map[string]interface{}
+	// runtimePaths caches getRuntimePathForTier for the known tiers.
+	runtimePaths map[string]string
+	// publicWSURL is the WebSocket URL derived from PublicOrigin, if set.
+	publicWSURL string
+}
+
+func (a *App) rootLayoutPropsTemplate() *rootPropsTemplate {
+	a.rootPropsOnce.Do(func() {
+		wsRD, wsMR, wsHB := a.normalizeWSConfig()
+		t := &rootPropsTemplate{
+			static: map[string]interface{}{
+				"appName":             a.Config.AppName,
+				"debug":               a.Config.DevMode,
+				"hydrationMode":       a.Config.HydrationMode,
+				"hydrationTimeout":    a.Config.HydrationTimeout,
+				"wsReconnectDelay":    wsRD,
+				"wsMaxReconnect":      wsMR,
+				"wsHeartbeat":         wsHB,
+				"wsBatchInterval":     a.Config.StateSyncCoalesceInterval.Milliseconds(),
+				"serializationFormat": a.Config.SerializationFormat,
+				"navigationOptions":   a.Config.NavigationOptions,
+				"disableSanitization": a.Config.DisableSanitization,
+				"transport":           map[string]interface{}{"pollUrl": a.pollURL()},
+			},
+			runtimePaths: make(map[string]string, 4),
+		}
+		if manifest := a.webAppManifest(); manifest != nil {
+			t.static["manifest"] = *manifest
+		}
+		for _, tier := range []string{"", string(RuntimeTierMicro), string(RuntimeTierCore), string(RuntimeTierFull)} {
+			t.runtimePaths[tier] = a.getRuntimePathForTier(tier)
+		}
+		if realtimeURL := strings.TrimSpace(a.Config.RealtimeURL); realtimeURL != "" {
+			t.publicWSURL = realtimeURL
+		} else if publicOrigin := strings.TrimSpace(a.Config.PublicOrigin); publicOrigin != "" {
+			if parsed, err := url.Parse(publicOrigin); err == nil && parsed.Host != "" {
+				scheme := "ws"
+				if strings.EqualFold(parsed.Scheme, "https") {
+					scheme = "wss"
+				}
+				t.publicWSURL = scheme + "://" + parsed.Host + a.Config.WebSocketPath
+			}
+		}
+		a.rootProps = t
+	})
+	return a.rootProps
+}
+
+func (t *rootPropsTemplate) runtimePath(a *App, tier string) string {
+	if p, ok := t.runtimePaths[tier]; ok {
+		return p
+	}
+	return a.getRuntimePathForTier(tier)
+}
+
+// buildRootLayoutProps returns the props passed to the root layout: the
+// cached Config-derived template, the per-request keys, and params on top.
+// The map is pooled; callers hand it back with releaseRootLayoutProps once
+// the root layout has finished rendering, and must not retain it afterwards.
+func (a *App) buildRootLayoutProps(c gofiber.Ctx, params map[string]interface{}, tier string) map[string]interface{} {
+	t := a.rootLayoutPropsTemplate()
+	props := rootPropsPool.Get().(map[string]interface{})
+	maps.Copy(props, t.static)
+	props["runtimePath"] = t.runtimePath(a, tier)
+	props["path"] = c.Path()
+	props["wsUrl"] = a.getWSUrl(c)
+	maps.Copy(props, params)
+	return props
+}
+
+// addPageMetaProps sets the "title" and "description" root layout props from
+// the route's routing.Meta, unless the page's load data already set them, and
+// "robots" from its NoIndex and NoFollow options.
+func addPageMetaProps(props map[string]interface{}, routePath string) {
+	if robots := routing.GetRouteOptions(routePath).Robots(); robots != "" {
+		props["robots"] = robots
+	}
+	meta, ok := routing.GetPageMeta(routePath)
+	if !ok {
+		return
+	}
+	if _, set := props["title"]; !set && meta.Title != "" {
+		props["title"] = meta.Title
+	}
+	if _, set := props["description"]; !set && meta.Description != "" {
+		props["description"] = meta.Description
+	}
+}
+
+// releaseRootLayoutProps returns a map from buildRootLayoutProps to the pool.
+func releaseRootLayoutProps(props map[string]interface{}) {
+	if props == nil || len(props) > maxPooledRootProps {
+		return
+	}
+	clear(props)
+	rootPropsPool.Put(props)
+}
@@ -0,0 +1,173 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+func TestBuildRootLayoutProps(t *testing.T) {
+	app := New(Config{
+		AppName:       "Props",
+		PublicOrigin:  "https://example.com",
+		WebSocketPath: "/ws",
+	})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	f := gofiber.New()
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI("/docs")
+	c := f.AcquireCtx(reqCtx)
+
+	props := app.buildRootLayoutProps(c, map[string]interface{}{"id": "7", "appName": "Override"}, "")
+	if props["path"] != "/docs" || props["wsUrl"] != "wss://example.com/ws" || props["id"] != "7" {
+		t.Fatalf("unexpected props: %v", props)
+	}
+	if props["appName"] != "Override" {
+		t.Fatalf("params should override template keys, got appName=%v", props["appName"])
+	}
+	if props["runtimePath"] != app.getRuntimePathForTier("") {
+		t.Fatalf("runtimePath = %v", props["runtimePath"])
+	}
+	releaseRootLayoutProps(props)
+	if len(props) != 0 {
+		t.Fatalf("released props should be cleared, got %v", props)
+	}
+
+	// The template is untouched by per-request keys and overrides.
+	again := app.buildRootLayoutProps(c, nil, "")
+	defer releaseRootLayoutProps(again)
+	if again["appName"] != "Props" || again["id"] != nil {
+		t.Fatalf("template leaked request data: %v", again)
+	}
+}
+
+func BenchmarkBuildRootLayoutProps(b *testing.B) {
+	app := New(Config{PublicOrigin: "https://example.com"})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	f := gofiber.New()
+	c := f.AcquireCtx(&fasthttp.RequestCtx{})
+	params := map[string]interface{}{"id": "42"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		releaseRootLayoutProps(app.buildRootLayoutProps(c, params, ""))
+	}
+}
+
+// BenchmarkRenderRouteSSR measures allocations on the SSR hot path with a
+// root layout registered.
+func BenchmarkRenderRouteSSR(b *testing.B) {
+	app := New(Config{PublicOrigin: "https://example.com"})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/bench-ssr-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>bench</p>")
+			return err
+		})
+	})
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(func(children templ.Component, _ map[string]interface{}) templ.Component {
+		return children
+	}, "")
+	defer routing.RegisterRootLayout(prev, "")
+
+	f := gofiber.New()
+	params := map[string]interface{}{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(routePath)
+		c := f.AcquireCtx(reqCtx)
+		if err := app.renderRoute(c, route, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRouteRuntime(t *testing.T) {
+	app := New(Config{PublicOrigin: "https://example.com"})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(nil, "")
+	defer routing.RegisterRootLayout(prev, "")
+
+	f := gofiber.New()
+	render := func(opts routing.RouteOptions) (body, tier string) {
+		t.Helper()
+		routePath := fmt.Sprintf("/runtime-%d", time.Now().UnixNano())
+		routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+			return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+				_, err := io.WriteString(w, "<p>page</p>")
+				return err
+			})
+		}, opts)
+		reqCtx := &fasthttp.RequestCtx{}
+		reqCtx.Request.SetRequestURI(routePath)
+		c := f.AcquireCtx(reqCtx)
+		defer f.ReleaseCtx(c)
+		if err := app.renderRoute(c, &routing.Route{Path: routePath}, nil); err != nil {
+			t.Fatal(err)
+		}
+		return string(c.Response().Body()), string(c.Response().Header.Peek("X-GoSPA-Runtime-Tier"))
+	}
+
+	body, tier := render(routing.RouteOptions{Runtime: routing.RuntimeNone})
+	if tier != "none" || strings.Contains(body, "runtime") || !strings.Contains(body, "<p>page</p>") {
+		t.Fatalf("Runtime=none: tier %q, body %s", tier, body)
+	}
+	body, tier = render(routing.RouteOptions{Runtime: "micro", RuntimeTier: "full"})
+	if tier != "micro" || !strings.Contains(body, `<script src="/_gospa/runtime-micro.js"`) {
+		t.Fatalf("Runtime=micro: tier %q, body %s", tier, body)
+	}
+	if got := app.buildRootLayoutProps(f.AcquireCtx(&fasthttp.RequestCtx{}), nil, routing.RuntimeNone)["runtimePath"]; got != "" {
+		t.Fatalf("runtimePath for none = %q, want empty", got)
+	}
+}
+
+func TestInlineRuntimeBootstrap(t *testing.T) {
+	app := New(Config{PublicOrigin: "https://example.com", InlineRuntimeBootstrap: true})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(nil, "")
+	defer routing.RegisterRootLayout(prev, "")
+
+	routePath := fmt.Sprintf("/bootstrap-%d", time.Now().UnixNano())
+	routing.RegisterPage(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>page</p>")
+			return err
+		})
+	})
+	f := gofiber.New()
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.SetRequestURI(routePath)
+	c := f.AcquireCtx(reqCtx)
+	defer f.ReleaseCtx(c)
+	if err := app.renderRoute(c, &routing.Route{Path: routePath}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	body := string(c.Response().Body())
+	if strings.Contains(body, `<script src="/_gospa/runtime`) || strings.Contains(body, "import * as runtime") {
+		t.Fatalf("runtime is loaded up front: %s", body)
+	}
+	if !strings.Contains(body, `window.__GOSPA_BOOT__ = {runtime: "/_gospa/runtime.js"`) || !strings.Contains(body, "requestIdleCallback") {
+		t.Fatalf("bootstrap missing: %s", body)
+	}
+}
@@ -1,12 +1,23 @@
 package gospa
 
 import (
+	"strconv"
 	"time"
+
+	gofiber "github.com/gofiber/fiber/v3"
 )
 
+// now returns Config.Now(), or time.Now.
+func (a *App) now() time.Time {
+	if a.Config.Now != nil {
+		return a.Config.Now()
+	}
+	return time.Now()
+}
+
 func (a *App) storeSsgEntry(key string, html []byte, tags, keys []string) {
 	if a.Config.Storage != nil {
-		entry := ssgEntry{html: html, createdAt: time.Now()}
+		entry := newSsgEntry(html, a.now())
 		_ = a.Config.Storage.Set(a.Context(), "gospa:ssg:"+key, encodeSsgEntry(entry), 0)
 		a.indexCacheEntry(key, tags, keys)
 		return
@@ -50,6 +61,25 @@ func (a *App) storeSsgEntry(key string, html []byte, tags, keys []string) {
 
 	a.ssgCacheKeys = append(a.ssgCacheKeys, key)
 	a.ssgCacheIndex[key] = struct{}{}
-	a.ssgCache[key] = ssgEntry{html: html, createdAt: time.Now()}
+	a.ssgCache[key] = newSsgEntry(html, a.now())
 	a.indexCacheEntry(key, tags, keys)
 }
+
+// sendCachedPage writes a cached SSG/ISR page. Cached HTML is never modified
+// once stored, so pages without a nonce placeholder are handed to fasthttp by
+// reference rather than copied per request. The capacity is clipped so nothing
+// downstream can append into the cached slice.
+func (a *App) sendCachedPage(c gofiber.Ctx, entry ssgEntry, nonce string) error {
+	c.Response().Header.SetContentType("text/html")
+	if !entry.hasNonce {
+		c.Response().SetBodyRaw(entry.html[:len(entry.html):len(entry.html)])
+		return nil
+	}
+	return c.Send(a.replaceNonces(entry.html, nonce))
+}
+
+// isrCacheControl returns the Cache-Control value for an ISR hit.
+func isrCacheControl(ttlSec int) string {
+	ttl := strconv.Itoa(ttlSec)
+	return "public, s-maxage=" + ttl + ", stale-while-revalidate=" + ttl
+}
@@ -117,11 +117,3 @@ func (a *App) handleCacheStats(c gofiber.Ctx) error {
 	}
 	return c.JSON(a.cacheStatsSnapshot())
 }
-
-func (a *App) handleTransportPoll(c gofiber.Ctx) error {
-	return c.JSON(gofiber.Map{
-		"messages":  []any{},
-		"transport": "polling",
-		"ts":        time.Now().UnixMilli(),
-	})
-}
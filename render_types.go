@@ -1,6 +1,7 @@
 package gospa
 
 import (
+	"bytes"
 	"encoding/binary"
 	"math"
 	"time"
@@ -10,6 +11,17 @@ import (
 type ssgEntry struct {
 	html      []byte
 	createdAt time.Time
+	// hasNonce reports whether html contains the CSP nonce placeholder and so
+	// must be rewritten for each response.
+	hasNonce bool
+}
+
+func newSsgEntry(html []byte, createdAt time.Time) ssgEntry {
+	return ssgEntry{
+		html:      html,
+		createdAt: createdAt,
+		hasNonce:  bytes.Contains(html, []byte(noncePlaceholder)),
+	}
 }
 
 // pprEntry holds a cached static shell for PPR pages.
@@ -35,8 +47,5 @@ func decodeSsgEntry(data []byte) (ssgEntry, bool) {
 	if createdAtNano > uint64(math.MaxInt64) {
 		return ssgEntry{}, false
 	}
-	return ssgEntry{
-		html:      data[8:],
-		createdAt: time.Unix(0, int64(createdAtNano)),
-	}, true
+	return newSsgEntry(data[8:], time.Unix(0, int64(createdAtNano))), true
 }
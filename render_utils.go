@@ -3,6 +3,7 @@ package gospa
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,7 +13,9 @@ import (
 
 	"encoding/json"
 	"github.com/a-h/templ"
+	gospafiber "github.com/aydenstechdungeon/gospa/fiber"
 	"github.com/aydenstechdungeon/gospa/routing"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
 	gofiber "github.com/gofiber/fiber/v3"
 )
 
@@ -59,6 +62,11 @@ func (a *App) validatePublicHost(host string) (string, bool) {
 func (a *App) renderError(c gofiber.Ctx, statusCode int, errToDisplay error) error {
 	path := c.Path()
 	message := "Internal Server Error"
+	errorCode := ""
+	var appErr *Error
+	if errors.As(errToDisplay, &appErr) {
+		message, errorCode = appErr.UserMessage, appErr.Code
+	}
 	if a.Config.DevMode && errToDisplay != nil {
 		message = errToDisplay.Error()
 	}
@@ -77,6 +85,12 @@ func (a *App) renderError(c gofiber.Ctx, statusCode int, errToDisplay error) err
 		"code":  statusCode,
 		"path":  path,
 	}
+	if errorCode != "" {
+		props["errorCode"] = errorCode
+	}
+	if requestID := gospafiber.GetRequestID(c); requestID != "" {
+		props["requestId"] = requestID
+	}
 
 	content := errCompFn(props)
 	params := make(map[string]interface{})
@@ -89,19 +103,22 @@ func (a *App) renderError(c gofiber.Ctx, statusCode int, errToDisplay error) err
 	if rootLayoutFunc != nil {
 		tier := a.resolveTier(routing.RouteOptions{}, layouts)
 		rootProps := a.buildRootLayoutProps(c, params, tier)
-		wrappedContent = rootLayoutFunc(content, rootProps)
+		defer releaseRootLayoutProps(rootProps)
+		wrappedContent = templpkg.WithLayoutSlots(rootLayoutFunc(content, rootProps), templpkg.LayoutSlotsOf(content))
 	} else {
 		wrappedContent = content
 	}
 
-	var buf bytes.Buffer
-	if rerr := wrappedContent.Render(c.Context(), &buf); rerr != nil {
+	buf := acquireRenderBuffer()
+	defer releaseRenderBuffer(buf)
+	if rerr := wrappedContent.Render(c.Context(), buf); rerr != nil {
 		a.Logger().Error("Error rendering error boundary", "err", rerr)
 		return c.Status(statusCode).SendString("Internal Server Error")
 	}
 
 	c.Set("Content-Type", "text/html")
-	return c.Status(statusCode).Send(buf.Bytes())
+	c.Status(statusCode)
+	return sendRendered(c, buf.Bytes())
 }
 
 func (a *App) buildPageContent(route *routing.Route, params map[string]interface{}, path string) templ.Component {
@@ -119,7 +136,11 @@ func (a *App) buildPageContent(route *routing.Route, params map[string]interface
 	})
 }
 
+// wrapWithLayouts wraps content in its layout chain. Slots the page filled
+// with templpkg.WithLayoutSlots are passed to every layout and stay attached
+// to the result for the root layout.
 func (a *App) wrapWithLayouts(content templ.Component, layouts []*routing.Route, params map[string]interface{}, path string) templ.Component {
+	slots := templpkg.LayoutSlotsOf(content)
 	for i := len(layouts) - 1; i >= 0; i-- {
 		layout := layouts[i]
 		layoutFunc := routing.GetLayout(layout.Path)
@@ -142,30 +163,7 @@ func (a *App) wrapWithLayouts(content templ.Component, layouts []*routing.Route,
 			})
 		}
 	}
-	return content
-}
-
-func (a *App) buildRootLayoutProps(c gofiber.Ctx, params map[string]interface{}, tier string) map[string]interface{} {
-	wsRD, wsMR, wsHB := a.normalizeWSConfig()
-	props := map[string]interface{}{
-		"appName":             a.Config.AppName,
-		"runtimePath":         a.getRuntimePathForTier(tier),
-		"path":                c.Path(),
-		"debug":               a.Config.DevMode,
-		"wsUrl":               a.getWSUrl(c),
-		"hydrationMode":       a.Config.HydrationMode,
-		"hydrationTimeout":    a.Config.HydrationTimeout,
-		"wsReconnectDelay":    wsRD,
-		"wsMaxReconnect":      wsMR,
-		"wsHeartbeat":         wsHB,
-		"serializationFormat": a.Config.SerializationFormat,
-		"navigationOptions":   a.Config.NavigationOptions,
-		"disableSanitization": a.Config.DisableSanitization,
-	}
-	for k, v := range params {
-		props[k] = v
-	}
-	return props
+	return templpkg.WithLayoutSlots(content, slots)
 }
 
 func (a *App) buildPageHTML(ctx context.Context, route *routing.Route, params map[string]interface{}, requestPath string) ([]byte, error) {
@@ -177,7 +175,9 @@ func (a *App) buildPageHTML(ctx context.Context, route *routing.Route, params ma
 	if path == "" {
 		path = route.Path
 	}
+	prev := RenderInfo(ctx)
 	loadContext := newStaticLoadContext(path, params)
+	loadContext.locals = pageVariant{Locale: prev.Locale, Device: prev.Device}.locals()
 	loadedProps, _, err := a.resolveLoadChainWithContext(loadContext, route, layouts)
 	if err != nil {
 		return nil, err
@@ -185,16 +185,22 @@ func (a *App) buildPageHTML(ctx context.Context, route *routing.Route, params ma
 	for k, v := range params {
 		loadedProps[k] = v
 	}
+	strategy := routing.GetRouteOptions(route.Path).Strategy
+	if strategy == "" {
+		strategy = a.Config.DefaultRenderStrategy
+	}
+	ctx = withRenderInfo(ctx, RenderDetails{Strategy: strategy, Cache: CacheMiss, GeneratedAt: a.now(), Locale: prev.Locale, Device: prev.Device})
 	content := a.buildPageContent(route, loadedProps, path)
 	content = a.wrapWithLayouts(content, layouts, loadedProps, path)
 
 	rootLayoutFunc := routing.GetRootLayout()
+	buf := acquireRenderBuffer()
+	defer releaseRenderBuffer(buf)
 	if rootLayoutFunc == nil {
-		var buf bytes.Buffer
-		if err := content.Render(ctx, &buf); err != nil {
+		if err := content.Render(ctx, buf); err != nil {
 			return nil, err
 		}
-		return buf.Bytes(), nil
+		return a.transformHTML(route, bytes.Clone(buf.Bytes())), nil
 	}
 
 	wsRD, wsMR, wsHB := a.normalizeWSConfig()
@@ -209,6 +215,7 @@ func (a *App) buildPageHTML(ctx context.Context, route *routing.Route, params ma
 		"wsReconnectDelay":    wsRD,
 		"wsMaxReconnect":      wsMR,
 		"wsHeartbeat":         wsHB,
+		"wsBatchInterval":     a.Config.StateSyncCoalesceInterval.Milliseconds(),
 		"serializationFormat": string(a.Config.SerializationFormat),
 	}
 	for k, v := range params {
@@ -220,16 +227,19 @@ func (a *App) buildPageHTML(ctx context.Context, route *routing.Route, params ma
 		}
 	}
 
-	wrapped := rootLayoutFunc(content, rootProps)
-	var buf bytes.Buffer
-	if err := wrapped.Render(ctx, &buf); err != nil {
+	wrapped := templpkg.WithLayoutSlots(rootLayoutFunc(content, rootProps), templpkg.LayoutSlotsOf(content))
+	if err := wrapped.Render(ctx, buf); err != nil {
 		return nil, err
 	}
-	return buf.Bytes(), nil
+	return a.transformHTML(route, bytes.Clone(buf.Bytes())), nil
 }
 
-// getRuntimePathForTier returns the path to the client runtime script for the specified tier.
+// getRuntimePathForTier returns the path to the client runtime script for the
+// specified tier, or "" for routing.RuntimeNone.
 func (a *App) getRuntimePathForTier(tier string) string {
+	if tier == routing.RuntimeNone {
+		return ""
+	}
 	if a.Config.RuntimeScript != "" && tier == "" {
 		return a.Config.RuntimeScript
 	}
@@ -261,14 +271,13 @@ func (a *App) getRuntimePath() string {
 }
 
 func (a *App) getWSUrl(c gofiber.Ctx) string {
-	if publicOrigin := strings.TrimSpace(a.Config.PublicOrigin); publicOrigin != "" {
-		if parsed, err := url.Parse(publicOrigin); err == nil && parsed.Host != "" {
-			scheme := "ws"
-			if strings.EqualFold(parsed.Scheme, "https") {
-				scheme = "wss"
-			}
-			return scheme + "://" + parsed.Host + a.Config.WebSocketPath
-		}
+	if wsURL := a.rootLayoutPropsTemplate().publicWSURL; wsURL != "" {
+		return wsURL
+	}
+	// RunAutoTLS domains are an explicit allow list, so the Host header is
+	// safe to reflect, and the connection is always TLS.
+	if host, ok := a.autoTLSHost(c); ok {
+		return "wss://" + host + a.Config.WebSocketPath
 	}
 
 	host := strings.TrimSpace(string(c.Request().Host()))
@@ -347,3 +356,17 @@ const noncePlaceholder = "__GOSPA_NONCE_PLACEHOLDER__"
 func (a *App) replaceNonces(html []byte, nonce string) []byte {
 	return bytes.ReplaceAll(html, []byte(noncePlaceholder), []byte(nonce))
 }
+
+// requestModeStrategy maps a render strategy to one that works without a
+// long-lived process: ISR falls back to SSG, since its background
+// revalidation may never run, and PPR to SSR, since it streams dynamic slots
+// after the shell.
+func requestModeStrategy(strategy routing.RenderStrategy) routing.RenderStrategy {
+	switch strategy {
+	case routing.StrategyISR:
+		return routing.StrategySSG
+	case routing.StrategyPPR:
+		return routing.StrategySSR
+	}
+	return strategy
+}
@@ -1,10 +1,16 @@
 package gospa
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
 	gofiber "github.com/gofiber/fiber/v3"
 	"github.com/valyala/fasthttp"
 )
@@ -141,3 +147,72 @@ func TestGetWSUrl_DevFallback(t *testing.T) {
 		t.Errorf("expected ws://localhost:3000/wsx, got %s", ws)
 	}
 }
+
+func TestRequestModeStrategy(t *testing.T) {
+	for in, want := range map[routing.RenderStrategy]routing.RenderStrategy{
+		routing.StrategySSR: routing.StrategySSR,
+		routing.StrategySSG: routing.StrategySSG,
+		routing.StrategyISR: routing.StrategySSG,
+		routing.StrategyPPR: routing.StrategySSR,
+	} {
+		if got := requestModeStrategy(in); got != want {
+			t.Errorf("requestModeStrategy(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	app := New(Config{RequestMode: true, RealtimeURL: "wss://rt.example.com/_gospa/ws"})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if app.Config.EnableWebSocket || app.Hub != nil {
+		t.Fatal("RequestMode must disable WebSocket")
+	}
+	if got := app.rootLayoutPropsTemplate().publicWSURL; got != "wss://rt.example.com/_gospa/ws" {
+		t.Fatalf("ws url = %q, want RealtimeURL", got)
+	}
+}
+
+func TestWrapWithLayouts_NamedSlots(t *testing.T) {
+	app := New(Config{})
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	layoutPath := fmt.Sprintf("/test-layout-slots-%d", time.Now().UnixNano())
+	routing.RegisterLayout(layoutPath, func(children templ.Component, _ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, _ = io.WriteString(w, "<aside>")
+			if err := templpkg.LayoutSlot("sidebar", templ.Raw("default nav")).Render(ctx, w); err != nil {
+				return err
+			}
+			_, _ = io.WriteString(w, "</aside><main>")
+			if err := children.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</main>")
+			return err
+		})
+	})
+	layouts := []*routing.Route{{Path: layoutPath, Type: routing.RouteTypeLayout}}
+
+	render := func(page templ.Component) (string, templ.Component) {
+		t.Helper()
+		content := app.wrapWithLayouts(page, layouts, nil, layoutPath)
+		var b strings.Builder
+		if err := content.Render(context.Background(), &b); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return b.String(), content
+	}
+
+	out, content := render(templpkg.WithLayoutSlots(templ.Raw("body"), map[string]templ.Component{
+		"sidebar": templ.Raw("page nav"),
+	}))
+	if out != "<aside>page nav</aside><main>body</main>" {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if templpkg.LayoutSlotsOf(content)["sidebar"] == nil {
+		t.Fatal("slots must stay attached for the root layout")
+	}
+
+	out, _ = render(templ.Raw("body"))
+	if out != "<aside>default nav</aside><main>body</main>" {
+		t.Fatalf("unexpected fallback output: %s", out)
+	}
+}
@@ -0,0 +1,198 @@
+package gospa
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	gofiber "github.com/gofiber/fiber/v3"
+)
+
+// Device classes of pages varying by routing.VariantDevice.
+const (
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+)
+
+// Locals under which loaders of a varying page find its variant, e.g.
+// ctx.Local("gospa.locale").(string).
+const (
+	localeLocal = "gospa.locale"
+	deviceLocal = "gospa.device"
+)
+
+// pageVariant is the locale and device class a page is rendered for. Fields
+// the route does not vary by are empty.
+type pageVariant struct {
+	Locale string
+	Device string
+}
+
+// requestVariant negotiates the variant of the page c asks for and sets the
+// Vary headers it depends on.
+func (a *App) requestVariant(c gofiber.Ctx, opts routing.RouteOptions) pageVariant {
+	var v pageVariant
+	for _, variant := range opts.Variants {
+		switch variant {
+		case routing.VariantLocale:
+			v.Locale = negotiateLocale(c.Get("Accept-Language"), a.Config.Locales)
+			c.Vary("Accept-Language")
+			c.Locals(localeLocal, v.Locale)
+		case routing.VariantDevice:
+			v.Device = deviceClass(c.Get("Sec-CH-UA-Mobile"), c.Get("User-Agent"))
+			c.Vary("Sec-CH-UA-Mobile", "User-Agent")
+			c.Append("Accept-CH", "Sec-CH-UA-Mobile")
+			c.Locals(deviceLocal, v.Device)
+		}
+	}
+	return v
+}
+
+// locals returns v as the loader locals set for it.
+func (v pageVariant) locals() map[string]interface{} {
+	locals := map[string]interface{}{}
+	if v.Locale != "" {
+		locals[localeLocal] = v.Locale
+	}
+	if v.Device != "" {
+		locals[deviceLocal] = v.Device
+	}
+	return locals
+}
+
+// variantCacheKey appends v to the cache key of a page, as a fragment so
+// routePathFromCacheKey still yields the page path.
+func variantCacheKey(key string, v pageVariant) string {
+	if v == (pageVariant{}) {
+		return key
+	}
+	values := url.Values{}
+	if v.Locale != "" {
+		values.Set("locale", v.Locale)
+	}
+	if v.Device != "" {
+		values.Set("device", v.Device)
+	}
+	return key + "#" + values.Encode()
+}
+
+// splitVariantKey reverses variantCacheKey.
+func splitVariantKey(key string) (string, pageVariant) {
+	idx := strings.IndexByte(key, '#')
+	if idx < 0 {
+		return key, pageVariant{}
+	}
+	values, _ := url.ParseQuery(key[idx+1:])
+	return key[:idx], pageVariant{Locale: values.Get("locale"), Device: values.Get("device")}
+}
+
+// variantCacheKeys returns key along with the cache keys of its variants:
+// those this process has cached plus, for a route it serves, every variant
+// the route can have, so invalidating a path reaches all of them on any
+// process.
+func (a *App) variantCacheKeys(key string) []string {
+	keys := []string{key}
+	for _, k := range a.collectCacheKeysByKey("path:" + key) {
+		if base, _ := splitVariantKey(k); base == key && k != key {
+			keys = append(keys, k)
+		}
+	}
+	route, _ := a.Router.Match(routePathFromCacheKey(key))
+	if route == nil {
+		return keys
+	}
+	opts := routing.GetRouteOptions(route.Path)
+	locales, devices := []string{""}, []string{""}
+	for _, variant := range opts.Variants {
+		switch variant {
+		case routing.VariantLocale:
+			if len(a.Config.Locales) > 0 {
+				locales = a.Config.Locales
+			}
+		case routing.VariantDevice:
+			devices = []string{DeviceMobile, DeviceDesktop}
+		}
+	}
+	for _, locale := range locales {
+		for _, device := range devices {
+			if k := variantCacheKey(key, pageVariant{Locale: locale, Device: device}); !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+// negotiateLocale picks the entry of locales best matching an
+// Accept-Language header: by quality, an exact tag match first and then a
+// match on the primary language, e.g. "fr-CA" for "fr". It falls back to
+// the first locale.
+func negotiateLocale(header string, locales []string) string {
+	if len(locales) == 0 {
+		return ""
+	}
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	slices.SortStableFunc(tags, func(x, y weighted) int {
+		switch {
+		case x.q > y.q:
+			return -1
+		case x.q < y.q:
+			return 1
+		}
+		return 0
+	})
+	for _, t := range tags {
+		for _, locale := range locales {
+			if strings.EqualFold(t.tag, locale) {
+				return locale
+			}
+		}
+		primary, _, _ := strings.Cut(t.tag, "-")
+		for _, locale := range locales {
+			localePrimary, _, _ := strings.Cut(locale, "-")
+			if strings.EqualFold(primary, localePrimary) {
+				return locale
+			}
+		}
+	}
+	return locales[0]
+}
+
+// deviceClass classifies a client from the Sec-CH-UA-Mobile client hint,
+// or from its User-Agent when the hint is absent.
+func deviceClass(mobileHint, userAgent string) string {
+	switch strings.TrimSpace(mobileHint) {
+	case "?1":
+		return DeviceMobile
+	case "?0":
+		return DeviceDesktop
+	}
+	if strings.Contains(userAgent, "Mobi") {
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
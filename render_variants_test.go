@@ -0,0 +1,136 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	locales := []string{"en", "fr-CA", "de"}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"FR-ca", "fr-CA"},
+		{"fr", "fr-CA"},
+		{"es, de;q=0.5", "de"},
+		{"de;q=0.4, fr;q=0.9", "fr-CA"},
+		{"de;q=0, *", "en"},
+		{"ja", "en"},
+	}
+	for _, tt := range tests {
+		if got := negotiateLocale(tt.header, locales); got != tt.want {
+			t.Errorf("negotiateLocale(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+	if got := negotiateLocale("fr", nil); got != "" {
+		t.Errorf("negotiateLocale without locales = %q", got)
+	}
+}
+
+func TestDeviceClass(t *testing.T) {
+	iPhone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"
+	if got := deviceClass("?0", iPhone); got != DeviceDesktop {
+		t.Errorf("client hint ?0 = %q", got)
+	}
+	if got := deviceClass("?1", ""); got != DeviceMobile {
+		t.Errorf("client hint ?1 = %q", got)
+	}
+	if got := deviceClass("", iPhone); got != DeviceMobile {
+		t.Errorf("mobile user agent = %q", got)
+	}
+	if got := deviceClass("", "Mozilla/5.0 (X11; Linux x86_64)"); got != DeviceDesktop {
+		t.Errorf("desktop user agent = %q", got)
+	}
+}
+
+func TestVariantCacheKey(t *testing.T) {
+	key := variantCacheKey("/docs?page=2", pageVariant{Locale: "fr", Device: DeviceMobile})
+	if key != "/docs?page=2#device=mobile&locale=fr" {
+		t.Fatalf("variant key = %q", key)
+	}
+	if path := routePathFromCacheKey(key); path != "/docs" {
+		t.Fatalf("route path of %q = %q", key, path)
+	}
+	base, v := splitVariantKey(key)
+	if base != "/docs?page=2" || v != (pageVariant{Locale: "fr", Device: DeviceMobile}) {
+		t.Fatalf("splitVariantKey = %q, %+v", base, v)
+	}
+	if key := variantCacheKey("/docs", pageVariant{}); key != "/docs" {
+		t.Fatalf("key without variant = %q", key)
+	}
+}
+
+func TestPageVariants(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), CacheTemplates: true, Locales: []string{"en", "fr"}})
+	app.Config.Storage = nil
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	routePath := fmt.Sprintf("/test-variants-%d", time.Now().UnixNano())
+	route := &routing.Route{Path: routePath}
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			info := RenderInfo(ctx)
+			_, err := fmt.Fprintf(w, "<p>%s %s</p>", info.Locale, info.Device)
+			return err
+		})
+	}, routing.RouteOptions{Strategy: routing.StrategySSG, Variants: []string{routing.VariantLocale, routing.VariantDevice}})
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, routePath, nil)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	req.Header.Set("Sec-CH-UA-Mobile", "?1")
+	resp, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "<p>fr mobile</p>") {
+		t.Fatalf("body = %s", body)
+	}
+	vary := resp.Header.Get("Vary")
+	for _, h := range []string{"Accept-Language", "Sec-CH-UA-Mobile", "User-Agent"} {
+		if !strings.Contains(vary, h) {
+			t.Errorf("Vary = %q, missing %s", vary, h)
+		}
+	}
+	if got := resp.Header.Get("Accept-CH"); got != "Sec-CH-UA-Mobile" {
+		t.Errorf("Accept-CH = %q", got)
+	}
+
+	frMobile := variantCacheKey(routePath, pageVariant{Locale: "fr", Device: DeviceMobile})
+	enDesktop := variantCacheKey(routePath, pageVariant{Locale: "en", Device: DeviceDesktop})
+	app.regeneratePage(frMobile, route, nil)
+	app.regeneratePage(enDesktop, route, nil)
+	app.ssgCacheMu.RLock()
+	fr, en := app.ssgCache[frMobile], app.ssgCache[enDesktop]
+	app.ssgCacheMu.RUnlock()
+	if !strings.Contains(string(fr.html), "<p>fr mobile</p>") || !strings.Contains(string(en.html), "<p>en desktop</p>") {
+		t.Fatalf("variant entries = %q, %q", fr.html, en.html)
+	}
+
+	if n := app.Invalidate(routePath); n != 2 {
+		t.Fatalf("Invalidate removed %d entries, want 2", n)
+	}
+	app.ssgCacheMu.RLock()
+	remaining := len(app.ssgCache)
+	app.ssgCacheMu.RUnlock()
+	if remaining != 0 {
+		t.Fatalf("%d entries left after Invalidate", remaining)
+	}
+}
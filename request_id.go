@@ -0,0 +1,18 @@
+package gospa
+
+import (
+	"context"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+)
+
+// RequestID returns the ID of the request ctx belongs to, or "". It accepts
+// a fiber.Ctx, its Context(), and the context passed to remote actions. In a
+// Load function, read c.Header("X-Request-ID").
+//
+// The same ID is in the X-Request-ID response header, error responses,
+// framework log lines, and the WebSocket opened by the rendered page, so a
+// render can be followed into the realtime activity it started.
+func RequestID(ctx context.Context) string {
+	return fiber.RequestIDFromContext(ctx)
+}
@@ -85,6 +85,15 @@ type Router struct {
 	errorRouteIndex map[string]*Route
 	staticPageIndex map[string]*Route
 	dynamicRoutes   []*Route
+	dynamicTrie     *routeTrie
+	// basePath prefixes every scanned route path, see SetBasePath.
+	basePath string
+
+	// layoutChains caches ResolveLayoutChain for scanned pages and error
+	// routes. It is rebuilt when layouts are registered after Scan.
+	chainsMu      sync.RWMutex
+	layoutChains  map[*Route][]*Route
+	chainsVersion uint64
 }
 
 // NewRouter creates a new router with the given routes directory or filesystem.
@@ -113,6 +122,32 @@ func NewRouter(routesSource interface{}) *Router {
 	}
 }
 
+// SetBasePath makes Scan place every route under base, e.g. /shop, as when
+// the app is mounted into another one. Registry lookups use the prefixed
+// paths, so the routes must be generated with the same base path. Call it
+// before Scan.
+func (r *Router) SetBasePath(base string) {
+	r.basePath = strings.TrimSuffix(base, "/")
+}
+
+// BasePath returns the prefix set with SetBasePath.
+func (r *Router) BasePath() string {
+	return r.basePath
+}
+
+// JoinBasePath places the route path under base: JoinBasePath("/shop", "/")
+// is /shop and JoinBasePath("/shop", "/cart") is /shop/cart.
+func JoinBasePath(base, path string) string {
+	base = strings.TrimSuffix(base, "/")
+	if base == "" {
+		return path
+	}
+	if path == "/" || path == "" {
+		return base
+	}
+	return base + path
+}
+
 // Scan scans the routes directory and builds the route tree.
 func (r *Router) Scan() error {
 	// Reset previously discovered routes so repeated Scan calls are idempotent.
@@ -217,7 +252,7 @@ func (r *Router) parseRoute(relPath string) (*Route, error) {
 	}
 
 	// Convert file path to URL path
-	urlPath := r.filePathToURLPath(relPath, routeType)
+	urlPath := JoinBasePath(r.basePath, r.filePathToURLPath(relPath, routeType))
 
 	// Extract parameters
 	params, isDynamic, isCatchAll := extractParams(urlPath)
@@ -502,15 +537,19 @@ func (r *Router) Match(urlPath string) (*Route, map[string]string) {
 		return route, make(map[string]string)
 	}
 
+	if r.dynamicTrie == nil {
+		return nil, nil
+	}
 	pathSegs := splitPathSegments(urlPath)
 
-	// 2. Check dynamic routes (O(D) where D is number of dynamic routes)
-	for _, route := range r.dynamicRoutes {
-		if params, ok := matchRouteSegments(route.matchSegments, pathSegs); ok {
-			return route, params
-		}
+	// 2. Check dynamic routes (O(path length) via the segment trie)
+	rank := r.dynamicTrie.match(pathSegs)
+	if rank < 0 {
+		return nil, nil
 	}
-	return nil, nil
+	route := r.dynamicRoutes[rank]
+	params, _ := matchRouteSegments(route.matchSegments, pathSegs)
+	return route, params
 }
 
 // matchRoute checks if a route pattern matches a URL path.
@@ -709,11 +748,49 @@ func (r *Route) String() string {
 // directory does not contain .templ source files (e.g. a production binary
 // deployed without source), the global registry is consulted as a fallback so
 // that layouts registered via generated init() code are still applied.
+//
+// Chains for scanned routes are precomputed and shared; callers must not
+// modify the returned slice.
 func (r *Router) ResolveLayoutChain(route *Route) []*Route {
 	if route == nil {
 		return nil
 	}
 
+	version := globalRegistry.layoutsVersion.Load()
+	r.chainsMu.RLock()
+	chain, ok := r.layoutChains[route]
+	stale := r.chainsVersion != version
+	r.chainsMu.RUnlock()
+	if stale {
+		r.precomputeLayoutChains()
+		r.chainsMu.RLock()
+		chain, ok = r.layoutChains[route]
+		r.chainsMu.RUnlock()
+	}
+	if ok {
+		return chain
+	}
+	return r.buildLayoutChain(route)
+}
+
+// precomputeLayoutChains resolves the layout chain of every scanned page and
+// error route against the current layout registry.
+func (r *Router) precomputeLayoutChains() {
+	r.chainsMu.Lock()
+	defer r.chainsMu.Unlock()
+	version := globalRegistry.layoutsVersion.Load()
+	chains := make(map[*Route][]*Route)
+	for _, rt := range r.routes {
+		if rt.Type == RouteTypePage || rt.Type == RouteTypeError {
+			chains[rt] = r.buildLayoutChain(rt)
+		}
+	}
+	r.layoutChains = chains
+	r.chainsVersion = version
+}
+
+// buildLayoutChain walks the path hierarchy of route collecting layouts.
+func (r *Router) buildLayoutChain(route *Route) []*Route {
 	chain := make([]*Route, 0)
 
 	// synthRoute creates a synthetic *Route for a layout that exists only in
@@ -891,4 +968,6 @@ func (r *Router) rebuildIndexes() {
 			r.errorRouteIndex[rt.Path] = rt
 		}
 	}
+	r.dynamicTrie = newRouteTrie(r.dynamicRoutes)
+	r.precomputeLayoutChains()
 }
@@ -15,11 +15,12 @@ import (
 	"strings"
 
 	"github.com/aydenstechdungeon/gospa/compiler/sfc"
+	"github.com/aydenstechdungeon/gospa/routing"
 )
 
 var (
 	rePkgName      = regexp.MustCompile(`[^a-zA-Z0-9]+`)
-	reDynamicParam = regexp.MustCompile(`:([a-zA-Z_][a-zA-Z0-9_]*)`)
+	reDynamicParam = regexp.MustCompile(`[:*]\??([a-zA-Z_][a-zA-Z0-9_]*)`)
 )
 
 // RouteInfo holds information about a discovered route.
@@ -41,6 +42,7 @@ type RouteInfo struct {
 	ActionFuncs    map[string]string // Optional actionName -> exported function symbol
 	RuntimeTier    string            // Client runtime tier needed by this component
 	HasPageOptions bool              // True if route defines PageOptions in a companion options file
+	Meta           *PageMeta         // Page metadata from //gospa:meta comments, if any
 }
 
 // FuncParam represents a function parameter.
@@ -49,8 +51,21 @@ type FuncParam struct {
 	Type string
 }
 
+// Options configures GenerateWithOptions.
+type Options struct {
+	// BasePath places every route under a prefix such as /shop. Use it for
+	// the routes of an app mounted into another one with App.Mount, and pass
+	// the same prefix to Mount.
+	BasePath string
+}
+
 // Generate scans the routes directory and generates registration code.
 func Generate(routesDir string) error {
+	return GenerateWithOptions(routesDir, Options{})
+}
+
+// GenerateWithOptions is Generate with options.
+func GenerateWithOptions(routesDir string, opts Options) error {
 	// Output file path
 	outputPath := filepath.Join(routesDir, "generated_routes.go")
 
@@ -59,6 +74,9 @@ func Generate(routesDir string) error {
 	if err != nil {
 		return fmt.Errorf("scanning routes: %w", err)
 	}
+	for i := range routes {
+		routes[i].URLPath = routing.JoinBasePath(opts.BasePath, routes[i].URLPath)
+	}
 
 	// Check for hooks.server.go
 	hasHooks := false
@@ -95,6 +113,9 @@ func Generate(routesDir string) error {
 		if err := routeGen.GenerateRoutesFile(generatedDir); err != nil {
 			fmt.Printf("Warning: failed to generate route helpers: %v\n", err)
 		}
+		if err := generatePathsPackage(routes, generatedDir); err != nil {
+			fmt.Printf("Warning: failed to generate paths package: %v\n", err)
+		}
 
 		// Generate Remote Action helpers
 		actionGen := NewActionTypeScriptGenerator()
@@ -141,6 +162,9 @@ func scanRoutes(routesDir string) ([]RouteInfo, error) {
 		if err != nil {
 			return err
 		}
+		if dir := filepath.Dir(relPath); strings.ContainsAny(dir, "[]()*") {
+			return fmt.Errorf("%s: route directories are imported as Go packages, so their names cannot contain brackets, parentheses or '*'; use _id for an [id] directory and a bracket file name such as [...slug].templ for a catch-all", relPath)
+		}
 
 		route := parseRoute(relPath, routesDir)
 		route.FilePath = relPath
@@ -204,6 +228,15 @@ func scanRoutes(routesDir string) ([]RouteInfo, error) {
 					route.RuntimeTier = strings.TrimSpace(line[:end])
 				}
 			}
+			if !route.IsLayout && !route.IsError {
+				meta, ok, err := parsePageMeta(content)
+				if err != nil {
+					return fmt.Errorf("%s: %w", relPath, err)
+				}
+				if ok {
+					route.Meta = &meta
+				}
+			}
 		}
 
 		routeKind := "page"
@@ -339,53 +372,32 @@ func parseRoute(relPath, routesDir string) RouteInfo {
 	return route
 }
 
-// parseTemplGoFile parses a _templ.go file to extract the component function name and parameters using go/parser.
-func parseTemplGoFile(path string) (string, []FuncParam) {
+// Component is a templ component function found in a _templ.go file.
+type Component struct {
+	Name   string
+	Params []FuncParam
+}
+
+// ParseComponents returns the exported functions returning templ.Component
+// declared in a _templ.go file, in source order.
+func ParseComponents(path string) ([]Component, error) {
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, filepath.Clean(path), nil, parser.ParseComments)
 	if err != nil {
-		return "", nil
+		return nil, err
 	}
 
-	// Iterate through declarations to find the first exported function returning templ.Component
+	var components []Component
 	for _, decl := range node.Decls {
 		fnDecl, ok := decl.(*ast.FuncDecl)
-		if !ok || fnDecl.Name == nil {
+		if !ok || fnDecl.Name == nil || fnDecl.Recv != nil || !fnDecl.Name.IsExported() {
 			continue
 		}
-
-		// Must be exported (starts with capital letter)
-		if !fnDecl.Name.IsExported() {
-			continue
-		}
-
-		// Check return types for templ.Component
-		if fnDecl.Type.Results == nil {
-			continue
-		}
-
-		hasTemplComponent := false
-		for _, retField := range fnDecl.Type.Results.List {
-			switch t := retField.Type.(type) {
-			case *ast.SelectorExpr:
-				if ident, ok := t.X.(*ast.Ident); ok && ident.Name == "templ" && t.Sel.Name == "Component" {
-					hasTemplComponent = true
-					break
-				}
-			}
-			if hasTemplComponent {
-				break
-			}
-		}
-
-		if !hasTemplComponent {
+		if !returnsTemplComponent(fnDecl) {
 			continue
 		}
 
-		fnName := fnDecl.Name.Name
 		var params []FuncParam
-
-		// Extract parameters
 		if fnDecl.Type.Params != nil {
 			for _, field := range fnDecl.Type.Params.List {
 				var typeBuf bytes.Buffer
@@ -404,11 +416,52 @@ func parseTemplGoFile(path string) (string, []FuncParam) {
 				}
 			}
 		}
+		components = append(components, Component{Name: fnDecl.Name.Name, Params: params})
+	}
+	return components, nil
+}
 
-		return fnName, params
+func returnsTemplComponent(fnDecl *ast.FuncDecl) bool {
+	if fnDecl.Type.Results == nil {
+		return false
 	}
+	for _, retField := range fnDecl.Type.Results.List {
+		if t, ok := retField.Type.(*ast.SelectorExpr); ok {
+			if ident, ok := t.X.(*ast.Ident); ok && ident.Name == "templ" && t.Sel.Name == "Component" {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	return "", nil
+// parseTemplGoFile returns the name and parameters of the first component in
+// a _templ.go file, or "" when there is none.
+func parseTemplGoFile(path string) (string, []FuncParam) {
+	components, err := ParseComponents(path)
+	if err != nil || len(components) == 0 {
+		return "", nil
+	}
+	return components[0].Name, components[0].Params
+}
+
+// urlSegment converts a directory or file name to its URL segment with the
+// router's rules: [id] and _id become :id, [[id]] :?id, [...rest] *rest and
+// [[...rest]] *?rest.
+func urlSegment(part string) string {
+	switch {
+	case strings.HasPrefix(part, "[[...") && strings.HasSuffix(part, "]]"):
+		return "*?" + part[5:len(part)-2]
+	case strings.HasPrefix(part, "[...") && strings.HasSuffix(part, "]"):
+		return "*" + part[4:len(part)-1]
+	case strings.HasPrefix(part, "[[") && strings.HasSuffix(part, "]]"):
+		return ":?" + part[2:len(part)-2]
+	case strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]"):
+		return ":" + part[1:len(part)-1]
+	case strings.HasPrefix(part, "_") && len(part) > 1:
+		return ":" + part[1:]
+	}
+	return part
 }
 
 // filePathToURLPath converts a file path to a URL path.
@@ -438,20 +491,14 @@ func filePathToURLPath(dir, filename string) string {
 			continue
 		}
 
-		// Convert _param to :param (dynamic segment)
-		if strings.HasPrefix(part, "_") {
-			paramName := strings.TrimPrefix(part, "_")
-			urlParts = append(urlParts, ":"+paramName)
-		} else {
-			urlParts = append(urlParts, part)
-		}
+		urlParts = append(urlParts, urlSegment(part))
 	}
 
 	// Add the page name if it's not an index page
 	base := strings.TrimSuffix(cleanFilename, filepath.Ext(cleanFilename))
 	base = strings.TrimPrefix(base, "generated_")
 	if base != "page" && base != "layout" && base != "root_layout" && base != "error" && base != "_error" && base != "loading" && base != "_loading" {
-		urlParts = append(urlParts, base)
+		urlParts = append(urlParts, urlSegment(base))
 	}
 
 	if len(urlParts) == 0 {
@@ -709,16 +756,8 @@ func generateCode(routes []RouteInfo, routesDir string, hasHooks bool) (string,
 	}
 	needsStrconv := false
 	for _, route := range routes {
-		for _, p := range route.Params {
-			switch p.Type {
-			case "int", "int64", "int32", "bool", "float64", "float32":
-				needsStrconv = true
-			}
-			if needsStrconv {
-				break
-			}
-		}
-		if needsStrconv {
+		if NeedsStrconv(route.Params) {
+			needsStrconv = true
 			break
 		}
 	}
@@ -800,9 +839,17 @@ func generateCode(routes []RouteInfo, routesDir string, hasHooks bool) (string,
 	sb.WriteString("func mergeRouteOptions(base routing.RouteOptions, override routing.RouteOptions) routing.RouteOptions {\n")
 	sb.WriteString("\tif override.Strategy != \"\" {\n\t\tbase.Strategy = override.Strategy\n\t}\n")
 	sb.WriteString("\tif override.RevalidateAfter > 0 {\n\t\tbase.RevalidateAfter = override.RevalidateAfter\n\t}\n")
+	sb.WriteString("\tif override.RevalidateCron != \"\" {\n\t\tbase.RevalidateCron = override.RevalidateCron\n\t}\n")
 	sb.WriteString("\tif len(override.DynamicSlots) > 0 {\n\t\tbase.DynamicSlots = override.DynamicSlots\n\t}\n")
 	sb.WriteString("\tif len(override.DeferredSlots) > 0 {\n\t\tbase.DeferredSlots = override.DeferredSlots\n\t}\n")
 	sb.WriteString("\tif override.RuntimeTier != \"\" {\n\t\tbase.RuntimeTier = override.RuntimeTier\n\t}\n")
+	sb.WriteString("\tif override.Runtime != \"\" {\n\t\tbase.Runtime = override.Runtime\n\t}\n")
+	sb.WriteString("\tif override.NoIndex {\n\t\tbase.NoIndex = true\n\t}\n")
+	sb.WriteString("\tif override.NoFollow {\n\t\tbase.NoFollow = true\n\t}\n")
+	sb.WriteString("\tif override.CacheControl != \"\" {\n\t\tbase.CacheControl = override.CacheControl\n\t}\n")
+	sb.WriteString("\tif override.SurrogateControl != \"\" {\n\t\tbase.SurrogateControl = override.SurrogateControl\n\t}\n")
+	sb.WriteString("\tif len(override.SurrogateKeys) > 0 {\n\t\tbase.SurrogateKeys = override.SurrogateKeys\n\t}\n")
+	sb.WriteString("\tif len(override.Variants) > 0 {\n\t\tbase.Variants = override.Variants\n\t}\n")
 	sb.WriteString("\tif override.RateLimit != nil {\n\t\tbase.RateLimit = override.RateLimit\n\t}\n")
 	sb.WriteString("\treturn base\n")
 	sb.WriteString("}\n\n")
@@ -828,6 +875,9 @@ func generateCode(routes []RouteInfo, routesDir string, hasHooks bool) (string,
 			fmt.Fprintf(&sb, "\trouting.RegisterPageWithOptions(%q, func(props map[string]interface{}) templ.Component {\n", route.URLPath)
 			fmt.Fprintf(&sb, "\t\treturn %s\n", generatePageCallWithPackage(route))
 			fmt.Fprintf(&sb, "\t}, %s)\n", generateRouteOptionsExpression(route))
+			if route.Meta != nil {
+				fmt.Fprintf(&sb, "\trouting.RegisterPageMeta(%q, %s)\n", route.URLPath, route.Meta.expression())
+			}
 
 			if route.HasLoader {
 				pkgPrefix := ""
@@ -986,29 +1036,50 @@ func getModuleInfo(dir string) (moduleName string, moduleRoot string) {
 // generatePageCallWithPackage generates the function call with package prefix if needed.
 func generatePageCallWithPackage(route RouteInfo) string {
 	fnName := route.ComponentFn
+	params := route.Params
 	if route.PackageName != "routes" && route.ImportPath != "" {
 		fnName = route.PackageName + "." + fnName
+		params = qualifyParams(params, route.PackageName)
 	}
+	return PageCall(fnName, params)
+}
 
-	// If no params, just call the function
-	if len(route.Params) == 0 {
-		return fnName + "()"
+// PageCall returns Go source calling page component fn with each parameter
+// read from a props map named props. String props are parsed for int, bool
+// and float parameters, so a dynamic segment such as :id can feed an int.
+// Other types are type-asserted and fall back to their zero value.
+func PageCall(fn string, params []FuncParam) string {
+	args := make([]string, 0, len(params))
+	for _, param := range params {
+		args = append(args, propArg(param))
 	}
+	return fn + "(" + strings.Join(args, ", ") + ")"
+}
 
-	// Build argument list by matching route params to function params
-	var args []string
-	for _, param := range route.Params {
-		// Generate extraction from props
-		switch param.Type {
-		case "string":
-			args = append(args, fmt.Sprintf(`func() string {
+// NeedsStrconv reports whether PageCall output for params uses strconv.
+func NeedsStrconv(params []FuncParam) bool {
+	for _, p := range params {
+		switch p.Type {
+		case "int", "int64", "int32", "bool", "float64", "float32":
+			return true
+		}
+	}
+	return false
+}
+
+// propArg returns the expression converting props[param.Name] to the
+// parameter's type.
+func propArg(param FuncParam) string {
+	switch param.Type {
+	case "string":
+		return fmt.Sprintf(`func() string {
 		if v, ok := props["%s"].(string); ok {
 			return v
 		}
 		return ""
-	}()`, param.Name))
-		case "int", "int64", "int32":
-			args = append(args, fmt.Sprintf(`func() %s {
+	}()`, param.Name)
+	case "int", "int64", "int32":
+		return fmt.Sprintf(`func() %s {
 		if v, ok := props["%s"].(%s); ok {
 			return v
 		}
@@ -1019,9 +1090,9 @@ func generatePageCallWithPackage(route RouteInfo) string {
 			}
 		}
 		return 0
-	}()`, param.Type, param.Name, param.Type, param.Name, param.Type))
-		case "bool":
-			args = append(args, fmt.Sprintf(`func() bool {
+	}()`, param.Type, param.Name, param.Type, param.Name, param.Type)
+	case "bool":
+		return fmt.Sprintf(`func() bool {
 		if v, ok := props["%s"].(bool); ok {
 			return v
 		}
@@ -1032,9 +1103,9 @@ func generatePageCallWithPackage(route RouteInfo) string {
 			}
 		}
 		return false
-	}()`, param.Name, param.Name))
-		case "float64", "float32":
-			args = append(args, fmt.Sprintf(`func() %s {
+	}()`, param.Name, param.Name)
+	case "float64", "float32":
+		return fmt.Sprintf(`func() %s {
 		if v, ok := props["%s"].(%s); ok {
 			return v
 		}
@@ -1045,96 +1116,84 @@ func generatePageCallWithPackage(route RouteInfo) string {
 			}
 		}
 		return 0.0
-	}()`, param.Type, param.Name, param.Type, param.Name, param.Type))
-		case "templ.Component":
-			args = append(args, fmt.Sprintf(`func() templ.Component {
-		if v, ok := props["%s"].(templ.Component); ok {
-			return v
-		}
-		return nil
-	}()`, param.Name))
-		default:
-			// For complex types, try interface{}
-			args = append(args, fmt.Sprintf(`props["%s"]`, param.Name))
-		}
+	}()`, param.Type, param.Name, param.Type, param.Name, param.Type)
+	default:
+		// Props structs, templ.Component, maps and the like.
+		return fmt.Sprintf(`func() %s {
+		v, _ := props["%s"].(%s)
+		return v
+	}()`, param.Type, param.Name, param.Type)
 	}
+}
 
-	return fnName + "(" + strings.Join(args, ", ") + ")"
+// qualifyParams prefixes the exported type names declared in a route's own
+// package, such as a PageProps struct, with that package's name.
+func qualifyParams(params []FuncParam, pkg string) []FuncParam {
+	out := make([]FuncParam, len(params))
+	for i, p := range params {
+		out[i] = FuncParam{Name: p.Name, Type: qualifyType(p.Type, pkg)}
+	}
+	return out
+}
+
+func qualifyType(typ, pkg string) string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+	changed := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// Already qualified, e.g. templ.Component.
+			return false
+		case *ast.Field:
+			// Struct field and parameter names are not types.
+			if n.Type != nil {
+				ast.Inspect(n.Type, visit)
+			}
+			return false
+		case *ast.Ident:
+			if n.IsExported() {
+				n.Name = pkg + "." + n.Name
+				changed = true
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+	if !changed {
+		return typ
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		return typ
+	}
+	return buf.String()
 }
 
 // generateLayoutCallArgsWithPackage generates the function call for a layout component with package prefix.
 func generateLayoutCallArgsWithPackage(route RouteInfo) string {
 	fnName := route.ComponentFn
+	params := route.Params
 	if route.PackageName != "routes" && route.ImportPath != "" {
 		fnName = route.PackageName + "." + fnName
+		params = qualifyParams(params, route.PackageName)
 	}
 
-	if len(route.Params) == 0 {
+	if len(params) == 0 {
 		return fnName + "(children)"
 	}
 
 	// Build argument list
-	var args []string
-	for _, param := range route.Params {
-		switch param.Type {
-		case "templ.Component":
+	args := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.Type == "templ.Component" {
 			args = append(args, "children")
-		case "string":
-			args = append(args, fmt.Sprintf(`func() string {
-		if v, ok := props["%s"].(string); ok {
-			return v
-		}
-		return ""
-	}()`, param.Name))
-		case "int", "int64", "int32":
-			args = append(args, fmt.Sprintf(`func() %s {
-		if v, ok := props["%s"].(%s); ok {
-			return v
-		}
-		if v, ok := props["%s"].(string); ok {
-			parsed, err := strconv.ParseInt(v, 10, 64)
-			if err == nil {
-				return %s(parsed)
-			}
-		}
-		return 0
-	}()`, param.Type, param.Name, param.Type, param.Name, param.Type))
-		case "bool":
-			args = append(args, fmt.Sprintf(`func() bool {
-		if v, ok := props["%s"].(bool); ok {
-			return v
-		}
-		if v, ok := props["%s"].(string); ok {
-			parsed, err := strconv.ParseBool(v)
-			if err == nil {
-				return parsed
-			}
-		}
-		return false
-	}()`, param.Name, param.Name))
-		case "float64", "float32":
-			args = append(args, fmt.Sprintf(`func() %s {
-		if v, ok := props["%s"].(%s); ok {
-			return v
-		}
-		if v, ok := props["%s"].(string); ok {
-			parsed, err := strconv.ParseFloat(v, 64)
-			if err == nil {
-				return %s(parsed)
-			}
-		}
-		return 0.0
-	}()`, param.Type, param.Name, param.Type, param.Name, param.Type))
-		case "map[string]interface{}":
-			args = append(args, fmt.Sprintf(`func() map[string]interface{} {
-		if v, ok := props["%s"].(map[string]interface{}); ok {
-			return v
-		}
-		return nil
-	}()`, param.Name))
-		default:
-			args = append(args, fmt.Sprintf(`props["%s"]`, param.Name))
+			continue
 		}
+		args = append(args, propArg(param))
 	}
 
 	return fnName + "(" + strings.Join(args, ", ") + ")"
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/aydenstechdungeon/gospa/routing"
 )
 
 func TestToPascalCase(t *testing.T) {
@@ -44,6 +47,11 @@ func TestFilePathToURLPath(t *testing.T) {
 		{"(auth)/login", "page.templ", "/login"},
 		{"blog", "post.templ", "/blog/post"},
 		{"users/_userId/posts/_postId", "page.templ", "/users/:userId/posts/:postId"},
+		{"blog", "[slug].templ", "/blog/:slug"},
+		{"docs", "[...path].templ", "/docs/*path"},
+		{"shop", "[[...filters]].templ", "/shop/*?filters"},
+		{"archive", "[[year]].templ", "/archive/:?year"},
+		{"(marketing)/_id", "page.templ", "/:id"},
 	}
 
 	for _, tt := range tests {
@@ -239,3 +247,171 @@ func ActionDefault(c routing.LoadContext) (interface{}, error) { return nil, nil
 		t.Fatalf("expected actions discovered from page.gospa module script")
 	}
 }
+
+func TestParseComponents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page_templ.go")
+	src := `package blog
+
+import "github.com/a-h/templ"
+
+func helper() templ.Component { return nil }
+
+func Page(id int, props PageProps, extra map[string]any) templ.Component { return nil }
+
+func Sidebar() templ.Component { return nil }
+`
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	components, err := ParseComponents(path)
+	if err != nil {
+		t.Fatalf("ParseComponents: %v", err)
+	}
+	if len(components) != 2 || components[0].Name != "Page" || components[1].Name != "Sidebar" {
+		t.Fatalf("components = %+v", components)
+	}
+	want := []FuncParam{{"id", "int"}, {"props", "PageProps"}, {"extra", "map[string]any"}}
+	for i, p := range components[0].Params {
+		if p != want[i] {
+			t.Fatalf("param %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestGeneratePageCallWithPackage_TypedParams(t *testing.T) {
+	call := generatePageCallWithPackage(RouteInfo{
+		ComponentFn: "Page",
+		PackageName: "blogid",
+		ImportPath:  "blog/_id",
+		Params:      []FuncParam{{"id", "int"}, {"props", "*PageProps"}, {"items", "[]Item"}, {"body", "templ.Component"}},
+	})
+	for _, want := range []string{
+		`blogid.Page(`,
+		`strconv.ParseInt(v, 10, 64)`,
+		`props["props"].(*blogid.PageProps)`,
+		`props["items"].([]blogid.Item)`,
+		`props["body"].(templ.Component)`,
+	} {
+		if !strings.Contains(call, want) {
+			t.Errorf("call missing %q\n%s", want, call)
+		}
+	}
+	if !NeedsStrconv([]FuncParam{{"id", "int"}}) || NeedsStrconv([]FuncParam{{"slug", "string"}}) {
+		t.Error("NeedsStrconv mismatch")
+	}
+}
+
+func TestScanRoutes_RejectsBracketDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "blog", "[id]"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blog", "[id]", "page.templ"), []byte("package blog\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := scanRoutes(dir)
+	if err == nil || !strings.Contains(err.Error(), "_id for an [id] directory") {
+		t.Fatalf("scanRoutes error = %v", err)
+	}
+}
+
+// TestMixedTreeMatchesRouter checks that generated registrations use the
+// same paths the router derives from the files, and that the router matches
+// them with the expected params.
+func TestMixedTreeMatchesRouter(t *testing.T) {
+	files := []string{
+		"page.templ",
+		"docs/page.templ",
+		"docs/[...path].templ",
+		"docs/_version/page.templ",
+		"blog/[slug].templ",
+		"shop/[[...filters]].templ",
+		"(marketing)/about/page.templ",
+	}
+	dir := t.TempDir()
+	fsys := fstest.MapFS{}
+	for _, f := range files {
+		full := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(full), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("package x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		fsys[f] = &fstest.MapFile{}
+	}
+
+	router := routing.NewRouter(fsys)
+	if err := router.Scan(); err != nil {
+		t.Fatalf("router scan: %v", err)
+	}
+	want := map[string]bool{}
+	for _, r := range router.GetPages() {
+		want[r.Path] = true
+	}
+	for _, f := range files {
+		got := filePathToURLPath(filepath.Dir(f), filepath.Base(f))
+		if !want[got] {
+			t.Errorf("generator maps %s to %s, which the router does not serve (router: %v)", f, got, want)
+		}
+	}
+
+	// The group directory cannot be imported, so generation refuses it.
+	if _, err := scanRoutes(dir); err == nil || !strings.Contains(err.Error(), "(marketing)") {
+		t.Fatalf("scanRoutes error = %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "(marketing)")); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := scanRoutes(dir)
+	if err != nil {
+		t.Fatalf("scanRoutes: %v", err)
+	}
+	params := map[string]string{}
+	for _, r := range routes {
+		params[r.URLPath] = strings.Join(r.RouteParams, ",")
+	}
+	if params["/docs/*path"] != "path" || params["/shop/*?filters"] != "filters" || params["/docs/:version"] != "version" {
+		t.Fatalf("route params = %v", params)
+	}
+
+	for path, wantRoute := range map[string]string{
+		"/docs":          "/docs",
+		"/docs/v2":       "/docs/:version",
+		"/docs/v2/intro": "/docs/*path",
+		"/blog/hello":    "/blog/:slug",
+		"/shop":          "/shop/*?filters",
+		"/shop/red/xl":   "/shop/*?filters",
+		"/about":         "/about",
+	} {
+		route, _ := router.Match(path)
+		if route == nil || route.Path != wantRoute {
+			t.Errorf("Match(%s) = %v, want %s", path, route, wantRoute)
+		}
+	}
+	if _, p := router.Match("/docs/v2/intro"); p["path"] != "v2/intro" {
+		t.Errorf("catch-all params = %v", p)
+	}
+}
+
+func TestRouteTypeScriptGenerator_CatchAllRoutes(t *testing.T) {
+	g := NewRouteTypeScriptGenerator([]RouteInfo{
+		{URLPath: "/docs"},
+		{URLPath: "/docs/*path", RouteParams: []string{"path"}, IsDynamic: true},
+	}, "example.com/project")
+	var sb strings.Builder
+	g.generateRouteBuilder(&sb)
+	g.generateRouteHelpers(&sb)
+	output := sb.String()
+
+	for _, want := range []string{
+		"export function docsRoute(): string",
+		"export function docsByPathRoute(params: { path: string | number }): string",
+		"if (patternPart.startsWith('*')) {",
+		"params[patternPart.replace(/^\\*\\??/, '')] = rest.join('/');",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("generated TypeScript missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// metaDirective starts a page metadata comment in a .templ file:
+//
+//	//gospa:meta title="Pricing" description="Plans and prices" order=2
+//	//gospa:meta hidden
+//
+// Keys may be split across several comments.
+const metaDirective = "//gospa:meta"
+
+// PageMeta is the page metadata read from //gospa:meta comments, mirroring
+// routing.Meta.
+type PageMeta struct {
+	Title       string
+	Description string
+	NavOrder    int
+	Hidden      bool
+}
+
+// parsePageMeta reads the //gospa:meta comments in content. It reports
+// false when there are none.
+func parsePageMeta(content []byte) (PageMeta, bool, error) {
+	var meta PageMeta
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		rest, ok := strings.CutPrefix(line, metaDirective)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		found = true
+		if err := meta.parseFields(rest); err != nil {
+			return meta, false, err
+		}
+	}
+	return meta, found, scanner.Err()
+}
+
+func (m *PageMeta) parseFields(s string) error {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return nil
+		}
+		end := strings.IndexAny(s, "= \t")
+		if end < 0 {
+			end = len(s)
+		}
+		key := s[:end]
+		s = s[end:]
+
+		value, hasValue := "", strings.HasPrefix(s, "=")
+		if hasValue {
+			s = s[1:]
+			if strings.HasPrefix(s, `"`) {
+				quoted, err := strconv.QuotedPrefix(s)
+				if err != nil {
+					return fmt.Errorf("gospa:meta %s: unterminated string", key)
+				}
+				value, _ = strconv.Unquote(quoted)
+				s = s[len(quoted):]
+			} else {
+				end := strings.IndexAny(s, " \t")
+				if end < 0 {
+					end = len(s)
+				}
+				value, s = s[:end], s[end:]
+			}
+		}
+
+		switch key {
+		case "title":
+			m.Title = value
+		case "description":
+			m.Description = value
+		case "order":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("gospa:meta order: %q is not a number", value)
+			}
+			m.NavOrder = n
+		case "hidden":
+			if !hasValue {
+				m.Hidden = true
+				break
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("gospa:meta hidden: %q is not a boolean", value)
+			}
+			m.Hidden = b
+		default:
+			return fmt.Errorf("gospa:meta: unknown key %q", key)
+		}
+	}
+}
+
+// expression returns the routing.Meta literal for m.
+func (m PageMeta) expression() string {
+	var fields []string
+	if m.Title != "" {
+		fields = append(fields, fmt.Sprintf("Title: %q", m.Title))
+	}
+	if m.Description != "" {
+		fields = append(fields, fmt.Sprintf("Description: %q", m.Description))
+	}
+	if m.NavOrder != 0 {
+		fields = append(fields, fmt.Sprintf("NavOrder: %d", m.NavOrder))
+	}
+	if m.Hidden {
+		fields = append(fields, "Hidden: true")
+	}
+	return "routing.Meta{" + strings.Join(fields, ", ") + "}"
+}
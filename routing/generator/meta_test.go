@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePageMeta(t *testing.T) {
+	content := []byte(`package routes
+
+//gospa:meta title="Plans \"&\" pricing" order=2
+//gospa:meta description="What it costs" hidden
+//gospa:metadata title="ignored"
+
+templ Page() {
+	<h1>Pricing</h1>
+}
+`)
+	meta, ok, err := parsePageMeta(content)
+	if err != nil || !ok {
+		t.Fatalf("parsePageMeta = %v, %v", ok, err)
+	}
+	want := PageMeta{Title: `Plans "&" pricing`, Description: "What it costs", NavOrder: 2, Hidden: true}
+	if meta != want {
+		t.Fatalf("meta = %+v, want %+v", meta, want)
+	}
+
+	if _, ok, _ := parsePageMeta([]byte("templ Page() {}")); ok {
+		t.Fatal("found meta in a file without //gospa:meta")
+	}
+	for _, bad := range []string{`//gospa:meta order=x`, `//gospa:meta colour=red`, `//gospa:meta title="open`} {
+		if _, _, err := parsePageMeta([]byte(bad)); err == nil {
+			t.Errorf("parsePageMeta(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestGenerateCode_RegistersPageMeta(t *testing.T) {
+	code, err := generateCode([]RouteInfo{{
+		URLPath:     "/pricing",
+		ComponentFn: "Page",
+		PackageName: "routes",
+		Meta:        &PageMeta{Title: "Pricing", NavOrder: 2},
+	}}, "routes", false)
+	if err != nil {
+		t.Fatalf("generateCode failed: %v", err)
+	}
+	if want := `routing.RegisterPageMeta("/pricing", routing.Meta{Title: "Pricing", NavOrder: 2})`; !strings.Contains(code, want) {
+		t.Fatalf("generated code missing %s\n%s", want, code)
+	}
+}
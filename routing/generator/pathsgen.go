@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// routeName converts a URL path to an identifier shared by the Go paths
+// package and routes.ts: "/" is Home, "/blog/:slug" BlogBySlug and
+// "/client-runtime" ClientRuntime.
+func routeName(path string) string {
+	if path == "/" {
+		return "Home"
+	}
+	var name strings.Builder
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			name.WriteString("By")
+			part = strings.TrimLeft(part, ":*?")
+		}
+		name.WriteString(identWords(part))
+	}
+	if name.Len() == 0 || unicode.IsDigit(rune(name.String()[0])) {
+		return "Route" + name.String()
+	}
+	return name.String()
+}
+
+// identWords turns "client-runtime" into "ClientRuntime".
+func identWords(s string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(toTitle(w))
+	}
+	return b.String()
+}
+
+// pathRoute is a page route as it appears in the generated paths package.
+type pathRoute struct {
+	name     string
+	pattern  string
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	static   string
+	param    string // Go parameter name, for dynamic segments
+	catchAll bool
+}
+
+// pathRoutes returns the page routes sorted by path with unique names.
+func pathRoutes(routes []RouteInfo) []pathRoute {
+	var pages []RouteInfo
+	for _, r := range routes {
+		if !r.IsLayout && !r.IsError {
+			pages = append(pages, r)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].URLPath < pages[j].URLPath })
+
+	used := make(map[string]bool)
+	out := make([]pathRoute, 0, len(pages))
+	for _, r := range pages {
+		if len(out) > 0 && out[len(out)-1].pattern == r.URLPath {
+			continue
+		}
+		name := routeName(r.URLPath)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", routeName(r.URLPath), i)
+		}
+		used[name] = true
+
+		pr := pathRoute{name: name, pattern: r.URLPath}
+		for _, part := range strings.Split(strings.Trim(r.URLPath, "/"), "/") {
+			switch {
+			case part == "":
+			case strings.HasPrefix(part, ":"), strings.HasPrefix(part, "*"):
+				param := goParamName(strings.TrimLeft(part, ":*?"))
+				pr.segments = append(pr.segments, pathSegment{param: param, catchAll: part[0] == '*'})
+			default:
+				pr.segments = append(pr.segments, pathSegment{static: part})
+			}
+		}
+		out = append(out, pr)
+	}
+	return out
+}
+
+func (r pathRoute) dynamic() bool {
+	for _, s := range r.segments {
+		if s.param != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// goParamName makes a route param usable as a Go parameter name.
+func goParamName(name string) string {
+	words := identWords(name)
+	if words == "" {
+		return "param"
+	}
+	ident := strings.ToLower(words[:1]) + words[1:]
+	if token.IsKeyword(ident) || unicode.IsDigit(rune(ident[0])) {
+		return ident + "_"
+	}
+	return ident
+}
+
+// generatePathsPackage writes package paths to outputDir/paths/paths.go:
+// a constant per route pattern and, for dynamic routes, a URL builder, so
+// links are checked by the compiler instead of hardcoded.
+func generatePathsPackage(routes []RouteInfo, outputDir string) error {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by gospa route generator. DO NOT EDIT.\n\n")
+	sb.WriteString("// Package paths holds the app's route paths, generated from the routes\n")
+	sb.WriteString("// directory. Constants are the patterns the router registers; functions\n")
+	sb.WriteString("// build URLs for dynamic routes.\n")
+	sb.WriteString("package paths\n\n")
+	sb.WriteString("import (\n\t\"net/url\"\n\t\"strings\"\n)\n\n")
+
+	prs := pathRoutes(routes)
+	sb.WriteString("// Route patterns.\nconst (\n")
+	for _, r := range prs {
+		name := r.name
+		if r.dynamic() {
+			name += "Pattern"
+		}
+		fmt.Fprintf(&sb, "\t%s = %q\n", name, r.pattern)
+	}
+	sb.WriteString(")\n")
+
+	for _, r := range prs {
+		if !r.dynamic() {
+			continue
+		}
+		var params, args []string
+		for _, s := range r.segments {
+			switch {
+			case s.param == "":
+				args = append(args, fmt.Sprintf("%q", s.static))
+			case s.catchAll:
+				params = append(params, s.param)
+				args = append(args, "escapeCatchAll("+s.param+")")
+			default:
+				params = append(params, s.param)
+				args = append(args, "url.PathEscape("+s.param+")")
+			}
+		}
+		fmt.Fprintf(&sb, "\n// %s returns the URL of %s.\n", r.name, r.pattern)
+		fmt.Fprintf(&sb, "func %s(%s string) string {\n", r.name, strings.Join(params, ", "))
+		fmt.Fprintf(&sb, "\treturn joinSegments(%s)\n}\n", strings.Join(args, ", "))
+	}
+
+	sb.WriteString(`
+// joinSegments joins non-empty segments into a path, so empty optional
+// params are left out.
+func joinSegments(segments ...string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		if s != "" {
+			b.WriteByte('/')
+			b.WriteString(s)
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// escapeCatchAll escapes each segment of a catch-all value, keeping the
+// slashes between them.
+func escapeCatchAll(value string) string {
+	parts := strings.Split(strings.Trim(value, "/"), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+`)
+
+	code, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return fmt.Errorf("formatting paths package: %w", err)
+	}
+	dir := filepath.Join(outputDir, "paths")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "paths.go"), code, 0600)
+}
+
+// generatePathsMap writes the TypeScript counterpart of the Go paths
+// package: the same names, with builders for dynamic routes.
+func (g *RouteTypeScriptGenerator) generatePathsMap(sb *strings.Builder) {
+	sb.WriteString("/**\n")
+	sb.WriteString(" * Route paths by name, matching the Go paths package.\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("export const paths = {\n")
+	for _, r := range pathRoutes(g.routes) {
+		if !r.dynamic() {
+			fmt.Fprintf(sb, "  %s: %q,\n", r.name, r.pattern)
+			continue
+		}
+		fmt.Fprintf(sb, "  %s: (params: RouteParams<%q>) => buildRoute(%q, params),\n", r.name, r.pattern, r.pattern)
+	}
+	sb.WriteString("} as const;\n")
+}
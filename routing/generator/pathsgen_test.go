@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRouteName(t *testing.T) {
+	tests := map[string]string{
+		"/":                  "Home",
+		"/blog":              "Blog",
+		"/blog/:slug":        "BlogBySlug",
+		"/docs/*path":        "DocsByPath",
+		"/shop/:?category":   "ShopByCategory",
+		"/client-runtime":    "ClientRuntime",
+		"/404":               "Route404",
+		"/api/v2/user_stats": "ApiV2UserStats",
+	}
+	for path, want := range tests {
+		if got := routeName(path); got != want {
+			t.Errorf("routeName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestGeneratePathsPackage(t *testing.T) {
+	routes := []RouteInfo{
+		{URLPath: "/"},
+		{URLPath: "/", IsLayout: true},
+		{URLPath: "/blog"},
+		{URLPath: "/blog/:slug", RouteParams: []string{"slug"}},
+		{URLPath: "/docs/*path", RouteParams: []string{"path"}},
+		{URLPath: "/shop/:?category", RouteParams: []string{"category"}},
+		{URLPath: "/types/:type", RouteParams: []string{"type"}},
+		{URLPath: "/client-runtime"},
+		{URLPath: "/blog", IsError: true},
+	}
+	outDir := t.TempDir()
+	if err := generatePathsPackage(routes, outDir); err != nil {
+		t.Fatalf("generatePathsPackage: %v", err)
+	}
+
+	file := filepath.Join(outDir, "paths", "paths.go")
+	//nolint:gosec // file is generated by this test under t.TempDir().
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(content)
+	// Ignore gofmt's alignment of the constant block.
+	consts := strings.Join(strings.Fields(code), " ")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, content, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("paths", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("generated code does not type-check: %v\n%s", err, code)
+	}
+
+	for _, want := range []string{
+		`Home = "/"`,
+		`Blog = "/blog"`,
+		`BlogBySlugPattern = "/blog/:slug"`,
+		`ClientRuntime = "/client-runtime"`,
+	} {
+		if !strings.Contains(consts, want) {
+			t.Errorf("generated paths package missing constant %q\n%s", want, code)
+		}
+	}
+	for _, want := range []string{
+		"func BlogBySlug(slug string) string {\n\treturn joinSegments(\"blog\", url.PathEscape(slug))",
+		"func DocsByPath(path string) string {\n\treturn joinSegments(\"docs\", escapeCatchAll(path))",
+		"func ShopByCategory(category string) string",
+		"func TypesByType(type_ string) string",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated paths package missing %q\n%s", want, code)
+		}
+	}
+	if strings.Count(code, `"/blog"`) != 1 {
+		t.Errorf("layout and error routes should not get their own constants\n%s", code)
+	}
+}
+
+func TestGenerateRoutesFile_EmitsPathsMap(t *testing.T) {
+	gen := NewRouteTypeScriptGenerator([]RouteInfo{
+		{URLPath: "/"},
+		{URLPath: "/blog/:slug", RouteParams: []string{"slug"}},
+	}, "example.com/project")
+	var sb strings.Builder
+	gen.generatePathsMap(&sb)
+	output := sb.String()
+
+	for _, want := range []string{
+		"export const paths = {",
+		`  Home: "/",`,
+		`  BlogBySlug: (params: RouteParams<"/blog/:slug">) => buildRoute("/blog/:slug", params),`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("generated TypeScript missing %q\n%s", want, output)
+		}
+	}
+}
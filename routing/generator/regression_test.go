@@ -113,3 +113,29 @@ func TestActionTypeScriptGenerator_UsesDirectRemoteCall(t *testing.T) {
 		t.Fatalf("generated code should not call curried remoteAction with an input\n%s", output)
 	}
 }
+
+func TestGenerateWithOptions_BasePath(t *testing.T) {
+	routesDir := filepath.Join(t.TempDir(), "routes")
+	for _, file := range []string{"+layout.templ", "+page.templ", "cart/+page.templ"} {
+		path := filepath.Join(routesDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package routes"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := GenerateWithOptions(routesDir, Options{BasePath: "/shop"}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	code, err := os.ReadFile(filepath.Join(routesDir, "generated_routes.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`RegisterLayoutWithOptions("/shop", func(children`, `RegisterPageWithOptions("/shop", func(props`, `RegisterPageWithOptions("/shop/cart", func(props`} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated routes missing %s\n%s", want, code)
+		}
+	}
+}
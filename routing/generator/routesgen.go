@@ -64,6 +64,13 @@ func (g *RouteTypeScriptGenerator) GenerateRoutesFile(outputDir string) error {
 
 	g.generateRouteBuilder(&sb)
 
+	// Generate named paths
+	sb.WriteString("\n// ============================================\n")
+	sb.WriteString("// Named Paths\n")
+	sb.WriteString("// ============================================\n\n")
+
+	g.generatePathsMap(&sb)
+
 	// Generate route helpers
 	sb.WriteString("\n// ============================================\n")
 	sb.WriteString("// Route Helper Functions\n")
@@ -370,16 +377,17 @@ func (g *RouteTypeScriptGenerator) generateRouteBuilder(sb *strings.Builder) {
 	sb.WriteString("  path: T,\n")
 	sb.WriteString("  ...args: RouteParams<T> extends never ? [] : [params: RouteParams<T>]\n")
 	sb.WriteString("): string {\n")
-	sb.WriteString("  let result = path as string;\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  if (args.length > 0) {\n")
-	sb.WriteString("    const params = args[0] as Record<string, string | number>;\n")
-	sb.WriteString("    for (const [key, value] of Object.entries(params)) {\n")
-	sb.WriteString("      result = result.replace(`:${key}`, String(value));\n")
+	sb.WriteString("  const params = (args[0] ?? {}) as Record<string, string | number | undefined>;\n")
+	sb.WriteString("  // :name and *name take their value; optional :?name and *?name drop out when unset.\n")
+	sb.WriteString("  const parts = (path as string).split('/').filter(Boolean).map((part) => {\n")
+	sb.WriteString("    const m = /^[:*]\\??(.+)$/.exec(part);\n")
+	sb.WriteString("    if (!m) {\n")
+	sb.WriteString("      return part;\n")
 	sb.WriteString("    }\n")
-	sb.WriteString("  }\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  return result;\n")
+	sb.WriteString("    const value = params[m[1]];\n")
+	sb.WriteString("    return value === undefined ? '' : String(value);\n")
+	sb.WriteString("  });\n")
+	sb.WriteString("  return '/' + parts.filter(Boolean).join('/');\n")
 	sb.WriteString("}\n\n")
 
 	// Generate individual route builders
@@ -387,11 +395,18 @@ func (g *RouteTypeScriptGenerator) generateRouteBuilder(sb *strings.Builder) {
 	sb.WriteString(" * Individual route builder functions for better IDE support.\n")
 	sb.WriteString(" */\n")
 
+	used := make(map[string]bool)
 	for _, route := range g.routes {
 		if route.IsLayout {
 			continue
 		}
 		funcName := g.pathToFuncName(route.URLPath)
+		if used[funcName] {
+			// e.g. /docs and /docs/*slug: name the dynamic one docsBySlugRoute.
+			name := g.pathToName(route.URLPath)
+			funcName = strings.ToLower(name[:1]) + name[1:] + "Route"
+		}
+		used[funcName] = true
 
 		if len(route.RouteParams) > 0 {
 			fmt.Fprintf(sb, "export function %s(params: { ", funcName)
@@ -437,26 +452,44 @@ func (g *RouteTypeScriptGenerator) generateRouteHelpers(sb *strings.Builder) {
 	sb.WriteString("  if (routeMatchCache.has(cacheKey)) {\n")
 	sb.WriteString("    return routeMatchCache.get(cacheKey)!;\n")
 	sb.WriteString("  }\n\n")
+	sb.WriteString("  const matched = matchParams(pattern, path) !== null;\n")
+	sb.WriteString("  setRouteMatchCache(cacheKey, matched);\n")
+	sb.WriteString("  return matched;\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("/**\n")
+	sb.WriteString(" * Match path against pattern segment by segment, like the server router.\n")
+	sb.WriteString(" * A catch-all (*rest) takes the remaining segments joined by '/'.\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("function matchParams(pattern: string, path: string): Record<string, string> | null {\n")
 	sb.WriteString("  const patternParts = pattern.split('/').filter(Boolean);\n")
 	sb.WriteString("  const pathParts = path.split('/').filter(Boolean);\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  if (patternParts.length !== pathParts.length) {\n")
-	sb.WriteString("    setRouteMatchCache(cacheKey, false);\n")
-	sb.WriteString("    return false;\n")
-	sb.WriteString("  }\n")
+	sb.WriteString("  const params: Record<string, string> = {};\n")
 	sb.WriteString("  \n")
 	sb.WriteString("  for (let i = 0; i < patternParts.length; i++) {\n")
 	sb.WriteString("    const patternPart = patternParts[i];\n")
-	sb.WriteString("    const pathPart = pathParts[i];\n")
-	sb.WriteString("    \n")
-	sb.WriteString("    if (!patternPart.startsWith(':') && patternPart !== pathPart) {\n")
-	sb.WriteString("      setRouteMatchCache(cacheKey, false);\n")
-	sb.WriteString("      return false;\n")
+	sb.WriteString("    if (patternPart.startsWith('*')) {\n")
+	sb.WriteString("      const rest = pathParts.slice(i);\n")
+	sb.WriteString("      if (rest.length === 0 && !patternPart.startsWith('*?')) {\n")
+	sb.WriteString("        return null;\n")
+	sb.WriteString("      }\n")
+	sb.WriteString("      params[patternPart.replace(/^\\*\\??/, '')] = rest.join('/');\n")
+	sb.WriteString("      return params;\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    if (i >= pathParts.length) {\n")
+	sb.WriteString("      if (patternPart.startsWith(':?')) {\n")
+	sb.WriteString("        continue;\n")
+	sb.WriteString("      }\n")
+	sb.WriteString("      return null;\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    if (patternPart.startsWith(':')) {\n")
+	sb.WriteString("      params[patternPart.replace(/^:\\??/, '')] = pathParts[i];\n")
+	sb.WriteString("    } else if (patternPart !== pathParts[i]) {\n")
+	sb.WriteString("      return null;\n")
 	sb.WriteString("    }\n")
 	sb.WriteString("  }\n")
 	sb.WriteString("  \n")
-	sb.WriteString("  setRouteMatchCache(cacheKey, true);\n")
-	sb.WriteString("  return true;\n")
+	sb.WriteString("  return pathParts.length <= patternParts.length ? params : null;\n")
 	sb.WriteString("}\n\n")
 
 	sb.WriteString("/**\n")
@@ -466,25 +499,10 @@ func (g *RouteTypeScriptGenerator) generateRouteHelpers(sb *strings.Builder) {
 	sb.WriteString("  pattern: T,\n")
 	sb.WriteString("  path: string\n")
 	sb.WriteString("): RouteParams<T> | null {\n")
-	sb.WriteString("  const patternParts = pattern.split('/').filter(Boolean);\n")
-	sb.WriteString("  const pathParts = path.split('/').filter(Boolean);\n")
-	sb.WriteString("  const params: Record<string, string> = {};\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  if (patternParts.length !== pathParts.length) {\n")
+	sb.WriteString("  const params = matchParams(pattern, path);\n")
+	sb.WriteString("  if (params === null) {\n")
 	sb.WriteString("    return null;\n")
 	sb.WriteString("  }\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  for (let i = 0; i < patternParts.length; i++) {\n")
-	sb.WriteString("    const patternPart = patternParts[i];\n")
-	sb.WriteString("    const pathPart = pathParts[i];\n")
-	sb.WriteString("    \n")
-	sb.WriteString("    if (patternPart.startsWith(':')) {\n")
-	sb.WriteString("      params[patternPart.slice(1)] = pathPart;\n")
-	sb.WriteString("    } else if (patternPart !== pathPart) {\n")
-	sb.WriteString("      return null;\n")
-	sb.WriteString("    }\n")
-	sb.WriteString("  }\n")
-	sb.WriteString("  \n")
 	sb.WriteString("  return params as RouteParams<T>;\n")
 	sb.WriteString("}\n\n")
 
@@ -628,24 +646,7 @@ func (g *RouteTypeScriptGenerator) generateRouteRegistry(sb *strings.Builder) {
 
 // pathToName converts a URL path to a route name.
 func (g *RouteTypeScriptGenerator) pathToName(path string) string {
-	if path == "/" {
-		return "Home"
-	}
-
-	parts := strings.Split(strings.Trim(path, "/"), "/")
-	var name strings.Builder
-
-	for _, part := range parts {
-		if strings.HasPrefix(part, ":") {
-			// Dynamic segment - use "By" prefix
-			name.WriteString("By")
-			name.WriteString(toTitle(strings.TrimPrefix(part, ":")))
-		} else {
-			name.WriteString(toTitle(part))
-		}
-	}
-
-	return name.String()
+	return routeName(path)
 }
 
 // pathToFuncName converts a URL path to a function name.
@@ -658,7 +659,7 @@ func (g *RouteTypeScriptGenerator) pathToFuncName(path string) string {
 	var name strings.Builder
 
 	for i, part := range parts {
-		if strings.HasPrefix(part, ":") {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
 			// Dynamic segment - skip in function name
 			continue
 		}
@@ -7,13 +7,37 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// GenerateTypeScriptDefinitions scans Go structs in the routes directory to generate a types.d.ts file.
+// tsField is a property of a generated interface. A nil expr is typed
+// unknown.
+type tsField struct {
+	name string
+	expr ast.Expr
+}
+
+// tsDecls maps struct names to their fields. Structs are keyed by bare name,
+// so models.User and User share an interface.
+type tsDecls map[string][]tsField
+
+// remoteActionType is a registered remote action with its Go input and
+// output types, nil when registered untyped.
+type remoteActionType struct {
+	name   string
+	input  ast.Expr
+	output ast.Expr
+}
+
+// GenerateTypeScriptDefinitions writes routesDir/types.d.ts with interfaces
+// for the Go structs in the routes directory, the app state from
+// Config.DefaultState, the WebSocket message envelope and state patches,
+// and the input and output of each remote action in the module.
 func GenerateTypeScriptDefinitions(routesDir string) error {
 	fset := token.NewFileSet()
-	structs := make(map[string]map[string]string) // structName -> {fieldName -> tsType}
+	structs := make(tsDecls)
 
 	err := filepath.Walk(routesDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_templ.go") || strings.HasSuffix(path, "generated_routes.go") {
@@ -24,83 +48,441 @@ func GenerateTypeScriptDefinitions(routesDir string) error {
 		if err != nil {
 			return err
 		}
+		collectStructs(node, structs)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		ast.Inspect(node, func(n ast.Node) bool {
-			decl, ok := n.(*ast.GenDecl)
-			if !ok || decl.Tok != token.TYPE {
-				return true
-			}
+	_, moduleRoot := getModuleInfo(routesDir)
+	app := scanAppTypes(moduleRoot)
+	for name, fields := range structs {
+		app.decls[name] = fields
+	}
 
-			for _, spec := range decl.Specs {
-				typeSpec, ok := spec.(*ast.TypeSpec)
-				if !ok {
-					continue
-				}
+	var sb strings.Builder
+	sb.WriteString("// Code generated by GoSPA. DO NOT EDIT.\n")
+	sb.WriteString("// TypeScript Definitions for Go Structs\n\n")
 
-				structType, ok := typeSpec.Type.(*ast.StructType)
-				if !ok {
-					continue
+	// Route structs, then the module structs the app types refer to.
+	emit := make(map[string]bool)
+	for name := range structs {
+		emit[name] = true
+	}
+	var roots []ast.Expr
+	for _, f := range app.state {
+		roots = append(roots, f.expr)
+	}
+	for _, a := range app.actions {
+		roots = append(roots, a.input, a.output)
+	}
+	for name := range structs {
+		for _, f := range structs[name] {
+			roots = append(roots, f.expr)
+		}
+	}
+	for len(roots) > 0 {
+		expr := roots[len(roots)-1]
+		roots = roots[:len(roots)-1]
+		for _, name := range app.decls.refs(expr) {
+			if !emit[name] {
+				emit[name] = true
+				for _, f := range app.decls[name] {
+					roots = append(roots, f.expr)
 				}
+			}
+		}
+	}
+	names := make([]string, 0, len(emit))
+	for name := range emit {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "export interface %s {\n", name)
+		for _, f := range app.decls[name] {
+			fmt.Fprintf(&sb, "\t%s: %s;\n", tsPropName(f.name), app.decls.fieldType(f))
+		}
+		sb.WriteString("}\n\n")
+	}
 
-				// Check if the struct has a specific comment (optional constraint, we just parse all structs for now)
-				structName := typeSpec.Name.Name
-				fields := make(map[string]string)
+	sb.WriteString("/**\n")
+	sb.WriteString(" * App state from Config.DefaultState, as embedded in window.__GOSPA_STATE__\n")
+	sb.WriteString(" * and sent in \"init\" messages.\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("export interface GospaState {\n")
+	for _, f := range app.state {
+		fmt.Fprintf(&sb, "\t%s: %s;\n", tsPropName(f.name), app.decls.fieldType(f))
+	}
+	sb.WriteString("}\n\n")
+	sb.WriteString(wsEnvelopeTS)
 
-				for _, field := range structType.Fields.List {
-					if len(field.Names) == 0 {
-						continue // Embedded structs not perfectly supported without full type resolution
-					}
+	sb.WriteString("\n/**\n")
+	sb.WriteString(" * Input and output of each remote action. Actions registered with\n")
+	sb.WriteString(" * RegisterRemoteAction rather than RegisterRemoteActionTyped are unknown.\n")
+	sb.WriteString(" */\n")
+	sb.WriteString("export interface RemoteActionTypes {\n")
+	for _, a := range app.actions {
+		fmt.Fprintf(&sb, "\t%s: { input: %s; output: %s };\n", strconv.Quote(a.name),
+			app.decls.fieldType(tsField{expr: a.input}), app.decls.fieldType(tsField{expr: a.output}))
+	}
+	sb.WriteString("}\n")
+
+	outPath := filepath.Join(routesDir, "types.d.ts")
+	return os.WriteFile(outPath, []byte(sb.String()), 0600)
+}
+
+// wsEnvelopeTS mirrors the frames fiber's WebSocket handler reads and
+// writes. Patches carry the changed top-level state keys.
+const wsEnvelopeTS = `/**
+ * Changed state keys and their new values, sent in "patch" and "resume"
+ * messages.
+ */
+export type StatePatch = Partial<GospaState>;
+
+/**
+ * A message from the client, mirroring fiber.WSMessage. An "update" carries
+ * a WSStateUpdate as its payload.
+ */
+export interface WSMessage {
+	type: "init" | "update" | "sync" | "action" | "subscribe" | "unsubscribe" | "crdt" | "list" | "cancel" | "ping";
+	componentId?: string;
+	action?: string;
+	data?: Record<string, unknown>;
+	payload?: unknown;
+	clientId?: string;
+}
+
+/**
+ * The payload of an "update" message, mirroring fiber.WSStateUpdate.
+ */
+export interface WSStateUpdate {
+	key: string;
+	value: unknown;
+	version?: number;
+	ts?: number;
+}
+
+/**
+ * A message from the server.
+ */
+export type WSServerMessage =
+	| { type: "init"; state: GospaState; componentId?: string; clientId?: string; requestId?: string }
+	| { type: "patch"; patch: StatePatch; coalesced?: number; versions?: Record<string, number>; seq?: number }
+	| { type: "sync"; key: string; value: unknown; componentId?: string; version?: number; success?: boolean; conflict?: boolean }
+	| { type: "resume"; clientId: string; epoch: string; seq: number; replayed: number; patch?: StatePatch; versions?: Record<string, number> }
+	| { type: "list"; key: string; data: { cursor: string; items: { id: string; data?: unknown }[]; next: string; total: number } }
+	| { type: "list_patch"; key: string; data: { events: { op: "upsert" | "remove" | "reset"; item: { id: string; data?: unknown }; before?: string }[] } }
+	| { type: "compressed"; data: string; compressed: true }
+	| { type: "action_ack" }
+	| { type: "cancel"; data: { requestId: string; canceled: boolean } }
+	| { type: "progress"; data: { requestId: string; percent: number; message: string } }
+	| { type: "pong" }
+	| { type: "error"; error: string; code: string };
+
+/**
+ * Window globals set by the server-rendered page. Read the state with
+ * (window as unknown as GospaWindow).__GOSPA_STATE__.
+ */
+export interface GospaWindow {
+	__GOSPA_STATE__: GospaState;
+}
+`
+
+// appTypes is what scanAppTypes finds across the module.
+type appTypes struct {
+	decls   tsDecls
+	state   []tsField
+	actions []remoteActionType
+}
+
+// scanAppTypes reads every Go file under root for struct declarations,
+// DefaultState literals and remote action registrations. Files that fail
+// to parse are skipped.
+func scanAppTypes(root string) appTypes {
+	app := appTypes{decls: make(tsDecls)}
+	fset := token.NewFileSet()
+	seenState := make(map[string]bool)
+	actions := make(map[string]remoteActionType)
 
-					fieldName := field.Names[0].Name
-					// Exported fields only
-					if !ast.IsExported(fieldName) {
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" {
+				return filepath.SkipDir
+			}
+			// Nested modules are separate apps.
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "_templ.go") {
+			return nil
+		}
+		node, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil
+		}
+		collectStructs(node, app.decls)
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.KeyValueExpr:
+				if key, ok := n.Key.(*ast.Ident); !ok || key.Name != "DefaultState" {
+					return true
+				}
+				lit, ok := n.Value.(*ast.CompositeLit)
+				if !ok {
+					return true
+				}
+				for _, elt := range lit.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					name, ok := stringLit(kv.Key)
+					if !ok || seenState[name] {
 						continue
 					}
+					seenState[name] = true
+					app.state = append(app.state, tsField{name: name, expr: valueType(kv.Value)})
+				}
+			case *ast.CallExpr:
+				if a, ok := remoteActionCall(n); ok {
+					if prev, seen := actions[a.name]; !seen || prev.input == nil {
+						actions[a.name] = a
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+
+	for _, a := range actions {
+		app.actions = append(app.actions, a)
+	}
+	sort.Slice(app.actions, func(i, j int) bool { return app.actions[i].name < app.actions[j].name })
+	sort.Slice(app.state, func(i, j int) bool { return app.state[i].name < app.state[j].name })
+	return app
+}
 
-					tsType := mapGoTypeToTS(field.Type)
+// collectStructs adds the struct types declared in file to decls.
+func collectStructs(file *ast.File, decls tsDecls) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		decl, ok := n.(*ast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			return true
+		}
 
-					// Use JSON tag as field name if available
-					jsonTag := getJSONTag(field.Tag)
-					if jsonTag != "" && jsonTag != "-" {
-						fieldName = jsonTag
-					}
+		for _, spec := range decl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			var fields []tsField
+			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 {
+					continue // Embedded structs not perfectly supported without full type resolution
+				}
 
-					fields[fieldName] = tsType
+				fieldName := field.Names[0].Name
+				// Exported fields only
+				if !ast.IsExported(fieldName) {
+					continue
+				}
+
+				// Use JSON tag as field name if available
+				jsonTag := getJSONTag(field.Tag)
+				if jsonTag == "-" {
+					continue
+				}
+				if jsonTag != "" {
+					fieldName = jsonTag
 				}
 
-				structs[structName] = fields
+				fields = append(fields, tsField{name: fieldName, expr: field.Type})
 			}
-			return true
-		})
 
-		return nil
+			decls[typeSpec.Name.Name] = fields
+		}
+		return true
 	})
+}
 
-	if err != nil {
-		return err
+// remoteActionCall reports the action registered by call, if it is one of
+// the RegisterRemoteAction functions with a literal name. Typed actions
+// take their input and output from explicit type arguments or from the
+// handler's signature when it is a function literal.
+func remoteActionCall(call *ast.CallExpr) (remoteActionType, bool) {
+	fun := call.Fun
+	var typeArgs []ast.Expr
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun, typeArgs = f.X, []ast.Expr{f.Index}
+	case *ast.IndexListExpr:
+		fun, typeArgs = f.X, f.Indices
+	}
+	var name string
+	switch f := fun.(type) {
+	case *ast.SelectorExpr:
+		name = f.Sel.Name
+	case *ast.Ident:
+		name = f.Name
 	}
 
-	if len(structs) == 0 {
-		return nil // No structs found, no file generated
+	switch name {
+	case "RegisterRemoteAction", "RegisterRemoteActionWithOptions",
+		"RegisterRemoteActionTyped", "RegisterRemoteActionTypedWithOptions":
+	default:
+		return remoteActionType{}, false
+	}
+	if len(call.Args) < 2 {
+		return remoteActionType{}, false
+	}
+	actionName, ok := stringLit(call.Args[0])
+	if !ok {
+		return remoteActionType{}, false
+	}
+	a := remoteActionType{name: actionName}
+	if !strings.HasPrefix(name, "RegisterRemoteActionTyped") {
+		return a, true
+	}
+	if len(typeArgs) == 2 {
+		a.input, a.output = typeArgs[0], typeArgs[1]
+		return a, true
 	}
+	if fn, ok := call.Args[1].(*ast.FuncLit); ok {
+		if params := fieldTypes(fn.Type.Params); len(params) == 3 {
+			a.input = params[2]
+		}
+		if results := fieldTypes(fn.Type.Results); len(results) == 2 {
+			a.output = results[0]
+		}
+	}
+	return a, true
+}
 
-	var sb strings.Builder
-	sb.WriteString("// Code generated by GoSPA. DO NOT EDIT.\n")
-	sb.WriteString("// TypeScript Definitions for Go Structs\n\n")
+// fieldTypes returns one type per parameter in list.
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	if list == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, f := range list.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for range n {
+			types = append(types, f.Type)
+		}
+	}
+	return types
+}
 
-	for name, fields := range structs {
-		fmt.Fprintf(&sb, "export interface %s {\n", name)
-		for fname, ftype := range fields {
-			fmt.Fprintf(&sb, "\t%s: %s;\n", fname, ftype)
+// valueType returns the Go type of a DefaultState value where the literal
+// shows it, or nil.
+func valueType(expr ast.Expr) ast.Expr {
+	switch v := expr.(type) {
+	case *ast.BasicLit:
+		if v.Kind == token.STRING {
+			return ast.NewIdent("string")
+		}
+		return ast.NewIdent("float64")
+	case *ast.Ident:
+		if v.Name == "true" || v.Name == "false" {
+			return ast.NewIdent("bool")
+		}
+	case *ast.CompositeLit:
+		return v.Type
+	case *ast.UnaryExpr:
+		if v.Op == token.AND {
+			return valueType(v.X)
+		}
+	case *ast.CallExpr:
+		// Conversions such as int64(0) or []string(nil).
+		if len(v.Args) == 1 {
+			switch fun := v.Fun.(type) {
+			case *ast.Ident:
+				if mapGoTypeToTS(fun, nil) != fun.Name {
+					return fun
+				}
+			case *ast.ArrayType, *ast.MapType:
+				return fun
+			}
 		}
-		sb.WriteString("}\n\n")
 	}
+	return nil
+}
 
-	outPath := filepath.Join(routesDir, "types.d.ts")
-	return os.WriteFile(outPath, []byte(sb.String()), 0600)
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// tsPropName quotes property names that are not valid identifiers.
+func tsPropName(name string) string {
+	if token.IsIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
 }
 
-func mapGoTypeToTS(expr ast.Expr) string {
+// fieldType returns the TypeScript type of f.
+func (d tsDecls) fieldType(f tsField) string {
+	if f.expr == nil {
+		return "unknown"
+	}
+	return mapGoTypeToTS(f.expr, d)
+}
+
+// refs returns the names of the structs in d that expr refers to.
+func (d tsDecls) refs(expr ast.Expr) []string {
+	if expr == nil {
+		return nil
+	}
+	var names []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		var name string
+		switch t := n.(type) {
+		case *ast.Ident:
+			name = t.Name
+		case *ast.SelectorExpr:
+			name = t.Sel.Name
+		default:
+			return true
+		}
+		if _, ok := d[name]; ok {
+			names = append(names, name)
+		}
+		return false
+	})
+	return names
+}
+
+// mapGoTypeToTS converts a Go type to TypeScript. Types from other packages
+// become the interface of the same name when decls has it, and any
+// otherwise.
+func mapGoTypeToTS(expr ast.Expr, decls tsDecls) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
 		switch t.Name {
@@ -117,11 +499,11 @@ func mapGoTypeToTS(expr ast.Expr) string {
 			return t.Name
 		}
 	case *ast.ArrayType:
-		return mapGoTypeToTS(t.Elt) + "[]"
+		return mapGoTypeToTS(t.Elt, decls) + "[]"
 	case *ast.MapType:
-		return "Record<string, " + mapGoTypeToTS(t.Value) + ">"
+		return "Record<string, " + mapGoTypeToTS(t.Value, decls) + ">"
 	case *ast.StarExpr:
-		return mapGoTypeToTS(t.X) + " | null"
+		return mapGoTypeToTS(t.X, decls) + " | null"
 	case *ast.SelectorExpr:
 		if x, ok := t.X.(*ast.Ident); ok && x.Name == "time" && t.Sel.Name == "Time" {
 			return "string" // Typically passed as ISO string
@@ -129,6 +511,12 @@ func mapGoTypeToTS(expr ast.Expr) string {
 		if x, ok := t.X.(*ast.Ident); ok && x.Name == "json" && t.Sel.Name == "RawMessage" {
 			return "any"
 		}
+		if x, ok := t.X.(*ast.Ident); ok && x.Name == "routing" && t.Sel.Name == "FileResult" {
+			return "Blob" // Downloaded, see remote() in the client runtime
+		}
+		if _, ok := decls[t.Sel.Name]; ok {
+			return t.Sel.Name
+		}
 		return "any"
 	default:
 		return "any"
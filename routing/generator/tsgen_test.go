@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTypeScriptDefinitions_AppTypes(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n",
+		"main.go": `package main
+
+import (
+	"context"
+
+	"example.com/app/models"
+	"github.com/aydenstechdungeon/gospa"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+type SearchInput struct {
+	Query string ` + "`json:\"q\"`" + `
+	Limit int    ` + "`json:\"-\"`" + `
+}
+
+func main() {
+	routing.RegisterRemoteActionTyped("search", func(ctx context.Context, rc routing.RemoteContext, in SearchInput) ([]models.User, error) {
+		return nil, nil
+	})
+	routing.RegisterRemoteActionTyped[string, int]("count", nil)
+	routing.RegisterRemoteActionTyped[SearchInput, *routing.FileResult]("export", nil)
+	routing.RegisterRemoteAction("legacy", nil)
+
+	gospa.New(gospa.Config{
+		DefaultState: map[string]interface{}{
+			"count":     0,
+			"theme":     "dark",
+			"loggedIn":  false,
+			"user":      &models.User{},
+			"tags":      []string{},
+			"ratio":     float32(0.5),
+			"dark-mode": nil,
+		},
+	})
+}
+`,
+		"models/user.go": `package models
+
+type User struct {
+	Name    string   ` + "`json:\"name\"`" + `
+	Address *Address ` + "`json:\"address\"`" + `
+}
+
+type Address struct {
+	City string
+}
+
+type Unused struct {
+	X int
+}
+`,
+		"routes/page.go": `package routes
+
+type PageProps struct {
+	Title string ` + "`json:\"title\"`" + `
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	routesDir := filepath.Join(root, "routes")
+	if err := GenerateTypeScriptDefinitions(routesDir); err != nil {
+		t.Fatalf("GenerateTypeScriptDefinitions: %v", err)
+	}
+	//nolint:gosec // routesDir is under t.TempDir().
+	content, err := os.ReadFile(filepath.Join(routesDir, "types.d.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(content)
+
+	for _, want := range []string{
+		"export interface PageProps {\n\ttitle: string;\n}",
+		"export interface User {\n\tname: string;\n\taddress: Address | null;\n}",
+		"export interface Address {\n\tCity: string;\n}",
+		"export interface SearchInput {\n\tq: string;\n}",
+		"export interface GospaState {\n\tcount: number;\n\t\"dark-mode\": unknown;\n\tloggedIn: boolean;\n\tratio: number;\n\ttags: string[];\n\ttheme: string;\n\tuser: User;\n}",
+		"export type StatePatch = Partial<GospaState>;",
+		"export interface WSMessage {",
+		`| { type: "patch"; patch: StatePatch;`,
+		`"count": { input: string; output: number };`,
+		`"export": { input: SearchInput; output: Blob | null };`,
+		`"legacy": { input: unknown; output: unknown };`,
+		`"search": { input: SearchInput; output: User[] };`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("types.d.ts missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Unused") {
+		t.Errorf("types.d.ts includes a struct no app type refers to\n%s", out)
+	}
+}
@@ -0,0 +1,62 @@
+package routing
+
+// Meta describes a page for navigation, search engines and the document
+// head. Register it next to the page with RegisterPageMeta, or with a
+// //gospa:meta comment in the page's .templ file for gospa generate.
+type Meta struct {
+	// Title names the page in NavTree, breadcrumbs and the document <title>.
+	// NavTree defaults it to the last path segment in title case, e.g.
+	// "Client Runtime" for /client-runtime.
+	Title string
+	// Description is the page's <meta name="description">.
+	Description string
+	// NavOrder sorts the page among its siblings in NavTree, ascending,
+	// with ties broken by title.
+	NavOrder int
+	// Hidden leaves the page out of NavTree and the sitemap. Pages below it
+	// are still listed.
+	Hidden bool
+}
+
+// RegisterPageMeta sets the metadata for a page path. Paths of sections
+// without a page of their own, such as /docs/getstarted, may be registered
+// to title them in NavTree.
+func (r *Registry) RegisterPageMeta(path string, meta Meta) {
+	r.metaMu.Lock()
+	defer r.metaMu.Unlock()
+	r.meta[normalizeNavPath(path)] = meta
+}
+
+// GetPageMeta returns the metadata registered for path.
+func (r *Registry) GetPageMeta(path string) (Meta, bool) {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+	meta, ok := r.meta[normalizeNavPath(path)]
+	return meta, ok
+}
+
+// GetAllPageMeta returns a copy of all registered metadata keyed by path.
+func (r *Registry) GetAllPageMeta() map[string]Meta {
+	r.metaMu.RLock()
+	defer r.metaMu.RUnlock()
+	out := make(map[string]Meta, len(r.meta))
+	for k, v := range r.meta {
+		out[k] = v
+	}
+	return out
+}
+
+// RegisterPageMeta sets page metadata in the global registry.
+func RegisterPageMeta(path string, meta Meta) {
+	globalRegistry.RegisterPageMeta(path, meta)
+}
+
+// GetPageMeta returns page metadata from the global registry.
+func GetPageMeta(path string) (Meta, bool) {
+	return globalRegistry.GetPageMeta(path)
+}
+
+// GetAllPageMeta returns all page metadata from the global registry.
+func GetAllPageMeta() map[string]Meta {
+	return globalRegistry.GetAllPageMeta()
+}
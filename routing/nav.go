@@ -0,0 +1,181 @@
+package routing
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NavNode is an entry in a navigation tree built from registered pages.
+type NavNode struct {
+	// Path is the URL path, e.g. "/docs/api".
+	Path string
+	// Title is Meta.Title or a title made from the last segment.
+	Title string
+	// Order is Meta.NavOrder.
+	Order int
+	// HasPage is false for sections that only group other pages, such as
+	// /docs/getstarted when only /docs/getstarted/installation exists.
+	HasPage bool
+	// Children are sorted by Order, then Title.
+	Children []*NavNode
+}
+
+// NavTree builds the navigation tree of the globally registered pages. The
+// root is "/". Dynamic routes and Hidden pages are left out.
+func NavTree() *NavNode {
+	return globalRegistry.NavTree()
+}
+
+// NavTree builds the navigation tree of the registry's pages.
+func (r *Registry) NavTree() *NavNode {
+	r.pagesMu.RLock()
+	paths := make([]string, 0, len(r.pages))
+	for path := range r.pages {
+		paths = append(paths, path)
+	}
+	r.pagesMu.RUnlock()
+	return buildNavTree(paths, r.GetAllPageMeta())
+}
+
+func buildNavTree(pages []string, meta map[string]Meta) *NavNode {
+	root := &NavNode{Path: "/", Title: "Home"}
+	for _, path := range pages {
+		if isDynamicPath(path) {
+			continue
+		}
+		node := root
+		if path != "/" {
+			for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+				node = node.child(seg)
+			}
+		}
+		if !meta[normalizeNavPath(path)].Hidden {
+			node.HasPage = true
+		}
+	}
+	root.prune()
+	root.applyMeta(meta)
+	root.sort()
+	return root
+}
+
+// applyMeta sets titles and order from meta, for sections as well as pages.
+func (n *NavNode) applyMeta(meta map[string]Meta) {
+	if m, ok := meta[n.Path]; ok {
+		if m.Title != "" {
+			n.Title = m.Title
+		}
+		n.Order = m.NavOrder
+	}
+	for _, c := range n.Children {
+		c.applyMeta(meta)
+	}
+}
+
+func (n *NavNode) child(seg string) *NavNode {
+	path := strings.TrimSuffix(n.Path, "/") + "/" + seg
+	for _, c := range n.Children {
+		if c.Path == path {
+			return c
+		}
+	}
+	c := &NavNode{Path: path, Title: segmentTitle(seg)}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// prune drops sections left without pages, e.g. above a hidden page.
+func (n *NavNode) prune() bool {
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		if c.prune() {
+			kept = append(kept, c)
+		}
+	}
+	n.Children = kept
+	return n.HasPage || len(n.Children) > 0
+}
+
+func (n *NavNode) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.Title < b.Title
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// Find returns the node for path, or nil.
+func (n *NavNode) Find(path string) *NavNode {
+	if crumbs := n.Breadcrumbs(path); len(crumbs) > 0 && crumbs[len(crumbs)-1].Path == normalizeNavPath(path) {
+		return crumbs[len(crumbs)-1]
+	}
+	return nil
+}
+
+// Breadcrumbs returns the nodes from n down to path. For a path that is not
+// in the tree, such as a dynamic page, it stops at the deepest ancestor.
+func (n *NavNode) Breadcrumbs(path string) []*NavNode {
+	path = normalizeNavPath(path)
+	if !navContains(n.Path, path) {
+		return nil
+	}
+	crumbs := []*NavNode{n}
+	for node := n; ; {
+		var next *NavNode
+		for _, c := range node.Children {
+			if navContains(c.Path, path) {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return crumbs
+		}
+		crumbs = append(crumbs, next)
+		node = next
+	}
+}
+
+// Contains reports whether path is n's page or one below it, for marking
+// the open section of a menu.
+func (n *NavNode) Contains(path string) bool {
+	return navContains(n.Path, normalizeNavPath(path))
+}
+
+func navContains(base, path string) bool {
+	return base == "/" || path == base || strings.HasPrefix(path, base+"/")
+}
+
+func normalizeNavPath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	if path == "" || path == "/" {
+		return "/"
+	}
+	return "/" + strings.Trim(path, "/")
+}
+
+func isDynamicPath(path string) bool {
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") || strings.HasPrefix(seg, "[") {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentTitle turns "client-runtime" into "Client Runtime".
+func segmentTitle(seg string) string {
+	words := strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
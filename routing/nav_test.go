@@ -0,0 +1,86 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func navRegistry() *Registry {
+	reg := NewRegistry()
+	page := func(_ map[string]interface{}) templ.Component { return stubComponent() }
+	reg.RegisterPage("/", page)
+	reg.RegisterPage("/docs", page)
+	reg.RegisterPageMeta("/docs", Meta{Title: "Documentation"})
+	reg.RegisterPage("/docs/api", page)
+	reg.RegisterPageMeta("/docs/api", Meta{NavOrder: 2})
+	reg.RegisterPage("/docs/client-runtime", page)
+	reg.RegisterPageMeta("/docs/client-runtime/", Meta{NavOrder: 1})
+	reg.RegisterPage("/docs/getstarted/installation", page)
+	reg.RegisterPage("/blog/:slug", page)
+	reg.RegisterPage("/admin/users", page)
+	reg.RegisterPageMeta("/admin/users", Meta{Hidden: true})
+	return reg
+}
+
+func navPaths(nodes []*NavNode) string {
+	paths := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		paths = append(paths, n.Path)
+	}
+	return strings.Join(paths, " ")
+}
+
+func TestNavTree(t *testing.T) {
+	tree := navRegistry().NavTree()
+
+	if tree.Path != "/" || !tree.HasPage || navPaths(tree.Children) != "/docs" {
+		t.Fatalf("root = %+v, children %q", tree, navPaths(tree.Children))
+	}
+	docs := tree.Find("/docs")
+	if docs.Title != "Documentation" {
+		t.Fatalf("docs title = %q", docs.Title)
+	}
+	// Ordered pages first, then the unordered section.
+	if got := navPaths(docs.Children); got != "/docs/getstarted /docs/client-runtime /docs/api" {
+		t.Fatalf("docs children = %q", got)
+	}
+	getstarted := docs.Children[0]
+	if getstarted.HasPage || getstarted.Title != "Getstarted" || navPaths(getstarted.Children) != "/docs/getstarted/installation" {
+		t.Fatalf("getstarted = %+v", getstarted)
+	}
+	if rt := tree.Find("/docs/client-runtime/"); rt == nil || rt.Title != "Client Runtime" {
+		t.Fatalf("client-runtime = %+v", rt)
+	}
+	if tree.Find("/admin") != nil || tree.Find("/blog") != nil {
+		t.Fatal("hidden and dynamic pages must not be in the tree")
+	}
+}
+
+func TestNavTreeBreadcrumbs(t *testing.T) {
+	tree := navRegistry().NavTree()
+
+	if got := navPaths(tree.Breadcrumbs("/docs/getstarted/installation?x=1")); got != "/ /docs /docs/getstarted /docs/getstarted/installation" {
+		t.Fatalf("breadcrumbs = %q", got)
+	}
+	if got := navPaths(tree.Breadcrumbs("/docs/api/unknown")); got != "/ /docs /docs/api" {
+		t.Fatalf("breadcrumbs for unknown page = %q", got)
+	}
+	if got := tree.Find("/docs").Breadcrumbs("/blog"); got != nil {
+		t.Fatalf("breadcrumbs outside subtree = %q", navPaths(got))
+	}
+}
+
+func TestNavTreeSectionMeta(t *testing.T) {
+	reg := navRegistry()
+	reg.RegisterPageMeta("/docs/getstarted", Meta{Title: "Getting Started", NavOrder: 3})
+
+	docs := reg.NavTree().Find("/docs")
+	if got := navPaths(docs.Children); got != "/docs/client-runtime /docs/api /docs/getstarted" {
+		t.Fatalf("docs children = %q", got)
+	}
+	if gs := docs.Children[2]; gs.Title != "Getting Started" || gs.HasPage {
+		t.Fatalf("getstarted = %+v", gs)
+	}
+}
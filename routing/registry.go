@@ -3,6 +3,7 @@ package routing
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/a-h/templ"
@@ -35,6 +36,10 @@ const (
 	StrategyPPR RenderStrategy = "ppr"
 )
 
+// RuntimeNone is the RouteOptions.Runtime value that serves a page without
+// the client runtime.
+const RuntimeNone = "none"
+
 // RouteOptions holds page-level options like rendering strategy.
 type RouteOptions struct {
 	Strategy RenderStrategy
@@ -44,6 +49,11 @@ type RouteOptions struct {
 	// goroutine re-renders and updates the cache (stale-while-revalidate).
 	// Zero means "always revalidate" which behaves identically to SSR.
 	RevalidateAfter time.Duration
+	// ISR: cron expression (minute hour day-of-month month day-of-week, local
+	// time) on which the page is re-rendered proactively, so requests after a
+	// scheduled update never see a stale page, e.g. "0 * * * *" for hourly.
+	// Works alongside RevalidateAfter; see jobs.ParseCron for the syntax.
+	RevalidateCron string
 
 	// PPR: names of dynamic slots that are excluded from the cached static shell
 	// and re-rendered per-request. Each name must match a slot registered with
@@ -54,11 +64,66 @@ type RouteOptions struct {
 
 	// RuntimeTier specifies the minimum client runtime tier required for this route.
 	RuntimeTier string
+	// Runtime selects the client runtime for this route outright: "full",
+	// "core", "micro", or RuntimeNone for pages that need no JavaScript,
+	// such as static marketing pages. Unlike RuntimeTier it ignores
+	// Config.RuntimeTier and the layouts' tiers. Empty resolves the tier as
+	// usual.
+	Runtime string
+
+	// NoIndex keeps the page out of the sitemap served for Config.SEO and
+	// sends X-Robots-Tag: noindex with it.
+	NoIndex bool
+	// NoFollow asks crawlers not to follow the page's links. Like NoIndex it
+	// is sent in X-Robots-Tag and the root layout's "robots" prop.
+	NoFollow bool
+
+	// CacheControl replaces the Cache-Control header the strategy would send
+	// on successful page and __data responses, e.g.
+	// "public, s-maxage=60, stale-while-revalidate=300" to let a CDN cache an
+	// SSR page.
+	CacheControl string
+	// SurrogateControl sets the Surrogate-Control header, which CDNs such as
+	// Fastly honor and strip before the response reaches the browser.
+	SurrogateControl string
+	// SurrogateKeys are extra purge keys for the Surrogate-Key header. When
+	// SurrogateControl or SurrogateKeys is set, the header also carries the
+	// route's cache tags, so CDN purges can use the names InvalidateTag uses.
+	SurrogateKeys []string
+
+	// Variants keeps separate SSG, ISR and PPR cache entries per
+	// VariantLocale and/or VariantDevice, and sends the matching Vary
+	// headers.
+	Variants []string
 
 	// Optional per-route rate limiter config.
 	RateLimit *RateLimitOptions
 }
 
+// RouteOptions.Variants values.
+const (
+	// VariantLocale varies a page by the locale negotiated from
+	// Accept-Language among the app's Config.Locales.
+	VariantLocale = "locale"
+	// VariantDevice varies a page by device class, "mobile" or "desktop",
+	// from the Sec-CH-UA-Mobile client hint or else the User-Agent.
+	VariantDevice = "device"
+)
+
+// Robots returns the robots directives of NoIndex and NoFollow, e.g.
+// "noindex, nofollow", or "" when the page may be indexed and followed.
+func (o RouteOptions) Robots() string {
+	switch {
+	case o.NoIndex && o.NoFollow:
+		return "noindex, nofollow"
+	case o.NoIndex:
+		return "noindex"
+	case o.NoFollow:
+		return "nofollow"
+	}
+	return ""
+}
+
 // RateLimitOptions holds configuration for per-route rate limiters.
 type RateLimitOptions struct {
 	MaxRequests int
@@ -89,6 +154,10 @@ type LoadContext interface {
 // LoadFunc is a function that returns data for a page or layout.
 type LoadFunc func(c LoadContext) (map[string]interface{}, error)
 
+// LayoutLoaderFunc returns one named value for a layout's props, such as the
+// current user or the navigation items.
+type LayoutLoaderFunc func(c LoadContext) (interface{}, error)
+
 // ActionFunc is a function that handles a form action.
 type ActionFunc func(c LoadContext) (interface{}, error)
 
@@ -127,6 +196,9 @@ type Registry struct {
 
 	layoutsMu sync.RWMutex
 	layouts   map[string]LayoutFunc
+	// layoutsVersion changes whenever a layout is registered, so routers can
+	// tell when their precomputed layout chains are out of date.
+	layoutsVersion atomic.Uint64
 
 	errorsMu sync.RWMutex
 	errors   map[string]ComponentFunc
@@ -145,6 +217,8 @@ type Registry struct {
 
 	layoutLoaderMu sync.RWMutex
 	layoutLoader   map[string]LoadFunc
+	// layoutLoaders maps layoutPath → name → LayoutLoaderFunc.
+	layoutLoaders map[string]map[string]LayoutLoaderFunc
 
 	actionsMu sync.RWMutex
 	actions   map[string]map[string]ActionFunc
@@ -159,6 +233,10 @@ type Registry struct {
 	layoutTiersMu sync.RWMutex
 	// layoutTiers maps layoutPath → RuntimeTier
 	layoutTiers map[string]string
+
+	metaMu sync.RWMutex
+	// meta maps a page or section path to its Meta.
+	meta map[string]Meta
 }
 
 // globalRegistry is the default global registry.
@@ -167,18 +245,20 @@ var globalRegistry = NewRegistry()
 // NewRegistry creates a new component registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		pages:        make(map[string]ComponentFunc),
-		pageOptions:  make(map[string]RouteOptions),
-		layouts:      make(map[string]LayoutFunc),
-		errors:       make(map[string]ComponentFunc),
-		middlewares:  make(map[string]MiddlewareFunc),
-		loadings:     make(map[string]ComponentFunc),
-		loadFuncs:    make(map[string]LoadFunc),
-		layoutLoader: make(map[string]LoadFunc),
-		actions:      make(map[string]map[string]ActionFunc),
-		hooks:        make([]HookFunc, 0),
-		slots:        make(map[string]map[string]SlotFunc),
-		layoutTiers:  make(map[string]string),
+		pages:         make(map[string]ComponentFunc),
+		pageOptions:   make(map[string]RouteOptions),
+		layouts:       make(map[string]LayoutFunc),
+		errors:        make(map[string]ComponentFunc),
+		middlewares:   make(map[string]MiddlewareFunc),
+		loadings:      make(map[string]ComponentFunc),
+		loadFuncs:     make(map[string]LoadFunc),
+		layoutLoader:  make(map[string]LoadFunc),
+		layoutLoaders: make(map[string]map[string]LayoutLoaderFunc),
+		actions:       make(map[string]map[string]ActionFunc),
+		hooks:         make([]HookFunc, 0),
+		slots:         make(map[string]map[string]SlotFunc),
+		layoutTiers:   make(map[string]string),
+		meta:          make(map[string]Meta),
 	}
 }
 
@@ -290,6 +370,37 @@ func (r *Registry) GetLayoutLoad(path string) LoadFunc {
 	return r.layoutLoader[path]
 }
 
+// RegisterLayoutLoader registers a named loader for a layout path. Its result
+// is added to the props under name. Loaders registered under the same name
+// run once per request, however many layouts in the chain use them, so name
+// must identify the data rather than the layout. A nil fn removes the loader.
+func (r *Registry) RegisterLayoutLoader(path, name string, fn LayoutLoaderFunc) {
+	r.layoutLoaderMu.Lock()
+	defer r.layoutLoaderMu.Unlock()
+	if fn == nil {
+		delete(r.layoutLoaders[path], name)
+		return
+	}
+	if r.layoutLoaders[path] == nil {
+		r.layoutLoaders[path] = make(map[string]LayoutLoaderFunc)
+	}
+	r.layoutLoaders[path][name] = fn
+}
+
+// GetLayoutLoaders returns the named loaders of a layout path.
+func (r *Registry) GetLayoutLoaders(path string) map[string]LayoutLoaderFunc {
+	r.layoutLoaderMu.RLock()
+	defer r.layoutLoaderMu.RUnlock()
+	if len(r.layoutLoaders[path]) == 0 {
+		return nil
+	}
+	out := make(map[string]LayoutLoaderFunc, len(r.layoutLoaders[path]))
+	for name, fn := range r.layoutLoaders[path] {
+		out[name] = fn
+	}
+	return out
+}
+
 // RegisterPage registers a page component for a route path (default to SSR).
 func (r *Registry) RegisterPage(path string, fn ComponentFunc) {
 	r.RegisterPageWithOptions(path, fn, RouteOptions{Strategy: StrategySSR})
@@ -337,6 +448,7 @@ func (r *Registry) RegisterLayoutWithOptions(path string, fn LayoutFunc, tier st
 	defer r.layoutTiersMu.Unlock()
 	r.layouts[path] = fn
 	r.layoutTiers[path] = tier
+	r.layoutsVersion.Add(1)
 }
 
 // GetLayoutTier returns the runtime tier for a layout path.
@@ -582,6 +694,16 @@ func GetLayoutLoad(path string) LoadFunc {
 	return globalRegistry.GetLayoutLoad(path)
 }
 
+// RegisterLayoutLoader registers a named layout loader in the global registry.
+func RegisterLayoutLoader(path, name string, fn LayoutLoaderFunc) {
+	globalRegistry.RegisterLayoutLoader(path, name, fn)
+}
+
+// GetLayoutLoaders returns the named loaders of a layout from the global registry.
+func GetLayoutLoaders(path string) map[string]LayoutLoaderFunc {
+	return globalRegistry.GetLayoutLoaders(path)
+}
+
 // GetGlobalRegistry returns the global registry.
 func GetGlobalRegistry() *Registry {
 	return globalRegistry
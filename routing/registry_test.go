@@ -316,3 +316,20 @@ func TestRenderStrategyConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteOptionsRobots(t *testing.T) {
+	tests := []struct {
+		opts RouteOptions
+		want string
+	}{
+		{RouteOptions{}, ""},
+		{RouteOptions{NoIndex: true}, "noindex"},
+		{RouteOptions{NoFollow: true}, "nofollow"},
+		{RouteOptions{NoIndex: true, NoFollow: true}, "noindex, nofollow"},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Robots(); got != tt.want {
+			t.Errorf("Robots() of %+v = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
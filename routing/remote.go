@@ -2,7 +2,9 @@ package routing
 
 import (
 	"context"
+	"io"
 	"sync"
+	"time"
 )
 
 // RemoteContext provides HTTP request details to a remote action.
@@ -19,21 +21,62 @@ type RemoteContext struct {
 // RemoteActionFunc is a type-safe server function that can be called remotely from the client.
 type RemoteActionFunc func(ctx context.Context, rc RemoteContext, input interface{}) (interface{}, error)
 
+// FileResult is a remote action result sent as a file download instead of
+// JSON, such as a CSV or PDF export. Return it, or a pointer to it, from the
+// action; the handler streams Reader to the client and closes it afterwards
+// if it is an io.Closer. File results are never cached or replayed.
+type FileResult struct {
+	// Name is the file name the browser saves the download as.
+	Name string
+	// ContentType defaults to the type of Name's extension, or
+	// application/octet-stream.
+	ContentType string
+	Reader      io.Reader
+}
+
+// RemoteActionOptions controls how results of a remote action are reused.
+// Results are kept in the app's Storage, per caller (session or client IP).
+type RemoteActionOptions struct {
+	// CacheTTL returns the cached result for calls with identical input made
+	// within this window instead of running the action again. Zero disables
+	// caching.
+	CacheTTL time.Duration
+	// IdempotencyTTL is how long a result is replayed for retries sending the
+	// same Idempotency-Key header (default 24h).
+	IdempotencyTTL time.Duration
+}
+
 // RemoteRegistry is a registry for remote actions.
 type RemoteRegistry struct {
 	mu      sync.RWMutex
 	actions map[string]RemoteActionFunc
+	options map[string]RemoteActionOptions
 }
 
 var globalRemoteRegistry = &RemoteRegistry{
 	actions: make(map[string]RemoteActionFunc),
+	options: make(map[string]RemoteActionOptions),
 }
 
 // RegisterRemoteAction registers a remote server function.
 func RegisterRemoteAction(name string, action RemoteActionFunc) {
+	RegisterRemoteActionWithOptions(name, action, RemoteActionOptions{})
+}
+
+// RegisterRemoteActionWithOptions registers a remote server function with
+// result caching and idempotency options.
+func RegisterRemoteActionWithOptions(name string, action RemoteActionFunc, opts RemoteActionOptions) {
 	globalRemoteRegistry.mu.Lock()
 	defer globalRemoteRegistry.mu.Unlock()
 	globalRemoteRegistry.actions[name] = action
+	globalRemoteRegistry.options[name] = opts
+}
+
+// GetRemoteActionOptions returns the options an action was registered with.
+func GetRemoteActionOptions(name string) RemoteActionOptions {
+	globalRemoteRegistry.mu.RLock()
+	defer globalRemoteRegistry.mu.RUnlock()
+	return globalRemoteRegistry.options[name]
 }
 
 // GetRemoteAction retrieves a registered remote server function.
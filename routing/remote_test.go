@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestRegisterRemoteAction(t *testing.T) {
@@ -240,3 +241,19 @@ func TestRemoteActionConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestRegisterRemoteActionWithOptions(t *testing.T) {
+	action := func(_ context.Context, _ RemoteContext, _ interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	RegisterRemoteActionWithOptions("cachedAction", action, RemoteActionOptions{CacheTTL: time.Minute})
+	if got := GetRemoteActionOptions("cachedAction"); got.CacheTTL != time.Minute {
+		t.Fatalf("CacheTTL = %v, want 1m", got.CacheTTL)
+	}
+
+	// Re-registering without options clears them.
+	RegisterRemoteAction("cachedAction", action)
+	if got := GetRemoteActionOptions("cachedAction"); got != (RemoteActionOptions{}) {
+		t.Fatalf("options = %+v, want zero", got)
+	}
+}
@@ -42,3 +42,85 @@ func BenchmarkRouterMatch_Dynamic(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkRouterMatch_TableSize matches the same path against route tables
+// of increasing size. With the segment trie the cost stays flat as the table
+// grows.
+func BenchmarkRouterMatch_TableSize(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		b.Run(fmt.Sprintf("routes=%d", n), func(b *testing.B) {
+			paths := []string{"page.templ"}
+			for i := 0; i < n; i++ {
+				paths = append(paths, fmt.Sprintf("section%d/[id]/page.templ", i))
+			}
+			r := NewRouter(makeFS(paths...))
+			if err := r.Scan(); err != nil {
+				b.Fatal(err)
+			}
+			target := fmt.Sprintf("/section%d/42", n-1)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if route, _ := r.Match(target); route == nil {
+					b.Fatalf("no match for %s", target)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRouterMatch_PathLength matches paths of increasing depth against a
+// fixed table; the cost grows with the number of segments only.
+func BenchmarkRouterMatch_PathLength(b *testing.B) {
+	for _, depth := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("segments=%d", depth*2), func(b *testing.B) {
+			paths := []string{"page.templ"}
+			for i := 0; i < 500; i++ {
+				paths = append(paths, fmt.Sprintf("noise%d/[id]/page.templ", i))
+			}
+			dir, target := "", ""
+			for d := 0; d < depth; d++ {
+				dir += fmt.Sprintf("level%d/[p%d]/", d, d)
+				target += fmt.Sprintf("/level%d/%d", d, d)
+			}
+			paths = append(paths, dir+"page.templ")
+			r := NewRouter(makeFS(paths...))
+			if err := r.Scan(); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if route, _ := r.Match(target); route == nil {
+					b.Fatalf("no match for %s", target)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkResolveLayoutChain(b *testing.B) {
+	r := NewRouter(makeFS(
+		"layout.templ",
+		"app/layout.templ",
+		"app/[team]/layout.templ",
+		"app/[team]/projects/[id]/page.templ",
+	))
+	if err := r.Scan(); err != nil {
+		b.Fatal(err)
+	}
+	route, _ := r.Match("/app/acme/projects/7")
+	if route == nil {
+		b.Fatal("no match")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if chain := r.ResolveLayoutChain(route); len(chain) != 3 {
+			b.Fatalf("unexpected chain: %v", chain)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package routing
 import (
 	"testing"
 	"testing/fstest"
+
+	"github.com/a-h/templ"
 )
 
 // makeFS creates an in-memory filesystem with the given set of file paths.
@@ -415,6 +417,35 @@ func TestResolveLayoutChain_NilRoute(t *testing.T) {
 	}
 }
 
+func TestRouterScan_BasePath(t *testing.T) {
+	fs := makeFS(
+		"layout.templ",
+		"page.templ",
+		"cart/page.templ",
+		"products/[id]/page.templ",
+	)
+	r := NewRouter(fs)
+	r.SetBasePath("/shop/")
+	if err := r.Scan(); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	for _, path := range []string{"/shop", "/shop/cart"} {
+		if route, _ := r.Match(path); route == nil || route.Path != path {
+			t.Errorf("Match(%q) = %v", path, route)
+		}
+	}
+	route, params := r.Match("/shop/products/42")
+	if route == nil || route.Path != "/shop/products/:id" || params["id"] != "42" {
+		t.Fatalf("Match(/shop/products/42) = %v, %v", route, params)
+	}
+	if chain := r.ResolveLayoutChain(route); len(chain) != 1 || chain[0].Path != "/shop" {
+		t.Errorf("layout chain = %v, want the /shop layout", chain)
+	}
+	if route, _ := r.Match("/cart"); route != nil {
+		t.Errorf("Match(/cart) = %v, want no match outside the base path", route)
+	}
+}
+
 // ─── matchRoute edge cases ────────────────────────────────────────────────────
 
 func TestMatchRoute_OptionalSegment(t *testing.T) {
@@ -662,3 +693,106 @@ func TestNewRouter_WithUnknownType(t *testing.T) {
 		t.Fatal("NewRouter with unknown type should not return nil (uses fallback)")
 	}
 }
+
+// ─── Dynamic route trie ────────────────────────────────────────────────────────
+
+// linearMatch is the reference matcher: the first dynamic route in priority
+// order whose segments match.
+func linearMatch(r *Router, urlPath string) (*Route, map[string]string) {
+	pathSegs := splitPathSegments(urlPath)
+	for _, route := range r.dynamicRoutes {
+		if params, ok := matchRouteSegments(route.matchSegments, pathSegs); ok {
+			return route, params
+		}
+	}
+	return nil, nil
+}
+
+func TestMatch_TrieAgreesWithLinearScan(t *testing.T) {
+	r := NewRouter(makeFS(
+		"page.templ",
+		"blog/page.templ",
+		"blog/[slug]/page.templ",
+		"blog/[slug]/edit/page.templ",
+		"blog/[year]/[month]/page.templ",
+		"users/[id]/page.templ",
+		"users/[id]/posts/[[page]]/page.templ",
+		"users/[name]/settings/page.templ",
+		"docs/[...path]/page.templ",
+		"docs/[...path]/edit/page.templ",
+		"files/[[...rest]]/page.templ",
+		"[lang]/about/page.templ",
+		"[lang]/[...rest]/page.templ",
+	))
+	if err := r.Scan(); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+
+	paths := []string{
+		"/blog/hello", "/blog/hello/edit", "/blog/2024/05", "/blog/2024/05/extra",
+		"/users/7", "/users/7/posts", "/users/7/posts/3", "/users/ada/settings",
+		"/docs/a", "/docs/a/b/c", "/docs/a/b/edit", "/docs",
+		"/files", "/files/x", "/files/x/y/z",
+		"/en/about", "/en/anything/else", "/nope", "/",
+	}
+	for _, p := range paths {
+		want, wantParams := linearMatch(r, p)
+		if static, ok := r.staticPageIndex[p]; ok {
+			want, wantParams = static, map[string]string{}
+		}
+		got, gotParams := r.Match(p)
+		if got != want {
+			t.Errorf("Match(%q) = %v, want %v", p, got, want)
+			continue
+		}
+		if len(gotParams) != len(wantParams) {
+			t.Errorf("Match(%q) params = %v, want %v", p, gotParams, wantParams)
+			continue
+		}
+		for k, v := range wantParams {
+			if gotParams[k] != v {
+				t.Errorf("Match(%q) params = %v, want %v", p, gotParams, wantParams)
+				break
+			}
+		}
+	}
+}
+
+func TestResolveLayoutChain_Precomputed(t *testing.T) {
+	r := NewRouter(makeFS(
+		"layout.templ",
+		"shop/layout.templ",
+		"shop/[item]/page.templ",
+	))
+	if err := r.Scan(); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	route, _ := r.Match("/shop/hat")
+	first := r.ResolveLayoutChain(route)
+	if len(first) != 2 || first[0].Path != "/" || first[1].Path != "/shop" {
+		t.Fatalf("unexpected chain: %v", first)
+	}
+	if second := r.ResolveLayoutChain(route); &second[0] != &first[0] {
+		t.Error("expected the precomputed chain to be reused")
+	}
+}
+
+func TestResolveLayoutChain_LayoutRegisteredAfterScan(t *testing.T) {
+	const dir = "late_layout_9c2e"
+	r := NewRouter(makeFS(dir + "/[id]/page.templ"))
+	if err := r.Scan(); err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	route, _ := r.Match("/" + dir + "/1")
+	if chain := r.ResolveLayoutChain(route); len(chain) != 0 && chain[len(chain)-1].Path == "/"+dir {
+		t.Fatalf("unexpected layout before registration: %v", chain)
+	}
+
+	RegisterLayout("/"+dir, func(_ templ.Component, _ map[string]interface{}) templ.Component {
+		return stubComponent()
+	})
+	chain := r.ResolveLayoutChain(route)
+	if len(chain) == 0 || chain[len(chain)-1].Path != "/"+dir {
+		t.Fatalf("expected registry layout /%s at the end of the chain, got %v", dir, chain)
+	}
+}
@@ -0,0 +1,110 @@
+package routing
+
+import "math"
+
+// routeTrie indexes dynamic page routes by path segment. Matching walks the
+// request path once instead of trying every dynamic route in turn, so its cost
+// depends on the path length rather than the size of the route table.
+//
+// The trie only decides which route matches; parameters are then extracted
+// from the winning route's own segments, so param names and catch-all
+// semantics are exactly those of matchRouteSegments.
+type routeTrie struct {
+	root *trieNode
+}
+
+// trieNode is one path segment position. Static children are keyed by
+// segment value; dynamic children are keyed by segment kind, since different
+// routes may name the same parameter position differently.
+type trieNode struct {
+	static  map[string]*trieNode
+	dynamic [segmentOptionalCatchAll + 1]*trieNode
+	// rank is the position in the router's priority order of the best route
+	// ending here, or -1 if no route ends here.
+	rank int
+	// minRank is the best rank anywhere in this subtree, used for pruning.
+	minRank int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{rank: -1, minRank: math.MaxInt}
+}
+
+// newRouteTrie builds a trie from routes, which must be in match priority
+// order: when several routes match a path the earliest one wins.
+func newRouteTrie(routes []*Route) *routeTrie {
+	t := &routeTrie{root: newTrieNode()}
+	for rank, route := range routes {
+		t.insert(route.matchSegments, rank)
+	}
+	return t
+}
+
+func (t *routeTrie) insert(segments []routeSegment, rank int) {
+	n := t.root
+	n.minRank = min(n.minRank, rank)
+	for _, seg := range segments {
+		var child *trieNode
+		if seg.kind == segmentStatic {
+			if n.static == nil {
+				n.static = make(map[string]*trieNode)
+			}
+			child = n.static[seg.value]
+			if child == nil {
+				child = newTrieNode()
+				n.static[seg.value] = child
+			}
+		} else {
+			child = n.dynamic[seg.kind]
+			if child == nil {
+				child = newTrieNode()
+				n.dynamic[seg.kind] = child
+			}
+		}
+		child.minRank = min(child.minRank, rank)
+		n = child
+	}
+	if n.rank < 0 || rank < n.rank {
+		n.rank = rank
+	}
+}
+
+// match returns the rank of the best route matching pathSegs, or -1.
+func (t *routeTrie) match(pathSegs []string) int {
+	best := math.MaxInt
+	t.root.search(pathSegs, &best)
+	if best == math.MaxInt {
+		return -1
+	}
+	return best
+}
+
+func (n *trieNode) search(segs []string, best *int) {
+	if n == nil || n.minRank >= *best {
+		return
+	}
+	if len(segs) == 0 && n.rank >= 0 && n.rank < *best {
+		*best = n.rank
+	}
+
+	if len(segs) > 0 {
+		if child, ok := n.static[segs[0]]; ok {
+			child.search(segs[1:], best)
+		}
+		n.dynamic[segmentParam].search(segs[1:], best)
+		n.dynamic[segmentOptionalParam].search(segs[1:], best)
+	}
+	// An optional param may also match nothing.
+	n.dynamic[segmentOptionalParam].search(segs, best)
+
+	if child := n.dynamic[segmentCatchAll]; child != nil {
+		for k := 1; k <= len(segs); k++ {
+			child.search(segs[k:], best)
+		}
+	}
+	if child := n.dynamic[segmentOptionalCatchAll]; child != nil {
+		for k := 0; k <= len(segs); k++ {
+			child.search(segs[k:], best)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError reports invalid remote action input. The remote endpoint
+// answers it with status 422 and code VALIDATION_FAILED, and the client
+// runtime maps FieldErrors onto form fields by name.
+type ValidationError struct {
+	// FieldErrors maps JSON field paths ("email", "address.city",
+	// "items.0.name") to messages.
+	FieldErrors map[string]string `json:"fieldErrors,omitempty"`
+	FormError   string            `json:"formError,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.FormError != "" {
+		return "validation failed: " + e.FormError
+	}
+	fields := make([]string, 0, len(e.FieldErrors))
+	for f := range e.FieldErrors {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// Validator checks decoded remote action input. Returning a *ValidationError
+// reports field errors to the client; any other error fails the call.
+type Validator interface {
+	Validate(v interface{}) error
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc func(v interface{}) error
+
+// Validate calls f(v).
+func (f ValidatorFunc) Validate(v interface{}) error {
+	return f(v)
+}
+
+var (
+	validatorMu sync.RWMutex
+	validator   Validator = TagValidator{}
+)
+
+// SetValidator replaces the validator used by typed remote actions, e.g. to
+// adapt go-playground/validator. Passing nil restores TagValidator.
+func SetValidator(v Validator) {
+	validatorMu.Lock()
+	defer validatorMu.Unlock()
+	if v == nil {
+		v = TagValidator{}
+	}
+	validator = v
+}
+
+func currentValidator() Validator {
+	validatorMu.RLock()
+	defer validatorMu.RUnlock()
+	return validator
+}
+
+// RegisterRemoteActionTyped registers a remote action whose input is decoded
+// into In and checked by the validator before fn runs.
+func RegisterRemoteActionTyped[In, Out any](name string, fn func(ctx context.Context, rc RemoteContext, in In) (Out, error)) {
+	RegisterRemoteActionTypedWithOptions(name, fn, RemoteActionOptions{})
+}
+
+// RegisterRemoteActionTypedWithOptions is RegisterRemoteActionTyped with
+// result caching and idempotency options.
+func RegisterRemoteActionTypedWithOptions[In, Out any](name string, fn func(ctx context.Context, rc RemoteContext, in In) (Out, error), opts RemoteActionOptions) {
+	RegisterRemoteActionWithOptions(name, func(ctx context.Context, rc RemoteContext, input interface{}) (interface{}, error) {
+		var in In
+		if input != nil {
+			if err := decodeTyped(input, &in); err != nil {
+				return nil, err
+			}
+		}
+		if err := currentValidator().Validate(&in); err != nil {
+			return nil, err
+		}
+		return fn(ctx, rc, in)
+	}, opts)
+}
+
+// decodeTyped converts the generic JSON input into out, reporting type
+// mismatches as field errors. It uses encoding/json because its type errors
+// carry the JSON field path.
+func decodeTyped(input, out interface{}) error {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &ValidationError{FieldErrors: map[string]string{
+				typeErr.Field: "must be " + article(typeErr.Type.Kind().String()),
+			}}
+		}
+		return &ValidationError{FormError: "invalid input"}
+	}
+	return nil
+}
+
+func article(kind string) string {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "a number"
+	case "bool":
+		return "true or false"
+	case "slice", "array":
+		return "a list"
+	case "struct", "map":
+		return "an object"
+	}
+	return "a " + kind
+}
+
+// TagValidator validates structs from `validate` struct tags. Rules are
+// comma-separated:
+//
+//	required      non-zero value
+//	omitempty     skip the remaining rules when the value is zero
+//	min=N, max=N  length for strings (in characters), slices and maps; value for numbers
+//	len=N         exact length
+//	oneof=a b c   one of the space-separated values
+//	email, url    string format
+//
+// Nested structs, pointers and slices of structs are validated recursively.
+// Field paths use JSON names.
+type TagValidator struct{}
+
+// Validate checks v, which is usually a pointer to a struct.
+func (TagValidator) Validate(v interface{}) error {
+	errs := make(map[string]string)
+	validateValue(reflect.ValueOf(v), "", errs)
+	if len(errs) > 0 {
+		return &ValidationError{FieldErrors: errs}
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, path string, errs map[string]string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name := jsonFieldName(sf)
+			if name == "-" {
+				continue
+			}
+			fieldPath := name
+			if sf.Anonymous && sf.Tag.Get("json") == "" {
+				fieldPath = ""
+			}
+			if path != "" && fieldPath != "" {
+				fieldPath = path + "." + fieldPath
+			} else if fieldPath == "" {
+				fieldPath = path
+			}
+			fv := v.Field(i)
+			if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+				if msg := checkRules(fv, tag); msg != "" {
+					errs[fieldPath] = msg
+					continue
+				}
+			}
+			validateValue(fv, fieldPath, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), path+"."+strconv.Itoa(i), errs)
+		}
+	}
+}
+
+func jsonFieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// checkRules returns the message for the first rule v fails, or "".
+func checkRules(v reflect.Value, tag string) string {
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "":
+		case "omitempty":
+			if v.IsZero() {
+				return ""
+			}
+		case "required":
+			if v.IsZero() {
+				return "is required"
+			}
+		case "min", "max", "len":
+			if msg := checkSize(v, key, arg); msg != "" {
+				return msg
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			got := fmt.Sprint(deref(v).Interface())
+			found := false
+			for _, o := range options {
+				if o == got {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return "must be one of: " + strings.Join(options, ", ")
+			}
+		case "email":
+			s, ok := stringOf(v)
+			if ok && s != "" {
+				if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+					return "must be a valid email address"
+				}
+			}
+		case "url":
+			s, ok := stringOf(v)
+			if ok && s != "" {
+				if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+					return "must be a valid URL"
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func stringOf(v reflect.Value) (string, bool) {
+	v = deref(v)
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func checkSize(v reflect.Value, key, arg string) string {
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return ""
+	}
+	v = deref(v)
+	var size float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		size, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		size, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return ""
+	}
+	switch {
+	case key == "min" && size < n:
+		return "must be at least " + arg + unit
+	case key == "max" && size > n:
+		return "must be at most " + arg + unit
+	case key == "len" && size != n:
+		return "must be exactly " + arg + unit
+	}
+	return ""
+}
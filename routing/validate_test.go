@@ -0,0 +1,89 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type order struct {
+	Email    string    `json:"email" validate:"required,email"`
+	Plan     string    `json:"plan" validate:"oneof=free pro"`
+	Note     string    `json:"note" validate:"omitempty,min=3"`
+	Website  string    `json:"website" validate:"url"`
+	Quantity int       `json:"qty" validate:"min=1,max=10"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Ship     *address  `json:"ship"`
+	Items    []address `json:"items"`
+}
+
+func TestTagValidator(t *testing.T) {
+	valid := order{Email: "a@b.co", Plan: "pro", Quantity: 2, Ship: &address{City: "Oslo"}}
+	if err := (TagValidator{}).Validate(&valid); err != nil {
+		t.Fatalf("valid order: %v", err)
+	}
+
+	bad := order{
+		Email:    "a@",
+		Plan:     "gold",
+		Note:     "hi",
+		Website:  "example.com",
+		Quantity: 11,
+		Tags:     []string{"a", "b", "c"},
+		Ship:     &address{},
+		Items:    []address{{City: "Rome"}, {}},
+	}
+	err := (TagValidator{}).Validate(&bad)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	want := map[string]string{
+		"email":        "must be a valid email address",
+		"plan":         "must be one of: free, pro",
+		"note":         "must be at least 3 characters",
+		"website":      "must be a valid URL",
+		"qty":          "must be at most 10",
+		"tags":         "must be at most 2 items",
+		"ship.city":    "is required",
+		"items.1.city": "is required",
+	}
+	if len(verr.FieldErrors) != len(want) {
+		t.Fatalf("field errors %v, want %v", verr.FieldErrors, want)
+	}
+	for field, msg := range want {
+		if verr.FieldErrors[field] != msg {
+			t.Errorf("%s: got %q, want %q", field, verr.FieldErrors[field], msg)
+		}
+	}
+}
+
+func TestRegisterRemoteActionTyped(t *testing.T) {
+	RegisterRemoteActionTyped("typedOrder", func(_ context.Context, _ RemoteContext, in order) (int, error) {
+		return in.Quantity * 2, nil
+	})
+	fn, _ := GetRemoteAction("typedOrder")
+
+	out, err := fn(context.Background(), RemoteContext{}, map[string]interface{}{"email": "a@b.co", "plan": "free", "qty": 3})
+	if err != nil || out != 6 {
+		t.Fatalf("got %v, %v; want 6", out, err)
+	}
+
+	_, err = fn(context.Background(), RemoteContext{}, map[string]interface{}{"email": "a@b.co", "plan": "free", "qty": "three"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.FieldErrors["qty"] != "must be a number" {
+		t.Fatalf("type mismatch err = %#v", err)
+	}
+
+	SetValidator(ValidatorFunc(func(interface{}) error {
+		return &ValidationError{FormError: "closed"}
+	}))
+	defer SetValidator(nil)
+	if _, err := fn(context.Background(), RemoteContext{}, map[string]interface{}{}); !errors.As(err, &verr) || verr.FormError != "closed" {
+		t.Fatalf("custom validator err = %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package gospa
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/plugin/seo"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+// seoFile is a generated robots.txt or sitemap.xml. Like an ISR page it is
+// served stale once RevalidateAfter passes while one rebuild runs in the
+// background. On a cold cache one request builds it while the others wait
+// on built.
+type seoFile struct {
+	mu       sync.Mutex
+	body     []byte
+	builtAt  time.Time
+	building bool
+	built    chan struct{}
+}
+
+func (f *seoFile) reset() {
+	f.mu.Lock()
+	f.body = nil
+	f.mu.Unlock()
+}
+
+// finishBuild stores the result of the build started by the caller and
+// wakes the requests waiting on it. A failed build keeps the old body.
+func (f *seoFile) finishBuild(body []byte, err error, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.building = false
+	close(f.built)
+	if err == nil {
+		f.body, f.builtAt = body, now
+	}
+}
+
+func (a *App) setupSEORoutes() {
+	cfg := a.Config.SEO
+	if cfg == nil {
+		return
+	}
+	if cfg.GenerateRobots {
+		a.Fiber.Get("/robots.txt", func(c fiberpkg.Ctx) error {
+			return a.serveSEOFile(c, &a.seoRobots, "text/plain; charset=utf-8", func(context.Context) ([]byte, error) {
+				return seo.Robots(a.seoConfig()), nil
+			})
+		})
+	}
+	if cfg.GenerateSitemap {
+		a.Fiber.Get("/sitemap.xml", func(c fiberpkg.Ctx) error {
+			return a.serveSEOFile(c, &a.seoSitemap, "application/xml; charset=utf-8", a.buildSitemap)
+		})
+	}
+}
+
+// seoConfig returns Config.SEO with SiteURL defaulting to PublicOrigin.
+func (a *App) seoConfig() *seo.Config {
+	cfg := *a.Config.SEO
+	if cfg.SiteURL == "" {
+		cfg.SiteURL = a.Config.PublicOrigin
+	}
+	cfg.SiteURL = strings.TrimRight(cfg.SiteURL, "/")
+	return &cfg
+}
+
+func (a *App) serveSEOFile(c fiberpkg.Ctx, f *seoFile, contentType string, build func(context.Context) ([]byte, error)) error {
+	ttl := a.Config.SEO.RevalidateAfter
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	f.mu.Lock()
+	for f.body == nil && f.building {
+		built := f.built
+		f.mu.Unlock()
+		select {
+		case <-built:
+		case <-c.Context().Done():
+			return c.Context().Err()
+		}
+		f.mu.Lock()
+	}
+	body := f.body
+	stale := body != nil && !f.building && a.now().Sub(f.builtAt) >= ttl
+	if body == nil || stale {
+		f.building = true
+		f.built = make(chan struct{})
+	}
+	f.mu.Unlock()
+
+	if body == nil {
+		var err error
+		body, err = build(c.Context())
+		f.finishBuild(body, err, a.now())
+		if err != nil {
+			a.Logger().Error("SEO file build failed", "path", c.Path(), "err", err)
+			return a.sendError(c, err)
+		}
+	} else if stale {
+		go a.rebuildSEOFile(f, strings.Clone(c.Path()), build) // #nosec //nolint:gosec // intentional: rebuild outlives the request
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Cache-Control", isrCacheControl(int(ttl.Seconds())))
+	return c.Send(body)
+}
+
+func (a *App) rebuildSEOFile(f *seoFile, path string, build func(context.Context) ([]byte, error)) {
+	timeout := a.Config.ISRTimeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(a.Context(), timeout)
+	defer cancel()
+
+	body, err := build(ctx)
+	f.finishBuild(body, err, a.now())
+	if err != nil {
+		a.Logger().Error("SEO file rebuild failed", "path", path, "err", err)
+	}
+}
+
+// RouteSEO returns the SEO plugin's metadata for a registered page: its
+// routing.Meta title and description, and its NoIndex and NoFollow options,
+// e.g. for seo.Plugin.GeneratePageMeta.
+func RouteSEO(routePath string) seo.PageSEO {
+	meta, _ := routing.GetPageMeta(routePath)
+	opts := routing.GetRouteOptions(routePath)
+	return seo.PageSEO{
+		Path:        routePath,
+		Title:       meta.Title,
+		Description: meta.Description,
+		NoIndex:     opts.NoIndex,
+		NoFollow:    opts.NoFollow,
+	}
+}
+
+// buildSitemap lists the static pages of the app and its mounted apps, minus
+// NoIndex routes and Hidden pages, and the pages from SEO.DynamicPages.
+func (a *App) buildSitemap(ctx context.Context) ([]byte, error) {
+	cfg := a.seoConfig()
+	var pages []seo.PageSEO
+	for _, route := range a.pages() {
+		if route.IsDynamic || route.IsCatchAll {
+			continue
+		}
+		if meta, _ := routing.GetPageMeta(route.Path); meta.Hidden {
+			continue
+		}
+		page := RouteSEO(route.Path)
+		if page.NoIndex {
+			continue
+		}
+		page.ChangeFreq, page.Priority = "weekly", 0.5
+		if route.Path == "/" {
+			page.ChangeFreq, page.Priority = "daily", 1.0
+		}
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Path < pages[j].Path })
+	if cfg.DynamicPages != nil {
+		dynamic, err := cfg.DynamicPages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, dynamic...)
+	}
+	return seo.Sitemap(cfg.SiteURL, pages), nil
+}
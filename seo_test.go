@@ -0,0 +1,253 @@
+package gospa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/plugin/seo"
+	"github.com/aydenstechdungeon/gospa/routing"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+func TestSEORoutes(t *testing.T) {
+	empty := func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, _ io.Writer) error { return nil })
+	}
+	routing.RegisterPage("/seo-public", empty)
+	routing.RegisterPageWithOptions("/seo-hidden", empty, routing.RouteOptions{NoIndex: true})
+	routing.RegisterPage("/seo-unlisted", empty)
+	routing.RegisterPageMeta("/seo-unlisted", routing.Meta{Hidden: true})
+
+	var mu sync.Mutex
+	now := time.Now()
+	posts := []string{"/seo-blog/hello"}
+	cfg := seo.DefaultConfig()
+	cfg.SiteURL = ""
+	cfg.Disallow = []string{"/admin"}
+	cfg.RevalidateAfter = time.Minute
+	cfg.DynamicPages = func(context.Context) ([]seo.PageSEO, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pages := make([]seo.PageSEO, 0, len(posts))
+		for _, p := range posts {
+			pages = append(pages, seo.PageSEO{Path: p, Modified: "2026-01-02"})
+		}
+		return pages, nil
+	}
+
+	app := New(Config{
+		// Other tests register cached routes globally.
+		CacheTemplates: true,
+		PublicOrigin:   "https://example.com",
+		SEO:            cfg,
+		Now: func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		},
+		RoutesFS: fstest.MapFS{
+			"seo-public/page.templ":      &fstest.MapFile{},
+			"seo-hidden/page.templ":      &fstest.MapFile{},
+			"seo-unlisted/page.templ":    &fstest.MapFile{},
+			"seo-blog/[slug]/page.templ": &fstest.MapFile{},
+		},
+	})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	get := func(path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, robots := get("/robots.txt")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(robots, "Disallow: /admin\n") ||
+		!strings.Contains(robots, "Sitemap: https://example.com/sitemap.xml") {
+		t.Fatalf("robots.txt (%d):\n%s", resp.StatusCode, robots)
+	}
+
+	resp, sitemap := get("/sitemap.xml")
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Fatalf("sitemap Content-Type = %q", ct)
+	}
+	if resp.Header.Get("Cache-Control") != isrCacheControl(60) {
+		t.Fatalf("sitemap Cache-Control = %q", resp.Header.Get("Cache-Control"))
+	}
+	for _, want := range []string{"<loc>https://example.com/seo-public</loc>", "<loc>https://example.com/seo-blog/hello</loc>", "<lastmod>2026-01-02</lastmod>"} {
+		if !strings.Contains(sitemap, want) {
+			t.Fatalf("sitemap missing %s:\n%s", want, sitemap)
+		}
+	}
+	for _, unwanted := range []string{"seo-hidden", "seo-unlisted", "[slug]", ":slug"} {
+		if strings.Contains(sitemap, unwanted) {
+			t.Fatalf("sitemap contains %s:\n%s", unwanted, sitemap)
+		}
+	}
+
+	// A new post stays out of the cached sitemap until it goes stale; the
+	// stale copy is served while the rebuild runs.
+	mu.Lock()
+	posts = append(posts, "/seo-blog/second")
+	mu.Unlock()
+	if _, body := get("/sitemap.xml"); strings.Contains(body, "second") {
+		t.Fatal("fresh sitemap was rebuilt")
+	}
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+	if _, body := get("/sitemap.xml"); strings.Contains(body, "second") {
+		t.Fatal("stale sitemap was not served while rebuilding")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, body := get("/sitemap.xml"); strings.Contains(body, "/seo-blog/second") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sitemap was not rebuilt")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSEOFileColdCacheBuildsOnce(t *testing.T) {
+	var builds atomic.Int32
+	cfg := seo.DefaultConfig()
+	cfg.SiteURL = "https://example.com"
+	cfg.DynamicPages = func(context.Context) ([]seo.PageSEO, error) {
+		builds.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	}
+	app := New(Config{CacheTemplates: true, SEO: cfg, RoutesFS: fstest.MapFS{}})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+			if err != nil {
+				t.Errorf("GET /sitemap.xml: %v", err)
+				return
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("sitemap built %d times, want 1", n)
+	}
+}
+
+func TestPageMetaHead(t *testing.T) {
+	routing.RegisterPage("/meta-pricing", func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "<p>plans</p>")
+			return err
+		})
+	})
+	routing.RegisterPageMeta("/meta-pricing", routing.Meta{Title: "Pricing", Description: `Plans & "prices"`})
+
+	app := New(Config{
+		CacheTemplates: true,
+		AppName:        "Shop",
+		RoutesFS:       fstest.MapFS{"meta-pricing/page.templ": &fstest.MapFile{}},
+	})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	if err := app.Prepare(); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	get := func() string {
+		t.Helper()
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, "/meta-pricing", nil))
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	body := get()
+	if !strings.Contains(body, "<title>Pricing</title>") ||
+		!strings.Contains(body, `<meta name="description" content="Plans &amp; &#34;prices&#34;">`) {
+		t.Fatalf("default document head:\n%s", body)
+	}
+
+	var gotTitle, gotDesc any
+	prev := routing.GetRootLayout()
+	routing.RegisterRootLayout(func(children templ.Component, props map[string]interface{}) templ.Component {
+		gotTitle, gotDesc = props["title"], props["description"]
+		return children
+	}, "")
+	defer routing.RegisterRootLayout(prev, "")
+	get()
+	if gotTitle != "Pricing" || gotDesc != `Plans & "prices"` {
+		t.Fatalf("root layout props title=%v description=%v", gotTitle, gotDesc)
+	}
+}
+
+func TestRouteRobotsDirectives(t *testing.T) {
+	routePath := fmt.Sprintf("/seo-robots-%d", time.Now().UnixNano())
+	routing.RegisterPageWithOptions(routePath, func(_ map[string]interface{}) templ.Component {
+		return templ.Raw("<p>private</p>")
+	}, routing.RouteOptions{NoIndex: true, NoFollow: true})
+	routing.RegisterPageMeta(routePath, routing.Meta{Title: "Private"})
+
+	app := New(Config{RoutesDir: t.TempDir()})
+	defer func() { _ = app.Fiber.Shutdown() }()
+	route := &routing.Route{Path: routePath}
+	app.Get(routePath, func(c fiberpkg.Ctx) error {
+		return app.renderRoute(c, route, map[string]interface{}{})
+	})
+
+	resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, routePath, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if got := resp.Header.Get("X-Robots-Tag"); got != "noindex, nofollow" {
+		t.Fatalf("X-Robots-Tag = %q", got)
+	}
+	if !strings.Contains(string(body), `<meta name="robots" content="noindex, nofollow">`) {
+		t.Fatalf("page without a root layout lacks the robots meta tag: %s", body)
+	}
+
+	props := map[string]interface{}{}
+	addPageMetaProps(props, routePath)
+	if props["robots"] != "noindex, nofollow" {
+		t.Fatalf("root layout props = %v", props)
+	}
+
+	page := RouteSEO(routePath)
+	if page.Title != "Private" || !page.NoIndex || !page.NoFollow {
+		t.Fatalf("RouteSEO = %+v", page)
+	}
+}
@@ -0,0 +1,211 @@
+package gospa
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/embed"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// defaultServiceWorkerPath is where the worker is served unless
+// ServiceWorkerConfig.Path says otherwise.
+const defaultServiceWorkerPath = "/gospa-sw.js"
+
+// ServiceWorkerConfig configures the service worker served for
+// Config.ServiceWorker. It precaches the runtime, the build's hashed assets
+// and the listed pages, so a docs or marketing site keeps working offline.
+type ServiceWorkerConfig struct {
+	// Path is where the worker is served, default "/gospa-sw.js". The
+	// runtime registers it with scope "/".
+	Path string
+	// Routes are the pages to precache, e.g. "/" and "/docs". Default: every
+	// SSG page without route parameters.
+	Routes []string
+	// Assets are further URLs to precache, e.g. "/static/app.css". The
+	// runtime, its chunks, the static files in BuildManifest and the web app
+	// manifest with its icons are always cached.
+	Assets []string
+	// OfflinePage is served for navigations that fail and are not cached,
+	// e.g. "/offline". It is precached too.
+	OfflinePage string
+	// Version names the cache. Default: a hash of the GoSPA version, the
+	// runtime, BuildManifest and the precache list, so each build replaces
+	// the previous cache.
+	Version string
+}
+
+// serviceWorkerPath returns the path the worker for cfg is served at.
+func serviceWorkerPath(cfg *ServiceWorkerConfig) string {
+	if cfg.Path == "" {
+		return defaultServiceWorkerPath
+	}
+	return cfg.Path
+}
+
+// serviceWorker is the generated worker script, built on first request
+// because the precache list depends on the scanned routes.
+type serviceWorker struct {
+	once sync.Once
+	body []byte
+}
+
+func (a *App) setupServiceWorkerRoutes() {
+	cfg := a.Config.ServiceWorker
+	if cfg == nil {
+		return
+	}
+	a.Fiber.Get(serviceWorkerPath(cfg), func(c fiberpkg.Ctx) error {
+		a.serviceWorker.once.Do(func() {
+			a.serviceWorker.body = a.buildServiceWorker()
+		})
+		c.Set("Content-Type", "application/javascript; charset=utf-8")
+		// Browsers check for a new worker on navigation; let every check
+		// reach the server so a new build's cache version is seen.
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Service-Worker-Allowed", "/")
+		return c.Send(a.serviceWorker.body)
+	})
+}
+
+// serviceWorkerPrecache returns the sorted URLs the worker caches on install.
+func (a *App) serviceWorkerPrecache() []string {
+	cfg := a.Config.ServiceWorker
+	urls := append([]string{}, cfg.Assets...)
+	routes := cfg.Routes
+	if routes == nil {
+		for _, page := range a.pages() {
+			if page.IsDynamic || page.IsCatchAll {
+				continue
+			}
+			strategy := routing.GetRouteOptions(page.Path).Strategy
+			if strategy == "" {
+				strategy = a.Config.DefaultRenderStrategy
+			}
+			if strategy == routing.StrategySSG {
+				routes = append(routes, page.Path)
+			}
+		}
+	}
+	urls = append(urls, routes...)
+	if cfg.OfflinePage != "" {
+		urls = append(urls, cfg.OfflinePage)
+	}
+	if runtime := a.getRuntimePath(); runtime != "" {
+		urls = append(urls, runtime)
+	}
+	if manifest := a.webAppManifest(); manifest != nil {
+		urls = append(urls, manifest.Href)
+		for _, icon := range manifest.Icons {
+			urls = append(urls, icon.Src)
+		}
+	}
+	if a.Config.BuildManifest != nil {
+		// The build's static files; the manifest also lists the server's
+		// own files.
+		for file := range a.Config.BuildManifest {
+			if path := "/" + file; strings.HasPrefix(path, a.Config.StaticPrefix+"/") {
+				urls = append(urls, a.assetURL(path))
+			}
+		}
+	} else {
+		// Chunks the runtime imports; the tier entry points other than the
+		// one in use are never requested.
+		for _, chunk := range embed.RuntimeChunks() {
+			switch chunk {
+			case "runtime.js", "runtime-core.js", "runtime-micro.js", "bootstrap.js":
+				continue
+			}
+			urls = append(urls, "/_gospa/"+chunk)
+		}
+	}
+	sort.Strings(urls)
+	return slices.Compact(urls)
+}
+
+// buildServiceWorker generates the worker script.
+func (a *App) buildServiceWorker() []byte {
+	cfg := a.Config.ServiceWorker
+	precache := a.serviceWorkerPrecache()
+	version := cfg.Version
+	if version == "" {
+		h := sha256.New()
+		runtimeHash, _ := embed.RuntimeHash(a.Config.RuntimeTier)
+		_, _ = fmt.Fprintf(h, "%s\n%s\n%s\n", Version, runtimeHash, cfg.OfflinePage)
+		for _, url := range precache {
+			_, _ = fmt.Fprintln(h, url)
+		}
+		manifest := make([]string, 0, len(a.Config.BuildManifest))
+		for name, file := range a.Config.BuildManifest {
+			manifest = append(manifest, name+"="+file)
+		}
+		sort.Strings(manifest)
+		for _, entry := range manifest {
+			_, _ = fmt.Fprintln(h, entry)
+		}
+		version = fmt.Sprintf("%x", h.Sum(nil)[:6])
+	}
+	list, _ := json.Marshal(precache)
+	return fmt.Appendf(nil, serviceWorkerTemplate, toJS(version), list, toJS(cfg.OfflinePage))
+}
+
+// serviceWorkerTemplate is the worker script. Pages are fetched network
+// first so online visitors always get fresh HTML, and fall back to the cache
+// and then the offline page; other precached URLs are served cache first.
+const serviceWorkerTemplate = `// Generated by GoSPA.
+const VERSION = %s;
+const PRECACHE = %s;
+const OFFLINE = %s;
+const PREFIX = (new URL(self.location).searchParams.get("cacheName") || "gospa") + "-";
+const CACHE = PREFIX + VERSION;
+const cached = new Set(PRECACHE);
+
+self.addEventListener("install", (event) => {
+  event.waitUntil(caches.open(CACHE).then((cache) => cache.addAll(PRECACHE)).then(() => self.skipWaiting()));
+});
+
+self.addEventListener("activate", (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((key) => key.startsWith(PREFIX) && key !== CACHE).map((key) => caches.delete(key))))
+      .then(() => self.clients.claim()),
+  );
+});
+
+self.addEventListener("fetch", (event) => {
+  const request = event.request;
+  const url = new URL(request.url);
+  if (request.method !== "GET" || url.origin !== self.location.origin) return;
+
+  if (request.mode === "navigate") {
+    event.respondWith(
+      fetch(request)
+        .then((response) => {
+          if (response.ok && cached.has(url.pathname)) {
+            const copy = response.clone();
+            caches.open(CACHE).then((cache) => cache.put(url.pathname, copy));
+          }
+          return response;
+        })
+        .catch(() =>
+          caches.match(url.pathname)
+            .then((response) => response || (OFFLINE && caches.match(OFFLINE)))
+            .then((response) => response || Response.error()),
+        ),
+    );
+    return;
+  }
+
+  const key = url.pathname + url.search;
+  if (cached.has(key)) {
+    event.respondWith(caches.match(key).then((response) => response || fetch(request)));
+  }
+});
+`
@@ -0,0 +1,108 @@
+package gospa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+	templpkg "github.com/aydenstechdungeon/gospa/templ"
+)
+
+func TestServiceWorker(t *testing.T) {
+	empty := func(_ map[string]interface{}) templ.Component {
+		return templ.ComponentFunc(func(_ context.Context, _ io.Writer) error { return nil })
+	}
+	routing.RegisterPageWithOptions("/sw-docs", empty, routing.RouteOptions{Strategy: routing.StrategySSG})
+	routing.RegisterPageWithOptions("/sw-docs/:slug", empty, routing.RouteOptions{Strategy: routing.StrategySSG})
+	routing.RegisterPage("/sw-account", empty)
+
+	newApp := func(manifest map[string]string) *App {
+		app := New(Config{
+			// Other tests register cached routes globally.
+			CacheTemplates: true,
+			BuildManifest:  manifest,
+			ServiceWorker: &ServiceWorkerConfig{
+				Assets:      []string{"/static/app.css"},
+				OfflinePage: "/offline",
+			},
+			Manifest: &templpkg.ManifestConfig{
+				ShortName: "Docs",
+				Icons:     []templpkg.ManifestIcon{{Src: "/static/icon.png", Sizes: "512x512"}},
+			},
+			RoutesFS: fstest.MapFS{
+				"sw-docs/page.templ":        &fstest.MapFile{},
+				"sw-docs/[slug]/page.templ": &fstest.MapFile{},
+				"sw-account/page.templ":     &fstest.MapFile{},
+			},
+		})
+		t.Cleanup(func() { _ = app.Fiber.Shutdown() })
+		if err := app.Prepare(); err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+		return app
+	}
+	get := func(app *App, path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	app := newApp(map[string]string{
+		"static/js/runtime.js": "static/js/runtime-abc123.js",
+		"static/icon.png":      "0123456789abcdef0123",
+		"server.conf":          "fedcba9876543210",
+	})
+	sw := app.Config.NavigationOptions.ServiceWorkerNavigationCaching
+	if sw == nil || sw.Enabled == nil || !*sw.Enabled || sw.Path != defaultServiceWorkerPath {
+		t.Fatalf("navigation service worker = %+v", sw)
+	}
+
+	resp, script := get(app, defaultServiceWorkerPath)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Service-Worker-Allowed") != "/" ||
+		resp.Header.Get("Cache-Control") != "no-cache" {
+		t.Fatalf("service worker response %d %v", resp.StatusCode, resp.Header)
+	}
+	for _, want := range []string{`"/sw-docs"`, `"/static/app.css"`, `"/offline"`, `"/static/js/runtime-abc123.js"`,
+		`"/manifest.webmanifest"`, `"/static/icon.png?v=0123456789ab"`} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("service worker does not precache %s:\n%s", want, script)
+		}
+	}
+	for _, unwanted := range []string{"sw-account", ":slug", "server.conf"} {
+		if strings.Contains(script, unwanted) {
+			t.Fatalf("service worker precaches %s:\n%s", unwanted, script)
+		}
+	}
+
+	// A different build gets a different cache.
+	_, other := get(newApp(map[string]string{"static/js/runtime.js": "static/js/runtime-def456.js"}), defaultServiceWorkerPath)
+	version := func(script string) string {
+		line, _, _ := strings.Cut(script[strings.Index(script, "const VERSION"):], "\n")
+		return line
+	}
+	if version(script) == version(other) {
+		t.Fatalf("both builds use %s", version(script))
+	}
+
+	resp, manifest := get(app, "/manifest.webmanifest")
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/manifest+json") {
+		t.Fatalf("manifest Content-Type = %q", ct)
+	}
+	for _, want := range []string{`"name":"GoSPA Application"`, `"short_name":"Docs"`, `"start_url":"/"`, `"display":"standalone"`,
+		`"src":"/static/icon.png?v=0123456789ab"`} {
+		if !strings.Contains(manifest, want) {
+			t.Fatalf("manifest missing %s: %s", want, manifest)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package gospa
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/routing"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// printStartupSummary prints the startup summary to stdout, once per server
+// (not from prefork children), unless DisableStartupSummary is set.
+func (a *App) printStartupSummary() {
+	if a.Config.DisableStartupSummary || fiberpkg.IsChild() {
+		return
+	}
+	a.writeStartupSummary(os.Stdout)
+}
+
+// writeStartupSummary writes a table of the settings the app runs with.
+func (a *App) writeStartupSummary(w io.Writer) {
+	cfg := &a.Config
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+
+	mode := "production"
+	switch {
+	case cfg.DevMode:
+		mode = "development"
+	case cfg.StrictProduction:
+		mode = "production (strict)"
+	}
+	if cfg.RequestMode {
+		mode += ", request mode"
+	}
+
+	strategy := cfg.DefaultRenderStrategy
+	if strategy == "" {
+		strategy = routing.StrategySSR
+	}
+	tier := cfg.RuntimeTier
+	if tier == "" {
+		tier = RuntimeTierFull
+	}
+
+	ws := "off"
+	if cfg.EnableWebSocket {
+		var opts []string
+		if cfg.CompressState {
+			opts = append(opts, "compressed")
+		}
+		if cfg.StateDiffing {
+			opts = append(opts, "diffing")
+		}
+		if cfg.StateSyncCoalesceInterval > 0 {
+			opts = append(opts, "coalesce "+cfg.StateSyncCoalesceInterval.String())
+		}
+		ws = cfg.WebSocketPath
+		if len(opts) > 0 {
+			ws += " (" + strings.Join(opts, ", ") + ")"
+		}
+	}
+	for i, ep := range a.wsEndpoints {
+		if i == 0 && !cfg.EnableWebSocket {
+			ws = ep.path
+			continue
+		}
+		ws += ", " + ep.path
+	}
+
+	remote := "no middleware"
+	switch {
+	case cfg.RemoteActionMiddleware != nil:
+		remote = "middleware"
+	case cfg.AllowUnauthenticatedRemoteActions:
+		remote = "unauthenticated"
+	}
+
+	var features []string
+	for _, f := range []struct {
+		name string
+		on   bool
+	}{
+		{"jobs", a.Jobs != nil},
+		{"analytics", a.Analytics != nil},
+		{"audit", a.Audit != nil},
+		{"database", cfg.Database != nil},
+		{"seo", cfg.SEO != nil},
+		{"cdn purge", cfg.CDNPurger != nil},
+		{"pprof", cfg.EnablePprof},
+	} {
+		if f.on {
+			features = append(features, f.name)
+		}
+	}
+	if len(features) == 0 {
+		features = []string{"none"}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "GoSPA %s\t%s\n", Version, cfg.AppName)
+	rows := [][2]string{
+		{"Mode", mode},
+		{"Pages", fmt.Sprint(len(a.pages()))},
+		{"Rendering", fmt.Sprintf("%s, template cache %s", strategy, onOff(cfg.CacheTemplates))},
+		{"Runtime", string(tier)},
+		{"WebSocket", ws},
+		{"Storage", backendName(store.Unwrap(cfg.Storage), isInMemoryStorage(cfg.Storage))},
+		{"PubSub", backendName(store.UnwrapPubSub(cfg.PubSub), isInMemoryPubSub(cfg.PubSub))},
+		{"CSRF", onOff(cfg.EnableCSRF && !cfg.DisableCSRF)},
+		{"Remote actions", cfg.RemotePrefix + " (" + remote + ")"},
+		{"Prefork", onOff(cfg.Prefork)},
+		{"Features", strings.Join(features, ", ")},
+	}
+	if len(a.hosts) > 0 {
+		rows = slices.Insert(rows, 2, [2]string{"Hosts", strings.Join(a.hostNames(), ", ")})
+	}
+	if cfg.Environment != "" {
+		rows = append([][2]string{{"Environment", cfg.Environment}}, rows...)
+	}
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", row[0], row[1])
+	}
+	_ = tw.Flush()
+}
+
+// backendName names a Storage or PubSub backend for the startup summary.
+func backendName(backend interface{}, memory bool) string {
+	if memory {
+		return "memory (this process only)"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", backend), "*")
+}
+
+func isInMemoryPubSub(pubsub store.PubSub) bool {
+	if pubsub == nil {
+		return true
+	}
+	_, ok := store.UnwrapPubSub(pubsub).(*store.MemoryPubSub)
+	return ok
+}
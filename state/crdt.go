@@ -0,0 +1,688 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"sync"
+
+	json "github.com/goccy/go-json"
+)
+
+// CRDT operation kinds.
+const (
+	CRDTOpSet    = "set" // map: assign a key
+	CRDTOpInsert = "ins" // list: insert an element after Ref
+	CRDTOpDelete = "del" // map: remove Key; list: remove the element at Ref
+)
+
+// maxCRDTPending bounds list inserts buffered while waiting for their reference element.
+const maxCRDTPending = 1024
+
+// OpID identifies a CRDT operation by Lamport counter and issuing replica.
+// IDs are totally ordered, which makes concurrent operations resolve the same
+// way on every replica.
+type OpID struct {
+	Counter uint64 `json:"c" msgpack:"c"`
+	Replica string `json:"r" msgpack:"r"`
+}
+
+// Less reports whether a was issued before b.
+func (a OpID) Less(b OpID) bool {
+	if a.Counter != b.Counter {
+		return a.Counter < b.Counter
+	}
+	return a.Replica < b.Replica
+}
+
+// IsZero reports whether the ID is unset (the list head).
+func (a OpID) IsZero() bool {
+	return a.Counter == 0 && a.Replica == ""
+}
+
+// CRDTOp is a single replicated operation. Ops are idempotent and commutative,
+// so replicas converge regardless of delivery order or duplication.
+type CRDTOp struct {
+	Op    string      `json:"op" msgpack:"op"`
+	ID    OpID        `json:"id" msgpack:"id"`
+	Key   string      `json:"key,omitempty" msgpack:"key,omitempty"`
+	Ref   *OpID       `json:"ref,omitempty" msgpack:"ref,omitempty"`
+	Value interface{} `json:"value,omitempty" msgpack:"value,omitempty"`
+}
+
+// CRDT is a reactive, conflict-free replicated value. Local mutations emit ops
+// to OnLocalOps listeners; ops from other replicas are merged with Apply.
+type CRDT interface {
+	Settable
+	// CRDTType returns "map" or "list".
+	CRDTType() string
+	// Apply merges remote ops and reports whether the visible value changed.
+	// Remote ops are not re-emitted to OnLocalOps listeners.
+	Apply(ops []CRDTOp) bool
+	// Ops returns the full replica state as ops, suitable for bootstrapping a new replica.
+	Ops() []CRDTOp
+	// OnLocalOps registers a listener for ops produced by local mutations.
+	OnLocalOps(fn func([]CRDTOp)) Unsubscribe
+}
+
+// crdtBase holds the bookkeeping shared by CRDTMap and CRDTList.
+type crdtBase struct {
+	mu          sync.RWMutex
+	id          string
+	replica     string
+	clock       uint64
+	version     uint64
+	nextSubID   uint64
+	subscribers map[uint64]func(any)
+	listeners   map[uint64]func([]CRDTOp)
+}
+
+func newCRDTBase() crdtBase {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return crdtBase{
+		id:          generateRuneID(),
+		replica:     hex.EncodeToString(buf),
+		subscribers: make(map[uint64]func(any)),
+		listeners:   make(map[uint64]func([]CRDTOp)),
+	}
+}
+
+// nextID issues a new operation ID. Caller must hold mu.
+func (b *crdtBase) nextID() OpID {
+	b.clock++
+	return OpID{Counter: b.clock, Replica: b.replica}
+}
+
+// observe advances the Lamport clock past a remote ID. Caller must hold mu.
+func (b *crdtBase) observe(id OpID) {
+	if id.Counter > b.clock {
+		b.clock = id.Counter
+	}
+}
+
+// ID returns the unique identifier for this CRDT.
+func (b *crdtBase) ID() string {
+	return b.id
+}
+
+// Version returns the number of visible changes applied so far.
+func (b *crdtBase) Version() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.version
+}
+
+// SubscribeAny registers a callback invoked with the new value after every change.
+func (b *crdtBase) SubscribeAny(fn func(any)) Unsubscribe {
+	b.mu.Lock()
+	id := b.nextSubID
+	b.nextSubID++
+	b.subscribers[id] = fn
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		delete(b.subscribers, id)
+		b.mu.Unlock()
+	}
+}
+
+// OnLocalOps registers a listener for ops produced by local mutations.
+func (b *crdtBase) OnLocalOps(fn func([]CRDTOp)) Unsubscribe {
+	b.mu.Lock()
+	id := b.nextSubID
+	b.nextSubID++
+	b.listeners[id] = fn
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		delete(b.listeners, id)
+		b.mu.Unlock()
+	}
+}
+
+// changed bumps the version and snapshots callbacks. Caller must hold mu.
+func (b *crdtBase) changed(local bool) ([]func(any), []func([]CRDTOp)) {
+	b.version++
+	subs := make([]func(any), 0, len(b.subscribers))
+	for _, fn := range b.subscribers {
+		subs = append(subs, fn)
+	}
+	if !local {
+		return subs, nil
+	}
+	listeners := make([]func([]CRDTOp), 0, len(b.listeners))
+	for _, fn := range b.listeners {
+		listeners = append(listeners, fn)
+	}
+	return subs, listeners
+}
+
+// notifyCRDT runs callbacks outside the lock.
+func notifyCRDT(value any, ops []CRDTOp, subs []func(any), listeners []func([]CRDTOp)) {
+	for _, fn := range subs {
+		fn(value)
+	}
+	for _, fn := range listeners {
+		fn(ops)
+	}
+}
+
+// toPlain converts arbitrary values into their JSON-decoded form (maps, slices, float64).
+func toPlain(value any, out any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+type crdtMapEntry struct {
+	id      OpID
+	value   any
+	deleted bool
+}
+
+// CRDTMap is a last-writer-wins map CRDT. Each key is resolved independently
+// by operation ID, so concurrent writes to different keys never clobber each other.
+type CRDTMap struct {
+	crdtBase
+	entries map[string]*crdtMapEntry
+}
+
+// NewCRDTMap creates an empty CRDT map with a fresh replica ID.
+//
+// Example:
+//
+//	todos := state.NewCRDTMap()
+//	todos.Set("t1", map[string]any{"title": "Write docs", "done": false})
+func NewCRDTMap() *CRDTMap {
+	return &CRDTMap{crdtBase: newCRDTBase(), entries: make(map[string]*crdtMapEntry)}
+}
+
+// CRDTType implements CRDT.
+func (m *CRDTMap) CRDTType() string { return "map" }
+
+// Get returns the value stored under key.
+func (m *CRDTMap) Get(key string) (any, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[key]
+	if !ok || e.deleted {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Len returns the number of visible keys.
+func (m *CRDTMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := 0
+	for _, e := range m.entries {
+		if !e.deleted {
+			n++
+		}
+	}
+	return n
+}
+
+// Set assigns key locally and emits the op.
+func (m *CRDTMap) Set(key string, value any) {
+	m.local(func() []CRDTOp {
+		return []CRDTOp{{Op: CRDTOpSet, ID: m.nextID(), Key: key, Value: value}}
+	})
+}
+
+// Delete removes key locally and emits the op.
+func (m *CRDTMap) Delete(key string) {
+	m.local(func() []CRDTOp {
+		if e, ok := m.entries[key]; !ok || e.deleted {
+			return nil
+		}
+		return []CRDTOp{{Op: CRDTOpDelete, ID: m.nextID(), Key: key}}
+	})
+}
+
+// GetAny returns a copy of the visible map. This implements the Observable interface.
+func (m *CRDTMap) GetAny() any {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.valueLocked()
+}
+
+func (m *CRDTMap) valueLocked() map[string]any {
+	out := make(map[string]any, len(m.entries))
+	for key, e := range m.entries {
+		if !e.deleted {
+			out[key] = e.value
+		}
+	}
+	return out
+}
+
+// SetAny replaces the visible map, emitting set/del ops only for keys that differ.
+// This implements the Settable interface so plain "update" messages still work.
+func (m *CRDTMap) SetAny(value any) error {
+	next, ok := value.(map[string]any)
+	if !ok {
+		if err := toPlain(value, &next); err != nil {
+			return err
+		}
+	}
+	m.local(func() []CRDTOp {
+		var ops []CRDTOp
+		keys := make([]string, 0, len(next))
+		for key := range next {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if e, ok := m.entries[key]; ok && !e.deleted && reflect.DeepEqual(e.value, next[key]) {
+				continue
+			}
+			ops = append(ops, CRDTOp{Op: CRDTOpSet, ID: m.nextID(), Key: key, Value: next[key]})
+		}
+		for key, e := range m.entries {
+			if _, keep := next[key]; !keep && !e.deleted {
+				ops = append(ops, CRDTOp{Op: CRDTOpDelete, ID: m.nextID(), Key: key})
+			}
+		}
+		return ops
+	})
+	return nil
+}
+
+// local applies ops produced by build under the lock and emits them.
+func (m *CRDTMap) local(build func() []CRDTOp) {
+	m.mu.Lock()
+	ops := build()
+	for _, op := range ops {
+		m.applyLocked(op)
+	}
+	if len(ops) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	subs, listeners := m.changed(true)
+	value := m.valueLocked()
+	m.mu.Unlock()
+	notifyCRDT(value, ops, subs, listeners)
+}
+
+// Apply implements CRDT.
+func (m *CRDTMap) Apply(ops []CRDTOp) bool {
+	m.mu.Lock()
+	changed := false
+	for _, op := range ops {
+		m.observe(op.ID)
+		if m.applyLocked(op) {
+			changed = true
+		}
+	}
+	if !changed {
+		m.mu.Unlock()
+		return false
+	}
+	subs, _ := m.changed(false)
+	value := m.valueLocked()
+	m.mu.Unlock()
+	notifyCRDT(value, nil, subs, nil)
+	return true
+}
+
+func (m *CRDTMap) applyLocked(op CRDTOp) bool {
+	if op.Op != CRDTOpSet && op.Op != CRDTOpDelete {
+		return false
+	}
+	if e, ok := m.entries[op.Key]; ok && !e.id.Less(op.ID) {
+		return false
+	}
+	m.entries[op.Key] = &crdtMapEntry{id: op.ID, value: op.Value, deleted: op.Op == CRDTOpDelete}
+	return true
+}
+
+// Ops implements CRDT. Tombstones are included so deletions reach new replicas.
+func (m *CRDTMap) Ops() []CRDTOp {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ops := make([]CRDTOp, 0, len(m.entries))
+	for key, e := range m.entries {
+		op := CRDTOp{Op: CRDTOpSet, ID: e.id, Key: key, Value: e.value}
+		if e.deleted {
+			op = CRDTOp{Op: CRDTOpDelete, ID: e.id, Key: key}
+		}
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID.Less(ops[j].ID) })
+	return ops
+}
+
+// MarshalJSON implements json.Marshaler for serialization to client.
+func (m *CRDTMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":    m.id,
+		"value": m.GetAny(),
+	})
+}
+
+type crdtListElem struct {
+	id      OpID
+	ref     OpID
+	value   any
+	deleted bool
+}
+
+// CRDTList is a replicated growable array (RGA). Elements are inserted after a
+// reference element; concurrent inserts at the same position are ordered by
+// operation ID so every replica converges on the same sequence.
+type CRDTList struct {
+	crdtBase
+	elems      map[OpID]*crdtListElem
+	children   map[OpID][]OpID
+	order      []OpID
+	pending    []CRDTOp
+	pendingDel map[OpID]bool
+}
+
+// NewCRDTList creates an empty CRDT list with a fresh replica ID.
+//
+// Example:
+//
+//	items := state.NewCRDTList()
+//	items.Append("milk")
+//	items.Insert(0, "eggs")
+func NewCRDTList() *CRDTList {
+	return &CRDTList{
+		crdtBase:   newCRDTBase(),
+		elems:      make(map[OpID]*crdtListElem),
+		children:   make(map[OpID][]OpID),
+		pendingDel: make(map[OpID]bool),
+	}
+}
+
+// CRDTType implements CRDT.
+func (l *CRDTList) CRDTType() string { return "list" }
+
+// Len returns the number of visible elements.
+func (l *CRDTList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.order)
+}
+
+// Values returns a copy of the visible elements.
+func (l *CRDTList) Values() []any {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.valueLocked()
+}
+
+func (l *CRDTList) valueLocked() []any {
+	out := make([]any, len(l.order))
+	for i, id := range l.order {
+		out[i] = l.elems[id].value
+	}
+	return out
+}
+
+// GetAny returns a copy of the visible elements. This implements the Observable interface.
+func (l *CRDTList) GetAny() any {
+	return l.Values()
+}
+
+// Insert places value at index (clamped to the list bounds) and emits the op.
+func (l *CRDTList) Insert(index int, value any) {
+	l.local(func() []CRDTOp {
+		return []CRDTOp{l.insertOpLocked(index, value)}
+	})
+}
+
+// Append adds value to the end of the list.
+func (l *CRDTList) Append(value any) {
+	l.local(func() []CRDTOp {
+		return []CRDTOp{l.insertOpLocked(len(l.order), value)}
+	})
+}
+
+// Delete removes the element at index. Out-of-range indexes are ignored.
+func (l *CRDTList) Delete(index int) {
+	l.local(func() []CRDTOp {
+		if index < 0 || index >= len(l.order) {
+			return nil
+		}
+		target := l.order[index]
+		return []CRDTOp{{Op: CRDTOpDelete, ID: l.nextID(), Ref: &target}}
+	})
+}
+
+func (l *CRDTList) insertOpLocked(index int, value any) CRDTOp {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(l.order) {
+		index = len(l.order)
+	}
+	var ref OpID
+	if index > 0 {
+		ref = l.order[index-1]
+	}
+	return CRDTOp{Op: CRDTOpInsert, ID: l.nextID(), Ref: &ref, Value: value}
+}
+
+// SetAny replaces the visible sequence. The common prefix and suffix are kept
+// and only the differing middle is deleted and re-inserted.
+// This implements the Settable interface so plain "update" messages still work.
+func (l *CRDTList) SetAny(value any) error {
+	next, ok := value.([]any)
+	if !ok {
+		if err := toPlain(value, &next); err != nil {
+			return err
+		}
+	}
+	l.local(func() []CRDTOp {
+		cur := l.valueLocked()
+		prefix := 0
+		for prefix < len(cur) && prefix < len(next) && reflect.DeepEqual(cur[prefix], next[prefix]) {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(cur)-prefix && suffix < len(next)-prefix &&
+			reflect.DeepEqual(cur[len(cur)-1-suffix], next[len(next)-1-suffix]) {
+			suffix++
+		}
+
+		var ops []CRDTOp
+		for i := prefix; i < len(cur)-suffix; i++ {
+			target := l.order[i]
+			ops = append(ops, CRDTOp{Op: CRDTOpDelete, ID: l.nextID(), Ref: &target})
+		}
+		var ref OpID
+		if prefix > 0 {
+			ref = l.order[prefix-1]
+		}
+		for _, v := range next[prefix : len(next)-suffix] {
+			after := ref
+			op := CRDTOp{Op: CRDTOpInsert, ID: l.nextID(), Ref: &after, Value: v}
+			ops = append(ops, op)
+			ref = op.ID
+		}
+		return ops
+	})
+	return nil
+}
+
+// local applies ops produced by build under the lock and emits them.
+func (l *CRDTList) local(build func() []CRDTOp) {
+	l.mu.Lock()
+	ops := build()
+	for _, op := range ops {
+		l.applyLocked(op)
+	}
+	if len(ops) == 0 {
+		l.mu.Unlock()
+		return
+	}
+	l.reorderLocked()
+	subs, listeners := l.changed(true)
+	value := l.valueLocked()
+	l.mu.Unlock()
+	notifyCRDT(value, ops, subs, listeners)
+}
+
+// Apply implements CRDT. Inserts whose reference element has not arrived yet
+// are buffered until it does.
+func (l *CRDTList) Apply(ops []CRDTOp) bool {
+	l.mu.Lock()
+	changed := false
+	for _, op := range ops {
+		l.observe(op.ID)
+		if l.applyLocked(op) {
+			changed = true
+		}
+	}
+	if !changed {
+		l.mu.Unlock()
+		return false
+	}
+	l.reorderLocked()
+	subs, _ := l.changed(false)
+	value := l.valueLocked()
+	l.mu.Unlock()
+	notifyCRDT(value, nil, subs, nil)
+	return true
+}
+
+func (l *CRDTList) applyLocked(op CRDTOp) bool {
+	if op.Ref == nil {
+		return false
+	}
+	switch op.Op {
+	case CRDTOpInsert:
+		if !l.insertLocked(op) {
+			return false
+		}
+		l.drainPendingLocked()
+		return true
+	case CRDTOpDelete:
+		e, ok := l.elems[*op.Ref]
+		if !ok {
+			if len(l.pendingDel) < maxCRDTPending {
+				l.pendingDel[*op.Ref] = true
+			}
+			return false
+		}
+		if e.deleted {
+			return false
+		}
+		e.deleted = true
+		return true
+	}
+	return false
+}
+
+func (l *CRDTList) insertLocked(op CRDTOp) bool {
+	if op.ID.IsZero() {
+		return false
+	}
+	if _, exists := l.elems[op.ID]; exists {
+		return false
+	}
+	ref := *op.Ref
+	if !ref.IsZero() {
+		if _, ok := l.elems[ref]; !ok {
+			if len(l.pending) >= maxCRDTPending {
+				l.pending = l.pending[1:]
+			}
+			l.pending = append(l.pending, op)
+			return false
+		}
+	}
+	e := &crdtListElem{id: op.ID, ref: ref, value: op.Value}
+	if l.pendingDel[op.ID] {
+		e.deleted = true
+		delete(l.pendingDel, op.ID)
+	}
+	l.elems[op.ID] = e
+
+	// Siblings are kept newest-first: a later insert at the same position lands in front.
+	siblings := l.children[ref]
+	i := sort.Search(len(siblings), func(i int) bool { return siblings[i].Less(op.ID) })
+	siblings = append(siblings, OpID{})
+	copy(siblings[i+1:], siblings[i:])
+	siblings[i] = op.ID
+	l.children[ref] = siblings
+	return true
+}
+
+// drainPendingLocked retries buffered inserts until no more can be placed.
+func (l *CRDTList) drainPendingLocked() {
+	for progress := true; progress && len(l.pending) > 0; {
+		progress = false
+		remaining := l.pending[:0]
+		for _, op := range l.pending {
+			if _, ok := l.elems[*op.Ref]; ok {
+				l.insertLocked(op)
+				progress = true
+				continue
+			}
+			remaining = append(remaining, op)
+		}
+		l.pending = remaining
+	}
+}
+
+// reorderLocked recomputes the visible order with an iterative pre-order walk.
+func (l *CRDTList) reorderLocked() {
+	order := l.order[:0]
+	stack := make([]OpID, 0, 16)
+	push := func(parent OpID) {
+		kids := l.children[parent]
+		for i := len(kids) - 1; i >= 0; i-- {
+			stack = append(stack, kids[i])
+		}
+	}
+	push(OpID{})
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !l.elems[id].deleted {
+			order = append(order, id)
+		}
+		push(id)
+	}
+	l.order = order
+}
+
+// Ops implements CRDT. Inserts are emitted parents-first, followed by deletions.
+func (l *CRDTList) Ops() []CRDTOp {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	ops := make([]CRDTOp, 0, len(l.elems))
+	var dels []CRDTOp
+	stack := []OpID{{}}
+	for len(stack) > 0 {
+		parent := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, id := range l.children[parent] {
+			e := l.elems[id]
+			ref := e.ref
+			ops = append(ops, CRDTOp{Op: CRDTOpInsert, ID: e.id, Ref: &ref, Value: e.value})
+			if e.deleted {
+				target := e.id
+				dels = append(dels, CRDTOp{Op: CRDTOpDelete, ID: e.id, Ref: &target})
+			}
+			stack = append(stack, id)
+		}
+	}
+	return append(ops, dels...)
+}
+
+// MarshalJSON implements json.Marshaler for serialization to client.
+func (l *CRDTList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":    l.id,
+		"value": l.Values(),
+	})
+}
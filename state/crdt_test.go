@@ -0,0 +1,141 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+// exchange delivers every op produced locally by a to b and vice versa.
+func exchange(a, b CRDT) (func(), *[]CRDTOp, *[]CRDTOp) {
+	var fromA, fromB []CRDTOp
+	unsubA := a.OnLocalOps(func(ops []CRDTOp) { fromA = append(fromA, ops...) })
+	unsubB := b.OnLocalOps(func(ops []CRDTOp) { fromB = append(fromB, ops...) })
+	return func() { unsubA(); unsubB() }, &fromA, &fromB
+}
+
+func TestCRDTMapConcurrentWritesConverge(t *testing.T) {
+	a, b := NewCRDTMap(), NewCRDTMap()
+	stop, fromA, fromB := exchange(a, b)
+	defer stop()
+
+	a.Set("title", "from a")
+	a.Set("owner", "alice")
+	b.Set("title", "from b")
+	b.Set("done", true)
+
+	// Deliver in opposite orders; both replicas must agree.
+	b.Apply(*fromA)
+	a.Apply(*fromB)
+
+	if !reflect.DeepEqual(a.GetAny(), b.GetAny()) {
+		t.Fatalf("replicas diverged: %v vs %v", a.GetAny(), b.GetAny())
+	}
+	if a.Len() != 3 {
+		t.Fatalf("expected 3 keys, got %v", a.GetAny())
+	}
+
+	// Re-applying is a no-op.
+	if a.Apply(*fromB) {
+		t.Fatal("duplicate ops must not change the map")
+	}
+}
+
+func TestCRDTMapDeleteAndSetAny(t *testing.T) {
+	m := NewCRDTMap()
+	var emitted []CRDTOp
+	m.OnLocalOps(func(ops []CRDTOp) { emitted = append(emitted, ops...) })
+
+	m.Set("a", 1.0)
+	m.Set("b", 2.0)
+	emitted = nil
+	if err := m.SetAny(map[string]any{"a": 1.0, "c": 3.0}); err != nil {
+		t.Fatalf("SetAny: %v", err)
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("expected set c + del b, got %+v", emitted)
+	}
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("expected b to be deleted")
+	}
+
+	replica := NewCRDTMap()
+	replica.Apply(m.Ops())
+	if !reflect.DeepEqual(replica.GetAny(), m.GetAny()) {
+		t.Fatalf("bootstrap mismatch: %v vs %v", replica.GetAny(), m.GetAny())
+	}
+}
+
+func TestCRDTListConcurrentInsertsConverge(t *testing.T) {
+	a, b := NewCRDTList(), NewCRDTList()
+	stop, fromA, fromB := exchange(a, b)
+	defer stop()
+
+	a.Append("x")
+	b.Apply(*fromA)
+	*fromA = nil
+
+	a.Insert(1, "a1")
+	a.Insert(2, "a2")
+	b.Insert(1, "b1")
+	b.Delete(0)
+
+	b.Apply(*fromA)
+	a.Apply(*fromB)
+
+	if !reflect.DeepEqual(a.Values(), b.Values()) {
+		t.Fatalf("replicas diverged: %v vs %v", a.Values(), b.Values())
+	}
+	if a.Len() != 3 {
+		t.Fatalf("expected 3 elements, got %v", a.Values())
+	}
+}
+
+func TestCRDTListOutOfOrderDelivery(t *testing.T) {
+	src := NewCRDTList()
+	var ops []CRDTOp
+	src.OnLocalOps(func(o []CRDTOp) { ops = append(ops, o...) })
+	src.Append("one")
+	src.Append("two")
+	src.Append("three")
+	src.Delete(1)
+
+	dst := NewCRDTList()
+	for i := len(ops) - 1; i >= 0; i-- {
+		dst.Apply([]CRDTOp{ops[i]})
+	}
+	want := []any{"one", "three"}
+	if !reflect.DeepEqual(dst.Values(), want) {
+		t.Fatalf("expected %v, got %v", want, dst.Values())
+	}
+
+	fresh := NewCRDTList()
+	fresh.Apply(src.Ops())
+	if !reflect.DeepEqual(fresh.Values(), want) {
+		t.Fatalf("bootstrap mismatch: %v", fresh.Values())
+	}
+}
+
+func TestCRDTListSetAnyKeepsCommonEdges(t *testing.T) {
+	l := NewCRDTList()
+	if err := l.SetAny([]any{"a", "b", "c"}); err != nil {
+		t.Fatalf("SetAny: %v", err)
+	}
+	var emitted []CRDTOp
+	l.OnLocalOps(func(ops []CRDTOp) { emitted = append(emitted, ops...) })
+	if err := l.SetAny([]any{"a", "x", "y", "c"}); err != nil {
+		t.Fatalf("SetAny: %v", err)
+	}
+	if want := []any{"a", "x", "y", "c"}; !reflect.DeepEqual(l.Values(), want) {
+		t.Fatalf("expected %v, got %v", want, l.Values())
+	}
+	if len(emitted) != 3 {
+		t.Fatalf("expected 1 delete + 2 inserts, got %d ops", len(emitted))
+	}
+
+	var notified any
+	l.SubscribeAny(func(v any) { notified = v })
+	l.Delete(0)
+	if !reflect.DeepEqual(notified, []any{"x", "y", "c"}) {
+		t.Fatalf("subscriber got %v", notified)
+	}
+}
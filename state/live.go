@@ -0,0 +1,89 @@
+// Package state provides live runes, whose values come from a clock or a
+// channel instead of Set calls.
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Live is an Observable fed from outside the program's own state, such as a
+// metric sampled on an interval. Something has to drive it: App.Live runs it
+// while a connected client receives its key and pauses it otherwise.
+type Live interface {
+	Observable
+	// Run updates the value until ctx is done or the source ends.
+	Run(ctx context.Context)
+}
+
+// LiveRune is a Rune whose value is produced by Run.
+type LiveRune[T any] struct {
+	*Rune[T]
+	// runMu keeps a second Run from feeding the rune at the same time.
+	runMu  sync.Mutex
+	source func(ctx context.Context, set func(T))
+}
+
+// NewTicker creates a live rune that takes the value of fn when Run starts
+// and every interval after that, while it runs.
+//
+// Example:
+//
+//	clock := state.NewTicker(time.Second, func() string {
+//	    return time.Now().Format(time.TimeOnly)
+//	})
+//	app.Live("clock", clock)
+func NewTicker[T any](interval time.Duration, fn func() T) *LiveRune[T] {
+	var zero T
+	return &LiveRune[T]{
+		Rune: NewRune(zero),
+		source: func(ctx context.Context, set func(T)) {
+			set(fn())
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					set(fn())
+				}
+			}
+		},
+	}
+}
+
+// FromChannel creates a live rune that takes each value received from ch
+// while it runs. While it is paused nothing reads ch, so senders block or
+// fill its buffer; the rune keeps the last value once ch is closed.
+func FromChannel[T any](ch <-chan T) *LiveRune[T] {
+	var zero T
+	return &LiveRune[T]{
+		Rune: NewRune(zero),
+		source: func(ctx context.Context, set func(T)) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					set(v)
+				}
+			}
+		},
+	}
+}
+
+// Run feeds the rune until ctx is done or its source ends. Calls made while
+// another Run is active wait for it to return.
+func (l *LiveRune[T]) Run(ctx context.Context) {
+	l.runMu.Lock()
+	defer l.runMu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	l.source(ctx, l.Set)
+}
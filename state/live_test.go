@@ -0,0 +1,66 @@
+package state
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewTickerRunsUntilCanceled(t *testing.T) {
+	var calls atomic.Int32
+	ticker := NewTicker(5*time.Millisecond, func() int32 {
+		return calls.Add(1)
+	})
+	if ticker.Get() != 0 {
+		t.Fatalf("ticker sampled before Run: %d", ticker.Get())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ticker.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for ticker.Get() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ticker value = %d after 2s", ticker.Get())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	paused := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != paused {
+		t.Fatal("ticker kept sampling after Run returned")
+	}
+}
+
+func TestFromChannel(t *testing.T) {
+	ch := make(chan string)
+	live := FromChannel(ch)
+	var seen []string
+	live.Subscribe(func(v string) { seen = append(seen, v) })
+
+	done := make(chan struct{})
+	go func() {
+		live.Run(context.Background())
+		close(done)
+	}()
+	ch <- "queued"
+	ch <- "running"
+	close(ch)
+	<-done
+
+	if live.Get() != "running" || len(seen) != 2 {
+		t.Fatalf("value = %q, seen %q", live.Get(), seen)
+	}
+	// A closed channel ends Run right away and keeps the value.
+	live.Run(context.Background())
+	if live.Get() != "running" {
+		t.Fatalf("value after rerun = %q", live.Get())
+	}
+}
@@ -0,0 +1,201 @@
+package state
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StateKey is a key of a Config.DefaultState literal and the places that
+// reference it.
+//
+//nolint:revive // named after StateUsage
+type StateKey struct {
+	Name       string   `json:"name"`
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+	References []string `json:"references,omitempty"`
+	IsUsed     bool     `json:"isUsed"`
+}
+
+// componentExts are the sources searched for DefaultState key references
+// besides Go string literals.
+var componentExts = map[string]bool{
+	".templ": true,
+	".gospa": true,
+	".ts":    true,
+	".tsx":   true,
+	".js":    true,
+	".jsx":   true,
+	".mjs":   true,
+	".html":  true,
+}
+
+// stringRef is a Go string literal that may name a state key.
+type stringRef struct {
+	value string
+	pos   token.Pos
+}
+
+// skipDir reports whether the walk should skip a directory: dependencies,
+// hidden directories and build output.
+func (sp *StatePruner) skipDir(path string, info os.FileInfo) bool {
+	if filepath.Clean(path) == filepath.Clean(sp.config.RootDir) {
+		return false
+	}
+	name := info.Name()
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "dist"
+}
+
+// isComponentFile reports whether path is a component or client source to
+// search for DefaultState key references. Generated code is skipped, since
+// generated types list every key.
+func isComponentFile(path string) bool {
+	if strings.HasSuffix(path, ".d.ts") {
+		return false
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "generated" {
+			return false
+		}
+	}
+	return componentExts[filepath.Ext(path)]
+}
+
+// processDefaultState records the string keys of a DefaultState composite
+// literal.
+func (sp *StatePruner) processDefaultState(kv *ast.KeyValueExpr, path string) {
+	if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "DefaultState" {
+		return
+	}
+	lit, ok := kv.Value.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		entry, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		keyLit, ok := entry.Key.(*ast.BasicLit)
+		if !ok || keyLit.Kind != token.STRING {
+			continue
+		}
+		name, err := strconv.Unquote(keyLit.Value)
+		if err != nil {
+			continue
+		}
+		if _, seen := sp.stateKeys[name]; seen {
+			continue
+		}
+		sp.stateKeys[name] = StateKey{
+			Name: name,
+			File: path,
+			Line: sp.fset.Position(keyLit.Pos()).Line,
+		}
+		sp.keyPos[keyLit.Pos()] = true
+	}
+}
+
+// keyPattern matches key as a whole token: bound by anything other than
+// identifier characters, as in "count", text:count or state.count.
+func keyPattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^A-Za-z0-9_$])` + regexp.QuoteMeta(key) + `([^A-Za-z0-9_$]|$)`)
+}
+
+// markUsedStateKeys resolves references to DefaultState keys from Go string
+// literals and component sources.
+func (sp *StatePruner) markUsedStateKeys() {
+	if len(sp.stateKeys) == 0 {
+		return
+	}
+	patterns := make(map[string]*regexp.Regexp, len(sp.stateKeys))
+	for name := range sp.stateKeys {
+		patterns[name] = keyPattern(name)
+	}
+	addRef := func(name, ref string) {
+		key := sp.stateKeys[name]
+		key.IsUsed = true
+		key.References = append(key.References, ref)
+		sp.stateKeys[name] = key
+	}
+
+	for _, s := range sp.stringRefs {
+		if sp.keyPos[s.pos] {
+			continue
+		}
+		for name, re := range patterns {
+			if re.MatchString(s.value) {
+				pos := sp.fset.Position(s.pos)
+				addRef(name, fmt.Sprintf("%s:%d", pos.Filename, pos.Line))
+			}
+		}
+	}
+
+	for _, path := range sp.componentFiles {
+		// #nosec //nolint:gosec // path comes from walking RootDir
+		content, err := os.ReadFile(path)
+		if err != nil {
+			sp.report.Errors = append(sp.report.Errors, fmt.Sprintf("failed to read %s: %v", path, err))
+			continue
+		}
+		text := string(content)
+		for name, re := range patterns {
+			if loc := re.FindStringIndex(text); loc != nil {
+				line := strings.Count(text[:loc[0]], "\n") + 1
+				addRef(name, fmt.Sprintf("%s:%d", path, line))
+			}
+		}
+	}
+
+	for name, key := range sp.stateKeys {
+		sp.report.StateKeys[name] = key
+		if !key.IsUsed {
+			sp.report.UnusedStateKeys = append(sp.report.UnusedStateKeys, name)
+		}
+	}
+	sort.Strings(sp.report.UnusedStateKeys)
+}
+
+var (
+	prunedKeysMu sync.RWMutex
+	prunedKeys   map[string]bool
+)
+
+// RegisterPrunedKeys marks DefaultState keys that no component references,
+// so PruneDefaultState leaves them out of the initial state. gospa build
+// --prune-state generates the call; it is not meant to be written by hand.
+func RegisterPrunedKeys(keys ...string) {
+	prunedKeysMu.Lock()
+	defer prunedKeysMu.Unlock()
+	if prunedKeys == nil {
+		prunedKeys = make(map[string]bool, len(keys))
+	}
+	for _, k := range keys {
+		prunedKeys[k] = true
+	}
+}
+
+// PruneDefaultState returns defaults without the keys registered with
+// RegisterPrunedKeys. defaults itself is not modified.
+func PruneDefaultState(defaults map[string]interface{}) map[string]interface{} {
+	prunedKeysMu.RLock()
+	defer prunedKeysMu.RUnlock()
+	if len(prunedKeys) == 0 {
+		return defaults
+	}
+	out := make(map[string]interface{}, len(defaults))
+	for k, v := range defaults {
+		if !prunedKeys[k] {
+			out[k] = v
+		}
+	}
+	return out
+}
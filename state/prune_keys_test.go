@@ -0,0 +1,93 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree writes files relative to a new temp dir and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestAnalyze_UnusedStateKeys(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"main.go": `package main
+
+func main() {
+	_ = Config{
+		DefaultState: map[string]interface{}{
+			"count":   0,
+			"theme":   "dark",
+			"flag":    true,
+			"legacy":  1,
+			"visitor": "",
+		},
+	}
+	hub.Broadcast("flag", true)
+}
+`,
+		"routes/page.templ":         `<span { templ.Bind("count", templ.TextBind) }></span>`,
+		"client/app.ts":             `const t = state.theme;`,
+		"routes/types.d.ts":         `export interface GospaState { legacy: number; visitor: string }`,
+		"generated/routes.ts":       `// legacy visitor`,
+		"node_modules/x/index.js":   `"legacy"`,
+		"routes/counter/page.gospa": `<p>visitors</p>`,
+	})
+
+	cfg := DefaultPruningConfig()
+	cfg.RootDir = root
+	report, err := NewStatePruner(cfg).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if want := []string{"legacy", "visitor"}; !reflect.DeepEqual(report.UnusedStateKeys, want) {
+		t.Fatalf("UnusedStateKeys = %v, want %v", report.UnusedStateKeys, want)
+	}
+	if len(report.StateKeys) != 5 {
+		t.Fatalf("expected 5 state keys, got %d", len(report.StateKeys))
+	}
+	count := report.StateKeys["count"]
+	if !count.IsUsed || count.Line != 6 || len(count.References) != 1 {
+		t.Errorf("count = %+v", count)
+	}
+	if !report.StateKeys["flag"].IsUsed {
+		t.Error("flag is used from a Go string literal")
+	}
+}
+
+func TestPruneDefaultState(t *testing.T) {
+	t.Cleanup(func() {
+		prunedKeysMu.Lock()
+		prunedKeys = nil
+		prunedKeysMu.Unlock()
+	})
+
+	defaults := map[string]interface{}{"count": 0, "legacy": 1}
+	if got := PruneDefaultState(defaults); !reflect.DeepEqual(got, defaults) {
+		t.Fatalf("nothing registered: got %v", got)
+	}
+
+	RegisterPrunedKeys("legacy")
+	got := PruneDefaultState(defaults)
+	if want := map[string]interface{}{"count": 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("PruneDefaultState = %v, want %v", got, want)
+	}
+	if _, ok := defaults["legacy"]; !ok {
+		t.Fatal("PruneDefaultState modified its argument")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -71,7 +72,11 @@ type PruningReport struct {
 	EstimatedSavings int                   `json:"estimatedSavings"`
 	StateUsage       map[string]StateUsage `json:"stateUsage"`
 	PrunedFiles      []string              `json:"prunedFiles"`
-	Errors           []string              `json:"errors,omitempty"`
+	// StateKeys are the keys of Config.DefaultState literals.
+	StateKeys map[string]StateKey `json:"stateKeys,omitempty"`
+	// UnusedStateKeys are the StateKeys no component references, sorted.
+	UnusedStateKeys []string `json:"unusedStateKeys,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
 }
 
 // StatePruner analyzes and prunes unused state.
@@ -83,6 +88,13 @@ type StatePruner struct {
 	stateVars map[string]StateUsage
 	usedVars  map[string]bool
 	report    *PruningReport
+
+	// DefaultState keys, the positions of their literals, and the Go
+	// strings and component files that may reference them.
+	stateKeys      map[string]StateKey
+	keyPos         map[token.Pos]bool
+	stringRefs     []stringRef
+	componentFiles []string
 }
 
 // NewStatePruner creates a new state pruner.
@@ -94,7 +106,10 @@ func NewStatePruner(config PruningConfig) *StatePruner {
 		usedVars:  make(map[string]bool),
 		report: &PruningReport{
 			StateUsage: make(map[string]StateUsage),
+			StateKeys:  make(map[string]StateKey),
 		},
+		stateKeys: make(map[string]StateKey),
+		keyPos:    make(map[token.Pos]bool),
 	}
 }
 
@@ -107,6 +122,14 @@ func (sp *StatePruner) Analyze() (*PruningReport, error) {
 		}
 
 		if info.IsDir() {
+			if sp.skipDir(path, info) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isComponentFile(path) {
+			sp.componentFiles = append(sp.componentFiles, path)
 			return nil
 		}
 
@@ -125,6 +148,7 @@ func (sp *StatePruner) Analyze() (*PruningReport, error) {
 
 	// Mark used variables
 	sp.markUsedVariables()
+	sp.markUsedStateKeys()
 
 	// Calculate statistics
 	sp.calculateStatistics()
@@ -171,6 +195,14 @@ func (sp *StatePruner) analyzeFile(path string) error {
 			sp.processIdent(decl, path)
 		case *ast.SelectorExpr:
 			sp.processSelectorExpr(decl, path)
+		case *ast.KeyValueExpr:
+			sp.processDefaultState(decl, path)
+		case *ast.BasicLit:
+			if decl.Kind == token.STRING {
+				if value, err := strconv.Unquote(decl.Value); err == nil {
+					sp.stringRefs = append(sp.stringRefs, stringRef{value: value, pos: decl.Pos()})
+				}
+			}
 		}
 		return true
 	})
@@ -0,0 +1,102 @@
+package gospa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/store"
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+const (
+	// stateReapLockKey lets one process of a deployment reap per interval.
+	stateReapLockKey = "gospa:lock:state-reap"
+	// defaultStateReapInterval is used when StateReapInterval is unset.
+	defaultStateReapInterval = time.Hour
+)
+
+// PurgeClientState removes the persisted state and session tokens of
+// clients not seen within olderThan. It needs StateRetention to be set,
+// since only then is client activity tracked.
+func (a *App) PurgeClientState(ctx context.Context, olderThan time.Duration) (fiber.PurgeResult, error) {
+	if a.Config.StateRetention <= 0 {
+		return fiber.PurgeResult{}, errors.New("gospa: PurgeClientState requires Config.StateRetention")
+	}
+	return a.stateStores.PurgeInactive(ctx, olderThan)
+}
+
+// startStateReaper purges clients not seen within StateRetention every
+// StateReapInterval until the app shuts down.
+func (a *App) startStateReaper() {
+	if a.Config.StateRetention <= 0 || a.Config.RequestMode {
+		return
+	}
+	interval := a.Config.StateReapInterval
+	if interval <= 0 {
+		interval = defaultStateReapInterval
+	}
+	go func() {
+		ctx := a.Context()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.reapClientState(ctx, interval)
+			}
+		}
+	}()
+}
+
+// reapClientState runs one reaper pass. Clients connected to this process
+// are touched first so a long-lived connection is never reaped.
+func (a *App) reapClientState(ctx context.Context, interval time.Duration) {
+	if a.Hub != nil {
+		a.Hub.Range(func(c *fiber.WSClient) bool {
+			a.stateStores.Touch(c.SessionID)
+			return true
+		})
+	}
+	// The lock is left to expire so other processes skip this interval.
+	if _, err := store.TryLock(ctx, a.Config.Storage, stateReapLockKey, interval); err != nil && !errors.Is(err, store.ErrLocksUnsupported) {
+		if !errors.Is(err, store.ErrLockHeld) {
+			a.Logger().Warn("state reaper: lock failed", "err", err)
+		}
+		return
+	}
+	res, err := a.PurgeClientState(ctx, a.Config.StateRetention)
+	if err != nil {
+		a.Logger().Warn("state reaper: purge failed", "err", err)
+		return
+	}
+	if res.Purged > 0 {
+		a.Logger().Info("state reaper: purged inactive clients", "purged", res.Purged, "sessions", res.Sessions, "scanned", res.Scanned)
+	}
+}
+
+// handleStatePurge purges clients not seen within the olderThan query
+// duration, defaulting to StateRetention.
+func (a *App) handleStatePurge(c fiberpkg.Ctx) error {
+	olderThan := a.Config.StateRetention
+	if v := c.Query("olderThan"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return a.sendError(c, NewError(CodeInvalidStatePurge, fiberpkg.StatusBadRequest, "olderThan must be a positive duration"))
+		}
+		olderThan = d
+	}
+	res, err := a.PurgeClientState(c.Context(), olderThan)
+	if err != nil {
+		return a.sendError(c, NewError(CodeStatePurgeFailed, fiberpkg.StatusInternalServerError, "State purge failed").Wrap(err))
+	}
+	return c.JSON(fiberpkg.Map{
+		"ok":       true,
+		"scanned":  res.Scanned,
+		"purged":   res.Purged,
+		"sessions": res.Sessions,
+	})
+}
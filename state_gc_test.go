@@ -0,0 +1,101 @@
+package gospa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestStatePurgeEndpoint(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), DevMode: true, StateRetention: time.Hour})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/state/purge?olderThan=720h", nil)
+	addValidCSRF(req)
+	res, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", res.StatusCode)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["ok"] != true {
+		t.Fatalf("body = %#v", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/_gospa/state/purge?olderThan=soon", nil)
+	addValidCSRF(req)
+	res, err = app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 for a bad duration", res.StatusCode)
+	}
+}
+
+func TestStatePurgeEndpointNeedsAuthInProduction(t *testing.T) {
+	app := New(Config{RoutesDir: t.TempDir(), PublicOrigin: "http://localhost", StateRetention: time.Hour})
+	app.applyPluginMiddleware()
+	app.setupRoutes()
+	defer func() { _ = app.Fiber.Shutdown() }()
+
+	req := httptest.NewRequest(http.MethodPost, "/_gospa/state/purge", strings.NewReader(""))
+	addValidCSRF(req)
+	res, err := app.Fiber.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if res.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", res.StatusCode)
+	}
+}
+
+func TestTenantClientStateIsPurged(t *testing.T) {
+	app := New(Config{RoutesFS: fstest.MapFS{}, DevMode: true, StateRetention: time.Hour})
+	defer func() { _ = app.Shutdown() }()
+	docs, err := app.NewTenant("gc.example.com", Config{RoutesFS: fstest.MapFS{}, DevMode: true})
+	if err != nil {
+		t.Fatalf("NewTenant: %v", err)
+	}
+	if docs.Config.StateRetention != time.Hour {
+		t.Fatalf("tenant StateRetention = %v, want the parent's", docs.Config.StateRetention)
+	}
+
+	stores := docs.StateStores()
+	token, err := stores.Sessions.CreateSession("gc-client")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	stores.ClientState.Save("gc-client", docs.StateMap)
+	stores.Touch("gc-client")
+	time.Sleep(5 * time.Millisecond)
+
+	// Purging the parent's or the global stores leaves the tenant alone.
+	if res, err := app.PurgeClientState(context.Background(), time.Millisecond); err != nil || res.Purged != 0 {
+		t.Fatalf("parent purge = %+v, %v", res, err)
+	}
+	res, err := docs.PurgeClientState(context.Background(), time.Millisecond)
+	if err != nil || res.Purged != 1 || res.Sessions != 1 {
+		t.Fatalf("tenant purge = %+v, %v", res, err)
+	}
+	if _, ok := stores.ClientState.Get("gc-client"); ok {
+		t.Fatal("purged tenant client state is still stored")
+	}
+	if _, ok := stores.Sessions.ValidateSession(token); ok {
+		t.Fatal("purged tenant session is still valid")
+	}
+}
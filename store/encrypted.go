@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// minEncryptionKeyLen is the minimum accepted key length in bytes.
+const minEncryptionKeyLen = 32
+
+// ErrDecrypt is returned by Get of an Encrypted Storage when a value was not
+// sealed by any of its keys, or was changed or moved to another key since.
+var ErrDecrypt = errors.New("store: value cannot be decrypted")
+
+// encryptionKey is the key material derived from one Encrypted key.
+type encryptionKey struct {
+	id   [4]byte
+	aead cipher.AEAD
+}
+
+// Encrypted returns a Storage that AES-GCM encrypts every value before
+// passing it to inner and decrypts it on Get, so the backend never holds
+// plaintext. Each value is bound to its key: a value copied to another key
+// fails to decrypt. Keys must be at least 32 bytes.
+//
+// key seals new values; oldKeys are only used to open values written before
+// a rotation. Values are sealed with key again when they are next written;
+// keep an old key until the values it sealed have expired.
+//
+// Set members and key names are stored in the clear. The result implements
+// SetStorage and LockStorage when inner implements both, but never
+// CounterStorage, CASStorage or BatchStorage: counters must stay readable by
+// the backend and ciphertexts cannot be compared.
+func Encrypted(inner Storage, key []byte, oldKeys ...[]byte) (Storage, error) {
+	e := &encryptedStorage{s: inner}
+	for i, secret := range append([][]byte{key}, oldKeys...) {
+		if len(secret) < minEncryptionKeyLen {
+			return nil, fmt.Errorf("store: encryption key %d must be at least %d bytes", i, minEncryptionKeyLen)
+		}
+		block, err := aes.NewCipher(deriveEncryptionKey(secret, "gospa-storage-enc"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		var id [4]byte
+		copy(id[:], deriveEncryptionKey(secret, "gospa-storage-id"))
+		e.keys = append(e.keys, encryptionKey{id: id, aead: aead})
+	}
+	_, sets := inner.(SetStorage)
+	_, locks := inner.(LockStorage)
+	if sets && locks {
+		return &encryptedFullStorage{e}, nil
+	}
+	return e, nil
+}
+
+func deriveEncryptionKey(secret []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+type encryptedStorage struct {
+	s    Storage
+	keys []encryptionKey
+}
+
+// seal encrypts val for key with the active key: key ID, nonce, ciphertext.
+func (e *encryptedStorage) seal(key string, val []byte) ([]byte, error) {
+	k := e.keys[0]
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(k.id)+len(nonce)+len(val)+k.aead.Overhead())
+	out = append(out, k.id[:]...)
+	out = append(out, nonce...)
+	return k.aead.Seal(out, nonce, val, []byte(key)), nil
+}
+
+// open decrypts a value sealed by seal with any of the keys.
+func (e *encryptedStorage) open(key string, data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, ErrDecrypt
+	}
+	for _, k := range e.keys {
+		if !hmac.Equal(data[:4], k.id[:]) {
+			continue
+		}
+		body := data[4:]
+		if len(body) < k.aead.NonceSize() {
+			return nil, ErrDecrypt
+		}
+		nonce, ciphertext := body[:k.aead.NonceSize()], body[k.aead.NonceSize():]
+		val, err := k.aead.Open(nil, nonce, ciphertext, []byte(key))
+		if err != nil {
+			return nil, ErrDecrypt
+		}
+		return val, nil
+	}
+	return nil, ErrDecrypt
+}
+
+func (e *encryptedStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := e.s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return e.open(key, data)
+}
+
+func (e *encryptedStorage) Set(ctx context.Context, key string, val []byte, exp time.Duration) error {
+	data, err := e.seal(key, val)
+	if err != nil {
+		return err
+	}
+	return e.s.Set(ctx, key, data, exp)
+}
+
+func (e *encryptedStorage) Delete(ctx context.Context, key string) error {
+	return e.s.Delete(ctx, key)
+}
+
+func (e *encryptedStorage) Unwrap() Storage {
+	return e.s
+}
+
+// encryptedFullStorage adds SetStorage and LockStorage to encryptedStorage.
+type encryptedFullStorage struct {
+	*encryptedStorage
+}
+
+func (e *encryptedFullStorage) SAdd(ctx context.Context, key string, member string, exp time.Duration) error {
+	return e.s.(SetStorage).SAdd(ctx, key, member, exp)
+}
+
+func (e *encryptedFullStorage) SRem(ctx context.Context, key string, member string) error {
+	return e.s.(SetStorage).SRem(ctx, key, member)
+}
+
+func (e *encryptedFullStorage) SMembers(ctx context.Context, key string) ([]string, error) {
+	return e.s.(SetStorage).SMembers(ctx, key)
+}
+
+func (e *encryptedFullStorage) SetNX(ctx context.Context, key string, val []byte, exp time.Duration) (bool, error) {
+	data, err := e.seal(key, val)
+	if err != nil {
+		return false, err
+	}
+	return e.s.(LockStorage).SetNX(ctx, key, data, exp)
+}
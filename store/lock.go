@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// lockRetryInterval is how often AcquireLock retries a held lock.
+const lockRetryInterval = 50 * time.Millisecond
+
+var (
+	// ErrLockHeld is returned by TryLock when another owner holds the lock.
+	ErrLockHeld = errors.New("store: lock is held")
+	// ErrLockLost is returned by Refresh and Unlock when the lock expired and
+	// may have been taken by another owner since.
+	ErrLockLost = errors.New("store: lock lost")
+	// ErrLocksUnsupported is returned when the Storage does not implement
+	// both LockStorage and CASStorage.
+	ErrLocksUnsupported = errors.New("store: storage does not support locks")
+)
+
+// lockStorage is what a Lock needs from its Storage.
+type lockStorage interface {
+	LockStorage
+	CASStorage
+}
+
+// Lock is a lock held on a Storage key, excluding every process that shares
+// the backend. It expires after its TTL unless refreshed, so a crashed owner
+// cannot hold it forever; owners doing long work must call Refresh in time.
+type Lock struct {
+	storage lockStorage
+	key     string
+	token   []byte
+	ttl     time.Duration
+}
+
+// TryLock takes the lock key for ttl, or fails with ErrLockHeld if another
+// owner holds it. s must implement LockStorage and CASStorage, as the memory
+// and Redis stores do.
+//
+// Example:
+//
+//	lock, err := store.TryLock(ctx, storage, "lock:report", time.Minute)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock(ctx)
+func TryLock(ctx context.Context, s Storage, key string, ttl time.Duration) (*Lock, error) {
+	storage, ok := s.(lockStorage)
+	if !ok {
+		return nil, ErrLocksUnsupported
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	token := []byte(hex.EncodeToString(b[:]))
+	won, err := storage.SetNX(ctx, key, token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !won {
+		return nil, ErrLockHeld
+	}
+	return &Lock{storage: storage, key: key, token: token, ttl: ttl}, nil
+}
+
+// AcquireLock is like TryLock but waits for a held lock to be released or
+// expire, until ctx is done.
+func AcquireLock(ctx context.Context, s Storage, key string, ttl time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+	for {
+		lock, err := TryLock(ctx, s, key, ttl)
+		if !errors.Is(err, ErrLockHeld) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Key returns the Storage key of the lock.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Refresh extends the lock to its TTL from now.
+func (l *Lock) Refresh(ctx context.Context) error {
+	ok, err := l.storage.CompareAndSwap(ctx, l.key, l.token, l.token, l.ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Unlock releases the lock. It leaves a lock taken by another owner after
+// this one expired alone and returns ErrLockLost.
+func (l *Lock) Unlock(ctx context.Context) error {
+	ok, err := l.storage.CompareAndDelete(ctx, l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockLost
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// WithPrefix returns a Storage that adds prefix to every key before passing
+// it to s, so several apps can share one backend without seeing each other's
+// keys. The result implements SetStorage, LockStorage and CounterStorage when
+// s implements all three, and CASStorage and BatchStorage as well when s
+// implements all five, as the memory and Redis stores do. It has no Close
+// method: the owner of s closes it.
+func WithPrefix(s Storage, prefix string) Storage {
+	p := &prefixStorage{s: s, prefix: prefix}
+	_, sets := s.(SetStorage)
+	_, locks := s.(LockStorage)
+	_, counters := s.(CounterStorage)
+	if !sets || !locks || !counters {
+		return p
+	}
+	_, cas := s.(CASStorage)
+	_, batch := s.(BatchStorage)
+	if cas && batch {
+		return &prefixAtomicStorage{&prefixFullStorage{p}}
+	}
+	return &prefixFullStorage{p}
+}
+
+// Unwrap returns the Storage that a WithPrefix or Encrypted Storage wraps, or
+// s itself.
+func Unwrap(s Storage) Storage {
+	for {
+		u, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			return s
+		}
+		s = u.Unwrap()
+	}
+}
+
+type prefixStorage struct {
+	s      Storage
+	prefix string
+}
+
+func (p *prefixStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return p.s.Get(ctx, p.prefix+key)
+}
+
+func (p *prefixStorage) Set(ctx context.Context, key string, val []byte, exp time.Duration) error {
+	return p.s.Set(ctx, p.prefix+key, val, exp)
+}
+
+func (p *prefixStorage) Delete(ctx context.Context, key string) error {
+	return p.s.Delete(ctx, p.prefix+key)
+}
+
+func (p *prefixStorage) Unwrap() Storage {
+	return p.s
+}
+
+// prefixFullStorage adds the optional interfaces to prefixStorage.
+type prefixFullStorage struct {
+	*prefixStorage
+}
+
+func (p *prefixFullStorage) SAdd(ctx context.Context, key string, member string, exp time.Duration) error {
+	return p.s.(SetStorage).SAdd(ctx, p.prefix+key, member, exp)
+}
+
+func (p *prefixFullStorage) SRem(ctx context.Context, key string, member string) error {
+	return p.s.(SetStorage).SRem(ctx, p.prefix+key, member)
+}
+
+func (p *prefixFullStorage) SMembers(ctx context.Context, key string) ([]string, error) {
+	return p.s.(SetStorage).SMembers(ctx, p.prefix+key)
+}
+
+func (p *prefixFullStorage) SetNX(ctx context.Context, key string, val []byte, exp time.Duration) (bool, error) {
+	return p.s.(LockStorage).SetNX(ctx, p.prefix+key, val, exp)
+}
+
+func (p *prefixFullStorage) IncrBy(ctx context.Context, key string, n int64, exp time.Duration) (int64, error) {
+	return p.s.(CounterStorage).IncrBy(ctx, p.prefix+key, n, exp)
+}
+
+// prefixAtomicStorage adds CASStorage and BatchStorage to prefixFullStorage.
+type prefixAtomicStorage struct {
+	*prefixFullStorage
+}
+
+func (p *prefixAtomicStorage) CompareAndSwap(ctx context.Context, key string, old, val []byte, exp time.Duration) (bool, error) {
+	return p.s.(CASStorage).CompareAndSwap(ctx, p.prefix+key, old, val, exp)
+}
+
+func (p *prefixAtomicStorage) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	return p.s.(CASStorage).CompareAndDelete(ctx, p.prefix+key, old)
+}
+
+func (p *prefixAtomicStorage) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = p.prefix + key
+	}
+	return p.s.(BatchStorage).MGet(ctx, prefixed)
+}
+
+func (p *prefixAtomicStorage) MSet(ctx context.Context, values map[string][]byte, exp time.Duration) error {
+	prefixed := make(map[string][]byte, len(values))
+	for key, val := range values {
+		prefixed[p.prefix+key] = val
+	}
+	return p.s.(BatchStorage).MSet(ctx, prefixed, exp)
+}
+
+// WithPrefixPubSub returns a PubSub that adds prefix to every channel name,
+// so several apps can share one backend without receiving each other's
+// messages. The result implements PatternPubSub; its PSubscribe fails with
+// ErrPatternsUnsupported unless ps implements it too.
+func WithPrefixPubSub(ps PubSub, prefix string) PubSub {
+	return &prefixPubSub{ps: ps, prefix: prefix}
+}
+
+// UnwrapPubSub returns the PubSub that a WithPrefixPubSub PubSub wraps, or ps
+// itself.
+func UnwrapPubSub(ps PubSub) PubSub {
+	for {
+		u, ok := ps.(interface{ Unwrap() PubSub })
+		if !ok {
+			return ps
+		}
+		ps = u.Unwrap()
+	}
+}
+
+type prefixPubSub struct {
+	ps     PubSub
+	prefix string
+}
+
+func (p *prefixPubSub) Publish(ctx context.Context, channel string, message []byte) error {
+	return p.ps.Publish(ctx, p.prefix+channel, message)
+}
+
+func (p *prefixPubSub) Subscribe(ctx context.Context, channel string, handler func(message []byte)) (Unsubscribe, error) {
+	return p.ps.Subscribe(ctx, p.prefix+channel, handler)
+}
+
+// PSubscribe subscribes to pattern within the prefix. The channels passed to
+// handler have the prefix removed.
+func (p *prefixPubSub) PSubscribe(ctx context.Context, pattern string, handler PatternHandler) (Unsubscribe, error) {
+	ps, ok := p.ps.(PatternPubSub)
+	if !ok {
+		return nil, ErrPatternsUnsupported
+	}
+	return ps.PSubscribe(ctx, escapePattern(p.prefix)+pattern, func(ctx context.Context, msg Message) {
+		msg.Channel = strings.TrimPrefix(msg.Channel, p.prefix)
+		msg.Pattern = pattern
+		handler(ctx, msg)
+	})
+}
+
+// escapePattern quotes the glob characters of s for MatchPattern.
+func escapePattern(s string) string {
+	if !strings.ContainsAny(s, `*?[]\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[]\`, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func (p *prefixPubSub) Unwrap() PubSub {
+	return p.ps
+}
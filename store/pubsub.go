@@ -3,6 +3,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -14,19 +15,134 @@ type PubSub interface {
 	Subscribe(ctx context.Context, channel string, handler func(message []byte)) (Unsubscribe, error)
 }
 
-// Unsubscribe is a function to cancel a subscription.
+// Unsubscribe is a function to cancel a subscription. Calling it more than
+// once has no further effect. Canceling the context passed to Subscribe or
+// PSubscribe cancels the subscription as well.
 type Unsubscribe func()
 
+// Message is a message received on a pattern subscription.
+type Message struct {
+	// Channel is the channel the message was published on.
+	Channel string
+	// Pattern is the pattern of the subscription that matched it.
+	Pattern string
+	Payload []byte
+}
+
+// PatternHandler handles the messages of a pattern subscription. ctx is
+// canceled once the subscription is.
+type PatternHandler func(ctx context.Context, msg Message)
+
+// PatternPubSub is implemented by PubSubs that can subscribe to every channel
+// matching a pattern, so one subscription serves many per-room or per-client
+// channels. Patterns follow Redis PSUBSCRIBE syntax, see MatchPattern.
+type PatternPubSub interface {
+	PubSub
+	PSubscribe(ctx context.Context, pattern string, handler PatternHandler) (Unsubscribe, error)
+}
+
+// ErrPatternsUnsupported is returned by PSubscribe of a PubSub wrapping one
+// that does not implement PatternPubSub.
+var ErrPatternsUnsupported = errors.New("store: pubsub does not support pattern subscriptions")
+
+// MatchPattern reports whether channel matches pattern in Redis glob syntax:
+// '*' matches any sequence, '?' any one byte, "[abc]", "[a-z]" and "[^a]"
+// sets of bytes, and '\' escapes the byte after it.
+func MatchPattern(pattern, channel string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(channel); i++ {
+				if MatchPattern(pattern[1:], channel[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(channel) == 0 {
+				return false
+			}
+			pattern, channel = pattern[1:], channel[1:]
+		case '[':
+			if len(channel) == 0 {
+				return false
+			}
+			rest, ok := matchSet(pattern[1:], channel[0])
+			if !ok {
+				return false
+			}
+			pattern, channel = rest, channel[1:]
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(channel) == 0 || pattern[0] != channel[0] {
+				return false
+			}
+			pattern, channel = pattern[1:], channel[1:]
+		}
+	}
+	return len(channel) == 0
+}
+
+// matchSet matches c against the set that starts the pattern (after its
+// '['). It returns the pattern after the set and whether c is in it. A set
+// missing its ']' extends to the end of the pattern, as in Redis.
+func matchSet(pattern string, c byte) (string, bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+	match := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			match = match || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			match = match || (c >= lo && c <= hi)
+			pattern = pattern[3:]
+		default:
+			match = match || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:]
+	}
+	return pattern, match != negate
+}
+
 // subscriber holds a handler and a unique ID for identification.
 type subscriber struct {
 	id      uint64
 	handler func(message []byte)
 }
 
-// MemoryPubSub provides an in-memory implementation of the PubSub interface.
+// patternSubscriber is a subscriber of a PSubscribe pattern.
+type patternSubscriber struct {
+	id      uint64
+	pattern string
+	ctx     context.Context
+	handler PatternHandler
+}
+
+// MemoryPubSub provides an in-memory implementation of the PubSub and
+// PatternPubSub interfaces.
 // It is intended for single-process environments where external infrastructure is not needed.
 type MemoryPubSub struct {
 	subscribers map[string][]subscriber
+	patterns    []patternSubscriber
 	mu          sync.RWMutex
 	nextID      uint64
 }
@@ -38,13 +154,20 @@ func NewMemoryPubSub() *MemoryPubSub {
 	}
 }
 
-// Publish sends a message to all subscribers of a channel.
+// Publish sends a message to all subscribers of a channel and of the patterns
+// matching it.
 func (p *MemoryPubSub) Publish(_ context.Context, channel string, message []byte) error {
 	p.mu.RLock()
-	handlers, ok := p.subscribers[channel]
+	handlers := p.subscribers[channel]
+	var patterns []patternSubscriber
+	for _, sub := range p.patterns {
+		if MatchPattern(sub.pattern, channel) {
+			patterns = append(patterns, sub)
+		}
+	}
 	p.mu.RUnlock()
 
-	if !ok || len(handlers) == 0 {
+	if len(handlers) == 0 && len(patterns) == 0 {
 		return nil
 	}
 
@@ -55,22 +178,30 @@ func (p *MemoryPubSub) Publish(_ context.Context, channel string, message []byte
 	// Dispatch to handlers asynchronously to avoid blocking the publisher.
 	for _, sub := range handlers {
 		go func(h func(message []byte)) {
-			defer func() {
-				// Guard against consumer panics in the memory backend
-				if r := recover(); r != nil {
-					// Use a logger if available, otherwise just drop the panic
-					fmt.Printf("MemoryPubSub: consumer panicked: %v\n", r)
-				}
-			}()
+			defer recoverConsumer()
 			h(msgCopy)
 		}(sub.handler)
 	}
+	for _, sub := range patterns {
+		go func(sub patternSubscriber) {
+			defer recoverConsumer()
+			sub.handler(sub.ctx, Message{Channel: channel, Pattern: sub.pattern, Payload: msgCopy})
+		}(sub)
+	}
 
 	return nil
 }
 
+// recoverConsumer guards against consumer panics in the memory backend.
+func recoverConsumer() {
+	if r := recover(); r != nil {
+		// Use a logger if available, otherwise just drop the panic
+		fmt.Printf("MemoryPubSub: consumer panicked: %v\n", r)
+	}
+}
+
 // Subscribe registers a handler and returns an Unsubscribe function.
-func (p *MemoryPubSub) Subscribe(_ context.Context, channel string, handler func(message []byte)) (Unsubscribe, error) {
+func (p *MemoryPubSub) Subscribe(ctx context.Context, channel string, handler func(message []byte)) (Unsubscribe, error) {
 	id := atomic.AddUint64(&p.nextID, 1)
 	sub := subscriber{id: id, handler: handler}
 
@@ -78,15 +209,54 @@ func (p *MemoryPubSub) Subscribe(_ context.Context, channel string, handler func
 	p.subscribers[channel] = append(p.subscribers[channel], sub)
 	p.mu.Unlock()
 
-	return func() {
+	return p.unsubscribeOnDone(ctx, func() {
 		p.mu.Lock()
 		defer p.mu.Unlock()
 		handlers := p.subscribers[channel]
 		for i, s := range handlers {
 			if s.id == id {
-				p.subscribers[channel] = append(handlers[:i], handlers[i+1:]...)
+				p.subscribers[channel] = append(handlers[:i:i], handlers[i+1:]...)
 				break
 			}
 		}
-	}, nil
+		if len(p.subscribers[channel]) == 0 {
+			delete(p.subscribers, channel)
+		}
+	}), nil
+}
+
+// PSubscribe registers a handler for every channel matching pattern and
+// returns an Unsubscribe function.
+func (p *MemoryPubSub) PSubscribe(ctx context.Context, pattern string, handler PatternHandler) (Unsubscribe, error) {
+	id := atomic.AddUint64(&p.nextID, 1)
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := patternSubscriber{id: id, pattern: pattern, ctx: subCtx, handler: handler}
+
+	p.mu.Lock()
+	p.patterns = append(p.patterns, sub)
+	p.mu.Unlock()
+
+	return p.unsubscribeOnDone(subCtx, func() {
+		cancel()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for i, s := range p.patterns {
+			if s.id == id {
+				p.patterns = append(p.patterns[:i:i], p.patterns[i+1:]...)
+				break
+			}
+		}
+	}), nil
+}
+
+// unsubscribeOnDone makes remove run once, when the returned Unsubscribe is
+// called or ctx is done, whichever comes first.
+func (p *MemoryPubSub) unsubscribeOnDone(ctx context.Context, remove func()) Unsubscribe {
+	var once sync.Once
+	unsub := func() { once.Do(remove) }
+	stop := context.AfterFunc(ctx, unsub)
+	return func() {
+		stop()
+		unsub()
+	}
 }
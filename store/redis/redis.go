@@ -37,12 +37,139 @@ func (s *Store) Set(ctx context.Context, key string, val []byte, exp time.Durati
 	return s.client.Set(ctx, key, val, exp).Err()
 }
 
+// SetNX stores a key in Redis only if it does not exist.
+func (s *Store) SetNX(ctx context.Context, key string, val []byte, exp time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, val, exp).Result()
+}
+
+// IncrBy adds n to the Redis counter at key and extends its expiration to exp.
+func (s *Store) IncrBy(ctx context.Context, key string, n int64, exp time.Duration) (int64, error) {
+	var incr *goredis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, key, n)
+		if exp > 0 {
+			pipe.PExpire(ctx, key, exp)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+var compareAndSwapScript = goredis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if ARGV[1] == "1" then
+  if current then return 0 end
+elseif current ~= ARGV[2] then
+  return 0
+end
+local ttl_ms = tonumber(ARGV[4])
+if ttl_ms > 0 then
+  redis.call("SET", KEYS[1], ARGV[3], "PX", ttl_ms)
+else
+  redis.call("SET", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+var compareAndDeleteScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// CompareAndSwap stores val at key if it holds old, or is absent when old is
+// nil, in one Lua script.
+func (s *Store) CompareAndSwap(ctx context.Context, key string, old, val []byte, exp time.Duration) (bool, error) {
+	absent := "0"
+	if old == nil {
+		absent = "1"
+	}
+	result, err := compareAndSwapScript.Run(ctx, s.client, []string{key}, absent, old, val, exp.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// CompareAndDelete deletes key if it holds old, in one Lua script.
+func (s *Store) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	result, err := compareAndDeleteScript.Run(ctx, s.client, []string{key}, old).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// MGet retrieves several keys from Redis in one command.
+func (s *Store) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	results, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	vals := make([][]byte, len(results))
+	for i, r := range results {
+		if v, ok := r.(string); ok {
+			vals[i] = []byte(v)
+		}
+	}
+	return vals, nil
+}
+
+// MSet stores several keys in Redis in a single MULTI/EXEC transaction.
+func (s *Store) MSet(ctx context.Context, values map[string][]byte, exp time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for key, val := range values {
+			pipe.Set(ctx, key, val, exp)
+		}
+		return nil
+	})
+	return err
+}
+
 // Delete removes a key from Redis.
 func (s *Store) Delete(ctx context.Context, key string) error {
 	return s.client.Del(ctx, key).Err()
 }
 
-// PubSub provides a Redis-backed implementation of the store.PubSub interface.
+// SAdd adds member to the Redis set at key and extends its expiration to exp
+// in a single MULTI/EXEC transaction.
+func (s *Store) SAdd(ctx context.Context, key string, member string, exp time.Duration) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.SAdd(ctx, key, member)
+		if exp > 0 {
+			pipe.PExpire(ctx, key, exp)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// SRem removes member from the Redis set at key.
+func (s *Store) SRem(ctx context.Context, key string, member string) error {
+	return s.client.SRem(ctx, key, member).Err()
+}
+
+// SMembers returns the members of the Redis set at key.
+func (s *Store) SMembers(ctx context.Context, key string) ([]string, error) {
+	return s.client.SMembers(ctx, key).Result()
+}
+
+// PubSub provides a Redis-backed implementation of the store.PubSub and
+// store.PatternPubSub interfaces.
 type PubSub struct {
 	client *goredis.Client
 }
@@ -112,6 +239,46 @@ func (p *PubSub) SubscribeWithContext(ctx context.Context, channel string, handl
 	return nil
 }
 
+// PSubscribe subscribes to every Redis channel matching pattern and invokes
+// the handler for each message. The handler's context is canceled once the
+// subscription is. Returns an unsubscribe function to stop the subscription.
+func (p *PubSub) PSubscribe(ctx context.Context, pattern string, handler store.PatternHandler) (store.Unsubscribe, error) {
+	subCtx, cancel := context.WithCancel(ctx)
+	pubsub := p.client.PSubscribe(subCtx, pattern)
+
+	// Wait for confirmation that subscription is created
+	if _, err := pubsub.Receive(subCtx); err != nil {
+		_ = pubsub.Close()
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Guard against consumer panics from crashing the whole process
+				fmt.Printf("Redis PubSub: consumer panicked: %v\n", r)
+			}
+			_ = pubsub.Close()
+		}()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				handler(subCtx, store.Message{Channel: msg.Channel, Pattern: msg.Pattern, Payload: []byte(msg.Payload)})
+			}
+		}
+	}()
+
+	return store.Unsubscribe(cancel), nil
+}
+
 var consumeRateLimitTokenScript = goredis.NewScript(`
 local key = KEYS[1]
 local now = tonumber(ARGV[1])
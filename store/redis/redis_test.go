@@ -51,6 +51,121 @@ func TestStoreGetSetDelete(t *testing.T) {
 	}
 }
 
+func TestStoreSetNX(t *testing.T) {
+	_, client := newTestRedis(t)
+	s := NewStore(client)
+	ctx := context.Background()
+
+	var _ store.LockStorage = s
+	if ok, err := s.SetNX(ctx, "lock", []byte("a"), time.Minute); !ok || err != nil {
+		t.Fatalf("first SetNX = %v, %v; want true", ok, err)
+	}
+	if ok, err := s.SetNX(ctx, "lock", []byte("b"), time.Minute); ok || err != nil {
+		t.Fatalf("second SetNX = %v, %v; want false", ok, err)
+	}
+}
+
+func TestStoreIncrBy(t *testing.T) {
+	srv, client := newTestRedis(t)
+	s := NewStore(client)
+	ctx := context.Background()
+
+	var _ store.CounterStorage = s
+	if n, err := s.IncrBy(ctx, "hits", 2, time.Minute); n != 2 || err != nil {
+		t.Fatalf("first IncrBy = %d, %v; want 2", n, err)
+	}
+	if n, _ := s.IncrBy(ctx, "hits", 3, time.Minute); n != 5 {
+		t.Fatalf("second IncrBy = %d, want 5", n)
+	}
+	if got, _ := s.Get(ctx, "hits"); string(got) != "5" {
+		t.Fatalf("Get = %q, want 5", got)
+	}
+	if ttl := srv.TTL("hits"); ttl <= 0 {
+		t.Fatalf("TTL = %v, want an expiration", ttl)
+	}
+}
+
+func TestStoreSets(t *testing.T) {
+	srv, client := newTestRedis(t)
+	s := NewStore(client)
+	ctx := context.Background()
+
+	var _ store.SetStorage = s
+	if err := s.SAdd(ctx, "set", "a", time.Minute); err != nil {
+		t.Fatalf("SAdd failed: %v", err)
+	}
+	if err := s.SAdd(ctx, "set", "b", time.Minute); err != nil {
+		t.Fatalf("SAdd failed: %v", err)
+	}
+	if ttl := srv.TTL("set"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected set TTL to be refreshed, got %v", ttl)
+	}
+
+	members, err := s.SMembers(ctx, "set")
+	if err != nil || len(members) != 2 {
+		t.Fatalf("SMembers = %v, %v; want 2 members", members, err)
+	}
+	if err := s.SRem(ctx, "set", "a"); err != nil {
+		t.Fatalf("SRem failed: %v", err)
+	}
+	if members, _ := s.SMembers(ctx, "set"); len(members) != 1 || members[0] != "b" {
+		t.Fatalf("after SRem: %v", members)
+	}
+}
+
+func TestStoreCompareAndSwap(t *testing.T) {
+	srv, client := newTestRedis(t)
+	s := NewStore(client)
+	ctx := context.Background()
+
+	if ok, err := s.CompareAndSwap(ctx, "k", nil, []byte("a"), time.Minute); err != nil || !ok {
+		t.Fatalf("CompareAndSwap from absent = %v, %v", ok, err)
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "k", nil, []byte("b"), 0); ok {
+		t.Fatal("CompareAndSwap from absent replaced a value")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "k", []byte("a"), []byte("b"), time.Minute); !ok {
+		t.Fatal("CompareAndSwap failed on a matching value")
+	}
+	if ttl := srv.TTL("k"); ttl <= 0 {
+		t.Fatalf("CompareAndSwap did not set the expiration, TTL %v", ttl)
+	}
+	if ok, _ := s.CompareAndDelete(ctx, "k", []byte("a")); ok {
+		t.Fatal("CompareAndDelete deleted a different value")
+	}
+	if ok, _ := s.CompareAndDelete(ctx, "k", []byte("b")); !ok {
+		t.Fatal("CompareAndDelete failed on a matching value")
+	}
+
+	lock, err := store.TryLock(ctx, s, "lock", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if _, err := store.TryLock(ctx, s, "lock", time.Minute); err != store.ErrLockHeld {
+		t.Fatalf("second TryLock = %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+}
+
+func TestStoreBatch(t *testing.T) {
+	_, client := newTestRedis(t)
+	s := NewStore(client)
+	ctx := context.Background()
+
+	if err := s.MSet(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Minute); err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+	vals, err := s.MGet(ctx, []string{"a", "missing", "b"})
+	if err != nil {
+		t.Fatalf("MGet failed: %v", err)
+	}
+	if len(vals) != 3 || string(vals[0]) != "1" || vals[1] != nil || string(vals[2]) != "2" {
+		t.Fatalf("MGet = %q", vals)
+	}
+}
+
 func TestPubSubPublishSubscribe(t *testing.T) {
 	_, client := newTestRedis(t)
 	p := NewPubSub(client)
@@ -80,6 +195,36 @@ func TestPubSubPublishSubscribe(t *testing.T) {
 	}
 }
 
+func TestPubSubPSubscribe(t *testing.T) {
+	_, client := newTestRedis(t)
+	p := NewPubSub(client)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	received := make(chan store.Message, 1)
+	unsub, err := p.PSubscribe(ctx, "room:*", func(_ context.Context, msg store.Message) {
+		received <- msg
+	})
+	if err != nil {
+		t.Fatalf("PSubscribe failed: %v", err)
+	}
+	defer unsub()
+
+	_ = p.Publish(ctx, "lobby", []byte("skipped"))
+	if err := p.Publish(ctx, "room:42", []byte("hello")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.Channel != "room:42" || msg.Pattern != "room:*" || string(msg.Payload) != "hello" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for pubsub message")
+	}
+}
+
 func TestConsumeRateLimitToken(t *testing.T) {
 	_, client := newTestRedis(t)
 	s := NewStore(client)
@@ -1,9 +1,12 @@
 package store
 
 import (
+	"bytes"
 	"container/list"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -18,10 +21,59 @@ type Storage interface {
 	Delete(ctx context.Context, key string) error
 }
 
+// SetStorage is implemented by backends that can keep string sets alongside
+// plain keys, used for secondary indexes such as the session tokens issued to
+// one client. Each operation must be atomic on its own; the set's expiration
+// is extended to exp on every SAdd, and Delete removes a set like any key.
+type SetStorage interface {
+	SAdd(ctx context.Context, key string, member string, exp time.Duration) error
+	SRem(ctx context.Context, key string, member string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
+
+// LockStorage is implemented by backends that can store a key only if it is
+// absent, in one atomic step. Instances sharing the backend use it as a lock,
+// for example so only one of them runs a scheduled task.
+type LockStorage interface {
+	// SetNX stores val at key with expiration exp unless key already holds a
+	// live value, and reports whether it stored it.
+	SetNX(ctx context.Context, key string, val []byte, exp time.Duration) (bool, error)
+}
+
+// CounterStorage is implemented by backends that can add to an integer
+// counter in one atomic step, so instances sharing the backend never lose
+// increments. Counters are stored as decimal text and read back with Get.
+type CounterStorage interface {
+	// IncrBy adds n to the counter at key, creating it at zero if absent,
+	// extends its expiration to exp and returns the new value.
+	IncrBy(ctx context.Context, key string, n int64, exp time.Duration) (int64, error)
+}
+
+// CASStorage is implemented by backends that can replace or delete a value
+// only if it still holds what the caller last read, in one atomic step.
+type CASStorage interface {
+	// CompareAndSwap stores val at key with expiration exp if key holds old,
+	// or, with old nil, if key holds no live value. It reports whether it
+	// stored val.
+	CompareAndSwap(ctx context.Context, key string, old, val []byte, exp time.Duration) (bool, error)
+	// CompareAndDelete deletes key if it holds old and reports whether it did.
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+}
+
+// BatchStorage is implemented by backends that can read and write several
+// keys in one round trip.
+type BatchStorage interface {
+	// MGet returns the values of keys in order, nil for missing keys.
+	MGet(ctx context.Context, keys []string) ([][]byte, error)
+	// MSet stores every value with expiration exp, atomically.
+	MSet(ctx context.Context, values map[string][]byte, exp time.Duration) error
+}
+
 // MemoryStorage provides an in-memory implementation of the Storage interface.
 type MemoryStorage struct {
 	mu         sync.RWMutex
 	store      map[string]memoryEntry
+	sets       map[string]memorySet
 	stop       chan struct{}
 	once       sync.Once
 	maxEntries int // Max entries for zero-TTL keys to prevent unbounded growth
@@ -38,10 +90,17 @@ type memoryEntry struct {
 	exp time.Time
 }
 
+// memorySet is a string set with an optional expiration time.
+type memorySet struct {
+	members map[string]struct{}
+	exp     time.Time
+}
+
 // NewMemoryStorage creates a new in-memory storage.
 func NewMemoryStorage() *MemoryStorage {
 	s := &MemoryStorage{
 		store:       make(map[string]memoryEntry),
+		sets:        make(map[string]memorySet),
 		stop:        make(chan struct{}),
 		maxEntries:  10000,
 		lru:         list.New(),
@@ -91,7 +150,101 @@ func (s *MemoryStorage) Get(_ context.Context, key string) ([]byte, error) {
 func (s *MemoryStorage) Set(_ context.Context, key string, val []byte, exp time.Duration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.setLocked(key, val, exp)
+	return nil
+}
+
+// SetNX stores a value unless key holds one that has not expired.
+func (s *MemoryStorage) SetNX(_ context.Context, key string, val []byte, exp time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, exists := s.store[key]; exists && (entry.exp.IsZero() || time.Now().Before(entry.exp)) {
+		return false, nil
+	}
+	s.setLocked(key, val, exp)
+	return true, nil
+}
+
+// IncrBy adds n to the decimal counter at key.
+func (s *MemoryStorage) IncrBy(_ context.Context, key string, n int64, exp time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var current int64
+	if entry, exists := s.store[key]; exists && (entry.exp.IsZero() || time.Now().Before(entry.exp)) {
+		v, err := strconv.ParseInt(string(entry.val), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("store: value at %q is not a counter", key)
+		}
+		current = v
+	}
+	current += n
+	s.setLocked(key, []byte(strconv.FormatInt(current, 10)), exp)
+	return current, nil
+}
+
+// CompareAndSwap stores val if key holds old, or no live value when old is
+// nil.
+func (s *MemoryStorage) CompareAndSwap(_ context.Context, key string, old, val []byte, exp time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, exists := s.liveLocked(key)
+	if old == nil && exists || old != nil && (!exists || !bytes.Equal(current, old)) {
+		return false, nil
+	}
+	s.setLocked(key, bytes.Clone(val), exp)
+	return true, nil
+}
+
+// CompareAndDelete deletes key if it holds old.
+func (s *MemoryStorage) CompareAndDelete(_ context.Context, key string, old []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, exists := s.liveLocked(key)
+	if !exists || !bytes.Equal(current, old) {
+		return false, nil
+	}
+	if s.store[key].exp.IsZero() {
+		s.removeFromLRU(key)
+	}
+	delete(s.store, key)
+	return true, nil
+}
+
+// MGet returns the values of keys, nil for missing ones.
+func (s *MemoryStorage) MGet(_ context.Context, keys []string) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vals := make([][]byte, len(keys))
+	for i, key := range keys {
+		if val, ok := s.liveLocked(key); ok {
+			vals[i] = bytes.Clone(val)
+		}
+	}
+	return vals, nil
+}
+
+// MSet stores every value under one lock.
+func (s *MemoryStorage) MSet(_ context.Context, values map[string][]byte, exp time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, val := range values {
+		s.setLocked(key, bytes.Clone(val), exp)
+	}
+	return nil
+}
 
+// liveLocked returns the value at key unless it is missing or expired; s.mu
+// must be held.
+func (s *MemoryStorage) liveLocked(key string) ([]byte, bool) {
+	entry, exists := s.store[key]
+	if !exists || !entry.exp.IsZero() && !time.Now().Before(entry.exp) {
+		return nil, false
+	}
+	return entry.val, true
+}
+
+// setLocked stores a value; s.mu must be held.
+func (s *MemoryStorage) setLocked(key string, val []byte, exp time.Duration) {
 	var expiration time.Time
 	if exp > 0 {
 		expiration = time.Now().Add(exp)
@@ -117,7 +270,6 @@ func (s *MemoryStorage) Set(_ context.Context, key string, val []byte, exp time.
 	}
 
 	s.store[key] = memoryEntry{val: val, exp: expiration}
-	return nil
 }
 
 // Delete removes a value from the in-memory store.
@@ -131,9 +283,64 @@ func (s *MemoryStorage) Delete(_ context.Context, key string) error {
 		}
 		delete(s.store, key)
 	}
+	delete(s.sets, key)
+	return nil
+}
+
+// SAdd adds member to the set at key and extends the set's expiration to exp.
+func (s *MemoryStorage) SAdd(_ context.Context, key string, member string, exp time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, exists := s.sets[key]
+	if !exists || set.expired(time.Now()) {
+		set = memorySet{members: make(map[string]struct{})}
+	}
+	set.members[member] = struct{}{}
+	if exp > 0 {
+		set.exp = time.Now().Add(exp)
+	} else {
+		set.exp = time.Time{}
+	}
+	s.sets[key] = set
 	return nil
 }
 
+// SRem removes member from the set at key, dropping the set once it is empty.
+func (s *MemoryStorage) SRem(_ context.Context, key string, member string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if set, exists := s.sets[key]; exists {
+		delete(set.members, member)
+		if len(set.members) == 0 {
+			delete(s.sets, key)
+		}
+	}
+	return nil
+}
+
+// SMembers returns the members of the set at key, or none if it is missing or
+// expired.
+func (s *MemoryStorage) SMembers(_ context.Context, key string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, exists := s.sets[key]
+	if !exists || set.expired(time.Now()) {
+		return nil, nil
+	}
+	members := make([]string, 0, len(set.members))
+	for m := range set.members {
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (set memorySet) expired(now time.Time) bool {
+	return !set.exp.IsZero() && now.After(set.exp)
+}
+
 func (s *MemoryStorage) removeFromLRU(key string) {
 	if el, ok := s.lruElements[key]; ok {
 		s.lru.Remove(el)
@@ -185,13 +392,29 @@ func (s *MemoryStorage) prune() {
 			break
 		}
 	}
+	var expiredSets []string
+	i = 0
+	for key, set := range s.sets {
+		if set.expired(now) {
+			expiredSets = append(expiredSets, key)
+		}
+		i++
+		if i >= maxScan {
+			break
+		}
+	}
 	s.mu.RUnlock()
 
-	if len(expired) > 0 {
+	if len(expired) > 0 || len(expiredSets) > 0 {
 		s.mu.Lock()
 		for _, key := range expired {
 			delete(s.store, key)
 		}
+		for _, key := range expiredSets {
+			if set, ok := s.sets[key]; ok && set.expired(now) {
+				delete(s.sets, key)
+			}
+		}
 		s.mu.Unlock()
 	}
 }
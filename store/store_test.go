@@ -106,6 +106,182 @@ func TestMemoryStorage_Expiry(t *testing.T) {
 	}
 }
 
+func TestMemoryStorage_Sets(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	var _ SetStorage = s
+	_ = s.SAdd(ctx, "set", "a", 0)
+	_ = s.SAdd(ctx, "set", "b", 0)
+	_ = s.SAdd(ctx, "set", "a", 0)
+	members, err := s.SMembers(ctx, "set")
+	if err != nil || len(members) != 2 {
+		t.Fatalf("SMembers = %v, %v; want 2 members", members, err)
+	}
+
+	_ = s.SRem(ctx, "set", "a")
+	if members, _ := s.SMembers(ctx, "set"); len(members) != 1 || members[0] != "b" {
+		t.Fatalf("after SRem: %v", members)
+	}
+
+	_ = s.Delete(ctx, "set")
+	if members, _ := s.SMembers(ctx, "set"); len(members) != 0 {
+		t.Fatalf("after Delete: %v", members)
+	}
+
+	_ = s.SAdd(ctx, "expiring", "x", 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if members, _ := s.SMembers(ctx, "expiring"); len(members) != 0 {
+		t.Fatalf("expired set returned %v", members)
+	}
+}
+
+func TestMemoryStorage_SetNX(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	var _ LockStorage = s
+	if ok, err := s.SetNX(ctx, "lock", []byte("a"), 50*time.Millisecond); !ok || err != nil {
+		t.Fatalf("first SetNX = %v, %v; want true", ok, err)
+	}
+	if ok, _ := s.SetNX(ctx, "lock", []byte("b"), time.Minute); ok {
+		t.Fatal("SetNX replaced a live key")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if ok, _ := s.SetNX(ctx, "lock", []byte("c"), time.Minute); !ok {
+		t.Fatal("SetNX did not replace an expired key")
+	}
+	if got, _ := s.Get(ctx, "lock"); string(got) != "c" {
+		t.Fatalf("Get = %q, want c", got)
+	}
+}
+
+func TestMemoryStorage_IncrBy(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	var _ CounterStorage = s
+	if n, err := s.IncrBy(ctx, "hits", 2, time.Minute); n != 2 || err != nil {
+		t.Fatalf("first IncrBy = %d, %v; want 2", n, err)
+	}
+	if n, _ := s.IncrBy(ctx, "hits", 3, time.Minute); n != 5 {
+		t.Fatalf("second IncrBy = %d, want 5", n)
+	}
+	if got, _ := s.Get(ctx, "hits"); string(got) != "5" {
+		t.Fatalf("Get = %q, want 5", got)
+	}
+	_ = s.Set(ctx, "name", []byte("x"), 0)
+	if _, err := s.IncrBy(ctx, "name", 1, 0); err == nil {
+		t.Fatal("IncrBy on a non-counter value should fail")
+	}
+}
+
+func TestMemoryStorage_CompareAndSwap(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	if ok, _ := s.CompareAndSwap(ctx, "k", nil, []byte("a"), 0); !ok {
+		t.Fatal("CompareAndSwap from absent failed")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "k", nil, []byte("b"), 0); ok {
+		t.Fatal("CompareAndSwap from absent replaced a value")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "k", []byte("x"), []byte("b"), 0); ok {
+		t.Fatal("CompareAndSwap replaced a different value")
+	}
+	if ok, _ := s.CompareAndSwap(ctx, "k", []byte("a"), []byte("b"), 0); !ok {
+		t.Fatal("CompareAndSwap failed on a matching value")
+	}
+	if ok, _ := s.CompareAndDelete(ctx, "k", []byte("a")); ok {
+		t.Fatal("CompareAndDelete deleted a different value")
+	}
+	if ok, _ := s.CompareAndDelete(ctx, "k", []byte("b")); !ok {
+		t.Fatal("CompareAndDelete failed on a matching value")
+	}
+	if _, err := s.Get(ctx, "k"); err != ErrNotFound {
+		t.Fatalf("Get after CompareAndDelete: %v", err)
+	}
+
+	_ = s.Set(ctx, "old", []byte("a"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := s.CompareAndSwap(ctx, "old", nil, []byte("b"), 0); !ok {
+		t.Fatal("CompareAndSwap treated an expired value as live")
+	}
+}
+
+func TestMemoryStorage_Batch(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	if err := s.MSet(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Minute); err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+	vals, err := s.MGet(ctx, []string{"a", "missing", "b"})
+	if err != nil {
+		t.Fatalf("MGet failed: %v", err)
+	}
+	if len(vals) != 3 || string(vals[0]) != "1" || vals[1] != nil || string(vals[2]) != "2" {
+		t.Fatalf("MGet = %q", vals)
+	}
+}
+
+func TestLock(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	lock, err := TryLock(ctx, s, "lock", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if _, err := TryLock(ctx, s, "lock", time.Minute); err != ErrLockHeld {
+		t.Fatalf("second TryLock = %v, want ErrLockHeld", err)
+	}
+	if err := lock.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		next, err := AcquireLock(ctx, s, "lock", time.Minute)
+		if err == nil {
+			err = next.Unlock(ctx)
+		}
+		waited <- err
+	}()
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("AcquireLock after Unlock: %v", err)
+	}
+	if err := lock.Unlock(ctx); err != ErrLockLost {
+		t.Fatalf("Unlock of a released lock = %v, want ErrLockLost", err)
+	}
+
+	expiring, _ := TryLock(ctx, s, "short", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	other, err := TryLock(ctx, s, "short", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock after expiry: %v", err)
+	}
+	if err := expiring.Unlock(ctx); err != ErrLockLost {
+		t.Fatalf("Unlock of an expired lock = %v, want ErrLockLost", err)
+	}
+	if err := other.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock of the new owner failed: %v", err)
+	}
+
+	if _, err := TryLock(ctx, basicStorage{s}, "lock", time.Minute); err != ErrLocksUnsupported {
+		t.Fatalf("TryLock on plain storage = %v, want ErrLocksUnsupported", err)
+	}
+}
+
 func TestMemoryStorage_Overwrite(t *testing.T) {
 	s := NewMemoryStorage()
 	ctx := context.Background()
@@ -215,3 +391,250 @@ func TestMemoryPubSub_MultipleSubscribers(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryPubSub_UnsubscribeOnContextDone(t *testing.T) {
+	ps := NewMemoryPubSub()
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := ps.Subscribe(ctx, "ctx", func(_ []byte) {}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		ps.mu.RLock()
+		n := len(ps.subscribers["ctx"])
+		ps.mu.RUnlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscription outlived its context")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMemoryPubSub_PSubscribe(t *testing.T) {
+	ps := NewMemoryPubSub()
+	received := make(chan Message, 10)
+	var handlerCtx context.Context
+	var once sync.Once
+	unsub, err := ps.PSubscribe(context.Background(), "room:*", func(ctx context.Context, msg Message) {
+		once.Do(func() { handlerCtx = ctx })
+		received <- msg
+	})
+	if err != nil {
+		t.Fatalf("PSubscribe failed: %v", err)
+	}
+
+	ctx := context.Background()
+	_ = ps.Publish(ctx, "lobby", []byte("skipped"))
+	_ = ps.Publish(ctx, "room:1", []byte("hello"))
+	select {
+	case msg := <-received:
+		if msg.Channel != "room:1" || msg.Pattern != "room:*" || string(msg.Payload) != "hello" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out waiting for pattern message")
+	}
+
+	unsub()
+	if handlerCtx.Err() == nil {
+		t.Error("handler context not canceled by Unsubscribe")
+	}
+	_ = ps.Publish(ctx, "room:2", []byte("late"))
+	time.Sleep(50 * time.Millisecond)
+	if len(received) != 0 {
+		t.Errorf("received %d messages after unsubscribing", len(received))
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern, channel string
+		want             bool
+	}{
+		{"room:*", "room:1", true},
+		{"room:*", "room:", true},
+		{"room:*", "lobby", false},
+		{"*:client:*", "app:client:42", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+	for _, tc := range cases {
+		if got := MatchPattern(tc.pattern, tc.channel); got != tc.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tc.pattern, tc.channel, got, tc.want)
+		}
+	}
+}
+
+// ─── WithPrefix ───────────────────────────────────────────────────────────────
+
+// basicStorage hides the optional interfaces of the storage it wraps.
+type basicStorage struct{ Storage }
+
+func TestWithPrefix(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+	a, b := WithPrefix(s, "a:"), WithPrefix(s, "b:")
+
+	_ = a.Set(ctx, "k", []byte("1"), 0)
+	_ = b.Set(ctx, "k", []byte("2"), 0)
+	if v, _ := a.Get(ctx, "k"); string(v) != "1" {
+		t.Fatalf("a.Get = %q", v)
+	}
+	if v, _ := s.Get(ctx, "b:k"); string(v) != "2" {
+		t.Fatalf("backend b:k = %q", v)
+	}
+	_ = a.Delete(ctx, "k")
+	if _, err := a.Get(ctx, "k"); err != ErrNotFound {
+		t.Fatalf("after Delete: %v", err)
+	}
+	if v, _ := b.Get(ctx, "k"); string(v) != "2" {
+		t.Fatalf("b.Get after a.Delete = %q", v)
+	}
+
+	sets, ok := a.(SetStorage)
+	if !ok {
+		t.Fatal("prefixed memory storage does not implement SetStorage")
+	}
+	_ = sets.SAdd(ctx, "set", "x", 0)
+	if members, _ := s.SMembers(ctx, "a:set"); len(members) != 1 {
+		t.Fatalf("backend a:set = %v", members)
+	}
+	if n, _ := a.(CounterStorage).IncrBy(ctx, "n", 2, 0); n != 2 {
+		t.Fatalf("IncrBy = %d", n)
+	}
+	if ok, _ := a.(LockStorage).SetNX(ctx, "n", nil, 0); ok {
+		t.Fatal("SetNX stored over an existing key")
+	}
+	if ok, _ := a.(CASStorage).CompareAndSwap(ctx, "n", []byte("2"), []byte("3"), 0); !ok {
+		t.Fatal("CompareAndSwap failed through the prefix")
+	}
+	if vals, _ := a.(BatchStorage).MGet(ctx, []string{"n"}); len(vals) != 1 || string(vals[0]) != "3" {
+		t.Fatalf("MGet = %q", vals)
+	}
+
+	if Unwrap(a) != Storage(s) || Unwrap(s) != Storage(s) {
+		t.Fatal("Unwrap did not return the backend")
+	}
+	if _, ok := WithPrefix(basicStorage{s}, "c:").(SetStorage); ok {
+		t.Fatal("prefixed storage claims SetStorage its backend lacks")
+	}
+}
+
+func TestWithPrefixPubSub(t *testing.T) {
+	ps := NewMemoryPubSub()
+	ctx := context.Background()
+	a, b := WithPrefixPubSub(ps, "a:"), WithPrefixPubSub(ps, "b:")
+	received := make(chan string, 2)
+	_, _ = a.Subscribe(ctx, "ch", func(msg []byte) { received <- "a " + string(msg) })
+	_, _ = b.Subscribe(ctx, "ch", func(msg []byte) { received <- "b " + string(msg) })
+
+	_ = a.Publish(ctx, "ch", []byte("hi"))
+	select {
+	case got := <-received:
+		if got != "a hi" {
+			t.Fatalf("received %q", got)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out waiting for the message")
+	}
+	select {
+	case got := <-received:
+		t.Fatalf("message crossed prefixes: %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if UnwrapPubSub(a) != PubSub(ps) {
+		t.Fatal("UnwrapPubSub did not return the backend")
+	}
+}
+
+func TestWithPrefixPubSub_PSubscribe(t *testing.T) {
+	ctx := context.Background()
+	// The prefix's glob characters match literally.
+	a := WithPrefixPubSub(NewMemoryPubSub(), "t[1]:").(PatternPubSub)
+	received := make(chan Message, 1)
+	if _, err := a.PSubscribe(ctx, "room:*", func(_ context.Context, msg Message) { received <- msg }); err != nil {
+		t.Fatalf("PSubscribe failed: %v", err)
+	}
+	_ = a.Publish(ctx, "room:7", []byte("hi"))
+	select {
+	case msg := <-received:
+		if msg.Channel != "room:7" || msg.Pattern != "room:*" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out waiting for the message")
+	}
+
+	plain := WithPrefixPubSub(struct{ PubSub }{NewMemoryPubSub()}, "a:").(PatternPubSub)
+	if _, err := plain.PSubscribe(ctx, "*", func(context.Context, Message) {}); err != ErrPatternsUnsupported {
+		t.Fatalf("PSubscribe on a plain PubSub = %v, want ErrPatternsUnsupported", err)
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	s := NewMemoryStorage()
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte("o"), 32)
+	newKey := bytes.Repeat([]byte("n"), 32)
+
+	before, err := Encrypted(s, oldKey)
+	if err != nil {
+		t.Fatalf("Encrypted failed: %v", err)
+	}
+	_ = before.Set(ctx, "session", []byte("user-42"), 0)
+	if raw, _ := s.Get(ctx, "session"); bytes.Contains(raw, []byte("user-42")) {
+		t.Fatal("backend holds the plaintext")
+	}
+
+	rotated, err := Encrypted(s, newKey, oldKey)
+	if err != nil {
+		t.Fatalf("Encrypted failed: %v", err)
+	}
+	if v, err := rotated.Get(ctx, "session"); err != nil || string(v) != "user-42" {
+		t.Fatalf("Get with the old key = %q, %v", v, err)
+	}
+	_ = rotated.Set(ctx, "session", []byte("user-43"), 0)
+	if _, err := before.Get(ctx, "session"); err != ErrDecrypt {
+		t.Fatalf("Get of a value sealed with an unknown key = %v, want ErrDecrypt", err)
+	}
+
+	// Values are bound to their key.
+	raw, _ := s.Get(ctx, "session")
+	_ = s.Set(ctx, "other", raw, 0)
+	if _, err := rotated.Get(ctx, "other"); err != ErrDecrypt {
+		t.Fatalf("Get of a moved value = %v, want ErrDecrypt", err)
+	}
+	if _, err := rotated.Get(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("Get of a missing key = %v, want ErrNotFound", err)
+	}
+
+	if ok, _ := rotated.(LockStorage).SetNX(ctx, "lock", []byte("1"), 0); !ok {
+		t.Fatal("SetNX failed")
+	}
+	if v, _ := rotated.Get(ctx, "lock"); string(v) != "1" {
+		t.Fatalf("Get after SetNX = %q", v)
+	}
+	if _, ok := rotated.(CounterStorage); ok {
+		t.Fatal("encrypted storage claims CounterStorage")
+	}
+	if Unwrap(rotated) != Storage(s) {
+		t.Fatal("Unwrap did not return the backend")
+	}
+	if _, err := Encrypted(s, []byte("short")); err == nil {
+		t.Fatal("Encrypted accepted a short key")
+	}
+}
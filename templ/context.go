@@ -16,3 +16,17 @@ func GetNonce(ctx context.Context) string {
 	}
 	return ""
 }
+
+type spaNavigationKey struct{}
+
+// WithSPANavigation marks ctx as rendering an SPA navigation response, which
+// leaves out ServerOnly content.
+func WithSPANavigation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, spaNavigationKey{}, true)
+}
+
+// IsSPANavigation reports whether ctx renders an SPA navigation response.
+func IsSPANavigation(ctx context.Context) bool {
+	v, _ := ctx.Value(spaNavigationKey{}).(bool)
+	return v
+}
@@ -0,0 +1,77 @@
+package templ
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/component"
+)
+
+// HydrationOptions configures when the client runtime hydrates the components
+// and islands rendered inside Hydrate.
+type HydrationOptions struct {
+	// Mode is immediate (the default), visible, idle, interaction or never.
+	Mode component.IslandHydrationMode
+	// Threshold is the viewport margin in pixels for visible mode.
+	Threshold int
+	// Timeout is the longest wait in ms for idle mode.
+	Timeout int
+	// Tag is the wrapper element, "div" by default.
+	Tag string
+}
+
+// Hydrate wraps content in a hydration boundary, so the components and islands
+// inside it hydrate on their own schedule instead of with the rest of the
+// page. Islands that set their own hydration mode keep it. The wrapper uses
+// display: contents, so it does not affect layout.
+func Hydrate(content templ.Component, opts HydrationOptions) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		mode := opts.Mode
+		switch mode {
+		case "":
+			mode = component.HydrationImmediate
+		case component.HydrationImmediate, component.HydrationVisible, component.HydrationIdle,
+			component.HydrationInteraction, component.HydrationNever:
+		default:
+			return fmt.Errorf("templ: unsupported hydration mode %q", mode)
+		}
+		tag := opts.Tag
+		if tag == "" {
+			tag = "div"
+		}
+		if !isTagName(tag) {
+			return fmt.Errorf("templ: invalid hydration boundary tag %q", tag)
+		}
+
+		attrs := map[string]string{
+			"data-gospa-hydrate": string(mode),
+			"style":              "display:contents",
+		}
+		if opts.Threshold > 0 {
+			attrs["data-gospa-threshold"] = strconv.Itoa(opts.Threshold)
+		}
+		if opts.Timeout > 0 {
+			attrs["data-gospa-defer"] = strconv.Itoa(opts.Timeout)
+		}
+
+		if _, err := fmt.Fprintf(w, "<%s", tag); err != nil {
+			return err
+		}
+		if err := writeAttrs(w, attrs, nil); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ">"); err != nil {
+			return err
+		}
+		if content != nil {
+			if err := content.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "</%s>", tag)
+		return err
+	})
+}
@@ -117,24 +117,72 @@ func IslandWithProps(name string, props map[string]any, content templ.Component,
 	})
 }
 
-// ClientOnly renders content only on the client.
-func ClientOnly(name string, placeholder ...templ.Component) templ.Component {
+// ClientOnly renders placeholder on the server and swaps in content once the
+// client runtime starts. content is rendered into an inert <template>, so
+// widgets that need browser APIs (maps, charts, canvas) only run after the
+// swap. The components and islands it contains are initialized after it is
+// mounted.
+func ClientOnly(placeholder, content templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		// Render placeholder if provided
+		if _, err := io.WriteString(w, `<div data-gospa-client-only style="display:contents">`); err != nil {
+			return err
+		}
+		if placeholder != nil {
+			if err := placeholder.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, `<template>`); err != nil {
+			return err
+		}
+		if content != nil {
+			if err := content.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, `</template></div>`)
+		return err
+	})
+}
+
+// ClientOnlyIsland renders an empty marker for the named island, or
+// placeholder inside it, and leaves rendering to the island's client module.
+func ClientOnlyIsland(name string, placeholder ...templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := fmt.Fprintf(w, `<div data-gospa-island="%s" data-gospa-client-only="true">`, templ.EscapeString(name)); err != nil {
+			return err
+		}
 		if len(placeholder) > 0 && placeholder[0] != nil {
-			return placeholder[0].Render(ctx, w)
+			if err := placeholder[0].Render(ctx, w); err != nil {
+				return err
+			}
 		}
-		// Otherwise render empty placeholder with island marker
-		_, err := fmt.Fprintf(w, `<div data-gospa-island="%s" data-gospa-client-only="true"></div>`, name)
+		_, err := io.WriteString(w, `</div>`)
 		return err
 	})
 }
 
-// ServerOnly renders content only on the server (no hydration).
+// ServerOnly renders content on full page loads only. SPA navigation
+// responses leave it out, and the client keeps what the previous page showed
+// in its place, so content the client never needs to diff (analytics
+// snippets, structured data, large static blocks) costs nothing on
+// navigation.
 func ServerOnly(content templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		// Render without any island markers
-		return content.Render(ctx, w)
+		if IsSPANavigation(ctx) {
+			_, err := io.WriteString(w, `<div data-gospa-server-only="omitted" style="display:contents"></div>`)
+			return err
+		}
+		if _, err := io.WriteString(w, `<div data-gospa-server-only style="display:contents">`); err != nil {
+			return err
+		}
+		if content != nil {
+			if err := content.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, `</div>`)
+		return err
 	})
 }
 
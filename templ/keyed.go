@@ -0,0 +1,106 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/a-h/templ"
+	"golang.org/x/net/html"
+)
+
+// Keyed renders a list of items with keys, like ForKey, without wrapping
+// them in <template> tags. When an item renders a single root element the key
+// is added to it as a data-key attribute; otherwise the item is bracketed by
+// <!--gospa-key:KEY--> and <!--/gospa-key--> comments. Either way the items
+// stay in normal flow and the client morph matches them by key.
+func Keyed[T any, K comparable](items []T, keyFn func(T) K, render func(T, int) templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		var buf bytes.Buffer
+		for i, item := range items {
+			buf.Reset()
+			if err := render(item, i).Render(ctx, &buf); err != nil {
+				return err
+			}
+			key := escapeAttr(fmt.Sprint(keyFn(item)))
+			if err := writeKeyed(w, buf.Bytes(), key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeKeyed writes one rendered item marked with its already escaped key.
+func writeKeyed(w io.Writer, item []byte, key string) error {
+	if at, ok := keyedRoot(item); ok {
+		if _, err := w.Write(item[:at]); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, ` data-key="%s"`, key); err != nil {
+			return err
+		}
+		_, err := w.Write(item[at:])
+		return err
+	}
+	if _, err := fmt.Fprintf(w, `<!--gospa-key:%s-->`, key); err != nil {
+		return err
+	}
+	if _, err := w.Write(item); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, `<!--/gospa-key-->`)
+	return err
+}
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// keyedRoot reports whether item is a single top-level element, surrounded
+// only by whitespace and comments, and returns the offset just past its tag
+// name where an attribute can be inserted.
+func keyedRoot(item []byte) (int, bool) {
+	z := html.NewTokenizer(bytes.NewReader(item))
+	offset, depth, roots, insertAt := 0, 0, 0, -1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF once the whole item is read; anything else is markup the
+			// tokenizer could not follow.
+			return insertAt, roots == 1 && depth == 0 && offset == len(item)
+		}
+		raw := z.Raw()
+		start := offset
+		offset += len(raw)
+
+		switch tt {
+		case html.TextToken:
+			if depth == 0 && len(bytes.TrimSpace(raw)) > 0 {
+				return 0, false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if depth == 0 {
+				roots++
+				if roots > 1 {
+					return 0, false
+				}
+				insertAt = start + 1 + len(name)
+			}
+			if tt == html.StartTagToken && !voidElements[string(name)] {
+				depth++
+			}
+		case html.EndTagToken:
+			if depth == 0 {
+				return 0, false
+			}
+			depth--
+		case html.DoctypeToken:
+			return 0, false
+		}
+	}
+}
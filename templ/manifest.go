@@ -0,0 +1,71 @@
+package templ
+
+import (
+	"context"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// DefaultManifestPath is where the web app manifest is served unless
+// ManifestConfig.Href says otherwise.
+const DefaultManifestPath = "/manifest.webmanifest"
+
+// ManifestConfig describes a web app manifest, the file browsers read to
+// install a site as an app. It marshals to the manifest's JSON.
+type ManifestConfig struct {
+	// Href is the URL the manifest is served at, default DefaultManifestPath.
+	Href            string         `json:"-"`
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	StartURL        string         `json:"start_url"`
+	Scope           string         `json:"scope,omitempty"`
+	Display         string         `json:"display"`
+	BackgroundColor string         `json:"background_color,omitempty"`
+	ThemeColor      string         `json:"theme_color,omitempty"`
+	Icons           []ManifestIcon `json:"icons,omitempty"`
+}
+
+// ManifestIcon is an icon of a ManifestConfig, e.g.
+// {Src: "/static/icon-512.png", Sizes: "512x512", Type: "image/png"}.
+type ManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// Manifest renders the head tags for m: the manifest link, the theme color
+// and the tags iOS reads instead of the manifest, with the first icon as the
+// home screen icon. Use it in the root layout's head.
+func Manifest(m ManifestConfig) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		href := m.Href
+		if href == "" {
+			href = DefaultManifestPath
+		}
+		var sb strings.Builder
+		sb.WriteString(`<link rel="manifest" href="` + html.EscapeString(href) + `">`)
+		if m.ThemeColor != "" {
+			sb.WriteString(`<meta name="theme-color" content="` + html.EscapeString(m.ThemeColor) + `">`)
+		}
+		if len(m.Icons) > 0 {
+			sb.WriteString(`<link rel="apple-touch-icon" href="` + html.EscapeString(m.Icons[0].Src) + `">`)
+		}
+		if m.Display == "" || m.Display == "standalone" || m.Display == "fullscreen" {
+			sb.WriteString(`<meta name="mobile-web-app-capable" content="yes">`)
+		}
+		title := m.ShortName
+		if title == "" {
+			title = m.Name
+		}
+		if title != "" {
+			sb.WriteString(`<meta name="apple-mobile-web-app-title" content="` + html.EscapeString(title) + `">`)
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	})
+}
@@ -0,0 +1,26 @@
+package templ
+
+import (
+	"context"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	got := renderComponent(context.Background(), t, Manifest(ManifestConfig{
+		Name:       "Docs & Guides",
+		ThemeColor: "#0f172a",
+		Icons:      []ManifestIcon{{Src: "/static/icon.png?v=abc", Sizes: "512x512"}},
+	}))
+	assertContainsAll(t, got,
+		`<link rel="manifest" href="/manifest.webmanifest">`,
+		`<meta name="theme-color" content="#0f172a">`,
+		`<link rel="apple-touch-icon" href="/static/icon.png?v=abc">`,
+		`<meta name="mobile-web-app-capable" content="yes">`,
+		`<meta name="apple-mobile-web-app-title" content="Docs &amp; Guides">`,
+	)
+
+	got = renderComponent(context.Background(), t, Manifest(ManifestConfig{Href: "/app.webmanifest", Display: "browser"}))
+	if got != `<link rel="manifest" href="/app.webmanifest">` {
+		t.Fatalf("Manifest = %s", got)
+	}
+}
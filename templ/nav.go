@@ -0,0 +1,79 @@
+package templ
+
+import (
+	"context"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+// NavMenu renders node's children as nested lists of links, e.g. a docs
+// sidebar from routing.NavTree().Find("/docs"). The link for current gets
+// aria-current="page" and the items leading to it get class "active".
+func NavMenu(node *routing.NavNode, current string) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		var sb strings.Builder
+		sb.WriteString(`<nav class="gospa-nav">`)
+		if node != nil {
+			writeNavList(&sb, node.Children, current)
+		}
+		sb.WriteString(`</nav>`)
+		_, err := io.WriteString(w, sb.String())
+		return err
+	})
+}
+
+func writeNavList(sb *strings.Builder, nodes []*routing.NavNode, current string) {
+	if len(nodes) == 0 {
+		return
+	}
+	sb.WriteString(`<ul>`)
+	for _, n := range nodes {
+		if n.Contains(current) {
+			sb.WriteString(`<li class="active">`)
+		} else {
+			sb.WriteString(`<li>`)
+		}
+		writeNavLabel(sb, n, n.Find(current) == n)
+		writeNavList(sb, n.Children, current)
+		sb.WriteString(`</li>`)
+	}
+	sb.WriteString(`</ul>`)
+}
+
+// Breadcrumbs renders the trail from the tree's root to current. The last
+// crumb is marked as the current page when current is in the tree.
+func Breadcrumbs(tree *routing.NavNode, current string) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		var sb strings.Builder
+		sb.WriteString(`<nav class="gospa-breadcrumbs" aria-label="Breadcrumb"><ol>`)
+		if tree != nil {
+			crumbs := tree.Breadcrumbs(current)
+			for i, n := range crumbs {
+				sb.WriteString(`<li>`)
+				writeNavLabel(&sb, n, i == len(crumbs)-1 && tree.Find(current) == n)
+				sb.WriteString(`</li>`)
+			}
+		}
+		sb.WriteString(`</ol></nav>`)
+		_, err := io.WriteString(w, sb.String())
+		return err
+	})
+}
+
+// writeNavLabel writes a link to n, or a plain label for sections without
+// a page.
+func writeNavLabel(sb *strings.Builder, n *routing.NavNode, isCurrent bool) {
+	title := html.EscapeString(n.Title)
+	switch {
+	case !n.HasPage:
+		sb.WriteString(`<span>` + title + `</span>`)
+	case isCurrent:
+		sb.WriteString(`<a href="` + html.EscapeString(n.Path) + `" aria-current="page">` + title + `</a>`)
+	default:
+		sb.WriteString(`<a href="` + html.EscapeString(n.Path) + `">` + title + `</a>`)
+	}
+}
@@ -0,0 +1,35 @@
+package templ
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ahtempl "github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/routing"
+)
+
+func TestNavComponents(t *testing.T) {
+	reg := routing.NewRegistry()
+	page := func(_ map[string]interface{}) ahtempl.Component { return ahtempl.NopComponent }
+	reg.RegisterPage("/", page)
+	reg.RegisterPage("/docs", page)
+	reg.RegisterPage("/docs/api", page)
+	reg.RegisterPageMeta("/docs/api", routing.Meta{Title: "API <Reference>"})
+	reg.RegisterPage("/docs/guides/install", page)
+	tree := reg.NavTree()
+
+	menu := renderComponent(context.Background(), t, NavMenu(tree.Find("/docs"), "/docs/api"))
+	assertContainsAll(t, menu,
+		`<li class="active"><a href="/docs/api" aria-current="page">API &lt;Reference&gt;</a></li>`,
+		`<li><span>Guides</span><ul><li><a href="/docs/guides/install">Install</a></li></ul></li>`,
+	)
+
+	crumbs := renderComponent(context.Background(), t, Breadcrumbs(tree, "/docs/api"))
+	assertContainsAll(t, crumbs,
+		`<ol><li><a href="/">Home</a></li><li><a href="/docs">Docs</a></li><li><a href="/docs/api" aria-current="page">`,
+	)
+	if crumbs := renderComponent(context.Background(), t, Breadcrumbs(tree, "/docs/api/v2")); strings.Contains(crumbs, "aria-current") {
+		t.Fatalf("unknown page marked current: %s", crumbs)
+	}
+}
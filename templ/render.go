@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/a-h/templ"
 )
@@ -14,12 +15,7 @@ import (
 // RuntimeScript returns the script tag for the GoSPA client runtime.
 func RuntimeScript(src string) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		nonce := GetNonce(ctx)
-		nonceAttr := ""
-		if nonce != "" {
-			nonceAttr = fmt.Sprintf(` nonce="%s"`, nonce)
-		}
-		_, err := fmt.Fprintf(w, `<script src="%s" type="module"%s></script>`, templ.EscapeString(src), nonceAttr)
+		_, err := fmt.Fprintf(w, `<script src="%s" type="module"%s></script>`, templ.EscapeString(src), nonceAttr(ctx))
 		return err
 	})
 }
@@ -27,12 +23,7 @@ func RuntimeScript(src string) templ.Component {
 // RuntimeScriptInline returns an inline script tag with the runtime code.
 func RuntimeScriptInline(code string) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		nonce := GetNonce(ctx)
-		nonceAttr := ""
-		if nonce != "" {
-			nonceAttr = fmt.Sprintf(` nonce="%s"`, nonce)
-		}
-		_, err := fmt.Fprintf(w, `<script%s>%s</script>`, nonceAttr, code)
+		_, err := fmt.Fprintf(w, `<script%s>%s</script>`, nonceAttr(ctx), code)
 		return err
 	})
 }
@@ -48,12 +39,7 @@ func CSS(href string) templ.Component {
 // CSSInline returns an inline style tag.
 func CSSInline(css string) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		nonce := GetNonce(ctx)
-		nonceAttr := ""
-		if nonce != "" {
-			nonceAttr = fmt.Sprintf(` nonce="%s"`, nonce)
-		}
-		_, err := fmt.Fprintf(w, `<style%s>%s</style>`, nonceAttr, css)
+		_, err := fmt.Fprintf(w, `<style%s>%s</style>`, nonceAttr(ctx), css)
 		return err
 	})
 }
@@ -105,7 +91,7 @@ func Head(components ...templ.Component) templ.Component {
 // HTMLPage returns a complete HTML page.
 func HTMLPage(lang string, head, body templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-		if _, err := fmt.Fprintf(w, `<!DOCTYPE html><html lang="%s">`, lang); err != nil {
+		if _, err := fmt.Fprintf(w, `<!DOCTYPE html><html lang="%s">`, escapeAttr(lang)); err != nil {
 			return err
 		}
 		if _, err := fmt.Fprint(w, `<head>`); err != nil {
@@ -167,11 +153,6 @@ func SPAPage(config SPAConfig) templ.Component {
 				return err
 			}
 		}
-		nonce := GetNonce(ctx)
-		nonceAttr := ""
-		if nonce != "" {
-			nonceAttr = fmt.Sprintf(` nonce="%s"`, nonce)
-		}
 
 		if config.Head != nil {
 			if err := config.Head.Render(ctx, w); err != nil {
@@ -200,19 +181,14 @@ func SPAPage(config SPAConfig) templ.Component {
 
 		// Runtime script
 		if config.RuntimeSrc != "" {
-			if _, err := fmt.Fprintf(w, `<script src="%s" type="module"%s></script>`, templ.EscapeString(config.RuntimeSrc), nonceAttr); err != nil {
+			if _, err := fmt.Fprintf(w, `<script src="%s" type="module"%s></script>`, templ.EscapeString(config.RuntimeSrc), nonceAttr(ctx)); err != nil {
 				return err
 			}
 		}
 
 		// Auto-init script
 		if config.AutoInit {
-			nonce := GetNonce(ctx)
-			nonceAttr := ""
-			if nonce != "" {
-				nonceAttr = fmt.Sprintf(` nonce="%s"`, nonce)
-			}
-			if _, err := fmt.Fprintf(w, `<script%s data-gospa-auto></script>`, nonceAttr); err != nil {
+			if _, err := fmt.Fprintf(w, `<script%s data-gospa-auto></script>`, nonceAttr(ctx)); err != nil {
 				return err
 			}
 		}
@@ -271,6 +247,101 @@ func TextContent(text string) templ.Component {
 	})
 }
 
+// RawAttr is an attribute value written exactly as given. Every other value
+// passed to the render helpers is HTML-escaped, so use RawAttr only for values
+// that are already escaped (such as a URL containing &amp;) or fully trusted.
+type RawAttr string
+
+var legacyAttrEscaping atomic.Bool
+
+// SetLegacyAttrEscaping restores the old output of the render helpers, which
+// wrote head tag and attribute names, the page lang and ForKey keys unchecked
+// and unescaped. Attribute values were always escaped and still are.
+//
+// Deprecated: only for apps that relied on injecting markup through those
+// arguments; move such values to RawAttr instead.
+func SetLegacyAttrEscaping(enabled bool) {
+	legacyAttrEscaping.Store(enabled)
+}
+
+// escapeAttr escapes s for a double-quoted attribute value.
+func escapeAttr(s string) string {
+	if legacyAttrEscaping.Load() {
+		return s
+	}
+	return templ.EscapeString(s)
+}
+
+// nonceAttr returns the nonce attribute for ctx's CSP nonce, or "".
+func nonceAttr(ctx context.Context) string {
+	nonce := GetNonce(ctx)
+	if nonce == "" {
+		return ""
+	}
+	return fmt.Sprintf(` nonce="%s"`, templ.EscapeString(nonce))
+}
+
+// isTagName reports whether name is a plain element name.
+func isTagName(name string) bool {
+	if name == "" || legacyAttrEscaping.Load() {
+		return name != ""
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isAttrName reports whether name can be written as an attribute name
+// without breaking out of the tag.
+func isAttrName(name string) bool {
+	if legacyAttrEscaping.Load() {
+		return name != ""
+	}
+	return name != "" && !strings.ContainsFunc(name, func(r rune) bool {
+		return r <= ' ' || r == 0x7f || strings.ContainsRune("\"'<>/=`", r)
+	})
+}
+
+// writeAttrs writes attrs, escaped, and raw, unescaped, in name order. Empty
+// values are written as boolean attributes. Attributes with invalid names are
+// dropped.
+func writeAttrs(w io.Writer, attrs map[string]string, raw map[string]RawAttr) error {
+	names := make([]string, 0, len(attrs)+len(raw))
+	for k := range attrs {
+		if _, ok := raw[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	for k := range raw {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		if !isAttrName(k) {
+			continue
+		}
+		var err error
+		if v, ok := raw[k]; ok {
+			_, err = fmt.Fprintf(w, ` %s="%s"`, k, v)
+		} else if v := attrs[k]; v == "" {
+			_, err = fmt.Fprintf(w, ` %s`, k)
+		} else {
+			_, err = fmt.Fprintf(w, ` %s="%s"`, k, templ.EscapeString(v))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Attrs renders multiple attributes.
 func Attrs(attrs ...templ.Attributes) templ.Attributes {
 	result := make(templ.Attributes)
@@ -520,13 +591,15 @@ func For[T any](items []T, render func(T, int) templ.Component) templ.Component
 	})
 }
 
-// ForKey renders a list of items with keys.
+// ForKey renders a list of items with keys, each wrapped in a <template>
+// tag. Browsers do not render template content, so prefer Keyed for lists
+// that must be visible before the client runtime loads.
 func ForKey[T any, K comparable](items []T, keyFn func(T) K, render func(T, int) templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
 		for i, item := range items {
 			// Key is used for reconciliation on the client
 			k := keyFn(item)
-			if _, err := fmt.Fprintf(w, `<template data-key="%v">`, k); err != nil {
+			if _, err := fmt.Fprintf(w, `<template data-key="%s">`, escapeAttr(fmt.Sprint(k))); err != nil {
 				return err
 			}
 			if err := render(item, i).Render(ctx, w); err != nil {
@@ -576,11 +649,12 @@ type HeadManager struct {
 
 // HeadElement represents an element in the document head.
 type HeadElement struct {
-	Tag      string            // e.g., "title", "meta", "link", "script", "style"
-	Attrs    map[string]string // HTML attributes
-	Content  string            // Inner content (for title, script, style)
-	Key      string            // Unique key for deduplication (optional)
-	Priority int               // Higher priority renders first
+	Tag      string             // e.g., "title", "meta", "link", "script", "style"
+	Attrs    map[string]string  // HTML attributes, escaped when rendered
+	RawAttrs map[string]RawAttr // HTML attributes written without escaping
+	Content  string             // Inner content (for title, script, style)
+	Key      string             // Unique key for deduplication (optional)
+	Priority int                // Higher priority renders first
 }
 
 // NewHeadManager creates a new head manager.
@@ -732,6 +806,10 @@ func (h *HeadManager) Render() templ.Component {
 
 // renderHeadElement renders a single head element.
 func renderHeadElement(ctx context.Context, el HeadElement, w io.Writer) error {
+	if !isTagName(el.Tag) {
+		return fmt.Errorf("templ: invalid head element tag %q", el.Tag)
+	}
+
 	// Add data-gospa-head attribute for client-side updates
 	attrs := make(map[string]string)
 	for k, v := range el.Attrs {
@@ -745,47 +823,27 @@ func renderHeadElement(ctx context.Context, el HeadElement, w io.Writer) error {
 		return err
 	case "meta", "link":
 		// Self-closing tags
-		_, err := fmt.Fprintf(w, `<%s`, el.Tag)
-		if err != nil {
+		if _, err := fmt.Fprintf(w, `<%s`, el.Tag); err != nil {
 			return err
 		}
-		for k, v := range attrs {
-			if v == "" {
-				_, err = fmt.Fprintf(w, ` %s`, k)
-			} else {
-				_, err = fmt.Fprintf(w, ` %s="%s"`, k, templ.EscapeString(v))
-			}
-			if err != nil {
-				return err
-			}
+		if err := writeAttrs(w, attrs, el.RawAttrs); err != nil {
+			return err
 		}
-		_, err = fmt.Fprintf(w, `>`)
+		_, err := fmt.Fprint(w, `>`)
 		return err
 	case "script", "style":
-		// Tags with content
-		nonce := GetNonce(ctx)
-		if nonce != "" {
+		// Tags with content; the client only tracks meta and link by key.
+		delete(attrs, "data-gospa-head")
+		if nonce := GetNonce(ctx); nonce != "" {
 			attrs["nonce"] = nonce
 		}
-
-		_, err := fmt.Fprintf(w, `<%s`, el.Tag)
-		if err != nil {
+		if _, err := fmt.Fprintf(w, `<%s`, el.Tag); err != nil {
 			return err
 		}
-		for k, v := range attrs {
-			if k == "data-gospa-head" && (el.Tag == "script" || el.Tag == "style") {
-				continue // Don't add to script/style tags inner content logic but it's fine for the tag itself
-			}
-			if v == "" {
-				_, err = fmt.Fprintf(w, ` %s`, k)
-			} else {
-				_, err = fmt.Fprintf(w, ` %s="%s"`, k, templ.EscapeString(v))
-			}
-			if err != nil {
-				return err
-			}
+		if err := writeAttrs(w, attrs, el.RawAttrs); err != nil {
+			return err
 		}
-		_, err = fmt.Fprintf(w, `>%s</%s>`, el.Content, el.Tag)
+		_, err := fmt.Fprintf(w, `>%s</%s>`, el.Content, el.Tag)
 		return err
 	default:
 		_, err := fmt.Fprintf(w, `<%s data-gospa-head="%s">%s</%s>`, el.Tag, templ.EscapeString(el.Key), templ.EscapeString(el.Content), el.Tag)
@@ -842,15 +900,8 @@ func HeadLink(rel, href string, extraAttrs ...map[string]string) templ.Component
 		if err != nil {
 			return err
 		}
-		for k, v := range attrs {
-			if v == "" {
-				_, err = fmt.Fprintf(w, ` %s`, k)
-			} else {
-				_, err = fmt.Fprintf(w, ` %s="%s"`, k, templ.EscapeString(v))
-			}
-			if err != nil {
-				return err
-			}
+		if err := writeAttrs(w, attrs, nil); err != nil {
+			return err
 		}
 		_, err = fmt.Fprintf(w, `>`)
 		return err
@@ -873,15 +924,8 @@ func HeadScript(src string, async, deferAttr bool) templ.Component {
 		if err != nil {
 			return err
 		}
-		for k, v := range attrs {
-			if v == "" {
-				_, err = fmt.Fprintf(w, ` %s`, k)
-			} else {
-				_, err = fmt.Fprintf(w, ` %s="%s"`, k, templ.EscapeString(v))
-			}
-			if err != nil {
-				return err
-			}
+		if err := writeAttrs(w, attrs, nil); err != nil {
+			return err
 		}
 		_, err = fmt.Fprintf(w, `></script>`)
 		return err
@@ -2,11 +2,13 @@ package templ
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"testing"
 
 	ahtempl "github.com/a-h/templ"
+	"github.com/aydenstechdungeon/gospa/component"
 )
 
 func renderComponent(ctx context.Context, t *testing.T, c ahtempl.Component) string {
@@ -281,3 +283,116 @@ func TestHeadManagerAndHeadHelpers(t *testing.T) {
 		`data-gospa-head="style-/style.css"`,
 	)
 }
+
+func TestRenderHelpersEscapeAttributes(t *testing.T) {
+	ctx := WithNonce(context.Background(), `n"1`)
+
+	page := renderComponent(ctx, t, HTMLPage(`en" onload="x`, nil, nil))
+	assertContainsAll(t, page, `<html lang="en&#34; onload=&#34;x">`)
+
+	keyed := renderComponent(ctx, t, ForKey([]string{"a"}, func(string) string { return `"><b>` }, func(s string, _ int) ahtempl.Component {
+		return TextContent(s)
+	}))
+	assertContainsAll(t, keyed, `data-key="&#34;&gt;&lt;b&gt;"`)
+
+	assertContainsAll(t, renderComponent(ctx, t, CSSInline("a{}")), `<style nonce="n&#34;1">`)
+
+	link := renderComponent(ctx, t, HeadLink("alternate", "/feed?a=1&b=2", map[string]string{
+		`x" onerror="alert(1)`: "bad",
+		"title":                `A "quoted" title`,
+	}))
+	if strings.Contains(link, "onerror") {
+		t.Fatalf("invalid attribute name was rendered: %s", link)
+	}
+	assertContainsAll(t, link,
+		`<link data-gospa-head="link-alternate-/feed?a=1&amp;b=2" href="/feed?a=1&amp;b=2" rel="alternate" title="A &#34;quoted&#34; title">`)
+
+	var b strings.Builder
+	err := renderHeadElement(ctx, HeadElement{
+		Tag:      "link",
+		Key:      "raw",
+		Attrs:    map[string]string{"rel": "preload", "href": "/ignored"},
+		RawAttrs: map[string]RawAttr{"href": "/img?w=1&amp;h=2"},
+	}, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContainsAll(t, b.String(), `<link data-gospa-head="raw" href="/img?w=1&amp;h=2" rel="preload">`)
+
+	if err := renderHeadElement(ctx, HeadElement{Tag: "img src=x onerror=alert(1)"}, &b); err == nil {
+		t.Fatal("expected an error for an invalid tag name")
+	}
+
+	SetLegacyAttrEscaping(true)
+	defer SetLegacyAttrEscaping(false)
+	legacy := renderComponent(ctx, t, HTMLPage(`en"`, nil, nil))
+	assertContainsAll(t, legacy, `<html lang="en"">`)
+}
+
+func TestKeyed(t *testing.T) {
+	ctx := context.Background()
+	type item struct{ key, html string }
+	items := []item{
+		{"li", `<li class="a">one</li>`},
+		{"img", `  <img src="/x.png">` + "\n"},
+		{"pair", `<p>a</p><p>b</p>`},
+		{"text", `plain`},
+		{"div", `<!-- note --><div><br><span>x</span></div>`},
+	}
+	out := renderComponent(ctx, t, Keyed(items, func(it item) string { return it.key }, func(it item, _ int) ahtempl.Component {
+		return Raw(it.html)
+	}))
+	assertContainsAll(t, out,
+		`<li data-key="li" class="a">one</li>`,
+		`  <img data-key="img" src="/x.png">`,
+		`<!--gospa-key:pair--><p>a</p><p>b</p><!--/gospa-key-->`,
+		`<!--gospa-key:text-->plain<!--/gospa-key-->`,
+		`<!-- note --><div data-key="div"><br><span>x</span></div>`,
+	)
+	if strings.Contains(out, "<template") {
+		t.Fatalf("Keyed should not emit template tags: %s", out)
+	}
+
+	quoted := renderComponent(ctx, t, Keyed([]int{1}, func(int) string { return `"x"` }, func(n int, _ int) ahtempl.Component {
+		return TextContent(fmt.Sprint(n))
+	}))
+	assertContainsAll(t, quoted, `<!--gospa-key:&#34;x&#34;-->1<!--/gospa-key-->`)
+}
+
+func TestHydrate(t *testing.T) {
+	ctx := context.Background()
+	out := renderComponent(ctx, t, Hydrate(TextContent("chart"), HydrationOptions{
+		Mode:      component.HydrationVisible,
+		Threshold: 200,
+	}))
+	if out != `<div data-gospa-hydrate="visible" data-gospa-threshold="200" style="display:contents">chart</div>` {
+		t.Fatalf("unexpected boundary: %s", out)
+	}
+
+	never := renderComponent(ctx, t, Hydrate(nil, HydrationOptions{Mode: component.HydrationNever, Tag: "section"}))
+	assertContainsAll(t, never, `<section data-gospa-hydrate="never"`, `</section>`)
+
+	var b strings.Builder
+	if err := Hydrate(nil, HydrationOptions{Mode: "eventually"}).Render(ctx, &b); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
+
+func TestClientOnlyAndServerOnly(t *testing.T) {
+	ctx := context.Background()
+	out := renderComponent(ctx, t, ClientOnly(TextContent("Loading map"), Raw(`<div id="map"></div>`)))
+	if out != `<div data-gospa-client-only style="display:contents">Loading map<template><div id="map"></div></template></div>` {
+		t.Fatalf("unexpected client-only output: %s", out)
+	}
+	island := renderComponent(ctx, t, ClientOnlyIsland(`Chart"`, TextContent("...")))
+	assertContainsAll(t, island, `data-gospa-island="Chart&#34;"`, `data-gospa-client-only="true">...</div>`)
+
+	full := renderComponent(ctx, t, ServerOnly(TextContent("footer")))
+	if full != `<div data-gospa-server-only style="display:contents">footer</div>` {
+		t.Fatalf("unexpected server-only output: %s", full)
+	}
+	partial := renderComponent(WithSPANavigation(ctx), t, ServerOnly(TextContent("footer")))
+	if partial != `<div data-gospa-server-only="omitted" style="display:contents"></div>` {
+		t.Fatalf("server-only content rendered on SPA navigation: %s", partial)
+	}
+}
@@ -0,0 +1,279 @@
+package templ
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// scopedStyle is the rewritten stylesheet of one ScopedCSS call site.
+type scopedStyle struct {
+	once *templ.OnceHandle
+}
+
+// scopedStyles caches rewritten stylesheets by component name and source.
+var scopedStyles sync.Map
+
+// ScopeClass returns the class ScopedCSS scopes componentName's selectors to.
+// Add it to every element of the component the styles should apply to.
+func ScopeClass(componentName string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(componentName))
+	return "gs-" + strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// ScopedCSS returns a style block whose selectors only match elements carrying
+// ScopeClass(componentName), like Svelte's component styles. The block is
+// written once per page render however often the component renders, provided
+// the render context was initialized with templ.InitializeContext (GoSPA does
+// this for every page). Wrap a selector part in :global(...) to leave it
+// unscoped.
+func ScopedCSS(componentName, css string) templ.Component {
+	key := componentName + "\x00" + css
+	v, ok := scopedStyles.Load(key)
+	if !ok {
+		class := ScopeClass(componentName)
+		scoped := strings.ReplaceAll(scopeStylesheet(css, class), "</", `<\/`)
+		v, _ = scopedStyles.LoadOrStore(key, &scopedStyle{
+			once: templ.NewOnceHandle(templ.WithComponent(templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				_, err := fmt.Fprintf(w, `<style data-gospa-scope="%s"%s>%s</style>`, class, nonceAttr(ctx), scoped)
+				return err
+			}))),
+		})
+	}
+	return v.(*scopedStyle).once.Once()
+}
+
+// groupingAtRules contain rules whose selectors are scoped. The blocks of
+// other at-rules (@keyframes, @font-face, ...) are copied as is.
+var groupingAtRules = map[string]bool{
+	"media":     true,
+	"supports":  true,
+	"container": true,
+	"layer":     true,
+	"scope":     true,
+	"document":  true,
+}
+
+// scopeStylesheet adds .class to the selectors of every style rule in css.
+func scopeStylesheet(css, class string) string {
+	var sb strings.Builder
+	i := 0
+	for i < len(css) {
+		j := skipCSSSpace(css, i)
+		sb.WriteString(css[i:j])
+		i = j
+		if i >= len(css) {
+			break
+		}
+		if css[i] == '}' {
+			sb.WriteByte('}')
+			i++
+			continue
+		}
+
+		end := cssIndex(css, i, func(c byte) bool { return c == '{' || c == ';' })
+		if end < 0 {
+			sb.WriteString(css[i:])
+			break
+		}
+		if css[end] == ';' {
+			// Statement at-rule such as @import.
+			sb.WriteString(css[i : end+1])
+			i = end + 1
+			continue
+		}
+
+		prelude := css[i:end]
+		closing := matchingBrace(css, end)
+		body := css[end+1 : closing]
+		if strings.HasPrefix(prelude, "@") {
+			sb.WriteString(prelude)
+			sb.WriteByte('{')
+			if groupingAtRules[atRuleName(prelude)] {
+				sb.WriteString(scopeStylesheet(body, class))
+			} else {
+				sb.WriteString(body)
+			}
+		} else {
+			sb.WriteString(scopeSelectorList(prelude, class))
+			sb.WriteByte('{')
+			sb.WriteString(body)
+		}
+		if closing < len(css) {
+			sb.WriteByte('}')
+		}
+		i = closing + 1
+	}
+	return sb.String()
+}
+
+// atRuleName returns the lowercased name of the at-rule that starts prelude.
+func atRuleName(prelude string) string {
+	end := 1
+	for end < len(prelude) && (isCSSIdentByte(prelude[end])) {
+		end++
+	}
+	return strings.ToLower(prelude[1:end])
+}
+
+func isCSSIdentByte(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// skipCSSSpace returns the index after any whitespace and comments at i.
+func skipCSSSpace(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r' || s[i] == '\f':
+			i++
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return len(s)
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// cssIndex returns the index of the first byte at or after i for which stop
+// reports true, ignoring comments, strings, escapes and anything inside
+// parentheses or brackets. It returns -1 if there is none.
+func cssIndex(s string, i int, stop func(c byte) bool) int {
+	depth := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return -1
+			}
+			i += end + 4
+			continue
+		case c == '"' || c == '\'':
+			i++
+			for i < len(s) && s[i] != c {
+				if s[i] == '\\' {
+					i++
+				}
+				i++
+			}
+		case c == '\\':
+			i++
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			if depth > 0 {
+				depth--
+			} else if stop(c) {
+				return i
+			}
+		default:
+			if depth == 0 && stop(c) {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or
+// len(s) if the block is unterminated.
+func matchingBrace(s string, open int) int {
+	depth := 1
+	i := open + 1
+	for {
+		j := cssIndex(s, i, func(c byte) bool { return c == '{' || c == '}' })
+		if j < 0 {
+			return len(s)
+		}
+		if s[j] == '{' {
+			depth++
+		} else if depth--; depth == 0 {
+			return j
+		}
+		i = j + 1
+	}
+}
+
+// scopeSelectorList scopes each selector of a comma-separated list, keeping
+// the surrounding whitespace.
+func scopeSelectorList(list, class string) string {
+	var sb strings.Builder
+	for {
+		end := cssIndex(list, 0, func(c byte) bool { return c == ',' })
+		part := list
+		if end >= 0 {
+			part = list[:end]
+		}
+		trimmed := strings.TrimSpace(part)
+		lead := strings.Index(part, trimmed)
+		sb.WriteString(part[:lead])
+		sb.WriteString(scopeSelector(trimmed, class))
+		sb.WriteString(part[lead+len(trimmed):])
+		if end < 0 {
+			return sb.String()
+		}
+		sb.WriteByte(',')
+		list = list[end+1:]
+	}
+}
+
+func isCombinator(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f>+~", c) >= 0
+}
+
+// scopeSelector adds .class to every compound selector of sel.
+func scopeSelector(sel, class string) string {
+	var sb strings.Builder
+	i := 0
+	for i < len(sel) {
+		j := i
+		for j < len(sel) && isCombinator(sel[j]) {
+			j++
+		}
+		sb.WriteString(sel[i:j])
+		if j >= len(sel) {
+			break
+		}
+		end := cssIndex(sel, j, isCombinator)
+		if end < 0 {
+			end = len(sel)
+		}
+		sb.WriteString(scopeCompound(sel[j:end], class))
+		i = end
+	}
+	return sb.String()
+}
+
+// scopeCompound adds .class to a compound selector before its first pseudo
+// selector, so ".btn:hover" becomes ".btn.gs-x:hover".
+func scopeCompound(compound, class string) string {
+	if strings.HasPrefix(compound, ":global(") {
+		closing := cssIndex(compound, len(":global("), func(c byte) bool { return c == ')' })
+		if closing < 0 {
+			return compound
+		}
+		return compound[len(":global("):closing] + compound[closing+1:]
+	}
+	if strings.Contains(compound, "&") || compound == ":root" || strings.HasPrefix(compound, ":host") {
+		return compound
+	}
+	pseudo := cssIndex(compound, 0, func(c byte) bool { return c == ':' })
+	if pseudo < 0 {
+		return compound + "." + class
+	}
+	return compound[:pseudo] + "." + class + compound[pseudo:]
+}
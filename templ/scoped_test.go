@@ -0,0 +1,53 @@
+package templ
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ahtempl "github.com/a-h/templ"
+)
+
+func TestScopeStylesheet(t *testing.T) {
+	const class = "gs-x"
+	tests := map[string]string{
+		`.btn { color: red; }`:                          `.btn.gs-x { color: red; }`,
+		`.btn:hover, a::after{x:y}`:                     `.btn.gs-x:hover, a.gs-x::after{x:y}`,
+		`ul > li + li{}`:                                `ul.gs-x > li.gs-x + li.gs-x{}`,
+		`li:nth-child(2n+1) a{}`:                        `li.gs-x:nth-child(2n+1) a.gs-x{}`,
+		`:global(body) .card{}`:                         `body .card.gs-x{}`,
+		`input[type="a b,c"]{}`:                         `input[type="a b,c"].gs-x{}`,
+		`@media (max-width: 600px) { .a { b: c } }`:     `@media (max-width: 600px) { .a.gs-x { b: c } }`,
+		`@keyframes spin { from { a: b } to { c: d } }`: `@keyframes spin { from { a: b } to { c: d } }`,
+		`@import url("x.css"); /* .c */ p{content:"}"}`: `@import url("x.css"); /* .c */ p.gs-x{content:"}"}`,
+	}
+	for in, want := range tests {
+		if got := scopeStylesheet(in, class); got != want {
+			t.Errorf("scopeStylesheet(%q)\n got %q\nwant %q", in, got, want)
+		}
+	}
+}
+
+func TestScopedCSSRendersOncePerContext(t *testing.T) {
+	class := ScopeClass("Card")
+	if class != ScopeClass("Card") || class == ScopeClass("Button") {
+		t.Fatalf("ScopeClass should be stable and distinct per component, got %q", class)
+	}
+
+	style := ScopedCSS("Card", `.card { padding: 1rem } .x::after { content: "</style>" }`)
+	ctx := ahtempl.InitializeContext(WithNonce(context.Background(), "n1"))
+	out := renderComponent(ctx, t, Fragment(style, style, ScopedCSS("Card", `.card { padding: 1rem } .x::after { content: "</style>" }`)))
+	if strings.Count(out, "<style") != 1 {
+		t.Fatalf("expected one style block, got: %s", out)
+	}
+	assertContainsAll(t, out,
+		`<style data-gospa-scope="`+class+`" nonce="n1">`,
+		`.card.`+class+` { padding: 1rem }`,
+		`<\/style>`,
+	)
+
+	again := renderComponent(ahtempl.InitializeContext(context.Background()), t, style)
+	if !strings.Contains(again, "<style") {
+		t.Fatalf("a new render context should emit the style again, got: %s", again)
+	}
+}
@@ -0,0 +1,87 @@
+package templ
+
+import (
+	"context"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// slotted is content carrying the named layout slots filled by a page.
+type slotted struct {
+	content templ.Component
+	slots   map[string]templ.Component
+}
+
+// Render renders the content with the slots available to LayoutSlot.
+func (s *slotted) Render(ctx context.Context, w io.Writer) error {
+	ctx = context.WithValue(ctx, layoutSlotsKey{}, s.slots)
+	if s.content == nil {
+		return nil
+	}
+	return s.content.Render(ctx, w)
+}
+
+type layoutSlotsKey struct{}
+
+// WithLayoutSlots attaches named slot content to a page. GoSPA passes the
+// slots up to every layout wrapping the page, including the root layout,
+// where LayoutSlot renders them:
+//
+//	routing.RegisterPage("/dashboard", func(props map[string]interface{}) templ.Component {
+//		return templpkg.WithLayoutSlots(Dashboard(props), map[string]templ.Component{
+//			"sidebar": DashboardNav(),
+//		})
+//	})
+//
+// The names differ from WithSlots and Slot, which configure Component slots.
+func WithLayoutSlots(content templ.Component, slots map[string]templ.Component) templ.Component {
+	if len(slots) == 0 {
+		return content
+	}
+	merged := make(map[string]templ.Component, len(slots))
+	for name, slot := range LayoutSlotsOf(content) {
+		merged[name] = slot
+	}
+	for name, slot := range slots {
+		merged[name] = slot
+	}
+	if s, ok := content.(*slotted); ok {
+		content = s.content
+	}
+	return &slotted{content: content, slots: merged}
+}
+
+// LayoutSlotsOf returns the slots attached to c by WithLayoutSlots, or nil.
+func LayoutSlotsOf(c templ.Component) map[string]templ.Component {
+	if s, ok := c.(*slotted); ok {
+		return s.slots
+	}
+	return nil
+}
+
+// HasLayoutSlot reports whether the page being rendered filled the named slot.
+func HasLayoutSlot(ctx context.Context, name string) bool {
+	slots, _ := ctx.Value(layoutSlotsKey{}).(map[string]templ.Component)
+	_, ok := slots[name]
+	return ok
+}
+
+// LayoutSlot renders the page's content for the named slot, or fallback when
+// the page left it empty.
+func LayoutSlot(name string, fallback ...templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		slots, _ := ctx.Value(layoutSlotsKey{}).(map[string]templ.Component)
+		if slot, ok := slots[name]; ok && slot != nil {
+			return slot.Render(ctx, w)
+		}
+		for _, f := range fallback {
+			if f != nil {
+				if err := f.Render(ctx, w); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,195 @@
+//go:build !windows
+
+package gospa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+)
+
+const (
+	// upgradeFDEnv tells a replacement process that its listener is inherited
+	// as fd 3 and that fd 4 is the pipe it writes to once serving.
+	upgradeFDEnv          = "GOSPA_UPGRADE_FD"
+	defaultUpgradeTimeout = 30 * time.Second
+	defaultDrainTimeout   = 30 * time.Second
+)
+
+// RunWithUpgrades starts the app on addr and replaces the running binary
+// without refusing connections. On SIGHUP it starts the executable again with
+// the listening socket inherited and, once the new process is serving, stops
+// accepting, lets in-flight requests finish, and closes WebSocket clients
+// with a 1012 (service restart) frame so they reconnect to the new process
+// and resume. If the new process fails to start or report ready within
+// UpgradeTimeout, the old one keeps serving. SIGINT and SIGTERM run the same
+// drain without a handoff.
+func (a *App) RunWithUpgrades(addr string) error {
+	if err := a.Prepare(); err != nil {
+		return err
+	}
+	ln, ready, err := upgradeListener(addr)
+	if err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- a.Fiber.Listener(ln, fiberpkg.ListenConfig{
+			DisableStartupMessage: ready != nil,
+			BeforeServeFunc: func(*fiberpkg.App) error {
+				if ready != nil {
+					// Tell the old process to drain.
+					_, _ = ready.Write([]byte{1})
+					_ = ready.Close()
+				}
+				return nil
+			},
+		})
+	}()
+	a.Logger().Info("starting GoSPA (upgradable)", "version", Version, "addr", ln.Addr().String(), "pid", os.Getpid(), "inherited", ready != nil)
+	a.printStartupSummary()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case err := <-errc:
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := a.startReplacement(ln); err != nil {
+					a.Logger().Error("upgrade failed, still serving", "err", err)
+					continue
+				}
+				a.Logger().Info("upgrade ready, draining", "pid", os.Getpid())
+			}
+			return a.drain()
+		}
+	}
+}
+
+// drain stops accepting connections, waits up to DrainTimeout for in-flight
+// requests, closes WebSocket clients with a restart code, then shuts down.
+func (a *App) drain() error {
+	timeout := a.Config.DrainTimeout
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := a.Fiber.ShutdownWithContext(ctx)
+	if a.Hub != nil || len(a.wsEndpoints) > 0 {
+		n := 0
+		if a.Hub != nil {
+			n = a.Hub.Drain()
+		}
+		for _, ep := range a.wsEndpoints {
+			n += ep.hub.Drain()
+		}
+		a.Logger().Info("closed WebSocket clients for restart", "clients", n)
+	}
+	if shutdownErr := a.Shutdown(); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// startReplacement starts the current executable with ln inherited and waits
+// for it to report ready.
+func (a *App) startReplacement(ln net.Listener) error {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("gospa: listener %T cannot be passed to a new process", ln)
+	}
+	lnFile, err := filer.File()
+	if err != nil {
+		return fmt.Errorf("gospa: duplicate listener: %w", err)
+	}
+	defer func() { _ = lnFile.Close() }()
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("gospa: create ready pipe: %w", err)
+	}
+	defer func() { _ = readyR.Close() }()
+
+	exe, err := os.Executable()
+	if err != nil {
+		_ = readyW.Close()
+		return fmt.Errorf("gospa: locate executable: %w", err)
+	}
+	cmd := exec.Command(exe, os.Args[1:]...) // #nosec G204 -- re-executes this binary
+	cmd.Env = append(os.Environ(), upgradeFDEnv+"=3")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{lnFile, readyW}
+	err = cmd.Start()
+	_ = readyW.Close()
+	if err != nil {
+		return fmt.Errorf("gospa: start new process: %w", err)
+	}
+
+	timeout := a.Config.UpgradeTimeout
+	if timeout <= 0 {
+		timeout = defaultUpgradeTimeout
+	}
+	if err := waitReady(readyR, timeout); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	// The new process outlives this one; reap it if it exits first.
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// waitReady blocks until the new process writes to the ready pipe. The pipe
+// closing without a write means the process exited before serving.
+func waitReady(r *os.File, timeout time.Duration) error {
+	_ = r.SetReadDeadline(time.Now().Add(timeout))
+	var b [1]byte
+	n, err := r.Read(b[:])
+	switch {
+	case n == 1:
+		return nil
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return fmt.Errorf("gospa: new process not ready after %s", timeout)
+	default:
+		return errors.New("gospa: new process exited before serving")
+	}
+}
+
+// upgradeListener returns the listener inherited from a previous process, with
+// the pipe to signal once serving, or a new listener on addr.
+func upgradeListener(addr string) (net.Listener, *os.File, error) {
+	fd := os.Getenv(upgradeFDEnv)
+	if fd == "" {
+		ln, err := net.Listen("tcp", addr)
+		return ln, nil, err
+	}
+	// Do not hand the variable down to processes this one starts.
+	_ = os.Unsetenv(upgradeFDEnv)
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gospa: invalid %s %q", upgradeFDEnv, fd)
+	}
+	lnFile := os.NewFile(uintptr(n), "gospa-listener")
+	defer func() { _ = lnFile.Close() }()
+	ln, err := net.FileListener(lnFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gospa: inherit listener: %w", err)
+	}
+	return ln, os.NewFile(uintptr(n+1), "gospa-ready"), nil
+}
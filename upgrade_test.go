@@ -0,0 +1,60 @@
+//go:build !windows
+
+package gospa
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = r.Close() }()
+		_, _ = w.Write([]byte{1})
+		_ = w.Close()
+		if err := waitReady(r, time.Second); err != nil {
+			t.Fatalf("waitReady() = %v", err)
+		}
+	})
+
+	t.Run("exited", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = r.Close() }()
+		_ = w.Close()
+		if err := waitReady(r, time.Second); err == nil || !strings.Contains(err.Error(), "exited") {
+			t.Fatalf("waitReady() = %v, want exited error", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = r.Close(); _ = w.Close() }()
+		if err := waitReady(r, 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "not ready") {
+			t.Fatalf("waitReady() = %v, want timeout error", err)
+		}
+	})
+}
+
+func TestUpgradeListenerFresh(t *testing.T) {
+	t.Setenv(upgradeFDEnv, "")
+	ln, ready, err := upgradeListener("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	if ready != nil {
+		t.Fatal("fresh listener should not have a ready pipe")
+	}
+}
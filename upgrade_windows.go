@@ -0,0 +1,9 @@
+package gospa
+
+import "errors"
+
+// RunWithUpgrades is not supported on Windows, which cannot pass listening
+// sockets to a child process.
+func (a *App) RunWithUpgrades(string) error {
+	return errors.New("gospa: RunWithUpgrades is not supported on windows")
+}
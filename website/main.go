@@ -9,7 +9,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,12 +32,15 @@ var (
 )
 
 func main() {
-	// Production config with performance optimizations
-	devMode := os.Getenv("DEV") == "1" || os.Getenv("GOSPA_DEV") == "1"
+	// DEV=1 is the site's older dev switch; keep it as an alias of GOSPA_DEV.
+	if os.Getenv("DEV") == "1" && os.Getenv("GOSPA_DEV") == "" {
+		_ = os.Setenv("GOSPA_DEV", "1")
+	}
 
-	app := gospa.New(gospa.Config{
+	// Production config with performance optimizations. DevMode and
+	// AllowInsecureWS come from GOSPA_ENV (or GOSPA_DEV, or DEV) and gospa.yaml.
+	app, err := gospa.NewFromEnv(gospa.Config{
 		RoutesDir:             "./routes",
-		DevMode:               devMode,
 		AppName:               "GoSPA Documentation",
 		CacheTemplates:        true,                // Required for SSG/ISR/PPR strategies, including dev
 		DefaultRenderStrategy: routing.StrategySSG, // Make the entire docs site static by default
@@ -51,7 +53,6 @@ func main() {
 		WSHeartbeat:           30 * time.Second,
 		WSReconnectDelay:      1 * time.Second,
 		WSMaxReconnect:        5,
-		AllowInsecureWS:       devMode,
 		PublicOrigin:          "https://gospa.onrender.com",
 		HydrationMode:         "idle",
 		NavigationOptions: gospa.NavigationOptions{
@@ -76,6 +77,10 @@ func main() {
 		},
 	})
 
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Add security headers middleware with nonce-based CSP.
 	// NOTE: We allow 'unsafe-inline' for style-src because the GoSPA runtime and speculative navigation
 	// (IntersectionObserver viewport margins) require dynamic style updates.
@@ -89,7 +94,7 @@ func main() {
 	// Add middleware for performance (Link headers, caching)
 	// IMPORTANT: This must come BEFORE routes to catch static assets
 	// We've updated the framework to register static routes later so this works as expected.
-	app.Fiber.Use(cacheMiddleware)
+	app.Fiber.Use(cacheMiddleware(app.Config.DevMode))
 
 	// Legacy redirects after documentation restructuring
 	app.Fiber.Get("/docs/getstarted", func(c fiber.Ctx) error {
@@ -134,9 +139,16 @@ func intPtr(v int) *int {
 }
 
 // cacheMiddleware adds Link headers for preloading and Cache-Control headers in production
-func cacheMiddleware(c fiber.Ctx) error {
+func cacheMiddleware(devMode bool) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		return serveCacheHeaders(c, devMode)
+	}
+}
+
+// serveCacheHeaders sets the preload and caching headers for c. In devMode,
+// only static files get cache headers.
+func serveCacheHeaders(c fiber.Ctx, devMode bool) error {
 	path := c.Path()
-	devMode := getEnvBool("GOSPA_DEV", false)
 
 	// Send Link headers for critical assets on every HTML page request (Early Discovery)
 	if isHTMLPage(path) {
@@ -330,14 +342,3 @@ func isLikelyAssetHash(s string) bool {
 	}
 	return hasLetter && hasDigit
 }
-
-func getEnvBool(key string, defaultVal bool) bool {
-	if val := os.Getenv(key); val != "" {
-		b, err := strconv.ParseBool(val)
-		if err != nil {
-			return defaultVal
-		}
-		return b
-	}
-	return defaultVal
-}
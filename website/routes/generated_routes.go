@@ -89,6 +89,9 @@ func mergeRouteOptions(base routing.RouteOptions, override routing.RouteOptions)
 	if override.RevalidateAfter > 0 {
 		base.RevalidateAfter = override.RevalidateAfter
 	}
+	if override.RevalidateCron != "" {
+		base.RevalidateCron = override.RevalidateCron
+	}
 	if len(override.DynamicSlots) > 0 {
 		base.DynamicSlots = override.DynamicSlots
 	}
@@ -98,6 +101,24 @@ func mergeRouteOptions(base routing.RouteOptions, override routing.RouteOptions)
 	if override.RuntimeTier != "" {
 		base.RuntimeTier = override.RuntimeTier
 	}
+	if override.NoIndex {
+		base.NoIndex = true
+	}
+	if override.NoFollow {
+		base.NoFollow = true
+	}
+	if override.CacheControl != "" {
+		base.CacheControl = override.CacheControl
+	}
+	if override.SurrogateControl != "" {
+		base.SurrogateControl = override.SurrogateControl
+	}
+	if len(override.SurrogateKeys) > 0 {
+		base.SurrogateKeys = override.SurrogateKeys
+	}
+	if len(override.Variants) > 0 {
+		base.Variants = override.Variants
+	}
 	if override.RateLimit != nil {
 		base.RateLimit = override.RateLimit
 	}
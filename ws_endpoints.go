@@ -0,0 +1,208 @@
+package gospa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	fiberpkg "github.com/gofiber/fiber/v3"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+	"github.com/aydenstechdungeon/gospa/store"
+)
+
+// WebSocketOptions configures an endpoint added with App.WebSocket. Zero
+// fields fall back to the app's Config.
+type WebSocketOptions struct {
+	// Middleware runs before the upgrade, e.g. to authenticate. Defaults to
+	// Config.WebSocketMiddleware.
+	Middleware fiberpkg.Handler
+	// ConnRateLimit and ConnBurst limit upgrades per client IP with a limiter
+	// of the endpoint's own. Default: Config.WSConnRateLimit and WSConnBurst.
+	ConnRateLimit float64
+	ConnBurst     float64
+	// MaxMessageSize limits inbound messages. Default: Config.WSMaxMessageSize.
+	MaxMessageSize int
+	// Configure adjusts the handler settings, like Config.ConfigureWebSocket,
+	// which does not apply to this endpoint.
+	Configure func(*fiber.WebSocketConfig)
+}
+
+// wsEndpoint is a WebSocket route and the hub behind it.
+type wsEndpoint struct {
+	path       string
+	hub        *fiber.WSHub
+	upgrade    fiberpkg.Handler
+	middleware fiberpkg.Handler
+	maxMessage int
+	storage    store.Storage
+	configure  func(*fiber.WebSocketConfig)
+	// limiter is the endpoint's own upgrade limiter, nil for the main one.
+	limiter *fiber.ConnectionRateLimiter
+	// poll is the path of the long-polling fallback, if any.
+	poll string
+}
+
+// pollPath is where the main endpoint's long-polling fallback is served.
+const pollPath = "/_gospa/poll"
+
+// WebSocket adds a WebSocket endpoint at path with a hub of its own, so
+// realtime features with very different traffic, such as chat next to a live
+// dashboard, don't share one hub, broadcast channel and connection limit:
+//
+//	chat, err := app.WebSocket("/ws/chat", gospa.WebSocketOptions{
+//		Middleware: requireUser,
+//		ConnBurst:  50,
+//	})
+//	...
+//	chat.BroadcastToTopic("room:42", msg)
+//
+// The endpoint speaks the same protocol as Config.WebSocketPath; a page
+// connects to it with GoSPA.initWebSocket({url}) in the client runtime. Its hub publishes on PubSub channels
+// and keeps resume logs in Storage under "gospa:ws:<path>:". Sessions, client
+// hooks such as OnClientConnect, analytics and auditing are shared with the
+// main endpoint. Call WebSocket before Prepare or Run; Shutdown closes the hub.
+func (a *App) WebSocket(path string, opts WebSocketOptions) (*fiber.WSHub, error) {
+	switch {
+	case !strings.HasPrefix(path, "/") || strings.ContainsAny(path, ":*?"):
+		return nil, fmt.Errorf("gospa: WebSocket path %q must be a static path", path)
+	case a.Config.RequestMode:
+		return nil, errors.New("gospa: WebSocket endpoints are not available in RequestMode")
+	case a.prepared:
+		return nil, errors.New("gospa: WebSocket must be called before Prepare or Run")
+	case a.Hub != nil && path == a.Config.WebSocketPath:
+		return nil, fmt.Errorf("gospa: %s is the main WebSocket endpoint", path)
+	}
+	for _, ep := range a.wsEndpoints {
+		if ep.path == path {
+			return nil, fmt.Errorf("gospa: a WebSocket endpoint is already registered at %s", path)
+		}
+	}
+
+	namespace := "gospa:ws:" + path + ":"
+	pubsub := a.Config.PubSub
+	if pubsub == nil {
+		pubsub = store.NewMemoryPubSub()
+	}
+	storage := a.Config.Storage
+	if storage == nil {
+		storage = store.NewMemoryStorage()
+	}
+	storage = store.WithPrefix(storage, namespace)
+
+	if opts.Middleware == nil {
+		opts.Middleware = a.Config.WebSocketMiddleware
+	}
+	if opts.ConnRateLimit == 0 {
+		opts.ConnRateLimit = a.Config.WSConnRateLimit
+	}
+	if opts.ConnBurst == 0 {
+		opts.ConnBurst = a.Config.WSConnBurst
+	}
+	if opts.MaxMessageSize == 0 {
+		opts.MaxMessageSize = a.Config.WSMaxMessageSize
+	}
+	limiter := fiber.NewConnectionRateLimiter(storage)
+	limiter.SetLimits(opts.ConnBurst, opts.ConnRateLimit)
+
+	hub := fiber.NewWSHub(store.WithPrefixPubSub(pubsub, namespace))
+	go hub.Run()
+	a.wsEndpoints = append(a.wsEndpoints, &wsEndpoint{
+		path:       path,
+		hub:        hub,
+		upgrade:    fiber.WebSocketUpgradeMiddlewareWithLimiter(limiter),
+		middleware: opts.Middleware,
+		maxMessage: opts.MaxMessageSize,
+		storage:    storage,
+		configure:  opts.Configure,
+		limiter:    limiter,
+	})
+	return hub, nil
+}
+
+// mainWebSocket describes the endpoint at Config.WebSocketPath.
+func (a *App) mainWebSocket() *wsEndpoint {
+	return &wsEndpoint{
+		path:       a.Config.WebSocketPath,
+		hub:        a.Hub,
+		upgrade:    fiber.WebSocketUpgradeMiddleware(),
+		middleware: a.Config.WebSocketMiddleware,
+		maxMessage: a.Config.WSMaxMessageSize,
+		storage:    a.Config.Storage,
+		configure:  a.Config.ConfigureWebSocket,
+		poll:       a.pollURL(),
+	}
+}
+
+// pollURL is the long-polling endpoint the client runtime falls back to, or
+// "" when there is none for the WebSocket it connects to.
+func (a *App) pollURL() string {
+	if a.Hub == nil || a.Config.DisablePollFallback || strings.TrimSpace(a.Config.RealtimeURL) != "" {
+		return ""
+	}
+	return pollPath
+}
+
+// registerWebSocket registers the routes for ep.
+func (a *App) registerWebSocket(ep *wsEndpoint) {
+	handlers := []any{fiber.SessionMiddleware()}
+	if ep.middleware != nil {
+		handlers = append(handlers, ep.middleware)
+	}
+	wsConfig := fiber.WebSocketConfig{
+		Hub:                 ep.hub,
+		CompressState:       a.Config.CompressState,
+		StateDiffing:        a.Config.StateDiffing,
+		Serializer:          a.Config.StateSerializer,
+		Deserializer:        a.Config.StateDeserializer,
+		SerializationFormat: a.Config.SerializationFormat,
+		WSMaxMessageSize:    ep.maxMessage,
+		ClientPersistence:   a.clientPersistence,
+		CoalesceInterval:    a.Config.StateSyncCoalesceInterval,
+		PongWait:            a.Config.WSPongWait,
+		PingPeriod:          a.Config.WSPingPeriod,
+		WriteWait:           a.Config.WSWriteWait,
+		Resume:              fiber.NewResumeLog(ep.storage, a.Config.WSResumeBufferSize, a.Config.WSResumeWindow),
+		DevTools:            a.DevTools,
+		AuthorizeTopic:      a.Config.WSTopicAuthorizer,
+		StateQuota:          a.Config.WSStateQuota,
+		Stores:              a.stateStores,
+		OnConnect:           a.clientConnected,
+		OnDisconnect:        a.clientDisconnected,
+		OnError:             a.clientFailed,
+		Conflicts: &fiber.ConflictConfig{
+			Policy:   a.Config.StateConflictPolicy,
+			Resolver: a.Config.StateConflictResolver,
+			Mergers:  a.Config.StateMergeFuncs,
+		},
+	}
+	wsConfig.OnMessage = fiber.DefaultMessageHandler
+	if a.Analytics != nil {
+		wsConfig.OnMessage = a.recordWSMessage
+	}
+	if a.Audit != nil {
+		wsConfig.OnAction = a.auditWSAction
+	}
+	if ep.configure != nil {
+		ep.configure(&wsConfig)
+		wsConfig.Hub = ep.hub
+	}
+	wsHandlers := append([]any{handlers[0], ep.upgrade}, handlers[1:]...)
+	wsHandlers = append(wsHandlers, fiber.WebSocketHandler(wsConfig))
+	a.Fiber.Get(ep.path, wsHandlers[0], wsHandlers[1:]...)
+	if ep.poll != "" {
+		// The middleware runs on every poll request, so it authenticates
+		// each one rather than a single upgrade.
+		handlers = append(handlers, fiber.PollHandler(wsConfig, ep.limiter))
+		a.Fiber.Add([]string{fiberpkg.MethodGet, fiberpkg.MethodPost, fiberpkg.MethodDelete}, ep.poll, handlers[0], handlers[1:]...)
+	}
+}
+
+// closeWebSockets closes the hubs and limiters of the endpoints added with
+// WebSocket.
+func (a *App) closeWebSockets() {
+	for _, ep := range a.wsEndpoints {
+		ep.hub.Close()
+		ep.limiter.Close()
+	}
+}
@@ -0,0 +1,91 @@
+package gospa
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWebSocketEndpoint(t *testing.T) {
+	app := New(Config{RoutesFS: fstest.MapFS{}, DevMode: true, CacheTemplates: true})
+	defer func() { _ = app.Shutdown() }()
+
+	hub, err := app.WebSocket("/ws/chat", WebSocketOptions{ConnBurst: 1, ConnRateLimit: 0.001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hub == nil || hub == app.Hub {
+		t.Fatal("WebSocket should return a hub of its own")
+	}
+	for _, path := range []string{"/ws/chat", app.Config.WebSocketPath, "ws", "/ws/:room"} {
+		if _, err := app.WebSocket(path, WebSocketOptions{}); err == nil {
+			t.Errorf("WebSocket(%q) should fail", path)
+		}
+	}
+	if err := app.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.WebSocket("/ws/late", WebSocketOptions{}); err == nil {
+		t.Error("WebSocket after Prepare should fail")
+	}
+
+	upgrade := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		resp, err := app.Fiber.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := upgrade("/ws/chat"); got == http.StatusTooManyRequests || got == http.StatusNotFound {
+		t.Fatalf("first upgrade status = %d", got)
+	}
+	if got := upgrade("/ws/chat"); got != http.StatusTooManyRequests {
+		t.Fatalf("second upgrade status = %d, want 429 from the endpoint's limiter", got)
+	}
+	if got := upgrade(app.Config.WebSocketPath); got == http.StatusTooManyRequests {
+		t.Fatal("the main endpoint shares the chat endpoint's limit")
+	}
+}
+
+func TestPollFallback(t *testing.T) {
+	open := func(cfg Config) (*App, int, string) {
+		t.Helper()
+		cfg.RoutesFS, cfg.DevMode, cfg.CacheTemplates, cfg.DisableCSRF = fstest.MapFS{}, true, true, true
+		app := New(cfg)
+		t.Cleanup(func() { _ = app.Shutdown() })
+		if err := app.Prepare(); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := app.Fiber.Test(httptest.NewRequest(http.MethodPost, pollPath, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return app, resp.StatusCode, string(body)
+	}
+
+	app, status, body := open(Config{})
+	if status != http.StatusOK || !strings.Contains(body, `"token"`) {
+		t.Fatalf("open = %d %s", status, body)
+	}
+	if app.Hub.ClientCount() != 1 {
+		t.Fatalf("main hub has %d clients, want the poll client", app.Hub.ClientCount())
+	}
+	if got := app.rootLayoutPropsTemplate().static["transport"]; !reflect.DeepEqual(got, map[string]interface{}{"pollUrl": pollPath}) {
+		t.Fatalf("transport prop = %v", got)
+	}
+
+	app, status, _ = open(Config{DisablePollFallback: true})
+	if status == http.StatusOK || app.pollURL() != "" {
+		t.Fatalf("disabled fallback: open = %d, pollURL = %q", status, app.pollURL())
+	}
+}
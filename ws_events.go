@@ -0,0 +1,86 @@
+package gospa
+
+import (
+	"github.com/aydenstechdungeon/gospa/fiber"
+)
+
+// wsEventHooks holds the handlers registered with OnClientConnect,
+// OnClientDisconnect and OnClientError.
+type wsEventHooks struct {
+	connect    []func(*fiber.WSClient)
+	disconnect []func(*fiber.WSClient)
+	errors     []func(*fiber.WSClient, error)
+}
+
+// OnClientConnect registers fn to be called when a WebSocket client has
+// connected and its session state is loaded, before it receives its initial
+// state. Use it with OnClientDisconnect to track presence. Handlers run on the
+// connection's goroutine and must not block.
+func (a *App) OnClientConnect(fn func(*fiber.WSClient)) {
+	if fn == nil {
+		return
+	}
+	a.wsHooksMu.Lock()
+	defer a.wsHooksMu.Unlock()
+	a.wsHooks.connect = append(a.wsHooks.connect, fn)
+}
+
+// OnClientDisconnect registers fn to be called once when a WebSocket client
+// disconnects, whether it closed the connection, timed out or was dropped by
+// the server. Use it to release what OnClientConnect acquired. The client's
+// session state is still readable but nothing can be sent to it.
+func (a *App) OnClientDisconnect(fn func(*fiber.WSClient)) {
+	if fn == nil {
+		return
+	}
+	a.wsHooksMu.Lock()
+	defer a.wsHooksMu.Unlock()
+	a.wsHooks.disconnect = append(a.wsHooks.disconnect, fn)
+}
+
+// OnClientError registers fn to be called when reading from or writing to a
+// WebSocket client fails, e.g. on an abnormal close or a timed-out write.
+// OnClientDisconnect handlers run afterwards; normal closes only run those.
+func (a *App) OnClientError(fn func(*fiber.WSClient, error)) {
+	if fn == nil {
+		return
+	}
+	a.wsHooksMu.Lock()
+	defer a.wsHooksMu.Unlock()
+	a.wsHooks.errors = append(a.wsHooks.errors, fn)
+}
+
+// wsEventHandlers returns the registered WebSocket lifecycle handlers.
+func (a *App) wsEventHandlers() wsEventHooks {
+	a.wsHooksMu.RLock()
+	defer a.wsHooksMu.RUnlock()
+	return a.wsHooks
+}
+
+// clientConnected counts the connection for analytics, lets the live runes
+// start and runs the OnClientConnect handlers.
+func (a *App) clientConnected(client *fiber.WSClient) {
+	if a.Analytics != nil {
+		a.recordWSConnect(client)
+	}
+	a.wakeLive()
+	for _, fn := range a.wsEventHandlers().connect {
+		fn(client)
+	}
+}
+
+// clientDisconnected lets the live runes pause and runs the
+// OnClientDisconnect handlers.
+func (a *App) clientDisconnected(client *fiber.WSClient) {
+	a.wakeLive()
+	for _, fn := range a.wsEventHandlers().disconnect {
+		fn(client)
+	}
+}
+
+// clientFailed runs the OnClientError handlers.
+func (a *App) clientFailed(client *fiber.WSClient, err error) {
+	for _, fn := range a.wsEventHandlers().errors {
+		fn(client, err)
+	}
+}
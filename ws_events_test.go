@@ -0,0 +1,32 @@
+package gospa
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aydenstechdungeon/gospa/fiber"
+)
+
+func TestClientLifecycleHooks(t *testing.T) {
+	app := &App{}
+	var events []string
+	app.OnClientConnect(func(c *fiber.WSClient) { events = append(events, "connect "+c.ID) })
+	app.OnClientConnect(nil)
+	app.OnClientDisconnect(func(c *fiber.WSClient) { events = append(events, "disconnect "+c.ID) })
+	app.OnClientError(func(c *fiber.WSClient, err error) { events = append(events, "error "+c.ID+": "+err.Error()) })
+
+	client := fiber.NewWSClient("c1", nil, fiber.WebSocketConfig{})
+	app.clientConnected(client)
+	app.clientFailed(client, errors.New("write timeout"))
+	app.clientDisconnected(client)
+
+	want := []string{"connect c1", "error c1: write timeout", "disconnect c1"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %q, want %q", events, want)
+		}
+	}
+}